@@ -18,7 +18,6 @@
 package main
 
 import (
-	"context"
 	"crypto/tls"
 	"fmt"
 	"github.com/devtron-labs/devtron/client/telemetry"
@@ -26,7 +25,6 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"time"
 
 	"github.com/casbin/casbin"
 	authMiddleware "github.com/devtron-labs/authenticator/middleware"
@@ -34,6 +32,7 @@ import (
 	"github.com/devtron-labs/devtron/api/router"
 	"github.com/devtron-labs/devtron/api/sse"
 	"github.com/devtron-labs/devtron/internal/middleware"
+	"github.com/devtron-labs/devtron/pkg/shutdown"
 	"github.com/devtron-labs/devtron/pkg/user"
 	"github.com/go-pg/pg"
 	_ "github.com/lib/pq"
@@ -54,6 +53,8 @@ type App struct {
 	serveTls           bool
 	sessionManager2    *authMiddleware.SessionManager
 	OtelTracingService *otel.OtelTracingServiceImpl
+	// used to drain terminals and in-flight k8s operations before exiting
+	gracefulShutdownService shutdown.GracefulShutdownService
 }
 
 func NewApp(router *router.MuxRouter,
@@ -64,20 +65,22 @@ func NewApp(router *router.MuxRouter,
 	pubsubClient *pubsub.PubSubClientServiceImpl,
 	sessionManager2 *authMiddleware.SessionManager,
 	posthogClient *telemetry.PosthogClient,
+	gracefulShutdownService shutdown.GracefulShutdownService,
 ) *App {
 	//check argo connection
 	//todo - check argo-cd version on acd integration installation
 	app := &App{
-		MuxRouter:          router,
-		Logger:             Logger,
-		SSE:                sse,
-		Enforcer:           enforcer,
-		db:                 db,
-		pubsubClient:       pubsubClient,
-		serveTls:           false,
-		sessionManager2:    sessionManager2,
-		posthogClient:      posthogClient,
-		OtelTracingService: otel.NewOtelTracingServiceImpl(Logger),
+		MuxRouter:               router,
+		Logger:                  Logger,
+		SSE:                     sse,
+		Enforcer:                enforcer,
+		db:                      db,
+		pubsubClient:            pubsubClient,
+		serveTls:                false,
+		sessionManager2:         sessionManager2,
+		posthogClient:           posthogClient,
+		OtelTracingService:      otel.NewOtelTracingServiceImpl(Logger),
+		gracefulShutdownService: gracefulShutdownService,
 	}
 	return app
 }
@@ -93,7 +96,8 @@ func (app *App) Start() {
 	app.MuxRouter.Init()
 	//authEnforcer := casbin2.Create()
 
-	server := &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: authMiddleware.Authorizer(app.sessionManager2, user.WhitelistChecker)(app.MuxRouter.Router)}
+	// request id is assigned before authorization so that rejected requests can be correlated as well
+	server := &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: middleware.RequestIdMiddleware(authMiddleware.Authorizer(app.sessionManager2, user.WhitelistChecker)(app.MuxRouter.Router))}
 
 	app.MuxRouter.Router.Use(middleware.PrometheusMiddleware)
 	if tracerProvider != nil {
@@ -130,17 +134,13 @@ func (app *App) Stop() {
 		app.Logger.Info("flushing messages of posthog")
 		posthogCl.Close()
 	}
-	timeoutContext, _ := context.WithTimeout(context.Background(), 5*time.Second)
 	app.Logger.Infow("closing router")
-	err := app.server.Shutdown(timeoutContext)
-	if err != nil {
-		app.Logger.Errorw("error in mux router shutdown", "err", err)
-	}
+	app.gracefulShutdownService.Shutdown(app.server.Shutdown)
 
 	app.OtelTracingService.Shutdown()
 
 	app.Logger.Infow("closing db connection")
-	err = app.db.Close()
+	err := app.db.Close()
 	if err != nil {
 		app.Logger.Errorw("error in closing db connection", "err", err)
 	}
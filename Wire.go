@@ -30,11 +30,14 @@ import (
 	appStoreValues "github.com/devtron-labs/devtron/api/appStore/values"
 	chartRepo "github.com/devtron-labs/devtron/api/chartRepo"
 	"github.com/devtron-labs/devtron/api/cluster"
+	"github.com/devtron-labs/devtron/api/clusterOperation"
 	"github.com/devtron-labs/devtron/api/connector"
 	"github.com/devtron-labs/devtron/api/dashboardEvent"
 	"github.com/devtron-labs/devtron/api/deployment"
 	"github.com/devtron-labs/devtron/api/externalLink"
+	"github.com/devtron-labs/devtron/api/favorites"
 	client "github.com/devtron-labs/devtron/api/helm-app"
+	"github.com/devtron-labs/devtron/api/jobTemplate"
 	"github.com/devtron-labs/devtron/api/module"
 	"github.com/devtron-labs/devtron/api/restHandler"
 	pipeline2 "github.com/devtron-labs/devtron/api/restHandler/app"
@@ -60,6 +63,7 @@ import (
 	jClient "github.com/devtron-labs/devtron/client/jira"
 	"github.com/devtron-labs/devtron/client/lens"
 	"github.com/devtron-labs/devtron/client/telemetry"
+	middleware2 "github.com/devtron-labs/devtron/internal/middleware"
 	"github.com/devtron-labs/devtron/internal/sql/repository"
 	app2 "github.com/devtron-labs/devtron/internal/sql/repository/app"
 	appStatusRepo "github.com/devtron-labs/devtron/internal/sql/repository/appStatus"
@@ -92,6 +96,7 @@ import (
 	"github.com/devtron-labs/devtron/pkg/dockerRegistry"
 	"github.com/devtron-labs/devtron/pkg/git"
 	"github.com/devtron-labs/devtron/pkg/gitops"
+	"github.com/devtron-labs/devtron/pkg/health"
 	jira2 "github.com/devtron-labs/devtron/pkg/jira"
 	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
 	repository7 "github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs/repository"
@@ -103,7 +108,9 @@ import (
 	"github.com/devtron-labs/devtron/pkg/plugin"
 	repository6 "github.com/devtron-labs/devtron/pkg/plugin/repository"
 	"github.com/devtron-labs/devtron/pkg/projectManagementService/jira"
+	"github.com/devtron-labs/devtron/pkg/resourceAction"
 	"github.com/devtron-labs/devtron/pkg/security"
+	"github.com/devtron-labs/devtron/pkg/shutdown"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	util3 "github.com/devtron-labs/devtron/pkg/util"
 	util2 "github.com/devtron-labs/devtron/util"
@@ -137,6 +144,9 @@ func InitializeApp() (*App, error) {
 		apiToken.ApiTokenWireSet,
 		webhookHelm.WebhookHelmWireSet,
 		terminal.TerminalWireSet,
+		clusterOperation.ClusterOperationWireSet,
+		jobTemplate.JobTemplateWireSet,
+		favorites.FavoriteWireSet,
 		// -------wireset end ----------
 		gitSensor.GetGitSensorConfig,
 		gitSensor.NewGitSensorSession,
@@ -199,6 +209,14 @@ func InitializeApp() (*App, error) {
 			new(*dashboardEvent.DashboardTelemetryRouterImpl)),
 
 		router.NewMuxRouter,
+		middleware2.NewRateLimiter,
+		middleware2.NewIdempotencyHandler,
+		repository.NewIdempotencyKeyRepositoryImpl,
+		wire.Bind(new(repository.IdempotencyKeyRepository), new(*repository.IdempotencyKeyRepositoryImpl)),
+		shutdown.NewGracefulShutdownServiceImpl,
+		wire.Bind(new(shutdown.GracefulShutdownService), new(*shutdown.GracefulShutdownServiceImpl)),
+		health.NewHealthCheckServiceImpl,
+		wire.Bind(new(health.HealthCheckService), new(*health.HealthCheckServiceImpl)),
 
 		app2.NewAppRepositoryImpl,
 		wire.Bind(new(app2.AppRepository), new(*app2.AppRepositoryImpl)),
@@ -243,6 +261,8 @@ func InitializeApp() (*App, error) {
 		wire.Bind(new(router.AppListingRouter), new(*router.AppListingRouterImpl)),
 		restHandler.NewAppListingRestHandlerImpl,
 		wire.Bind(new(restHandler.AppListingRestHandler), new(*restHandler.AppListingRestHandlerImpl)),
+		resourceAction.NewResourceActionServiceImpl,
+		wire.Bind(new(resourceAction.ResourceActionService), new(*resourceAction.ResourceActionServiceImpl)),
 		app.NewAppListingServiceImpl,
 		wire.Bind(new(app.AppListingService), new(*app.AppListingServiceImpl)),
 		repository.NewAppListingRepositoryImpl,
@@ -665,11 +685,19 @@ func InitializeApp() (*App, error) {
 		wire.Bind(new(app.AppCrudOperationService), new(*app.AppCrudOperationServiceImpl)),
 		pipelineConfig.NewAppLabelRepositoryImpl,
 		wire.Bind(new(pipelineConfig.AppLabelRepository), new(*pipelineConfig.AppLabelRepositoryImpl)),
+		pipelineConfig.NewLabelTemplateRepositoryImpl,
+		wire.Bind(new(pipelineConfig.LabelTemplateRepository), new(*pipelineConfig.LabelTemplateRepositoryImpl)),
+		pipelineConfig.NewLabelWebhookRepositoryImpl,
+		wire.Bind(new(pipelineConfig.LabelWebhookRepository), new(*pipelineConfig.LabelWebhookRepositoryImpl)),
+		app.NewLabelEventPublisherImpl,
+		wire.Bind(new(app.LabelEventPublisher), new(*app.LabelEventPublisherImpl)),
 
 		delete2.NewDeleteServiceExtendedImpl,
 		wire.Bind(new(delete2.DeleteService), new(*delete2.DeleteServiceExtendedImpl)),
 		delete2.NewDeleteServiceFullModeImpl,
 		wire.Bind(new(delete2.DeleteServiceFullMode), new(*delete2.DeleteServiceFullModeImpl)),
+		delete2.NewClusterDeleteCheckServiceImpl,
+		wire.Bind(new(delete2.ClusterDeleteCheckService), new(*delete2.ClusterDeleteCheckServiceImpl)),
 
 		appStoreDeploymentFullMode.NewAppStoreDeploymentFullModeServiceImpl,
 		wire.Bind(new(appStoreDeploymentFullMode.AppStoreDeploymentFullModeService), new(*appStoreDeploymentFullMode.AppStoreDeploymentFullModeServiceImpl)),
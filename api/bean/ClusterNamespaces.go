@@ -0,0 +1,18 @@
+package bean
+
+import "time"
+
+type NamespaceInfo struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// CachedNamespaces are the namespaces of a cluster as of SyncedOn. Stale is set while the watch of the cluster is failing,
+// Live is set when the namespaces were listed from the cluster for the request instead of being read from the cache
+type CachedNamespaces struct {
+	ClusterId  int              `json:"clusterId"`
+	Namespaces []*NamespaceInfo `json:"namespaces"`
+	SyncedOn   time.Time        `json:"syncedOn"`
+	Stale      bool             `json:"stale"`
+	Live       bool             `json:"live"`
+}
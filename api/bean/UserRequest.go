@@ -104,6 +104,9 @@ const (
 	NOCHARTEXIST string = "NOCHARTEXIST"
 )
 
+// SYSTEM_USER_ID is the system user seeded with the schema, it is recorded on writes no logged in user asked for
+const SYSTEM_USER_ID int32 = 1
+
 type PolicyType int
 
 const (
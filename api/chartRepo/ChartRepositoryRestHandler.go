@@ -25,6 +25,7 @@ import (
 	"github.com/devtron-labs/devtron/pkg/attributes"
 	"github.com/devtron-labs/devtron/pkg/chartRepo"
 	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
 	delete2 "github.com/devtron-labs/devtron/pkg/delete"
 	"github.com/devtron-labs/devtron/pkg/user"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
@@ -258,11 +259,11 @@ func (handler *ChartRepositoryRestHandlerImpl) TriggerChartSyncManual(w http.Res
 		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
 		return
 	}
-	err2 := handler.chartRepositoryService.TriggerChartSyncManual()
+	operationId, err2 := handler.chartRepositoryService.TriggerChartSyncManualAsync(r.Context(), userId)
 	if err2 != nil {
 		common.WriteJsonResp(w, err2, nil, http.StatusInternalServerError)
 	} else {
-		common.WriteJsonResp(w, nil, map[string]string{"status": "ok"}, http.StatusOK)
+		common.WriteJsonResp(w, nil, &clusterOperation.ClusterOperationStartedResponse{OperationId: operationId}, http.StatusAccepted)
 	}
 }
 
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/util"
 	delete2 "github.com/devtron-labs/devtron/pkg/delete"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
 	util2 "github.com/devtron-labs/devtron/util"
@@ -50,19 +51,29 @@ type ClusterRestHandler interface {
 
 	FindAllForAutoComplete(w http.ResponseWriter, r *http.Request)
 	DeleteCluster(w http.ResponseWriter, r *http.Request)
+	GetClusterDeleteCheck(w http.ResponseWriter, r *http.Request)
 	GetClusterNamespaces(w http.ResponseWriter, r *http.Request)
 	GetAllClusterNamespaces(w http.ResponseWriter, r *http.Request)
+	GetNamespacesForPicker(w http.ResponseWriter, r *http.Request)
 	FindAllForClusterPermission(w http.ResponseWriter, r *http.Request)
+	GetClusterHealth(w http.ResponseWriter, r *http.Request)
+	GetTerminalDefaults(w http.ResponseWriter, r *http.Request)
+	UpdateTerminalDefaults(w http.ResponseWriter, r *http.Request)
+	DeleteTerminalDefaults(w http.ResponseWriter, r *http.Request)
+	GetNamespacePolicy(w http.ResponseWriter, r *http.Request)
+	UpdateNamespacePolicy(w http.ResponseWriter, r *http.Request)
+	DeleteNamespacePolicy(w http.ResponseWriter, r *http.Request)
 }
 
 type ClusterRestHandlerImpl struct {
-	clusterService  cluster.ClusterService
-	logger          *zap.SugaredLogger
-	userService     user.UserService
-	validator       *validator.Validate
-	enforcer        casbin.Enforcer
-	deleteService   delete2.DeleteService
-	argoUserService argo.ArgoUserService
+	clusterService            cluster.ClusterService
+	logger                    *zap.SugaredLogger
+	userService               user.UserService
+	validator                 *validator.Validate
+	enforcer                  casbin.Enforcer
+	deleteService             delete2.DeleteService
+	argoUserService           argo.ArgoUserService
+	clusterDeleteCheckService delete2.ClusterDeleteCheckService
 }
 
 func NewClusterRestHandlerImpl(clusterService cluster.ClusterService,
@@ -71,15 +82,17 @@ func NewClusterRestHandlerImpl(clusterService cluster.ClusterService,
 	validator *validator.Validate,
 	enforcer casbin.Enforcer,
 	deleteService delete2.DeleteService,
-	argoUserService argo.ArgoUserService) *ClusterRestHandlerImpl {
+	argoUserService argo.ArgoUserService,
+	clusterDeleteCheckService delete2.ClusterDeleteCheckService) *ClusterRestHandlerImpl {
 	return &ClusterRestHandlerImpl{
-		clusterService:  clusterService,
-		logger:          logger,
-		userService:     userService,
-		validator:       validator,
-		enforcer:        enforcer,
-		deleteService:   deleteService,
-		argoUserService: argoUserService,
+		clusterService:            clusterService,
+		logger:                    logger,
+		userService:               userService,
+		validator:                 validator,
+		enforcer:                  enforcer,
+		deleteService:             deleteService,
+		argoUserService:           argoUserService,
+		clusterDeleteCheckService: clusterDeleteCheckService,
 	}
 }
 
@@ -304,6 +317,8 @@ func (impl ClusterRestHandlerImpl) FindAllForAutoComplete(w http.ResponseWriter,
 	common.WriteJsonResp(w, err, result, http.StatusOK)
 }
 
+// DeleteCluster responds with 409 and the dependency report when the cluster still has terminal sessions, running
+// operations or environments, force=true releases them first and responds with the report of the cleanup
 func (impl ClusterRestHandlerImpl) DeleteCluster(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
 	userId, err := impl.userService.GetLoggedInUser(r)
@@ -334,6 +349,35 @@ func (impl ClusterRestHandlerImpl) DeleteCluster(w http.ResponseWriter, r *http.
 		return
 	}
 	//RBAC enforcer Ends
+	force := false
+	if forceParam := r.URL.Query().Get("force"); len(forceParam) > 0 {
+		force, err = strconv.ParseBool(forceParam)
+		if err != nil {
+			impl.logger.Errorw("request err, Delete", "error", err, "force", forceParam)
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	report, err := impl.clusterDeleteCheckService.CheckClusterDependencies(r.Context(), bean.Id)
+	if err != nil {
+		impl.logger.Errorw("error in checking cluster dependencies", "err", err, "id", bean.Id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	if report.HasDependencies() {
+		if !force {
+			common.WriteJsonResp(w, nil, report, http.StatusConflict)
+			return
+		}
+		report, err = impl.clusterDeleteCheckService.ReleaseAndDeleteCluster(r.Context(), bean.Id, userId)
+		if err != nil {
+			impl.logger.Errorw("error in releasing cluster dependencies", "err", err, "id", bean.Id)
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		common.WriteJsonResp(w, nil, map[string]interface{}{"message": CLUSTER_DELETE_SUCCESS_RESP, "report": report}, http.StatusOK)
+		return
+	}
 	err = impl.deleteService.DeleteCluster(&bean, userId)
 	if err != nil {
 		impl.logger.Errorw("error in deleting cluster", "err", err, "id", bean.Id, "name", bean.ClusterName)
@@ -343,6 +387,35 @@ func (impl ClusterRestHandlerImpl) DeleteCluster(w http.ResponseWriter, r *http.
 	common.WriteJsonResp(w, err, CLUSTER_DELETE_SUCCESS_RESP, http.StatusOK)
 }
 
+// GetClusterDeleteCheck reports what would block the removal of the cluster
+func (impl ClusterRestHandlerImpl) GetClusterDeleteCheck(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	clusterId, err := strconv.Atoi(mux.Vars(r)["clusterId"])
+	if err != nil {
+		impl.logger.Errorw("request err, GetClusterDeleteCheck", "error", err, "clusterId", mux.Vars(r)["clusterId"])
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceCluster, casbin.ActionCreate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	report, err := impl.clusterDeleteCheckService.CheckClusterDependencies(r.Context(), clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in checking cluster dependencies", "err", err, "id", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, report, http.StatusOK)
+}
+
 func (impl ClusterRestHandlerImpl) GetAllClusterNamespaces(w http.ResponseWriter, r *http.Request) {
 	token := r.Header.Get("token")
 	clusterNamespaces := impl.clusterService.GetAllClusterNamespaces()
@@ -389,6 +462,38 @@ func (impl ClusterRestHandlerImpl) GetClusterNamespaces(w http.ResponseWriter, r
 	common.WriteJsonResp(w, nil, allClusterNamespaces, http.StatusOK)
 }
 
+// GetNamespacesForPicker serves the namespace pickers from the namespace watch cache, refresh=true lists them live
+func (impl ClusterRestHandlerImpl) GetNamespacesForPicker(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		impl.logger.Errorw("user not authorized", "error", err, "userId", userId)
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	clusterId, err := strconv.Atoi(mux.Vars(r)["clusterId"])
+	if err != nil {
+		impl.logger.Errorw("failed to extract clusterId from param", "error", err, "clusterId", mux.Vars(r)["clusterId"])
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	refresh := false
+	if refreshParam := r.URL.Query().Get("refresh"); len(refreshParam) > 0 {
+		refresh, err = strconv.ParseBool(refreshParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	token := r.Header.Get("token")
+	isActionUserSuperAdmin := impl.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*")
+	namespaces, err := impl.clusterService.FindNamespacesForPicker(userId, clusterId, isActionUserSuperAdmin, refresh)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, namespaces, http.StatusOK)
+}
+
 func (impl ClusterRestHandlerImpl) FindAllForClusterPermission(w http.ResponseWriter, r *http.Request) {
 	userId, err := impl.userService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
@@ -422,3 +527,176 @@ func (impl ClusterRestHandlerImpl) FindAllForClusterPermission(w http.ResponseWr
 	}
 	common.WriteJsonResp(w, err, clusterList, http.StatusOK)
 }
+
+// GetClusterHealth serves the connection status and the circuit breaker of a cluster
+func (impl ClusterRestHandlerImpl) GetClusterHealth(w http.ResponseWriter, r *http.Request) {
+	clusterId, ok := impl.authorizeClusterSettings(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	health, err := impl.clusterService.GetClusterHealth(clusterId)
+	if err != nil {
+		impl.logger.Errorw("service err, GetClusterHealth", "err", err, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, health, http.StatusOK)
+}
+
+func (impl ClusterRestHandlerImpl) GetTerminalDefaults(w http.ResponseWriter, r *http.Request) {
+	clusterId, ok := impl.authorizeClusterSettings(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	defaults, err := impl.clusterService.GetTerminalDefaults(clusterId)
+	if err != nil {
+		impl.logger.Errorw("service err, GetTerminalDefaults", "err", err, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, defaults, http.StatusOK)
+}
+
+func (impl ClusterRestHandlerImpl) UpdateTerminalDefaults(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean cluster.ClusterTerminalDefaultsBean
+	err = json.NewDecoder(r.Body).Decode(&bean)
+	if err != nil {
+		impl.logger.Errorw("request err, UpdateTerminalDefaults", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = impl.validator.Struct(bean)
+	if err != nil {
+		impl.logger.Errorw("validation err, UpdateTerminalDefaults", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	clusterId, ok := impl.authorizeClusterSettings(w, r, casbin.ActionUpdate)
+	if !ok {
+		return
+	}
+	bean.ClusterId = clusterId
+	defaults, err := impl.clusterService.UpdateTerminalDefaults(&bean, userId)
+	if err != nil {
+		impl.logger.Errorw("service err, UpdateTerminalDefaults", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, defaults, http.StatusOK)
+}
+
+func (impl ClusterRestHandlerImpl) DeleteTerminalDefaults(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	clusterId, ok := impl.authorizeClusterSettings(w, r, casbin.ActionUpdate)
+	if !ok {
+		return
+	}
+	err = impl.clusterService.DeleteTerminalDefaults(clusterId, userId)
+	if err != nil {
+		impl.logger.Errorw("service err, DeleteTerminalDefaults", "err", err, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, clusterId, http.StatusOK)
+}
+
+func (impl ClusterRestHandlerImpl) GetNamespacePolicy(w http.ResponseWriter, r *http.Request) {
+	clusterId, ok := impl.authorizeClusterSettings(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	policy, err := impl.clusterService.GetNamespacePolicy(clusterId)
+	if err != nil {
+		impl.logger.Errorw("service err, GetNamespacePolicy", "err", err, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, policy, http.StatusOK)
+}
+
+func (impl ClusterRestHandlerImpl) UpdateNamespacePolicy(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean cluster.ClusterNamespacePolicyBean
+	err = json.NewDecoder(r.Body).Decode(&bean)
+	if err != nil {
+		impl.logger.Errorw("request err, UpdateNamespacePolicy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = impl.validator.Struct(bean)
+	if err != nil {
+		impl.logger.Errorw("validation err, UpdateNamespacePolicy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	clusterId, ok := impl.authorizeClusterSettings(w, r, casbin.ActionUpdate)
+	if !ok {
+		return
+	}
+	bean.ClusterId = clusterId
+	policy, err := impl.clusterService.UpdateNamespacePolicy(&bean, userId)
+	if err != nil {
+		impl.logger.Errorw("service err, UpdateNamespacePolicy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, policy, http.StatusOK)
+}
+
+func (impl ClusterRestHandlerImpl) DeleteNamespacePolicy(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	clusterId, ok := impl.authorizeClusterSettings(w, r, casbin.ActionUpdate)
+	if !ok {
+		return
+	}
+	err = impl.clusterService.DeleteNamespacePolicy(clusterId, userId)
+	if err != nil {
+		impl.logger.Errorw("service err, DeleteNamespacePolicy", "err", err, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, clusterId, http.StatusOK)
+}
+
+// authorizeClusterSettings reads the cluster id of the request and enforces action on the cluster for the settings
+// kept with the cluster record, the response is written when false is returned
+func (impl ClusterRestHandlerImpl) authorizeClusterSettings(w http.ResponseWriter, r *http.Request, action string) (int, bool) {
+	clusterId, err := strconv.Atoi(mux.Vars(r)["clusterId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return 0, false
+	}
+	bean, err := impl.clusterService.FindByIdWithoutConfig(clusterId)
+	if err != nil {
+		impl.logger.Errorw("service err, authorizeClusterSettings", "err", err, "clusterId", clusterId)
+		if util.IsErrNoRows(err) {
+			common.WriteJsonResp(w, err, nil, http.StatusNotFound)
+			return 0, false
+		}
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return 0, false
+	}
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceCluster, action, strings.ToLower(bean.ClusterName)); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return 0, false
+	}
+	return clusterId, true
+}
@@ -26,12 +26,17 @@ type ClusterRouter interface {
 }
 
 type ClusterRouterImpl struct {
-	clusterRestHandler ClusterRestHandler
+	clusterRestHandler            ClusterRestHandler
+	podPlacementPolicyRestHandler PodPlacementPolicyRestHandler
+	freezeWindowRestHandler       FreezeWindowRestHandler
 }
 
-func NewClusterRouterImpl(handler ClusterRestHandler) *ClusterRouterImpl {
+func NewClusterRouterImpl(handler ClusterRestHandler, podPlacementPolicyRestHandler PodPlacementPolicyRestHandler,
+	freezeWindowRestHandler FreezeWindowRestHandler) *ClusterRouterImpl {
 	return &ClusterRouterImpl{
-		clusterRestHandler: handler,
+		clusterRestHandler:            handler,
+		podPlacementPolicyRestHandler: podPlacementPolicyRestHandler,
+		freezeWindowRestHandler:       freezeWindowRestHandler,
 	}
 }
 
@@ -61,6 +66,10 @@ func (impl ClusterRouterImpl) InitClusterRouter(clusterRouter *mux.Router) {
 		Methods("GET").
 		HandlerFunc(impl.clusterRestHandler.GetClusterNamespaces)
 
+	clusterRouter.Path("/namespaces/{clusterId}/picker").
+		Methods("GET").
+		HandlerFunc(impl.clusterRestHandler.GetNamespacesForPicker)
+
 	clusterRouter.Path("/namespaces").
 		Methods("GET").
 		HandlerFunc(impl.clusterRestHandler.GetAllClusterNamespaces)
@@ -69,7 +78,75 @@ func (impl ClusterRouterImpl) InitClusterRouter(clusterRouter *mux.Router) {
 		Methods("DELETE").
 		HandlerFunc(impl.clusterRestHandler.DeleteCluster)
 
+	clusterRouter.Path("/delete-check/{clusterId}").
+		Methods("GET").
+		HandlerFunc(impl.clusterRestHandler.GetClusterDeleteCheck)
+
 	clusterRouter.Path("/auth-list").
 		Methods("GET").
 		HandlerFunc(impl.clusterRestHandler.FindAllForClusterPermission)
+
+	clusterRouter.Path("/health/{clusterId}").
+		Methods("GET").
+		HandlerFunc(impl.clusterRestHandler.GetClusterHealth)
+
+	clusterRouter.Path("/terminal-defaults/{clusterId}").
+		Methods("GET").
+		HandlerFunc(impl.clusterRestHandler.GetTerminalDefaults)
+
+	clusterRouter.Path("/terminal-defaults/{clusterId}").
+		Methods("PUT").
+		HandlerFunc(impl.clusterRestHandler.UpdateTerminalDefaults)
+
+	clusterRouter.Path("/terminal-defaults/{clusterId}").
+		Methods("DELETE").
+		HandlerFunc(impl.clusterRestHandler.DeleteTerminalDefaults)
+
+	clusterRouter.Path("/namespace-policy/{clusterId}").
+		Methods("GET").
+		HandlerFunc(impl.clusterRestHandler.GetNamespacePolicy)
+
+	clusterRouter.Path("/namespace-policy/{clusterId}").
+		Methods("PUT").
+		HandlerFunc(impl.clusterRestHandler.UpdateNamespacePolicy)
+
+	clusterRouter.Path("/namespace-policy/{clusterId}").
+		Methods("DELETE").
+		HandlerFunc(impl.clusterRestHandler.DeleteNamespacePolicy)
+
+	clusterRouter.Path("/placement-policy").
+		Methods("GET").
+		HandlerFunc(impl.podPlacementPolicyRestHandler.FindAll)
+
+	clusterRouter.Path("/placement-policy").
+		Methods("POST").
+		HandlerFunc(impl.podPlacementPolicyRestHandler.Create)
+
+	clusterRouter.Path("/placement-policy").
+		Methods("PUT").
+		HandlerFunc(impl.podPlacementPolicyRestHandler.Update)
+
+	clusterRouter.Path("/placement-policy/effective").
+		Methods("GET").
+		HandlerFunc(impl.podPlacementPolicyRestHandler.GetEffectivePlacement)
+
+	clusterRouter.Path("/placement-policy/{id}").
+		Methods("DELETE").
+		HandlerFunc(impl.podPlacementPolicyRestHandler.Delete)
+
+	clusterRouter.Path("/freeze-window").
+		Methods("GET").
+		HandlerFunc(impl.freezeWindowRestHandler.FindAll)
+
+	clusterRouter.Path("/freeze-window").
+		Methods("POST").
+		HandlerFunc(impl.freezeWindowRestHandler.Create)
+
+	clusterRouter.Path("/freeze-window").
+		Methods("PUT").
+		HandlerFunc(impl.freezeWindowRestHandler.Update)
+
+	clusterRouter.Path("/freeze-window/{id}").
+		Methods("DELETE").
+		HandlerFunc(impl.freezeWindowRestHandler.Delete)
 }
@@ -28,6 +28,7 @@ import (
 	"github.com/devtron-labs/devtron/api/bean"
 
 	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/util"
 	request "github.com/devtron-labs/devtron/pkg/cluster"
 	delete2 "github.com/devtron-labs/devtron/pkg/delete"
 	"github.com/devtron-labs/devtron/pkg/user"
@@ -51,6 +52,10 @@ type EnvironmentRestHandler interface {
 	GetCombinedEnvironmentListForDropDown(w http.ResponseWriter, r *http.Request)
 	DeleteEnvironment(w http.ResponseWriter, r *http.Request)
 	GetCombinedEnvironmentListForDropDownByClusterIds(w http.ResponseWriter, r *http.Request)
+	GetSecurityPostureReport(w http.ResponseWriter, r *http.Request)
+	GetEnvironmentAvailability(w http.ResponseWriter, r *http.Request)
+	ListConfigObjects(w http.ResponseWriter, r *http.Request)
+	PreviewNamespace(w http.ResponseWriter, r *http.Request)
 }
 
 type EnvironmentRestHandlerImpl struct {
@@ -414,3 +419,146 @@ func (impl EnvironmentRestHandlerImpl) GetCombinedEnvironmentListForDropDownByCl
 	}
 	common.WriteJsonResp(w, err, clusters, http.StatusOK)
 }
+
+func (impl EnvironmentRestHandlerImpl) GetSecurityPostureReport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	envId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean, err := impl.environmentClusterMappingsService.FindById(envId)
+	if err != nil {
+		impl.logger.Errorw("service err, GetSecurityPostureReport", "err", err, "envId", envId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceGlobalEnvironment, casbin.ActionGet, strings.ToLower(bean.EnvironmentIdentifier)); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+
+	report, err := impl.environmentClusterMappingsService.GetSecurityPostureReport(r.Context(), bean)
+	if err != nil {
+		impl.logger.Errorw("service err, GetSecurityPostureReport", "err", err, "envId", envId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, report, http.StatusOK)
+}
+
+// GetEnvironmentAvailability serves the replica and health summary of the environment overview
+func (impl EnvironmentRestHandlerImpl) GetEnvironmentAvailability(w http.ResponseWriter, r *http.Request) {
+	envId, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean, err := impl.environmentClusterMappingsService.FindById(envId)
+	if err != nil {
+		impl.logger.Errorw("service err, GetEnvironmentAvailability", "err", err, "envId", envId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceGlobalEnvironment, casbin.ActionGet, strings.ToLower(bean.EnvironmentIdentifier)); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+
+	availability, err := impl.environmentClusterMappingsService.GetEnvironmentAvailability(r.Context(), bean)
+	if err != nil {
+		impl.logger.Errorw("service err, GetEnvironmentAvailability", "err", err, "envId", envId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, availability, http.StatusOK)
+}
+
+// ListConfigObjects lists the ConfigMaps or Secrets of the namespace of the environment for the environment config page,
+// secret values are never part of the response
+func (impl EnvironmentRestHandlerImpl) ListConfigObjects(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	envId, err := strconv.Atoi(v.Get("id"))
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	kind := v.Get("kind")
+	if kind != util.ConfigMapKind && kind != util.SecretKind {
+		common.WriteJsonResp(w, errors.Errorf("kind must be %s or %s", util.ConfigMapKind, util.SecretKind), nil, http.StatusBadRequest)
+		return
+	}
+	options := util.ConfigObjectListOptions{
+		LabelSelector:      v.Get("labelSelector"),
+		DevtronManagedOnly: v.Get("devtronManaged") == "true",
+		Continue:           v.Get("continue"),
+	}
+	if limit := v.Get("limit"); len(limit) > 0 {
+		options.Limit, err = strconv.ParseInt(limit, 10, 64)
+		if err != nil || options.Limit <= 0 {
+			common.WriteJsonResp(w, errors.Errorf("invalid limit %q", limit), nil, http.StatusBadRequest)
+			return
+		}
+	}
+	bean, err := impl.environmentClusterMappingsService.FindById(envId)
+	if err != nil {
+		impl.logger.Errorw("service err, ListConfigObjects", "err", err, "envId", envId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceGlobalEnvironment, casbin.ActionGet, strings.ToLower(bean.EnvironmentIdentifier)); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+
+	list, err := impl.environmentClusterMappingsService.ListConfigObjects(r.Context(), bean, kind, options)
+	if err != nil {
+		impl.logger.Errorw("service err, ListConfigObjects", "err", err, "envId", envId, "kind", kind)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, list, http.StatusOK)
+}
+
+func (impl EnvironmentRestHandlerImpl) PreviewNamespace(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	clusterId, err := strconv.Atoi(v.Get("clusterId"))
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceGlobalEnvironment, casbin.ActionCreate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+
+	previewRequest := &request.NamespacePreviewRequest{
+		ClusterId:   clusterId,
+		Environment: v.Get("environment"),
+		Team:        v.Get("team"),
+		Namespace:   v.Get("namespace"),
+	}
+	preview, err := impl.environmentClusterMappingsService.PreviewNamespace(r.Context(), previewRequest)
+	if err != nil {
+		impl.logger.Errorw("service err, PreviewNamespace", "err", err, "request", previewRequest)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, preview, http.StatusOK)
+}
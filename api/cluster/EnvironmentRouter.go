@@ -71,5 +71,20 @@ func (impl EnvironmentRouterImpl) InitEnvironmentClusterMappingsRouter(environme
 	environmentClusterMappingsRouter.Path("/namespace/autocomplete").
 		Methods("GET").
 		HandlerFunc(impl.environmentClusterMappingsRestHandler.GetCombinedEnvironmentListForDropDownByClusterIds)
+	environmentClusterMappingsRouter.Path("/security-posture").
+		Methods("GET").
+		Queries("id", "{id}").
+		HandlerFunc(impl.environmentClusterMappingsRestHandler.GetSecurityPostureReport)
+	environmentClusterMappingsRouter.Path("/availability").
+		Methods("GET").
+		Queries("id", "{id}").
+		HandlerFunc(impl.environmentClusterMappingsRestHandler.GetEnvironmentAvailability)
+	environmentClusterMappingsRouter.Path("/config-objects").
+		Methods("GET").
+		Queries("id", "{id}", "kind", "{kind}").
+		HandlerFunc(impl.environmentClusterMappingsRestHandler.ListConfigObjects)
+	environmentClusterMappingsRouter.Path("/namespace/preview").
+		Methods("GET").
+		HandlerFunc(impl.environmentClusterMappingsRestHandler.PreviewNamespace)
 
 }
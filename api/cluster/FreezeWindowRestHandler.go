@@ -0,0 +1,142 @@
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/cluster"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type FreezeWindowRestHandler interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	FindAll(w http.ResponseWriter, r *http.Request)
+}
+
+type FreezeWindowRestHandlerImpl struct {
+	logger              *zap.SugaredLogger
+	freezeWindowService cluster.FreezeWindowService
+	userService         user.UserService
+	validator           *validator.Validate
+	enforcer            casbin.Enforcer
+}
+
+func NewFreezeWindowRestHandlerImpl(logger *zap.SugaredLogger, freezeWindowService cluster.FreezeWindowService,
+	userService user.UserService, validator *validator.Validate, enforcer casbin.Enforcer) *FreezeWindowRestHandlerImpl {
+	return &FreezeWindowRestHandlerImpl{
+		logger:              logger,
+		freezeWindowService: freezeWindowService,
+		userService:         userService,
+		validator:           validator,
+		enforcer:            enforcer,
+	}
+}
+
+func (impl FreezeWindowRestHandlerImpl) Create(w http.ResponseWriter, r *http.Request) {
+	impl.save(w, r, false)
+}
+
+func (impl FreezeWindowRestHandlerImpl) Update(w http.ResponseWriter, r *http.Request) {
+	impl.save(w, r, true)
+}
+
+// save is only allowed to admins as windows block changes of every user of the cluster
+func (impl FreezeWindowRestHandlerImpl) save(w http.ResponseWriter, r *http.Request, isUpdate bool) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean cluster.FreezeWindowBean
+	err = json.NewDecoder(r.Body).Decode(&bean)
+	if err != nil {
+		impl.logger.Errorw("request err, save freeze window", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if !isUpdate {
+		if err = impl.validator.Struct(bean); err != nil {
+			impl.logger.Errorw("validation err, save freeze window", "err", err, "payload", bean)
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	var res *cluster.FreezeWindowBean
+	if isUpdate {
+		res, err = impl.freezeWindowService.Update(&bean, userId)
+	} else {
+		res, err = impl.freezeWindowService.Create(&bean, userId)
+	}
+	if err != nil {
+		impl.logger.Errorw("service err, save freeze window", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (impl FreezeWindowRestHandlerImpl) Delete(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	err = impl.freezeWindowService.Delete(id, userId)
+	if err != nil {
+		impl.logger.Errorw("service err, delete freeze window", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (impl FreezeWindowRestHandlerImpl) FindAll(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	clusterId := 0
+	if value := r.URL.Query().Get("clusterId"); value != "" {
+		if clusterId, err = strconv.Atoi(value); err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceCluster, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	res, err := impl.freezeWindowService.FindAll(clusterId)
+	if err != nil {
+		impl.logger.Errorw("service err, FindAll freeze windows", "err", err, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
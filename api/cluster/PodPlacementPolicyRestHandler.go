@@ -0,0 +1,165 @@
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/cluster"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type PodPlacementPolicyRestHandler interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	FindAll(w http.ResponseWriter, r *http.Request)
+	GetEffectivePlacement(w http.ResponseWriter, r *http.Request)
+}
+
+type PodPlacementPolicyRestHandlerImpl struct {
+	logger                    *zap.SugaredLogger
+	podPlacementPolicyService cluster.PodPlacementPolicyService
+	userService               user.UserService
+	validator                 *validator.Validate
+	enforcer                  casbin.Enforcer
+}
+
+func NewPodPlacementPolicyRestHandlerImpl(logger *zap.SugaredLogger, podPlacementPolicyService cluster.PodPlacementPolicyService,
+	userService user.UserService, validator *validator.Validate, enforcer casbin.Enforcer) *PodPlacementPolicyRestHandlerImpl {
+	return &PodPlacementPolicyRestHandlerImpl{
+		logger:                    logger,
+		podPlacementPolicyService: podPlacementPolicyService,
+		userService:               userService,
+		validator:                 validator,
+		enforcer:                  enforcer,
+	}
+}
+
+func (impl PodPlacementPolicyRestHandlerImpl) Create(w http.ResponseWriter, r *http.Request) {
+	impl.save(w, r, false)
+}
+
+func (impl PodPlacementPolicyRestHandlerImpl) Update(w http.ResponseWriter, r *http.Request) {
+	impl.save(w, r, true)
+}
+
+// save is only allowed to admins as policies move pods of every user of the cluster
+func (impl PodPlacementPolicyRestHandlerImpl) save(w http.ResponseWriter, r *http.Request, isUpdate bool) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean cluster.PodPlacementPolicyBean
+	err = json.NewDecoder(r.Body).Decode(&bean)
+	if err != nil {
+		impl.logger.Errorw("request err, save pod placement policy", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if !isUpdate {
+		if err = impl.validator.Struct(bean); err != nil {
+			impl.logger.Errorw("validation err, save pod placement policy", "err", err, "payload", bean)
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	var res *cluster.PodPlacementPolicyBean
+	if isUpdate {
+		res, err = impl.podPlacementPolicyService.Update(&bean, userId)
+	} else {
+		res, err = impl.podPlacementPolicyService.Create(&bean, userId)
+	}
+	if err != nil {
+		impl.logger.Errorw("service err, save pod placement policy", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (impl PodPlacementPolicyRestHandlerImpl) Delete(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	err = impl.podPlacementPolicyService.Delete(id, userId)
+	if err != nil {
+		impl.logger.Errorw("service err, delete pod placement policy", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (impl PodPlacementPolicyRestHandlerImpl) FindAll(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	clusterId := 0
+	if value := r.URL.Query().Get("clusterId"); value != "" {
+		if clusterId, err = strconv.Atoi(value); err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceCluster, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	res, err := impl.podPlacementPolicyService.FindAll(clusterId)
+	if err != nil {
+		impl.logger.Errorw("service err, FindAll pod placement policies", "err", err, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+// GetEffectivePlacement is open to every user so that anyone can see why a job or terminal pod landed on a node
+func (impl PodPlacementPolicyRestHandlerImpl) GetEffectivePlacement(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	clusterId, err := strconv.Atoi(r.URL.Query().Get("clusterId"))
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	namespace := r.URL.Query().Get("namespace")
+	res, err := impl.podPlacementPolicyService.GetEffectivePlacement(clusterId, namespace)
+	if err != nil {
+		impl.logger.Errorw("service err, GetEffectivePlacement", "err", err, "clusterId", clusterId, "namespace", namespace)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
@@ -17,6 +17,18 @@ var ClusterWireSet = wire.NewSet(
 	wire.Bind(new(ClusterRestHandler), new(*ClusterRestHandlerImpl)),
 	NewClusterRouterImpl,
 	wire.Bind(new(ClusterRouter), new(*ClusterRouterImpl)),
+	repository.NewPodPlacementPolicyRepositoryImpl,
+	wire.Bind(new(repository.PodPlacementPolicyRepository), new(*repository.PodPlacementPolicyRepositoryImpl)),
+	cluster.NewPodPlacementPolicyServiceImpl,
+	wire.Bind(new(cluster.PodPlacementPolicyService), new(*cluster.PodPlacementPolicyServiceImpl)),
+	NewPodPlacementPolicyRestHandlerImpl,
+	wire.Bind(new(PodPlacementPolicyRestHandler), new(*PodPlacementPolicyRestHandlerImpl)),
+	repository.NewFreezeWindowRepositoryImpl,
+	wire.Bind(new(repository.FreezeWindowRepository), new(*repository.FreezeWindowRepositoryImpl)),
+	cluster.NewFreezeWindowServiceImpl,
+	wire.Bind(new(cluster.FreezeWindowService), new(*cluster.FreezeWindowServiceImpl)),
+	NewFreezeWindowRestHandlerImpl,
+	wire.Bind(new(FreezeWindowRestHandler), new(*FreezeWindowRestHandlerImpl)),
 
 	repository.NewEnvironmentRepositoryImpl,
 	wire.Bind(new(repository.EnvironmentRepository), new(*repository.EnvironmentRepositoryImpl)),
@@ -38,6 +50,18 @@ var ClusterWireSetEa = wire.NewSet(
 	wire.Bind(new(ClusterRestHandler), new(*ClusterRestHandlerImpl)),
 	NewClusterRouterImpl,
 	wire.Bind(new(ClusterRouter), new(*ClusterRouterImpl)),
+	repository.NewPodPlacementPolicyRepositoryImpl,
+	wire.Bind(new(repository.PodPlacementPolicyRepository), new(*repository.PodPlacementPolicyRepositoryImpl)),
+	cluster.NewPodPlacementPolicyServiceImpl,
+	wire.Bind(new(cluster.PodPlacementPolicyService), new(*cluster.PodPlacementPolicyServiceImpl)),
+	NewPodPlacementPolicyRestHandlerImpl,
+	wire.Bind(new(PodPlacementPolicyRestHandler), new(*PodPlacementPolicyRestHandlerImpl)),
+	repository.NewFreezeWindowRepositoryImpl,
+	wire.Bind(new(repository.FreezeWindowRepository), new(*repository.FreezeWindowRepositoryImpl)),
+	cluster.NewFreezeWindowServiceImpl,
+	wire.Bind(new(cluster.FreezeWindowService), new(*cluster.FreezeWindowServiceImpl)),
+	NewFreezeWindowRestHandlerImpl,
+	wire.Bind(new(FreezeWindowRestHandler), new(*FreezeWindowRestHandlerImpl)),
 	repository.NewEnvironmentRepositoryImpl,
 	wire.Bind(new(repository.EnvironmentRepository), new(*repository.EnvironmentRepositoryImpl)),
 	cluster.NewEnvironmentServiceImpl,
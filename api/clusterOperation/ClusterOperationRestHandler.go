@@ -0,0 +1,113 @@
+package clusterOperation
+
+import (
+	"errors"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/pagination"
+	"github.com/go-pg/pg"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type ClusterOperationRestHandler interface {
+	GetOperation(w http.ResponseWriter, r *http.Request)
+	ListOperations(w http.ResponseWriter, r *http.Request)
+}
+
+var clusterOperationListingContract = pagination.ListingContract{
+	SortColumns:      map[string]string{"startedOn": "started_on", "finishedOn": "finished_on", "state": "state", "operationType": "operation_type"},
+	DefaultSortBy:    "startedOn",
+	DefaultSortOrder: pagination.Desc,
+}
+
+type ClusterOperationRestHandlerImpl struct {
+	logger                  *zap.SugaredLogger
+	clusterOperationService clusterOperation.ClusterOperationService
+	userService             user.UserService
+	enforcer                casbin.Enforcer
+}
+
+func NewClusterOperationRestHandlerImpl(logger *zap.SugaredLogger, clusterOperationService clusterOperation.ClusterOperationService,
+	userService user.UserService, enforcer casbin.Enforcer) *ClusterOperationRestHandlerImpl {
+	return &ClusterOperationRestHandlerImpl{
+		logger:                  logger,
+		clusterOperationService: clusterOperationService,
+		userService:             userService,
+		enforcer:                enforcer,
+	}
+}
+
+// GetOperation returns the operation to the user who started it or to users with global access
+func (handler *ClusterOperationRestHandlerImpl) GetOperation(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	operation, err := handler.clusterOperationService.FindById(id)
+	if err == pg.ErrNoRows {
+		common.WriteJsonResp(w, &util.ApiError{HttpStatusCode: http.StatusNotFound, Code: strconv.Itoa(http.StatusNotFound), UserMessage: "operation not found"}, nil, http.StatusNotFound)
+		return
+	} else if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if operation.UserId != userId && !handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*") {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	common.WriteJsonResp(w, nil, operation, http.StatusOK)
+}
+
+// ListOperations filters on operationType, clusterId, state and userId, users without global access only see their own
+func (handler *ClusterOperationRestHandlerImpl) ListOperations(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	query := r.URL.Query()
+	listingRequest, err := pagination.ParseListingRequest(query, clusterOperationListingContract)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	filter := &repository.ClusterOperationFilter{OperationType: query.Get("operationType"), State: query.Get("state")}
+	if clusterId := query.Get("clusterId"); clusterId != "" {
+		if filter.ClusterId, err = strconv.Atoi(clusterId); err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	if filterUserId := query.Get("userId"); filterUserId != "" {
+		value, err := strconv.ParseInt(filterUserId, 10, 32)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+		filter.UserId = int32(value)
+	}
+	token := r.Header.Get("token")
+	if !handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*") {
+		filter.UserId = userId
+	}
+	response, err := handler.clusterOperationService.FindAll(filter, listingRequest)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
@@ -0,0 +1,37 @@
+package clusterOperation
+
+import (
+	"github.com/gorilla/mux"
+)
+
+type ClusterOperationRouter interface {
+	InitClusterOperationRouter(router *mux.Router)
+}
+
+type ClusterOperationRouterImpl struct {
+	clusterOperationRestHandler ClusterOperationRestHandler
+	orphanedResourceRestHandler OrphanedResourceRestHandler
+	secretRotationRestHandler   SecretRotationRestHandler
+}
+
+func NewClusterOperationRouterImpl(clusterOperationRestHandler ClusterOperationRestHandler,
+	orphanedResourceRestHandler OrphanedResourceRestHandler, secretRotationRestHandler SecretRotationRestHandler) *ClusterOperationRouterImpl {
+	return &ClusterOperationRouterImpl{
+		clusterOperationRestHandler: clusterOperationRestHandler,
+		orphanedResourceRestHandler: orphanedResourceRestHandler,
+		secretRotationRestHandler:   secretRotationRestHandler,
+	}
+}
+
+func (router ClusterOperationRouterImpl) InitClusterOperationRouter(operationRouter *mux.Router) {
+	operationRouter.Path("").
+		HandlerFunc(router.clusterOperationRestHandler.ListOperations).Methods("GET")
+	operationRouter.Path("/orphaned-resources").
+		HandlerFunc(router.orphanedResourceRestHandler.ScanOrphanedResources).Methods("GET")
+	operationRouter.Path("/orphaned-resources/cleanup").
+		HandlerFunc(router.orphanedResourceRestHandler.CleanupOrphanedResources).Methods("POST")
+	operationRouter.Path("/secret-rotation").
+		HandlerFunc(router.secretRotationRestHandler.RotateSecret).Methods("POST")
+	operationRouter.Path("/{id}").
+		HandlerFunc(router.clusterOperationRestHandler.GetOperation).Methods("GET")
+}
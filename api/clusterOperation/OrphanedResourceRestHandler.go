@@ -0,0 +1,98 @@
+package clusterOperation
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type OrphanedResourceRestHandler interface {
+	ScanOrphanedResources(w http.ResponseWriter, r *http.Request)
+	CleanupOrphanedResources(w http.ResponseWriter, r *http.Request)
+}
+
+type OrphanedResourceRestHandlerImpl struct {
+	logger                  *zap.SugaredLogger
+	orphanedResourceService clusterOperation.OrphanedResourceService
+	userService             user.UserService
+	validator               *validator.Validate
+	enforcer                casbin.Enforcer
+}
+
+func NewOrphanedResourceRestHandlerImpl(logger *zap.SugaredLogger, orphanedResourceService clusterOperation.OrphanedResourceService,
+	userService user.UserService, validator *validator.Validate, enforcer casbin.Enforcer) *OrphanedResourceRestHandlerImpl {
+	return &OrphanedResourceRestHandlerImpl{
+		logger:                  logger,
+		orphanedResourceService: orphanedResourceService,
+		userService:             userService,
+		validator:               validator,
+		enforcer:                enforcer,
+	}
+}
+
+// ScanOrphanedResources is the dry run of the cleanup, it scans the cluster of clusterId or every cluster without it
+func (handler *OrphanedResourceRestHandlerImpl) ScanOrphanedResources(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	clusterId := 0
+	if value := r.URL.Query().Get("clusterId"); value != "" {
+		if clusterId, err = strconv.Atoi(value); err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	token := r.Header.Get("token")
+	if !handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*") {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	result, err := handler.orphanedResourceService.Scan(r.Context(), clusterId)
+	if err != nil {
+		handler.logger.Errorw("service err, ScanOrphanedResources", "err", err, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, result, http.StatusOK)
+}
+
+// CleanupOrphanedResources requires the confirmation token of a scan and answers with the id of the cleanup operation
+func (handler *OrphanedResourceRestHandlerImpl) CleanupOrphanedResources(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request clusterOperation.OrphanCleanupRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, CleanupOrphanedResources", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if err = handler.validator.Struct(request); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if !handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionDelete, "*") {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	operationId, err := handler.orphanedResourceService.Cleanup(r.Context(), &request, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, CleanupOrphanedResources", "err", err, "clusterId", request.ClusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, &clusterOperation.ClusterOperationStartedResponse{OperationId: operationId}, http.StatusAccepted)
+}
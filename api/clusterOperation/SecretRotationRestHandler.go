@@ -0,0 +1,72 @@
+package clusterOperation
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+)
+
+type SecretRotationRestHandler interface {
+	RotateSecret(w http.ResponseWriter, r *http.Request)
+}
+
+type SecretRotationRestHandlerImpl struct {
+	logger                *zap.SugaredLogger
+	secretRotationService clusterOperation.SecretRotationService
+	userService           user.UserService
+	validator             *validator.Validate
+	enforcer              casbin.Enforcer
+}
+
+func NewSecretRotationRestHandlerImpl(logger *zap.SugaredLogger, secretRotationService clusterOperation.SecretRotationService,
+	userService user.UserService, validator *validator.Validate, enforcer casbin.Enforcer) *SecretRotationRestHandlerImpl {
+	return &SecretRotationRestHandlerImpl{
+		logger:                logger,
+		secretRotationService: secretRotationService,
+		userService:           userService,
+		validator:             validator,
+		enforcer:              enforcer,
+	}
+}
+
+// RotateSecret answers a dry run with the result per namespace and a rotation with the id of its operation
+func (handler *SecretRotationRestHandlerImpl) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request clusterOperation.SecretRotationRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, RotateSecret", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if err = handler.validator.Struct(request); err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if !handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*") {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	response, err := handler.secretRotationService.RotateSecret(r.Context(), &request, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, RotateSecret", "err", err, "clusterId", request.ClusterId, "secretName", request.SecretName)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	if response.DryRun {
+		common.WriteJsonResp(w, nil, response, http.StatusOK)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusAccepted)
+}
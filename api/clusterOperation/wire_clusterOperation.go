@@ -0,0 +1,26 @@
+package clusterOperation
+
+import (
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
+	"github.com/google/wire"
+)
+
+var ClusterOperationWireSet = wire.NewSet(
+	NewClusterOperationRouterImpl,
+	wire.Bind(new(ClusterOperationRouter), new(*ClusterOperationRouterImpl)),
+	NewClusterOperationRestHandlerImpl,
+	wire.Bind(new(ClusterOperationRestHandler), new(*ClusterOperationRestHandlerImpl)),
+	NewOrphanedResourceRestHandlerImpl,
+	wire.Bind(new(OrphanedResourceRestHandler), new(*OrphanedResourceRestHandlerImpl)),
+	NewSecretRotationRestHandlerImpl,
+	wire.Bind(new(SecretRotationRestHandler), new(*SecretRotationRestHandlerImpl)),
+	clusterOperation.NewClusterOperationServiceImpl,
+	wire.Bind(new(clusterOperation.ClusterOperationService), new(*clusterOperation.ClusterOperationServiceImpl)),
+	clusterOperation.NewOrphanedResourceServiceImpl,
+	wire.Bind(new(clusterOperation.OrphanedResourceService), new(*clusterOperation.OrphanedResourceServiceImpl)),
+	clusterOperation.NewSecretRotationServiceImpl,
+	wire.Bind(new(clusterOperation.SecretRotationService), new(*clusterOperation.SecretRotationServiceImpl)),
+	repository.NewClusterOperationRepositoryImpl,
+	wire.Bind(new(repository.ClusterOperationRepository), new(*repository.ClusterOperationRepositoryImpl)),
+)
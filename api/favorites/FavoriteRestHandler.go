@@ -0,0 +1,107 @@
+package favorites
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/favorites"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type FavoriteRestHandler interface {
+	ListFavorites(w http.ResponseWriter, r *http.Request)
+	SaveFavorite(w http.ResponseWriter, r *http.Request)
+	DeleteFavorite(w http.ResponseWriter, r *http.Request)
+}
+
+type FavoriteRestHandlerImpl struct {
+	logger          *zap.SugaredLogger
+	favoriteService favorites.FavoriteService
+	userService     user.UserService
+	validator       *validator.Validate
+}
+
+func NewFavoriteRestHandlerImpl(logger *zap.SugaredLogger, favoriteService favorites.FavoriteService,
+	userService user.UserService, validator *validator.Validate) *FavoriteRestHandlerImpl {
+	return &FavoriteRestHandlerImpl{
+		logger:          logger,
+		favoriteService: favoriteService,
+		userService:     userService,
+		validator:       validator,
+	}
+}
+
+// authenticate writes the error response and returns 0 when the user is not logged in, favorites are private to their
+// user so there is no further rbac
+func (handler *FavoriteRestHandlerImpl) authenticate(w http.ResponseWriter, r *http.Request) int32 {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return 0
+	}
+	return userId
+}
+
+func (handler *FavoriteRestHandlerImpl) ListFavorites(w http.ResponseWriter, r *http.Request) {
+	userId := handler.authenticate(w, r)
+	if userId == 0 {
+		return
+	}
+	favoriteType := r.URL.Query().Get("type")
+	if len(favoriteType) > 0 && favoriteType != favorites.FavoriteTypeTerminalPreset && favoriteType != favorites.FavoriteTypeResourceBookmark {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, "invalid favorite type "+favoriteType), http.StatusBadRequest)
+		return
+	}
+	results, err := handler.favoriteService.ListFavorites(userId, favoriteType)
+	if err != nil {
+		handler.logger.Errorw("service err, ListFavorites", "err", err, "userId", userId)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, results, http.StatusOK)
+}
+
+func (handler *FavoriteRestHandlerImpl) SaveFavorite(w http.ResponseWriter, r *http.Request) {
+	userId := handler.authenticate(w, r)
+	if userId == 0 {
+		return
+	}
+	var request favorites.FavoriteBean
+	err := common.DecodeAndValidateJson(r, &request, handler.validator)
+	if err != nil {
+		handler.logger.Errorw("request err, SaveFavorite", "err", err, "request", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	favorite, err := handler.favoriteService.SaveFavorite(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, SaveFavorite", "err", err, "userId", userId, "name", request.Name)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, favorite, http.StatusOK)
+}
+
+func (handler *FavoriteRestHandlerImpl) DeleteFavorite(w http.ResponseWriter, r *http.Request) {
+	userId := handler.authenticate(w, r)
+	if userId == 0 {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, "invalid favorite id"), http.StatusBadRequest)
+		return
+	}
+	err = handler.favoriteService.DeleteFavorite(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteFavorite", "err", err, "id", id)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, map[string]int{"id": id}, http.StatusOK)
+}
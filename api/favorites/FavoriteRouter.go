@@ -0,0 +1,26 @@
+package favorites
+
+import (
+	"github.com/gorilla/mux"
+)
+
+type FavoriteRouter interface {
+	InitFavoriteRouter(router *mux.Router)
+}
+
+type FavoriteRouterImpl struct {
+	favoriteRestHandler FavoriteRestHandler
+}
+
+func NewFavoriteRouterImpl(favoriteRestHandler FavoriteRestHandler) *FavoriteRouterImpl {
+	return &FavoriteRouterImpl{favoriteRestHandler: favoriteRestHandler}
+}
+
+func (router FavoriteRouterImpl) InitFavoriteRouter(favoriteRouter *mux.Router) {
+	favoriteRouter.Path("").
+		HandlerFunc(router.favoriteRestHandler.ListFavorites).Methods("GET")
+	favoriteRouter.Path("").
+		HandlerFunc(router.favoriteRestHandler.SaveFavorite).Methods("POST")
+	favoriteRouter.Path("/{id}").
+		HandlerFunc(router.favoriteRestHandler.DeleteFavorite).Methods("DELETE")
+}
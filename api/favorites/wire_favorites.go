@@ -0,0 +1,18 @@
+package favorites
+
+import (
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/pkg/favorites"
+	"github.com/google/wire"
+)
+
+var FavoriteWireSet = wire.NewSet(
+	NewFavoriteRouterImpl,
+	wire.Bind(new(FavoriteRouter), new(*FavoriteRouterImpl)),
+	NewFavoriteRestHandlerImpl,
+	wire.Bind(new(FavoriteRestHandler), new(*FavoriteRestHandlerImpl)),
+	favorites.NewFavoriteServiceImpl,
+	wire.Bind(new(favorites.FavoriteService), new(*favorites.FavoriteServiceImpl)),
+	repository.NewUserFavoriteRepositoryImpl,
+	wire.Bind(new(repository.UserFavoriteRepository), new(*repository.UserFavoriteRepositoryImpl)),
+)
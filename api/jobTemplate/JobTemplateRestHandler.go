@@ -0,0 +1,177 @@
+package jobTemplate
+
+import (
+	"net/http"
+
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/jobTemplate"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type JobTemplateRestHandler interface {
+	ListTemplates(w http.ResponseWriter, r *http.Request)
+	GetTemplate(w http.ResponseWriter, r *http.Request)
+	CreateTemplate(w http.ResponseWriter, r *http.Request)
+	UpdateTemplate(w http.ResponseWriter, r *http.Request)
+	DeleteTemplate(w http.ResponseWriter, r *http.Request)
+	RenderTemplate(w http.ResponseWriter, r *http.Request)
+}
+
+// RenderJobTemplateRequest carries the params of a render preview
+type RenderJobTemplateRequest struct {
+	Params map[string]string `json:"params"`
+}
+
+type JobTemplateRestHandlerImpl struct {
+	logger             *zap.SugaredLogger
+	jobTemplateService jobTemplate.JobTemplateService
+	userService        user.UserService
+	validator          *validator.Validate
+}
+
+func NewJobTemplateRestHandlerImpl(logger *zap.SugaredLogger, jobTemplateService jobTemplate.JobTemplateService,
+	userService user.UserService, validator *validator.Validate) *JobTemplateRestHandlerImpl {
+	return &JobTemplateRestHandlerImpl{
+		logger:             logger,
+		jobTemplateService: jobTemplateService,
+		userService:        userService,
+		validator:          validator,
+	}
+}
+
+// authorizeSuperAdmin writes the error response and returns 0 unless the logged in user is a super admin, job templates
+// are created on clusters so every endpoint is limited to super admins
+func (handler *JobTemplateRestHandlerImpl) authorizeSuperAdmin(w http.ResponseWriter, r *http.Request) int32 {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return 0
+	}
+	isSuperAdmin, err := handler.userService.IsSuperAdmin(int(userId))
+	if err != nil {
+		handler.logger.Errorw("service err, IsSuperAdmin", "err", err, "userId", userId)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return 0
+	}
+	if !isSuperAdmin {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+		return 0
+	}
+	return userId
+}
+
+func (handler *JobTemplateRestHandlerImpl) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	if handler.authorizeSuperAdmin(w, r) == 0 {
+		return
+	}
+	templates, err := handler.jobTemplateService.ListTemplates()
+	if err != nil {
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, templates, http.StatusOK)
+}
+
+func (handler *JobTemplateRestHandlerImpl) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	if handler.authorizeSuperAdmin(w, r) == 0 {
+		return
+	}
+	template, err := handler.jobTemplateService.GetTemplate(mux.Vars(r)["name"])
+	if err != nil {
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, template, http.StatusOK)
+}
+
+func (handler *JobTemplateRestHandlerImpl) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	userId := handler.authorizeSuperAdmin(w, r)
+	if userId == 0 {
+		return
+	}
+	var request jobTemplate.JobTemplateBean
+	err := common.DecodeJsonStrict(r, &request)
+	if err == nil {
+		err = common.ValidateRequest(handler.validator, request)
+	}
+	if err != nil {
+		handler.logger.Errorw("request err, CreateTemplate", "err", err, "request", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	template, err := handler.jobTemplateService.CreateTemplate(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, CreateTemplate", "err", err, "name", request.Name)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, template, http.StatusOK)
+}
+
+// UpdateTemplate replaces the template and description, the name is taken from the path
+func (handler *JobTemplateRestHandlerImpl) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	userId := handler.authorizeSuperAdmin(w, r)
+	if userId == 0 {
+		return
+	}
+	var request jobTemplate.JobTemplateBean
+	err := common.DecodeJsonStrict(r, &request)
+	if err == nil {
+		request.Name = mux.Vars(r)["name"]
+		err = common.ValidateRequest(handler.validator, request)
+	}
+	if err != nil {
+		handler.logger.Errorw("request err, UpdateTemplate", "err", err, "request", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	template, err := handler.jobTemplateService.UpdateTemplate(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, UpdateTemplate", "err", err, "name", request.Name)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, template, http.StatusOK)
+}
+
+func (handler *JobTemplateRestHandlerImpl) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	userId := handler.authorizeSuperAdmin(w, r)
+	if userId == 0 {
+		return
+	}
+	name := mux.Vars(r)["name"]
+	err := handler.jobTemplateService.DeleteTemplate(name, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteTemplate", "err", err, "name", name)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, nil, http.StatusOK)
+}
+
+// RenderTemplate previews the Job yaml for the params without creating it
+func (handler *JobTemplateRestHandlerImpl) RenderTemplate(w http.ResponseWriter, r *http.Request) {
+	if handler.authorizeSuperAdmin(w, r) == 0 {
+		return
+	}
+	var request RenderJobTemplateRequest
+	err := common.DecodeJsonStrict(r, &request)
+	if err != nil {
+		handler.logger.Errorw("request err, RenderTemplate", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+		return
+	}
+	name := mux.Vars(r)["name"]
+	content, err := handler.jobTemplateService.RenderJobTemplate(name, request.Params)
+	if err != nil {
+		handler.logger.Errorw("service err, RenderTemplate", "err", err, "name", name)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, string(content), http.StatusOK)
+}
@@ -0,0 +1,32 @@
+package jobTemplate
+
+import (
+	"github.com/gorilla/mux"
+)
+
+type JobTemplateRouter interface {
+	InitJobTemplateRouter(router *mux.Router)
+}
+
+type JobTemplateRouterImpl struct {
+	jobTemplateRestHandler JobTemplateRestHandler
+}
+
+func NewJobTemplateRouterImpl(jobTemplateRestHandler JobTemplateRestHandler) *JobTemplateRouterImpl {
+	return &JobTemplateRouterImpl{jobTemplateRestHandler: jobTemplateRestHandler}
+}
+
+func (router JobTemplateRouterImpl) InitJobTemplateRouter(jobTemplateRouter *mux.Router) {
+	jobTemplateRouter.Path("").
+		HandlerFunc(router.jobTemplateRestHandler.ListTemplates).Methods("GET")
+	jobTemplateRouter.Path("").
+		HandlerFunc(router.jobTemplateRestHandler.CreateTemplate).Methods("POST")
+	jobTemplateRouter.Path("/{name}").
+		HandlerFunc(router.jobTemplateRestHandler.GetTemplate).Methods("GET")
+	jobTemplateRouter.Path("/{name}").
+		HandlerFunc(router.jobTemplateRestHandler.UpdateTemplate).Methods("PUT")
+	jobTemplateRouter.Path("/{name}").
+		HandlerFunc(router.jobTemplateRestHandler.DeleteTemplate).Methods("DELETE")
+	jobTemplateRouter.Path("/{name}/render").
+		HandlerFunc(router.jobTemplateRestHandler.RenderTemplate).Methods("POST")
+}
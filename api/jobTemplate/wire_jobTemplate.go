@@ -0,0 +1,18 @@
+package jobTemplate
+
+import (
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/pkg/jobTemplate"
+	"github.com/google/wire"
+)
+
+var JobTemplateWireSet = wire.NewSet(
+	NewJobTemplateRouterImpl,
+	wire.Bind(new(JobTemplateRouter), new(*JobTemplateRouterImpl)),
+	NewJobTemplateRestHandlerImpl,
+	wire.Bind(new(JobTemplateRestHandler), new(*JobTemplateRestHandlerImpl)),
+	jobTemplate.NewJobTemplateServiceImpl,
+	wire.Bind(new(jobTemplate.JobTemplateService), new(*jobTemplate.JobTemplateServiceImpl)),
+	repository.NewJobTemplateRepositoryImpl,
+	wire.Bind(new(repository.JobTemplateRepository), new(*repository.JobTemplateRepositoryImpl)),
+)
@@ -24,6 +24,7 @@ import (
 	application2 "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
 	"github.com/devtron-labs/devtron/api/bean"
 	client "github.com/devtron-labs/devtron/api/helm-app"
 	"github.com/devtron-labs/devtron/api/restHandler/common"
@@ -38,6 +39,7 @@ import (
 	"github.com/devtron-labs/devtron/pkg/cluster"
 	"github.com/devtron-labs/devtron/pkg/deploymentGroup"
 	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/resourceAction"
 	"github.com/devtron-labs/devtron/pkg/team"
 	"github.com/devtron-labs/devtron/pkg/user"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
@@ -48,6 +50,10 @@ import (
 	"github.com/gorilla/mux"
 	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
+	"io"
+	errors2 "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"net/http"
 	"strconv"
 	"strings"
@@ -66,6 +72,19 @@ type AppListingRestHandler interface {
 	GetHostUrlsByBatch(w http.ResponseWriter, r *http.Request)
 
 	ManualSyncAcdPipelineDeploymentStatus(w http.ResponseWriter, r *http.Request)
+	PauseRollout(w http.ResponseWriter, r *http.Request)
+	ResumeRollout(w http.ResponseWriter, r *http.Request)
+	PromoteRollout(w http.ResponseWriter, r *http.Request)
+	AbortRollout(w http.ResponseWriter, r *http.Request)
+	GetRolloutRevisionHistory(w http.ResponseWriter, r *http.Request)
+	GetAppCronJobs(w http.ResponseWriter, r *http.Request)
+	SuspendCronJob(w http.ResponseWriter, r *http.Request)
+	ResumeCronJob(w http.ResponseWriter, r *http.Request)
+	DeleteAppPod(w http.ResponseWriter, r *http.Request)
+	RestartAppWorkload(w http.ResponseWriter, r *http.Request)
+	ScaleAppWorkload(w http.ResponseWriter, r *http.Request)
+	GetResourceActionMatrix(w http.ResponseWriter, r *http.Request)
+	SnapshotAppPvc(w http.ResponseWriter, r *http.Request)
 }
 
 type AppListingRestHandlerImpl struct {
@@ -87,6 +106,7 @@ type AppListingRestHandlerImpl struct {
 	cdApplicationStatusUpdateHandler cron.CdApplicationStatusUpdateHandler
 	pipelineRepository               pipelineConfig.PipelineRepository
 	appStatusService                 appStatus.AppStatusService
+	resourceActionService            resourceAction.ResourceActionService
 }
 
 type AppStatus struct {
@@ -108,7 +128,8 @@ func NewAppListingRestHandlerImpl(application application.ServiceClient,
 	argoUserService argo.ArgoUserService, k8sApplicationService k8s.K8sApplicationService, installedAppService service1.InstalledAppService,
 	cdApplicationStatusUpdateHandler cron.CdApplicationStatusUpdateHandler,
 	pipelineRepository pipelineConfig.PipelineRepository,
-	appStatusService appStatus.AppStatusService) *AppListingRestHandlerImpl {
+	appStatusService appStatus.AppStatusService,
+	resourceActionService resourceAction.ResourceActionService) *AppListingRestHandlerImpl {
 	appListingHandler := &AppListingRestHandlerImpl{
 		application:                      application,
 		appListingService:                appListingService,
@@ -128,6 +149,7 @@ func NewAppListingRestHandlerImpl(application application.ServiceClient,
 		cdApplicationStatusUpdateHandler: cdApplicationStatusUpdateHandler,
 		pipelineRepository:               pipelineRepository,
 		appStatusService:                 appStatusService,
+		resourceActionService:            resourceActionService,
 	}
 	return appListingHandler
 }
@@ -804,6 +826,12 @@ func (handler AppListingRestHandlerImpl) fetchResourceTree(w http.ResponseWriter
 			}
 		}
 		appDetail.ResourceTree = util2.InterfaceToMapAdapter(resp)
+		for _, node := range resp.Nodes {
+			if node.Kind == util.K8sClusterResourceRolloutKind && node.Group == util.K8sClusterResourceRolloutGroup {
+				handler.addRolloutStatus(r.Context(), appDetail, node.Namespace, node.Name)
+				break
+			}
+		}
 		if resp.Status == string(health.HealthStatusHealthy) {
 			err = handler.cdApplicationStatusUpdateHandler.SyncPipelineStatusForResourceTreeCall(cdPipeline)
 			if err != nil {
@@ -834,6 +862,12 @@ func (handler AppListingRestHandlerImpl) fetchResourceTree(w http.ResponseWriter
 			resourceTree := util2.InterfaceToMapAdapter(detail.ResourceTreeResponse)
 			resourceTree["status"] = detail.ApplicationStatus
 			appDetail.ResourceTree = resourceTree
+			for _, node := range detail.ResourceTreeResponse.GetNodes() {
+				if node.Kind == util.K8sClusterResourceRolloutKind && node.Group == util.K8sClusterResourceRolloutGroup {
+					handler.addRolloutStatus(r.Context(), appDetail, node.Namespace, node.Name)
+					break
+				}
+			}
 			handler.logger.Warnw("appName and envName not found - avoiding resource tree call", "app", appDetail.AppName, "env", appDetail.EnvironmentName)
 		} else {
 			appDetail.ResourceTree = map[string]interface{}{}
@@ -845,6 +879,17 @@ func (handler AppListingRestHandlerImpl) fetchResourceTree(w http.ResponseWriter
 	return appDetail
 }
 
+// addRolloutStatus adds the argo rollout phase and canary weights to the resource tree, failures are only logged as
+// the resource tree is still usable without it
+func (handler AppListingRestHandlerImpl) addRolloutStatus(ctx context.Context, appDetail bean.AppDetailContainer, namespace string, name string) {
+	rolloutStatus, err := handler.k8sApplicationService.GetRolloutStatus(ctx, appDetail.ClusterId, namespace, name)
+	if err != nil {
+		handler.logger.Errorw("error in fetching rollout status", "err", err, "appId", appDetail.AppId, "envId", appDetail.EnvironmentId, "rollout", name)
+		return
+	}
+	appDetail.ResourceTree["rolloutStatus"] = rolloutStatus
+}
+
 func (handler AppListingRestHandlerImpl) ManualSyncAcdPipelineDeploymentStatus(w http.ResponseWriter, r *http.Request) {
 	token := r.Header.Get("token")
 	vars := mux.Vars(r)
@@ -886,3 +931,418 @@ func (handler AppListingRestHandlerImpl) ManualSyncAcdPipelineDeploymentStatus(w
 	}
 	common.WriteJsonResp(w, nil, "App synced successfully.", http.StatusOK)
 }
+
+func (handler AppListingRestHandlerImpl) PauseRollout(w http.ResponseWriter, r *http.Request) {
+	handler.updateRolloutPaused(w, r, true)
+}
+
+func (handler AppListingRestHandlerImpl) ResumeRollout(w http.ResponseWriter, r *http.Request) {
+	handler.updateRolloutPaused(w, r, false)
+}
+
+func (handler AppListingRestHandlerImpl) updateRolloutPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	rollout, ok := handler.resolveAppRollout(w, r, casbin.ActionGet)
+	if !ok || !handler.checkResourceAction(w, &rollout.appEnvironment, rolloutGVK, resourceAction.VerbPatch) {
+		return
+	}
+	var err error
+	if paused {
+		err = handler.k8sApplicationService.PauseRollout(util.ContextWithUserId(r.Context(), rollout.userId), rollout.clusterId, rollout.namespace, rollout.name)
+	} else {
+		err = handler.k8sApplicationService.ResumeRollout(util.ContextWithUserId(r.Context(), rollout.userId), rollout.clusterId, rollout.namespace, rollout.name)
+	}
+	if err != nil {
+		handler.logger.Errorw("service err, updateRolloutPaused", "err", err, "appId", rollout.appId, "envId", rollout.envId, "rollout", rollout.name, "paused", paused)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	handler.logger.Infow("rollout paused state updated", "appId", rollout.appId, "envId", rollout.envId, "rollout", rollout.name, "paused", paused, "userId", rollout.userId)
+	common.WriteJsonResp(w, nil, map[string]interface{}{"name": rollout.name, "paused": paused}, http.StatusOK)
+}
+
+func (handler AppListingRestHandlerImpl) PromoteRollout(w http.ResponseWriter, r *http.Request) {
+	fullPromotion := false
+	if full := r.URL.Query().Get("full"); full != "" {
+		var err error
+		fullPromotion, err = strconv.ParseBool(full)
+		if err != nil {
+			handler.logger.Errorw("request err, PromoteRollout", "err", err, "full", full)
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	rollout, ok := handler.resolveAppRollout(w, r, casbin.ActionGet)
+	if !ok || !handler.checkResourceAction(w, &rollout.appEnvironment, rolloutGVK, resourceAction.VerbPatch) {
+		return
+	}
+	err := handler.k8sApplicationService.PromoteRollout(util.ContextWithUserId(r.Context(), rollout.userId), rollout.clusterId, rollout.namespace, rollout.name, fullPromotion)
+	if err != nil {
+		handler.logger.Errorw("service err, PromoteRollout", "err", err, "appId", rollout.appId, "envId", rollout.envId, "rollout", rollout.name, "fullPromotion", fullPromotion)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	handler.logger.Infow("rollout promoted", "appId", rollout.appId, "envId", rollout.envId, "rollout", rollout.name, "fullPromotion", fullPromotion, "userId", rollout.userId)
+	common.WriteJsonResp(w, nil, map[string]interface{}{"name": rollout.name, "fullPromotion": fullPromotion}, http.StatusOK)
+}
+
+func (handler AppListingRestHandlerImpl) AbortRollout(w http.ResponseWriter, r *http.Request) {
+	rollout, ok := handler.resolveAppRollout(w, r, casbin.ActionGet)
+	if !ok || !handler.checkResourceAction(w, &rollout.appEnvironment, rolloutGVK, resourceAction.VerbPatch) {
+		return
+	}
+	err := handler.k8sApplicationService.AbortRollout(util.ContextWithUserId(r.Context(), rollout.userId), rollout.clusterId, rollout.namespace, rollout.name)
+	if err != nil {
+		handler.logger.Errorw("service err, AbortRollout", "err", err, "appId", rollout.appId, "envId", rollout.envId, "rollout", rollout.name)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	handler.logger.Infow("rollout aborted", "appId", rollout.appId, "envId", rollout.envId, "rollout", rollout.name, "userId", rollout.userId)
+	common.WriteJsonResp(w, nil, map[string]interface{}{"name": rollout.name, "aborted": true}, http.StatusOK)
+}
+
+func (handler AppListingRestHandlerImpl) GetRolloutRevisionHistory(w http.ResponseWriter, r *http.Request) {
+	rollout, ok := handler.resolveAppRollout(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	revisions, err := handler.k8sApplicationService.GetRolloutRevisionHistory(r.Context(), rollout.clusterId, rollout.namespace, rollout.name)
+	if err != nil {
+		handler.logger.Errorw("service err, GetRolloutRevisionHistory", "err", err, "appId", rollout.appId, "envId", rollout.envId, "rollout", rollout.name)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, revisions, http.StatusOK)
+}
+
+// GetAppCronJobs lists the cronjobs of the app in the environment, labelSelector filters them further and lastRun=true
+// adds the status of the most recent job of each cronjob
+func (handler AppListingRestHandlerImpl) GetAppCronJobs(w http.ResponseWriter, r *http.Request) {
+	withLastRun := false
+	if lastRun := r.URL.Query().Get("lastRun"); lastRun != "" {
+		var err error
+		withLastRun, err = strconv.ParseBool(lastRun)
+		if err != nil {
+			handler.logger.Errorw("request err, GetAppCronJobs", "err", err, "lastRun", lastRun)
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	labelSelector := r.URL.Query().Get("labelSelector")
+	if _, err := labels.Parse(labelSelector); err != nil {
+		handler.logger.Errorw("request err, GetAppCronJobs", "err", err, "labelSelector", labelSelector)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	appEnv, ok := handler.resolveAppEnvironment(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	cronJobs, err := handler.k8sApplicationService.ListAppCronJobs(r.Context(), appEnv.clusterId, appEnv.namespace, appEnv.appId, appEnv.envId, labelSelector, withLastRun)
+	if err != nil {
+		handler.logger.Errorw("service err, GetAppCronJobs", "err", err, "appId", appEnv.appId, "envId", appEnv.envId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, cronJobs, http.StatusOK)
+}
+
+func (handler AppListingRestHandlerImpl) SuspendCronJob(w http.ResponseWriter, r *http.Request) {
+	handler.updateCronJobSuspended(w, r, true)
+}
+
+func (handler AppListingRestHandlerImpl) ResumeCronJob(w http.ResponseWriter, r *http.Request) {
+	handler.updateCronJobSuspended(w, r, false)
+}
+
+func (handler AppListingRestHandlerImpl) updateCronJobSuspended(w http.ResponseWriter, r *http.Request, suspended bool) {
+	appEnv, ok := handler.resolveAppEnvironment(w, r, casbin.ActionGet)
+	if !ok || !handler.checkResourceAction(w, appEnv, cronJobGVK, resourceAction.VerbPatch) {
+		return
+	}
+	name := mux.Vars(r)["name"]
+	err := handler.k8sApplicationService.SetAppCronJobSuspended(util.ContextWithUserId(r.Context(), appEnv.userId), appEnv.clusterId, appEnv.namespace, appEnv.appId, appEnv.envId, name, suspended)
+	if err == util.ErrCronJobNotFound {
+		common.WriteJsonResp(w, err, "cronjob is not deployed for the app in this environment", http.StatusNotFound)
+		return
+	} else if err != nil {
+		handler.logger.Errorw("service err, updateCronJobSuspended", "err", err, "appId", appEnv.appId, "envId", appEnv.envId, "cronJob", name, "suspended", suspended)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	handler.logger.Infow("cronjob suspended state updated", "appId", appEnv.appId, "envId", appEnv.envId, "cronJob", name, "suspended", suspended, "userId", appEnv.userId)
+	common.WriteJsonResp(w, nil, map[string]interface{}{"name": name, "suspended": suspended}, http.StatusOK)
+}
+
+// DeleteAppPod takes gracePeriodSeconds and force as query params, pods not owned by a controller are only deleted with force
+func (handler AppListingRestHandlerImpl) DeleteAppPod(w http.ResponseWriter, r *http.Request) {
+	var gracePeriodSeconds *int64
+	if value := r.URL.Query().Get("gracePeriodSeconds"); len(value) > 0 {
+		gracePeriod, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || gracePeriod < 0 {
+			common.WriteJsonResp(w, fmt.Errorf("invalid gracePeriodSeconds %q", value), nil, http.StatusBadRequest)
+			return
+		}
+		gracePeriodSeconds = &gracePeriod
+	}
+	force := r.URL.Query().Get("force") == "true"
+	appEnv, ok := handler.resolveAppEnvironment(w, r, casbin.ActionGet)
+	if !ok || !handler.checkResourceAction(w, appEnv, schema.GroupVersionKind{Version: util.V1VERSION, Kind: kube.PodKind}, resourceAction.VerbDelete) {
+		return
+	}
+	name := mux.Vars(r)["name"]
+	state, err := handler.k8sApplicationService.DeleteAppPod(util.ContextWithUserId(r.Context(), appEnv.userId), appEnv.clusterId, appEnv.namespace, appEnv.appId, appEnv.envId, name, gracePeriodSeconds, force)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteAppPod", "err", err, "appId", appEnv.appId, "envId", appEnv.envId, "pod", name, "force", force)
+		writeK8sActionError(w, err)
+		return
+	}
+	handler.logger.Infow("pod deleted", "appId", appEnv.appId, "envId", appEnv.envId, "pod", name, "force", force, "userId", appEnv.userId)
+	common.WriteJsonResp(w, nil, state, http.StatusOK)
+}
+
+func (handler AppListingRestHandlerImpl) RestartAppWorkload(w http.ResponseWriter, r *http.Request) {
+	appEnv, ok := handler.resolveAppEnvironment(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	kind, name := vars["kind"], vars["name"]
+	if !handler.checkResourceAction(w, appEnv, schema.GroupVersionKind{Group: util.AppsGroup, Version: util.V1VERSION, Kind: kind}, resourceAction.VerbPatch) {
+		return
+	}
+	state, err := handler.k8sApplicationService.RestartAppWorkload(util.ContextWithUserId(r.Context(), appEnv.userId), appEnv.clusterId, appEnv.namespace, appEnv.appId, appEnv.envId, kind, name)
+	if err != nil {
+		handler.logger.Errorw("service err, RestartAppWorkload", "err", err, "appId", appEnv.appId, "envId", appEnv.envId, "kind", kind, "name", name)
+		writeK8sActionError(w, err)
+		return
+	}
+	handler.logger.Infow("workload restarted", "appId", appEnv.appId, "envId", appEnv.envId, "kind", kind, "name", name, "userId", appEnv.userId)
+	common.WriteJsonResp(w, nil, state, http.StatusOK)
+}
+
+type scaleWorkloadRequest struct {
+	Replicas *int32 `json:"replicas"`
+}
+
+func (handler AppListingRestHandlerImpl) ScaleAppWorkload(w http.ResponseWriter, r *http.Request) {
+	var request scaleWorkloadRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		handler.logger.Errorw("request err, ScaleAppWorkload", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if request.Replicas == nil || *request.Replicas < 0 {
+		common.WriteJsonResp(w, fmt.Errorf("replicas must be zero or more"), nil, http.StatusBadRequest)
+		return
+	}
+	appEnv, ok := handler.resolveAppEnvironment(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	kind, name := vars["kind"], vars["name"]
+	if !handler.checkResourceAction(w, appEnv, schema.GroupVersionKind{Group: util.AppsGroup, Version: util.V1VERSION, Kind: kind}, resourceAction.VerbUpdate) {
+		return
+	}
+	state, err := handler.k8sApplicationService.ScaleAppWorkload(util.ContextWithUserId(r.Context(), appEnv.userId), appEnv.clusterId, appEnv.namespace, appEnv.appId, appEnv.envId, kind, name, *request.Replicas)
+	if err != nil {
+		handler.logger.Errorw("service err, ScaleAppWorkload", "err", err, "appId", appEnv.appId, "envId", appEnv.envId, "kind", kind, "name", name, "replicas", *request.Replicas)
+		writeK8sActionError(w, err)
+		return
+	}
+	handler.logger.Infow("workload scaled", "appId", appEnv.appId, "envId", appEnv.envId, "kind", kind, "name", name, "replicas", *request.Replicas, "userId", appEnv.userId)
+	common.WriteJsonResp(w, nil, state, http.StatusOK)
+}
+
+// GetResourceActionMatrix returns the actions on the kubernetes resources of the app the user given by the userId query
+// param may take in the environment, it is restricted to super admins
+func (handler AppListingRestHandlerImpl) GetResourceActionMatrix(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	isSuperAdmin, err := handler.userService.IsSuperAdmin(int(userId))
+	if err != nil {
+		handler.logger.Errorw("service err, GetResourceActionMatrix", "err", err, "userId", userId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	if !isSuperAdmin {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	vars := mux.Vars(r)
+	appId, err := strconv.Atoi(vars["appId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	envId, err := strconv.Atoi(vars["envId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	matrixUserId, err := strconv.Atoi(r.URL.Query().Get("userId"))
+	if err != nil || matrixUserId <= 0 {
+		common.WriteJsonResp(w, fmt.Errorf("invalid userId %q", r.URL.Query().Get("userId")), nil, http.StatusBadRequest)
+		return
+	}
+	matrix, err := handler.resourceActionService.GetEffectiveMatrix(int32(matrixUserId), appId, envId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetResourceActionMatrix", "err", err, "userId", matrixUserId, "appId", appId, "envId", envId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, matrix, http.StatusOK)
+}
+
+var rolloutGVK = schema.GroupVersionKind{Group: util.K8sClusterResourceRolloutGroup, Version: "v1alpha1", Kind: util.K8sClusterResourceRolloutKind}
+var cronJobGVK = schema.GroupVersionKind{Group: util.BatchGroup, Version: util.V1VERSION, Kind: util.K8sClusterResourceCronJobKind}
+var volumeSnapshotGVK = schema.GroupVersionKind{Group: util.VolumeSnapshotGVR.Group, Version: util.VolumeSnapshotGVR.Version, Kind: util.VolumeSnapshotKind}
+
+// checkResourceAction enforces the resource action policy for the actions on the kubernetes resources of the app, the
+// response is written when false is returned
+func (handler AppListingRestHandlerImpl) checkResourceAction(w http.ResponseWriter, appEnv *appEnvironment, gvk schema.GroupVersionKind, verb string) bool {
+	user := &resourceAction.ResourceActionUser{UserId: appEnv.userId, AppId: appEnv.appId}
+	err := handler.resourceActionService.CheckResourceAction(user, appEnv.clusterId, appEnv.namespace, gvk, verb)
+	if denied, ok := err.(*resourceAction.ErrResourceActionDenied); ok {
+		common.WriteJsonResp(w, denied, "Unauthorized User", http.StatusForbidden)
+		return false
+	} else if err != nil {
+		handler.logger.Errorw("service err, checkResourceAction", "err", err, "appId", appEnv.appId, "envId", appEnv.envId, "kind", gvk.Kind, "verb", verb)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// writeK8sActionError keeps the status of typed kubernetes errors, not found, conflict or invalid are not server errors.
+// Freeze window errors carry their own status
+func writeK8sActionError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch err {
+	case util.ErrPodNotControlled:
+		common.WriteJsonResp(w, err, "pod is not owned by a controller and would not be recreated, delete it with force", http.StatusConflict)
+		return
+	case util.ErrWorkloadKindNotSupported:
+		status = http.StatusBadRequest
+	}
+	if statusErr, ok := err.(errors2.APIStatus); ok && statusErr.Status().Code > 0 {
+		status = int(statusErr.Status().Code)
+		err = &util.ApiError{HttpStatusCode: status, Code: strconv.Itoa(status), UserMessage: statusErr.Status().Message, InternalMessage: err.Error()}
+	}
+	common.WriteJsonResp(w, err, nil, status)
+}
+
+type snapshotPvcRequest struct {
+	SnapshotClassName string `json:"snapshotClassName"`
+}
+
+// SnapshotAppPvc takes a volume snapshot of a pvc of the app, the body is optional and an empty snapshotClassName uses
+// the default snapshot class of the cluster
+func (handler AppListingRestHandlerImpl) SnapshotAppPvc(w http.ResponseWriter, r *http.Request) {
+	var request snapshotPvcRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		handler.logger.Errorw("request err, SnapshotAppPvc", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	appEnv, ok := handler.resolveAppEnvironment(w, r, casbin.ActionGet)
+	if !ok || !handler.checkResourceAction(w, appEnv, volumeSnapshotGVK, resourceAction.VerbCreate) {
+		return
+	}
+	name := mux.Vars(r)["name"]
+	snapshot, err := handler.k8sApplicationService.SnapshotAppPvc(util.ContextWithUserId(r.Context(), appEnv.userId), appEnv.clusterId, appEnv.namespace, appEnv.appId, appEnv.envId, name, request.SnapshotClassName)
+	if err == util.ErrPvcNotMounted {
+		common.WriteJsonResp(w, err, "pvc is not mounted by the app in this environment", http.StatusNotFound)
+		return
+	} else if err != nil {
+		handler.logger.Errorw("service err, SnapshotAppPvc", "err", err, "appId", appEnv.appId, "envId", appEnv.envId, "pvc", name)
+		writeK8sActionError(w, err)
+		return
+	}
+	handler.logger.Infow("pvc snapshot created", "appId", appEnv.appId, "envId", appEnv.envId, "pvc", name, "snapshot", snapshot.Name, "userId", appEnv.userId)
+	common.WriteJsonResp(w, nil, snapshot, http.StatusOK)
+}
+
+type appEnvironment struct {
+	userId    int32
+	appId     int
+	envId     int
+	clusterId int
+	namespace string
+}
+
+type appRollout struct {
+	appEnvironment
+	name string
+}
+
+// resolveAppEnvironment authorizes the rbac action for the app and environment in the path and finds the cluster and
+// namespace the app is deployed to, the error response is already written when ok is false
+func (handler AppListingRestHandlerImpl) resolveAppEnvironment(w http.ResponseWriter, r *http.Request, action string) (*appEnvironment, bool) {
+	token := r.Header.Get("token")
+	vars := mux.Vars(r)
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return nil, false
+	}
+	appId, err := strconv.Atoi(vars["appId"])
+	if err != nil {
+		handler.logger.Errorw("request err, resolveAppEnvironment", "err", err, "appId", vars["appId"])
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return nil, false
+	}
+	envId, err := strconv.Atoi(vars["envId"])
+	if err != nil {
+		handler.logger.Errorw("request err, resolveAppEnvironment", "err", err, "envId", vars["envId"])
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return nil, false
+	}
+	// RBAC enforcer applying
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, action, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return nil, false
+	}
+	object = handler.enforcerUtil.GetEnvRBACNameByAppId(appId, envId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, action, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return nil, false
+	}
+	//RBAC enforcer Ends
+	appDetail, err := handler.appListingService.FetchAppDetails(r.Context(), appId, envId)
+	if err != nil {
+		handler.logger.Errorw("service err, resolveAppEnvironment", "err", err, "appId", appId, "envId", envId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return nil, false
+	}
+	return &appEnvironment{
+		userId:    userId,
+		appId:     appId,
+		envId:     envId,
+		clusterId: appDetail.ClusterId,
+		namespace: appDetail.Namespace,
+	}, true
+}
+
+// resolveAppRollout is resolveAppEnvironment followed by finding the rollout the app is deployed as
+func (handler AppListingRestHandlerImpl) resolveAppRollout(w http.ResponseWriter, r *http.Request, action string) (*appRollout, bool) {
+	appEnv, ok := handler.resolveAppEnvironment(w, r, action)
+	if !ok {
+		return nil, false
+	}
+	appId, envId := appEnv.appId, appEnv.envId
+	rolloutName, err := handler.k8sApplicationService.FindAppRolloutName(r.Context(), appEnv.clusterId, appEnv.namespace, appId, envId)
+	if err == util.ErrRolloutNotFound {
+		common.WriteJsonResp(w, err, "app is not deployed as a rollout in this environment", http.StatusNotFound)
+		return nil, false
+	} else if err != nil {
+		handler.logger.Errorw("service err, resolveAppRollout", "err", err, "appId", appId, "envId", envId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return nil, false
+	}
+	return &appRollout{appEnvironment: *appEnv, name: rolloutName}, true
+}
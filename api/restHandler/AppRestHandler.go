@@ -18,14 +18,16 @@
 package restHandler
 
 import (
-	"encoding/json"
 	"fmt"
 	client "github.com/devtron-labs/devtron/api/helm-app"
 	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/app"
 	"github.com/devtron-labs/devtron/pkg/bean"
 	"github.com/devtron-labs/devtron/pkg/user"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/pagination"
 	"github.com/devtron-labs/devtron/util/rbac"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
@@ -33,14 +35,31 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
+const appMetaInfoExpandDeployments = "deployments"
+
+var labelListingContract = pagination.ListingContract{
+	SortColumns:      map[string]string{"key": "key", "value": "value", "updatedOn": "updated_on"},
+	DefaultSortBy:    "updatedOn",
+	DefaultSortOrder: pagination.Desc,
+}
+
 type AppRestHandler interface {
 	GetAllLabels(w http.ResponseWriter, r *http.Request)
+	GetLabelsByProjectId(w http.ResponseWriter, r *http.Request)
+	GetLabelsByEnvironmentId(w http.ResponseWriter, r *http.Request)
+	GetLabelLimitReport(w http.ResponseWriter, r *http.Request)
+	GetLabelFeed(w http.ResponseWriter, r *http.Request)
+	CreateLabelTemplate(w http.ResponseWriter, r *http.Request)
+	CreateLabelWebhook(w http.ResponseWriter, r *http.Request)
 	GetAppMetaInfo(w http.ResponseWriter, r *http.Request)
 	GetHelmAppMetaInfo(w http.ResponseWriter, r *http.Request)
 	UpdateApp(w http.ResponseWriter, r *http.Request)
 	UpdateProjectForApps(w http.ResponseWriter, r *http.Request)
+	AddLabelToMultipleApps(w http.ResponseWriter, r *http.Request)
+	RemoveLabelFromMultipleApps(w http.ResponseWriter, r *http.Request)
 	GetAppListByTeamIds(w http.ResponseWriter, r *http.Request)
 }
 
@@ -74,17 +93,276 @@ func NewAppRestHandlerImpl(logger *zap.SugaredLogger, appService app.AppCrudOper
 func (handler AppRestHandlerImpl) GetAllLabels(w http.ResponseWriter, r *http.Request) {
 	userId, err := handler.userAuthService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
 	token := r.Header.Get("token")
-	results := make([]*bean.AppLabelDto, 0)
-	labels, err := handler.appService.FindAll()
+	source, err := parseLabelSourceFilter(r)
 	if err != nil {
-		handler.logger.Errorw("service err, GetAllLabels", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, err.Error(), err.Error()), http.StatusBadRequest)
+		return
+	}
+	if !pagination.HasListingParams(r.URL.Query()) {
+		labels, err := handler.appService.FindAll()
+		if err != nil {
+			handler.logger.Errorw("service err, GetAllLabels", "err", err)
+			common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+			return
+		}
+		common.WriteJsonResp(w, nil, handler.filterAuthorizedLabels(token, filterLabelsBySource(labels, source)), http.StatusOK)
+		return
+	}
+	listingRequest, err := pagination.ParseListingRequest(r.URL.Query(), labelListingContract)
+	if err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, err.Error(), err.Error()), http.StatusBadRequest)
+		return
+	}
+	labels, err := handler.appService.FindAllByListingRequest(listingRequest)
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllLabels", "err", err, "request", listingRequest)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	results := handler.filterAuthorizedLabels(token, filterLabelsBySource(labels, source))
+	start, end := listingRequest.PageBounds(len(results))
+	common.WriteJsonResp(w, nil, pagination.NewListingResponse(listingRequest, len(results), results[start:end]), http.StatusOK)
+}
+
+func (handler AppRestHandlerImpl) GetLabelsByProjectId(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	projectId, err := strconv.Atoi(vars["projectId"])
+	if err != nil {
+		handler.logger.Errorw("request err, GetLabelsByProjectId", "err", err, "projectId", vars["projectId"])
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+		return
+	}
+	source, err := parseLabelSourceFilter(r)
+	if err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, err.Error(), err.Error()), http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	labels, err := handler.appService.FindByProjectId(projectId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetLabelsByProjectId", "err", err, "projectId", projectId)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, handler.filterAuthorizedLabels(token, filterLabelsBySource(labels, source)), http.StatusOK)
+}
+
+func (handler AppRestHandlerImpl) GetLabelsByEnvironmentId(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	envId, err := strconv.Atoi(vars["envId"])
+	if err != nil {
+		handler.logger.Errorw("request err, GetLabelsByEnvironmentId", "err", err, "envId", vars["envId"])
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+		return
+	}
+	source, err := parseLabelSourceFilter(r)
+	if err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, err.Error(), err.Error()), http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	labels, err := handler.appService.FindByEnvironmentId(envId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetLabelsByEnvironmentId", "err", err, "envId", envId)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, handler.filterAuthorizedLabels(token, filterLabelsBySource(labels, source)), http.StatusOK)
+}
+
+// GetLabelLimitReport lists the apps above the soft limit of labels, it is only served to super admins
+func (handler AppRestHandlerImpl) GetLabelLimitReport(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	isSuperAdmin, err := handler.userAuthService.IsSuperAdmin(int(userId))
+	if err != nil {
+		handler.logger.Errorw("service err, GetLabelLimitReport", "err", err, "userId", userId)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	if !isSuperAdmin {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+		return
+	}
+	report, err := handler.appService.FindAppsAboveLabelSoftLimit()
+	if err != nil {
+		handler.logger.Errorw("service err, GetLabelLimitReport", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, report, http.StatusOK)
+}
+
+// GetLabelFeed pages through the labels of all apps for external systems, it is only served to super admins. A walk
+// is continued with the cursor of the previous page, updatedAfter starts an incremental sync instead of a full walk
+func (handler AppRestHandlerImpl) GetLabelFeed(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	isSuperAdmin, err := handler.userAuthService.IsSuperAdmin(int(userId))
+	if err != nil {
+		handler.logger.Errorw("service err, GetLabelFeed", "err", err, "userId", userId)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	if !isSuperAdmin {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+		return
+	}
+	request, err := parseLabelFeedRequest(r)
+	if err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, err.Error(), err.Error()), http.StatusBadRequest)
+		return
+	}
+	feed, err := handler.appService.FindLabelFeed(request)
+	if err != nil {
+		handler.logger.Errorw("service err, GetLabelFeed", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, feed, http.StatusOK)
+}
+
+func parseLabelFeedRequest(r *http.Request) (*bean.AppLabelFeedRequest, error) {
+	query := r.URL.Query()
+	request := &bean.AppLabelFeedRequest{Cursor: query.Get("cursor")}
+	if limit := query.Get("limit"); len(limit) > 0 {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			return nil, fmt.Errorf("invalid limit %q, it must be a positive number", limit)
+		}
+		request.Limit = value
+	}
+	if updatedAfter := query.Get("updatedAfter"); len(updatedAfter) > 0 {
+		if len(request.Cursor) > 0 {
+			return nil, fmt.Errorf("updatedAfter can not be combined with a cursor, the cursor carries it")
+		}
+		value, err := time.Parse(time.RFC3339Nano, updatedAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid updatedAfter %q, it must be an RFC3339 timestamp", updatedAfter)
+		}
+		request.UpdatedAfter = &value
+	}
+	return request, nil
+}
+
+// CreateLabelTemplate is limited to super admins as templates are shared by all apps
+func (handler AppRestHandlerImpl) CreateLabelTemplate(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	var request bean.LabelTemplateDto
+	err = common.DecodeJsonStrict(r, &request)
+	if err == nil {
+		err = common.ValidateRequest(handler.validator, request)
+	}
+	if err != nil {
+		handler.logger.Errorw("request err, CreateLabelTemplate", "err", err, "request", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	isSuperAdmin, err := handler.userAuthService.IsSuperAdmin(int(userId))
+	if err != nil {
+		handler.logger.Errorw("service err, CreateLabelTemplate", "err", err, "userId", userId)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	if !isSuperAdmin {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+		return
+	}
+	template, err := handler.appService.CreateLabelTemplate(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, CreateLabelTemplate", "err", err, "request", request)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, template, http.StatusOK)
+}
+
+// CreateLabelWebhook is limited to super admins as webhooks receive the label changes of all apps
+func (handler AppRestHandlerImpl) CreateLabelWebhook(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	var request bean.LabelWebhookDto
+	err = common.DecodeJsonStrict(r, &request)
+	if err == nil {
+		err = common.ValidateRequest(handler.validator, request)
+	}
+	if err != nil {
+		handler.logger.Errorw("request err, CreateLabelWebhook", "err", err, "request", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	isSuperAdmin, err := handler.userAuthService.IsSuperAdmin(int(userId))
+	if err != nil {
+		handler.logger.Errorw("service err, CreateLabelWebhook", "err", err, "userId", userId)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	if !isSuperAdmin {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+		return
+	}
+	webhook, err := handler.appService.CreateLabelWebhook(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, CreateLabelWebhook", "err", err, "request", request)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
 		return
 	}
+	common.WriteJsonResp(w, nil, webhook, http.StatusOK)
+}
+
+// parseLabelSourceFilter reads the optional source query param of the label listings
+func parseLabelSourceFilter(r *http.Request) (string, error) {
+	source := r.URL.Query().Get("source")
+	if len(source) > 0 && !pipelineConfig.IsValidAppLabelSource(source) {
+		return "", fmt.Errorf("%w: %s", util.ErrLabelSourceInvalid, source)
+	}
+	return source, nil
+}
+
+func filterLabelsBySource(labels []*bean.AppLabelDto, source string) []*bean.AppLabelDto {
+	if len(source) == 0 {
+		return labels
+	}
+	results := make([]*bean.AppLabelDto, 0, len(labels))
+	for _, label := range labels {
+		if label.Source == source {
+			results = append(results, label)
+		}
+	}
+	return results
+}
+
+func (handler AppRestHandlerImpl) filterAuthorizedLabels(token string, labels []*bean.AppLabelDto) []*bean.AppLabelDto {
+	results := make([]*bean.AppLabelDto, 0)
 	objects := handler.enforcerUtil.GetRbacObjectsForAllApps()
 	for _, label := range labels {
 		object := objects[label.AppId]
@@ -92,20 +370,20 @@ func (handler AppRestHandlerImpl) GetAllLabels(w http.ResponseWriter, r *http.Re
 			results = append(results, label)
 		}
 	}
-	common.WriteJsonResp(w, nil, results, http.StatusOK)
+	return results
 }
 
 func (handler AppRestHandlerImpl) GetAppMetaInfo(w http.ResponseWriter, r *http.Request) {
 	userId, err := handler.userAuthService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
 	vars := mux.Vars(r)
 	appId, err := strconv.Atoi(vars["appId"])
 	if err != nil {
 		handler.logger.Errorw("request err, GetAppMetaInfo", "err", err, "appId", appId)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 
@@ -113,24 +391,51 @@ func (handler AppRestHandlerImpl) GetAppMetaInfo(w http.ResponseWriter, r *http.
 	token := r.Header.Get("token")
 	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
 	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object); !ok {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusForbidden)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 		return
 	}
 	//rback implementation ends here
 
+	expandDeployments := false
+	if expand := r.URL.Query().Get("expand"); len(expand) > 0 {
+		for _, field := range strings.Split(expand, ",") {
+			if strings.TrimSpace(field) != appMetaInfoExpandDeployments {
+				common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, fmt.Sprintf("unsupported expand %q", field)), http.StatusBadRequest)
+				return
+			}
+			expandDeployments = true
+		}
+	}
+
 	res, err := handler.appService.GetAppMetaInfo(appId)
 	if err != nil {
 		handler.logger.Errorw("service err, GetAppMetaInfo", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
 		return
 	}
+	if expandDeployments {
+		deployments, err := handler.appService.GetAppDeployments(appId)
+		if err != nil {
+			handler.logger.Errorw("service err, GetAppDeployments", "err", err, "appId", appId)
+			common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+			return
+		}
+		// only the environments the user can view are listed
+		res.Deployments = make([]*bean.AppEnvironmentDeployment, 0, len(deployments))
+		for _, deployment := range deployments {
+			envObject := fmt.Sprintf("%s/%s", strings.ToLower(deployment.EnvironmentIdentifier), strings.ToLower(res.AppName))
+			if handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionGet, envObject) {
+				res.Deployments = append(res.Deployments, deployment)
+			}
+		}
+	}
 	common.WriteJsonResp(w, nil, res, http.StatusOK)
 }
 
 func (handler AppRestHandlerImpl) GetHelmAppMetaInfo(w http.ResponseWriter, r *http.Request) {
 	userId, err := handler.userAuthService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
 	vars := mux.Vars(r)
@@ -145,20 +450,24 @@ func (handler AppRestHandlerImpl) GetHelmAppMetaInfo(w http.ResponseWriter, r *h
 
 		appIdDecoded, err := handler.helmAppService.DecodeAppId(appIdReq)
 		if err != nil {
-			common.WriteJsonResp(w, err, "request err, not able to decode app id", http.StatusForbidden)
+			common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, "request err, not able to decode app id", err.Error()), http.StatusBadRequest)
 			return
 		}
 		object, object2 := handler.enforcerUtilHelm.GetHelmObjectByClusterIdNamespaceAndAppName(appIdDecoded.ClusterId, appIdDecoded.Namespace, appIdDecoded.ReleaseName)
 
 		ok := handler.enforcer.Enforce(token, casbin.ResourceHelmApp, casbin.ActionGet, object) || handler.enforcer.Enforce(token, casbin.ResourceHelmApp, casbin.ActionGet, object2)
 		if !ok {
-			common.WriteJsonResp(w, err, "Unauthorized User", http.StatusForbidden)
+			common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 			return
 		}
 
 	} else {
 
 		appId, err := strconv.Atoi(appIdReq)
+		if err != nil {
+			common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+			return
+		}
 		var ok bool
 		object, object2 := handler.enforcerUtilHelm.GetAppRBACNameByInstalledAppId(appId)
 		if object2 == "" {
@@ -167,14 +476,14 @@ func (handler AppRestHandlerImpl) GetHelmAppMetaInfo(w http.ResponseWriter, r *h
 			ok = handler.enforcer.Enforce(token, casbin.ResourceHelmApp, casbin.ActionGet, object) || handler.enforcer.Enforce(token, casbin.ResourceHelmApp, casbin.ActionGet, object2)
 		}
 		if !ok {
-			common.WriteJsonResp(w, err, "Unauthorized User", http.StatusForbidden)
+			common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 			return
 		}
 	}
 	res, err := handler.appService.GetHelmAppMetaInfo(appIdReq)
 	if err != nil {
 		handler.logger.Errorw("service err, GetAppMetaInfo", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
 		return
 	}
 	common.WriteJsonResp(w, nil, res, http.StatusOK)
@@ -183,41 +492,54 @@ func (handler AppRestHandlerImpl) GetHelmAppMetaInfo(w http.ResponseWriter, r *h
 func (handler AppRestHandlerImpl) UpdateApp(w http.ResponseWriter, r *http.Request) {
 	userId, err := handler.userAuthService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
-	decoder := json.NewDecoder(r.Body)
 	var request bean.CreateAppDTO
-	err = decoder.Decode(&request)
+	err = common.DecodeJsonStrict(r, &request)
+	if err == nil {
+		// only labels are validated here, rest of the app fields are not part of the edit payload
+		err = common.ValidateRequest(handler.validator, request.AppLabels)
+	}
 	request.UserId = userId
 	if err != nil {
 		handler.logger.Errorw("request err, UpdateApp", "err", err, "request", request)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 	handler.logger.Infow("request payload, UpdateApp", "request", request)
 
 	//rbac implementation starts here
 	token := r.Header.Get("token")
+	emailId, err := handler.userAuthService.GetEmailFromToken(token)
+	if err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	request.LabelSource, err = app.ResolveLabelSource(request.LabelSource, emailId)
+	if err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, err.Error(), err.Error()), http.StatusBadRequest)
+		return
+	}
 
 	// check for existing project/app permission
 	object := handler.enforcerUtil.GetAppRBACNameByAppId(request.Id)
 	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionUpdate, object); !ok {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusForbidden)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 		return
 	}
 
 	// check for request project/app permission
 	object = handler.enforcerUtil.GetAppRBACNameByTeamIdAndAppId(request.TeamId, request.Id)
 	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionUpdate, object); !ok {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusForbidden)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 		return
 	}
 
 	objects := handler.enforcerUtil.GetEnvRBACArrayByAppId(request.Id)
 	for _, object := range objects {
 		if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionUpdate, object); !ok {
-			common.WriteJsonResp(w, err, "Unauthorized User", http.StatusForbidden)
+			common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 			return
 		}
 	}
@@ -226,7 +548,7 @@ func (handler AppRestHandlerImpl) UpdateApp(w http.ResponseWriter, r *http.Reque
 	res, err := handler.appService.UpdateApp(&request)
 	if err != nil {
 		handler.logger.Errorw("service err, UpdateApp", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
 		return
 	}
 	common.WriteJsonResp(w, nil, res, http.StatusOK)
@@ -238,13 +560,12 @@ func (handler AppRestHandlerImpl) UpdateProjectForApps(w http.ResponseWriter, r
 		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
 		return
 	}
-	decoder := json.NewDecoder(r.Body)
 	var request bean.UpdateProjectBulkAppsRequest
-	err = decoder.Decode(&request)
+	err = common.DecodeAndValidateJson(r, &request, handler.validator)
 	request.UserId = userId
 	if err != nil {
 		handler.logger.Errorw("request err, ProjectChange", "err", err, "request", request)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 	handler.logger.Infow("request payload, ProjectChange", "request", request)
@@ -276,6 +597,106 @@ func (handler AppRestHandlerImpl) UpdateProjectForApps(w http.ResponseWriter, r
 	common.WriteJsonResp(w, nil, res, http.StatusOK)
 }
 
+// AddLabelToMultipleApps adds labels to several apps at once, the response has the outcome of each app
+func (handler AppRestHandlerImpl) AddLabelToMultipleApps(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	var request bean.AppLabelBulkRequest
+	err = common.DecodeAndValidateJson(r, &request, handler.validator)
+	request.UserId = userId
+	if err != nil {
+		handler.logger.Errorw("request err, AddLabelToMultipleApps", "err", err, "request", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("request payload, AddLabelToMultipleApps", "request", request)
+
+	token := r.Header.Get("token")
+	emailId, err := handler.userAuthService.GetEmailFromToken(token)
+	if err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	request.LabelSource, err = app.ResolveLabelSource(request.LabelSource, emailId)
+	if err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, err.Error(), err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	//rbac implementation starts here
+	for _, appId := range request.AppIds {
+		object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionUpdate, object); !ok {
+			common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+			return
+		}
+		objects := handler.enforcerUtil.GetEnvRBACArrayByAppId(appId)
+		for _, object := range objects {
+			if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionUpdate, object); !ok {
+				common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+				return
+			}
+		}
+	}
+	//rbac implementation ends here
+
+	res, err := handler.appService.AddLabelsToApps(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, AddLabelToMultipleApps", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+// RemoveLabelFromMultipleApps removes the labels with the given keys from several apps at once, the response has the
+// number of labels removed from each app
+func (handler AppRestHandlerImpl) RemoveLabelFromMultipleApps(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	var request bean.AppLabelBulkDeleteRequest
+	err = common.DecodeAndValidateJson(r, &request, handler.validator)
+	request.UserId = userId
+	if err != nil {
+		handler.logger.Errorw("request err, RemoveLabelFromMultipleApps", "err", err, "request", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("request payload, RemoveLabelFromMultipleApps", "request", request)
+
+	//rbac implementation starts here
+	token := r.Header.Get("token")
+	for _, appId := range request.AppIds {
+		object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionUpdate, object); !ok {
+			common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+			return
+		}
+		objects := handler.enforcerUtil.GetEnvRBACArrayByAppId(appId)
+		for _, object := range objects {
+			if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionUpdate, object); !ok {
+				common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+				return
+			}
+		}
+	}
+	//rbac implementation ends here
+
+	res, err := handler.appService.RemoveLabelsFromApps(&request)
+	if err != nil {
+		handler.logger.Errorw("service err, RemoveLabelFromMultipleApps", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
 func (handler AppRestHandlerImpl) GetAppListByTeamIds(w http.ResponseWriter, r *http.Request) {
 	userId, err := handler.userAuthService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
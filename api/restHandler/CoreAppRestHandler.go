@@ -1098,9 +1098,10 @@ func (handler CoreAppRestHandlerImpl) createBlankApp(appMetadata *appBean.AppMet
 	handler.logger.Infow("Create App - creating blank app with metadata", "appMetadata", appMetadata)
 
 	createAppRequest := &bean.CreateAppDTO{
-		AppName: appMetadata.AppName,
-		TeamId:  team.Id,
-		UserId:  userId,
+		AppName:     appMetadata.AppName,
+		TeamId:      team.Id,
+		UserId:      userId,
+		LabelSource: pipelineConfig.AppLabelSourceImport,
 	}
 
 	var appLabels []*bean.Label
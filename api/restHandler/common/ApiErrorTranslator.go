@@ -0,0 +1,105 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"github.com/devtron-labs/devtron/internal/util"
+	"gopkg.in/go-playground/validator.v9"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// NewApiError builds an api error for a registered code, http status and user message are taken from the registry
+func NewApiError(code string, internalMessage string, details ...string) *util.ApiError {
+	return &util.ApiError{
+		HttpStatusCode:  ErrorHttpStatus(code),
+		Code:            code,
+		InternalMessage: internalMessage,
+		UserMessage:     ErrorMessage(code),
+		Details:         details,
+	}
+}
+
+// TranslateError converts errors returned by services into an api error with a stable code and http status,
+// errors which are not known are reported with defaultStatus
+func TranslateError(err error, defaultStatus int) *util.ApiError {
+	var apiErr *util.ApiError
+	if errors.As(err, &apiErr) {
+		if apiErr.HttpStatusCode == 0 {
+			apiErr.HttpStatusCode = defaultStatus
+		}
+		return apiErr
+	}
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		var details []string
+		for _, validationErr := range validationErrs {
+			details = append(details, fmt.Sprint(validationErr))
+		}
+		return NewApiError(BadRequest, err.Error(), details...)
+	}
+	var namespacePolicyErr *util.ErrNamespacePolicyDenied
+	if errors.As(err, &namespacePolicyErr) {
+		// the user message names the namespace and the pattern which blocked the call
+		apiErr = NewApiError(NamespacePolicyDenied, err.Error())
+		apiErr.UserMessage = namespacePolicyErr.Error()
+		return apiErr
+	}
+	var circuitOpenErr *util.ClusterCircuitOpenError
+	if errors.As(err, &circuitOpenErr) {
+		// the user message has the last error of the cluster and when calls are attempted again
+		apiErr = NewApiError(ClusterCircuitOpen, err.Error())
+		apiErr.UserMessage = circuitOpenErr.Error()
+		return apiErr
+	}
+	switch {
+	case errors.Is(err, util.ErrLabelNotFound):
+		return NewApiError(LabelNotFound, err.Error())
+	case errors.Is(err, util.ErrSessionLimitExceeded):
+		return NewApiError(SessionLimitExceeded, err.Error())
+	case errors.Is(err, util.ErrServerShuttingDown):
+		return NewApiError(ServerShuttingDown, err.Error())
+	case errors.Is(err, util.ErrTerminalBudgetExceeded):
+		return NewApiError(TerminalBudgetExceeded, err.Error())
+	case util.IsErrNoRows(err):
+		return NewApiError(ResourceNotFound, err.Error())
+	}
+	switch util.ClassifyK8sError(err) {
+	case util.K8sErrorNotFound:
+		return NewApiError(ResourceNotFound, err.Error())
+	case util.K8sErrorUnauthorized, util.K8sErrorForbidden:
+		return NewApiError(UnAuthorized, err.Error())
+	case util.K8sErrorConflict:
+		return NewApiError(ResourceConflict, err.Error())
+	case util.K8sErrorInvalid:
+		return NewApiError(BadRequest, err.Error())
+	case util.K8sErrorTooManyRequests:
+		return NewApiError(TooManyRequests, err.Error())
+	case util.K8sErrorUnreachable:
+		return NewApiError(ClusterUnreachable, err.Error())
+	}
+	code := InternalServerError
+	if defaultStatus == http.StatusBadRequest {
+		code = BadRequest
+	}
+	apiErr = NewApiError(code, err.Error())
+	apiErr.HttpStatusCode = defaultStatus
+	apiErr.UserMessage = err.Error()
+	return apiErr
+}
+
+// WriteJsonErrorResp writes err in the common response envelope after translating it with TranslateError
+func WriteJsonErrorResp(w http.ResponseWriter, err error, defaultStatus int) {
+	apiErr := TranslateError(err, defaultStatus)
+	var circuitOpenErr *util.ClusterCircuitOpenError
+	if errors.As(err, &circuitOpenErr) {
+		retryAfter := int(math.Ceil(time.Until(circuitOpenErr.RetryAfter).Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	}
+	WriteJsonResp(w, apiErr, nil, apiErr.HttpStatusCode)
+}
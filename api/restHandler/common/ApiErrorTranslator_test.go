@@ -0,0 +1,87 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/go-pg/pg"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/go-playground/validator.v9"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func init() {
+	util.InitLogger()
+}
+
+func TestWriteJsonErrorResp(t *testing.T) {
+	podResource := schema.GroupResource{Resource: "pods"}
+	tests := []struct {
+		name          string
+		err           error
+		defaultStatus int
+		wantStatus    int
+		wantCode      string
+	}{
+		{name: "unauthenticated", err: NewApiError(UnAuthenticated, "unauthenticated user"), defaultStatus: http.StatusUnauthorized, wantStatus: http.StatusUnauthorized, wantCode: UnAuthenticated},
+		{name: "unauthorized", err: NewApiError(UnAuthorized, "unauthorized"), defaultStatus: http.StatusForbidden, wantStatus: http.StatusForbidden, wantCode: UnAuthorized},
+		{name: "label not found", err: util.ErrLabelNotFound, defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusNotFound, wantCode: LabelNotFound},
+		{name: "wrapped label not found", err: fmt.Errorf("label 5: %w", util.ErrLabelNotFound), defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusNotFound, wantCode: LabelNotFound},
+		{name: "session limit exceeded", err: util.ErrSessionLimitExceeded, defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusTooManyRequests, wantCode: SessionLimitExceeded},
+		{name: "server shutting down", err: util.ErrServerShuttingDown, defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusServiceUnavailable, wantCode: ServerShuttingDown},
+		{name: "terminal budget exceeded", err: fmt.Errorf("used 12.00 of 10.00 cpu core hours: %w", util.ErrTerminalBudgetExceeded), defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusForbidden, wantCode: TerminalBudgetExceeded},
+		{name: "namespace policy denied", err: fmt.Errorf("creating job: %w", &util.ErrNamespacePolicyDenied{ClusterId: 2, Namespace: "kube-system", Mode: util.NamespacePolicyDeny, Pattern: "kube-.*"}), defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusForbidden, wantCode: NamespacePolicyDenied},
+		{name: "cluster unreachable", err: util.ErrClusterUnreachable, defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusServiceUnavailable, wantCode: ClusterUnreachable},
+		{name: "cluster connection error", err: &url.Error{Op: "Get", URL: "https://10.0.0.1/api", Err: errors.New("connection refused")}, defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusServiceUnavailable, wantCode: ClusterUnreachable},
+		{name: "cluster circuit open", err: &url.Error{Op: "Get", URL: "https://10.0.0.1/api", Err: &util.ClusterCircuitOpenError{ClusterId: 2, LastError: "connection refused", RetryAfter: time.Now().Add(time.Minute)}}, defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusServiceUnavailable, wantCode: ClusterCircuitOpen},
+		{name: "db no rows", err: pg.ErrNoRows, defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusNotFound, wantCode: ResourceNotFound},
+		{name: "k8s not found", err: k8sErrors.NewNotFound(podResource, "pod-1"), defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusNotFound, wantCode: ResourceNotFound},
+		{name: "kind not served", err: util.NewKindNotServedError(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}), defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusNotFound, wantCode: ResourceNotFound},
+		{name: "k8s forbidden", err: k8sErrors.NewForbidden(podResource, "pod-1", errors.New("rbac")), defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusForbidden, wantCode: UnAuthorized},
+		{name: "k8s already exists", err: k8sErrors.NewAlreadyExists(podResource, "pod-1"), defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusConflict, wantCode: ResourceConflict},
+		{name: "k8s invalid", err: k8sErrors.NewBadRequest("invalid manifest"), defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusBadRequest, wantCode: BadRequest},
+		{name: "k8s service unavailable", err: k8sErrors.NewServiceUnavailable("api server down"), defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusServiceUnavailable, wantCode: ClusterUnreachable},
+		{name: "decode error", err: errors.New("invalid character"), defaultStatus: http.StatusBadRequest, wantStatus: http.StatusBadRequest, wantCode: BadRequest},
+		{name: "unknown error", err: errors.New("boom"), defaultStatus: http.StatusInternalServerError, wantStatus: http.StatusInternalServerError, wantCode: InternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			WriteJsonErrorResp(recorder, tt.err, tt.defaultStatus)
+			assert.Equal(t, tt.wantStatus, recorder.Code)
+			response := Response{}
+			err := json.Unmarshal(recorder.Body.Bytes(), &response)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantStatus, response.Code)
+			if assert.Len(t, response.Errors, 1) {
+				assert.Equal(t, tt.wantCode, response.Errors[0].Code)
+				assert.NotEmpty(t, response.Errors[0].UserMessage)
+				assert.NotEmpty(t, response.Errors[0].InternalMessage)
+			}
+		})
+	}
+
+	t.Run("validation errors carry failed fields as details", func(tt *testing.T) {
+		request := struct {
+			ClusterId int    `validate:"number,gt=0"`
+			Name      string `validate:"required"`
+		}{}
+		validationErr := validator.New().Struct(request)
+		recorder := httptest.NewRecorder()
+		WriteJsonErrorResp(recorder, validationErr, http.StatusInternalServerError)
+		assert.Equal(tt, http.StatusBadRequest, recorder.Code)
+		response := Response{}
+		assert.Nil(tt, json.Unmarshal(recorder.Body.Bytes(), &response))
+		if assert.Len(tt, response.Errors, 1) {
+			assert.Equal(tt, BadRequest, response.Errors[0].Code)
+			assert.Len(tt, response.Errors[0].Details, 2)
+		}
+	})
+}
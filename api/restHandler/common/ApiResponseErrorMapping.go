@@ -1,19 +1,68 @@
 package common
 
+import "net/http"
+
 const (
-	UnAuthenticated     = "E100"
-	UnAuthorized        = "E101"
-	BadRequest          = "E102"
-	InternalServerError = "E103"
-	ResourceNotFound    = "E104"
-	UnknownError        = "E105"
+	UnAuthenticated        = "E100"
+	UnAuthorized           = "E101"
+	BadRequest             = "E102"
+	InternalServerError    = "E103"
+	ResourceNotFound       = "E104"
+	UnknownError           = "E105"
+	LabelNotFound          = "E106"
+	ClusterUnreachable     = "E107"
+	SessionLimitExceeded   = "E108"
+	ResourceConflict       = "E109"
+	TooManyRequests        = "E110"
+	ServerShuttingDown     = "E111"
+	TerminalBudgetExceeded = "E112"
+	NamespacePolicyDenied  = "E113"
+	ClusterCircuitOpen     = "E114"
 )
 
 var errorMessage = map[string]string{
-	UnAuthenticated: "User is not authenticated",
-	UnAuthorized:    "User is not authorized to perform this action",
+	UnAuthenticated:        "User is not authenticated",
+	UnAuthorized:           "User is not authorized to perform this action",
+	BadRequest:             "Request is not valid",
+	InternalServerError:    "Something went wrong while processing the request",
+	ResourceNotFound:       "Requested resource was not found",
+	UnknownError:           "Unknown error",
+	LabelNotFound:          "Label was not found",
+	ClusterUnreachable:     "Cluster is not reachable",
+	SessionLimitExceeded:   "Maximum number of active sessions reached",
+	ResourceConflict:       "Resource already exists or was modified concurrently",
+	TooManyRequests:        "Too many requests, please retry later",
+	ServerShuttingDown:     "Server is restarting, please retry shortly",
+	TerminalBudgetExceeded: "Monthly terminal usage budget is used up, new sessions can be started next month",
+	NamespacePolicyDenied:  "Namespace is blocked by the namespace policy of the cluster",
+	ClusterCircuitOpen:     "Cluster is failing repeatedly, calls to it are paused until it recovers",
+}
+
+var errorHttpStatus = map[string]int{
+	UnAuthenticated:        http.StatusUnauthorized,
+	UnAuthorized:           http.StatusForbidden,
+	BadRequest:             http.StatusBadRequest,
+	InternalServerError:    http.StatusInternalServerError,
+	ResourceNotFound:       http.StatusNotFound,
+	UnknownError:           http.StatusInternalServerError,
+	LabelNotFound:          http.StatusNotFound,
+	ClusterUnreachable:     http.StatusServiceUnavailable,
+	SessionLimitExceeded:   http.StatusTooManyRequests,
+	ResourceConflict:       http.StatusConflict,
+	TooManyRequests:        http.StatusTooManyRequests,
+	ServerShuttingDown:     http.StatusServiceUnavailable,
+	TerminalBudgetExceeded: http.StatusForbidden,
+	NamespacePolicyDenied:  http.StatusForbidden,
+	ClusterCircuitOpen:     http.StatusServiceUnavailable,
 }
 
 func ErrorMessage(code string) string {
 	return errorMessage[code]
 }
+
+func ErrorHttpStatus(code string) int {
+	if status, ok := errorHttpStatus[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
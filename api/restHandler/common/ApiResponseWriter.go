@@ -10,6 +10,8 @@ type ApiResponse struct {
 	Success bool           `json:"success,notnull" validate:"required"`
 	Error   *ErrorResponse `json:"error,omitempty"`
 	Result  interface{}    `json:"result,omitempty"`
+	// RequestId is returned with errors so that users can quote it while reporting issues
+	RequestId string `json:"requestId,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -38,6 +40,9 @@ func WriteApiJsonResponse(w http.ResponseWriter, result interface{}, statusCode
 }
 
 func WriteApiJsonResponseStructured(w http.ResponseWriter, apiResponse *ApiResponse, statusCode int) {
+	if apiResponse.Error != nil {
+		apiResponse.RequestId = w.Header().Get(util.RequestIdHeader)
+	}
 	apiResponseByteArr, err := json.Marshal(&apiResponse)
 	if err != nil {
 		util.GetLogger().Error("error in marshaling api response object", err)
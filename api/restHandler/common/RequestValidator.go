@@ -0,0 +1,73 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/go-playground/validator.v9"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// DecodeAndValidateJson decodes the request body into v and validates it, every failure is returned as a
+// bad request api error listing the failed fields in details
+func DecodeAndValidateJson(r *http.Request, v interface{}, validate *validator.Validate) error {
+	err := DecodeJsonStrict(r, v)
+	if err != nil {
+		return err
+	}
+	return ValidateRequest(validate, v)
+}
+
+// DecodeJsonStrict decodes the request body into v, fields not present in v are rejected
+func DecodeJsonStrict(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	detail := err.Error()
+	switch {
+	case errors.Is(err, io.EOF):
+		detail = "request body is empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		detail = "malformed json, body ended unexpectedly"
+	case errors.As(err, &syntaxErr):
+		detail = fmt.Sprintf("malformed json at offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		detail = fmt.Sprintf("field %s must be of type %s", typeErr.Field, typeErr.Type.String())
+	}
+	return NewApiError(BadRequest, err.Error(), detail)
+}
+
+// ValidateRequest runs struct tag validations on v, slices are validated element wise
+func ValidateRequest(validate *validator.Validate, v interface{}) error {
+	var err error
+	if kind := reflect.Indirect(reflect.ValueOf(v)).Kind(); kind == reflect.Slice || kind == reflect.Array {
+		err = validate.Var(v, "dive")
+	} else {
+		err = validate.Struct(v)
+	}
+	if err == nil {
+		return nil
+	}
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return NewApiError(BadRequest, err.Error(), err.Error())
+	}
+	var details []string
+	for _, fieldErr := range validationErrs {
+		detail := fmt.Sprintf("field %s failed on %s", fieldErr.Namespace(), fieldErr.Tag())
+		if fieldErr.Param() != "" {
+			detail = fmt.Sprintf("%s=%s", detail, fieldErr.Param())
+		}
+		details = append(details, detail)
+	}
+	apiErr := NewApiError(BadRequest, err.Error(), details...)
+	apiErr.UserMessage = fmt.Sprintf("%s: %d field(s) failed validation", ErrorMessage(BadRequest), len(details))
+	return apiErr
+}
@@ -0,0 +1,120 @@
+package common
+
+import (
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeAndValidateJson(t *testing.T) {
+	validate, err := util.IntValidator()
+	assert.Nil(t, err)
+	tests := []struct {
+		name          string
+		payload       string
+		request       interface{}
+		wantErr       bool
+		wantDetailHas []string
+	}{
+		{
+			name:    "valid terminal request",
+			payload: `{"clusterId":1,"nodeName":"node-1","baseImage":"alpine:3.16","shellName":"sh","namespace":"default"}`,
+			request: &models.UserTerminalSessionRequest{},
+		},
+		{
+			name:          "unknown field",
+			payload:       `{"clusterId":1,"nodeName":"node-1","baseImage":"alpine:3.16","shellName":"sh","namespace":"default","privileged":true}`,
+			request:       &models.UserTerminalSessionRequest{},
+			wantErr:       true,
+			wantDetailHas: []string{`unknown field "privileged"`},
+		},
+		{
+			name:          "missing required fields",
+			payload:       `{"clusterId":1}`,
+			request:       &models.UserTerminalSessionRequest{},
+			wantErr:       true,
+			wantDetailHas: []string{"NodeName failed on required", "Namespace failed on required"},
+		},
+		{
+			name:    "base image and shell defaulted",
+			payload: `{"clusterId":1,"nodeName":"node-1","namespace":"default"}`,
+			request: &models.UserTerminalSessionRequest{},
+		},
+		{
+			name:          "cluster id not positive and shell not allowed",
+			payload:       `{"clusterId":0,"nodeName":"node-1","baseImage":"alpine:3.16","shellName":"zsh","namespace":"default"}`,
+			request:       &models.UserTerminalSessionRequest{},
+			wantErr:       true,
+			wantDetailHas: []string{"ClusterId failed on gt=0", "ShellName failed on oneof=bash sh powershell cmd"},
+		},
+		{
+			name:          "type mismatch",
+			payload:       `{"clusterId":"one","nodeName":"node-1","baseImage":"alpine:3.16","shellName":"sh","namespace":"default"}`,
+			request:       &models.UserTerminalSessionRequest{},
+			wantErr:       true,
+			wantDetailHas: []string{"field clusterId must be of type int"},
+		},
+		{
+			name:          "malformed json",
+			payload:       `{"clusterId":1,`,
+			request:       &models.UserTerminalSessionRequest{},
+			wantErr:       true,
+			wantDetailHas: []string{"malformed json"},
+		},
+		{
+			name:          "empty body",
+			payload:       ``,
+			request:       &models.UserTerminalSessionRequest{},
+			wantErr:       true,
+			wantDetailHas: []string{"request body is empty"},
+		},
+		{
+			name:          "label value too long",
+			payload:       `{"key":"team","value":"` + strings.Repeat("a", 65537) + `"}`,
+			request:       &bean.Label{},
+			wantErr:       true,
+			wantDetailHas: []string{"Value failed on max=65536"},
+		},
+		{
+			name:          "bulk project update without apps",
+			payload:       `{"appIds":[],"teamId":1}`,
+			request:       &bean.UpdateProjectBulkAppsRequest{},
+			wantErr:       true,
+			wantDetailHas: []string{"AppIds failed on min=1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.payload))
+			err := DecodeAndValidateJson(r, tt.request, validate)
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				return
+			}
+			apiErr, ok := err.(*util.ApiError)
+			if assert.True(t, ok, "expected api error, got %v", err) {
+				assert.Equal(t, BadRequest, apiErr.Code)
+				assert.Equal(t, http.StatusBadRequest, apiErr.HttpStatusCode)
+				details := strings.Join(apiErr.Details, "\n")
+				for _, want := range tt.wantDetailHas {
+					assert.Contains(t, details, want)
+				}
+			}
+		})
+	}
+
+	t.Run("labels are validated element wise", func(tt *testing.T) {
+		labels := []*bean.Label{{Key: "team", Value: "devtron"}, {Key: "", Value: "x"}}
+		err := ValidateRequest(validate, labels)
+		apiErr, ok := err.(*util.ApiError)
+		if assert.True(tt, ok) {
+			assert.Len(tt, apiErr.Details, 1)
+			assert.Contains(tt, apiErr.Details[0], "Key failed on required")
+		}
+	})
+}
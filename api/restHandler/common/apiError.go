@@ -40,6 +40,10 @@ func WriteJsonResp(w http.ResponseWriter, err error, respBody interface{}, statu
 		if apiErr.HttpStatusCode != 0 {
 			status = apiErr.HttpStatusCode
 		}
+	} else if _, ok := err.(*util.ErrNamespacePolicyDenied); ok {
+		apiErr := TranslateError(err, status)
+		status = apiErr.HttpStatusCode
+		response.Errors = []*util.ApiError{apiErr}
 	} else if validationErrs, ok := err.(validator.ValidationErrors); ok {
 		var valErrors []*util.ApiError
 		for _, validationErr := range validationErrs {
@@ -94,6 +98,9 @@ func WriteJsonResp(w http.ResponseWriter, err error, respBody interface{}, statu
 		}
 		response.Errors = []*util.ApiError{apiErr}
 	}
+	if err != nil {
+		response.RequestId = w.Header().Get(util.RequestIdHeader)
+	}
 	response.Code = status //TODO : discuss with prashant about http status header
 	response.Status = http.StatusText(status)
 
@@ -115,7 +122,7 @@ func WriteJsonResp(w http.ResponseWriter, err error, respBody interface{}, statu
 		}
 	}
 	if status > 299 || err != nil {
-		util.GetLogger().Infow("ERROR RES", "TYPE", "API-ERROR", "RES", response.Code, "ERROR-MSG", response.Errors, "err", err)
+		util.GetLogger().Infow("ERROR RES", "TYPE", "API-ERROR", "RES", response.Code, "ERROR-MSG", response.Errors, "err", err, util.RequestIdLogKey, response.RequestId)
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -135,6 +142,7 @@ func writeJsonRespStructured(w http.ResponseWriter, err error, respBody interfac
 		response.Result = respBody
 	} else {
 		response.Errors = apiErrors
+		response.RequestId = w.Header().Get(util.RequestIdHeader)
 	}
 	b, err := json.Marshal(response)
 	if err != nil {
@@ -155,6 +163,8 @@ type Response struct {
 	Status string           `json:"status,omitempty"`
 	Result interface{}      `json:"result,omitempty"`
 	Errors []*util.ApiError `json:"errors,omitempty"`
+	// RequestId is returned with errors so that users can quote it while reporting issues
+	RequestId string `json:"requestId,omitempty"`
 }
 
 func contains(s []*string, e *string) bool {
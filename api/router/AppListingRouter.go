@@ -73,4 +73,57 @@ func (router AppListingRouterImpl) initAppListingRouter(appListingRouter *mux.Ro
 	appListingRouter.Path("/deployment-status/manual-sync/{appId}/{envId}").
 		HandlerFunc(router.appListingRestHandler.ManualSyncAcdPipelineDeploymentStatus).
 		Methods("GET")
+
+	appListingRouter.Path("/{appId}/env/{envId}/rollout/pause").
+		HandlerFunc(router.appListingRestHandler.PauseRollout).
+		Methods("POST")
+
+	appListingRouter.Path("/{appId}/env/{envId}/rollout/resume").
+		HandlerFunc(router.appListingRestHandler.ResumeRollout).
+		Methods("POST")
+
+	appListingRouter.Path("/{appId}/env/{envId}/rollout/promote").
+		HandlerFunc(router.appListingRestHandler.PromoteRollout).
+		Methods("POST")
+
+	appListingRouter.Path("/{appId}/env/{envId}/rollout/abort").
+		HandlerFunc(router.appListingRestHandler.AbortRollout).
+		Methods("POST")
+
+	appListingRouter.Path("/{appId}/env/{envId}/rollout/history").
+		HandlerFunc(router.appListingRestHandler.GetRolloutRevisionHistory).
+		Methods("GET")
+
+	appListingRouter.Path("/{appId}/env/{envId}/cronjobs").
+		HandlerFunc(router.appListingRestHandler.GetAppCronJobs).
+		Methods("GET")
+
+	appListingRouter.Path("/{appId}/env/{envId}/cronjob/{name}/suspend").
+		HandlerFunc(router.appListingRestHandler.SuspendCronJob).
+		Methods("POST")
+
+	appListingRouter.Path("/{appId}/env/{envId}/cronjob/{name}/resume").
+		HandlerFunc(router.appListingRestHandler.ResumeCronJob).
+		Methods("POST")
+
+	appListingRouter.Path("/{appId}/env/{envId}/pod/{name}").
+		HandlerFunc(router.appListingRestHandler.DeleteAppPod).
+		Methods("DELETE")
+
+	appListingRouter.Path("/{appId}/env/{envId}/workload/{kind}/{name}/restart").
+		HandlerFunc(router.appListingRestHandler.RestartAppWorkload).
+		Methods("POST")
+
+	appListingRouter.Path("/{appId}/env/{envId}/workload/{kind}/{name}/scale").
+		HandlerFunc(router.appListingRestHandler.ScaleAppWorkload).
+		Methods("POST")
+
+	appListingRouter.Path("/{appId}/env/{envId}/pvc/{name}/snapshot").
+		HandlerFunc(router.appListingRestHandler.SnapshotAppPvc).
+		Methods("POST")
+
+	appListingRouter.Path("/{appId}/env/{envId}/resource-actions").
+		Queries("userId", "{userId}").
+		HandlerFunc(router.appListingRestHandler.GetResourceActionMatrix).
+		Methods("GET")
 }
@@ -25,6 +25,8 @@ import (
 
 type AppRouter interface {
 	InitAppRouter(router *mux.Router)
+	InitLabelTemplateRouter(router *mux.Router)
+	InitLabelWebhookRouter(router *mux.Router)
 }
 
 type AppRouterImpl struct {
@@ -43,6 +45,18 @@ func NewAppRouterImpl(logger *zap.SugaredLogger, handler restHandler.AppRestHand
 func (router AppRouterImpl) InitAppRouter(appRouter *mux.Router) {
 	appRouter.Path("/labels/list").
 		HandlerFunc(router.handler.GetAllLabels).Methods("GET")
+	appRouter.Path("/labels/project/{projectId}").
+		HandlerFunc(router.handler.GetLabelsByProjectId).Methods("GET")
+	appRouter.Path("/labels/environment/{envId}").
+		HandlerFunc(router.handler.GetLabelsByEnvironmentId).Methods("GET")
+	appRouter.Path("/labels/limit/report").
+		HandlerFunc(router.handler.GetLabelLimitReport).Methods("GET")
+	appRouter.Path("/labels/all-apps").
+		HandlerFunc(router.handler.GetLabelFeed).Methods("GET")
+	appRouter.Path("/labels/bulk").
+		HandlerFunc(router.handler.AddLabelToMultipleApps).Methods("POST")
+	appRouter.Path("/labels/bulk").
+		HandlerFunc(router.handler.RemoveLabelFromMultipleApps).Methods("DELETE")
 	appRouter.Path("/meta/info/{appId}").
 		HandlerFunc(router.handler.GetAppMetaInfo).Methods("GET")
 
@@ -57,3 +71,13 @@ func (router AppRouterImpl) InitAppRouter(appRouter *mux.Router) {
 	appRouter.Path("/min").HandlerFunc(router.handler.GetAppListByTeamIds).Methods("GET")
 
 }
+
+func (router AppRouterImpl) InitLabelTemplateRouter(labelTemplateRouter *mux.Router) {
+	labelTemplateRouter.Path("").
+		HandlerFunc(router.handler.CreateLabelTemplate).Methods("POST")
+}
+
+func (router AppRouterImpl) InitLabelWebhookRouter(labelWebhookRouter *mux.Router) {
+	labelWebhookRouter.Path("").
+		HandlerFunc(router.handler.CreateLabelWebhook).Methods("POST")
+}
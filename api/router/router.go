@@ -25,10 +25,13 @@ import (
 	appStoreDeployment "github.com/devtron-labs/devtron/api/appStore/deployment"
 	"github.com/devtron-labs/devtron/api/chartRepo"
 	"github.com/devtron-labs/devtron/api/cluster"
+	"github.com/devtron-labs/devtron/api/clusterOperation"
 	"github.com/devtron-labs/devtron/api/dashboardEvent"
 	"github.com/devtron-labs/devtron/api/deployment"
 	"github.com/devtron-labs/devtron/api/externalLink"
+	"github.com/devtron-labs/devtron/api/favorites"
 	client "github.com/devtron-labs/devtron/api/helm-app"
+	"github.com/devtron-labs/devtron/api/jobTemplate"
 	"github.com/devtron-labs/devtron/api/module"
 	"github.com/devtron-labs/devtron/api/restHandler/common"
 	"github.com/devtron-labs/devtron/api/router/pubsub"
@@ -41,6 +44,9 @@ import (
 	"github.com/devtron-labs/devtron/client/cron"
 	"github.com/devtron-labs/devtron/client/dashboard"
 	"github.com/devtron-labs/devtron/client/telemetry"
+	"github.com/devtron-labs/devtron/internal/middleware"
+	"github.com/devtron-labs/devtron/pkg/health"
+	"github.com/devtron-labs/devtron/pkg/shutdown"
 	"github.com/devtron-labs/devtron/pkg/terminal"
 	"github.com/devtron-labs/devtron/util"
 	"github.com/devtron-labs/devtron/util/k8s"
@@ -115,7 +121,14 @@ type MuxRouter struct {
 	webhookHelmRouter                  webhookHelm.WebhookHelmRouter
 	globalCMCSRouter                   GlobalCMCSRouter
 	userTerminalAccessRouter           terminal2.UserTerminalAccessRouter
+	clusterOperationRouter             clusterOperation.ClusterOperationRouter
+	jobTemplateRouter                  jobTemplate.JobTemplateRouter
+	favoriteRouter                     favorites.FavoriteRouter
 	ciStatusUpdateCron                 cron.CiStatusUpdateCron
+	rateLimiter                        *middleware.RateLimiter
+	idempotencyHandler                 *middleware.IdempotencyHandler
+	gracefulShutdownService            shutdown.GracefulShutdownService
+	healthCheckService                 health.HealthCheckService
 }
 
 func NewMuxRouter(logger *zap.SugaredLogger, HelmRouter PipelineTriggerRouter, PipelineConfigRouter PipelineConfigRouter,
@@ -143,7 +156,9 @@ func NewMuxRouter(logger *zap.SugaredLogger, HelmRouter PipelineTriggerRouter, P
 	serverRouter server.ServerRouter, apiTokenRouter apiToken.ApiTokenRouter,
 	helmApplicationStatusUpdateHandler cron.CdApplicationStatusUpdateHandler, k8sCapacityRouter k8s.K8sCapacityRouter,
 	webhookHelmRouter webhookHelm.WebhookHelmRouter, globalCMCSRouter GlobalCMCSRouter,
-	userTerminalAccessRouter terminal2.UserTerminalAccessRouter, ciStatusUpdateCron cron.CiStatusUpdateCron) *MuxRouter {
+	userTerminalAccessRouter terminal2.UserTerminalAccessRouter, clusterOperationRouter clusterOperation.ClusterOperationRouter, jobTemplateRouter jobTemplate.JobTemplateRouter, favoriteRouter favorites.FavoriteRouter, ciStatusUpdateCron cron.CiStatusUpdateCron,
+	rateLimiter *middleware.RateLimiter, idempotencyHandler *middleware.IdempotencyHandler, gracefulShutdownService shutdown.GracefulShutdownService,
+	healthCheckService health.HealthCheckService) *MuxRouter {
 	r := &MuxRouter{
 		Router:                             mux.NewRouter(),
 		HelmRouter:                         HelmRouter,
@@ -209,7 +224,14 @@ func NewMuxRouter(logger *zap.SugaredLogger, HelmRouter PipelineTriggerRouter, P
 		webhookHelmRouter:                  webhookHelmRouter,
 		globalCMCSRouter:                   globalCMCSRouter,
 		userTerminalAccessRouter:           userTerminalAccessRouter,
+		clusterOperationRouter:             clusterOperationRouter,
+		jobTemplateRouter:                  jobTemplateRouter,
+		favoriteRouter:                     favoriteRouter,
 		ciStatusUpdateCron:                 ciStatusUpdateCron,
+		rateLimiter:                        rateLimiter,
+		idempotencyHandler:                 idempotencyHandler,
+		gracefulShutdownService:            gracefulShutdownService,
+		healthCheckService:                 healthCheckService,
 	}
 	return r
 }
@@ -224,10 +246,15 @@ func (r MuxRouter) Init() {
 	//prometheus.MustRegister(app.CdTriggerCounter)
 	r.Router.Path("/health").HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(200)
 		response := common.Response{}
 		response.Code = 200
 		response.Result = "OK"
+		if r.gracefulShutdownService.IsDraining() {
+			// failing the check takes the pod out of the load balancer while it drains
+			response.Code = http.StatusServiceUnavailable
+			response.Result = "DRAINING"
+		}
+		writer.WriteHeader(response.Code)
 		b, err := json.Marshal(response)
 		if err != nil {
 			b = []byte("OK")
@@ -236,6 +263,13 @@ func (r MuxRouter) Init() {
 		_, _ = writer.Write(b)
 	})
 
+	r.Router.Path("/health/live").HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writeHealthStatus(writer, r.healthCheckService.Liveness())
+	})
+	r.Router.Path("/health/ready").HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writeHealthStatus(writer, r.healthCheckService.Readiness(request.Context()))
+	})
+
 	r.Router.Path("/orchestrator/version").HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		writer.Header().Set("Content-Type", "application/json")
 		writer.WriteHeader(200)
@@ -258,6 +292,12 @@ func (r MuxRouter) Init() {
 	r.HelmRouter.initPipelineTriggerRouter(pipelineConfigRouter)
 	r.appRouter.InitAppRouter(pipelineConfigRouter)
 
+	labelTemplateRouter := r.Router.PathPrefix("/orchestrator/label-templates").Subrouter()
+	r.appRouter.InitLabelTemplateRouter(labelTemplateRouter)
+
+	labelWebhookRouter := r.Router.PathPrefix("/orchestrator/label-webhooks").Subrouter()
+	r.appRouter.InitLabelWebhookRouter(labelWebhookRouter)
+
 	migrateRouter := r.Router.PathPrefix("/orchestrator/migrate").Subrouter()
 	r.MigrateDbRouter.InitMigrateDbRouter(migrateRouter)
 
@@ -413,4 +453,37 @@ func (r MuxRouter) Init() {
 
 	userTerminalAccessRouter := r.Router.PathPrefix("/orchestrator/user/terminal").Subrouter()
 	r.userTerminalAccessRouter.InitTerminalAccessRouter(userTerminalAccessRouter)
+
+	clusterOperationRouter := r.Router.PathPrefix("/orchestrator/operations").Subrouter()
+	r.clusterOperationRouter.InitClusterOperationRouter(clusterOperationRouter)
+
+	jobTemplateRouter := r.Router.PathPrefix("/orchestrator/job-templates").Subrouter()
+	r.jobTemplateRouter.InitJobTemplateRouter(jobTemplateRouter)
+
+	favoriteRouter := r.Router.PathPrefix("/orchestrator/user/favorites").Subrouter()
+	r.favoriteRouter.InitFavoriteRouter(favoriteRouter)
+
+	// endpoints fanning out to customer clusters are rate limited per user
+	r.Router.Use(r.rateLimiter.LimitRoutes(map[string]string{
+		"/orchestrator/k8s/resource/list":             middleware.RouteGroupClusterResource,
+		"/orchestrator/k8s/api-resources/{clusterId}": middleware.RouteGroupClusterResource,
+		"/orchestrator/user/terminal/start":           middleware.RouteGroupTerminal,
+		"/orchestrator/user/terminal/update":          middleware.RouteGroupTerminal,
+		"/orchestrator/app/labels/list":               middleware.RouteGroupLabelFilter,
+	}))
+	// retries of endpoints creating cluster resources or running bulk label updates replay the first response
+	r.Router.Use(r.idempotencyHandler.ForRoutes(
+		"/orchestrator/user/terminal/start",
+		"/orchestrator/app/edit",
+		"/orchestrator/app/edit/projects",
+		"/orchestrator/app/labels/bulk",
+	))
+}
+
+func writeHealthStatus(w http.ResponseWriter, healthStatus *health.HealthStatus) {
+	status := http.StatusOK
+	if !healthStatus.IsUp() {
+		status = http.StatusServiceUnavailable
+	}
+	common.WriteJsonResp(w, nil, healthStatus, status)
 }
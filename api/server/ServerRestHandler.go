@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/server"
 	serverBean "github.com/devtron-labs/devtron/pkg/server/bean"
 	"github.com/devtron-labs/devtron/pkg/user"
@@ -33,6 +34,7 @@ import (
 type ServerRestHandler interface {
 	GetServerInfo(w http.ResponseWriter, r *http.Request)
 	HandleServerAction(w http.ResponseWriter, r *http.Request)
+	GetTunables(w http.ResponseWriter, r *http.Request)
 }
 
 type ServerRestHandlerImpl struct {
@@ -41,6 +43,7 @@ type ServerRestHandlerImpl struct {
 	userService   user.UserService
 	enforcer      casbin.Enforcer
 	validator     *validator.Validate
+	k8sUtil       *util.K8sUtil
 }
 
 func NewServerRestHandlerImpl(logger *zap.SugaredLogger,
@@ -48,6 +51,7 @@ func NewServerRestHandlerImpl(logger *zap.SugaredLogger,
 	userService user.UserService,
 	enforcer casbin.Enforcer,
 	validator *validator.Validate,
+	k8sUtil *util.K8sUtil,
 ) *ServerRestHandlerImpl {
 	return &ServerRestHandlerImpl{
 		logger:        logger,
@@ -55,6 +59,7 @@ func NewServerRestHandlerImpl(logger *zap.SugaredLogger,
 		userService:   userService,
 		enforcer:      enforcer,
 		validator:     validator,
+		k8sUtil:       k8sUtil,
 	}
 }
 
@@ -125,3 +130,19 @@ func (impl ServerRestHandlerImpl) HandleServerAction(w http.ResponseWriter, r *h
 	}
 	common.WriteJsonResp(w, err, res, http.StatusOK)
 }
+
+// GetTunables returns the tunables in use and whether each comes from the env or the tunables ConfigMap
+func (impl ServerRestHandlerImpl) GetTunables(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	// handle super-admin RBAC
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	common.WriteJsonResp(w, nil, impl.k8sUtil.GetEffectiveTunables(), http.StatusOK)
+}
@@ -19,4 +19,5 @@ func NewServerRouterImpl(serverRestHandler ServerRestHandler) *ServerRouterImpl
 func (impl ServerRouterImpl) Init(configRouter *mux.Router) {
 	configRouter.Path("").HandlerFunc(impl.serverRestHandler.GetServerInfo).Methods("GET")
 	configRouter.Path("").HandlerFunc(impl.serverRestHandler.HandleServerAction).Methods("POST")
+	configRouter.Path("/tunables").HandlerFunc(impl.serverRestHandler.GetTunables).Methods("GET")
 }
@@ -1,18 +1,22 @@
 package terminal
 
 import (
-	"encoding/json"
-	"errors"
+	"fmt"
 	"github.com/devtron-labs/devtron/api/restHandler/common"
 	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/clusterTerminalAccess"
+	"github.com/devtron-labs/devtron/pkg/favorites"
 	"github.com/devtron-labs/devtron/pkg/user"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/pagination"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 	"gopkg.in/go-playground/validator.v9"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type UserTerminalAccessRestHandler interface {
@@ -25,6 +29,17 @@ type UserTerminalAccessRestHandler interface {
 	DisconnectAllTerminalSessionAndRetry(w http.ResponseWriter, r *http.Request)
 	FetchTerminalPodEvents(w http.ResponseWriter, r *http.Request)
 	FetchTerminalPodManifest(w http.ResponseWriter, r *http.Request)
+	PrePullTerminalImages(w http.ResponseWriter, r *http.Request)
+	FetchSessionTranscript(w http.ResponseWriter, r *http.Request)
+	ListTerminalSessions(w http.ResponseWriter, r *http.Request)
+	FetchNodeDetail(w http.ResponseWriter, r *http.Request)
+	FetchTerminalUsageReport(w http.ResponseWriter, r *http.Request)
+}
+
+var terminalSessionListingContract = pagination.ListingContract{
+	SortColumns:      map[string]string{"createdOn": "created_on", "updatedOn": "updated_on", "status": "status", "nodeName": "node_name"},
+	DefaultSortBy:    "createdOn",
+	DefaultSortOrder: pagination.Desc,
 }
 
 type UserTerminalAccessRestHandlerImpl struct {
@@ -33,257 +48,259 @@ type UserTerminalAccessRestHandlerImpl struct {
 	Enforcer                  casbin.Enforcer
 	UserService               user.UserService
 	validator                 *validator.Validate
+	favoriteService           favorites.FavoriteService
 }
 
 func NewUserTerminalAccessRestHandlerImpl(logger *zap.SugaredLogger, userTerminalAccessService clusterTerminalAccess.UserTerminalAccessService, Enforcer casbin.Enforcer,
-	UserService user.UserService, validator *validator.Validate) *UserTerminalAccessRestHandlerImpl {
+	UserService user.UserService, validator *validator.Validate, favoriteService favorites.FavoriteService) *UserTerminalAccessRestHandlerImpl {
 	return &UserTerminalAccessRestHandlerImpl{
 		Logger:                    logger,
 		UserTerminalAccessService: userTerminalAccessService,
 		Enforcer:                  Enforcer,
 		UserService:               UserService,
 		validator:                 validator,
+		favoriteService:           favoriteService,
 	}
 }
 
 func (handler UserTerminalAccessRestHandlerImpl) StartTerminalSession(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
 	userId, err := handler.UserService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
-	decoder := json.NewDecoder(r.Body)
 	var request models.UserTerminalSessionRequest
-	err = decoder.Decode(&request)
+	err = common.DecodeJsonStrict(r, &request)
 	if err != nil {
-		handler.Logger.Errorw("request err, StartTerminalSession", "err", err, "payload", request)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		logger.Errorw("request err, StartTerminalSession", "err", err, "payload", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 	request.UserId = userId
-	err = handler.validator.Struct(request)
+	// a preset is expanded before validation so that it is held to the same rules as a request sent in full
+	err = handler.favoriteService.ExpandTerminalRequest(&request)
+	if err != nil {
+		logger.Errorw("service err, ExpandTerminalRequest", "err", err, "favoriteId", request.FavoriteId)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	err = common.ValidateRequest(handler.validator, request)
 	if err != nil {
-		handler.Logger.Errorw("validation err, StartTerminalSession", "err", err, "payload", request)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		logger.Errorw("request err, StartTerminalSession", "err", err, "payload", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 
 	token := r.Header.Get("token")
 	if ok := handler.Enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionCreate, "*"); !ok {
-		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 		return
 	}
 	sessionResponse, err := handler.UserTerminalAccessService.StartTerminalSession(r.Context(), &request)
 	if err != nil {
-		handler.Logger.Errorw("service err, StartTerminalSession", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		logger.Errorw("service err, StartTerminalSession", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
 		return
 	}
 	common.WriteJsonResp(w, nil, sessionResponse, http.StatusOK)
 }
 
 func (handler UserTerminalAccessRestHandlerImpl) UpdateTerminalSession(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
 	userId, err := handler.UserService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
-	decoder := json.NewDecoder(r.Body)
 	var request models.UserTerminalSessionRequest
-	err = decoder.Decode(&request)
+	err = common.DecodeAndValidateJson(r, &request, handler.validator)
 	if err != nil {
-		handler.Logger.Errorw("request err, UpdateTerminalSession", "err", err, "payload", request)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		logger.Errorw("request err, UpdateTerminalSession", "err", err, "payload", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 	request.UserId = userId
-	err = handler.validator.Struct(request)
-	if err != nil {
-		handler.Logger.Errorw("validation err, UpdateTerminalSession", "err", err, "payload", request)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
-		return
-	}
 
 	token := r.Header.Get("token")
 	if ok := handler.Enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
-		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 		return
 	}
 	sessionResponse, err := handler.UserTerminalAccessService.UpdateTerminalSession(r.Context(), &request)
 	if err != nil {
-		handler.Logger.Errorw("service err, UpdateTerminalSession", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		logger.Errorw("service err, UpdateTerminalSession", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
 		return
 	}
 	common.WriteJsonResp(w, nil, sessionResponse, http.StatusOK)
 }
 
 func (handler UserTerminalAccessRestHandlerImpl) UpdateTerminalShellSession(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
 	userId, err := handler.UserService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
-	decoder := json.NewDecoder(r.Body)
 	var request models.UserTerminalShellSessionRequest
-	err = decoder.Decode(&request)
-	if err != nil {
-		handler.Logger.Errorw("request err, UpdateTerminalShellSession", "err", err, "payload", request)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
-		return
-	}
-	err = handler.validator.Struct(request)
+	err = common.DecodeAndValidateJson(r, &request, handler.validator)
 	if err != nil {
-		handler.Logger.Errorw("validation err, UpdateTerminalShellSession", "err", err, "payload", request)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		logger.Errorw("request err, UpdateTerminalShellSession", "err", err, "payload", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 
 	token := r.Header.Get("token")
 	if ok := handler.Enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
-		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 		return
 	}
 	sessionResponse, err := handler.UserTerminalAccessService.UpdateTerminalShellSession(r.Context(), &request)
 	if err != nil {
-		handler.Logger.Errorw("service err, UpdateTerminalShellSession", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		logger.Errorw("service err, UpdateTerminalShellSession", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
 		return
 	}
 	common.WriteJsonResp(w, nil, sessionResponse, http.StatusOK)
 }
 
 func (handler UserTerminalAccessRestHandlerImpl) FetchTerminalStatus(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
 	userId, err := handler.UserService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
 	vars := mux.Vars(r)
 	terminalAccessId, err := strconv.Atoi(vars["terminalAccessId"])
 	if err != nil {
-		handler.Logger.Errorw("request err, FetchTerminalStatus", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		logger.Errorw("request err, FetchTerminalStatus", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 
 	token := r.Header.Get("token")
 	if ok := handler.Enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
-		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 		return
 	}
 	sessionResponse, err := handler.UserTerminalAccessService.FetchTerminalStatus(r.Context(), terminalAccessId)
 	if err != nil {
-		handler.Logger.Errorw("service err, FetchTerminalStatus", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		logger.Errorw("service err, FetchTerminalStatus", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
 		return
 	}
 	common.WriteJsonResp(w, nil, sessionResponse, http.StatusOK)
 }
 
 func (handler UserTerminalAccessRestHandlerImpl) FetchTerminalPodEvents(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
 	userId, err := handler.UserService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
 	vars := mux.Vars(r)
 	terminalAccessId, err := strconv.Atoi(vars["terminalAccessId"])
 	if err != nil {
-		handler.Logger.Errorw("request err, FetchTerminalPodEvents", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		logger.Errorw("request err, FetchTerminalPodEvents", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 
 	token := r.Header.Get("token")
 	if ok := handler.Enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
-		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 		return
 	}
 
 	podEvents, err := handler.UserTerminalAccessService.FetchPodEvents(r.Context(), terminalAccessId)
 	if err != nil {
-		handler.Logger.Errorw("service err, FetchTerminalPodEvents", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		logger.Errorw("service err, FetchTerminalPodEvents", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
 		return
 	}
 	common.WriteJsonResp(w, nil, podEvents, http.StatusOK)
 }
 
 func (handler UserTerminalAccessRestHandlerImpl) FetchTerminalPodManifest(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
 	userId, err := handler.UserService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
 	vars := mux.Vars(r)
 	terminalAccessId, err := strconv.Atoi(vars["terminalAccessId"])
 	if err != nil {
-		handler.Logger.Errorw("request err, FetchTerminalPodManifest", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		logger.Errorw("request err, FetchTerminalPodManifest", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 
 	token := r.Header.Get("token")
 	if ok := handler.Enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
-		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 		return
 	}
 
 	podManifest, err := handler.UserTerminalAccessService.FetchPodManifest(r.Context(), terminalAccessId)
 	if err != nil {
-		handler.Logger.Errorw("service err, FetchTerminalPodManifest", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		logger.Errorw("service err, FetchTerminalPodManifest", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
 		return
 	}
 	common.WriteJsonResp(w, nil, podManifest, http.StatusOK)
 }
 
 func (handler UserTerminalAccessRestHandlerImpl) DisconnectTerminalSession(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
 	userId, err := handler.UserService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
 	vars := mux.Vars(r)
 	terminalAccessId, err := strconv.Atoi(vars["terminalAccessId"])
 	if err != nil {
-		handler.Logger.Errorw("request err, DisconnectTerminalSession", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		logger.Errorw("request err, DisconnectTerminalSession", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 
 	token := r.Header.Get("token")
 	if ok := handler.Enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
-		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 		return
 	}
 	err = handler.UserTerminalAccessService.DisconnectTerminalSession(r.Context(), terminalAccessId)
 	if err != nil {
-		handler.Logger.Errorw("service err, DisconnectTerminalSession", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		logger.Errorw("service err, DisconnectTerminalSession", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
 		return
 	}
 	common.WriteJsonResp(w, nil, nil, http.StatusOK)
 }
 
 func (handler UserTerminalAccessRestHandlerImpl) StopTerminalSession(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
 	userId, err := handler.UserService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
 	vars := mux.Vars(r)
 	terminalAccessId, err := strconv.Atoi(vars["terminalAccessId"])
 	if err != nil {
-		handler.Logger.Errorw("request err, StopTerminalSession", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		logger.Errorw("request err, StopTerminalSession", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 
 	token := r.Header.Get("token")
 	if ok := handler.Enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
-		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 		return
 	}
 	handler.UserTerminalAccessService.StopTerminalSession(r.Context(), terminalAccessId)
@@ -291,38 +308,180 @@ func (handler UserTerminalAccessRestHandlerImpl) StopTerminalSession(w http.Resp
 }
 
 func (handler UserTerminalAccessRestHandlerImpl) DisconnectAllTerminalSessionAndRetry(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
 	userId, err := handler.UserService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
-		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
 		return
 	}
-	decoder := json.NewDecoder(r.Body)
 	var request models.UserTerminalSessionRequest
-	err = decoder.Decode(&request)
+	err = common.DecodeAndValidateJson(r, &request, handler.validator)
 	if err != nil {
-		handler.Logger.Errorw("request err, DisconnectAllTerminalSessionAndRetry", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		logger.Errorw("request err, DisconnectAllTerminalSessionAndRetry", "err", err, "payload", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
 		return
 	}
 	request.UserId = userId
-	err = handler.validator.Struct(request)
-	if err != nil {
-		handler.Logger.Errorw("validation err, DisconnectAllTerminalSessionAndRetry", "err", err, "payload", request)
-		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
-		return
-	}
 
 	token := r.Header.Get("token")
 	if ok := handler.Enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
-		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
 		return
 	}
 	handler.UserTerminalAccessService.DisconnectAllSessionsForUser(r.Context(), userId)
 	sessionResponse, err := handler.UserTerminalAccessService.StartTerminalSession(r.Context(), &request)
 	if err != nil {
-		handler.Logger.Errorw("service err, DisconnectAllTerminalSessionAndRetry", "err", err)
-		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		logger.Errorw("service err, DisconnectAllTerminalSessionAndRetry", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
 		return
 	}
 	common.WriteJsonResp(w, nil, sessionResponse, http.StatusOK)
 }
+
+func (handler UserTerminalAccessRestHandlerImpl) PrePullTerminalImages(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
+	userId, err := handler.UserService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	var request models.UserTerminalImagePrePullRequest
+	err = common.DecodeAndValidateJson(r, &request, handler.validator)
+	if err != nil {
+		logger.Errorw("request err, PrePullTerminalImages", "err", err, "payload", request)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+		return
+	}
+
+	token := r.Header.Get("token")
+	if ok := handler.Enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionCreate, "*"); !ok {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+		return
+	}
+	err = handler.UserTerminalAccessService.PrePullTerminalImages(r.Context(), &request)
+	if err != nil {
+		logger.Errorw("service err, PrePullTerminalImages", "err", err, "payload", request)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, true, http.StatusOK)
+}
+
+func (handler UserTerminalAccessRestHandlerImpl) FetchSessionTranscript(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
+	userId, err := handler.UserService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	sessionId := vars["sessionId"]
+	transcript, err := handler.UserTerminalAccessService.GetSessionTranscript(r.Context(), sessionId)
+	if err != nil {
+		logger.Errorw("service err, FetchSessionTranscript", "err", err, "sessionId", sessionId)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	// transcript is only available to the owner of the session and super admins
+	if transcript.UserId != userId {
+		isSuperAdmin, err := handler.UserService.IsSuperAdmin(int(userId))
+		if err != nil || !isSuperAdmin {
+			common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=terminal-transcript-%s.log", sessionId))
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write([]byte(transcript.Transcript))
+	if err != nil {
+		logger.Errorw("error in writing transcript response", "err", err, "sessionId", sessionId)
+	}
+}
+
+func (handler UserTerminalAccessRestHandlerImpl) ListTerminalSessions(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
+	userId, err := handler.UserService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	listingRequest, err := pagination.ParseListingRequest(r.URL.Query(), terminalSessionListingContract)
+	if err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, err.Error(), err.Error()), http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.Enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+		return
+	}
+	sessions, err := handler.UserTerminalAccessService.ListTerminalSessions(r.Context(), userId, listingRequest)
+	if err != nil {
+		logger.Errorw("service err, ListTerminalSessions", "err", err, "userId", userId)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, sessions, http.StatusOK)
+}
+
+func (handler UserTerminalAccessRestHandlerImpl) FetchNodeDetail(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
+	userId, err := handler.UserService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	clusterId, err := strconv.Atoi(vars["clusterId"])
+	if err != nil {
+		logger.Errorw("request err, FetchNodeDetail", "err", err)
+		common.WriteJsonErrorResp(w, err, http.StatusBadRequest)
+		return
+	}
+	nodeName := vars["nodeName"]
+
+	token := r.Header.Get("token")
+	if ok := handler.Enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+		return
+	}
+	nodeDetail, err := handler.UserTerminalAccessService.GetNodeDetail(r.Context(), clusterId, nodeName)
+	if err != nil {
+		logger.Errorw("service err, FetchNodeDetail", "err", err, "clusterId", clusterId, "nodeName", nodeName)
+		if k8sErrors.IsNotFound(err) {
+			common.WriteJsonErrorResp(w, err, http.StatusNotFound)
+			return
+		}
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, nodeDetail, http.StatusOK)
+}
+
+func (handler UserTerminalAccessRestHandlerImpl) FetchTerminalUsageReport(w http.ResponseWriter, r *http.Request) {
+	logger := util.LoggerFromContext(r.Context(), handler.Logger)
+	userId, err := handler.UserService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthenticated, "unauthenticated user"), http.StatusUnauthorized)
+		return
+	}
+	// usage of all users is only available to super admins
+	isSuperAdmin, err := handler.UserService.IsSuperAdmin(int(userId))
+	if err != nil || !isSuperAdmin {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.UnAuthorized, "unauthorized"), http.StatusForbidden)
+		return
+	}
+	month, err := clusterTerminalAccess.ParseUsageMonth(r.URL.Query().Get("month"), time.Now())
+	if err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, err.Error(), err.Error()), http.StatusBadRequest)
+		return
+	}
+	report, err := handler.UserTerminalAccessService.GetTerminalUsageReport(r.Context(), month)
+	if err != nil {
+		logger.Errorw("service err, FetchTerminalUsageReport", "err", err, "month", month)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, report, http.StatusOK)
+}
@@ -0,0 +1,120 @@
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/clusterTerminalAccess"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/pagination"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type userServiceStub struct {
+	user.UserService
+	userId int32
+}
+
+func (impl userServiceStub) GetLoggedInUser(r *http.Request) (int32, error) {
+	return impl.userId, nil
+}
+
+type enforcerStub struct {
+	casbin.Enforcer
+}
+
+func (impl enforcerStub) Enforce(emailId string, resource string, action string, resourceItem string) bool {
+	return true
+}
+
+type terminalAccessServiceStub struct {
+	clusterTerminalAccess.UserTerminalAccessService
+	request *pagination.ListingRequest
+}
+
+func (impl *terminalAccessServiceStub) ListTerminalSessions(ctx context.Context, userId int32, request *pagination.ListingRequest) (*pagination.ListingResponse, error) {
+	impl.request = request
+	return pagination.NewListingResponse(request, 42, []interface{}{}), nil
+}
+
+// TestListTerminalSessions_ListingContract documents the listing query contract shared by list endpoints
+func TestListTerminalSessions_ListingContract(t *testing.T) {
+	logger, err := util.NewSugardLogger()
+	assert.Nil(t, err)
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		want       pagination.ListingRequest
+	}{
+		{
+			name:       "defaults",
+			query:      "",
+			wantStatus: http.StatusOK,
+			want:       pagination.ListingRequest{Offset: 0, Size: pagination.DefaultPageSize, SortBy: "createdOn", SortOrder: pagination.Desc},
+		},
+		{
+			name:       "explicit page sort and search",
+			query:      "offset=40&size=10&sortBy=status&sortOrder=asc&searchKey=%20node-1%20",
+			wantStatus: http.StatusOK,
+			want:       pagination.ListingRequest{Offset: 40, Size: 10, SortBy: "status", SortOrder: pagination.Asc, SearchKey: "node-1"},
+		},
+		{
+			name:       "size is capped",
+			query:      "size=5000",
+			wantStatus: http.StatusOK,
+			want:       pagination.ListingRequest{Size: pagination.MaxPageSize, SortBy: "createdOn", SortOrder: pagination.Desc},
+		},
+		{
+			name:       "negative offset",
+			query:      "offset=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "non numeric size",
+			query:      "size=ten",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "sort by outside allow list",
+			query:      "sortBy=pod_name%3Bdrop%20table%20users",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown sort order",
+			query:      "sortOrder=random",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &terminalAccessServiceStub{}
+			handler := NewUserTerminalAccessRestHandlerImpl(logger, service, enforcerStub{}, userServiceStub{userId: 2}, nil, nil)
+			r := httptest.NewRequest(http.MethodGet, "/user/terminal/sessions?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.ListTerminalSessions(w, r)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				assert.Nil(t, service.request)
+				return
+			}
+			assert.Equal(t, tt.want.Offset, service.request.Offset)
+			assert.Equal(t, tt.want.Size, service.request.Size)
+			assert.Equal(t, tt.want.SortBy, service.request.SortBy)
+			assert.Equal(t, tt.want.SortOrder, service.request.SortOrder)
+			assert.Equal(t, tt.want.SearchKey, service.request.SearchKey)
+
+			var body struct {
+				Result pagination.ListingResponse `json:"result"`
+			}
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, 42, body.Result.TotalCount)
+			assert.Equal(t, tt.want.Offset, body.Result.Offset)
+			assert.Equal(t, tt.want.Size, body.Result.Size)
+		})
+	}
+}
@@ -37,6 +37,16 @@ func (router UserTerminalAccessRouterImpl) InitTerminalAccessRouter(userTerminal
 		HandlerFunc(router.userTerminalAccessRestHandler.StopTerminalSession).Queries("terminalAccessId", "{terminalAccessId}").Methods("PUT")
 	userTerminalAccessRouter.Path("/disconnectAndRetry").
 		HandlerFunc(router.userTerminalAccessRestHandler.DisconnectAllTerminalSessionAndRetry).Methods("POST")
+	userTerminalAccessRouter.Path("/image/pre-pull").
+		HandlerFunc(router.userTerminalAccessRestHandler.PrePullTerminalImages).Methods("POST")
+	userTerminalAccessRouter.Path("/sessions").
+		HandlerFunc(router.userTerminalAccessRestHandler.ListTerminalSessions).Methods("GET")
+	userTerminalAccessRouter.Path("/{sessionId}/transcript").
+		HandlerFunc(router.userTerminalAccessRestHandler.FetchSessionTranscript).Methods("GET")
+	userTerminalAccessRouter.Path("/node/detail").
+		HandlerFunc(router.userTerminalAccessRestHandler.FetchNodeDetail).Queries("clusterId", "{clusterId}", "nodeName", "{nodeName}").Methods("GET")
+	userTerminalAccessRouter.Path("/usage").
+		HandlerFunc(router.userTerminalAccessRestHandler.FetchTerminalUsageReport).Methods("GET")
 
 	//TODO fetch all user running/starting pods
 	//TODO fetch all running/starting pods also include sessionIds if session exists
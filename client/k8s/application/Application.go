@@ -31,6 +31,7 @@ type K8sClientService interface {
 	GetPodLogs(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean) (io.ReadCloser, error)
 	GetApiResources(restConfig *rest.Config, includeOnlyVerb string) ([]*K8sApiResource, error)
 	GetResourceList(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean) (*ResourceListResponse, bool, error)
+	ListResourcesInChunks(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, chunkSize int64, onChunk func(resp *ResourceListResponse, namespaced bool) (bool, error)) error
 	ApplyResource(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, manifest string) (*ManifestResponse, error)
 }
 
@@ -50,6 +51,8 @@ type K8sRequestBean struct {
 	ResourceIdentifier ResourceIdentifier `json:"resourceIdentifier"`
 	Patch              string             `json:"patch,omitempty"`
 	PodLogsRequest     PodLogsRequest     `json:"podLogsRequest,omitempty"`
+	LabelSelector      string             `json:"labelSelector,omitempty"`
+	FieldSelector      string             `json:"fieldSelector,omitempty"`
 }
 
 type PodLogsRequest struct {
@@ -397,23 +400,68 @@ func (impl K8sClientServiceImpl) GetResourceList(ctx context.Context, restConfig
 		return nil, namespaced, err
 	}
 	resourceIdentifier := request.ResourceIdentifier
-	var resp *unstructured.UnstructuredList
+	resp, err := listResources(ctx, resourceIf, namespaced, request, "", 0)
+	if err != nil {
+		impl.logger.Errorw("error in getting resource", "err", err, "resource", resourceIdentifier)
+		return nil, namespaced, err
+	}
+	return &ResourceListResponse{*resp}, namespaced, nil
+}
+
+// ListResourcesInChunks pages through the list with the continue token so that large listings are never held in
+// memory at once, onChunk is called for every page and can stop the listing by returning false
+func (impl K8sClientServiceImpl) ListResourcesInChunks(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, chunkSize int64, onChunk func(resp *ResourceListResponse, namespaced bool) (bool, error)) error {
+	resourceIf, namespaced, err := impl.GetResourceIfWithAcceptHeader(restConfig, request)
+	if err != nil {
+		impl.logger.Errorw("error in getting dynamic interface for resource", "err", err)
+		return err
+	}
+	err = ListInChunks(ctx, resourceIf, namespaced, request, chunkSize, onChunk)
+	if err != nil {
+		impl.logger.Errorw("error in getting resource", "err", err, "resource", request.ResourceIdentifier)
+	}
+	return err
+}
+
+// ListInChunks is the paging loop of ListResourcesInChunks, it stops as soon as ctx is done so that a listing is not
+// continued for a client which went away
+func ListInChunks(ctx context.Context, resourceIf dynamic.NamespaceableResourceInterface, namespaced bool, request *K8sRequestBean, chunkSize int64, onChunk func(resp *ResourceListResponse, namespaced bool) (bool, error)) error {
+	continueToken := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		resp, err := listResources(ctx, resourceIf, namespaced, request, continueToken, chunkSize)
+		if err != nil {
+			return err
+		}
+		next, err := onChunk(&ResourceListResponse{*resp}, namespaced)
+		if err != nil || !next {
+			return err
+		}
+		continueToken = resp.GetContinue()
+		if continueToken == "" {
+			return nil
+		}
+	}
+}
+
+func listResources(ctx context.Context, resourceIf dynamic.NamespaceableResourceInterface, namespaced bool, request *K8sRequestBean, continueToken string, limit int64) (*unstructured.UnstructuredList, error) {
+	resourceIdentifier := request.ResourceIdentifier
 	listOptions := metav1.ListOptions{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       resourceIdentifier.GroupVersionKind.Kind,
 			APIVersion: resourceIdentifier.GroupVersionKind.GroupVersion().String(),
 		},
+		LabelSelector: request.LabelSelector,
+		FieldSelector: request.FieldSelector,
+		Continue:      continueToken,
+		Limit:         limit,
 	}
 	if len(resourceIdentifier.Namespace) > 0 && namespaced {
-		resp, err = resourceIf.Namespace(resourceIdentifier.Namespace).List(ctx, listOptions)
-	} else {
-		resp, err = resourceIf.List(ctx, listOptions)
+		return resourceIf.Namespace(resourceIdentifier.Namespace).List(ctx, listOptions)
 	}
-	if err != nil {
-		impl.logger.Errorw("error in getting resource", "err", err, "resource", resourceIdentifier)
-		return nil, namespaced, err
-	}
-	return &ResourceListResponse{*resp}, namespaced, nil
+	return resourceIf.List(ctx, listOptions)
 }
 
 func (impl K8sClientServiceImpl) ApplyResource(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, manifest string) (*ManifestResponse, error) {
@@ -0,0 +1,119 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var configMapGvr = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// pagedResourceInterface serves total synthetic config maps page by page, the fake dynamic client drops limit and
+// continue so List is generated here from the options
+type pagedResourceInterface struct {
+	dynamic.NamespaceableResourceInterface
+	total int
+	lists int
+}
+
+func newPagedResourceInterface(total int) *pagedResourceInterface {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(k8sRuntime.NewScheme(), map[schema.GroupVersionResource]string{configMapGvr: "ConfigMapList"})
+	return &pagedResourceInterface{NamespaceableResourceInterface: client.Resource(configMapGvr), total: total}
+}
+
+func (impl *pagedResourceInterface) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	impl.lists++
+	start := 0
+	if opts.Continue != "" {
+		start, _ = strconv.Atoi(opts.Continue)
+	}
+	end := impl.total
+	if opts.Limit > 0 && start+int(opts.Limit) < end {
+		end = start + int(opts.Limit)
+	}
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMapList"}}
+	for i := start; i < end; i++ {
+		list.Items = append(list.Items, unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": fmt.Sprintf("config-%d", i), "namespace": "default"},
+			"data":       map[string]interface{}{"payload": strings.Repeat("x", 1024)},
+		}})
+	}
+	if end < impl.total {
+		list.SetContinue(strconv.Itoa(end))
+	}
+	return list, nil
+}
+
+func newConfigMapListRequest() *K8sRequestBean {
+	return &K8sRequestBean{ResourceIdentifier: ResourceIdentifier{
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+	}}
+}
+
+func heapInUse() uint64 {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+func TestListInChunksKeepsMemoryFlat(t *testing.T) {
+	const total = 50000
+	resourceIf := newPagedResourceInterface(total)
+	encoder := json.NewEncoder(io.Discard)
+	baseline := heapInUse()
+	var maxHeap uint64
+	listed := 0
+	err := ListInChunks(context.Background(), resourceIf, true, newConfigMapListRequest(), 500, func(resp *ResourceListResponse, namespaced bool) (bool, error) {
+		for _, item := range resp.Resources.Items {
+			if err := encoder.Encode(item.Object); err != nil {
+				return false, err
+			}
+		}
+		listed += len(resp.Resources.Items)
+		if heap := heapInUse(); heap > maxHeap {
+			maxHeap = heap
+		}
+		return true, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, total, listed)
+	assert.Equal(t, total/500, resourceIf.lists)
+	// holding the whole listing would take well over 50MB, a page is around 1MB
+	var growth uint64
+	if maxHeap > baseline {
+		growth = maxHeap - baseline
+	}
+	assert.Less(t, growth, uint64(16<<20), "heap grew by %d bytes while listing", growth)
+}
+
+func TestListInChunksStopsOnCancel(t *testing.T) {
+	resourceIf := newPagedResourceInterface(5000)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pages := 0
+	err := ListInChunks(ctx, resourceIf, true, newConfigMapListRequest(), 500, func(resp *ResourceListResponse, namespaced bool) (bool, error) {
+		pages++
+		// the client went away while the first page was written
+		cancel()
+		return true, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, pages)
+	assert.Equal(t, 1, resourceIf.lists)
+}
@@ -24,4 +24,5 @@ type ApplyResourcesResponse struct {
 	Name     string `json:"name"`
 	Error    string `json:"error"`
 	IsUpdate bool   `json:"isUpdate"`
+	Warning  string `json:"warning,omitempty"`
 }
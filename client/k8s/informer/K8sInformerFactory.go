@@ -11,8 +11,8 @@ import (
 	"github.com/devtron-labs/authenticator/client"
 	"github.com/devtron-labs/devtron/api/bean"
 	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
@@ -30,19 +30,33 @@ type K8sInformerFactoryImpl struct {
 	mutex                     sync.Mutex
 	informerStopper           map[string]chan struct{}
 	runtimeConfig             *client.RuntimeConfig
+	namespaceCaches           map[int]*namespaceCache
+	cacheMutex                sync.RWMutex
+	namespaceCacheConfig      *NamespaceCacheConfig
 }
 
 type K8sInformerFactory interface {
 	GetLatestNamespaceListGroupByCLuster() map[string]map[string]bool
 	BuildInformer(clusterInfo []*bean.ClusterInfo)
 	CleanNamespaceInformer(clusterName string)
+	// GetCachedNamespaces returns the watched namespaces of the cluster with the time they were last in sync
+	GetCachedNamespaces(clusterId int) (*bean.CachedNamespaces, error)
+	// IsNamespaceCacheExpired tells whether stale namespaces are older than NAMESPACE_CACHE_MAX_STALENESS_SECS
+	IsNamespaceCacheExpired(cachedNamespaces *bean.CachedNamespaces, now time.Time) bool
 }
 
 func NewK8sInformerFactoryImpl(logger *zap.SugaredLogger, globalMapClusterNamespace map[string]map[string]bool, runtimeConfig *client.RuntimeConfig) *K8sInformerFactoryImpl {
+	namespaceCacheConfig, err := GetNamespaceCacheConfig()
+	if err != nil {
+		logger.Errorw("error in parsing namespace cache config, using defaults", "err", err)
+		namespaceCacheConfig = &NamespaceCacheConfig{ResyncSecs: 60, MaxStalenessSecs: 300}
+	}
 	informerFactory := &K8sInformerFactoryImpl{
 		logger:                    logger,
 		globalMapClusterNamespace: globalMapClusterNamespace,
 		runtimeConfig:             runtimeConfig,
+		namespaceCaches:           make(map[int]*namespaceCache),
+		namespaceCacheConfig:      namespaceCacheConfig,
 	}
 	informerFactory.informerStopper = make(map[string]chan struct{})
 	return informerFactory
@@ -90,20 +104,22 @@ func (impl *K8sInformerFactoryImpl) BuildInformer(clusterInfo []*bean.ClusterInf
 				restConfig = clusterConfig
 			}
 
-			impl.buildInformerAndNamespaceList(info.ClusterName, restConfig, &impl.mutex)
+			impl.buildInformerAndNamespaceList(info.ClusterId, info.ClusterName, restConfig, &impl.mutex)
 		} else {
 			c := &rest.Config{
 				Host:            info.ServerUrl,
 				BearerToken:     info.BearerToken,
 				TLSClientConfig: rest.TLSClientConfig{Insecure: true},
 			}
-			impl.buildInformerAndNamespaceList(info.ClusterName, c, &impl.mutex)
+			impl.buildInformerAndNamespaceList(info.ClusterId, info.ClusterName, c, &impl.mutex)
 		}
 	}
 	return
 }
 
-func (impl *K8sInformerFactoryImpl) buildInformerAndNamespaceList(clusterName string, config *rest.Config, mutex *sync.Mutex) map[string]map[string]bool {
+// buildInformerAndNamespaceList watches the namespaces of the cluster, the informer keeps the names and labels for
+// GetCachedNamespaces and the handlers maintain the names grouped by cluster
+func (impl *K8sInformerFactoryImpl) buildInformerAndNamespaceList(clusterId int, clusterName string, config *rest.Config, mutex *sync.Mutex) map[string]map[string]bool {
 	allNamespaces := make(map[string]bool)
 	impl.globalMapClusterNamespace[clusterName] = allNamespaces
 	httpClient, err := util.OverrideK8sHttpClientWithTracer(config)
@@ -115,10 +131,21 @@ func (impl *K8sInformerFactoryImpl) buildInformerAndNamespaceList(clusterName st
 		impl.logger.Errorw("error in create k8s config", "err", err)
 		return impl.globalMapClusterNamespace
 	}
-	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(clusterClient, time.Minute)
-	nsInformer := informerFactory.Core().V1().Namespaces()
+	namespaceCache := &namespaceCache{clusterName: clusterName}
+	nsInformer := cache.NewSharedIndexInformer(newNamespaceListWatch(clusterClient, namespaceCache), &v1.Namespace{},
+		time.Duration(impl.namespaceCacheConfig.ResyncSecs)*time.Second, cache.Indexers{})
+	if err = nsInformer.SetTransform(stripNamespace); err != nil {
+		impl.logger.Errorw("error in setting namespace transform", "err", err, "clusterName", clusterName)
+	}
+	if err = nsInformer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		namespaceCache.markStale(err, time.Now())
+		cache.DefaultWatchErrorHandler(r, err)
+	}); err != nil {
+		impl.logger.Errorw("error in setting namespace watch error handler", "err", err, "clusterName", clusterName)
+	}
+	namespaceCache.informer = nsInformer
 	stopper := make(chan struct{})
-	nsInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	nsInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			if mobject, ok := obj.(metav1.Object); ok {
 				mutex.Lock()
@@ -148,8 +175,11 @@ func (impl *K8sInformerFactoryImpl) buildInformerAndNamespaceList(clusterName st
 			}
 		},
 	})
-	informerFactory.Start(stopper)
+	go nsInformer.Run(stopper)
 	impl.informerStopper[clusterName] = stopper
+	impl.cacheMutex.Lock()
+	impl.namespaceCaches[clusterId] = namespaceCache
+	impl.cacheMutex.Unlock()
 	return impl.globalMapClusterNamespace
 }
 
@@ -159,5 +189,9 @@ func (impl *K8sInformerFactoryImpl) CleanNamespaceInformer(clusterName string) {
 		close(stopper)
 		delete(impl.informerStopper, clusterName)
 	}
+	impl.removeNamespaceCaches(clusterName)
+	impl.mutex.Lock()
+	delete(impl.globalMapClusterNamespace, clusterName)
+	impl.mutex.Unlock()
 	return
 }
@@ -0,0 +1,149 @@
+package informer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/devtron-labs/devtron/api/bean"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var ErrNamespaceCacheNotSynced = errors.New("namespaces of the cluster are not cached yet")
+
+// NamespaceCacheConfig sets the resync period of the namespace watches and the age after which cached namespaces are
+// listed live again by the pickers
+type NamespaceCacheConfig struct {
+	ResyncSecs       int `env:"NAMESPACE_CACHE_RESYNC_SECS" envDefault:"60"`
+	MaxStalenessSecs int `env:"NAMESPACE_CACHE_MAX_STALENESS_SECS" envDefault:"300"`
+}
+
+func GetNamespaceCacheConfig() (*NamespaceCacheConfig, error) {
+	config := &NamespaceCacheConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+// namespaceCache holds the namespace informer of a cluster, its store only keeps names and labels. The cache is fresh
+// while the watch is established, syncedOn is the last time that was known to be true
+type namespaceCache struct {
+	clusterName string
+	informer    cache.SharedIndexInformer
+	mutex       sync.RWMutex
+	stale       bool
+	syncedOn    time.Time
+	lastErr     error
+}
+
+func (c *namespaceCache) markSynced(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stale = false
+	c.syncedOn = now
+	c.lastErr = nil
+}
+
+func (c *namespaceCache) markStale(err error, now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.stale {
+		// the watch was serving events till now
+		c.syncedOn = now
+	}
+	c.stale = true
+	c.lastErr = err
+}
+
+// status returns when the cache was last in sync with the cluster, now while the watch is established
+func (c *namespaceCache) status(now time.Time) (time.Time, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.stale {
+		return c.syncedOn, true
+	}
+	return now, false
+}
+
+// newNamespaceListWatch records the outcome of every list and watch call of the reflector on the cache
+func newNamespaceListWatch(clusterClient kubernetes.Interface, namespaceCache *namespaceCache) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			list, err := clusterClient.CoreV1().Namespaces().List(context.Background(), options)
+			if err != nil {
+				namespaceCache.markStale(err, time.Now())
+			} else {
+				namespaceCache.markSynced(time.Now())
+			}
+			return list, err
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			watcher, err := clusterClient.CoreV1().Namespaces().Watch(context.Background(), options)
+			if err != nil {
+				namespaceCache.markStale(err, time.Now())
+			}
+			return watcher, err
+		},
+	}
+}
+
+// stripNamespace keeps only the name and labels of namespaces in the informer store
+func stripNamespace(obj interface{}) (interface{}, error) {
+	namespace, ok := obj.(*v1.Namespace)
+	if !ok {
+		return obj, nil
+	}
+	return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace.Name, Labels: namespace.Labels}}, nil
+}
+
+func namespaceInfos(objects []interface{}) []*bean.NamespaceInfo {
+	namespaces := make([]*bean.NamespaceInfo, 0, len(objects))
+	for _, obj := range objects {
+		if namespace, ok := obj.(*v1.Namespace); ok {
+			namespaces = append(namespaces, &bean.NamespaceInfo{Name: namespace.Name, Labels: namespace.Labels})
+		}
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+	return namespaces
+}
+
+// GetCachedNamespaces returns ErrNamespaceCacheNotSynced till the first list of the cluster succeeded
+func (impl *K8sInformerFactoryImpl) GetCachedNamespaces(clusterId int) (*bean.CachedNamespaces, error) {
+	impl.cacheMutex.RLock()
+	namespaceCache, ok := impl.namespaceCaches[clusterId]
+	impl.cacheMutex.RUnlock()
+	if !ok || !namespaceCache.informer.HasSynced() {
+		return nil, ErrNamespaceCacheNotSynced
+	}
+	syncedOn, stale := namespaceCache.status(time.Now())
+	return &bean.CachedNamespaces{
+		ClusterId:  clusterId,
+		Namespaces: namespaceInfos(namespaceCache.informer.GetStore().List()),
+		SyncedOn:   syncedOn,
+		Stale:      stale,
+	}, nil
+}
+
+func (impl *K8sInformerFactoryImpl) IsNamespaceCacheExpired(cachedNamespaces *bean.CachedNamespaces, now time.Time) bool {
+	maxStaleness := time.Duration(impl.namespaceCacheConfig.MaxStalenessSecs) * time.Second
+	return cachedNamespaces.Stale && now.Sub(cachedNamespaces.SyncedOn) > maxStaleness
+}
+
+func (impl *K8sInformerFactoryImpl) removeNamespaceCaches(clusterName string) {
+	impl.cacheMutex.Lock()
+	defer impl.cacheMutex.Unlock()
+	for clusterId, namespaceCache := range impl.namespaceCaches {
+		if namespaceCache.clusterName == clusterName {
+			delete(impl.namespaceCaches, clusterId)
+		}
+	}
+}
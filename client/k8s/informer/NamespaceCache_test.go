@@ -0,0 +1,53 @@
+package informer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStripNamespace(t *testing.T) {
+	namespace := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "payments", Labels: map[string]string{"team": "billing"},
+			Annotations: map[string]string{"note": "large"}, ResourceVersion: "42"},
+		Spec:   v1.NamespaceSpec{Finalizers: []v1.FinalizerName{v1.FinalizerKubernetes}},
+		Status: v1.NamespaceStatus{Phase: v1.NamespaceActive},
+	}
+	stripped, err := stripNamespace(namespace)
+	assert.Nil(t, err)
+	assert.Equal(t, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments", Labels: map[string]string{"team": "billing"}}}, stripped)
+}
+
+func TestNamespaceCacheStatus(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	namespaceCache := &namespaceCache{}
+	namespaceCache.markSynced(now)
+	syncedOn, stale := namespaceCache.status(now.Add(time.Hour))
+	assert.False(t, stale)
+	assert.Equal(t, now.Add(time.Hour), syncedOn)
+
+	namespaceCache.markStale(errors.New("connection refused"), now.Add(2*time.Hour))
+	namespaceCache.markStale(errors.New("connection refused"), now.Add(3*time.Hour))
+	syncedOn, stale = namespaceCache.status(now.Add(4 * time.Hour))
+	assert.True(t, stale)
+	assert.Equal(t, now.Add(2*time.Hour), syncedOn)
+
+	namespaceCache.markSynced(now.Add(5 * time.Hour))
+	_, stale = namespaceCache.status(now.Add(5 * time.Hour))
+	assert.False(t, stale)
+}
+
+func TestNamespaceInfos(t *testing.T) {
+	infos := namespaceInfos([]interface{}{
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"env": "dev"}}},
+	})
+	assert.Len(t, infos, 2)
+	assert.Equal(t, "default", infos[0].Name)
+	assert.Equal(t, "dev", infos[0].Labels["env"])
+	assert.Equal(t, "kube-system", infos[1].Name)
+}
@@ -8,9 +8,12 @@ import (
 	appStoreValues "github.com/devtron-labs/devtron/api/appStore/values"
 	"github.com/devtron-labs/devtron/api/chartRepo"
 	"github.com/devtron-labs/devtron/api/cluster"
+	"github.com/devtron-labs/devtron/api/clusterOperation"
 	"github.com/devtron-labs/devtron/api/dashboardEvent"
 	"github.com/devtron-labs/devtron/api/externalLink"
+	"github.com/devtron-labs/devtron/api/favorites"
 	client "github.com/devtron-labs/devtron/api/helm-app"
+	"github.com/devtron-labs/devtron/api/jobTemplate"
 	"github.com/devtron-labs/devtron/api/module"
 	"github.com/devtron-labs/devtron/api/restHandler/common"
 	"github.com/devtron-labs/devtron/api/router"
@@ -55,6 +58,9 @@ type MuxRouter struct {
 	userAttributesRouter     router.UserAttributesRouter
 	telemetryRouter          router.TelemetryRouter
 	userTerminalAccessRouter terminal.UserTerminalAccessRouter
+	clusterOperationRouter   clusterOperation.ClusterOperationRouter
+	jobTemplateRouter        jobTemplate.JobTemplateRouter
+	favoriteRouter           favorites.FavoriteRouter
 	attributesRouter         router.AttributesRouter
 	appRouter                router.AppRouter
 }
@@ -84,6 +90,9 @@ func NewMuxRouter(
 	userAttributesRouter router.UserAttributesRouter,
 	telemetryRouter router.TelemetryRouter,
 	userTerminalAccessRouter terminal.UserTerminalAccessRouter,
+	clusterOperationRouter clusterOperation.ClusterOperationRouter,
+	jobTemplateRouter jobTemplate.JobTemplateRouter,
+	favoriteRouter favorites.FavoriteRouter,
 	attributesRouter router.AttributesRouter,
 	appRouter router.AppRouter,
 ) *MuxRouter {
@@ -114,6 +123,9 @@ func NewMuxRouter(
 		userAttributesRouter:     userAttributesRouter,
 		telemetryRouter:          telemetryRouter,
 		userTerminalAccessRouter: userTerminalAccessRouter,
+		clusterOperationRouter:   clusterOperationRouter,
+		jobTemplateRouter:        jobTemplateRouter,
+		favoriteRouter:           favoriteRouter,
 		attributesRouter:         attributesRouter,
 		appRouter:                appRouter,
 	}
@@ -177,6 +189,12 @@ func (r *MuxRouter) Init() {
 	ApplicationSubRouter := r.Router.PathPrefix("/orchestrator/app").Subrouter()
 	r.appRouter.InitAppRouter(ApplicationSubRouter)
 
+	labelTemplateRouter := r.Router.PathPrefix("/orchestrator/label-templates").Subrouter()
+	r.appRouter.InitLabelTemplateRouter(labelTemplateRouter)
+
+	labelWebhookRouter := r.Router.PathPrefix("/orchestrator/label-webhooks").Subrouter()
+	r.appRouter.InitLabelWebhookRouter(labelWebhookRouter)
+
 	k8sApp := r.Router.PathPrefix("/orchestrator/k8s").Subrouter()
 	r.k8sApplicationRouter.InitK8sApplicationRouter(k8sApp)
 
@@ -236,6 +254,15 @@ func (r *MuxRouter) Init() {
 	userTerminalAccessRouter := r.Router.PathPrefix("/orchestrator/user/terminal").Subrouter()
 	r.userTerminalAccessRouter.InitTerminalAccessRouter(userTerminalAccessRouter)
 
+	clusterOperationRouter := r.Router.PathPrefix("/orchestrator/operations").Subrouter()
+	r.clusterOperationRouter.InitClusterOperationRouter(clusterOperationRouter)
+
+	jobTemplateRouter := r.Router.PathPrefix("/orchestrator/job-templates").Subrouter()
+	r.jobTemplateRouter.InitJobTemplateRouter(jobTemplateRouter)
+
+	favoriteRouter := r.Router.PathPrefix("/orchestrator/user/favorites").Subrouter()
+	r.favoriteRouter.InitFavoriteRouter(favoriteRouter)
+
 	attributeRouter := r.Router.PathPrefix("/orchestrator/attributes").Subrouter()
 	r.attributesRouter.InitAttributesRouter(attributeRouter)
 }
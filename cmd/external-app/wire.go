@@ -11,10 +11,13 @@ import (
 	appStoreValues "github.com/devtron-labs/devtron/api/appStore/values"
 	chartRepo "github.com/devtron-labs/devtron/api/chartRepo"
 	"github.com/devtron-labs/devtron/api/cluster"
+	"github.com/devtron-labs/devtron/api/clusterOperation"
 	"github.com/devtron-labs/devtron/api/connector"
 	"github.com/devtron-labs/devtron/api/dashboardEvent"
 	"github.com/devtron-labs/devtron/api/externalLink"
+	"github.com/devtron-labs/devtron/api/favorites"
 	client "github.com/devtron-labs/devtron/api/helm-app"
+	"github.com/devtron-labs/devtron/api/jobTemplate"
 	"github.com/devtron-labs/devtron/api/module"
 	"github.com/devtron-labs/devtron/api/restHandler"
 	"github.com/devtron-labs/devtron/api/router"
@@ -72,6 +75,9 @@ func InitializeApp() (*App, error) {
 		apiToken.ApiTokenWireSet,
 		webhookHelm.WebhookHelmWireSet,
 		terminal.TerminalWireSet,
+		clusterOperation.ClusterOperationWireSet,
+		jobTemplate.JobTemplateWireSet,
+		favorites.FavoriteWireSet,
 
 		NewApp,
 		NewMuxRouter,
@@ -100,6 +106,12 @@ func InitializeApp() (*App, error) {
 		wire.Bind(new(app.AppCrudOperationService), new(*app.AppCrudOperationServiceImpl)),
 		pipelineConfig.NewAppLabelRepositoryImpl,
 		wire.Bind(new(pipelineConfig.AppLabelRepository), new(*pipelineConfig.AppLabelRepositoryImpl)),
+		pipelineConfig.NewLabelTemplateRepositoryImpl,
+		wire.Bind(new(pipelineConfig.LabelTemplateRepository), new(*pipelineConfig.LabelTemplateRepositoryImpl)),
+		pipelineConfig.NewLabelWebhookRepositoryImpl,
+		wire.Bind(new(pipelineConfig.LabelWebhookRepository), new(*pipelineConfig.LabelWebhookRepositoryImpl)),
+		app.NewLabelEventPublisherImpl,
+		wire.Bind(new(app.LabelEventPublisher), new(*app.LabelEventPublisherImpl)),
 		//acd session client bind with authenticator login
 		wire.Bind(new(session.ServiceClient), new(*middleware.LoginService)),
 		connector.NewPumpImpl,
@@ -109,6 +121,8 @@ func InitializeApp() (*App, error) {
 		wire.Bind(new(telemetry.TelemetryEventClient), new(*telemetry.TelemetryEventClientImpl)),
 
 		wire.Bind(new(delete2.DeleteService), new(*delete2.DeleteServiceImpl)),
+		delete2.NewClusterDeleteCheckServiceImpl,
+		wire.Bind(new(delete2.ClusterDeleteCheckService), new(*delete2.ClusterDeleteCheckServiceImpl)),
 
 		// needed for enforcer util
 		pipelineConfig.NewPipelineRepositoryImpl,
@@ -15,10 +15,13 @@ import (
 	"github.com/devtron-labs/devtron/api/appStore/values"
 	chartRepo2 "github.com/devtron-labs/devtron/api/chartRepo"
 	cluster2 "github.com/devtron-labs/devtron/api/cluster"
+	clusterOperation2 "github.com/devtron-labs/devtron/api/clusterOperation"
 	"github.com/devtron-labs/devtron/api/connector"
 	"github.com/devtron-labs/devtron/api/dashboardEvent"
 	externalLink2 "github.com/devtron-labs/devtron/api/externalLink"
 	client2 "github.com/devtron-labs/devtron/api/helm-app"
+	favorites2 "github.com/devtron-labs/devtron/api/favorites"
+	jobTemplate2 "github.com/devtron-labs/devtron/api/jobTemplate"
 	module2 "github.com/devtron-labs/devtron/api/module"
 	"github.com/devtron-labs/devtron/api/restHandler"
 	"github.com/devtron-labs/devtron/api/router"
@@ -52,9 +55,12 @@ import (
 	"github.com/devtron-labs/devtron/pkg/chartRepo/repository"
 	"github.com/devtron-labs/devtron/pkg/cluster"
 	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
 	"github.com/devtron-labs/devtron/pkg/clusterTerminalAccess"
 	delete2 "github.com/devtron-labs/devtron/pkg/delete"
 	"github.com/devtron-labs/devtron/pkg/externalLink"
+	"github.com/devtron-labs/devtron/pkg/favorites"
+	"github.com/devtron-labs/devtron/pkg/jobTemplate"
 	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
 	repository5 "github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs/repository"
 	"github.com/devtron-labs/devtron/pkg/module"
@@ -161,7 +167,9 @@ func InitializeApp() (*App, error) {
 	if err != nil {
 		return nil, err
 	}
-	chartRepositoryServiceImpl := chartRepo.NewChartRepositoryServiceImpl(sugaredLogger, chartRepoRepositoryImpl, k8sUtil, clusterServiceImpl, acdAuthConfig, httpClient, serverEnvConfigServerEnvConfig)
+	clusterOperationRepositoryImpl := repository4.NewClusterOperationRepositoryImpl(db)
+	clusterOperationServiceImpl := clusterOperation.NewClusterOperationServiceImpl(sugaredLogger, clusterOperationRepositoryImpl)
+	chartRepositoryServiceImpl := chartRepo.NewChartRepositoryServiceImpl(sugaredLogger, chartRepoRepositoryImpl, k8sUtil, clusterServiceImpl, acdAuthConfig, httpClient, serverEnvConfigServerEnvConfig, clusterOperationServiceImpl)
 	installedAppRepositoryImpl := repository3.NewInstalledAppRepositoryImpl(sugaredLogger, db)
 	deleteServiceImpl := delete2.NewDeleteServiceImpl(sugaredLogger, teamServiceImpl, clusterServiceImpl, environmentServiceImpl, chartRepositoryServiceImpl, installedAppRepositoryImpl)
 	teamRestHandlerImpl := team2.NewTeamRestHandlerImpl(sugaredLogger, teamServiceImpl, userServiceImpl, enforcerImpl, validate, userAuthServiceImpl, deleteServiceImpl)
@@ -175,8 +183,13 @@ func InitializeApp() (*App, error) {
 	if err != nil {
 		return nil, err
 	}
-	clusterRestHandlerImpl := cluster2.NewClusterRestHandlerImpl(clusterServiceImpl, sugaredLogger, userServiceImpl, validate, enforcerImpl, deleteServiceImpl, helmUserServiceImpl)
-	clusterRouterImpl := cluster2.NewClusterRouterImpl(clusterRestHandlerImpl)
+	podPlacementPolicyRepositoryImpl := repository2.NewPodPlacementPolicyRepositoryImpl(db)
+	podPlacementPolicyServiceImpl := cluster.NewPodPlacementPolicyServiceImpl(sugaredLogger, podPlacementPolicyRepositoryImpl, clusterRepositoryImpl, k8sUtil)
+	podPlacementPolicyRestHandlerImpl := cluster2.NewPodPlacementPolicyRestHandlerImpl(sugaredLogger, podPlacementPolicyServiceImpl, userServiceImpl, validate, enforcerImpl)
+	freezeWindowRepositoryImpl := repository2.NewFreezeWindowRepositoryImpl(db)
+	k8sResourceHistoryRepositoryImpl := repository5.NewK8sResourceHistoryRepositoryImpl(db, sugaredLogger)
+	freezeWindowServiceImpl := cluster.NewFreezeWindowServiceImpl(sugaredLogger, freezeWindowRepositoryImpl, environmentRepositoryImpl, userServiceImpl, k8sResourceHistoryRepositoryImpl, k8sUtil)
+	freezeWindowRestHandlerImpl := cluster2.NewFreezeWindowRestHandlerImpl(sugaredLogger, freezeWindowServiceImpl, userServiceImpl, validate, enforcerImpl)
 	dashboardConfig, err := dashboard.GetConfig()
 	if err != nil {
 		return nil, err
@@ -202,9 +215,8 @@ func InitializeApp() (*App, error) {
 	environmentRestHandlerImpl := cluster2.NewEnvironmentRestHandlerImpl(environmentServiceImpl, sugaredLogger, userServiceImpl, validate, enforcerImpl, deleteServiceImpl)
 	environmentRouterImpl := cluster2.NewEnvironmentRouterImpl(environmentRestHandlerImpl)
 	k8sClientServiceImpl := application.NewK8sClientServiceImpl(sugaredLogger, clusterRepositoryImpl)
-	k8sResourceHistoryRepositoryImpl := repository5.NewK8sResourceHistoryRepositoryImpl(db, sugaredLogger)
 	k8sResourceHistoryServiceImpl := kubernetesResourceAuditLogs.Newk8sResourceHistoryServiceImpl(k8sResourceHistoryRepositoryImpl, sugaredLogger, appRepositoryImpl, environmentRepositoryImpl)
-	k8sApplicationServiceImpl := k8s.NewK8sApplicationServiceImpl(sugaredLogger, clusterServiceImpl, pumpImpl, k8sClientServiceImpl, helmAppServiceImpl, k8sUtil, acdAuthConfig, k8sResourceHistoryServiceImpl)
+	k8sApplicationServiceImpl := k8s.NewK8sApplicationServiceImpl(sugaredLogger, clusterServiceImpl, pumpImpl, k8sClientServiceImpl, helmAppServiceImpl, k8sUtil, acdAuthConfig, k8sResourceHistoryServiceImpl, clusterOperationServiceImpl)
 	terminalSessionHandlerImpl := terminal.NewTerminalSessionHandlerImpl(environmentServiceImpl, clusterServiceImpl, sugaredLogger)
 	ciPipelineRepositoryImpl := pipelineConfig.NewCiPipelineRepositoryImpl(db, sugaredLogger)
 	enforcerUtilImpl := rbac.NewEnforcerUtilImpl(sugaredLogger, teamRepositoryImpl, appRepositoryImpl, environmentRepositoryImpl, pipelineRepositoryImpl, ciPipelineRepositoryImpl, clusterRepositoryImpl)
@@ -274,7 +286,7 @@ func InitializeApp() (*App, error) {
 	moduleRouterImpl := module2.NewModuleRouterImpl(moduleRestHandlerImpl)
 	serverActionAuditLogRepositoryImpl := server.NewServerActionAuditLogRepositoryImpl(db)
 	serverServiceImpl := server.NewServerServiceImpl(sugaredLogger, serverActionAuditLogRepositoryImpl, serverDataStoreServerDataStore, serverEnvConfigServerEnvConfig, helmAppServiceImpl, moduleRepositoryImpl)
-	serverRestHandlerImpl := server2.NewServerRestHandlerImpl(sugaredLogger, serverServiceImpl, userServiceImpl, enforcerImpl, validate)
+	serverRestHandlerImpl := server2.NewServerRestHandlerImpl(sugaredLogger, serverServiceImpl, userServiceImpl, enforcerImpl, validate, k8sUtil)
 	serverRouterImpl := server2.NewServerRouterImpl(serverRestHandlerImpl)
 	apiTokenSecretServiceImpl, err := apiToken.NewApiTokenSecretServiceImpl(sugaredLogger, attributesServiceImpl, apiTokenSecretStore)
 	if err != nil {
@@ -284,11 +296,11 @@ func InitializeApp() (*App, error) {
 	apiTokenServiceImpl := apiToken.NewApiTokenServiceImpl(sugaredLogger, apiTokenSecretServiceImpl, userServiceImpl, userAuditServiceImpl, apiTokenRepositoryImpl)
 	apiTokenRestHandlerImpl := apiToken2.NewApiTokenRestHandlerImpl(sugaredLogger, apiTokenServiceImpl, userServiceImpl, enforcerImpl, validate)
 	apiTokenRouterImpl := apiToken2.NewApiTokenRouterImpl(apiTokenRestHandlerImpl)
-	clusterCronServiceImpl, err := k8s.NewClusterCronServiceImpl(sugaredLogger, clusterServiceImpl, k8sApplicationServiceImpl, clusterRepositoryImpl)
+	clusterCronServiceImpl, err := k8s.NewClusterCronServiceImpl(sugaredLogger, clusterServiceImpl, k8sApplicationServiceImpl, clusterRepositoryImpl, k8sUtil)
 	if err != nil {
 		return nil, err
 	}
-	k8sCapacityServiceImpl := k8s.NewK8sCapacityServiceImpl(sugaredLogger, clusterServiceImpl, k8sApplicationServiceImpl, k8sClientServiceImpl, clusterCronServiceImpl)
+	k8sCapacityServiceImpl := k8s.NewK8sCapacityServiceImpl(sugaredLogger, clusterServiceImpl, k8sApplicationServiceImpl, k8sClientServiceImpl, clusterCronServiceImpl, clusterOperationServiceImpl, k8sUtil)
 	k8sCapacityRestHandlerImpl := k8s.NewK8sCapacityRestHandlerImpl(sugaredLogger, k8sCapacityServiceImpl, userServiceImpl, enforcerImpl, clusterServiceImpl, environmentServiceImpl)
 	k8sCapacityRouterImpl := k8s.NewK8sCapacityRouterImpl(k8sCapacityRestHandlerImpl)
 	webhookHelmServiceImpl := webhookHelm.NewWebhookHelmServiceImpl(sugaredLogger, helmAppServiceImpl, clusterServiceImpl, chartRepositoryServiceImpl, attributesServiceImpl)
@@ -305,19 +317,49 @@ func InitializeApp() (*App, error) {
 	if err != nil {
 		return nil, err
 	}
-	userTerminalAccessServiceImpl, err := clusterTerminalAccess.NewUserTerminalAccessServiceImpl(sugaredLogger, terminalAccessRepositoryImpl, userTerminalSessionConfig, k8sApplicationServiceImpl, k8sClientServiceImpl, terminalSessionHandlerImpl)
+	userTerminalAccessServiceImpl, err := clusterTerminalAccess.NewUserTerminalAccessServiceImpl(sugaredLogger, terminalAccessRepositoryImpl, userTerminalSessionConfig, k8sApplicationServiceImpl, k8sClientServiceImpl, terminalSessionHandlerImpl, k8sUtil, clusterRepositoryImpl)
 	if err != nil {
 		return nil, err
 	}
-	userTerminalAccessRestHandlerImpl := terminal2.NewUserTerminalAccessRestHandlerImpl(sugaredLogger, userTerminalAccessServiceImpl, enforcerImpl, userServiceImpl, validate)
+	userFavoriteRepositoryImpl := repository4.NewUserFavoriteRepositoryImpl(db)
+	favoriteServiceImpl := favorites.NewFavoriteServiceImpl(sugaredLogger, userFavoriteRepositoryImpl)
+	userTerminalAccessRestHandlerImpl := terminal2.NewUserTerminalAccessRestHandlerImpl(sugaredLogger, userTerminalAccessServiceImpl, enforcerImpl, userServiceImpl, validate, favoriteServiceImpl)
 	userTerminalAccessRouterImpl := terminal2.NewUserTerminalAccessRouterImpl(userTerminalAccessRestHandlerImpl)
+	clusterOperationRestHandlerImpl := clusterOperation2.NewClusterOperationRestHandlerImpl(sugaredLogger, clusterOperationServiceImpl, userServiceImpl, enforcerImpl)
+	orphanedResourceServiceImpl := clusterOperation.NewOrphanedResourceServiceImpl(sugaredLogger, clusterServiceImpl, clusterOperationServiceImpl, clusterOperationRepositoryImpl, terminalAccessRepositoryImpl, appRepositoryImpl, environmentRepositoryImpl, k8sUtil)
+	orphanedResourceRestHandlerImpl := clusterOperation2.NewOrphanedResourceRestHandlerImpl(sugaredLogger, orphanedResourceServiceImpl, userServiceImpl, validate, enforcerImpl)
+	secretRotationServiceImpl := clusterOperation.NewSecretRotationServiceImpl(sugaredLogger, clusterServiceImpl, clusterOperationServiceImpl, k8sUtil)
+	secretRotationRestHandlerImpl := clusterOperation2.NewSecretRotationRestHandlerImpl(sugaredLogger, secretRotationServiceImpl, userServiceImpl, validate, enforcerImpl)
+	clusterOperationRouterImpl := clusterOperation2.NewClusterOperationRouterImpl(clusterOperationRestHandlerImpl, orphanedResourceRestHandlerImpl, secretRotationRestHandlerImpl)
+	jobTemplateRepositoryImpl := repository4.NewJobTemplateRepositoryImpl(db)
+	jobTemplateServiceImpl := jobTemplate.NewJobTemplateServiceImpl(sugaredLogger, jobTemplateRepositoryImpl, k8sUtil)
+	jobTemplateRestHandlerImpl := jobTemplate2.NewJobTemplateRestHandlerImpl(sugaredLogger, jobTemplateServiceImpl, userServiceImpl, validate)
+	jobTemplateRouterImpl := jobTemplate2.NewJobTemplateRouterImpl(jobTemplateRestHandlerImpl)
+	favoriteRestHandlerImpl := favorites2.NewFavoriteRestHandlerImpl(sugaredLogger, favoriteServiceImpl, userServiceImpl, validate)
+	favoriteRouterImpl := favorites2.NewFavoriteRouterImpl(favoriteRestHandlerImpl)
 	attributesRestHandlerImpl := restHandler.NewAttributesRestHandlerImpl(sugaredLogger, enforcerImpl, userServiceImpl, attributesServiceImpl)
 	attributesRouterImpl := router.NewAttributesRouterImpl(attributesRestHandlerImpl)
 	appLabelRepositoryImpl := pipelineConfig.NewAppLabelRepositoryImpl(db)
-	appCrudOperationServiceImpl := app2.NewAppCrudOperationServiceImpl(appLabelRepositoryImpl, sugaredLogger, appRepositoryImpl, userRepositoryImpl, installedAppRepositoryImpl)
+	labelTemplateRepositoryImpl := pipelineConfig.NewLabelTemplateRepositoryImpl(db)
+	transactionUtilImpl, err := sql.NewTransactionUtilImpl(db, sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	clusterDeleteCheckServiceImpl := delete2.NewClusterDeleteCheckServiceImpl(sugaredLogger, clusterServiceImpl, environmentRepositoryImpl, terminalAccessRepositoryImpl, userTerminalAccessServiceImpl, clusterOperationServiceImpl, deleteServiceImpl, transactionUtilImpl, k8sUtil)
+	clusterRestHandlerImpl := cluster2.NewClusterRestHandlerImpl(clusterServiceImpl, sugaredLogger, userServiceImpl, validate, enforcerImpl, deleteServiceImpl, helmUserServiceImpl, clusterDeleteCheckServiceImpl)
+	clusterRouterImpl := cluster2.NewClusterRouterImpl(clusterRestHandlerImpl, podPlacementPolicyRestHandlerImpl, freezeWindowRestHandlerImpl)
+	labelWebhookRepositoryImpl := pipelineConfig.NewLabelWebhookRepositoryImpl(db)
+	labelEventPublisherImpl, err := app2.NewLabelEventPublisherImpl(sugaredLogger, labelWebhookRepositoryImpl)
+	if err != nil {
+		return nil, err
+	}
+	appCrudOperationServiceImpl, err := app2.NewAppCrudOperationServiceImpl(appLabelRepositoryImpl, sugaredLogger, appRepositoryImpl, userRepositoryImpl, installedAppRepositoryImpl, transactionUtilImpl, labelTemplateRepositoryImpl, labelEventPublisherImpl)
+	if err != nil {
+		return nil, err
+	}
 	appRestHandlerImpl := restHandler.NewAppRestHandlerImpl(sugaredLogger, appCrudOperationServiceImpl, userServiceImpl, validate, enforcerUtilImpl, enforcerImpl, helmAppServiceImpl, enforcerUtilHelmImpl)
 	appRouterImpl := router.NewAppRouterImpl(sugaredLogger, appRestHandlerImpl)
-	muxRouter := NewMuxRouter(sugaredLogger, ssoLoginRouterImpl, teamRouterImpl, userAuthRouterImpl, userRouterImpl, clusterRouterImpl, dashboardRouterImpl, helmAppRouterImpl, environmentRouterImpl, k8sApplicationRouterImpl, chartRepositoryRouterImpl, appStoreDiscoverRouterImpl, appStoreValuesRouterImpl, appStoreDeploymentRouterImpl, dashboardTelemetryRouterImpl, commonDeploymentRouterImpl, externalLinkRouterImpl, moduleRouterImpl, serverRouterImpl, apiTokenRouterImpl, k8sCapacityRouterImpl, webhookHelmRouterImpl, userAttributesRouterImpl, telemetryRouterImpl, userTerminalAccessRouterImpl, attributesRouterImpl, appRouterImpl)
+	muxRouter := NewMuxRouter(sugaredLogger, ssoLoginRouterImpl, teamRouterImpl, userAuthRouterImpl, userRouterImpl, clusterRouterImpl, dashboardRouterImpl, helmAppRouterImpl, environmentRouterImpl, k8sApplicationRouterImpl, chartRepositoryRouterImpl, appStoreDiscoverRouterImpl, appStoreValuesRouterImpl, appStoreDeploymentRouterImpl, dashboardTelemetryRouterImpl, commonDeploymentRouterImpl, externalLinkRouterImpl, moduleRouterImpl, serverRouterImpl, apiTokenRouterImpl, k8sCapacityRouterImpl, webhookHelmRouterImpl, userAttributesRouterImpl, telemetryRouterImpl, userTerminalAccessRouterImpl, clusterOperationRouterImpl, jobTemplateRouterImpl, favoriteRouterImpl, attributesRouterImpl, appRouterImpl)
 	mainApp := NewApp(db, sessionManager, muxRouter, telemetryEventClientImpl, posthogClient, sugaredLogger)
 	return mainApp, nil
 }
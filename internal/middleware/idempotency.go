@@ -0,0 +1,277 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/go-pg/pg"
+	"github.com/gorilla/mux"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotencyKeyMaxLength   = 255
+	idempotencyMaxCreateTries = 2
+)
+
+type IdempotencyConfig struct {
+	Enabled                bool `env:"IDEMPOTENCY_KEY_ENABLED" envDefault:"true"`
+	TtlInMinutes           int  `env:"IDEMPOTENCY_KEY_TTL_IN_MINUTES" envDefault:"1440"`
+	CleanupIntervalInMins  int  `env:"IDEMPOTENCY_KEY_CLEANUP_INTERVAL_IN_MINUTES" envDefault:"60"`
+	MaxResponseSizeInBytes int  `env:"IDEMPOTENCY_KEY_MAX_RESPONSE_SIZE_IN_BYTES" envDefault:"1048576"`
+}
+
+// IdempotencyUserFunc resolves the user the idempotency key is scoped to, ok is false for unauthenticated requests
+type IdempotencyUserFunc func(r *http.Request) (userId int32, ok bool)
+
+// IdempotencyHandler makes POST endpoints safe to retry, the first request holding an Idempotency-Key executes and its
+// response is stored against the key and request hash, replays get the stored response and reuse of the key with a
+// different payload is rejected
+type IdempotencyHandler struct {
+	logger     *zap.SugaredLogger
+	config     *IdempotencyConfig
+	repository repository.IdempotencyKeyRepository
+	userFunc   IdempotencyUserFunc
+	now        func() time.Time
+}
+
+func GetIdempotencyConfig() (*IdempotencyConfig, error) {
+	config := &IdempotencyConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+func NewIdempotencyHandler(logger *zap.SugaredLogger, idempotencyKeyRepository repository.IdempotencyKeyRepository,
+	userService user.UserService) (*IdempotencyHandler, error) {
+	config, err := GetIdempotencyConfig()
+	if err != nil {
+		logger.Errorw("error in parsing idempotency config", "err", err)
+		return nil, err
+	}
+	handler := newIdempotencyHandler(logger, config, idempotencyKeyRepository, func(r *http.Request) (int32, bool) {
+		userId, _, err := userService.GetUserByToken(r.Header.Get("token"))
+		return userId, err == nil && userId > 0
+	})
+	if !config.Enabled {
+		return handler, nil
+	}
+	cleanupCron := cron.New(cron.WithChain())
+	cleanupCron.Start()
+	_, err = cleanupCron.AddFunc(fmt.Sprintf("@every %dm", config.CleanupIntervalInMins), handler.CleanupExpiredKeys)
+	if err != nil {
+		logger.Errorw("error occurred while starting idempotency key cleanup cron", "err", err)
+		return nil, err
+	}
+	return handler, nil
+}
+
+func newIdempotencyHandler(logger *zap.SugaredLogger, config *IdempotencyConfig, idempotencyKeyRepository repository.IdempotencyKeyRepository,
+	userFunc IdempotencyUserFunc) *IdempotencyHandler {
+	return &IdempotencyHandler{
+		logger:     logger,
+		config:     config,
+		repository: idempotencyKeyRepository,
+		userFunc:   userFunc,
+		now:        time.Now,
+	}
+}
+
+func (impl *IdempotencyHandler) CleanupExpiredKeys() {
+	deleted, err := impl.repository.DeleteExpired(impl.now())
+	if err != nil {
+		impl.logger.Errorw("error in deleting expired idempotency keys", "err", err)
+		return
+	}
+	impl.logger.Debugw("deleted expired idempotency keys", "count", deleted)
+}
+
+// ForRoutes returns a middleware honouring Idempotency-Key on POST requests to the given path templates
+func (impl *IdempotencyHandler) ForRoutes(pathTemplates ...string) mux.MiddlewareFunc {
+	routes := make(map[string]bool, len(pathTemplates))
+	for _, pathTemplate := range pathTemplates {
+		routes[pathTemplate] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if !impl.config.Enabled || key == "" || r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			pathTemplate, _ := route.GetPathTemplate()
+			if !routes[pathTemplate] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			impl.serveIdempotent(w, r, next, key, pathTemplate)
+		})
+	}
+}
+
+func (impl *IdempotencyHandler) serveIdempotent(w http.ResponseWriter, r *http.Request, next http.Handler, key string, route string) {
+	if len(key) > idempotencyKeyMaxLength {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, "idempotency key too long", fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, idempotencyKeyMaxLength)), http.StatusBadRequest)
+		return
+	}
+	userId, ok := impl.userFunc(r)
+	if !ok {
+		// unauthenticated requests are rejected by the handler itself
+		next.ServeHTTP(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.BadRequest, err.Error(), "unable to read request body"), http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	requestHash := hashRequest(r, body)
+
+	entry, created, err := impl.reserveKey(key, userId, route, requestHash)
+	if err != nil {
+		impl.logger.Errorw("error in reserving idempotency key", "err", err, "route", route, "userId", userId)
+		common.WriteJsonErrorResp(w, err, http.StatusInternalServerError)
+		return
+	}
+	if !created {
+		impl.replay(w, entry, requestHash)
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK, maxSize: impl.config.MaxResponseSizeInBytes}
+	completed := false
+	defer func() {
+		if !completed {
+			// handler panicked, release the key so that the client can retry
+			impl.releaseKey(entry)
+		}
+	}()
+	next.ServeHTTP(recorder, r)
+	completed = true
+	if recorder.status >= http.StatusInternalServerError || recorder.truncated {
+		// server side failures are not cached so that retries get a chance to succeed
+		impl.releaseKey(entry)
+		return
+	}
+	entry.Completed = true
+	entry.ResponseStatus = recorder.status
+	entry.ResponseType = recorder.Header().Get("Content-Type")
+	entry.ResponseBody = recorder.body.String()
+	entry.UpdatedOn = impl.now()
+	entry.UpdatedBy = userId
+	if err = impl.repository.UpdateResponse(entry); err != nil {
+		impl.logger.Errorw("error in saving idempotent response", "err", err, "route", route, "userId", userId)
+	}
+}
+
+// reserveKey inserts the key in progress, if it is already taken the existing entry is returned, expired entries which
+// are not yet cleaned up are removed and the key is taken afresh
+func (impl *IdempotencyHandler) reserveKey(key string, userId int32, route string, requestHash string) (*repository.IdempotencyKey, bool, error) {
+	for i := 0; i < idempotencyMaxCreateTries; i++ {
+		now := impl.now()
+		entry := &repository.IdempotencyKey{
+			IdempotencyKey: key,
+			UserId:         userId,
+			Route:          route,
+			RequestHash:    requestHash,
+			ExpiresOn:      now.Add(time.Duration(impl.config.TtlInMinutes) * time.Minute),
+		}
+		entry.CreatedOn = now
+		entry.CreatedBy = userId
+		entry.UpdatedOn = now
+		entry.UpdatedBy = userId
+		created, err := impl.repository.Create(entry)
+		if err != nil || created {
+			return entry, created, err
+		}
+		existing, err := impl.repository.FindByKey(key, userId, route)
+		if err == pg.ErrNoRows {
+			// released in between, try again
+			continue
+		} else if err != nil {
+			return nil, false, err
+		}
+		if existing.ExpiresOn.After(now) {
+			return existing, false, nil
+		}
+		if err = impl.repository.Delete(existing); err != nil {
+			return nil, false, err
+		}
+	}
+	return nil, false, fmt.Errorf("unable to reserve idempotency key %s", key)
+}
+
+func (impl *IdempotencyHandler) releaseKey(entry *repository.IdempotencyKey) {
+	if err := impl.repository.Delete(entry); err != nil {
+		impl.logger.Errorw("error in releasing idempotency key", "err", err, "route", entry.Route, "userId", entry.UserId)
+	}
+}
+
+func (impl *IdempotencyHandler) replay(w http.ResponseWriter, entry *repository.IdempotencyKey, requestHash string) {
+	if entry.RequestHash != requestHash {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.ResourceConflict, "idempotency key reused with a different payload",
+			fmt.Sprintf("%s was already used for a different request", IdempotencyKeyHeader)), http.StatusConflict)
+		return
+	}
+	if !entry.Completed {
+		common.WriteJsonErrorResp(w, common.NewApiError(common.ResourceConflict, "request with the same idempotency key is in progress",
+			fmt.Sprintf("a request with the same %s is still in progress", IdempotencyKeyHeader)), http.StatusConflict)
+		return
+	}
+	if entry.ResponseType != "" {
+		w.Header().Set("Content-Type", entry.ResponseType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(entry.ResponseStatus)
+	_, _ = w.Write([]byte(entry.ResponseBody))
+}
+
+func hashRequest(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method))
+	hash.Write([]byte(r.URL.RequestURI()))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes the response through while keeping a copy of it to be stored
+type responseRecorder struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	maxSize   int
+	truncated bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.truncated {
+		if r.maxSize > 0 && r.body.Len()+len(b) > r.maxSize {
+			r.truncated = true
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
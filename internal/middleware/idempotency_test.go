@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/go-pg/pg"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type inMemoryIdempotencyKeyRepository struct {
+	mutex   sync.Mutex
+	entries map[string]*repository.IdempotencyKey
+	lastId  int
+}
+
+func newInMemoryIdempotencyKeyRepository() *inMemoryIdempotencyKeyRepository {
+	return &inMemoryIdempotencyKeyRepository{entries: make(map[string]*repository.IdempotencyKey)}
+}
+
+func idempotencyEntryKey(key string, userId int32, route string) string {
+	return fmt.Sprintf("%s/%d/%s", key, userId, route)
+}
+
+func (impl *inMemoryIdempotencyKeyRepository) Create(entry *repository.IdempotencyKey) (bool, error) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	entryKey := idempotencyEntryKey(entry.IdempotencyKey, entry.UserId, entry.Route)
+	if _, ok := impl.entries[entryKey]; ok {
+		return false, nil
+	}
+	impl.lastId++
+	entry.Id = impl.lastId
+	stored := *entry
+	impl.entries[entryKey] = &stored
+	return true, nil
+}
+
+func (impl *inMemoryIdempotencyKeyRepository) FindByKey(key string, userId int32, route string) (*repository.IdempotencyKey, error) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	entry, ok := impl.entries[idempotencyEntryKey(key, userId, route)]
+	if !ok {
+		return nil, pg.ErrNoRows
+	}
+	found := *entry
+	return &found, nil
+}
+
+func (impl *inMemoryIdempotencyKeyRepository) UpdateResponse(entry *repository.IdempotencyKey) error {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	stored := *entry
+	impl.entries[idempotencyEntryKey(entry.IdempotencyKey, entry.UserId, entry.Route)] = &stored
+	return nil
+}
+
+func (impl *inMemoryIdempotencyKeyRepository) Delete(entry *repository.IdempotencyKey) error {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	delete(impl.entries, idempotencyEntryKey(entry.IdempotencyKey, entry.UserId, entry.Route))
+	return nil
+}
+
+func (impl *inMemoryIdempotencyKeyRepository) DeleteExpired(now time.Time) (int, error) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	deleted := 0
+	for key, entry := range impl.entries {
+		if entry.ExpiresOn.Before(now) {
+			delete(impl.entries, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+type idempotencyTestSetup struct {
+	router     *mux.Router
+	handler    *IdempotencyHandler
+	repository *inMemoryIdempotencyKeyRepository
+	executions *int32
+	clock      *fakeClock
+}
+
+func newIdempotencyTestSetup(t *testing.T, status int, delay time.Duration) *idempotencyTestSetup {
+	logger, err := util.NewSugardLogger()
+	assert.Nil(t, err)
+	repo := newInMemoryIdempotencyKeyRepository()
+	config := &IdempotencyConfig{Enabled: true, TtlInMinutes: 60, MaxResponseSizeInBytes: 1024}
+	handler := newIdempotencyHandler(logger, config, repo, func(r *http.Request) (int32, bool) {
+		return 1, true
+	})
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	handler.now = clock.Now
+	var executions int32
+	router := mux.NewRouter()
+	router.Path("/orchestrator/user/terminal/start").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := atomic.AddInt32(&executions, 1)
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"execution":%d}`, count)))
+	}).Methods("POST")
+	router.Use(handler.ForRoutes("/orchestrator/user/terminal/start"))
+	return &idempotencyTestSetup{router: router, handler: handler, repository: repo, executions: &executions, clock: clock}
+}
+
+func (s *idempotencyTestSetup) post(key string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/orchestrator/user/terminal/start", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, r)
+	return w
+}
+
+func TestIdempotency_ConcurrentFirstRequestsExecuteOnce(t *testing.T) {
+	setup := newIdempotencyTestSetup(t, http.StatusOK, 50*time.Millisecond)
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 20)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = setup.post("key-1", `{"clusterId":1}`)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(setup.executions))
+	succeeded := 0
+	for _, w := range responses {
+		switch w.Code {
+		case http.StatusOK:
+			succeeded++
+			assert.Equal(t, `{"execution":1}`, w.Body.String())
+		default:
+			assert.Equal(t, http.StatusConflict, w.Code)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+
+	// once completed every retry gets the stored response
+	w := setup.post("key-1", `{"clusterId":1}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"execution":1}`, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(setup.executions))
+}
+
+func TestIdempotency_ReuseWithDifferentPayload(t *testing.T) {
+	setup := newIdempotencyTestSetup(t, http.StatusOK, 0)
+	assert.Equal(t, http.StatusOK, setup.post("key-1", `{"clusterId":1}`).Code)
+	w := setup.post("key-1", `{"clusterId":2}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "different")
+	assert.Equal(t, int32(1), atomic.LoadInt32(setup.executions))
+}
+
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	setup := newIdempotencyTestSetup(t, http.StatusInternalServerError, 0)
+	assert.Equal(t, http.StatusInternalServerError, setup.post("key-1", `{"clusterId":1}`).Code)
+	assert.Equal(t, http.StatusInternalServerError, setup.post("key-1", `{"clusterId":1}`).Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(setup.executions))
+}
+
+func TestIdempotency_ExpiredKeysExecuteAgain(t *testing.T) {
+	setup := newIdempotencyTestSetup(t, http.StatusOK, 0)
+	assert.Equal(t, `{"execution":1}`, setup.post("key-1", `{"clusterId":1}`).Body.String())
+	setup.clock.Advance(2 * time.Hour)
+	assert.Equal(t, `{"execution":2}`, setup.post("key-1", `{"clusterId":1}`).Body.String())
+
+	setup.clock.Advance(2 * time.Hour)
+	setup.handler.CleanupExpiredKeys()
+	assert.Empty(t, setup.repository.entries)
+}
+
+func TestIdempotency_RequestsWithoutKey(t *testing.T) {
+	setup := newIdempotencyTestSetup(t, http.StatusOK, 0)
+	assert.Equal(t, `{"execution":1}`, setup.post("", `{"clusterId":1}`).Body.String())
+	assert.Equal(t, `{"execution":2}`, setup.post("", `{"clusterId":1}`).Body.String())
+	assert.Empty(t, setup.repository.entries)
+}
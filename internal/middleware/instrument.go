@@ -57,6 +57,16 @@ var CiTriggerCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 	Name: "ci_trigger_counter",
 }, []string{"appId", "pipelineId"})
 
+var BuildJobCompletionRatioGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "orchestrator_build_job_completion_ratio",
+	Help: "succeeded over finished build jobs in the BUILD_JOB_METRICS_WINDOW_IN_MINS window",
+}, []string{"namespace"})
+
+var BuildJobsFinishedGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "orchestrator_build_jobs_finished",
+	Help: "build jobs finished in the BUILD_JOB_METRICS_WINDOW_IN_MINS window, partitioned by result",
+}, []string{"namespace", "result"})
+
 // prometheusMiddleware implements mux.MiddlewareFunc.
 func PrometheusMiddleware(next http.Handler) http.Handler {
 	//	prometheus.MustRegister(requestCounter)
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"container/list"
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/apiToken"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// route groups fanning out to customer clusters, each group has its own bucket per user
+const (
+	RouteGroupClusterResource = "cluster-resource"
+	RouteGroupTerminal        = "terminal"
+	RouteGroupLabelFilter     = "label-filter"
+)
+
+type RateLimitConfig struct {
+	Enabled              bool    `env:"RATE_LIMIT_ENABLED" envDefault:"true"`
+	MaxBuckets           int     `env:"RATE_LIMIT_MAX_BUCKETS" envDefault:"10000"`
+	ClusterResourceRps   float64 `env:"RATE_LIMIT_CLUSTER_RESOURCE_RPS" envDefault:"5"`
+	ClusterResourceBurst int     `env:"RATE_LIMIT_CLUSTER_RESOURCE_BURST" envDefault:"20"`
+	TerminalRps          float64 `env:"RATE_LIMIT_TERMINAL_RPS" envDefault:"0.5"`
+	TerminalBurst        int     `env:"RATE_LIMIT_TERMINAL_BURST" envDefault:"5"`
+	LabelFilterRps       float64 `env:"RATE_LIMIT_LABEL_FILTER_RPS" envDefault:"5"`
+	LabelFilterBurst     int     `env:"RATE_LIMIT_LABEL_FILTER_BURST" envDefault:"10"`
+	// ExemptApiTokens are the names of the api tokens of internal services which are never limited, other api tokens
+	// are limited like users
+	ExemptApiTokens []string `env:"RATE_LIMIT_EXEMPT_API_TOKENS" envSeparator:","`
+}
+
+type rateLimit struct {
+	rps   float64
+	burst int
+}
+
+// RateLimitKeyFunc returns the key requests are limited by, skip is true for callers which are never limited
+type RateLimitKeyFunc func(r *http.Request) (key string, skip bool)
+
+type bucketEntry struct {
+	key     string
+	limiter *rate.Limiter
+}
+
+// RateLimiter applies token bucket limits per key and route group, buckets are kept in an lru so that memory stays
+// bounded by MaxBuckets irrespective of the number of users
+type RateLimiter struct {
+	logger  *zap.SugaredLogger
+	config  *RateLimitConfig
+	limits  map[string]rateLimit
+	keyFunc RateLimitKeyFunc
+	now     func() time.Time
+
+	mutex   sync.Mutex
+	buckets map[string]*list.Element
+	lru     *list.List
+}
+
+func GetRateLimitConfig() (*RateLimitConfig, error) {
+	config := &RateLimitConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+func NewRateLimiter(logger *zap.SugaredLogger, userService user.UserService) (*RateLimiter, error) {
+	config, err := GetRateLimitConfig()
+	if err != nil {
+		logger.Errorw("error in parsing rate limit config", "err", err)
+		return nil, err
+	}
+	return newRateLimiter(logger, config, userRateLimitKeyFunc(userService, config.ExemptApiTokens)), nil
+}
+
+func newRateLimiter(logger *zap.SugaredLogger, config *RateLimitConfig, keyFunc RateLimitKeyFunc) *RateLimiter {
+	return &RateLimiter{
+		logger: logger,
+		config: config,
+		limits: map[string]rateLimit{
+			RouteGroupClusterResource: {rps: config.ClusterResourceRps, burst: config.ClusterResourceBurst},
+			RouteGroupTerminal:        {rps: config.TerminalRps, burst: config.TerminalBurst},
+			RouteGroupLabelFilter:     {rps: config.LabelFilterRps, burst: config.LabelFilterBurst},
+		},
+		keyFunc: keyFunc,
+		now:     time.Now,
+		buckets: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// userRateLimitKeyFunc keys requests by user id, the api tokens named in exemptApiTokens are not limited and requests
+// without a valid token are keyed by their remote address
+func userRateLimitKeyFunc(userService user.UserService, exemptApiTokens []string) RateLimitKeyFunc {
+	exempt := make(map[string]bool, len(exemptApiTokens))
+	for _, name := range exemptApiTokens {
+		if name = strings.TrimSpace(name); len(name) > 0 {
+			exempt[name] = true
+		}
+	}
+	return func(r *http.Request) (string, bool) {
+		token := r.Header.Get("token")
+		userId, userType, err := userService.GetUserByToken(token)
+		if err != nil {
+			host, _, splitErr := net.SplitHostPort(r.RemoteAddr)
+			if splitErr != nil {
+				return r.RemoteAddr, false
+			}
+			return host, false
+		}
+		if userType == bean.USER_TYPE_API_TOKEN && len(exempt) > 0 {
+			email, err := userService.GetEmailFromToken(token)
+			if err == nil && exempt[strings.TrimPrefix(email, apiToken.API_TOKEN_USER_EMAIL_PREFIX)] {
+				return "", true
+			}
+		}
+		return strconv.Itoa(int(userId)), false
+	}
+}
+
+// LimitRoutes returns a middleware limiting the routes whose path template is present in routeGroups, other routes
+// pass through untouched
+func (impl *RateLimiter) LimitRoutes(routeGroups map[string]string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !impl.config.Enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			pathTemplate, _ := route.GetPathTemplate()
+			group, ok := routeGroups[pathTemplate]
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if allowed, retryAfter := impl.allow(r, group); !allowed {
+				impl.logger.Debugw("request rate limited", "path", pathTemplate, "group", group)
+				writeTooManyRequests(w, retryAfter)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (impl *RateLimiter) allow(r *http.Request, group string) (bool, time.Duration) {
+	limit, ok := impl.limits[group]
+	if !ok || limit.rps <= 0 {
+		return true, 0
+	}
+	key, skip := impl.keyFunc(r)
+	if skip {
+		return true, 0
+	}
+	now := impl.now()
+	reservation := impl.getLimiter(group+"/"+key, limit).ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Duration(float64(time.Second) / limit.rps)
+	}
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+func (impl *RateLimiter) getLimiter(key string, limit rateLimit) *rate.Limiter {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	if element, ok := impl.buckets[key]; ok {
+		impl.lru.MoveToFront(element)
+		return element.Value.(*bucketEntry).limiter
+	}
+	entry := &bucketEntry{key: key, limiter: rate.NewLimiter(rate.Limit(limit.rps), limit.burst)}
+	impl.buckets[key] = impl.lru.PushFront(entry)
+	for impl.config.MaxBuckets > 0 && impl.lru.Len() > impl.config.MaxBuckets {
+		oldest := impl.lru.Back()
+		impl.lru.Remove(oldest)
+		delete(impl.buckets, oldest.Value.(*bucketEntry).key)
+	}
+	return entry.limiter
+}
+
+func writeTooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
+	if retryAfterSeconds < 1 {
+		retryAfterSeconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+	internalMessage := fmt.Sprintf("rate limit exceeded, retry after %d seconds", retryAfterSeconds)
+	common.WriteJsonErrorResp(w, common.NewApiError(common.TooManyRequests, internalMessage), http.StatusTooManyRequests)
+}
@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func headerKeyFunc(r *http.Request) (string, bool) {
+	if r.Header.Get("api-token") != "" {
+		return "", true
+	}
+	return r.Header.Get("user"), false
+}
+
+func newTestRouter(t *testing.T, config *RateLimitConfig) (*mux.Router, *RateLimiter, *fakeClock) {
+	logger, err := util.NewSugardLogger()
+	assert.Nil(t, err)
+	limiter := newRateLimiter(logger, config, headerKeyFunc)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	limiter.now = clock.Now
+	router := mux.NewRouter()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router.Path("/orchestrator/user/terminal/start").HandlerFunc(ok)
+	router.Path("/orchestrator/k8s/api-resources/{clusterId}").HandlerFunc(ok)
+	router.Path("/orchestrator/app/labels/list").HandlerFunc(ok)
+	router.Use(limiter.LimitRoutes(map[string]string{
+		"/orchestrator/user/terminal/start":           RouteGroupTerminal,
+		"/orchestrator/k8s/api-resources/{clusterId}": RouteGroupClusterResource,
+	}))
+	return router, limiter, clock
+}
+
+func serve(router *mux.Router, path string, user string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	r.Header.Set("user", user)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func testRateLimitConfig() *RateLimitConfig {
+	return &RateLimitConfig{
+		Enabled:              true,
+		MaxBuckets:           100,
+		ClusterResourceRps:   2,
+		ClusterResourceBurst: 4,
+		TerminalRps:          1,
+		TerminalBurst:        2,
+	}
+}
+
+func TestRateLimiter_ConcurrentLoad(t *testing.T) {
+	router, _, _ := newTestRouter(t, testRateLimitConfig())
+	var allowed, limited int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := serve(router, "/orchestrator/k8s/api-resources/1", "1")
+			switch w.Code {
+			case http.StatusOK:
+				atomic.AddInt32(&allowed, 1)
+			case http.StatusTooManyRequests:
+				atomic.AddInt32(&limited, 1)
+				assert.NotEmpty(t, w.Header().Get("Retry-After"))
+			}
+		}()
+	}
+	wg.Wait()
+	// the clock is frozen so only the burst goes through
+	assert.Equal(t, int32(4), allowed)
+	assert.Equal(t, int32(46), limited)
+}
+
+func TestRateLimiter_ResetsAfterWindow(t *testing.T) {
+	router, _, clock := newTestRouter(t, testRateLimitConfig())
+	assert.Equal(t, http.StatusOK, serve(router, "/orchestrator/user/terminal/start", "1").Code)
+	assert.Equal(t, http.StatusOK, serve(router, "/orchestrator/user/terminal/start", "1").Code)
+	w := serve(router, "/orchestrator/user/terminal/start", "1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	clock.Advance(time.Second)
+	assert.Equal(t, http.StatusOK, serve(router, "/orchestrator/user/terminal/start", "1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(router, "/orchestrator/user/terminal/start", "1").Code)
+
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, http.StatusOK, serve(router, "/orchestrator/user/terminal/start", "1").Code)
+	assert.Equal(t, http.StatusOK, serve(router, "/orchestrator/user/terminal/start", "1").Code)
+}
+
+func TestRateLimiter_KeysAndGroupsAreIndependent(t *testing.T) {
+	router, _, _ := newTestRouter(t, testRateLimitConfig())
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusOK, serve(router, "/orchestrator/user/terminal/start", "1").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, serve(router, "/orchestrator/user/terminal/start", "1").Code)
+	assert.Equal(t, http.StatusOK, serve(router, "/orchestrator/user/terminal/start", "2").Code)
+	assert.Equal(t, http.StatusOK, serve(router, "/orchestrator/k8s/api-resources/1", "1").Code)
+	// routes outside the route groups are never limited
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusOK, serve(router, "/orchestrator/app/labels/list", "1").Code)
+	}
+}
+
+func TestRateLimiter_SkipsInternalTokens(t *testing.T) {
+	router, _, _ := newTestRouter(t, testRateLimitConfig())
+	for i := 0; i < 10; i++ {
+		r := httptest.NewRequest(http.MethodGet, "/orchestrator/user/terminal/start", nil)
+		r.Header.Set("api-token", "internal")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	config := testRateLimitConfig()
+	config.Enabled = false
+	router, _, _ := newTestRouter(t, config)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusOK, serve(router, "/orchestrator/user/terminal/start", "1").Code)
+	}
+}
+
+func TestRateLimiter_BucketsAreBounded(t *testing.T) {
+	config := testRateLimitConfig()
+	config.MaxBuckets = 3
+	router, limiter, _ := newTestRouter(t, config)
+	for _, user := range []string{"1", "2", "3", "4", "5"} {
+		serve(router, "/orchestrator/user/terminal/start", user)
+	}
+	assert.Equal(t, 3, limiter.lru.Len())
+	assert.Equal(t, 3, len(limiter.buckets))
+	_, ok := limiter.buckets[RouteGroupTerminal+"/1"]
+	assert.False(t, ok, "least recently used bucket should be evicted")
+	_, ok = limiter.buckets[RouteGroupTerminal+"/5"]
+	assert.True(t, ok)
+}
+
+type tokenUserService struct {
+	user.UserService
+	// users maps a token to the user id and email it belongs to
+	users map[string]struct {
+		id    int32
+		email string
+	}
+}
+
+func (s *tokenUserService) GetUserByToken(token string) (int32, string, error) {
+	u, ok := s.users[token]
+	if !ok {
+		return http.StatusUnauthorized, "", errors.New("invalid token")
+	}
+	if len(u.email) > 0 {
+		return u.id, bean.USER_TYPE_API_TOKEN, nil
+	}
+	return u.id, "", nil
+}
+
+func (s *tokenUserService) GetEmailFromToken(token string) (string, error) {
+	return s.users[token].email, nil
+}
+
+func TestUserRateLimitKeyFunc(t *testing.T) {
+	userService := &tokenUserService{users: map[string]struct {
+		id    int32
+		email string
+	}{
+		"user":     {id: 2},
+		"ci-bot":   {id: 5, email: "API-TOKEN:ci-bot"},
+		"internal": {id: 6, email: "API-TOKEN:kubelink"},
+	}}
+	keyFunc := userRateLimitKeyFunc(userService, []string{"kubelink", " "})
+	tests := []struct {
+		name       string
+		token      string
+		remoteAddr string
+		wantKey    string
+		wantSkip   bool
+	}{
+		{name: "user", token: "user", wantKey: "2"},
+		{name: "api token not in allow list", token: "ci-bot", wantKey: "5"},
+		{name: "allow listed api token", token: "internal", wantSkip: true},
+		{name: "invalid token", token: "expired", remoteAddr: "10.0.0.7:51234", wantKey: "10.0.0.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/orchestrator/user/terminal/start", nil)
+			r.Header.Set("token", tt.token)
+			if len(tt.remoteAddr) > 0 {
+				r.RemoteAddr = tt.remoteAddr
+			}
+			key, skip := keyFunc(r)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantSkip, skip)
+		})
+	}
+}
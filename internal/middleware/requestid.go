@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/google/uuid"
+	"net/http"
+	"regexp"
+)
+
+// caller supplied ids are only trusted when they are safe to put in logs and annotations
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,63}$`)
+
+// RequestIdMiddleware takes the X-Request-ID of the caller or generates one, puts it in the request context for
+// util.LoggerFromContext and returns it in the response headers
+func RequestIdMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(util.RequestIdHeader)
+		if !validRequestId.MatchString(requestId) {
+			requestId = uuid.NewString()
+		}
+		w.Header().Set(util.RequestIdHeader, requestId)
+		next.ServeHTTP(w, r.WithContext(util.ContextWithRequestId(r.Context(), requestId)))
+	})
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIdMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestId     string
+		wantPropagate bool
+	}{
+		{name: "caller id is propagated", requestId: "abc-123.retry:1", wantPropagate: true},
+		{name: "missing id is generated", requestId: ""},
+		{name: "id with unsafe characters is replaced", requestId: "abc\ninjected=true"},
+		{name: "oversized id is replaced", requestId: strings.Repeat("a", 64)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestIdInContext string
+			handler := RequestIdMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestIdInContext = util.RequestIdFromContext(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/orchestrator/user/terminal/start", nil)
+			if tt.requestId != "" {
+				req.Header.Set(util.RequestIdHeader, tt.requestId)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			responseRequestId := rec.Header().Get(util.RequestIdHeader)
+			assert.NotEmpty(t, responseRequestId)
+			assert.Equal(t, responseRequestId, requestIdInContext)
+			if tt.wantPropagate {
+				assert.Equal(t, tt.requestId, responseRequestId)
+			} else {
+				assert.NotEqual(t, tt.requestId, responseRequestId)
+			}
+		})
+	}
+}
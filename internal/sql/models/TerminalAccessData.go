@@ -1,6 +1,9 @@
 package models
 
-import "github.com/devtron-labs/devtron/pkg/sql"
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"time"
+)
 
 type TerminalAccessTemplates struct {
 	tableName    struct{} `sql:"terminal_access_templates" pg:",discard_unknown_columns"`
@@ -19,5 +22,22 @@ type UserTerminalAccessData struct {
 	PodName   string   `sql:"pod_name"`
 	Status    string   `sql:"status"`
 	Metadata  string   `sql:"metadata"`
+	// RunningStartedOn and RunningEndedOn are the transition timestamps of the pod used for usage accounting,
+	// they are persisted so that the running duration is not lost on orchestrator restarts
+	RunningStartedOn     *time.Time `sql:"running_started_on"`
+	RunningEndedOn       *time.Time `sql:"running_ended_on"`
+	RequestedCpuMillis   int64      `sql:"requested_cpu_millis,notnull"`
+	RequestedMemoryBytes int64      `sql:"requested_memory_bytes,notnull"`
+	sql.AuditLog
+}
+
+type UserTerminalSessionTranscript struct {
+	tableName        struct{} `sql:"user_terminal_session_transcript" pg:",discard_unknown_columns"`
+	Id               int      `sql:"id,pk"`
+	TerminalAccessId int      `sql:"terminal_access_id"`
+	SessionId        string   `sql:"session_id"`
+	UserId           int32    `sql:"user_id"`
+	Transcript       string   `sql:"transcript"`
+	Truncated        bool     `sql:"truncated,notnull"`
 	sql.AuditLog
 }
@@ -1,24 +1,59 @@
 package models
 
+import "time"
+
 type UserTerminalSessionRequest struct {
 	Id        int    `json:"id"`
 	UserId    int32  `json:"userId"`
 	ClusterId int    `json:"clusterId" validate:"number,gt=0"`
 	NodeName  string `json:"nodeName" validate:"required,min=1"`
-	BaseImage string `json:"baseImage" validate:"required,min=1"`
-	ShellName string `json:"shellName" validate:"required,min=1"`
-	Namespace string `json:"namespace" validate:"required,min=1"`
+	// BaseImage and ShellName are resolved from the cluster and then the global defaults when empty
+	BaseImage        string `json:"baseImage"`
+	ShellName        string `json:"shellName" validate:"omitempty,oneof=bash sh powershell cmd"`
+	Namespace        string `json:"namespace" validate:"required,min=1"`
+	RecordTranscript bool   `json:"recordTranscript"`
+	// FavoriteId starts the terminal preset saved by the user, fields set on the request take precedence over it
+	FavoriteId int `json:"favoriteId,omitempty"`
 }
 type UserTerminalShellSessionRequest struct {
 	TerminalAccessId int    `json:"terminalAccessId" validate:"number,gt=0"`
-	ShellName        string `json:"shellName" validate:"required,min=1"`
+	ShellName        string `json:"shellName" validate:"required,oneof=bash sh powershell cmd"`
 }
 
+// UserTerminalSessionConfig is read once at startup, MaxSessionPerUser, TerminalPodInActiveDurationInMins and
+// TerminalBaseImageAllowList can be changed later through the tunables ConfigMap, see util.TunableConfig
 type UserTerminalSessionConfig struct {
 	MaxSessionPerUser                 int    `env:"MAX_SESSION_PER_USER" envDefault:"5"`
 	TerminalPodStatusSyncTimeInSecs   int    `env:"TERMINAL_POD_STATUS_SYNC_In_SECS" envDefault:"600"`
 	TerminalPodDefaultNamespace       string `env:"TERMINAL_POD_DEFAULT_NAMESPACE" envDefault:"default"`
 	TerminalPodInActiveDurationInMins int    `env:"TERMINAL_POD_INACTIVE_DURATION_IN_MINS" envDefault:"10"`
+	TerminalPodNameTemplate           string `env:"TERMINAL_POD_NAME_TEMPLATE" envDefault:"terminal-access-${cluster_id}-${user_id}-${random_id}"`
+	TerminalImagePrePullAllowList     string `env:"TERMINAL_IMAGE_PRE_PULL_ALLOW_LIST" envDefault:""`
+	TerminalImagePrePullNamespace     string `env:"TERMINAL_IMAGE_PRE_PULL_NAMESPACE" envDefault:"default"`
+	TerminalImagePrePullPauseImage    string `env:"TERMINAL_IMAGE_PRE_PULL_PAUSE_IMAGE" envDefault:"registry.k8s.io/pause:3.6"`
+	TerminalTranscriptEnabled         bool   `env:"TERMINAL_SESSION_TRANSCRIPT_ENABLED" envDefault:"false"`
+	TerminalTranscriptMaxSizeInKB     int    `env:"TERMINAL_SESSION_TRANSCRIPT_MAX_SIZE_IN_KB" envDefault:"512"`
+	// monthly budgets are per user across clusters, new sessions are blocked once either is used up, 0 disables the budget
+	TerminalUserMonthlyCpuCoreHours   float64 `env:"TERMINAL_USER_MONTHLY_BUDGET_CPU_CORE_HOURS" envDefault:"0"`
+	TerminalUserMonthlyMemoryGibHours float64 `env:"TERMINAL_USER_MONTHLY_BUDGET_MEMORY_GIB_HOURS" envDefault:"0"`
+	TerminalDefaultBaseImage          string  `env:"TERMINAL_DEFAULT_BASE_IMAGE" envDefault:"quay.io/devtron/ubuntu-k8s-utils:latest"`
+	TerminalDefaultShellName          string  `env:"TERMINAL_DEFAULT_SHELL_NAME" envDefault:"sh"`
+	// TerminalBaseImageAllowList restricts the base images of sessions after defaults are resolved, empty allows any image
+	TerminalBaseImageAllowList string `env:"TERMINAL_BASE_IMAGE_ALLOW_LIST" envDefault:""`
+	// TerminalPreflightCacheTTLInSecs is how long the permission preflight of a user, cluster and namespace is reused
+	TerminalPreflightCacheTTLInSecs int `env:"TERMINAL_PREFLIGHT_CACHE_TTL_IN_SECS" envDefault:"30"`
+	// TerminalImagePrePullNoopImage provides the statically linked /bin/true the pre-pulled images run, so that images
+	// without a shell can be pre-pulled too
+	TerminalImagePrePullNoopImage string `env:"TERMINAL_IMAGE_PRE_PULL_NOOP_IMAGE" envDefault:"busybox:1.36-musl"`
+	// the startup status of a session is pushed over its status session every interval until the pod runs or fails,
+	// pushing stops after the timeout
+	TerminalStatusPushIntervalInSecs int `env:"TERMINAL_STATUS_PUSH_INTERVAL_IN_SECS" envDefault:"2"`
+	TerminalStatusPushTimeoutInSecs  int `env:"TERMINAL_STATUS_PUSH_TIMEOUT_IN_SECS" envDefault:"600"`
+}
+
+type UserTerminalImagePrePullRequest struct {
+	ClusterId int      `json:"clusterId" validate:"number,gt=0"`
+	Images    []string `json:"images" validate:"required,min=1"`
 }
 
 type UserTerminalSessionResponse struct {
@@ -27,8 +62,56 @@ type UserTerminalSessionResponse struct {
 	TerminalAccessId      int               `json:"terminalAccessId"`
 	Status                TerminalPodStatus `json:"status"`
 	PodName               string            `json:"podName"`
+	StatusReason          string            `json:"statusReason,omitempty"`
+	ErrorReason           string            `json:"errorReason,omitempty"`
+	// DefaultsApplied is set when base image or shell of the session were not part of the request
+	DefaultsApplied *TerminalSessionDefaultsApplied `json:"defaultsApplied,omitempty"`
+	// StatusSessionId is bound like UserTerminalSessionId and receives the startup status of the pod as status messages
+	StatusSessionId string `json:"statusSessionId,omitempty"`
+}
+
+// TerminalSessionDefaultsApplied states where the defaulted fields of a session came from, empty when set in the request
+type TerminalSessionDefaultsApplied struct {
+	BaseImage string `json:"baseImage,omitempty"`
+	ShellName string `json:"shellName,omitempty"`
+}
+
+// sources of the defaults of terminal sessions
+const (
+	TerminalDefaultSourceCluster = "cluster"
+	TerminalDefaultSourceGlobal  = "global"
+)
+
+type UserTerminalSessionSummary struct {
+	TerminalAccessId int       `json:"terminalAccessId"`
+	ClusterId        int       `json:"clusterId"`
+	NodeName         string    `json:"nodeName"`
+	PodName          string    `json:"podName"`
+	Status           string    `json:"status"`
+	CreatedOn        time.Time `json:"createdOn"`
+	UpdatedOn        time.Time `json:"updatedOn"`
+}
+
+// UserTerminalUsage is the usage of a user on a cluster, hours are the running duration of the terminal pods weighted
+// by their requested cpu and memory
+type UserTerminalUsage struct {
+	UserId         int32   `json:"userId"`
+	ClusterId      int     `json:"clusterId"`
+	SessionCount   int     `json:"sessionCount"`
+	RunningHours   float64 `json:"runningHours"`
+	CpuCoreHours   float64 `json:"cpuCoreHours"`
+	MemoryGibHours float64 `json:"memoryGibHours"`
+}
+
+type UserTerminalUsageReport struct {
+	Month                string               `json:"month"`
+	CpuCoreHoursBudget   float64              `json:"cpuCoreHoursBudget,omitempty"`
+	MemoryGibHoursBudget float64              `json:"memoryGibHoursBudget,omitempty"`
+	Usage                []*UserTerminalUsage `json:"usage"`
 }
 
+const TerminalUsageMonthLayout = "2006-01"
+
 const TerminalAccessPodNameTemplate = "terminal-access-" + TerminalAccessClusterIdTemplateVar + "-" + TerminalAccessUserIdTemplateVar + "-" + TerminalAccessRandomIdVar
 const TerminalAccessClusterIdTemplateVar = "${cluster_id}"
 const TerminalAccessUserIdTemplateVar = "${user_id}"
@@ -44,6 +127,14 @@ const TerminalAccessClusterRoleBindingTemplate = TerminalAccessPodNameTemplate +
 const TerminalAccessServiceAccountTemplateName = "terminal-access-service-account"
 const TerminalAccessServiceAccountTemplate = TerminalAccessPodNameTemplate + "-sa"
 const MaxSessionLimitReachedMsg = "session-limit-reached"
+const TranscriptRecordingDisabledMsg = "session-transcript-recording-disabled"
+const TerminalImagePrePullDaemonSetName = "devtron-terminal-image-pre-pull"
+const TerminalImagePrePullNoopVolume = "noop"
+const TerminalImagePrePullNoopBinary = "/noop/true"
+const TerminalShutdownCountdownMsg = "Server is restarting, this terminal will disconnect in %d seconds. Reconnect to resume the session."
+const TerminalPermissionDeniedMsg = "cluster permissions required by terminal sessions are missing"
+const TerminalShutdownCloseMsg = "Server restarted, reconnect to resume the session"
+const TerminalStatusSessionCloseMsg = "terminal startup status is no longer pushed"
 
 type TerminalPodStatus string
 
@@ -52,4 +143,25 @@ const (
 	TerminalPodRunning    TerminalPodStatus = "Running"
 	TerminalPodTerminated TerminalPodStatus = "Terminated"
 	TerminalPodError      TerminalPodStatus = "Error"
+	// TerminalPodDisconnected is set when the orchestrator shuts down with the socket open, the pod is left running so
+	// that the session can be resumed from another instance
+	TerminalPodDisconnected TerminalPodStatus = "Disconnected"
+	// TerminalPodDenied records a start refused by the permission preflight, no pod was created for it
+	TerminalPodDenied TerminalPodStatus = "Denied"
+)
+
+// pod container waiting reasons and event reasons used to report terminal pod startup progress
+const (
+	ContainerCreatingReason          = "ContainerCreating"
+	PodInitializingReason            = "PodInitializing"
+	ErrImagePullReason               = "ErrImagePull"
+	ImagePullBackOffReason           = "ImagePullBackOff"
+	InvalidImageNameReason           = "InvalidImageName"
+	ErrImageNeverPullReason          = "ErrImageNeverPull"
+	CrashLoopBackOffReason           = "CrashLoopBackOff"
+	CreateContainerConfigErrorReason = "CreateContainerConfigError"
+	PullingEventReason               = "Pulling"
+	PulledEventReason                = "Pulled"
+	ScheduledEventReason             = "Scheduled"
+	FailedSchedulingEventReason      = "FailedScheduling"
 )
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/pagination"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+	"time"
+)
+
+const (
+	ClusterOperationRunning   = "running"
+	ClusterOperationSucceeded = "succeeded"
+	ClusterOperationFailed    = "failed"
+)
+
+// ClusterOperation tracks a cluster operation running longer than the http request which started it
+type ClusterOperation struct {
+	tableName       struct{}   `sql:"cluster_operation" pg:",discard_unknown_columns"`
+	Id              int        `sql:"id,pk"`
+	OperationType   string     `sql:"operation_type"`
+	ClusterId       int        `sql:"cluster_id"`
+	Namespace       string     `sql:"namespace"`
+	ResourceName    string     `sql:"resource_name"`
+	State           string     `sql:"state"`
+	ProgressMessage string     `sql:"progress_message"`
+	StartedOn       time.Time  `sql:"started_on"`
+	FinishedOn      *time.Time `sql:"finished_on"`
+	UserId          int32      `sql:"user_id"`
+	// Checkpoint is the state a resumable operation continues from, its format is up to the operation type
+	Checkpoint string `sql:"checkpoint"`
+	// OwnerInstanceId is the orchestrator instance running the operation, it refreshes HeartbeatOn while it runs
+	OwnerInstanceId string     `sql:"owner_instance_id"`
+	HeartbeatOn     *time.Time `sql:"heartbeat_on"`
+	sql.AuditLog
+}
+
+// ClusterOperationFilter zero values match everything
+type ClusterOperationFilter struct {
+	OperationType string
+	ClusterId     int
+	State         string
+	UserId        int32
+}
+
+type ClusterOperationRepository interface {
+	Save(operation *ClusterOperation) error
+	UpdateProgress(operation *ClusterOperation) error
+	UpdateCheckpoint(operation *ClusterOperation) error
+	FindById(id int) (*ClusterOperation, error)
+	FindAll(filter *ClusterOperationFilter, request *pagination.ListingRequest) ([]*ClusterOperation, int, error)
+	// ExistsForResource tells whether any operation, finished or not, was recorded for the resource
+	ExistsForResource(clusterId int, namespace string, resourceName string) (bool, error)
+	// UpdateHeartbeat refreshes the heartbeat of the running operations of the instance
+	UpdateHeartbeat(ids []int, ownerInstanceId string, now time.Time) (int, error)
+	// MarkStaleRunningAsFailed finishes the running operations whose heartbeat is older than staleBefore, their
+	// instance is gone. Operations started before heartbeats were recorded have none and count as stale
+	MarkStaleRunningAsFailed(message string, staleBefore time.Time, now time.Time) (int, error)
+	FindRunningByClusterId(clusterId int) ([]*ClusterOperation, error)
+	// MarkRunningAsFailedForCluster finishes the running operations of a cluster, their goroutines are cancelled by the caller
+	MarkRunningAsFailedForCluster(clusterId int, message string, now time.Time, tx *pg.Tx) (int, error)
+}
+
+type ClusterOperationRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewClusterOperationRepositoryImpl(dbConnection *pg.DB) *ClusterOperationRepositoryImpl {
+	return &ClusterOperationRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl ClusterOperationRepositoryImpl) Save(operation *ClusterOperation) error {
+	return impl.dbConnection.Insert(operation)
+}
+
+func (impl ClusterOperationRepositoryImpl) UpdateProgress(operation *ClusterOperation) error {
+	_, err := impl.dbConnection.Model(operation).
+		Column("state", "progress_message", "finished_on", "updated_on", "updated_by").
+		WherePK().
+		Update()
+	return err
+}
+
+func (impl ClusterOperationRepositoryImpl) UpdateCheckpoint(operation *ClusterOperation) error {
+	_, err := impl.dbConnection.Model(operation).
+		Column("checkpoint", "updated_on", "updated_by").
+		WherePK().
+		Update()
+	return err
+}
+
+func (impl ClusterOperationRepositoryImpl) FindById(id int) (*ClusterOperation, error) {
+	operation := &ClusterOperation{}
+	err := impl.dbConnection.Model(operation).Where("id = ?", id).Select()
+	return operation, err
+}
+
+func (impl ClusterOperationRepositoryImpl) FindAll(filter *ClusterOperationFilter, request *pagination.ListingRequest) ([]*ClusterOperation, int, error) {
+	var operations []*ClusterOperation
+	query := impl.dbConnection.Model(&operations)
+	if len(filter.OperationType) > 0 {
+		query = query.Where("operation_type = ?", filter.OperationType)
+	}
+	if filter.ClusterId > 0 {
+		query = query.Where("cluster_id = ?", filter.ClusterId)
+	}
+	if len(filter.State) > 0 {
+		query = query.Where("state = ?", filter.State)
+	}
+	if filter.UserId > 0 {
+		query = query.Where("user_id = ?", filter.UserId)
+	}
+	if request.SearchKey != "" {
+		query = query.Where("resource_name ILIKE ?", request.SearchPattern())
+	}
+	totalCount, err := request.Apply(query).SelectAndCount()
+	if err == pg.ErrNoRows {
+		err = nil
+	}
+	return operations, totalCount, err
+}
+
+func (impl ClusterOperationRepositoryImpl) ExistsForResource(clusterId int, namespace string, resourceName string) (bool, error) {
+	return impl.dbConnection.Model((*ClusterOperation)(nil)).
+		Where("cluster_id = ?", clusterId).
+		Where("namespace = ?", namespace).
+		Where("resource_name = ?", resourceName).
+		Exists()
+}
+
+func (impl ClusterOperationRepositoryImpl) UpdateHeartbeat(ids []int, ownerInstanceId string, now time.Time) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result, err := impl.dbConnection.Model((*ClusterOperation)(nil)).
+		Set("heartbeat_on = ?", now).
+		Where("id in (?)", pg.In(ids)).
+		Where("owner_instance_id = ?", ownerInstanceId).
+		Where("state = ?", ClusterOperationRunning).
+		Update()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (impl ClusterOperationRepositoryImpl) MarkStaleRunningAsFailed(message string, staleBefore time.Time, now time.Time) (int, error) {
+	result, err := impl.dbConnection.Model((*ClusterOperation)(nil)).
+		Set("state = ?", ClusterOperationFailed).
+		Set("progress_message = ?", message).
+		Set("finished_on = ?", now).
+		Set("updated_on = ?", now).
+		Where("state = ?", ClusterOperationRunning).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.WhereOr("heartbeat_on IS NULL").WhereOr("heartbeat_on < ?", staleBefore), nil
+		}).
+		Update()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (impl ClusterOperationRepositoryImpl) FindRunningByClusterId(clusterId int) ([]*ClusterOperation, error) {
+	var operations []*ClusterOperation
+	err := impl.dbConnection.Model(&operations).
+		Where("cluster_id = ?", clusterId).
+		Where("state = ?", ClusterOperationRunning).
+		Select()
+	if err == pg.ErrNoRows {
+		err = nil
+	}
+	return operations, err
+}
+
+func (impl ClusterOperationRepositoryImpl) MarkRunningAsFailedForCluster(clusterId int, message string, now time.Time, tx *pg.Tx) (int, error) {
+	result, err := sql.Connection(impl.dbConnection, tx).Model((*ClusterOperation)(nil)).
+		Set("state = ?", ClusterOperationFailed).
+		Set("progress_message = ?", message).
+		Set("finished_on = ?", now).
+		Set("updated_on = ?", now).
+		Where("cluster_id = ?", clusterId).
+		Where("state = ?", ClusterOperationRunning).
+		Update()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"time"
+)
+
+type IdempotencyKey struct {
+	tableName      struct{}  `sql:"idempotency_key" pg:",discard_unknown_columns"`
+	Id             int       `sql:"id,pk"`
+	IdempotencyKey string    `sql:"idempotency_key"`
+	UserId         int32     `sql:"user_id"`
+	Route          string    `sql:"route"`
+	RequestHash    string    `sql:"request_hash"`
+	Completed      bool      `sql:"completed,notnull"`
+	ResponseStatus int       `sql:"response_status"`
+	ResponseType   string    `sql:"response_type"`
+	ResponseBody   string    `sql:"response_body"`
+	ExpiresOn      time.Time `sql:"expires_on"`
+	sql.AuditLog
+}
+
+type IdempotencyKeyRepository interface {
+	// Create inserts the key, created is false if the same key was already taken by the user for the route
+	Create(entry *IdempotencyKey) (created bool, err error)
+	FindByKey(key string, userId int32, route string) (*IdempotencyKey, error)
+	UpdateResponse(entry *IdempotencyKey) error
+	Delete(entry *IdempotencyKey) error
+	DeleteExpired(now time.Time) (int, error)
+}
+
+type IdempotencyKeyRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewIdempotencyKeyRepositoryImpl(dbConnection *pg.DB) *IdempotencyKeyRepositoryImpl {
+	return &IdempotencyKeyRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl IdempotencyKeyRepositoryImpl) Create(entry *IdempotencyKey) (bool, error) {
+	result, err := impl.dbConnection.Model(entry).
+		OnConflict("(idempotency_key, user_id, route) DO NOTHING").
+		Insert()
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (impl IdempotencyKeyRepositoryImpl) FindByKey(key string, userId int32, route string) (*IdempotencyKey, error) {
+	entry := &IdempotencyKey{}
+	err := impl.dbConnection.Model(entry).
+		Where("idempotency_key = ?", key).
+		Where("user_id = ?", userId).
+		Where("route = ?", route).
+		Select()
+	return entry, err
+}
+
+func (impl IdempotencyKeyRepositoryImpl) UpdateResponse(entry *IdempotencyKey) error {
+	_, err := impl.dbConnection.Model(entry).
+		Column("completed", "response_status", "response_type", "response_body", "updated_on", "updated_by").
+		WherePK().
+		Update()
+	return err
+}
+
+func (impl IdempotencyKeyRepositoryImpl) Delete(entry *IdempotencyKey) error {
+	_, err := impl.dbConnection.Model(entry).WherePK().Delete()
+	return err
+}
+
+func (impl IdempotencyKeyRepositoryImpl) DeleteExpired(now time.Time) (int, error) {
+	result, err := impl.dbConnection.Model((*IdempotencyKey)(nil)).
+		Where("expires_on < ?", now).
+		Delete()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// JobTemplate is the yaml of a kubernetes Job with ${param} placeholders, only active templates are unique by name
+type JobTemplate struct {
+	tableName   struct{} `sql:"job_template" pg:",discard_unknown_columns"`
+	Id          int      `sql:"id,pk"`
+	Name        string   `sql:"name,notnull"`
+	Description string   `sql:"description"`
+	Template    string   `sql:"template,notnull"`
+	Active      bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type JobTemplateRepository interface {
+	Save(model *JobTemplate) error
+	Update(model *JobTemplate) error
+	FindActiveByName(name string) (*JobTemplate, error)
+	FindAllActive() ([]*JobTemplate, error)
+}
+
+type JobTemplateRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewJobTemplateRepositoryImpl(dbConnection *pg.DB) *JobTemplateRepositoryImpl {
+	return &JobTemplateRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl JobTemplateRepositoryImpl) Save(model *JobTemplate) error {
+	return impl.dbConnection.Insert(model)
+}
+
+func (impl JobTemplateRepositoryImpl) Update(model *JobTemplate) error {
+	return impl.dbConnection.Update(model)
+}
+
+func (impl JobTemplateRepositoryImpl) FindActiveByName(name string) (*JobTemplate, error) {
+	model := &JobTemplate{}
+	err := impl.dbConnection.Model(model).Where("name = ?", name).Where("active = ?", true).Select()
+	return model, err
+}
+
+func (impl JobTemplateRepositoryImpl) FindAllActive() ([]*JobTemplate, error) {
+	var models []*JobTemplate
+	err := impl.dbConnection.Model(&models).Where("active = ?", true).Order("name").Select()
+	return models, err
+}
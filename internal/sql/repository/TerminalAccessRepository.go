@@ -2,6 +2,8 @@ package repository
 
 import (
 	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/pagination"
 	"github.com/go-pg/pg"
 	"github.com/go-pg/pg/orm"
 	"go.uber.org/zap"
@@ -13,9 +15,21 @@ type TerminalAccessRepository interface {
 	FetchAllTemplates() ([]*models.TerminalAccessTemplates, error)
 	GetUserTerminalAccessData(id int) (*models.UserTerminalAccessData, error)
 	GetAllRunningUserTerminalData() ([]*models.UserTerminalAccessData, error)
-	SaveUserTerminalAccessData(data *models.UserTerminalAccessData) error
-	UpdateUserTerminalAccessData(data *models.UserTerminalAccessData) error
+	FindUserTerminalAccessData(userId int32, request *pagination.ListingRequest) ([]*models.UserTerminalAccessData, int, error)
+	// SaveUserTerminalAccessData, UpdateUserTerminalAccessData and SaveSessionTranscript write on tx when it is set
+	SaveUserTerminalAccessData(data *models.UserTerminalAccessData, tx *pg.Tx) error
+	UpdateUserTerminalAccessData(data *models.UserTerminalAccessData, tx *pg.Tx) error
 	UpdateUserTerminalStatus(id int, status string) error
+	SaveSessionTranscript(transcript *models.UserTerminalSessionTranscript, tx *pg.Tx) error
+	GetSessionTranscript(sessionId string) (*models.UserTerminalSessionTranscript, error)
+	// MarkClusterSessionsTerminated terminates every running, starting or disconnected session of the cluster
+	MarkClusterSessionsTerminated(clusterId int, tx *pg.Tx) (int, error)
+	// MarkUserTerminalRunning keeps the first running timestamp of the session, the requests are overwritten
+	MarkUserTerminalRunning(id int, startedOn time.Time, cpuMillis int64, memoryBytes int64) error
+	// MarkUserTerminalRunningEnded sets the end of the running duration once, sessions which never ran are skipped
+	MarkUserTerminalRunningEnded(id int, endedOn time.Time) error
+	// FindUserTerminalAccessDataRunningBetween returns the sessions running at any point in [from, to), userId 0 returns all users
+	FindUserTerminalAccessDataRunningBetween(userId int32, from time.Time, to time.Time) ([]*models.UserTerminalAccessData, error)
 }
 
 type TerminalAccessRepositoryImpl struct {
@@ -74,18 +88,18 @@ func (impl TerminalAccessRepositoryImpl) GetUserTerminalAccessData(id int) (*mod
 	return terminalAccessData, err
 }
 
-func (impl TerminalAccessRepositoryImpl) SaveUserTerminalAccessData(data *models.UserTerminalAccessData) error {
+func (impl TerminalAccessRepositoryImpl) SaveUserTerminalAccessData(data *models.UserTerminalAccessData, tx *pg.Tx) error {
 	data.CreatedBy = data.UserId
 	data.UpdatedBy = data.UserId
 	data.CreatedOn = time.Now()
 	data.UpdatedOn = time.Now()
-	return impl.dbConnection.Insert(data)
+	return sql.Connection(impl.dbConnection, tx).Insert(data)
 }
 
-func (impl TerminalAccessRepositoryImpl) UpdateUserTerminalAccessData(data *models.UserTerminalAccessData) error {
+func (impl TerminalAccessRepositoryImpl) UpdateUserTerminalAccessData(data *models.UserTerminalAccessData, tx *pg.Tx) error {
 	data.UpdatedBy = data.UserId
 	data.UpdatedOn = time.Now()
-	return impl.dbConnection.Update(data)
+	return sql.Connection(impl.dbConnection, tx).Update(data)
 }
 
 func (impl TerminalAccessRepositoryImpl) UpdateUserTerminalStatus(id int, status string) error {
@@ -102,7 +116,8 @@ func (impl TerminalAccessRepositoryImpl) GetAllRunningUserTerminalData() ([]*mod
 	var accessDataArray []*models.UserTerminalAccessData
 	err := impl.dbConnection.Model(&accessDataArray).
 		WhereGroup(func(query *orm.Query) (*orm.Query, error) {
-			query = query.WhereOr("status = ?", string(models.TerminalPodRunning)).WhereOr("status = ?", string(models.TerminalPodStarting))
+			query = query.WhereOr("status = ?", string(models.TerminalPodRunning)).WhereOr("status = ?", string(models.TerminalPodStarting)).
+				WhereOr("status = ?", string(models.TerminalPodDisconnected))
 			return query, nil
 		}).
 		Select()
@@ -113,3 +128,90 @@ func (impl TerminalAccessRepositoryImpl) GetAllRunningUserTerminalData() ([]*mod
 	}
 	return accessDataArray, err
 }
+
+func (impl TerminalAccessRepositoryImpl) MarkClusterSessionsTerminated(clusterId int, tx *pg.Tx) (int, error) {
+	result, err := sql.Connection(impl.dbConnection, tx).Model((*models.UserTerminalAccessData)(nil)).
+		Set("status = ?", string(models.TerminalPodTerminated)).
+		Set("running_ended_on = coalesce(running_ended_on, ?)", time.Now()).
+		Set("updated_on = ?", time.Now()).
+		Where("cluster_id = ?", clusterId).
+		Where("status in (?)", pg.In([]string{string(models.TerminalPodRunning), string(models.TerminalPodStarting), string(models.TerminalPodDisconnected)})).
+		Update()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (impl TerminalAccessRepositoryImpl) MarkUserTerminalRunning(id int, startedOn time.Time, cpuMillis int64, memoryBytes int64) error {
+	_, err := impl.dbConnection.Model((*models.UserTerminalAccessData)(nil)).
+		Set("running_started_on = coalesce(running_started_on, ?)", startedOn).
+		Set("requested_cpu_millis = ?", cpuMillis).
+		Set("requested_memory_bytes = ?", memoryBytes).
+		Set("updated_on = ?", time.Now()).
+		Where("id = ?", id).
+		Update()
+	return err
+}
+
+func (impl TerminalAccessRepositoryImpl) MarkUserTerminalRunningEnded(id int, endedOn time.Time) error {
+	_, err := impl.dbConnection.Model((*models.UserTerminalAccessData)(nil)).
+		Set("running_ended_on = ?", endedOn).
+		Set("updated_on = ?", time.Now()).
+		Where("id = ?", id).
+		Where("running_started_on IS NOT NULL").
+		Where("running_ended_on IS NULL").
+		Update()
+	return err
+}
+
+func (impl TerminalAccessRepositoryImpl) FindUserTerminalAccessDataRunningBetween(userId int32, from time.Time, to time.Time) ([]*models.UserTerminalAccessData, error) {
+	var accessDataArray []*models.UserTerminalAccessData
+	query := impl.dbConnection.Model(&accessDataArray).
+		Where("running_started_on IS NOT NULL").
+		Where("running_started_on < ?", to).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.WhereOr("running_ended_on IS NULL").WhereOr("running_ended_on > ?", from), nil
+		})
+	if userId > 0 {
+		query = query.Where("user_id = ?", userId)
+	}
+	err := query.Select()
+	if err == pg.ErrNoRows {
+		err = nil
+	}
+	return accessDataArray, err
+}
+
+func (impl TerminalAccessRepositoryImpl) FindUserTerminalAccessData(userId int32, request *pagination.ListingRequest) ([]*models.UserTerminalAccessData, int, error) {
+	var accessDataArray []*models.UserTerminalAccessData
+	query := impl.dbConnection.Model(&accessDataArray).Where("user_id = ?", userId)
+	if request.SearchKey != "" {
+		pattern := request.SearchPattern()
+		query = query.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.WhereOr("node_name ILIKE ?", pattern).WhereOr("pod_name ILIKE ?", pattern).WhereOr("status ILIKE ?", pattern), nil
+		})
+	}
+	totalCount, err := request.Apply(query).SelectAndCount()
+	if err == pg.ErrNoRows {
+		err = nil
+	}
+	return accessDataArray, totalCount, err
+}
+
+func (impl TerminalAccessRepositoryImpl) SaveSessionTranscript(transcript *models.UserTerminalSessionTranscript, tx *pg.Tx) error {
+	transcript.CreatedBy = transcript.UserId
+	transcript.UpdatedBy = transcript.UserId
+	transcript.CreatedOn = time.Now()
+	transcript.UpdatedOn = time.Now()
+	return sql.Connection(impl.dbConnection, tx).Insert(transcript)
+}
+
+func (impl TerminalAccessRepositoryImpl) GetSessionTranscript(sessionId string) (*models.UserTerminalSessionTranscript, error) {
+	transcript := &models.UserTerminalSessionTranscript{}
+	err := impl.dbConnection.
+		Model(transcript).
+		Where("session_id = ?", sessionId).
+		Select()
+	return transcript, err
+}
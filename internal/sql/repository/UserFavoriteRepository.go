@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// UserFavorite is a saved terminal preset or resource bookmark of a user, Payload is the json of the favorite and
+// names are unique per user and type
+type UserFavorite struct {
+	tableName struct{} `sql:"user_favorite" pg:",discard_unknown_columns"`
+	Id        int      `sql:"id,pk"`
+	UserId    int32    `sql:"user_id,notnull"`
+	Type      string   `sql:"type,notnull"`
+	Name      string   `sql:"name,notnull"`
+	Payload   string   `sql:"payload,notnull"`
+	sql.AuditLog
+}
+
+type UserFavoriteRepository interface {
+	Save(model *UserFavorite) error
+	Update(model *UserFavorite) error
+	Delete(model *UserFavorite) error
+	// FindByIdAndUserId only finds the favorites of the user, the favorites of others are not found
+	FindByIdAndUserId(id int, userId int32) (*UserFavorite, error)
+	FindByUserIdAndTypeAndName(userId int32, favoriteType string, name string) (*UserFavorite, error)
+	// FindByUserId lists the favorites of the user, of all types when favoriteType is empty
+	FindByUserId(userId int32, favoriteType string) ([]*UserFavorite, error)
+	CountByUserId(userId int32) (int, error)
+}
+
+type UserFavoriteRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewUserFavoriteRepositoryImpl(dbConnection *pg.DB) *UserFavoriteRepositoryImpl {
+	return &UserFavoriteRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl UserFavoriteRepositoryImpl) Save(model *UserFavorite) error {
+	return impl.dbConnection.Insert(model)
+}
+
+func (impl UserFavoriteRepositoryImpl) Update(model *UserFavorite) error {
+	return impl.dbConnection.Update(model)
+}
+
+func (impl UserFavoriteRepositoryImpl) Delete(model *UserFavorite) error {
+	return impl.dbConnection.Delete(model)
+}
+
+func (impl UserFavoriteRepositoryImpl) FindByIdAndUserId(id int, userId int32) (*UserFavorite, error) {
+	model := &UserFavorite{}
+	err := impl.dbConnection.Model(model).Where("id = ?", id).Where("user_id = ?", userId).Select()
+	return model, err
+}
+
+func (impl UserFavoriteRepositoryImpl) FindByUserIdAndTypeAndName(userId int32, favoriteType string, name string) (*UserFavorite, error) {
+	model := &UserFavorite{}
+	err := impl.dbConnection.Model(model).Where("user_id = ?", userId).Where("type = ?", favoriteType).
+		Where("name = ?", name).Select()
+	return model, err
+}
+
+func (impl UserFavoriteRepositoryImpl) FindByUserId(userId int32, favoriteType string) ([]*UserFavorite, error) {
+	var models []*UserFavorite
+	query := impl.dbConnection.Model(&models).Where("user_id = ?", userId)
+	if len(favoriteType) > 0 {
+		query = query.Where("type = ?", favoriteType)
+	}
+	err := query.Order("type").Order("name").Select()
+	return models, err
+}
+
+func (impl UserFavoriteRepositoryImpl) CountByUserId(userId int32) (int, error) {
+	return impl.dbConnection.Model(&UserFavorite{}).Where("user_id = ?", userId).Count()
+}
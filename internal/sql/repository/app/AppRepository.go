@@ -19,6 +19,8 @@ package app
 
 import (
 	"fmt"
+	"time"
+
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/devtron-labs/devtron/pkg/team"
 	"github.com/go-pg/pg"
@@ -45,6 +47,8 @@ type AppRepository interface {
 	FindActiveByName(appName string) (pipelineGroup *App, err error)
 	FindActiveListByName(appName string) ([]*App, error)
 	FindById(id int) (pipelineGroup *App, err error)
+	// FindByIdForUpdate reads the app on tx and locks its row until tx ends
+	FindByIdForUpdate(id int, tx *pg.Tx) (*App, error)
 	FindAppsByTeamId(teamId int) ([]*App, error)
 	FindAppsByTeamIds(teamId []int, appType string) ([]App, error)
 	FindAppsByTeamName(teamName string) ([]App, error)
@@ -64,6 +68,20 @@ type AppRepository interface {
 	FetchAllActiveInstalledAppsWithAppIdAndName() ([]*App, error)
 	FetchAllActiveDevtronAppsWithAppIdAndName() ([]*App, error)
 	FindEnvironmentIdForInstalledApp(appId int) (int, error)
+	FindDeploymentSummaryByAppId(appId int) ([]*AppDeploymentSummary, error)
+}
+
+// AppDeploymentSummary is one cd pipeline of an app with the environment it deploys to and its latest deployment,
+// LastDeployedOn and LastDeploymentStatus are empty when the pipeline was never deployed
+type AppDeploymentSummary struct {
+	EnvironmentId         int        `sql:"environment_id"`
+	EnvironmentName       string     `sql:"environment_name"`
+	EnvironmentIdentifier string     `sql:"environment_identifier"`
+	Namespace             string     `sql:"namespace"`
+	ClusterId             int        `sql:"cluster_id"`
+	ClusterName           string     `sql:"cluster_name"`
+	LastDeployedOn        *time.Time `sql:"last_deployed_on"`
+	LastDeploymentStatus  string     `sql:"last_deployment_status"`
 }
 
 const DevtronApp = "DevtronApp"
@@ -145,6 +163,12 @@ func (repo AppRepositoryImpl) FindById(id int) (*App, error) {
 	return pipelineGroup, err
 }
 
+func (repo AppRepositoryImpl) FindByIdForUpdate(id int, tx *pg.Tx) (*App, error) {
+	app := &App{}
+	err := tx.Model(app).Where("id = ?", id).For("UPDATE").Select()
+	return app, err
+}
+
 func (repo AppRepositoryImpl) FindAppsByTeamId(teamId int) ([]*App, error) {
 	var apps []*App
 	err := repo.dbConnection.Model(&apps).Where("team_id = ?", teamId).
@@ -333,3 +357,23 @@ func (repo AppRepositoryImpl) FindEnvironmentIdForInstalledApp(appId int) (int,
 	_, err := repo.dbConnection.Query(&res, query, appId)
 	return res.envId, err
 }
+
+func (repo AppRepositoryImpl) FindDeploymentSummaryByAppId(appId int) ([]*AppDeploymentSummary, error) {
+	var summaries []*AppDeploymentSummary
+	query := "select env.id as environment_id, env.environment_name, env.environment_identifier, env.namespace," +
+		" c.id as cluster_id, c.cluster_name, wfr.started_on as last_deployed_on, wfr.status as last_deployment_status" +
+		" from pipeline p" +
+		" inner join environment env on env.id = p.environment_id and env.active = true" +
+		" inner join cluster c on c.id = env.cluster_id" +
+		" left join lateral (select cwr.started_on, cwr.status from cd_workflow_runner cwr" +
+		" inner join cd_workflow cw on cw.id = cwr.cd_workflow_id" +
+		" where cw.pipeline_id = p.id and cwr.workflow_type = 'DEPLOY' order by cwr.id desc limit 1) wfr on true" +
+		" where p.app_id = ? and p.deleted = false" +
+		" order by env.environment_name;"
+	_, err := repo.dbConnection.Query(&summaries, query, appId)
+	if err != nil {
+		repo.logger.Errorw("error in getting deployment summary of app", "appId", appId, "err", err)
+		return nil, err
+	}
+	return summaries, nil
+}
@@ -336,6 +336,29 @@ func (_m *AppRepository) FindById(id int) (*app.App, error) {
 	return r0, r1
 }
 
+// FindByIdForUpdate provides a mock function with given fields: id, tx
+func (_m *AppRepository) FindByIdForUpdate(id int, tx *pg.Tx) (*app.App, error) {
+	ret := _m.Called(id, tx)
+
+	var r0 *app.App
+	if rf, ok := ret.Get(0).(func(int, *pg.Tx) *app.App); ok {
+		r0 = rf(id, tx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*app.App)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, *pg.Tx) error); ok {
+		r1 = rf(id, tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByIds provides a mock function with given fields: ids
 func (_m *AppRepository) FindByIds(ids []*int) ([]*app.App, error) {
 	ret := _m.Called(ids)
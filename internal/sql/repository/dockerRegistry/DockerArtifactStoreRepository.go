@@ -35,20 +35,20 @@ const JSON_KEY_USERNAME string = "_json_key"
 type RegistryType string
 
 type DockerArtifactStore struct {
-	tableName          struct{}     `sql:"docker_artifact_store" json:",omitempty"  pg:",discard_unknown_columns"`
-	Id                 string       `sql:"id,pk" json:"id,,omitempty"`
-	PluginId           string       `sql:"plugin_id,notnull" json:"pluginId,omitempty"`
-	RegistryURL        string       `sql:"registry_url" json:"registryUrl,omitempty"`
-	RegistryType       RegistryType `sql:"registry_type,notnull" json:"registryType,omitempty"`
-	AWSAccessKeyId     string       `sql:"aws_accesskey_id" json:"awsAccessKeyId,omitempty" `
-	AWSSecretAccessKey string       `sql:"aws_secret_accesskey" json:"awsSecretAccessKey,omitempty"`
-	AWSRegion          string       `sql:"aws_region" json:"awsRegion,omitempty"`
-	Username           string       `sql:"username" json:"username,omitempty"`
-	Password           string       `sql:"password" json:"password,omitempty"`
-	IsDefault          bool         `sql:"is_default,notnull" json:"isDefault"`
-	Connection         string       `sql:"connection" json:"connection,omitempty"`
-	Cert               string       `sql:"cert" json:"cert,omitempty"`
-	Active             bool         `sql:"active,notnull" json:"active"`
+	tableName          struct{}            `sql:"docker_artifact_store" json:",omitempty"  pg:",discard_unknown_columns"`
+	Id                 string              `sql:"id,pk" json:"id,,omitempty"`
+	PluginId           string              `sql:"plugin_id,notnull" json:"pluginId,omitempty"`
+	RegistryURL        string              `sql:"registry_url" json:"registryUrl,omitempty"`
+	RegistryType       RegistryType        `sql:"registry_type,notnull" json:"registryType,omitempty"`
+	AWSAccessKeyId     string              `sql:"aws_accesskey_id" json:"awsAccessKeyId,omitempty" `
+	AWSSecretAccessKey sql.EncryptedString `sql:"aws_secret_accesskey" json:"awsSecretAccessKey,omitempty"`
+	AWSRegion          string              `sql:"aws_region" json:"awsRegion,omitempty"`
+	Username           string              `sql:"username" json:"username,omitempty"`
+	Password           sql.EncryptedString `sql:"password" json:"password,omitempty"`
+	IsDefault          bool                `sql:"is_default,notnull" json:"isDefault"`
+	Connection         string              `sql:"connection" json:"connection,omitempty"`
+	Cert               string              `sql:"cert" json:"cert,omitempty"`
+	Active             bool                `sql:"active,notnull" json:"active"`
 	IpsConfig          *DockerRegistryIpsConfig
 	sql.AuditLog
 }
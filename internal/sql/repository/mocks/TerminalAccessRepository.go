@@ -5,6 +5,12 @@ package mocks
 import (
 	models "github.com/devtron-labs/devtron/internal/sql/models"
 	mock "github.com/stretchr/testify/mock"
+
+	pagination "github.com/devtron-labs/devtron/util/pagination"
+
+	pg "github.com/go-pg/pg"
+
+	time "time"
 )
 
 // TerminalAccessRepository is an autogenerated mock type for the TerminalAccessRepository type
@@ -58,6 +64,59 @@ func (_m *TerminalAccessRepository) FetchTerminalAccessTemplate(templateName str
 	return r0, r1
 }
 
+// FindUserTerminalAccessData provides a mock function with given fields: userId, request
+func (_m *TerminalAccessRepository) FindUserTerminalAccessData(userId int32, request *pagination.ListingRequest) ([]*models.UserTerminalAccessData, int, error) {
+	ret := _m.Called(userId, request)
+
+	var r0 []*models.UserTerminalAccessData
+	if rf, ok := ret.Get(0).(func(int32, *pagination.ListingRequest) []*models.UserTerminalAccessData); ok {
+		r0 = rf(userId, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.UserTerminalAccessData)
+		}
+	}
+
+	var r1 int
+	if rf, ok := ret.Get(1).(func(int32, *pagination.ListingRequest) int); ok {
+		r1 = rf(userId, request)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(int32, *pagination.ListingRequest) error); ok {
+		r2 = rf(userId, request)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FindUserTerminalAccessDataRunningBetween provides a mock function with given fields: userId, from, to
+func (_m *TerminalAccessRepository) FindUserTerminalAccessDataRunningBetween(userId int32, from time.Time, to time.Time) ([]*models.UserTerminalAccessData, error) {
+	ret := _m.Called(userId, from, to)
+
+	var r0 []*models.UserTerminalAccessData
+	if rf, ok := ret.Get(0).(func(int32, time.Time, time.Time) []*models.UserTerminalAccessData); ok {
+		r0 = rf(userId, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.UserTerminalAccessData)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int32, time.Time, time.Time) error); ok {
+		r1 = rf(userId, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllRunningUserTerminalData provides a mock function with given fields:
 func (_m *TerminalAccessRepository) GetAllRunningUserTerminalData() ([]*models.UserTerminalAccessData, error) {
 	ret := _m.Called()
@@ -81,6 +140,29 @@ func (_m *TerminalAccessRepository) GetAllRunningUserTerminalData() ([]*models.U
 	return r0, r1
 }
 
+// GetSessionTranscript provides a mock function with given fields: sessionId
+func (_m *TerminalAccessRepository) GetSessionTranscript(sessionId string) (*models.UserTerminalSessionTranscript, error) {
+	ret := _m.Called(sessionId)
+
+	var r0 *models.UserTerminalSessionTranscript
+	if rf, ok := ret.Get(0).(func(string) *models.UserTerminalSessionTranscript); ok {
+		r0 = rf(sessionId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserTerminalSessionTranscript)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(sessionId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserTerminalAccessData provides a mock function with given fields: id
 func (_m *TerminalAccessRepository) GetUserTerminalAccessData(id int) (*models.UserTerminalAccessData, error) {
 	ret := _m.Called(id)
@@ -127,13 +209,76 @@ func (_m *TerminalAccessRepository) GetUserTerminalAccessDataByUser(userId int32
 	return r0, r1
 }
 
-// SaveUserTerminalAccessData provides a mock function with given fields: data
-func (_m *TerminalAccessRepository) SaveUserTerminalAccessData(data *models.UserTerminalAccessData) error {
-	ret := _m.Called(data)
+// MarkClusterSessionsTerminated provides a mock function with given fields: clusterId, tx
+func (_m *TerminalAccessRepository) MarkClusterSessionsTerminated(clusterId int, tx *pg.Tx) (int, error) {
+	ret := _m.Called(clusterId, tx)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(int, *pg.Tx) int); ok {
+		r0 = rf(clusterId, tx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, *pg.Tx) error); ok {
+		r1 = rf(clusterId, tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkUserTerminalRunning provides a mock function with given fields: id, startedOn, cpuMillis, memoryBytes
+func (_m *TerminalAccessRepository) MarkUserTerminalRunning(id int, startedOn time.Time, cpuMillis int64, memoryBytes int64) error {
+	ret := _m.Called(id, startedOn, cpuMillis, memoryBytes)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, time.Time, int64, int64) error); ok {
+		r0 = rf(id, startedOn, cpuMillis, memoryBytes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkUserTerminalRunningEnded provides a mock function with given fields: id, endedOn
+func (_m *TerminalAccessRepository) MarkUserTerminalRunningEnded(id int, endedOn time.Time) error {
+	ret := _m.Called(id, endedOn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, time.Time) error); ok {
+		r0 = rf(id, endedOn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveSessionTranscript provides a mock function with given fields: transcript, tx
+func (_m *TerminalAccessRepository) SaveSessionTranscript(transcript *models.UserTerminalSessionTranscript, tx *pg.Tx) error {
+	ret := _m.Called(transcript, tx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.UserTerminalSessionTranscript, *pg.Tx) error); ok {
+		r0 = rf(transcript, tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveUserTerminalAccessData provides a mock function with given fields: data, tx
+func (_m *TerminalAccessRepository) SaveUserTerminalAccessData(data *models.UserTerminalAccessData, tx *pg.Tx) error {
+	ret := _m.Called(data, tx)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.UserTerminalAccessData) error); ok {
-		r0 = rf(data)
+	if rf, ok := ret.Get(0).(func(*models.UserTerminalAccessData, *pg.Tx) error); ok {
+		r0 = rf(data, tx)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -141,13 +286,13 @@ func (_m *TerminalAccessRepository) SaveUserTerminalAccessData(data *models.User
 	return r0
 }
 
-// UpdateUserTerminalAccessData provides a mock function with given fields: data
-func (_m *TerminalAccessRepository) UpdateUserTerminalAccessData(data *models.UserTerminalAccessData) error {
-	ret := _m.Called(data)
+// UpdateUserTerminalAccessData provides a mock function with given fields: data, tx
+func (_m *TerminalAccessRepository) UpdateUserTerminalAccessData(data *models.UserTerminalAccessData, tx *pg.Tx) error {
+	ret := _m.Called(data, tx)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.UserTerminalAccessData) error); ok {
-		r0 = rf(data)
+	if rf, ok := ret.Get(0).(func(*models.UserTerminalAccessData, *pg.Tx) error); ok {
+		r0 = rf(data, tx)
 	} else {
 		r0 = ret.Error(0)
 	}
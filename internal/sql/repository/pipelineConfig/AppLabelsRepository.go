@@ -21,6 +21,9 @@ import (
 	"fmt"
 	"github.com/devtron-labs/devtron/internal/sql/repository/app"
 	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/pagination"
+	"github.com/go-pg/pg/orm"
+	"time"
 
 	"github.com/go-pg/pg"
 )
@@ -32,21 +35,81 @@ type AppLabel struct {
 	Key       string   `sql:"key,notnull"`
 	Value     string   `sql:"value,notnull"`
 	Propagate bool     `sql:"propagate,notnull"`
-	App       app.App
+	Source    string   `sql:"source,notnull"`
+	Type      string   `sql:"type,notnull"`
+	// Active is unset when the label is removed, the row is kept so that the label feed can report the deletion
+	Active bool `sql:"active,notnull"`
+	App    app.App
 	sql.AuditLog
 }
 
+// sources of the writes of app labels, rows written before the source was recorded are unknown
+const (
+	AppLabelSourceUi       = "ui"
+	AppLabelSourceApi      = "api"
+	AppLabelSourceImport   = "import"
+	AppLabelSourceSystem   = "system"
+	AppLabelSourceTemplate = "template"
+	AppLabelSourceUnknown  = "unknown"
+)
+
+// types of app labels, labels and annotations are propagated to the kubernetes metadata of the same kind when
+// Propagate is set and tags are never propagated
+const (
+	AppLabelTypeLabel      = "label"
+	AppLabelTypeAnnotation = "annotation"
+	AppLabelTypeTag        = "tag"
+)
+
+func IsValidAppLabelSource(source string) bool {
+	switch source {
+	case AppLabelSourceUi, AppLabelSourceApi, AppLabelSourceImport, AppLabelSourceSystem, AppLabelSourceTemplate, AppLabelSourceUnknown:
+		return true
+	}
+	return false
+}
+
+// AppLabelRepository writes on tx when it is set and directly on the connection otherwise
 type AppLabelRepository interface {
 	Create(model *AppLabel, tx *pg.Tx) (*AppLabel, error)
-	Update(model *AppLabel) (*AppLabel, error)
+	Update(model *AppLabel, tx *pg.Tx) (*AppLabel, error)
 	Delete(model *AppLabel, tx *pg.Tx) error
+	// DeleteByAppIdsAndKeys marks the labels of the apps having one of the keys removed in one statement and returns them
+	DeleteByAppIdsAndKeys(appIds []int, keys []string, userId int32, tx *pg.Tx) ([]*AppLabel, error)
 	FindById(id int) (*AppLabel, error)
 	FindAllByIds(ids []int) ([]*AppLabel, error)
 	FindAll() ([]*AppLabel, error)
+	FindAllByListingRequest(request *pagination.ListingRequest) ([]*AppLabel, error)
 	FindByLabelKey(key string) ([]*AppLabel, error)
 	FindByAppIdAndKeyAndValue(appId int, key string, value string) (*AppLabel, error)
 	FindByLabelValue(label string) ([]*AppLabel, error)
 	FindAllByAppId(appId int) ([]*AppLabel, error)
+	FindAllByAppIdWithTxn(appId int, tx *pg.Tx) ([]*AppLabel, error)
+	FindByProjectId(projectId int) ([]*AppLabel, error)
+	FindByEnvironmentId(environmentId int) ([]*AppLabel, error)
+	CountByAppId(appId int, tx *pg.Tx) (int, error)
+	FindAppsWithLabelCountAbove(limit int) ([]*AppLabelCount, error)
+	// FindFeedPage reads a page of the label feed in one repeatable read transaction, App is set on the returned labels
+	FindFeedPage(query *AppLabelFeedQuery) ([]*AppLabel, error)
+}
+
+// AppLabelFeedQuery selects the labels after the position (AfterUpdatedOn, AfterId) ordered by (updated_on, id).
+// Labels updated at or after SettledBefore are left out, a write committing later than its updated_on could otherwise
+// land behind a position already served. Removed labels are included when updated after DeletedAfter, a zero DeletedAfter
+// leaves them out
+type AppLabelFeedQuery struct {
+	AfterUpdatedOn time.Time
+	AfterId        int
+	DeletedAfter   time.Time
+	SettledBefore  time.Time
+	Limit          int
+}
+
+type AppLabelCount struct {
+	AppId      int    `sql:"app_id"`
+	AppName    string `sql:"app_name"`
+	TeamId     int    `sql:"team_id"`
+	LabelCount int    `sql:"label_count"`
 }
 
 type AppLabelRepositoryImpl struct {
@@ -58,14 +121,15 @@ func NewAppLabelRepositoryImpl(dbConnection *pg.DB) *AppLabelRepositoryImpl {
 }
 
 func (impl AppLabelRepositoryImpl) Create(model *AppLabel, tx *pg.Tx) (*AppLabel, error) {
-	err := tx.Insert(model)
+	model.Active = true
+	err := sql.Connection(impl.dbConnection, tx).Insert(model)
 	if err != nil {
 		return model, err
 	}
 	return model, nil
 }
-func (impl AppLabelRepositoryImpl) Update(model *AppLabel) (*AppLabel, error) {
-	err := impl.dbConnection.Update(model)
+func (impl AppLabelRepositoryImpl) Update(model *AppLabel, tx *pg.Tx) (*AppLabel, error) {
+	err := sql.Connection(impl.dbConnection, tx).Update(model)
 	if err != nil {
 		return model, err
 	}
@@ -73,37 +137,63 @@ func (impl AppLabelRepositoryImpl) Update(model *AppLabel) (*AppLabel, error) {
 	return model, nil
 }
 
+// Delete marks the label removed, UpdatedOn and UpdatedBy of model are recorded as the time and user of the removal
 func (impl AppLabelRepositoryImpl) Delete(model *AppLabel, tx *pg.Tx) error {
-	err := tx.Delete(model)
+	model.Active = false
+	_, err := sql.Connection(impl.dbConnection, tx).Model(model).
+		Column("active", "updated_on", "updated_by").WherePK().Update()
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func (impl AppLabelRepositoryImpl) DeleteByAppIdsAndKeys(appIds []int, keys []string, userId int32, tx *pg.Tx) ([]*AppLabel, error) {
+	var models []*AppLabel
+	query := "UPDATE app_label SET active = false, updated_on = ?, updated_by = ?" +
+		" WHERE app_id IN (?) AND key IN (?) AND active = true RETURNING *"
+	_, err := sql.Connection(impl.dbConnection, tx).Query(&models, query, time.Now(), userId, pg.In(appIds), pg.In(keys))
+	return models, err
+}
 func (impl AppLabelRepositoryImpl) FindById(id int) (*AppLabel, error) {
 	var model AppLabel
-	err := impl.dbConnection.Model(&model).Where("id = ?", id).Order("id desc").Limit(1).Select()
+	err := impl.dbConnection.Model(&model).Where("id = ?", id).Where("active = ?", true).Order("id desc").Limit(1).Select()
 	return &model, err
 }
 func (impl AppLabelRepositoryImpl) FindAllByIds(ids []int) ([]*AppLabel, error) {
 	var models []*AppLabel
-	err := impl.dbConnection.Model(&models).Where("id in (?)", pg.In(ids)).Order("updated_on desc").Select()
+	err := impl.dbConnection.Model(&models).Where("id in (?)", pg.In(ids)).Where("active = ?", true).Order("updated_on desc").Select()
 	return models, err
 }
 func (impl AppLabelRepositoryImpl) FindAll() ([]*AppLabel, error) {
 	var models []*AppLabel
-	err := impl.dbConnection.Model(&models).Order("updated_on desc").Select()
+	err := impl.dbConnection.Model(&models).Where("active = ?", true).Order("updated_on desc").Select()
+	return models, err
+}
+
+// FindAllByListingRequest applies search and sort of the listing request, pagination is left to the caller as labels
+// are filtered by rbac after fetching
+func (impl AppLabelRepositoryImpl) FindAllByListingRequest(request *pagination.ListingRequest) ([]*AppLabel, error) {
+	var models []*AppLabel
+	query := impl.dbConnection.Model(&models).Where("active = ?", true)
+	if request.SearchKey != "" {
+		pattern := request.SearchPattern()
+		query = query.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.WhereOr("key ILIKE ?", pattern).WhereOr("value ILIKE ?", pattern), nil
+		})
+	}
+	err := request.ApplySort(query).Select()
 	return models, err
 }
 func (impl AppLabelRepositoryImpl) FindByLabelKey(key string) ([]*AppLabel, error) {
 	var models []*AppLabel
-	err := impl.dbConnection.Model(&models).Where("key = ?", key).Select()
+	err := impl.dbConnection.Model(&models).Where("key = ?", key).Where("active = ?", true).Select()
 	return models, err
 }
 func (impl AppLabelRepositoryImpl) FindByAppIdAndKeyAndValue(appId int, key string, value string) (*AppLabel, error) {
 	var model AppLabel
 	err := impl.dbConnection.Model(&model).Where("app_id = ?", appId).
-		Where("key = ?", key).Where("value = ?", value).Select()
+		Where("key = ?", key).Where("value = ?", value).Where("active = ?", true).Select()
 	return &model, err
 }
 
@@ -112,12 +202,104 @@ func (impl AppLabelRepositoryImpl) FindByLabelValue(label string) ([]*AppLabel,
 		return nil, fmt.Errorf("no labels provided for search")
 	}
 	var models []*AppLabel
-	err := impl.dbConnection.Model(&models).Where("value = ?", label).Select()
+	err := impl.dbConnection.Model(&models).Where("value = ?", label).Where("active = ?", true).Select()
 	return models, err
 }
 
 func (impl AppLabelRepositoryImpl) FindAllByAppId(appId int) ([]*AppLabel, error) {
 	var models []*AppLabel
-	err := impl.dbConnection.Model(&models).Where("app_id=?", appId).Select()
+	err := impl.dbConnection.Model(&models).Where("app_id=?", appId).Where("active = ?", true).Select()
+	return models, err
+}
+
+func (impl AppLabelRepositoryImpl) FindAllByAppIdWithTxn(appId int, tx *pg.Tx) ([]*AppLabel, error) {
+	var models []*AppLabel
+	err := sql.Connection(impl.dbConnection, tx).Model(&models).Where("app_id = ?", appId).Where("active = ?", true).Select()
+	return models, err
+}
+
+// FindByProjectId returns the labels of the active apps of the project
+func (impl AppLabelRepositoryImpl) FindByProjectId(projectId int) ([]*AppLabel, error) {
+	var models []*AppLabel
+	err := impl.dbConnection.Model(&models).
+		Column("app_label.*").
+		Join("INNER JOIN app a ON a.id = app_label.app_id").
+		Where("a.team_id = ?", projectId).
+		Where("a.active = ?", true).
+		Where("app_label.active = ?", true).
+		Order("app_label.app_id").Order("app_label.key").
+		Select()
+	return models, err
+}
+
+// FindByEnvironmentId returns the labels of the apps which have been deployed to the environment by one of their active
+// pipelines
+func (impl AppLabelRepositoryImpl) FindByEnvironmentId(environmentId int) ([]*AppLabel, error) {
+	var models []*AppLabel
+	err := impl.dbConnection.Model(&models).
+		Where("EXISTS (SELECT 1 FROM pipeline p INNER JOIN pipeline_config_override pco ON pco.pipeline_id = p.id"+
+			" WHERE p.app_id = app_label.app_id AND p.environment_id = ? AND p.deleted = false)", environmentId).
+		Where("app_label.active = ?", true).
+		Order("app_label.app_id").Order("app_label.key").
+		Select()
+	return models, err
+}
+
+func (impl AppLabelRepositoryImpl) CountByAppId(appId int, tx *pg.Tx) (int, error) {
+	return sql.Connection(impl.dbConnection, tx).Model((*AppLabel)(nil)).Where("app_id = ?", appId).Where("active = ?", true).Count()
+}
+
+// FindAppsWithLabelCountAbove returns the active apps having more than limit labels, most labelled first
+func (impl AppLabelRepositoryImpl) FindAppsWithLabelCountAbove(limit int) ([]*AppLabelCount, error) {
+	var counts []*AppLabelCount
+	query := "SELECT a.id AS app_id, a.app_name, a.team_id, count(al.id) AS label_count" +
+		" FROM app_label al INNER JOIN app a ON a.id = al.app_id AND a.active = true WHERE al.active = true" +
+		" GROUP BY a.id, a.app_name, a.team_id HAVING count(al.id) > ? ORDER BY label_count DESC, a.id"
+	_, err := impl.dbConnection.Query(&counts, query, limit)
+	return counts, err
+}
+
+func (impl AppLabelRepositoryImpl) FindFeedPage(query *AppLabelFeedQuery) ([]*AppLabel, error) {
+	var models []*AppLabel
+	err := impl.dbConnection.RunInTransaction(func(tx *pg.Tx) error {
+		// the labels and their apps are read from one snapshot
+		_, err := tx.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ")
+		if err != nil {
+			return err
+		}
+		q := tx.Model(&models).
+			Where("(updated_on, id) > (?, ?)", query.AfterUpdatedOn, query.AfterId).
+			Where("updated_on < ?", query.SettledBefore)
+		if query.DeletedAfter.IsZero() {
+			q = q.Where("active = ?", true)
+		} else {
+			q = q.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+				return q.WhereOr("active = ?", true).WhereOr("updated_on > ?", query.DeletedAfter), nil
+			})
+		}
+		err = q.Order("updated_on").Order("id").Limit(query.Limit).Select()
+		if err != nil || len(models) == 0 {
+			return err
+		}
+		appIds := make([]int, 0, len(models))
+		for _, model := range models {
+			appIds = append(appIds, model.AppId)
+		}
+		var apps []*app.App
+		err = tx.Model(&apps).Where("id in (?)", pg.In(appIds)).Select()
+		if err != nil {
+			return err
+		}
+		appsById := make(map[int]*app.App, len(apps))
+		for _, a := range apps {
+			appsById[a.Id] = a
+		}
+		for _, model := range models {
+			if a, ok := appsById[model.AppId]; ok {
+				model.App = *a
+			}
+		}
+		return nil
+	})
 	return models, err
 }
@@ -0,0 +1,70 @@
+package pipelineConfig
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+type LabelTemplate struct {
+	tableName struct{} `sql:"label_template" pg:",discard_unknown_columns"`
+	Id        int      `sql:"id,pk"`
+	Name      string   `sql:"name,notnull"`
+	Active    bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type LabelTemplateEntry struct {
+	tableName       struct{} `sql:"label_template_entry" pg:",discard_unknown_columns"`
+	Id              int      `sql:"id,pk"`
+	LabelTemplateId int      `sql:"label_template_id,notnull"`
+	Key             string   `sql:"key,notnull"`
+	DefaultValue    string   `sql:"default_value,notnull"`
+	Required        bool     `sql:"required,notnull"`
+	sql.AuditLog
+}
+
+// LabelTemplateRepository writes on tx when it is set and directly on the connection otherwise
+type LabelTemplateRepository interface {
+	Create(model *LabelTemplate, tx *pg.Tx) error
+	CreateEntries(entries []*LabelTemplateEntry, tx *pg.Tx) error
+	FindActiveById(id int) (*LabelTemplate, error)
+	FindActiveByName(name string) (*LabelTemplate, error)
+	FindEntriesByTemplateId(templateId int) ([]*LabelTemplateEntry, error)
+}
+
+type LabelTemplateRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewLabelTemplateRepositoryImpl(dbConnection *pg.DB) *LabelTemplateRepositoryImpl {
+	return &LabelTemplateRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl LabelTemplateRepositoryImpl) Create(model *LabelTemplate, tx *pg.Tx) error {
+	return sql.Connection(impl.dbConnection, tx).Insert(model)
+}
+
+func (impl LabelTemplateRepositoryImpl) CreateEntries(entries []*LabelTemplateEntry, tx *pg.Tx) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return sql.Connection(impl.dbConnection, tx).Insert(&entries)
+}
+
+func (impl LabelTemplateRepositoryImpl) FindActiveById(id int) (*LabelTemplate, error) {
+	model := &LabelTemplate{}
+	err := impl.dbConnection.Model(model).Where("id = ?", id).Where("active = ?", true).Select()
+	return model, err
+}
+
+func (impl LabelTemplateRepositoryImpl) FindActiveByName(name string) (*LabelTemplate, error) {
+	model := &LabelTemplate{}
+	err := impl.dbConnection.Model(model).Where("name = ?", name).Where("active = ?", true).Select()
+	return model, err
+}
+
+func (impl LabelTemplateRepositoryImpl) FindEntriesByTemplateId(templateId int) ([]*LabelTemplateEntry, error) {
+	var entries []*LabelTemplateEntry
+	err := impl.dbConnection.Model(&entries).Where("label_template_id = ?", templateId).Order("id").Select()
+	return entries, err
+}
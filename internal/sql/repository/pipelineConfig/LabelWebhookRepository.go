@@ -0,0 +1,100 @@
+package pipelineConfig
+
+import (
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+const (
+	LabelEventStatusPending   = "pending"
+	LabelEventStatusDelivered = "delivered"
+	LabelEventStatusFailed    = "failed"
+)
+
+// LabelWebhook is notified of label changes, EventTypes is a comma separated filter and an empty filter matches all events
+type LabelWebhook struct {
+	tableName  struct{} `sql:"label_webhook" pg:",discard_unknown_columns"`
+	Id         int      `sql:"id,pk"`
+	Url        string   `sql:"url,notnull"`
+	EventTypes string   `sql:"event_types,notnull"`
+	Active     bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// LabelEventOutbox is a label event pending delivery to one webhook, it is written in the transaction of the label change
+type LabelEventOutbox struct {
+	tableName     struct{}  `sql:"label_event_outbox" pg:",discard_unknown_columns"`
+	Id            int       `sql:"id,pk"`
+	WebhookId     int       `sql:"webhook_id,notnull"`
+	EventType     string    `sql:"event_type,notnull"`
+	Payload       string    `sql:"payload,notnull"`
+	Status        string    `sql:"status,notnull"`
+	Attempts      int       `sql:"attempts,notnull"`
+	LastError     string    `sql:"last_error"`
+	NextAttemptOn time.Time `sql:"next_attempt_on,notnull"`
+	CreatedOn     time.Time `sql:"created_on,notnull"`
+	UpdatedOn     time.Time `sql:"updated_on"`
+}
+
+type LabelWebhookRepository interface {
+	Create(model *LabelWebhook) error
+	FindAllActive() ([]*LabelWebhook, error)
+	FindByIds(ids []int) ([]*LabelWebhook, error)
+	CreateOutboxEvents(events []*LabelEventOutbox, tx *pg.Tx) error
+	// ClaimDueOutboxEvents leases up to limit pending events which are due by moving their next attempt to leaseUntil,
+	// rows locked by another instance are skipped so that every event is delivered by one instance at a time
+	ClaimDueOutboxEvents(limit int, now time.Time, leaseUntil time.Time) ([]*LabelEventOutbox, error)
+	UpdateOutboxEvent(event *LabelEventOutbox) error
+}
+
+type LabelWebhookRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewLabelWebhookRepositoryImpl(dbConnection *pg.DB) *LabelWebhookRepositoryImpl {
+	return &LabelWebhookRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl LabelWebhookRepositoryImpl) Create(model *LabelWebhook) error {
+	return impl.dbConnection.Insert(model)
+}
+
+func (impl LabelWebhookRepositoryImpl) FindAllActive() ([]*LabelWebhook, error) {
+	var webhooks []*LabelWebhook
+	err := impl.dbConnection.Model(&webhooks).Where("active = ?", true).Order("id").Select()
+	return webhooks, err
+}
+
+func (impl LabelWebhookRepositoryImpl) FindByIds(ids []int) ([]*LabelWebhook, error) {
+	var webhooks []*LabelWebhook
+	if len(ids) == 0 {
+		return webhooks, nil
+	}
+	err := impl.dbConnection.Model(&webhooks).Where("id in (?)", pg.In(ids)).Select()
+	return webhooks, err
+}
+
+func (impl LabelWebhookRepositoryImpl) CreateOutboxEvents(events []*LabelEventOutbox, tx *pg.Tx) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return sql.Connection(impl.dbConnection, tx).Insert(&events)
+}
+
+func (impl LabelWebhookRepositoryImpl) ClaimDueOutboxEvents(limit int, now time.Time, leaseUntil time.Time) ([]*LabelEventOutbox, error) {
+	var events []*LabelEventOutbox
+	query := "UPDATE label_event_outbox SET next_attempt_on = ?, updated_on = ? WHERE id IN " +
+		"(SELECT id FROM label_event_outbox WHERE status = ? AND next_attempt_on <= ? ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED) " +
+		"RETURNING *;"
+	_, err := impl.dbConnection.Query(&events, query, leaseUntil, now, LabelEventStatusPending, now, limit)
+	return events, err
+}
+
+func (impl LabelWebhookRepositoryImpl) UpdateOutboxEvent(event *LabelEventOutbox) error {
+	_, err := impl.dbConnection.Model(event).
+		Column("status", "attempts", "last_error", "next_attempt_on", "updated_on").
+		WherePK().Update()
+	return err
+}
@@ -0,0 +1,25 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHealthzVerbose(t *testing.T) {
+	output := `[+]ping ok
+[+]log ok
+[-]etcd failed: reason withheld
+[+]poststarthook/start-informers ok
+[-]broken
+healthz check failed
+`
+	assert.Equal(t, map[string]string{
+		"ping":                          HealthzStatusOk,
+		"log":                           HealthzStatusOk,
+		"etcd":                          "failed: reason withheld",
+		"poststarthook/start-informers": HealthzStatusOk,
+		"broken":                        HealthzStatusFailed,
+	}, parseHealthzVerbose(output))
+	assert.Empty(t, parseHealthzVerbose("ok"))
+}
@@ -0,0 +1,125 @@
+package util
+
+import (
+	"context"
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"net"
+	"strings"
+)
+
+const (
+	clusterCIDRFlag           = "--cluster-cidr"
+	serviceClusterIpRangeFlag = "--service-cluster-ip-range"
+	kubeDnsServiceName        = "kube-dns"
+	kubeadmConfigMapName      = "kubeadm-config"
+	clusterInfoConfigMapName  = "cluster-info"
+)
+
+// ClusterCIDR is the network layout of a cluster, fields are nil when the cluster does not expose them which is the
+// case for most managed offerings as their control plane does not run as pods. DNSServiceIP is a single address mask
+type ClusterCIDR struct {
+	PodCIDR      *net.IPNet `json:"podCIDR"`
+	ServiceCIDR  *net.IPNet `json:"serviceCIDR"`
+	DNSServiceIP *net.IPNet `json:"dnsServiceIP"`
+}
+
+// controlPlaneCIDRs reads the ranges from the flags of the control plane pods, controller manager first as it knows
+// both ranges and the api server only the service one
+func controlPlaneCIDRs(ctx context.Context, clientSet *kubernetes.Clientset, cidr *ClusterCIDR) error {
+	for _, component := range []string{"kube-controller-manager", "kube-apiserver"} {
+		pods, err := clientSet.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{LabelSelector: "component=" + component})
+		if err != nil {
+			return err
+		}
+		if len(pods.Items) == 0 {
+			continue
+		}
+		for _, container := range pods.Items[0].Spec.Containers {
+			args := append(append([]string{}, container.Command...), container.Args...)
+			if cidr.PodCIDR == nil {
+				cidr.PodCIDR = parseFirstCIDR(apiServerFlagValue(args, clusterCIDRFlag))
+			}
+			if cidr.ServiceCIDR == nil {
+				cidr.ServiceCIDR = parseFirstCIDR(apiServerFlagValue(args, serviceClusterIpRangeFlag))
+			}
+		}
+	}
+	return nil
+}
+
+// configMapCIDRs fills the ranges missing after the control plane pods from the networking section of the kubeadm
+// ClusterConfiguration, which kubeadm keeps in kube-system and some installers copy to the public cluster-info
+func configMapCIDRs(ctx context.Context, clientSet *kubernetes.Clientset, cidr *ClusterCIDR) error {
+	for _, configMapKey := range []struct{ namespace, name string }{{"kube-public", clusterInfoConfigMapName}, {"kube-system", kubeadmConfigMapName}} {
+		if cidr.PodCIDR != nil && cidr.ServiceCIDR != nil {
+			return nil
+		}
+		configMap, err := clientSet.CoreV1().ConfigMaps(configMapKey.namespace).Get(ctx, configMapKey.name, metav1.GetOptions{})
+		if errors.IsNotFound(err) || errors.IsForbidden(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		for _, value := range configMap.Data {
+			podSubnet, serviceSubnet := parseNetworkingSubnets(value)
+			if cidr.PodCIDR == nil {
+				cidr.PodCIDR = parseFirstCIDR(podSubnet)
+			}
+			if cidr.ServiceCIDR == nil {
+				cidr.ServiceCIDR = parseFirstCIDR(serviceSubnet)
+			}
+		}
+	}
+	return nil
+}
+
+func dnsServiceIP(ctx context.Context, clientSet *kubernetes.Clientset) (*net.IPNet, error) {
+	service, err := clientSet.CoreV1().Services("kube-system").Get(ctx, kubeDnsServiceName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return singleIPNet(service.Spec.ClusterIP), nil
+}
+
+// parseNetworkingSubnets reads networking.podSubnet and networking.serviceSubnet of a kubeadm ClusterConfiguration,
+// values which are not one are ignored
+func parseNetworkingSubnets(config string) (string, string) {
+	clusterConfiguration := struct {
+		Networking struct {
+			PodSubnet     string `json:"podSubnet"`
+			ServiceSubnet string `json:"serviceSubnet"`
+		} `json:"networking"`
+	}{}
+	if err := yaml.Unmarshal([]byte(config), &clusterConfiguration); err != nil {
+		return "", ""
+	}
+	return clusterConfiguration.Networking.PodSubnet, clusterConfiguration.Networking.ServiceSubnet
+}
+
+// parseFirstCIDR returns the first range of a dual stack list like 10.244.0.0/16,fd00:10:244::/56
+func parseFirstCIDR(value string) *net.IPNet {
+	if len(value) == 0 {
+		return nil
+	}
+	_, ipNet, err := net.ParseCIDR(strings.TrimSpace(strings.Split(value, ",")[0]))
+	if err != nil {
+		return nil
+	}
+	return ipNet
+}
+
+func singleIPNet(value string) *net.IPNet {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
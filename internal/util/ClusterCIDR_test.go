@@ -0,0 +1,37 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNetworkingSubnets(t *testing.T) {
+	config := `apiVersion: kubeadm.k8s.io/v1beta3
+kind: ClusterConfiguration
+networking:
+  dnsDomain: cluster.local
+  podSubnet: 10.244.0.0/16
+  serviceSubnet: 10.96.0.0/12
+`
+	podSubnet, serviceSubnet := parseNetworkingSubnets(config)
+	assert.Equal(t, "10.244.0.0/16", podSubnet)
+	assert.Equal(t, "10.96.0.0/12", serviceSubnet)
+
+	podSubnet, serviceSubnet = parseNetworkingSubnets("apiVersion: v1\nclusters: []\n")
+	assert.Empty(t, podSubnet)
+	assert.Empty(t, serviceSubnet)
+}
+
+func TestParseFirstCIDR(t *testing.T) {
+	assert.Equal(t, "10.244.0.0/16", parseFirstCIDR("10.244.0.0/16,fd00:10:244::/56").String())
+	assert.Equal(t, "10.96.0.0/12", parseFirstCIDR("10.96.0.1/12").String(), "host bits are dropped")
+	assert.Nil(t, parseFirstCIDR(""))
+	assert.Nil(t, parseFirstCIDR("not-a-cidr"))
+}
+
+func TestSingleIPNet(t *testing.T) {
+	assert.Equal(t, "10.96.0.10/32", singleIPNet("10.96.0.10").String())
+	assert.Equal(t, "fd00::a/128", singleIPNet("fd00::a").String())
+	assert.Nil(t, singleIPNet("None"))
+}
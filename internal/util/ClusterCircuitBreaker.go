@@ -0,0 +1,281 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	ClusterCircuitClosed   = "closed"
+	ClusterCircuitOpen     = "open"
+	ClusterCircuitHalfOpen = "half-open"
+	// clusterCircuitProbePath is the cheapest authenticated call of an api server
+	clusterCircuitProbePath = "/version"
+)
+
+var clusterCircuitStateGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "orchestrator_cluster_circuit_breaker_state",
+	Help: "state of the circuit breaker of a cluster, 0 closed, 1 half-open and 2 open",
+}, []string{"clusterId"})
+
+var clusterCircuitRejectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "orchestrator_cluster_circuit_breaker_rejected_total",
+	Help: "calls to a cluster failed fast by its open circuit breaker",
+}, []string{"clusterId"})
+
+var clusterCircuitStateValue = map[string]float64{
+	ClusterCircuitClosed:   0,
+	ClusterCircuitHalfOpen: 1,
+	ClusterCircuitOpen:     2,
+}
+
+// ClusterCircuitBreakerConfig sets when calls to a cluster stop being attempted. The breaker opens after
+// FailureThreshold consecutive calls found the api server unavailable, calls then fail fast for OpenDurationInSecs
+// after which the next call probes the api server and closes the breaker when the probe succeeds
+type ClusterCircuitBreakerConfig struct {
+	Enabled            bool `env:"CLUSTER_CIRCUIT_BREAKER_ENABLED" envDefault:"true"`
+	FailureThreshold   int  `env:"CLUSTER_CIRCUIT_BREAKER_FAILURE_THRESHOLD" envDefault:"5"`
+	OpenDurationInSecs int  `env:"CLUSTER_CIRCUIT_BREAKER_OPEN_DURATION_IN_SECS" envDefault:"30"`
+	ProbeTimeoutInSecs int  `env:"CLUSTER_CIRCUIT_BREAKER_PROBE_TIMEOUT_IN_SECS" envDefault:"5"`
+}
+
+func GetClusterCircuitBreakerConfig() (*ClusterCircuitBreakerConfig, error) {
+	config := &ClusterCircuitBreakerConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+// ClusterCircuitOpenError is returned instead of calling a cluster whose breaker is open, reads and writes alike
+type ClusterCircuitOpenError struct {
+	ClusterId  int
+	LastError  string
+	RetryAfter time.Time
+}
+
+func (e *ClusterCircuitOpenError) Error() string {
+	return fmt.Sprintf("calls to cluster %d are paused after repeated failures until %s, last error: %s",
+		e.ClusterId, e.RetryAfter.UTC().Format(time.RFC3339), e.LastError)
+}
+
+func (e *ClusterCircuitOpenError) Is(target error) bool {
+	return target == ErrClusterCircuitOpen
+}
+
+// ClusterCircuitState is the breaker of a cluster as reported by the cluster health endpoint
+type ClusterCircuitState struct {
+	ClusterId           int        `json:"clusterId"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastError           string     `json:"lastError,omitempty"`
+	OpenedOn            *time.Time `json:"openedOn,omitempty"`
+	RetryAfter          *time.Time `json:"retryAfter,omitempty"`
+}
+
+// clusterCircuitBreakers holds a breaker per cluster id, the breaker of a cluster is shared by all its clients
+type clusterCircuitBreakers struct {
+	config   *ClusterCircuitBreakerConfig
+	lock     sync.Mutex
+	breakers map[int]*clusterCircuitBreaker
+}
+
+func newClusterCircuitBreakers(config *ClusterCircuitBreakerConfig) *clusterCircuitBreakers {
+	return &clusterCircuitBreakers{config: config, breakers: make(map[int]*clusterCircuitBreaker)}
+}
+
+func (b *clusterCircuitBreakers) get(clusterId int) *clusterCircuitBreaker {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	breaker, ok := b.breakers[clusterId]
+	if !ok {
+		breaker = &clusterCircuitBreaker{clusterId: clusterId, config: b.config, state: ClusterCircuitClosed}
+		b.breakers[clusterId] = breaker
+	}
+	return breaker
+}
+
+// wrap routes the calls of cfg through the breaker of the cluster, configs without a cluster id are left as they are
+func (b *clusterCircuitBreakers) wrap(cfg *rest.Config, clusterId int) {
+	if b == nil || !b.config.Enabled || clusterId == 0 {
+		return
+	}
+	breaker := b.get(clusterId)
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &clusterCircuitTransport{breaker: breaker, next: rt}
+	})
+}
+
+func (b *clusterCircuitBreakers) state(clusterId int) *ClusterCircuitState {
+	if b == nil {
+		return &ClusterCircuitState{ClusterId: clusterId, State: ClusterCircuitClosed}
+	}
+	b.lock.Lock()
+	breaker, ok := b.breakers[clusterId]
+	b.lock.Unlock()
+	if !ok {
+		return &ClusterCircuitState{ClusterId: clusterId, State: ClusterCircuitClosed}
+	}
+	return breaker.snapshot()
+}
+
+// GetClusterCircuitState returns the breaker of the cluster, clusters which were not called yet are closed
+func (impl K8sUtil) GetClusterCircuitState(clusterId int) *ClusterCircuitState {
+	return impl.circuitBreakers.state(clusterId)
+}
+
+type clusterCircuitBreaker struct {
+	clusterId           int
+	config              *ClusterCircuitBreakerConfig
+	lock                sync.Mutex
+	state               string
+	consecutiveFailures int
+	lastError           string
+	openedOn            time.Time
+	retryAfter          time.Time
+}
+
+// allow returns whether the call has to probe the cluster first, only the first call after the open duration probes
+// and the other calls keep failing fast until the probe completes
+func (b *clusterCircuitBreaker) allow(now time.Time) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	switch b.state {
+	case ClusterCircuitClosed:
+		return false, nil
+	case ClusterCircuitOpen:
+		if !now.Before(b.retryAfter) {
+			b.setState(ClusterCircuitHalfOpen)
+			return true, nil
+		}
+	}
+	clusterCircuitRejectedCounter.WithLabelValues(strconv.Itoa(b.clusterId)).Inc()
+	return false, b.openError()
+}
+
+// probed closes the breaker after a successful probe and opens it again for another open duration otherwise
+func (b *clusterCircuitBreaker) probed(err error, now time.Time) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if err == nil {
+		b.consecutiveFailures = 0
+		b.lastError = ""
+		b.setState(ClusterCircuitClosed)
+		return nil
+	}
+	b.lastError = err.Error()
+	b.open(now)
+	return b.openError()
+}
+
+// record counts the outcome of a call made while the breaker was closed, calls which were in flight when it opened are
+// not counted
+func (b *clusterCircuitBreaker) record(err error, now time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.state != ClusterCircuitClosed {
+		return
+	}
+	if err == nil {
+		b.consecutiveFailures = 0
+		return
+	}
+	b.consecutiveFailures++
+	b.lastError = err.Error()
+	if b.config.FailureThreshold > 0 && b.consecutiveFailures >= b.config.FailureThreshold {
+		b.open(now)
+	}
+}
+
+func (b *clusterCircuitBreaker) open(now time.Time) {
+	b.openedOn = now
+	b.retryAfter = now.Add(time.Duration(b.config.OpenDurationInSecs) * time.Second)
+	b.setState(ClusterCircuitOpen)
+}
+
+func (b *clusterCircuitBreaker) setState(state string) {
+	b.state = state
+	clusterCircuitStateGauge.WithLabelValues(strconv.Itoa(b.clusterId)).Set(clusterCircuitStateValue[state])
+}
+
+func (b *clusterCircuitBreaker) openError() error {
+	return &ClusterCircuitOpenError{ClusterId: b.clusterId, LastError: b.lastError, RetryAfter: b.retryAfter}
+}
+
+func (b *clusterCircuitBreaker) snapshot() *ClusterCircuitState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	state := &ClusterCircuitState{ClusterId: b.clusterId, State: b.state, ConsecutiveFailures: b.consecutiveFailures, LastError: b.lastError}
+	if b.state != ClusterCircuitClosed {
+		openedOn, retryAfter := b.openedOn, b.retryAfter
+		state.OpenedOn, state.RetryAfter = &openedOn, &retryAfter
+	}
+	return state
+}
+
+// clusterCircuitTransport sits below the auth wrappers of client-go, so the probe reuses the credentials of the call
+type clusterCircuitTransport struct {
+	breaker *clusterCircuitBreaker
+	next    http.RoundTripper
+}
+
+func (t *clusterCircuitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	probe, err := t.breaker.allow(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if probe {
+		if err = t.breaker.probed(t.probe(req), time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := t.next.RoundTrip(req)
+	// calls cancelled by the caller say nothing about the cluster and are not counted either way
+	if err == nil || !(errors.Is(err, context.Canceled) || errors.Is(req.Context().Err(), context.Canceled)) {
+		t.breaker.record(clusterCallFailure(resp, err), time.Now())
+	}
+	return resp, err
+}
+
+func (t *clusterCircuitTransport) probe(req *http.Request) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.breaker.config.ProbeTimeoutInSecs)*time.Second)
+	defer cancel()
+	probeUrl := *req.URL
+	probeUrl.Path, probeUrl.RawPath, probeUrl.RawQuery = clusterCircuitProbePath, "", ""
+	probeReq, err := http.NewRequestWithContext(ctx, http.MethodGet, probeUrl.String(), nil)
+	if err != nil {
+		return err
+	}
+	probeReq.Header = req.Header.Clone()
+	resp, err := t.next.RoundTrip(probeReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("api server responded to %s with %s", clusterCircuitProbePath, resp.Status)
+	}
+	return nil
+}
+
+// clusterCallFailure returns the reason a call counts as a failure of the cluster, nil when the api server answered
+func clusterCallFailure(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return fmt.Errorf("api server responded with %s", resp.Status)
+	}
+	return nil
+}
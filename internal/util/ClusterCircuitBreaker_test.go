@@ -0,0 +1,115 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newTestCircuitTransport(next roundTripFunc) *clusterCircuitTransport {
+	config := &ClusterCircuitBreakerConfig{Enabled: true, FailureThreshold: 2, OpenDurationInSecs: 30, ProbeTimeoutInSecs: 1}
+	return &clusterCircuitTransport{breaker: newClusterCircuitBreakers(config).get(7), next: next}
+}
+
+func testResponse(status int) *http.Response {
+	return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: io.NopCloser(strings.NewReader(""))}
+}
+
+func TestClusterCircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	calls := 0
+	circuitTransport := newTestCircuitTransport(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return nil, errors.New("connection refused")
+	})
+	req, _ := http.NewRequest(http.MethodPost, "https://10.0.0.1/api/v1/namespaces/dev/pods", nil)
+	for i := 0; i < 2; i++ {
+		_, err := circuitTransport.RoundTrip(req)
+		assert.EqualError(t, err, "connection refused")
+	}
+	// writes fail fast with the breaker error as well once it is open
+	_, err := circuitTransport.RoundTrip(req)
+	assert.True(t, errors.Is(err, ErrClusterCircuitOpen))
+	var openErr *ClusterCircuitOpenError
+	if assert.True(t, errors.As(err, &openErr)) {
+		assert.Equal(t, "connection refused", openErr.LastError)
+		assert.True(t, openErr.RetryAfter.After(time.Now()))
+	}
+	assert.Equal(t, 2, calls)
+	state := circuitTransport.breaker.snapshot()
+	assert.Equal(t, ClusterCircuitOpen, state.State)
+	assert.Equal(t, 2, state.ConsecutiveFailures)
+}
+
+func TestClusterCircuitBreakerProbe(t *testing.T) {
+	probeStatus := http.StatusServiceUnavailable
+	var paths []string
+	circuitTransport := newTestCircuitTransport(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		if req.URL.Path == clusterCircuitProbePath {
+			assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+			return testResponse(probeStatus), nil
+		}
+		return testResponse(http.StatusOK), nil
+	})
+	breaker := circuitTransport.breaker
+	breaker.lastError = "connection refused"
+	breaker.open(time.Now().Add(-time.Minute))
+
+	req, _ := http.NewRequest(http.MethodGet, "https://10.0.0.1/api/v1/pods?limit=5", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	_, err := circuitTransport.RoundTrip(req)
+	assert.True(t, errors.Is(err, ErrClusterCircuitOpen))
+	assert.Equal(t, ClusterCircuitOpen, breaker.snapshot().State)
+
+	probeStatus = http.StatusOK
+	breaker.retryAfter = time.Now().Add(-time.Second)
+	resp, err := circuitTransport.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{clusterCircuitProbePath, clusterCircuitProbePath, "/api/v1/pods"}, paths)
+	state := breaker.snapshot()
+	assert.Equal(t, ClusterCircuitClosed, state.State)
+	assert.Empty(t, state.LastError)
+	assert.Nil(t, state.RetryAfter)
+}
+
+func TestClusterCircuitBreakerIgnoresCancelledCalls(t *testing.T) {
+	circuitTransport := newTestCircuitTransport(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodDelete {
+			return nil, context.Canceled
+		}
+		return testResponse(http.StatusGatewayTimeout), nil
+	})
+	get, _ := http.NewRequest(http.MethodGet, "https://10.0.0.1/version", nil)
+	remove, _ := http.NewRequest(http.MethodDelete, "https://10.0.0.1/api/v1/namespaces/dev/pods/web", nil)
+	_, _ = circuitTransport.RoundTrip(get)
+	_, _ = circuitTransport.RoundTrip(remove)
+	assert.Equal(t, ClusterCircuitClosed, circuitTransport.breaker.snapshot().State)
+	assert.Equal(t, 1, circuitTransport.breaker.snapshot().ConsecutiveFailures)
+	_, _ = circuitTransport.RoundTrip(get)
+	assert.Equal(t, ClusterCircuitOpen, circuitTransport.breaker.snapshot().State)
+}
+
+func TestClusterCircuitBreakersState(t *testing.T) {
+	var breakers *clusterCircuitBreakers
+	assert.Equal(t, ClusterCircuitClosed, breakers.state(3).State)
+	breakers = newClusterCircuitBreakers(&ClusterCircuitBreakerConfig{Enabled: true, FailureThreshold: 1, OpenDurationInSecs: 30})
+	assert.Equal(t, ClusterCircuitClosed, breakers.state(3).State)
+	breakers.get(3).record(errors.New("i/o timeout"), time.Now())
+	state := breakers.state(3)
+	assert.Equal(t, ClusterCircuitOpen, state.State)
+	assert.Equal(t, "i/o timeout", state.LastError)
+	assert.NotNil(t, state.RetryAfter)
+}
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	batchV1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCompletedJobsBefore(t *testing.T) {
+	now := time.Date(2023, 3, 10, 10, 30, 0, 0, time.UTC)
+	job := func(name string, completionTime *time.Time) batchV1.Job {
+		job := batchV1.Job{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if completionTime != nil {
+			job.Status.CompletionTime = &metav1.Time{Time: *completionTime}
+		}
+		return job
+	}
+	jobs := []batchV1.Job{
+		job("old", timePtr(now.Add(-2*time.Hour))),
+		job("recent", timePtr(now.Add(-10*time.Minute))),
+		job("running or failed", nil),
+	}
+	completed := completedJobsBefore(jobs, now.Add(-time.Hour))
+	assert.Len(t, completed, 1)
+	assert.Equal(t, "old", completed[0].Name)
+	assert.Empty(t, completedJobsBefore(nil, now))
+}
@@ -0,0 +1,132 @@
+package util
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConfigObjectListMaxLimit bounds a page of ConfigMaps or Secrets, namespaces may hold hundreds of them
+const ConfigObjectListMaxLimit = 500
+
+// ConfigObjectListOptions filters the ConfigMaps or Secrets of a namespace, DevtronManagedOnly keeps the ones carrying
+// the devtron managed-by label. Continue is the token returned with the previous page
+type ConfigObjectListOptions struct {
+	LabelSelector      string
+	DevtronManagedOnly bool
+	Limit              int64
+	Continue           string
+}
+
+// ConfigObjectMetadata describes a ConfigMap or Secret without its values, DataSizeInBytes adds up the lengths of the
+// keys and values. Type is only set for secrets
+type ConfigObjectMetadata struct {
+	Name            string            `json:"name"`
+	Type            string            `json:"type,omitempty"`
+	KeyCount        int               `json:"keyCount"`
+	DataSizeInBytes int               `json:"dataSizeInBytes"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	CreatedOn       time.Time         `json:"createdOn"`
+	AgeInSeconds    int64             `json:"ageInSeconds"`
+}
+
+// ConfigObjectList is a page of ConfigMaps or Secrets, Continue is empty on the last page
+type ConfigObjectList struct {
+	Namespace string                  `json:"namespace"`
+	Kind      string                  `json:"kind"`
+	Items     []*ConfigObjectMetadata `json:"items"`
+	Continue  string                  `json:"continue,omitempty"`
+}
+
+func (options ConfigObjectListOptions) listOptions() metav1.ListOptions {
+	labelSelector := options.LabelSelector
+	if options.DevtronManagedOnly {
+		managedSelector := K8sManagedByLabelKey + "=" + DevtronManagedByLabelValue
+		if len(labelSelector) > 0 {
+			labelSelector = labelSelector + "," + managedSelector
+		} else {
+			labelSelector = managedSelector
+		}
+	}
+	limit := options.Limit
+	if limit <= 0 || limit > ConfigObjectListMaxLimit {
+		limit = ConfigObjectListMaxLimit
+	}
+	return metav1.ListOptions{LabelSelector: labelSelector, Limit: limit, Continue: options.Continue}
+}
+
+// ListConfigMaps lists a page of the ConfigMaps of the namespace with their size and age
+func (impl K8sUtil) ListConfigMaps(ctx context.Context, namespace string, clusterConfig *ClusterConfig, options ConfigObjectListOptions) (*ConfigObjectList, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, ListConfigMaps", "err", err)
+		return nil, err
+	}
+	configMaps, err := clientSet.CoreV1().ConfigMaps(namespace).List(ctx, options.listOptions())
+	if err != nil {
+		logger.Errorw("error in listing config maps", "err", err, "namespace", namespace)
+		return nil, err
+	}
+	now := time.Now()
+	list := &ConfigObjectList{Namespace: namespace, Kind: ConfigMapKind, Items: make([]*ConfigObjectMetadata, 0, len(configMaps.Items)), Continue: configMaps.Continue}
+	for i := range configMaps.Items {
+		list.Items = append(list.Items, configMapMetadata(&configMaps.Items[i], now))
+	}
+	return list, nil
+}
+
+// ListSecrets is ListConfigMaps for Secrets, the values are read to compute the size but never returned
+func (impl K8sUtil) ListSecrets(ctx context.Context, namespace string, clusterConfig *ClusterConfig, options ConfigObjectListOptions) (*ConfigObjectList, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, ListSecrets", "err", err)
+		return nil, err
+	}
+	secrets, err := clientSet.CoreV1().Secrets(namespace).List(ctx, options.listOptions())
+	if err != nil {
+		logger.Errorw("error in listing secrets", "err", err, "namespace", namespace)
+		return nil, err
+	}
+	now := time.Now()
+	list := &ConfigObjectList{Namespace: namespace, Kind: SecretKind, Items: make([]*ConfigObjectMetadata, 0, len(secrets.Items)), Continue: secrets.Continue}
+	for i := range secrets.Items {
+		list.Items = append(list.Items, secretMetadata(&secrets.Items[i], now))
+	}
+	return list, nil
+}
+
+func configMapMetadata(configMap *v1.ConfigMap, now time.Time) *ConfigObjectMetadata {
+	metadata := newConfigObjectMetadata(configMap.ObjectMeta, now)
+	for key, value := range configMap.Data {
+		metadata.KeyCount++
+		metadata.DataSizeInBytes += len(key) + len(value)
+	}
+	for key, value := range configMap.BinaryData {
+		metadata.KeyCount++
+		metadata.DataSizeInBytes += len(key) + len(value)
+	}
+	return metadata
+}
+
+func secretMetadata(secret *v1.Secret, now time.Time) *ConfigObjectMetadata {
+	metadata := newConfigObjectMetadata(secret.ObjectMeta, now)
+	metadata.Type = string(secret.Type)
+	for key, value := range secret.Data {
+		metadata.KeyCount++
+		metadata.DataSizeInBytes += len(key) + len(value)
+	}
+	return metadata
+}
+
+func newConfigObjectMetadata(objectMeta metav1.ObjectMeta, now time.Time) *ConfigObjectMetadata {
+	return &ConfigObjectMetadata{
+		Name:         objectMeta.Name,
+		Labels:       objectMeta.Labels,
+		CreatedOn:    objectMeta.CreationTimestamp.Time,
+		AgeInSeconds: int64(now.Sub(objectMeta.CreationTimestamp.Time).Seconds()),
+	}
+}
@@ -0,0 +1,51 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigObjectListOptions(t *testing.T) {
+	options := ConfigObjectListOptions{LabelSelector: "app=payments", DevtronManagedOnly: true, Continue: "token"}.listOptions()
+	assert.Equal(t, "app=payments,app.kubernetes.io/managed-by=devtron", options.LabelSelector)
+	assert.Equal(t, int64(ConfigObjectListMaxLimit), options.Limit)
+	assert.Equal(t, "token", options.Continue)
+
+	options = ConfigObjectListOptions{Limit: 50}.listOptions()
+	assert.Empty(t, options.LabelSelector)
+	assert.Equal(t, int64(50), options.Limit)
+	assert.Equal(t, int64(ConfigObjectListMaxLimit), ConfigObjectListOptions{Limit: 10000}.listOptions().Limit)
+}
+
+func TestConfigObjectMetadata(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	createdOn := metav1.NewTime(now.Add(-time.Hour))
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "payments-cm", CreationTimestamp: createdOn, Labels: map[string]string{"app": "payments"}},
+		Data:       map[string]string{"LOG_LEVEL": "debug"},
+		BinaryData: map[string][]byte{"cert": {1, 2, 3}},
+	}
+	metadata := configMapMetadata(configMap, now)
+	assert.Equal(t, 2, metadata.KeyCount)
+	assert.Equal(t, len("LOG_LEVEL")+len("debug")+len("cert")+3, metadata.DataSizeInBytes)
+	assert.Equal(t, int64(3600), metadata.AgeInSeconds)
+	assert.Empty(t, metadata.Type)
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "payments-secret", CreationTimestamp: createdOn},
+		Type:       v1.SecretTypeOpaque,
+		Data:       map[string][]byte{"DB_PASSWORD": []byte("hunter2")},
+	}
+	metadata = secretMetadata(secret, now)
+	assert.Equal(t, string(v1.SecretTypeOpaque), metadata.Type)
+	assert.Equal(t, 1, metadata.KeyCount)
+	payload, err := json.Marshal(metadata)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(payload), "hunter2")
+	assert.NotContains(t, string(payload), "DB_PASSWORD")
+}
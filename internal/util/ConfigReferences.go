@@ -0,0 +1,163 @@
+package util
+
+import (
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	"sort"
+)
+
+const (
+	ConfigMapKind = "ConfigMap"
+	SecretKind    = "Secret"
+)
+
+// ConfigReference is a workload, or a bare pod, using a ConfigMap or Secret. References describe how it is used,
+// e.g. "volume config" or "env DB_PASSWORD in container app"
+type ConfigReference struct {
+	Kind       string   `json:"kind"`
+	Namespace  string   `json:"namespace"`
+	Name       string   `json:"name"`
+	References []string `json:"references"`
+}
+
+func (r ConfigReference) String() string {
+	return fmt.Sprintf("%s/%s (%v)", r.Kind, r.Name, r.References)
+}
+
+// PodConfigUsage is a pod using a ConfigMap or Secret, References are described as in ConfigReference. OwnerKind and
+// OwnerName are the workload the pod belongs to, the ui groups pods by them
+type PodConfigUsage struct {
+	PodName    string   `json:"podName"`
+	OwnerKind  string   `json:"ownerKind"`
+	OwnerName  string   `json:"ownerName"`
+	References []string `json:"references"`
+}
+
+// SecretUsage lists the pods of the namespace referencing the secret as env source, volume or image pull secret
+type SecretUsage struct {
+	Namespace  string           `json:"namespace"`
+	SecretName string           `json:"secretName"`
+	Pods       []PodConfigUsage `json:"pods"`
+}
+
+// ConfigMapUsage lists the pods of the namespace mounting the ConfigMap or referencing it through envFrom or valueFrom
+type ConfigMapUsage struct {
+	Namespace     string           `json:"namespace"`
+	ConfigMapName string           `json:"configMapName"`
+	Pods          []PodConfigUsage `json:"pods"`
+}
+
+// podSpecConfigReferences lists how the pod spec uses the ConfigMap or Secret of kind and name, all container types,
+// projected volumes, csi node publish secrets and image pull secrets are covered
+func podSpecConfigReferences(spec *v1.PodSpec, kind string, name string) []string {
+	references := make([]string, 0)
+	matchesConfigMap := func(ref *v1.LocalObjectReference) bool {
+		return kind == ConfigMapKind && ref != nil && ref.Name == name
+	}
+	matchesSecret := func(ref *v1.LocalObjectReference) bool {
+		return kind == SecretKind && ref != nil && ref.Name == name
+	}
+	for _, volume := range spec.Volumes {
+		source := volume.VolumeSource
+		if source.ConfigMap != nil && matchesConfigMap(&source.ConfigMap.LocalObjectReference) {
+			references = append(references, "volume "+volume.Name)
+		}
+		if source.Secret != nil && kind == SecretKind && source.Secret.SecretName == name {
+			references = append(references, "volume "+volume.Name)
+		}
+		if source.Projected != nil {
+			for _, projection := range source.Projected.Sources {
+				if (projection.ConfigMap != nil && matchesConfigMap(&projection.ConfigMap.LocalObjectReference)) ||
+					(projection.Secret != nil && matchesSecret(&projection.Secret.LocalObjectReference)) {
+					references = append(references, "projected volume "+volume.Name)
+					break
+				}
+			}
+		}
+		if source.CSI != nil && matchesSecret(source.CSI.NodePublishSecretRef) {
+			references = append(references, "csi volume "+volume.Name)
+		}
+	}
+	containerReferences := func(containerType string, containerName string, envFrom []v1.EnvFromSource, env []v1.EnvVar) {
+		for _, source := range envFrom {
+			if (source.ConfigMapRef != nil && matchesConfigMap(&source.ConfigMapRef.LocalObjectReference)) ||
+				(source.SecretRef != nil && matchesSecret(&source.SecretRef.LocalObjectReference)) {
+				references = append(references, fmt.Sprintf("envFrom in %s %s", containerType, containerName))
+			}
+		}
+		for _, envVar := range env {
+			if envVar.ValueFrom == nil {
+				continue
+			}
+			if (envVar.ValueFrom.ConfigMapKeyRef != nil && matchesConfigMap(&envVar.ValueFrom.ConfigMapKeyRef.LocalObjectReference)) ||
+				(envVar.ValueFrom.SecretKeyRef != nil && matchesSecret(&envVar.ValueFrom.SecretKeyRef.LocalObjectReference)) {
+				references = append(references, fmt.Sprintf("env %s in %s %s", envVar.Name, containerType, containerName))
+			}
+		}
+	}
+	for _, container := range spec.InitContainers {
+		containerReferences("init container", container.Name, container.EnvFrom, container.Env)
+	}
+	for _, container := range spec.Containers {
+		containerReferences("container", container.Name, container.EnvFrom, container.Env)
+	}
+	for _, container := range spec.EphemeralContainers {
+		containerReferences("ephemeral container", container.Name, container.EnvFrom, container.Env)
+	}
+	for i := range spec.ImagePullSecrets {
+		if matchesSecret(&spec.ImagePullSecrets[i]) {
+			references = append(references, "imagePullSecrets")
+		}
+	}
+	return references
+}
+
+// configReferenceCollector merges the references of pods of the same workload and of the workload template
+type configReferenceCollector struct {
+	references map[string]*ConfigReference
+}
+
+func newConfigReferenceCollector() *configReferenceCollector {
+	return &configReferenceCollector{references: make(map[string]*ConfigReference)}
+}
+
+func (c *configReferenceCollector) add(kind string, namespace string, name string, references []string) {
+	if len(references) == 0 {
+		return
+	}
+	key := kind + "/" + name
+	reference, ok := c.references[key]
+	if !ok {
+		reference = &ConfigReference{Kind: kind, Namespace: namespace, Name: name}
+		c.references[key] = reference
+	}
+	for _, r := range references {
+		if !containsString(reference.References, r) {
+			reference.References = append(reference.References, r)
+		}
+	}
+}
+
+func (c *configReferenceCollector) list() []ConfigReference {
+	references := make([]ConfigReference, 0, len(c.references))
+	for _, reference := range c.references {
+		sort.Strings(reference.References)
+		references = append(references, *reference)
+	}
+	sort.Slice(references, func(i, j int) bool {
+		if references[i].Kind != references[j].Kind {
+			return references[i].Kind < references[j].Kind
+		}
+		return references[i].Name < references[j].Name
+	})
+	return references
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
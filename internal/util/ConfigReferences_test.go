@@ -0,0 +1,136 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestPodSpecConfigReferences(t *testing.T) {
+	spec := &v1.PodSpec{
+		Volumes: []v1.Volume{
+			{Name: "config", VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{LocalObjectReference: v1.LocalObjectReference{Name: "app-config"}}}},
+			{Name: "creds", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "app-secret"}}},
+			{Name: "bundle", VolumeSource: v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{Sources: []v1.VolumeProjection{
+				{ServiceAccountToken: &v1.ServiceAccountTokenProjection{Path: "token"}},
+				{ConfigMap: &v1.ConfigMapProjection{LocalObjectReference: v1.LocalObjectReference{Name: "app-config"}}},
+				{Secret: &v1.SecretProjection{LocalObjectReference: v1.LocalObjectReference{Name: "app-secret"}}},
+			}}}},
+			{Name: "vault", VolumeSource: v1.VolumeSource{CSI: &v1.CSIVolumeSource{
+				Driver:               "secrets-store.csi.k8s.io",
+				NodePublishSecretRef: &v1.LocalObjectReference{Name: "app-secret"},
+			}}},
+		},
+		InitContainers: []v1.Container{{
+			Name:    "migrate",
+			EnvFrom: []v1.EnvFromSource{{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "app-secret"}}}},
+		}},
+		Containers: []v1.Container{{
+			Name:    "app",
+			EnvFrom: []v1.EnvFromSource{{ConfigMapRef: &v1.ConfigMapEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "app-config"}}}},
+			Env: []v1.EnvVar{
+				{Name: "LOG_LEVEL", ValueFrom: &v1.EnvVarSource{ConfigMapKeyRef: &v1.ConfigMapKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "app-config"}, Key: "level"}}},
+				{Name: "DB_PASSWORD", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "app-secret"}, Key: "password"}}},
+				{Name: "PLAIN", Value: "app-config"},
+			},
+		}},
+		ImagePullSecrets: []v1.LocalObjectReference{{Name: "app-secret"}},
+	}
+	tests := []struct {
+		name     string
+		kind     string
+		refName  string
+		wantRefs []string
+	}{
+		{
+			name:     "config map",
+			kind:     ConfigMapKind,
+			refName:  "app-config",
+			wantRefs: []string{"volume config", "projected volume bundle", "envFrom in container app", "env LOG_LEVEL in container app"},
+		},
+		{
+			name:    "secret",
+			kind:    SecretKind,
+			refName: "app-secret",
+			wantRefs: []string{"volume creds", "projected volume bundle", "csi volume vault", "envFrom in init container migrate",
+				"env DB_PASSWORD in container app", "imagePullSecrets"},
+		},
+		{name: "secret with the name of the config map is not used", kind: SecretKind, refName: "app-config", wantRefs: []string{}},
+		{name: "unused config map", kind: ConfigMapKind, refName: "other", wantRefs: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantRefs, podSpecConfigReferences(spec, tt.kind, tt.refName))
+		})
+	}
+}
+
+func TestConfigReferenceCollector(t *testing.T) {
+	collector := newConfigReferenceCollector()
+	// two pods of the same deployment and its template are reported once
+	collector.add("Deployment", "demo", "web", []string{"volume config"})
+	collector.add("Deployment", "demo", "web", []string{"volume config", "env A in container app"})
+	collector.add("CronJob", "demo", "report", []string{"volume config"})
+	collector.add("StatefulSet", "demo", "db", nil)
+	assert.Equal(t, []ConfigReference{
+		{Kind: "CronJob", Namespace: "demo", Name: "report", References: []string{"volume config"}},
+		{Kind: "Deployment", Namespace: "demo", Name: "web", References: []string{"env A in container app", "volume config"}},
+	}, collector.list())
+}
+
+func TestPodTopLevelOwnerName(t *testing.T) {
+	isController := true
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		assert.Contains(t, r.Header.Get("Accept"), "as=PartialObjectMetadata")
+		if r.URL.Path != "/apis/apps/v1/namespaces/prod/replicasets/web-7d9f" {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(metav1.Status{Status: metav1.StatusFailure, Reason: metav1.StatusReasonNotFound, Code: http.StatusNotFound})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "web-7d9f", OwnerReferences: []metav1.OwnerReference{
+			{Kind: "Deployment", Name: "web", Controller: &isController},
+		}}})
+	}))
+	defer server.Close()
+	clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	assert.Nil(t, err)
+
+	pod := func(name, ownerKind, ownerName string) *v1.Pod {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"}}
+		if len(ownerKind) > 0 {
+			pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: ownerName, UID: types.UID(ownerName), Controller: &isController}}
+		}
+		return pod
+	}
+	owners := make(map[types.UID]*metav1.OwnerReference)
+	tests := []struct {
+		pod      *v1.Pod
+		wantKind string
+		wantName string
+	}{
+		{pod: pod("web-7d9f-a", "ReplicaSet", "web-7d9f"), wantKind: "Deployment", wantName: "web"},
+		{pod: pod("web-7d9f-b", "ReplicaSet", "web-7d9f"), wantKind: "Deployment", wantName: "web"},
+		{pod: pod("db-0", "StatefulSet", "db"), wantKind: "StatefulSet", wantName: "db"},
+		{pod: pod("backup-x1", "Job", "backup"), wantKind: "Job", wantName: "backup"},
+		{pod: pod("debug", "", ""), wantKind: "Pod", wantName: "debug"},
+	}
+	for _, tt := range tests {
+		kind, name, err := podTopLevelOwnerName(context.Background(), clientSet, "prod", tt.pod, owners)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.wantKind, kind, tt.pod.Name)
+		assert.Equal(t, tt.wantName, name, tt.pod.Name)
+	}
+	// pods of the same replicaset are resolved with a single call
+	assert.Equal(t, map[string]int{"/apis/apps/v1/namespaces/prod/replicasets/web-7d9f": 1, "/apis/batch/v1/namespaces/prod/jobs/backup": 1}, requests)
+}
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestContainerResourceInfos(t *testing.T) {
+	pod := &v1.Pod{Spec: v1.PodSpec{
+		InitContainers: []v1.Container{{Name: "migrate"}},
+		Containers: []v1.Container{{
+			Name: "app",
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceCPU:              resource.MustParse("250m"),
+					v1.ResourceMemory:           resource.MustParse("512Mi"),
+					v1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+				},
+				Limits: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("1"),
+					v1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+		}},
+	}}
+	resources := containerResourceInfos(pod)
+	assert.Equal(t, []ContainerResourceInfo{
+		{ContainerName: "migrate", InitContainer: true},
+		{
+			ContainerName:           "app",
+			CpuRequest:              "250m",
+			CpuLimit:                "1",
+			MemoryRequest:           "512Mi",
+			MemoryLimit:             "1Gi",
+			EphemeralStorageRequest: "1Gi",
+		},
+	}, resources)
+}
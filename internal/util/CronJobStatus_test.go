@@ -0,0 +1,49 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCronJobStatus(t *testing.T) {
+	now := time.Date(2023, 3, 10, 10, 30, 0, 0, time.UTC)
+	suspended := true
+	kolkata := "Asia/Kolkata"
+	tests := []struct {
+		name     string
+		schedule string
+		timeZone *string
+		suspend  *bool
+		wantNext *time.Time
+		wantErr  bool
+	}{
+		{name: "standard expression", schedule: "0 * * * *", wantNext: timePtr(time.Date(2023, 3, 10, 11, 0, 0, 0, time.UTC))},
+		{name: "descriptor", schedule: "@daily", wantNext: timePtr(time.Date(2023, 3, 11, 0, 0, 0, 0, time.UTC))},
+		{name: "time zone of the cronjob", schedule: "0 18 * * *", timeZone: &kolkata, wantNext: timePtr(time.Date(2023, 3, 10, 12, 30, 0, 0, time.UTC))},
+		{name: "time zone in the schedule", schedule: "TZ=Asia/Kolkata 0 18 * * *", wantNext: timePtr(time.Date(2023, 3, 10, 12, 30, 0, 0, time.UTC))},
+		{name: "suspended has no next run", schedule: "0 * * * *", suspend: &suspended},
+		{name: "invalid expression", schedule: "61 * * * *", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := newCronJobStatus(tt.schedule, tt.timeZone, tt.suspend, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.suspend != nil, status.Suspended)
+			if tt.wantNext == nil {
+				assert.Nil(t, status.NextScheduleTime)
+				return
+			}
+			assert.True(t, tt.wantNext.Equal(status.NextScheduleTime.Time), "got %s", status.NextScheduleTime.Time)
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,204 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"github.com/ghodss/yaml"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	EncryptionAtRestEnabled         = "enabled"
+	EncryptionAtRestProviderManaged = "provider-managed"
+	EncryptionAtRestNone            = "none"
+	EncryptionAtRestUnknown         = "unknown"
+
+	EncryptionSourceApiServer  = "apiserver"
+	EncryptionSourceNodeLabels = "node-labels"
+
+	KmsEnabled  = "enabled"
+	KmsDisabled = "disabled"
+	KmsUnknown  = "unknown"
+
+	encryptionProviderConfigFlag = "--encryption-provider-config"
+	encryptionProbeTimeout       = 10 * time.Second
+)
+
+// EncryptionAtRestStatus tells whether secrets written to the cluster are encrypted in etcd, Source is where the status
+// was derived from and is empty when it is unknown
+type EncryptionAtRestStatus struct {
+	Status   string `json:"status"`
+	Source   string `json:"source,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Kms      string `json:"kms"`
+}
+
+// managedProviderNodeLabels identify managed offerings which encrypt etcd at rest on their own
+var managedProviderNodeLabels = map[string]string{
+	"eks.amazonaws.com/nodegroup":    "eks",
+	"eks.amazonaws.com/compute-type": "eks",
+	"cloud.google.com/gke-nodepool":  "gke",
+	"kubernetes.azure.com/cluster":   "aks",
+}
+
+// EncryptionAtRestWarning returns the warning to attach to responses of flows storing secrets, empty if none is needed
+func EncryptionAtRestWarning(status *EncryptionAtRestStatus) string {
+	if status == nil || status.Status == EncryptionAtRestUnknown {
+		return "encryption at rest of secrets could not be determined for this cluster, values may be stored unencrypted in etcd"
+	}
+	if status.Status == EncryptionAtRestNone {
+		return "this cluster does not encrypt secrets at rest, values are stored unencrypted in etcd"
+	}
+	return ""
+}
+
+// probeEncryptionAtRest is best effort, any error including rbac denial moves on to the next source and ends in unknown
+func (impl K8sUtil) probeEncryptionAtRest(ctx context.Context, clientSet *kubernetes.Clientset) *EncryptionAtRestStatus {
+	logger := LoggerFromContext(ctx, impl.logger)
+	ctx, cancel := context.WithTimeout(ctx, encryptionProbeTimeout)
+	defer cancel()
+	status, err := probeApiServerEncryption(ctx, clientSet)
+	if err != nil {
+		logger.Debugw("unable to probe apiserver encryption configuration", "err", err)
+	} else if status != nil {
+		return status
+	}
+	nodes, err := clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		logger.Debugw("unable to list nodes for encryption probe", "err", err)
+	} else if len(nodes.Items) > 0 {
+		for label, provider := range managedProviderNodeLabels {
+			if _, ok := nodes.Items[0].Labels[label]; ok {
+				return &EncryptionAtRestStatus{Status: EncryptionAtRestProviderManaged, Source: EncryptionSourceNodeLabels, Provider: provider, Kms: KmsUnknown}
+			}
+		}
+	}
+	return &EncryptionAtRestStatus{Status: EncryptionAtRestUnknown, Kms: KmsUnknown}
+}
+
+// probeApiServerEncryption inspects the apiserver static pods, nil is returned when they are not visible, e.g. on
+// managed offerings. The EncryptionConfiguration itself is only readable when mounted from a ConfigMap or Secret
+func probeApiServerEncryption(ctx context.Context, clientSet *kubernetes.Clientset) (*EncryptionAtRestStatus, error) {
+	pods, err := clientSet.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{LabelSelector: "component=kube-apiserver"})
+	if err != nil || len(pods.Items) == 0 {
+		return nil, err
+	}
+	pod := pods.Items[0]
+	for _, container := range pod.Spec.Containers {
+		configPath := apiServerFlagValue(append(append([]string{}, container.Command...), container.Args...), encryptionProviderConfigFlag)
+		if container.Name != "kube-apiserver" && len(configPath) == 0 {
+			continue
+		}
+		if len(configPath) == 0 {
+			return &EncryptionAtRestStatus{Status: EncryptionAtRestNone, Source: EncryptionSourceApiServer, Kms: KmsDisabled}, nil
+		}
+		status := &EncryptionAtRestStatus{Status: EncryptionAtRestEnabled, Source: EncryptionSourceApiServer, Kms: KmsUnknown}
+		config, err := readMountedFile(ctx, clientSet, pod, container, configPath)
+		if err != nil || len(config) == 0 {
+			return status, err
+		}
+		secretsEncrypted, kms, err := parseEncryptionConfiguration(config)
+		if err != nil {
+			return status, err
+		}
+		if !secretsEncrypted {
+			status.Status = EncryptionAtRestNone
+		}
+		status.Kms = KmsDisabled
+		if kms {
+			status.Kms = KmsEnabled
+		}
+		return status, nil
+	}
+	return nil, nil
+}
+
+func apiServerFlagValue(args []string, flag string) string {
+	for i, arg := range args {
+		if strings.HasPrefix(arg, flag+"=") {
+			return strings.TrimPrefix(arg, flag+"=")
+		}
+		if arg == flag && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// readMountedFile reads the file from the ConfigMap or Secret volume mounted at its directory, empty if it is a host path
+func readMountedFile(ctx context.Context, clientSet *kubernetes.Clientset, pod v1.Pod, container v1.Container, filePath string) (string, error) {
+	for _, mount := range container.VolumeMounts {
+		mountPath := strings.TrimSuffix(mount.MountPath, "/")
+		if !strings.HasPrefix(filePath, mountPath+"/") {
+			continue
+		}
+		key := path.Join(mount.SubPath, strings.TrimPrefix(filePath, mountPath+"/"))
+		for _, volume := range pod.Spec.Volumes {
+			if volume.Name != mount.Name {
+				continue
+			}
+			if volume.ConfigMap != nil {
+				configMap, err := clientSet.CoreV1().ConfigMaps(pod.Namespace).Get(ctx, volume.ConfigMap.Name, metav1.GetOptions{})
+				if err != nil {
+					return "", err
+				}
+				return configMap.Data[volumeItemKey(volume.ConfigMap.Items, key)], nil
+			}
+			if volume.Secret != nil {
+				secret, err := clientSet.CoreV1().Secrets(pod.Namespace).Get(ctx, volume.Secret.SecretName, metav1.GetOptions{})
+				if err != nil {
+					return "", err
+				}
+				return string(secret.Data[volumeItemKey(volume.Secret.Items, key)]), nil
+			}
+			return "", nil
+		}
+	}
+	return "", nil
+}
+
+// volumeItemKey maps the path inside the volume back to the key of the ConfigMap or Secret
+func volumeItemKey(items []v1.KeyToPath, filePath string) string {
+	for _, item := range items {
+		if item.Path == filePath {
+			return item.Key
+		}
+	}
+	return filePath
+}
+
+type encryptionConfiguration struct {
+	Resources []struct {
+		Resources []string                 `json:"resources"`
+		Providers []map[string]interface{} `json:"providers"`
+	} `json:"resources"`
+}
+
+// parseEncryptionConfiguration returns whether new secrets are encrypted and whether that is done through kms, the
+// first provider of the first matching resource entry is the one used for writing
+func parseEncryptionConfiguration(config string) (bool, bool, error) {
+	parsed := &encryptionConfiguration{}
+	if err := yaml.Unmarshal([]byte(config), parsed); err != nil {
+		return false, false, fmt.Errorf("invalid encryption configuration: %w", err)
+	}
+	for _, entry := range parsed.Resources {
+		matches := false
+		for _, resource := range entry.Resources {
+			if resource == "secrets" || resource == "*." || resource == "*.*" {
+				matches = true
+			}
+		}
+		if !matches || len(entry.Providers) == 0 {
+			continue
+		}
+		for provider := range entry.Providers[0] {
+			return provider != "identity", provider == "kms", nil
+		}
+	}
+	return false, false, nil
+}
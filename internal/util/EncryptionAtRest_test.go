@@ -0,0 +1,77 @@
+package util
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseEncryptionConfiguration(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		encrypted bool
+		kms       bool
+	}{
+		{
+			name: "kms first",
+			config: `
+apiVersion: apiserver.config.k8s.io/v1
+kind: EncryptionConfiguration
+resources:
+  - resources: [secrets]
+    providers:
+      - kms: {name: vault, endpoint: unix:///var/run/kms.sock}
+      - identity: {}`,
+			encrypted: true,
+			kms:       true,
+		},
+		{
+			name: "aescbc for all resources",
+			config: `
+resources:
+  - resources: ["*.*"]
+    providers:
+      - aescbc: {keys: [{name: key1, secret: c2VjcmV0}]}`,
+			encrypted: true,
+		},
+		{
+			name: "identity first only reads encrypted data",
+			config: `
+resources:
+  - resources: [secrets]
+    providers:
+      - identity: {}
+      - aesgcm: {keys: [{name: key1, secret: c2VjcmV0}]}`,
+		},
+		{
+			name: "secrets not configured",
+			config: `
+resources:
+  - resources: [configmaps]
+    providers:
+      - aescbc: {keys: [{name: key1, secret: c2VjcmV0}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted, kms, err := parseEncryptionConfiguration(tt.config)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.encrypted, encrypted)
+			assert.Equal(t, tt.kms, kms)
+		})
+	}
+}
+
+func TestApiServerFlagValue(t *testing.T) {
+	args := []string{"kube-apiserver", "--secure-port=6443", "--encryption-provider-config", "/etc/kubernetes/enc/config.yaml"}
+	assert.Equal(t, "/etc/kubernetes/enc/config.yaml", apiServerFlagValue(args, encryptionProviderConfigFlag))
+	assert.Equal(t, "6443", apiServerFlagValue(args, "--secure-port"))
+	assert.Equal(t, "", apiServerFlagValue(args, "--audit-policy-file"))
+}
+
+func TestEncryptionAtRestWarning(t *testing.T) {
+	assert.NotEmpty(t, EncryptionAtRestWarning(nil))
+	assert.NotEmpty(t, EncryptionAtRestWarning(&EncryptionAtRestStatus{Status: EncryptionAtRestNone}))
+	assert.Empty(t, EncryptionAtRestWarning(&EncryptionAtRestStatus{Status: EncryptionAtRestProviderManaged}))
+	assert.Empty(t, EncryptionAtRestWarning(&EncryptionAtRestStatus{Status: EncryptionAtRestEnabled}))
+}
@@ -18,16 +18,37 @@
 package util
 
 import (
+	"errors"
 	"fmt"
 	"github.com/go-pg/pg"
 )
 
+var (
+	ErrLabelNotFound        = errors.New("label not found")
+	ErrLabelSourceInvalid   = errors.New("invalid label source")
+	ErrClusterUnreachable   = errors.New("cluster unreachable")
+	ErrSessionLimitExceeded = errors.New("session-limit-reached")
+	ErrRolloutNotFound      = errors.New("rollout not found")
+	ErrCronJobNotFound      = errors.New("cronjob not found")
+	ErrServerShuttingDown   = errors.New("server-shutting-down")
+	// ErrTerminalBudgetExceeded is returned when a user has used up the monthly terminal budget
+	ErrTerminalBudgetExceeded = errors.New("terminal-budget-exceeded")
+	// ErrPodNotControlled is returned for pods which would not be recreated after a delete
+	ErrPodNotControlled         = errors.New("pod is not owned by a controller")
+	ErrWorkloadKindNotSupported = errors.New("workload kind does not support the action")
+	// ErrPvcNotMounted is returned for pvcs which are not mounted by a pod of the app
+	ErrPvcNotMounted = errors.New("pvc is not mounted by the app")
+	// ErrClusterCircuitOpen is matched by the ClusterCircuitOpenError of calls to a cluster which keeps failing
+	ErrClusterCircuitOpen = errors.New("cluster circuit open")
+)
+
 type ApiError struct {
 	HttpStatusCode    int         `json:"-"`
 	Code              string      `json:"code,omitempty"`
 	InternalMessage   string      `json:"internalMessage,omitempty"`
 	UserMessage       interface{} `json:"userMessage,omitempty"`
 	UserDetailMessage string      `json:"userDetailMessage,omitempty"`
+	Details           []string    `json:"details,omitempty"`
 }
 
 func (e *ApiError) Error() string {
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// InflightTracker counts operations which should not be cut off midway on shutdown
+type InflightTracker struct {
+	count     int64
+	waitGroup sync.WaitGroup
+}
+
+func NewInflightTracker() *InflightTracker {
+	return &InflightTracker{}
+}
+
+// Begin marks an operation as started, the returned func must be called once it completes
+func (t *InflightTracker) Begin() func() {
+	if t == nil {
+		return func() {}
+	}
+	t.waitGroup.Add(1)
+	atomic.AddInt64(&t.count, 1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			atomic.AddInt64(&t.count, -1)
+			t.waitGroup.Done()
+		})
+	}
+}
+
+func (t *InflightTracker) Count() int64 {
+	if t == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&t.count)
+}
+
+// Wait blocks till all started operations complete or the context is done
+func (t *InflightTracker) Wait(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		t.waitGroup.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,49 @@
+package util
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestInflightTracker(t *testing.T) {
+	t.Run("wait returns once operations complete", func(t *testing.T) {
+		tracker := NewInflightTracker()
+		done := tracker.Begin()
+		nestedDone := tracker.Begin()
+		assert.Equal(t, int64(2), tracker.Count())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			nestedDone()
+			done()
+		}()
+		err := tracker.Wait(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), tracker.Count())
+	})
+	t.Run("done is idempotent", func(t *testing.T) {
+		tracker := NewInflightTracker()
+		done := tracker.Begin()
+		done()
+		done()
+		assert.Equal(t, int64(0), tracker.Count())
+		assert.Nil(t, tracker.Wait(context.Background()))
+	})
+	t.Run("wait gives up when context is done", func(t *testing.T) {
+		tracker := NewInflightTracker()
+		done := tracker.Begin()
+		defer done()
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := tracker.Wait(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, int64(1), tracker.Count())
+	})
+	t.Run("nil tracker is a no-op", func(t *testing.T) {
+		var tracker *InflightTracker
+		tracker.Begin()()
+		assert.Equal(t, int64(0), tracker.Count())
+		assert.Nil(t, tracker.Wait(context.Background()))
+	})
+}
@@ -0,0 +1,23 @@
+package util
+
+import "github.com/caarlos0/env"
+
+// JobTTLConfig caps the ttlSecondsAfterFinished of jobs created through CreateJobWithTTL so that no caller can keep
+// finished jobs and their pods in a cluster for longer than MaxTTLSecondsAfterFinished, 0 disables the cap
+type JobTTLConfig struct {
+	MaxTTLSecondsAfterFinished int `env:"JOB_MAX_TTL_SECONDS_AFTER_FINISHED" envDefault:"86400"`
+}
+
+func GetJobTTLConfig() (*JobTTLConfig, error) {
+	config := &JobTTLConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+// clampJobTTL returns the ttl to set on a job, values <= 0 are returned as is as they leave the field unset
+func (config *JobTTLConfig) clampJobTTL(ttlSecondsAfterFinished int32) int32 {
+	if config.MaxTTLSecondsAfterFinished > 0 && int(ttlSecondsAfterFinished) > config.MaxTTLSecondsAfterFinished {
+		return int32(config.MaxTTLSecondsAfterFinished)
+	}
+	return ttlSecondsAfterFinished
+}
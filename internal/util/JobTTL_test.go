@@ -0,0 +1,28 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClampJobTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxTTL  int
+		ttl     int32
+		wantTTL int32
+	}{
+		{name: "below max", maxTTL: 3600, ttl: 600, wantTTL: 600},
+		{name: "at max", maxTTL: 3600, ttl: 3600, wantTTL: 3600},
+		{name: "above max", maxTTL: 3600, ttl: 7 * 24 * 3600, wantTTL: 3600},
+		{name: "unset ttl", maxTTL: 3600, ttl: 0, wantTTL: 0},
+		{name: "cap disabled", maxTTL: 0, ttl: 7 * 24 * 3600, wantTTL: 7 * 24 * 3600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &JobTTLConfig{MaxTTLSecondsAfterFinished: tt.maxTTL}
+			assert.Equal(t, tt.wantTTL, config.clampJobTTL(tt.ttl))
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	JsonPatchOpAdd     = "add"
+	JsonPatchOpRemove  = "remove"
+	JsonPatchOpReplace = "replace"
+	JsonPatchOpTest    = "test"
+	JsonPatchOpCopy    = "copy"
+	JsonPatchOpMove    = "move"
+)
+
+var ErrInvalidJsonPatch = errors.New("invalid json patch")
+
+// JsonPatchOperation is a single RFC 6902 operation, paths are RFC 6901 json pointers
+type JsonPatchOperation struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+}
+
+// MarshalJSON always writes value for the operations which need one, so that nil, false and 0 are kept
+func (o JsonPatchOperation) MarshalJSON() ([]byte, error) {
+	operation := map[string]interface{}{"op": o.Op, "path": o.Path}
+	switch o.Op {
+	case JsonPatchOpAdd, JsonPatchOpReplace, JsonPatchOpTest:
+		operation["value"] = o.Value
+	case JsonPatchOpCopy, JsonPatchOpMove:
+		operation["from"] = o.From
+	}
+	return json.Marshal(operation)
+}
+
+// JsonPatch builds a json patch applied atomically by the api server, paths are expected to be escaped already,
+// use JsonPointer to build them from keys which can contain / or ~ such as config map keys
+type JsonPatch struct {
+	operations []JsonPatchOperation
+}
+
+func NewJsonPatch() *JsonPatch {
+	return &JsonPatch{}
+}
+
+func (p *JsonPatch) Add(path string, value interface{}) *JsonPatch {
+	return p.append(JsonPatchOperation{Op: JsonPatchOpAdd, Path: path, Value: value})
+}
+
+func (p *JsonPatch) Remove(path string) *JsonPatch {
+	return p.append(JsonPatchOperation{Op: JsonPatchOpRemove, Path: path})
+}
+
+func (p *JsonPatch) Replace(path string, value interface{}) *JsonPatch {
+	return p.append(JsonPatchOperation{Op: JsonPatchOpReplace, Path: path, Value: value})
+}
+
+// Test makes the whole patch fail if the value at path differs, it is used to guard against concurrent writers
+func (p *JsonPatch) Test(path string, value interface{}) *JsonPatch {
+	return p.append(JsonPatchOperation{Op: JsonPatchOpTest, Path: path, Value: value})
+}
+
+func (p *JsonPatch) Copy(from string, path string) *JsonPatch {
+	return p.append(JsonPatchOperation{Op: JsonPatchOpCopy, From: from, Path: path})
+}
+
+func (p *JsonPatch) Move(from string, path string) *JsonPatch {
+	return p.append(JsonPatchOperation{Op: JsonPatchOpMove, From: from, Path: path})
+}
+
+func (p *JsonPatch) append(operation JsonPatchOperation) *JsonPatch {
+	p.operations = append(p.operations, operation)
+	return p
+}
+
+func (p *JsonPatch) Operations() []JsonPatchOperation {
+	return p.operations
+}
+
+// Validate checks the patch before it is sent so that malformed pointers are reported with the offending operation
+// instead of a generic api server error
+func (p *JsonPatch) Validate() error {
+	if p == nil || len(p.operations) == 0 {
+		return fmt.Errorf("%w: no operations", ErrInvalidJsonPatch)
+	}
+	for i, operation := range p.operations {
+		if err := validateJsonPointer(operation.Path); err != nil {
+			return fmt.Errorf("%w: operation %d (%s) path: %v", ErrInvalidJsonPatch, i, operation.Op, err)
+		}
+		switch operation.Op {
+		case JsonPatchOpAdd, JsonPatchOpRemove, JsonPatchOpReplace, JsonPatchOpTest:
+		case JsonPatchOpCopy, JsonPatchOpMove:
+			if err := validateJsonPointer(operation.From); err != nil {
+				return fmt.Errorf("%w: operation %d (%s) from: %v", ErrInvalidJsonPatch, i, operation.Op, err)
+			}
+			if operation.Op == JsonPatchOpMove && strings.HasPrefix(operation.Path, operation.From+"/") {
+				return fmt.Errorf("%w: operation %d (move) cannot move %q into its own child %q", ErrInvalidJsonPatch, i, operation.From, operation.Path)
+			}
+		default:
+			return fmt.Errorf("%w: operation %d has unknown op %q", ErrInvalidJsonPatch, i, operation.Op)
+		}
+		if operation.Op == JsonPatchOpRemove && operation.Path == "" {
+			return fmt.Errorf("%w: operation %d (remove) cannot remove the whole document", ErrInvalidJsonPatch, i)
+		}
+	}
+	return nil
+}
+
+func (p *JsonPatch) MarshalJSON() ([]byte, error) {
+	if p.operations == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p.operations)
+}
+
+// EscapeJsonPointerToken escapes ~ and / in a single reference token as per RFC 6901
+func EscapeJsonPointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// JsonPointer builds a pointer from unescaped tokens, e.g. JsonPointer("data", "app.properties")
+func JsonPointer(tokens ...string) string {
+	var pointer strings.Builder
+	for _, token := range tokens {
+		pointer.WriteString("/")
+		pointer.WriteString(EscapeJsonPointerToken(token))
+	}
+	return pointer.String()
+}
+
+func validateJsonPointer(pointer string) error {
+	if pointer == "" {
+		return nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return fmt.Errorf("%q must start with /", pointer)
+	}
+	for i := 0; i < len(pointer); i++ {
+		if pointer[i] != '~' {
+			continue
+		}
+		if i+1 == len(pointer) || (pointer[i+1] != '0' && pointer[i+1] != '1') {
+			return fmt.Errorf("%q has ~ not followed by 0 or 1, use JsonPointer to escape keys", pointer)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestJsonPointer(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []string
+		want   string
+	}{
+		{name: "plain key", tokens: []string{"data", "config"}, want: "/data/config"},
+		{name: "dotted key is left as is", tokens: []string{"data", "app.properties"}, want: "/data/app.properties"},
+		{name: "slashed key", tokens: []string{"data", "nginx/conf.d"}, want: "/data/nginx~1conf.d"},
+		{name: "tilde key", tokens: []string{"data", "~backup"}, want: "/data/~0backup"},
+		{name: "tilde is escaped before slash", tokens: []string{"data", "a~/b"}, want: "/data/a~0~1b"},
+		{name: "no tokens is the whole document", tokens: nil, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pointer := JsonPointer(tt.tokens...)
+			assert.Equal(t, tt.want, pointer)
+			assert.Nil(t, validateJsonPointer(pointer))
+		})
+	}
+}
+
+func TestJsonPatch_MarshalJSON(t *testing.T) {
+	patch := NewJsonPatch().
+		Test(JsonPointer("metadata", "resourceVersion"), "42").
+		Replace(JsonPointer("data", "nginx/conf.d"), "server {}").
+		Add(JsonPointer("data", "enabled"), false).
+		Remove(JsonPointer("data", "app.properties")).
+		Copy(JsonPointer("data", "a"), JsonPointer("data", "b")).
+		Move(JsonPointer("data", "c"), JsonPointer("data", "d"))
+	assert.Nil(t, patch.Validate())
+	b, err := json.Marshal(patch)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[
+		{"op":"test","path":"/metadata/resourceVersion","value":"42"},
+		{"op":"replace","path":"/data/nginx~1conf.d","value":"server {}"},
+		{"op":"add","path":"/data/enabled","value":false},
+		{"op":"remove","path":"/data/app.properties"},
+		{"op":"copy","from":"/data/a","path":"/data/b"},
+		{"op":"move","from":"/data/c","path":"/data/d"}
+	]`, string(b))
+}
+
+func TestJsonPatch_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch *JsonPatch
+	}{
+		{name: "empty patch", patch: NewJsonPatch()},
+		{name: "unescaped tilde", patch: NewJsonPatch().Replace("/data/~backup", "x")},
+		{name: "path without leading slash", patch: NewJsonPatch().Add("data/key", "x")},
+		{name: "invalid from", patch: NewJsonPatch().Copy("data", "/data/b")},
+		{name: "move into own child", patch: NewJsonPatch().Move("/data", "/data/child")},
+		{name: "remove whole document", patch: NewJsonPatch().Remove("")},
+		{name: "unknown op", patch: &JsonPatch{operations: []JsonPatchOperation{{Op: "merge", Path: "/data"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.patch.Validate()
+			assert.True(t, errors.Is(err, ErrInvalidJsonPatch), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type K8sErrorClass string
+
+const (
+	K8sErrorNone            K8sErrorClass = ""
+	K8sErrorNotFound        K8sErrorClass = "NotFound"
+	K8sErrorUnauthorized    K8sErrorClass = "Unauthorized"
+	K8sErrorForbidden       K8sErrorClass = "Forbidden"
+	K8sErrorConflict        K8sErrorClass = "Conflict"
+	K8sErrorInvalid         K8sErrorClass = "Invalid"
+	K8sErrorTooManyRequests K8sErrorClass = "TooManyRequests"
+	K8sErrorUnreachable     K8sErrorClass = "Unreachable"
+	K8sErrorUnknown         K8sErrorClass = "Unknown"
+)
+
+// ClassifyK8sError maps errors returned by the kubernetes clients to a small set of classes which callers can
+// translate to api responses, K8sErrorNone is returned for errors which do not come from a kubernetes api call
+func ClassifyK8sError(err error) K8sErrorClass {
+	if err == nil {
+		return K8sErrorNone
+	}
+	if errors.Is(err, ErrClusterUnreachable) {
+		return K8sErrorUnreachable
+	}
+	var statusErr k8sErrors.APIStatus
+	if errors.As(err, &statusErr) {
+		switch {
+		case k8sErrors.IsNotFound(err), k8sErrors.IsGone(err):
+			return K8sErrorNotFound
+		case k8sErrors.IsUnauthorized(err):
+			return K8sErrorUnauthorized
+		case k8sErrors.IsForbidden(err):
+			return K8sErrorForbidden
+		case k8sErrors.IsAlreadyExists(err), k8sErrors.IsConflict(err):
+			return K8sErrorConflict
+		case k8sErrors.IsInvalid(err), k8sErrors.IsBadRequest(err):
+			return K8sErrorInvalid
+		case k8sErrors.IsTooManyRequests(err):
+			return K8sErrorTooManyRequests
+		case k8sErrors.IsTimeout(err), k8sErrors.IsServerTimeout(err), k8sErrors.IsServiceUnavailable(err):
+			return K8sErrorUnreachable
+		}
+		return K8sErrorUnknown
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) {
+		return K8sErrorUnreachable
+	}
+	return K8sErrorNone
+}
+
+// NewKindNotServedError is returned for kinds which discovery does not list, it is a kubernetes not found error so that
+// an unknown kind is reported like a missing object
+func NewKindNotServedError(gvk schema.GroupVersionKind) error {
+	return &k8sErrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusNotFound,
+		Reason:  metav1.StatusReasonNotFound,
+		Message: fmt.Sprintf("kind %s is not served by the cluster", gvk.String()),
+	}}
+}
@@ -18,6 +18,7 @@
 package util
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	error2 "errors"
@@ -25,38 +26,73 @@ import (
 	"fmt"
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"io"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"net/http"
 	"os/user"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/devtron-labs/authenticator/client"
+	"github.com/devtron-labs/devtron/util"
 	"github.com/ghodss/yaml"
+	"github.com/patrickmn/go-cache"
+	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
+	appsV1 "k8s.io/api/apps/v1"
+	authorizationV1 "k8s.io/api/authorization/v1"
 	batchV1 "k8s.io/api/batch/v1"
+	batchV1beta1 "k8s.io/api/batch/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	networkingV1 "k8s.io/api/networking/v1"
+	policyV1 "k8s.io/api/policy/v1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	v12 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 type K8sUtil struct {
-	logger        *zap.SugaredLogger
-	runTimeConfig *client.RuntimeConfig
-	kubeconfig    *string
+	logger           *zap.SugaredLogger
+	runTimeConfig    *client.RuntimeConfig
+	kubeconfig       *string
+	clusterInfoCache *cache.Cache
+	// inflightMutations tracks calls changing cluster state so that shutdown can wait for them
+	inflightMutations *InflightTracker
+	requestIdConfig   *RequestIdConfig
+	// manifestMutators are applied on jobs and other manifests rendered by devtron before they are created
+	manifestMutators       *ManifestMutatorChain
+	manifestMutationConfig *ManifestMutationConfig
+	podPlacement           *podPlacementRegistry
+	mutationGuard          *mutationGuardRegistry
+	namespacePolicy        *namespacePolicyRegistry
+	tunables               *TunableConfigStore
+	circuitBreakers        *clusterCircuitBreakers
+	jobTTLConfig           *JobTTLConfig
 }
 
 type ClusterConfig struct {
 	Host        string
 	BearerToken string
+	// ClusterId is set for configs of clusters added to devtron, it is 0 for configs built from a server url only
+	ClusterId int
 }
 
 func NewK8sUtil(logger *zap.SugaredLogger, runTimeConfig *client.RuntimeConfig) *K8sUtil {
@@ -70,7 +106,198 @@ func NewK8sUtil(logger *zap.SugaredLogger, runTimeConfig *client.RuntimeConfig)
 	}
 
 	flag.Parse()
-	return &K8sUtil{logger: logger, runTimeConfig: runTimeConfig, kubeconfig: kubeconfig}
+	requestIdConfig, err := GetRequestIdConfig()
+	if err != nil {
+		logger.Errorw("error in parsing request id config, resources will not be annotated", "err", err)
+		requestIdConfig = &RequestIdConfig{}
+	}
+	manifestMutationConfig, err := GetManifestMutationConfig()
+	if err != nil {
+		logger.Errorw("error in parsing manifest mutation config, using defaults", "err", err)
+		manifestMutationConfig = &ManifestMutationConfig{}
+	}
+	tunables, err := NewTunableConfigStore()
+	if err != nil {
+		logger.Errorw("error in parsing tunables, using defaults for the invalid values", "err", err)
+	}
+	circuitBreakerConfig, err := GetClusterCircuitBreakerConfig()
+	if err != nil {
+		logger.Errorw("error in parsing cluster circuit breaker config, calls to clusters are not guarded", "err", err)
+		circuitBreakerConfig = &ClusterCircuitBreakerConfig{}
+	}
+	jobTTLConfig, err := GetJobTTLConfig()
+	if err != nil {
+		logger.Errorw("error in parsing job ttl config, ttl of jobs is not capped", "err", err)
+		jobTTLConfig = &JobTTLConfig{}
+	}
+	k8sUtil := &K8sUtil{logger: logger, runTimeConfig: runTimeConfig, kubeconfig: kubeconfig,
+		clusterInfoCache: cache.New(ClusterInfoCacheExpiry, 2*ClusterInfoCacheExpiry), inflightMutations: NewInflightTracker(),
+		requestIdConfig: requestIdConfig, manifestMutators: NewManifestMutatorChain(manifestMutationConfig.DisabledMutators),
+		manifestMutationConfig: manifestMutationConfig, podPlacement: &podPlacementRegistry{},
+		mutationGuard: &mutationGuardRegistry{}, namespacePolicy: &namespacePolicyRegistry{}, tunables: tunables,
+		circuitBreakers: newClusterCircuitBreakers(circuitBreakerConfig), jobTTLConfig: jobTTLConfig}
+	k8sUtil.RegisterManifestMutator(NewManifestDefaultsMutator(k8sUtil.loadManifestDefaults))
+	if err = k8sUtil.watchTunableConfigMap(); err != nil {
+		logger.Errorw("error in watching tunables config map, env values are used", "err", err)
+	}
+	return k8sUtil
+}
+
+// RegisterManifestMutator adds the mutator at the end of the chain applied on manifests rendered by devtron
+func (impl K8sUtil) RegisterManifestMutator(mutator ManifestMutator) {
+	impl.manifestMutators.Register(mutator)
+}
+
+// MutateManifest applies the manifest mutator chain on the object
+func (impl K8sUtil) MutateManifest(object *unstructured.Unstructured) error {
+	return impl.manifestMutators.Apply(object)
+}
+
+// MutateManifestJson is MutateManifest for a json or yaml manifest, the mutated manifest is returned as json
+func (impl K8sUtil) MutateManifestJson(manifest string) (string, error) {
+	object := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(manifest), &object.Object); err != nil {
+		return "", err
+	}
+	if err := impl.MutateManifest(object); err != nil {
+		return "", err
+	}
+	mutated, err := json.Marshal(object.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(mutated), nil
+}
+
+// SetPodPlacementResolver sets where the placement policies of jobs and terminal pods are resolved from
+func (impl K8sUtil) SetPodPlacementResolver(resolver PodPlacementResolver) {
+	impl.podPlacement.set(resolver)
+}
+
+// SetMutationGuard sets the guard consulted by CheckMutation
+func (impl K8sUtil) SetMutationGuard(guard MutationGuard) {
+	impl.mutationGuard.set(guard)
+}
+
+// SetNamespacePolicyEnforcer sets the enforcer consulted by CheckNamespaceAccess
+func (impl K8sUtil) SetNamespacePolicyEnforcer(enforcer NamespacePolicyEnforcer) {
+	impl.namespacePolicy.set(enforcer)
+}
+
+// CheckNamespaceAccess checks the call against the namespace policy of the cluster, configs of clusters not added to
+// devtron are not checked
+func (impl K8sUtil) CheckNamespaceAccess(ctx context.Context, clusterConfig *ClusterConfig, namespace string, mutating bool) error {
+	enforcer := impl.namespacePolicy.get()
+	if enforcer == nil || clusterConfig == nil || clusterConfig.ClusterId == 0 {
+		return nil
+	}
+	return enforcer.CheckNamespaceAccess(ctx, clusterConfig.ClusterId, namespace, mutating)
+}
+
+// CheckMutation checks the change against the namespace policy of the cluster and asks the mutation guard whether it
+// may be made, the user is taken from the context. Configs of clusters not added to devtron are not checked
+func (impl K8sUtil) CheckMutation(ctx context.Context, clusterConfig *ClusterConfig, namespace, kind, name, action string) error {
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return err
+	}
+	guard := impl.mutationGuard.get()
+	if guard == nil || clusterConfig == nil || clusterConfig.ClusterId == 0 {
+		return nil
+	}
+	return guard.CheckMutation(ctx, &ClusterMutation{ClusterId: clusterConfig.ClusterId, Namespace: namespace, Kind: kind, Name: name,
+		Action: action, UserId: UserIdFromContext(ctx)})
+}
+
+// resolvePodPlacement returns nil for configs of clusters not added to devtron and when no resolver is set
+func (impl K8sUtil) resolvePodPlacement(clusterId int, namespace string) (*PodPlacement, error) {
+	resolver := impl.podPlacement.get()
+	if resolver == nil || clusterId == 0 {
+		return nil, nil
+	}
+	return resolver.ResolvePodPlacement(clusterId, namespace)
+}
+
+// ApplyPodPlacementJson applies the placement policy of the cluster and namespace the manifest is created in, the
+// manifest is returned as json
+func (impl K8sUtil) ApplyPodPlacementJson(clusterId int, namespace string, manifest string) (string, error) {
+	object := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(manifest), &object.Object); err != nil {
+		return "", err
+	}
+	placement, err := impl.resolvePodPlacement(clusterId, namespace)
+	if err != nil {
+		return "", err
+	}
+	if err = ApplyPodPlacement(object, placement); err != nil {
+		return "", err
+	}
+	applied, err := json.Marshal(object.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(applied), nil
+}
+
+// mutateJob applies the placement before the mutator chain so that mutators see the final spec
+func (impl K8sUtil) mutateJob(job *batchV1.Job, placement *PodPlacement) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(job)
+	if err != nil {
+		return err
+	}
+	object := &unstructured.Unstructured{Object: content}
+	// typed objects built in code usually leave TypeMeta empty
+	object.SetAPIVersion("batch/v1")
+	object.SetKind("Job")
+	if err = ApplyPodPlacement(object, placement); err != nil {
+		return err
+	}
+	if err = impl.MutateManifest(object); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, job)
+}
+
+// loadManifestDefaults reads the defaults ConfigMap from the cluster devtron runs in, a missing ConfigMap means no
+// defaults. The result is cached for a minute so that creating jobs does not read the ConfigMap each time
+func (impl K8sUtil) loadManifestDefaults() (*ManifestDefaults, error) {
+	config := impl.manifestMutationConfig
+	cacheKey := "manifest-defaults/" + config.DefaultsConfigMapNamespace + "/" + config.DefaultsConfigMapName
+	if defaults, found := impl.clusterInfoCache.Get(cacheKey); found {
+		return defaults.(*ManifestDefaults), nil
+	}
+	client, err := impl.GetClientForInCluster()
+	if err != nil {
+		return nil, err
+	}
+	var defaults *ManifestDefaults
+	configMap, err := impl.GetConfigMap(config.DefaultsConfigMapNamespace, config.DefaultsConfigMapName, client)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	} else if err == nil {
+		defaults, err = ParseManifestDefaults(configMap.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	impl.clusterInfoCache.Set(cacheKey, defaults, time.Minute)
+	return defaults, nil
+}
+
+// annotateWithRequestId stamps the request id on resources created by us when enabled, so that they can be traced
+// back to the request in orchestrator logs
+func (impl K8sUtil) annotateWithRequestId(ctx context.Context, object metav1.Object) {
+	if impl.requestIdConfig != nil && impl.requestIdConfig.AnnotateResources {
+		AnnotateWithRequestId(ctx, object)
+	}
+}
+
+func (impl K8sUtil) InflightMutationCount() int64 {
+	return impl.inflightMutations.Count()
+}
+
+// WaitForInflightMutations blocks till the mutating calls in progress complete or the context is done
+func (impl K8sUtil) WaitForInflightMutations(ctx context.Context) error {
+	return impl.inflightMutations.Wait(ctx)
 }
 
 func (impl K8sUtil) GetClient(clusterConfig *ClusterConfig) (*v12.CoreV1Client, error) {
@@ -78,6 +305,8 @@ func (impl K8sUtil) GetClient(clusterConfig *ClusterConfig) (*v12.CoreV1Client,
 	cfg.Host = clusterConfig.Host
 	cfg.BearerToken = clusterConfig.BearerToken
 	cfg.Insecure = true
+	impl.applyClientTunables(cfg)
+	impl.circuitBreakers.wrap(cfg, clusterConfig.ClusterId)
 	httpClient, err := OverrideK8sHttpClientWithTracer(cfg)
 	if err != nil {
 		return nil, err
@@ -91,6 +320,8 @@ func (impl K8sUtil) GetClientSet(clusterConfig *ClusterConfig) (*kubernetes.Clie
 	cfg.Host = clusterConfig.Host
 	cfg.BearerToken = clusterConfig.BearerToken
 	cfg.Insecure = true
+	impl.applyClientTunables(cfg)
+	impl.circuitBreakers.wrap(cfg, clusterConfig.ClusterId)
 	httpClient, err := OverrideK8sHttpClientWithTracer(cfg)
 	if err != nil {
 		return nil, err
@@ -99,6 +330,20 @@ func (impl K8sUtil) GetClientSet(clusterConfig *ClusterConfig) (*kubernetes.Clie
 	return client, err
 }
 
+func (impl K8sUtil) GetDynamicClient(clusterConfig *ClusterConfig) (dynamic.Interface, error) {
+	cfg := &rest.Config{}
+	cfg.Host = clusterConfig.Host
+	cfg.BearerToken = clusterConfig.BearerToken
+	cfg.Insecure = true
+	impl.applyClientTunables(cfg)
+	impl.circuitBreakers.wrap(cfg, clusterConfig.ClusterId)
+	httpClient, err := OverrideK8sHttpClientWithTracer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfigAndClient(cfg, httpClient)
+}
+
 func (impl K8sUtil) getKubeConfig(devMode client.LocalDevMode) (*rest.Config, error) {
 	if devMode {
 		restConfig, err := clientcmd.BuildConfigFromFlags("", *impl.kubeconfig)
@@ -115,6 +360,31 @@ func (impl K8sUtil) getKubeConfig(devMode client.LocalDevMode) (*rest.Config, er
 	}
 }
 
+// ListKubeconfigClusters lists the contexts of the kubeconfig sorted by name for the cluster selector of local dev mode,
+// the kubeconfig devtron was started with is read when kubeconfigPath is empty
+func (impl K8sUtil) ListKubeconfigClusters(kubeconfigPath string) ([]KubeconfigCluster, error) {
+	if len(kubeconfigPath) == 0 && impl.kubeconfig != nil {
+		kubeconfigPath = *impl.kubeconfig
+	}
+	config, err := clientcmd.LoadFromFile(kubeconfigPath)
+	if err != nil {
+		impl.logger.Errorw("error in loading kubeconfig", "path", kubeconfigPath, "err", err)
+		return nil, err
+	}
+	clusters := make([]KubeconfigCluster, 0, len(config.Contexts))
+	for name, context := range config.Contexts {
+		cluster := KubeconfigCluster{ContextName: name, Current: name == config.CurrentContext}
+		if clusterInfo, ok := config.Clusters[context.Cluster]; ok {
+			cluster.Server = clusterInfo.Server
+		}
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].ContextName < clusters[j].ContextName
+	})
+	return clusters, nil
+}
+
 func (impl K8sUtil) GetClientForInCluster() (*v12.CoreV1Client, error) {
 	// creates the in-cluster config
 	config, err := impl.getKubeConfig(impl.runTimeConfig.LocalDevMode)
@@ -160,6 +430,8 @@ func (impl K8sUtil) GetK8sDiscoveryClient(clusterConfig *ClusterConfig) (*discov
 	cfg.Host = clusterConfig.Host
 	cfg.BearerToken = clusterConfig.BearerToken
 	cfg.Insecure = true
+	impl.applyClientTunables(cfg)
+	impl.circuitBreakers.wrap(cfg, clusterConfig.ClusterId)
 	httpClient, err := OverrideK8sHttpClientWithTracer(cfg)
 	if err != nil {
 		return nil, err
@@ -198,12 +470,13 @@ func (impl K8sUtil) GetK8sDiscoveryClientInCluster() (*discovery.DiscoveryClient
 }
 
 func (impl K8sUtil) CreateNsIfNotExists(namespace string, clusterConfig *ClusterConfig) (err error) {
+	defer impl.inflightMutations.Begin()()
 	client, err := impl.GetClient(clusterConfig)
 	if err != nil {
 		impl.logger.Errorw("error", "error", err, "clusterConfig", clusterConfig)
 		return err
 	}
-	exists, err := impl.checkIfNsExists(namespace, client)
+	exists, err := impl.CheckNamespaceExists(context.Background(), namespace, client)
 	if err != nil {
 		impl.logger.Errorw("error", "error", err, "clusterConfig", clusterConfig)
 		return err
@@ -211,14 +484,61 @@ func (impl K8sUtil) CreateNsIfNotExists(namespace string, clusterConfig *Cluster
 	if exists {
 		return nil
 	}
+	if err = impl.CheckNamespaceAccess(context.Background(), clusterConfig, namespace, true); err != nil {
+		return err
+	}
 	impl.logger.Infow("ns not exists creating", "ns", namespace)
 	_, err = impl.createNs(namespace, client)
 	return err
 }
 
-func (impl K8sUtil) checkIfNsExists(namespace string, client *v12.CoreV1Client) (exists bool, err error) {
-	ns, err := client.Namespaces().Get(context.Background(), namespace, metav1.GetOptions{})
-	//ns, err := impl.k8sClient.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{})
+// GetNamespaceOwnership tells whether the namespace does not exist, exists with the devtron management label or exists
+// without it
+func (impl K8sUtil) GetNamespaceOwnership(ctx context.Context, namespace string, clusterConfig *ClusterConfig) (string, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetNamespaceOwnership", "err", err)
+		return "", err
+	}
+	ns, err := clientSet.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return NamespaceNotFound, nil
+	} else if err != nil {
+		logger.Errorw("error in getting namespace", "err", err, "namespace", namespace)
+		return "", err
+	}
+	if ns.Labels[K8sManagedByLabelKey] == DevtronManagedByLabelValue {
+		return NamespaceManaged, nil
+	}
+	return NamespaceUnmanaged, nil
+}
+
+// CreateManagedNamespace creates the namespace with the devtron management label, a namespace created in between by
+// someone else is left as it is
+func (impl K8sUtil) CreateManagedNamespace(ctx context.Context, namespace string, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, CreateManagedNamespace", "err", err)
+		return err
+	}
+	nsSpec := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: map[string]string{K8sManagedByLabelKey: DevtronManagedByLabelValue}}}
+	_, err = clientSet.CoreV1().Namespaces().Create(ctx, nsSpec, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		logger.Errorw("error in creating namespace", "err", err, "namespace", namespace)
+		return err
+	}
+	return nil
+}
+
+// CheckNamespaceExists only looks the namespace up, use CreateNsIfNotExists to create it as well
+func (impl K8sUtil) CheckNamespaceExists(ctx context.Context, namespace string, client *v12.CoreV1Client) (bool, error) {
+	ns, err := client.Namespaces().Get(ctx, namespace, metav1.GetOptions{})
 	impl.logger.Debugw("ns fetch", "name", namespace, "res", ns)
 	if errors.IsNotFound(err) {
 		return false, nil
@@ -227,7 +547,10 @@ func (impl K8sUtil) checkIfNsExists(namespace string, client *v12.CoreV1Client)
 	} else {
 		return true, nil
 	}
+}
 
+func (impl K8sUtil) checkIfNsExists(namespace string, client *v12.CoreV1Client) (exists bool, err error) {
+	return impl.CheckNamespaceExists(context.Background(), namespace, client)
 }
 
 func (impl K8sUtil) createNs(namespace string, client *v12.CoreV1Client) (ns *v1.Namespace, err error) {
@@ -245,6 +568,139 @@ func (impl K8sUtil) deleteNs(namespace string, client *v12.CoreV1Client) error {
 	return err
 }
 
+// DeleteNamespaceIfEmpty deletes the namespace only if no pod is running in it and every resource left in it is either
+// created by kubernetes itself or managed by devtron, deleted is false when running pods or user owned resources are
+// found or the namespace does not exist
+func (impl K8sUtil) DeleteNamespaceIfEmpty(ctx context.Context, namespace string, clusterConfig *ClusterConfig) (deleted bool, err error) {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return false, err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	client, err := impl.GetClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting k8s client", "err", err, "namespace", namespace)
+		return false, err
+	}
+	exists, err := impl.CheckNamespaceExists(ctx, namespace, client)
+	if err != nil {
+		logger.Errorw("error in checking namespace", "err", err, "namespace", namespace)
+		return false, err
+	}
+	if !exists {
+		return false, nil
+	}
+	running, err := impl.getRunningPodNames(ctx, namespace, client)
+	if err != nil {
+		logger.Errorw("error in listing namespace pods", "err", err, "namespace", namespace)
+		return false, err
+	}
+	if len(running) > 0 {
+		logger.Infow("skipping namespace delete, running pods found", "namespace", namespace, "pods", running)
+		return false, nil
+	}
+	leftovers, err := impl.getUnmanagedNamespaceResources(ctx, namespace, clusterConfig)
+	if err != nil {
+		logger.Errorw("error in listing namespace resources", "err", err, "namespace", namespace)
+		return false, err
+	}
+	if len(leftovers) > 0 {
+		logger.Infow("skipping namespace delete, unmanaged resources found", "namespace", namespace, "resources", leftovers)
+		return false, nil
+	}
+	err = client.Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		logger.Errorw("error in deleting namespace", "err", err, "namespace", namespace)
+		return false, err
+	}
+	return true, nil
+}
+
+// getRunningPodNames returns the pods of the namespace which are neither completed nor being deleted, devtron managed
+// workloads included
+func (impl K8sUtil) getRunningPodNames(ctx context.Context, namespace string, client *v12.CoreV1Client) ([]string, error) {
+	pods, err := client.Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var running []string
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		running = append(running, pod.Name)
+	}
+	return running, nil
+}
+
+// getUnmanagedNamespaceResources returns <resource>/<name> of every top level object in the namespace which is neither
+// a kubernetes default nor devtron managed
+func (impl K8sUtil) getUnmanagedNamespaceResources(ctx context.Context, namespace string, clusterConfig *ClusterConfig) ([]string, error) {
+	discoveryClient, err := impl.GetK8sDiscoveryClient(clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	resourceLists, err := discoveryClient.ServerPreferredNamespacedResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	var leftovers []string
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, apiResource := range resourceList.APIResources {
+			if NamespaceCleanupIgnoredResources[apiResource.Name] || !util.ContainsString(apiResource.Verbs, "list") {
+				continue
+			}
+			objects, err := dynamicClient.Resource(gv.WithResource(apiResource.Name)).Namespace(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				if errors.IsNotFound(err) || errors.IsMethodNotSupported(err) {
+					continue
+				}
+				return nil, err
+			}
+			for _, object := range objects.Items {
+				if !isNamespaceCleanupSafe(apiResource.Name, object) {
+					leftovers = append(leftovers, apiResource.Name+"/"+object.GetName())
+				}
+			}
+		}
+	}
+	return leftovers, nil
+}
+
+// isNamespaceCleanupSafe tells whether the object can be removed along with its namespace, owned objects are decided
+// by their owners so only top level objects are inspected
+func isNamespaceCleanupSafe(resource string, object unstructured.Unstructured) bool {
+	if len(object.GetOwnerReferences()) > 0 {
+		return true
+	}
+	switch {
+	case resource == "serviceaccounts" && object.GetName() == K8sDefaultServiceAccountName:
+		return true
+	case resource == "configmaps" && object.GetName() == K8sRootCAConfigMapName:
+		return true
+	case resource == "secrets" && object.GetAnnotations()[v1.ServiceAccountNameKey] == K8sDefaultServiceAccountName:
+		return true
+	}
+	labels := object.GetLabels()
+	if _, ok := labels[DevtronAppIdLabelKey]; ok {
+		if _, ok := labels[DevtronEnvIdLabelKey]; ok {
+			return true
+		}
+	}
+	// managed-by=Helm is set by every helm release, only objects labelled by devtron itself are safe to remove
+	return labels[K8sManagedByLabelKey] == DevtronManagedByLabelValue
+}
+
 func (impl K8sUtil) GetConfigMap(namespace string, name string, client *v12.CoreV1Client) (*v1.ConfigMap, error) {
 	cm, err := client.ConfigMaps(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
@@ -255,6 +711,7 @@ func (impl K8sUtil) GetConfigMap(namespace string, name string, client *v12.Core
 }
 
 func (impl K8sUtil) CreateConfigMap(namespace string, cm *v1.ConfigMap, client *v12.CoreV1Client) (*v1.ConfigMap, error) {
+	defer impl.inflightMutations.Begin()()
 	cm, err := client.ConfigMaps(namespace).Create(context.Background(), cm, metav1.CreateOptions{})
 	if err != nil {
 		return nil, err
@@ -264,6 +721,7 @@ func (impl K8sUtil) CreateConfigMap(namespace string, cm *v1.ConfigMap, client *
 }
 
 func (impl K8sUtil) UpdateConfigMap(namespace string, cm *v1.ConfigMap, client *v12.CoreV1Client) (*v1.ConfigMap, error) {
+	defer impl.inflightMutations.Begin()()
 	cm, err := client.ConfigMaps(namespace).Update(context.Background(), cm, metav1.UpdateOptions{})
 	if err != nil {
 		return nil, err
@@ -273,6 +731,10 @@ func (impl K8sUtil) UpdateConfigMap(namespace string, cm *v1.ConfigMap, client *
 }
 
 func (impl K8sUtil) PatchConfigMap(namespace string, clusterConfig *ClusterConfig, name string, data map[string]interface{}) (*v1.ConfigMap, error) {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(context.Background(), clusterConfig, namespace, true); err != nil {
+		return nil, err
+	}
 	client, err := impl.GetClient(clusterConfig)
 	if err != nil {
 		return nil, err
@@ -290,36 +752,44 @@ func (impl K8sUtil) PatchConfigMap(namespace string, clusterConfig *ClusterConfi
 	return cm, nil
 }
 
-func (impl K8sUtil) PatchConfigMapJsonType(namespace string, clusterConfig *ClusterConfig, name string, data interface{}, path string) (*v1.ConfigMap, error) {
-	client, err := impl.GetClient(clusterConfig)
-	if err != nil {
+// PatchConfigMapJsonType applies the json patch atomically, config map keys in paths must be escaped with JsonPointer
+func (impl K8sUtil) PatchConfigMapJsonType(namespace string, clusterConfig *ClusterConfig, name string, patch *JsonPatch) (*v1.ConfigMap, error) {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(context.Background(), clusterConfig, namespace, true); err != nil {
 		return nil, err
 	}
-	var patches []*JsonPatchType
-	patch := &JsonPatchType{
-		Op:    "replace",
-		Path:  path,
-		Value: data,
+	if err := patch.Validate(); err != nil {
+		return nil, err
 	}
-	patches = append(patches, patch)
-	b, err := json.Marshal(patches)
+	client, err := impl.GetClient(clusterConfig)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-
-	cm, err := client.ConfigMaps(namespace).Patch(context.Background(), name, types.PatchType(types.JSONPatchType), b, metav1.PatchOptions{})
+	b, err := json.Marshal(patch)
 	if err != nil {
 		return nil, err
-	} else {
-		return cm, nil
 	}
-	return cm, nil
+	return client.ConfigMaps(namespace).Patch(context.Background(), name, types.JSONPatchType, b, metav1.PatchOptions{})
 }
 
-type JsonPatchType struct {
-	Op    string      `json:"op"`
-	Path  string      `json:"path"`
-	Value interface{} `json:"value"`
+// PatchSecretJsonType is PatchConfigMapJsonType for secrets, values under /data must be base64 encoded
+func (impl K8sUtil) PatchSecretJsonType(namespace string, clusterConfig *ClusterConfig, name string, patch *JsonPatch) (*v1.Secret, error) {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(context.Background(), clusterConfig, namespace, true); err != nil {
+		return nil, err
+	}
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+	client, err := impl.GetClient(clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return client.Secrets(namespace).Patch(context.Background(), name, types.JSONPatchType, b, metav1.PatchOptions{})
 }
 
 func (impl K8sUtil) GetSecret(namespace string, name string, client *v12.CoreV1Client) (*v1.Secret, error) {
@@ -332,6 +802,7 @@ func (impl K8sUtil) GetSecret(namespace string, name string, client *v12.CoreV1C
 }
 
 func (impl K8sUtil) CreateSecret(namespace string, data map[string][]byte, secretName string, secretType v1.SecretType, client *v12.CoreV1Client) (*v1.Secret, error) {
+	defer impl.inflightMutations.Begin()()
 	secret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: secretName,
@@ -350,6 +821,7 @@ func (impl K8sUtil) CreateSecret(namespace string, data map[string][]byte, secre
 }
 
 func (impl K8sUtil) UpdateSecret(namespace string, secret *v1.Secret, client *v12.CoreV1Client) (*v1.Secret, error) {
+	defer impl.inflightMutations.Begin()()
 	secret, err := client.Secrets(namespace).Update(context.Background(), secret, metav1.UpdateOptions{})
 	if err != nil {
 		return nil, err
@@ -359,6 +831,10 @@ func (impl K8sUtil) UpdateSecret(namespace string, secret *v1.Secret, client *v1
 }
 
 func (impl K8sUtil) DeleteJob(namespace string, name string, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(context.Background(), clusterConfig, namespace, true); err != nil {
+		return err
+	}
 	clientSet, err := impl.GetClientSet(clusterConfig)
 	if err != nil {
 		impl.logger.Errorw("clientSet err, DeleteJob", "err", err)
@@ -383,360 +859,3048 @@ func (impl K8sUtil) DeleteJob(namespace string, name string, clusterConfig *Clus
 	return nil
 }
 
-func (impl K8sUtil) CreateJob(namespace string, name string, clusterConfig *ClusterConfig, job *batchV1.Job) error {
+// DeleteCompletedJobs deletes the jobs completed before now-olderThan together with their pods, jobs which failed or
+// are still running have no completion time and are kept. Every job is attempted, the count of deleted jobs is returned
+// with the first failure
+func (impl K8sUtil) DeleteCompletedJobs(ctx context.Context, namespace, labelSelector string, olderThan time.Duration, clusterConfig *ClusterConfig) (int, error) {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return 0, err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
 	clientSet, err := impl.GetClientSet(clusterConfig)
 	if err != nil {
-		impl.logger.Errorw("clientSet err, CreateJob", "err", err)
+		logger.Errorw("clientSet err, DeleteCompletedJobs", "err", err)
+		return 0, err
 	}
-	time.Sleep(5 * time.Second)
-
-	jobs := clientSet.BatchV1().Jobs(namespace)
-	_, err = jobs.Get(context.Background(), name, metav1.GetOptions{})
-	if err == nil {
-		impl.logger.Errorw("get job err, CreateJob", "err", err)
-		time.Sleep(5 * time.Second)
-		_, err = jobs.Get(context.Background(), name, metav1.GetOptions{})
-		if err == nil {
-			return error2.New("job deletion takes more time than expected, please try after sometime")
+	jobs, err := clientSet.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		logger.Errorw("error in listing jobs", "err", err, "namespace", namespace, "labelSelector", labelSelector)
+		return 0, err
+	}
+	propagationPolicy := metav1.DeletePropagationBackground
+	deleted := 0
+	var deleteErr error
+	for _, job := range completedJobsBefore(jobs.Items, time.Now().Add(-olderThan)) {
+		err = clientSet.BatchV1().Jobs(job.Namespace).Delete(ctx, job.Name, metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Errorw("error in deleting completed job", "err", err, "namespace", job.Namespace, "name", job.Name)
+			if deleteErr == nil {
+				deleteErr = err
+			}
+			continue
 		}
+		deleted++
 	}
+	return deleted, deleteErr
+}
 
-	_, err = jobs.Create(context.Background(), job, metav1.CreateOptions{})
-	if err != nil {
-		impl.logger.Errorw("create err, CreateJob", "err", err)
-		return err
+func completedJobsBefore(jobs []batchV1.Job, cutoff time.Time) []batchV1.Job {
+	completed := make([]batchV1.Job, 0)
+	for _, job := range jobs {
+		if job.Status.CompletionTime != nil && job.Status.CompletionTime.Time.Before(cutoff) {
+			completed = append(completed, job)
+		}
 	}
-	return nil
+	return completed
 }
 
-// DeletePod delete pods with label job-name
-
-const Running = "Running"
-
-func (impl K8sUtil) DeletePodByLabel(namespace string, labels string, clusterConfig *ClusterConfig) error {
+// GetJobLogs streams the logs of the most recently started pod of the job, it waits up to JobLogsPodWaitTimeout for a
+// pod to start so that it can be called right after creating the job. Logs of completed pods are streamed as well
+func (impl K8sUtil) GetJobLogs(ctx context.Context, namespace, jobName, containerName string, tailLines int64, follow bool, clusterConfig *ClusterConfig) (io.ReadCloser, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
 	clientSet, err := impl.GetClientSet(clusterConfig)
 	if err != nil {
-		impl.logger.Errorw("clientSet err, DeletePod", "err", err)
-		return err
+		logger.Errorw("clientSet err, GetJobLogs", "err", err)
+		return nil, err
 	}
-
-	time.Sleep(2 * time.Second)
-
-	pods := clientSet.CoreV1().Pods(namespace)
-	podList, err := pods.List(context.Background(), metav1.ListOptions{LabelSelector: labels})
-	if err != nil && errors.IsNotFound(err) {
-		impl.logger.Errorw("get pod err, DeletePod", "err", err)
-		return nil
+	job, err := clientSet.BatchV1().Jobs(namespace).Get(ctx, jobName, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("get job err, GetJobLogs", "namespace", namespace, "jobName", jobName, "err", err)
+		return nil, err
 	}
-
-	for _, pod := range (*podList).Items {
-		if pod.Status.Phase != Running {
-			podName := pod.ObjectMeta.Name
-			err := pods.Delete(context.Background(), podName, metav1.DeleteOptions{})
-			if err != nil && !errors.IsNotFound(err) {
-				impl.logger.Errorw("delete err, DeletePod", "err", err)
-				return err
-			}
+	selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, JobLogsPodWaitTimeout)
+	defer cancel()
+	var pod *v1.Pod
+	for pod == nil {
+		pods, err := clientSet.CoreV1().Pods(namespace).List(waitCtx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			logger.Errorw("list pods err, GetJobLogs", "namespace", namespace, "jobName", jobName, "err", err)
+			return nil, err
+		}
+		pod = latestStartedPod(pods.Items)
+		if pod != nil {
+			break
+		}
+		select {
+		case <-waitCtx.Done():
+			return nil, fmt.Errorf("no pod of job %s started within %s", jobName, JobLogsPodWaitTimeout)
+		case <-time.After(2 * time.Second):
 		}
 	}
-	return nil
+	logOptions := &v1.PodLogOptions{Container: containerName, Follow: follow}
+	if tailLines > 0 {
+		logOptions.TailLines = &tailLines
+	}
+	stream, err := clientSet.CoreV1().Pods(namespace).GetLogs(pod.Name, logOptions).Stream(ctx)
+	if err != nil {
+		logger.Errorw("log stream err, GetJobLogs", "namespace", namespace, "pod", pod.Name, "err", err)
+		return nil, err
+	}
+	return stream, nil
 }
 
-// DeleteAndCreateJob Deletes and recreates if job exists else creates the job
-func (impl K8sUtil) DeleteAndCreateJob(content []byte, namespace string, clusterConfig *ClusterConfig) error {
-	// Job object from content
-	var job batchV1.Job
-	err := yaml.Unmarshal(content, &job)
+// latestStartedPod returns the pod which started last among the pods past Pending, retries of a job get new pods
+func latestStartedPod(pods []v1.Pod) *v1.Pod {
+	var latest *v1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == v1.PodPending || pod.Status.Phase == v1.PodUnknown || pod.Status.StartTime == nil {
+			continue
+		}
+		if latest == nil || latest.Status.StartTime.Before(pod.Status.StartTime) {
+			latest = pod
+		}
+	}
+	return latest
+}
+
+// GetPodResourceConsumptionViaExec is a best-effort fallback for clusters without metrics server, it reads the cgroup
+// v1 accounting files of the container through exec. It fails for containers without cat, for cgroup v2 nodes and
+// when the user of the cluster config may not exec into pods. CpuUsageNanoseconds is cumulative since container start
+func (impl K8sUtil) GetPodResourceConsumptionViaExec(ctx context.Context, namespace, podName, containerName string, clusterConfig *ClusterConfig) (*ResourceConsumption, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	cfg := &rest.Config{}
+	cfg.Host = clusterConfig.Host
+	cfg.BearerToken = clusterConfig.BearerToken
+	cfg.Insecure = true
+	clientSet, err := impl.GetClientSet(clusterConfig)
 	if err != nil {
-		impl.logger.Errorw("Unmarshal err, CreateJobSafely", "err", err)
-		return err
+		logger.Errorw("clientSet err, GetPodResourceConsumptionViaExec", "err", err)
+		return nil, err
 	}
+	req := clientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec")
+	req.VersionedParams(&v1.PodExecOptions{
+		Container: containerName,
+		Command:   []string{"cat", CgroupMemoryUsagePath, CgroupCpuUsagePath},
+		Stdout:    true,
+		Stderr:    true,
+	}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(cfg, "POST", req.URL())
+	if err != nil {
+		logger.Errorw("error in creating executor", "namespace", namespace, "pod", podName, "err", err)
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	err = executor.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+	if err != nil {
+		logger.Errorw("error in reading cgroup usage", "namespace", namespace, "pod", podName, "container", containerName, "stderr", stderr.String(), "err", err)
+		return nil, fmt.Errorf("unable to read cgroup usage of container %s: %v", containerName, err)
+	}
+	consumption, err := parseCgroupUsage(stdout.String())
+	if err != nil {
+		logger.Errorw("error in parsing cgroup usage", "namespace", namespace, "pod", podName, "container", containerName, "output", stdout.String(), "err", err)
+		return nil, err
+	}
+	consumption.PodName = podName
+	consumption.ContainerName = containerName
+	return consumption, nil
+}
 
-	// delete job if exists
-	err = impl.DeleteJob(namespace, job.Name, clusterConfig)
+// DeleteAllJobsByLabel deletes the jobs matching the selector along with their pods and returns how many were deleted,
+// an empty selector is rejected as it would match every job of the namespace
+func (impl K8sUtil) DeleteAllJobsByLabel(ctx context.Context, namespace, labelSelector string, clusterConfig *ClusterConfig) (int, error) {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return 0, err
+	}
+	return impl.deleteAllJobsByLabel(ctx, namespace, labelSelector, false, clusterConfig)
+}
+
+// DryRunDeleteAllJobsByLabel returns the count DeleteAllJobsByLabel would delete without deleting anything
+func (impl K8sUtil) DryRunDeleteAllJobsByLabel(ctx context.Context, namespace, labelSelector string, clusterConfig *ClusterConfig) (int, error) {
+	return impl.deleteAllJobsByLabel(ctx, namespace, labelSelector, true, clusterConfig)
+}
+
+func (impl K8sUtil) deleteAllJobsByLabel(ctx context.Context, namespace, labelSelector string, dryRun bool, clusterConfig *ClusterConfig) (int, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	if len(strings.TrimSpace(labelSelector)) == 0 {
+		return 0, fmt.Errorf("label selector is required to delete jobs")
+	}
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, DeleteAllJobsByLabel", "err", err)
+		return 0, err
+	}
+	jobs := clientSet.BatchV1().Jobs(namespace)
+	jobList, err := jobs.List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
 	if err != nil {
-		impl.logger.Errorw("DeleteJobIfExists err, CreateJobSafely", "err", err)
+		logger.Errorw("list jobs err, DeleteAllJobsByLabel", "namespace", namespace, "labelSelector", labelSelector, "err", err)
+		return 0, err
+	}
+	if dryRun {
+		return len(jobList.Items), nil
+	}
+	// foreground deletion removes the pods before the job so that nothing of the pipeline is left behind
+	propagationPolicy := metav1.DeletePropagationForeground
+	deleted := 0
+	for _, job := range jobList.Items {
+		err = jobs.Delete(ctx, job.Name, metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Errorw("delete err, DeleteAllJobsByLabel", "namespace", namespace, "job", job.Name, "err", err)
+			return deleted, err
+		}
+		if err == nil {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (impl K8sUtil) CreateJob(ctx context.Context, namespace string, name string, clusterConfig *ClusterConfig, job *batchV1.Job) error {
+	if err := impl.CheckMutation(ctx, clusterConfig, namespace, "Job", name, MutationActionCreate); err != nil {
 		return err
 	}
+	return impl.createJob(ctx, namespace, name, clusterConfig, job)
+}
 
-	labels := "job-name=" + job.Name
-	err = impl.DeletePodByLabel(namespace, labels, clusterConfig)
+func (impl K8sUtil) createJob(ctx context.Context, namespace string, name string, clusterConfig *ClusterConfig, job *batchV1.Job) error {
+	defer impl.inflightMutations.Begin()()
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, CreateJob", "err", err)
+	}
+	time.Sleep(5 * time.Second)
+
+	jobs := clientSet.BatchV1().Jobs(namespace)
+	_, err = jobs.Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		logger.Errorw("get job err, CreateJob", "err", err)
+		time.Sleep(5 * time.Second)
+		_, err = jobs.Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			return error2.New("job deletion takes more time than expected, please try after sometime")
+		}
+	}
+
+	impl.annotateWithRequestId(ctx, job)
+	placement, err := impl.resolvePodPlacement(clusterConfig.ClusterId, namespace)
 	if err != nil {
-		impl.logger.Errorw("DeleteJobIfExists err, CreateJobSafely", "err", err)
+		logger.Errorw("placement err, CreateJob", "job", job.Name, "err", err)
 		return err
 	}
-	// create job
-	err = impl.CreateJob(namespace, job.Name, clusterConfig, &job)
+	if err = impl.mutateJob(job, placement); err != nil {
+		logger.Errorw("mutation err, CreateJob", "job", job.Name, "err", err)
+		return err
+	}
+	_, err = jobs.Create(ctx, job, metav1.CreateOptions{})
 	if err != nil {
-		impl.logger.Errorw("CreateJob err, CreateJobSafely", "err", err)
+		logger.Errorw("create err, CreateJob", "err", err)
 		return err
 	}
-
 	return nil
 }
 
-func (impl K8sUtil) ListNamespaces(client *v12.CoreV1Client) (*v1.NamespaceList, error) {
-	nsList, err := client.Namespaces().List(context.Background(), metav1.ListOptions{})
-	if errors.IsNotFound(err) {
-		return nsList, nil
-	} else if err != nil {
-		return nsList, err
-	} else {
-		return nsList, nil
+// CreateJobWithTTL creates the job with ttlSecondsAfterFinished so that finished jobs are garbage collected and
+// activeDeadlineSeconds so that stuck jobs are terminated, a value <= 0 leaves the respective field unset.
+// ttlSecondsAfterFinished is capped at JOB_MAX_TTL_SECONDS_AFTER_FINISHED
+func (impl K8sUtil) CreateJobWithTTL(ctx context.Context, namespace string, job *batchV1.Job, ttlSecondsAfterFinished int32, activeDeadlineSeconds int64, clusterConfig *ClusterConfig) error {
+	if impl.jobTTLConfig != nil {
+		ttlSecondsAfterFinished = impl.jobTTLConfig.clampJobTTL(ttlSecondsAfterFinished)
+	}
+	if ttlSecondsAfterFinished > 0 {
+		// older clusters silently drop the field and finished jobs would pile up
+		if err := impl.RequireClusterFeature(ctx, clusterConfig, ClusterFeatureJobTTLAfterFinished); err != nil {
+			return err
+		}
+		job.Spec.TTLSecondsAfterFinished = &ttlSecondsAfterFinished
+	}
+	if activeDeadlineSeconds > 0 {
+		job.Spec.ActiveDeadlineSeconds = &activeDeadlineSeconds
 	}
+	return impl.CreateJob(ctx, namespace, job.Name, clusterConfig, job)
 }
 
-func (impl K8sUtil) GetClientByToken(serverUrl string, token map[string]string) (*v12.CoreV1Client, error) {
-	bearerToken := token["bearer_token"]
-	clusterCfg := &ClusterConfig{Host: serverUrl, BearerToken: bearerToken}
-	client, err := impl.GetClient(clusterCfg)
+// SetJobActiveDeadline changes the deadline of a job which is already running, the deadline is counted from the
+// start time of the job and not from the time of the patch
+func (impl K8sUtil) SetJobActiveDeadline(ctx context.Context, namespace, name string, deadlineSeconds int64, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	if deadlineSeconds <= 0 {
+		return fmt.Errorf("active deadline must be positive, got %d", deadlineSeconds)
+	}
+	clientSet, err := impl.GetClientSet(clusterConfig)
 	if err != nil {
-		impl.logger.Errorw("error in k8s client", "error", err)
+		logger.Errorw("clientSet err, SetJobActiveDeadline", "err", err)
+		return err
+	}
+	patch := []byte(fmt.Sprintf(`{"spec":{"activeDeadlineSeconds":%d}}`, deadlineSeconds))
+	_, err = clientSet.BatchV1().Jobs(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		logger.Errorw("error in setting job active deadline", "err", err, "namespace", namespace, "name", name, "deadlineSeconds", deadlineSeconds)
+		return err
+	}
+	return nil
+}
+
+// GetJobActiveDeadline returns nil if the job has no deadline
+func (impl K8sUtil) GetJobActiveDeadline(ctx context.Context, namespace, name string, clusterConfig *ClusterConfig) (*int64, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetJobActiveDeadline", "err", err)
 		return nil, err
 	}
-	return client, nil
+	job, err := clientSet.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting job", "err", err, "namespace", namespace, "name", name)
+		return nil, err
+	}
+	return job.Spec.ActiveDeadlineSeconds, nil
 }
 
-func (impl K8sUtil) GetResourceInfoByLabelSelector(ctx context.Context, namespace string, labelSelector string) (*v1.Pod, error) {
-	client, err := impl.GetClientForInCluster()
+// GetJobCompletionRatio counts the jobs which finished within window, failed jobs have no completion time so the time
+// of their Failed condition is used instead. Ratio is succeeded over finished and 0 when nothing finished
+func (impl K8sUtil) GetJobCompletionRatio(ctx context.Context, namespace, labelSelector string, window time.Duration, clusterConfig *ClusterConfig) (*JobCompletionRatio, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
 	if err != nil {
-		impl.logger.Errorw("cluster config error", "err", err)
+		logger.Errorw("clientSet err, GetJobCompletionRatio", "err", err)
 		return nil, err
 	}
-	pods, err := client.Pods(namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: labelSelector,
-	})
+	jobs, err := clientSet.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
 	if err != nil {
+		logger.Errorw("error in listing jobs", "err", err, "namespace", namespace, "labelSelector", labelSelector)
 		return nil, err
-	} else if len(pods.Items) > 1 {
-		err = &ApiError{Code: "406", HttpStatusCode: 200, UserMessage: "found more than one pod for label selector"}
+	}
+	since := time.Now().Add(-window)
+	ratio := &JobCompletionRatio{Namespace: namespace, Window: window}
+	for _, job := range jobs.Items {
+		if job.Status.CompletionTime != nil {
+			if !job.Status.CompletionTime.Time.Before(since) {
+				ratio.Succeeded++
+			}
+			continue
+		}
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == batchV1.JobFailed && condition.Status == v1.ConditionTrue && !condition.LastTransitionTime.Time.Before(since) {
+				ratio.Failed++
+				break
+			}
+		}
+	}
+	if finished := ratio.Succeeded + ratio.Failed; finished > 0 {
+		ratio.Ratio = float64(ratio.Succeeded) / float64(finished)
+	}
+	return ratio, nil
+}
+
+// GetNamespacePodSummary lists the pods of the namespace once and aggregates them in memory
+func (impl K8sUtil) GetNamespacePodSummary(ctx context.Context, namespace string, clusterConfig *ClusterConfig) (*PodSummaryStats, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetNamespacePodSummary", "err", err)
 		return nil, err
-	} else if len(pods.Items) == 0 {
-		err = &ApiError{Code: "404", HttpStatusCode: 200, UserMessage: "no pod found for label selector"}
+	}
+	pods, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Errorw("error in listing pods", "err", err, "namespace", namespace)
 		return nil, err
-	} else {
-		return &pods.Items[0], nil
 	}
+	return newPodSummaryStats(namespace, pods.Items), nil
 }
 
-func (impl K8sUtil) GetK8sClusterRestConfig() (*rest.Config, error) {
-	impl.logger.Debug("getting k8s rest config")
-	if impl.runTimeConfig.LocalDevMode {
-		restConfig, err := clientcmd.BuildConfigFromFlags("", *impl.kubeconfig)
+func newPodSummaryStats(namespace string, pods []v1.Pod) *PodSummaryStats {
+	stats := &PodSummaryStats{Namespace: namespace, Total: len(pods)}
+	for _, pod := range pods {
+		switch pod.Status.Phase {
+		case v1.PodRunning:
+			stats.Running++
+		case v1.PodPending:
+			stats.Pending++
+		case v1.PodFailed:
+			stats.Failed++
+		case v1.PodSucceeded:
+			stats.Succeeded++
+		default:
+			stats.Unknown++
+		}
+		for _, containerStatus := range pod.Status.InitContainerStatuses {
+			stats.Restarts += int(containerStatus.RestartCount)
+		}
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			stats.Restarts += int(containerStatus.RestartCount)
+		}
+	}
+	return stats
+}
+
+// GetCronJobStatus reads batch/v1 cronjobs and falls back to batch/v1beta1 on clusters which do not serve them yet
+func (impl K8sUtil) GetCronJobStatus(ctx context.Context, namespace, name string, clusterConfig *ClusterConfig) (*CronJobStatus, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetCronJobStatus", "err", err)
+		return nil, err
+	}
+	var status *CronJobStatus
+	if impl.RequireClusterFeature(ctx, clusterConfig, ClusterFeatureCronJobBatchV1) != nil {
+		cronJob, err := clientSet.BatchV1beta1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
-			impl.logger.Errorw("Error while building kubernetes cluster rest config", "error", err)
+			logger.Errorw("error in getting cronjob", "err", err, "namespace", namespace, "name", name)
 			return nil, err
 		}
-		return restConfig, nil
+		status, err = newCronJobStatus(cronJob.Spec.Schedule, cronJob.Spec.TimeZone, cronJob.Spec.Suspend, time.Now())
+		if err != nil {
+			logger.Errorw("error in parsing cronjob schedule", "err", err, "namespace", namespace, "name", name, "schedule", cronJob.Spec.Schedule)
+			return nil, err
+		}
+		status.LastScheduleTime = cronJob.Status.LastScheduleTime
+		status.LastSuccessfulTime = cronJob.Status.LastSuccessfulTime
+		status.ActiveJobCount = len(cronJob.Status.Active)
 	} else {
-		clusterConfig, err := rest.InClusterConfig()
+		cronJob, err := clientSet.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
-			impl.logger.Errorw("error in fetch default cluster config", "err", err)
+			logger.Errorw("error in getting cronjob", "err", err, "namespace", namespace, "name", name)
 			return nil, err
 		}
-		return clusterConfig, nil
+		status, err = newCronJobStatus(cronJob.Spec.Schedule, cronJob.Spec.TimeZone, cronJob.Spec.Suspend, time.Now())
+		if err != nil {
+			logger.Errorw("error in parsing cronjob schedule", "err", err, "namespace", namespace, "name", name, "schedule", cronJob.Spec.Schedule)
+			return nil, err
+		}
+		status.LastScheduleTime = cronJob.Status.LastScheduleTime
+		status.LastSuccessfulTime = cronJob.Status.LastSuccessfulTime
+		status.ActiveJobCount = len(cronJob.Status.Active)
 	}
+	status.Namespace = namespace
+	status.Name = name
+	return status, nil
 }
 
-func (impl K8sUtil) GetPodByName(namespace string, name string, client *v12.CoreV1Client) (*v1.Pod, error) {
-	pod, err := client.Pods(namespace).Get(context.Background(), name, metav1.GetOptions{})
+// ListCronJobs lists batch/v1 cronjobs, on clusters which only serve batch/v1beta1 those are listed and converted
+func (impl K8sUtil) ListCronJobs(ctx context.Context, namespace, labelSelector string, clusterConfig *ClusterConfig) ([]*batchV1.CronJob, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
 	if err != nil {
-		impl.logger.Errorw("error in fetch pod name", "err", err)
+		logger.Errorw("clientSet err, ListCronJobs", "err", err)
 		return nil, err
-	} else {
-		return pod, nil
 	}
-}
-
-func (impl K8sUtil) BuildK8sObjectListTableData(manifest *unstructured.UnstructuredList, namespaced bool, gvk schema.GroupVersionKind, validateResourceAccess func(namespace string, group string, kind string, resourceName string) bool) (*ClusterResourceListMap, error) {
-	clusterResourceListMap := &ClusterResourceListMap{}
-	// build headers
-	var headers []string
-	columnIndexes := make(map[int]string)
-	kind := gvk.Kind
-	if kind == "Event" {
-		headers, columnIndexes = impl.getEventKindHeader()
-	} else {
-		columnDefinitionsUncast := manifest.Object[K8sClusterResourceColumnDefinitionKey]
-		if columnDefinitionsUncast != nil {
-			columnDefinitions := columnDefinitionsUncast.([]interface{})
-			for index, cd := range columnDefinitions {
-				if cd == nil {
-					continue
-				}
-				columnMap := cd.(map[string]interface{})
-				columnNameUncast := columnMap[K8sClusterResourceNameKey]
-				if columnNameUncast == nil {
-					continue
-				}
-				priorityUncast := columnMap[K8sClusterResourcePriorityKey]
-				if priorityUncast == nil {
-					continue
-				}
-				columnName := columnNameUncast.(string)
-				columnName = strings.ToLower(columnName)
-				priority := priorityUncast.(int64)
-				if namespaced && index == 1 {
-					headers = append(headers, K8sClusterResourceNamespaceKey)
-				}
-				if priority == 0 || (manifest.GetKind() == "Event" && columnName == "source") {
-					columnIndexes[index] = columnName
-					headers = append(headers, columnName)
-				}
-			}
+	listOptions := metav1.ListOptions{LabelSelector: labelSelector}
+	cronJobs := make([]*batchV1.CronJob, 0)
+	if impl.RequireClusterFeature(ctx, clusterConfig, ClusterFeatureCronJobBatchV1) != nil {
+		list, err := clientSet.BatchV1beta1().CronJobs(namespace).List(ctx, listOptions)
+		if err != nil {
+			logger.Errorw("error in listing cronjobs", "err", err, "namespace", namespace, "labelSelector", labelSelector)
+			return nil, err
 		}
-	}
-
-	// build rows
-	rowsMapping := make([]map[string]interface{}, 0)
-	rowsDataUncast := manifest.Object[K8sClusterResourceRowsKey]
-	var namespace string
-	var allowed bool
-	if rowsDataUncast != nil {
-		rows := rowsDataUncast.([]interface{})
-		for _, row := range rows {
-			namespace = ""
-			allowed = true
-			rowIndex := make(map[string]interface{})
-			rowMap := row.(map[string]interface{})
-			cellsUncast := rowMap[K8sClusterResourceCellKey]
-			if cellsUncast == nil {
-				continue
-			}
-			rowCells := cellsUncast.([]interface{})
-			for index, columnName := range columnIndexes {
-				cellValUncast := rowCells[index]
-				var cell interface{}
-				if cellValUncast == nil {
-					cell = ""
-				} else {
-					cell = cellValUncast.(interface{})
-				}
-				rowIndex[columnName] = cell
-			}
-
-			cellObjUncast := rowMap[K8sClusterResourceObjectKey]
-			var cellObj map[string]interface{}
-			if cellObjUncast != nil {
-				cellObj = cellObjUncast.(map[string]interface{})
-				if cellObj != nil && cellObj[K8sClusterResourceMetadataKey] != nil {
-					metadata := cellObj[K8sClusterResourceMetadataKey].(map[string]interface{})
-					if metadata[K8sClusterResourceNamespaceKey] != nil {
-						namespace = metadata[K8sClusterResourceNamespaceKey].(string)
-						if namespaced {
-							rowIndex[K8sClusterResourceNamespaceKey] = namespace
-						}
-					}
-				}
-			}
-			allowed = impl.ValidateResource(cellObj, gvk, validateResourceAccess)
-			if allowed {
-				rowsMapping = append(rowsMapping, rowIndex)
-			}
+		for i := range list.Items {
+			cronJobs = append(cronJobs, cronJobFromV1beta1(&list.Items[i]))
 		}
+		return cronJobs, nil
+	}
+	list, err := clientSet.BatchV1().CronJobs(namespace).List(ctx, listOptions)
+	if err != nil {
+		logger.Errorw("error in listing cronjobs", "err", err, "namespace", namespace, "labelSelector", labelSelector)
+		return nil, err
 	}
+	for i := range list.Items {
+		cronJobs = append(cronJobs, &list.Items[i])
+	}
+	return cronJobs, nil
+}
 
-	clusterResourceListMap.Headers = headers
-	clusterResourceListMap.Data = rowsMapping
-	impl.logger.Debugw("resource listing response", "clusterResourceListMap", clusterResourceListMap)
-	return clusterResourceListMap, nil
+func cronJobFromV1beta1(cronJob *batchV1beta1.CronJob) *batchV1.CronJob {
+	return &batchV1.CronJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: batchV1.SchemeGroupVersion.String(), Kind: K8sClusterResourceCronJobKind},
+		ObjectMeta: cronJob.ObjectMeta,
+		Spec: batchV1.CronJobSpec{
+			Schedule:                   cronJob.Spec.Schedule,
+			TimeZone:                   cronJob.Spec.TimeZone,
+			StartingDeadlineSeconds:    cronJob.Spec.StartingDeadlineSeconds,
+			ConcurrencyPolicy:          batchV1.ConcurrencyPolicy(cronJob.Spec.ConcurrencyPolicy),
+			Suspend:                    cronJob.Spec.Suspend,
+			JobTemplate:                batchV1.JobTemplateSpec{ObjectMeta: cronJob.Spec.JobTemplate.ObjectMeta, Spec: cronJob.Spec.JobTemplate.Spec},
+			SuccessfulJobsHistoryLimit: cronJob.Spec.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     cronJob.Spec.FailedJobsHistoryLimit,
+		},
+		Status: batchV1.CronJobStatus{
+			Active:             cronJob.Status.Active,
+			LastScheduleTime:   cronJob.Status.LastScheduleTime,
+			LastSuccessfulTime: cronJob.Status.LastSuccessfulTime,
+		},
+	}
 }
 
-func (impl K8sUtil) ValidateResource(resourceObj map[string]interface{}, gvk schema.GroupVersionKind, validateCallback func(namespace string, group string, kind string, resourceName string) bool) bool {
-	resKind := gvk.Kind
-	groupName := gvk.Group
-	metadata := resourceObj[K8sClusterResourceMetadataKey]
-	if metadata == nil {
-		return false
+// GetLastCronJobRuns returns the most recent job of each cronjob keyed by cronjob name, the jobs of the namespace
+// are listed once and matched to the cronjobs by their controller reference
+func (impl K8sUtil) GetLastCronJobRuns(ctx context.Context, namespace string, cronJobs []*batchV1.CronJob, clusterConfig *ClusterConfig) (map[string]*CronJobRun, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetLastCronJobRuns", "err", err)
+		return nil, err
 	}
-	metadataMap := metadata.(map[string]interface{})
-	var namespace, resourceName string
-	var ownerReferences []interface{}
-	if metadataMap[K8sClusterResourceNamespaceKey] != nil {
-		namespace = metadataMap[K8sClusterResourceNamespaceKey].(string)
+	jobs, err := clientSet.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Errorw("error in listing jobs", "err", err, "namespace", namespace)
+		return nil, err
 	}
-	if metadataMap[K8sClusterResourceMetadataNameKey] != nil {
-		resourceName = metadataMap[K8sClusterResourceMetadataNameKey].(string)
+	cronJobNames := make(map[types.UID]string, len(cronJobs))
+	for _, cronJob := range cronJobs {
+		cronJobNames[cronJob.UID] = cronJob.Name
 	}
-	if metadataMap[K8sClusterResourceOwnerReferenceKey] != nil {
-		ownerReferences = metadataMap[K8sClusterResourceOwnerReferenceKey].([]interface{})
+	lastJobs := make(map[string]*batchV1.Job)
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		owner := metav1.GetControllerOf(job)
+		if owner == nil || owner.Kind != K8sClusterResourceCronJobKind {
+			continue
+		}
+		cronJobName, ok := cronJobNames[owner.UID]
+		if !ok {
+			continue
+		}
+		if last, ok := lastJobs[cronJobName]; !ok || last.CreationTimestamp.Before(&job.CreationTimestamp) {
+			lastJobs[cronJobName] = job
+		}
 	}
-	if len(ownerReferences) > 0 {
-		for _, ownerRef := range ownerReferences {
-			allowed := impl.validateForResource(namespace, ownerRef, validateCallback)
-			if allowed {
-				return allowed
-			}
+	runs := make(map[string]*CronJobRun, len(lastJobs))
+	for cronJobName, job := range lastJobs {
+		runs[cronJobName] = &CronJobRun{
+			JobName:        job.Name,
+			Status:         cronJobRunStatus(job),
+			StartTime:      job.Status.StartTime,
+			CompletionTime: job.Status.CompletionTime,
 		}
 	}
-	// check current RBAC in case not matched with above one
-	return validateCallback(namespace, groupName, resKind, resourceName)
+	return runs, nil
 }
 
-func (impl K8sUtil) validateForResource(namespace string, resourceRef interface{}, validateCallback func(namespace string, group string, kind string, resourceName string) bool) bool {
-	resourceReference := resourceRef.(map[string]interface{})
-	resKind := resourceReference[K8sClusterResourceKindKey].(string)
-	apiVersion := resourceReference[K8sClusterResourceApiVersionKey].(string)
-	groupName := ""
-	if strings.Contains(apiVersion, "/") {
-		groupName = apiVersion[:strings.LastIndex(apiVersion, "/")] // extracting group from this apiVersion
+func cronJobRunStatus(job *batchV1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchV1.JobComplete:
+			return CronJobRunSucceeded
+		case batchV1.JobFailed:
+			return CronJobRunFailed
+		}
 	}
-	resName := ""
-	if resourceReference["name"] != "" {
-		resName = resourceReference["name"].(string)
-		switch resKind {
-		case kube.ReplicaSetKind:
-			// check deployment first, then RO and then RS
-			if strings.Contains(resName, "-") {
-				deploymentName := resName[:strings.LastIndex(resName, "-")]
-				allowed := validateCallback(namespace, groupName, kube.DeploymentKind, deploymentName)
-				if allowed {
-					return true
-				}
-				allowed = validateCallback(namespace, K8sClusterResourceRolloutGroup, K8sClusterResourceRolloutKind, deploymentName)
-				if allowed {
-					return true
-				}
-			}
-			allowed := validateCallback(namespace, groupName, resKind, resName)
-			if allowed {
-				return true
-			}
-		case kube.JobKind:
-			// check CronJob first, then Job
-			if strings.Contains(resName, "-") {
-				cronJobName := resName[:strings.LastIndex(resName, "-")]
-				allowed := validateCallback(namespace, groupName, K8sClusterResourceCronJobKind, cronJobName)
-				if allowed {
-					return true
-				}
-			}
-			allowed := validateCallback(namespace, groupName, resKind, resName)
-			if allowed {
-				return true
+	return CronJobRunActive
+}
+
+// SetCronJobSuspended patches spec.suspend, jobs which are already running are not stopped
+func (impl K8sUtil) SetCronJobSuspended(ctx context.Context, namespace, name string, suspended bool, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, SetCronJobSuspended", "err", err)
+		return err
+	}
+	patch := []byte(fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspended))
+	if impl.RequireClusterFeature(ctx, clusterConfig, ClusterFeatureCronJobBatchV1) != nil {
+		_, err = clientSet.BatchV1beta1().CronJobs(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	} else {
+		_, err = clientSet.BatchV1().CronJobs(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	if err != nil {
+		logger.Errorw("error in patching cronjob suspend", "err", err, "namespace", namespace, "name", name, "suspended", suspended)
+		return err
+	}
+	return nil
+}
+
+// newCronJobStatus computes the next run after now the way the cronjob controller does, in the time zone of the
+// cronjob when it sets one and in UTC otherwise
+func newCronJobStatus(schedule string, timeZone *string, suspend *bool, now time.Time) (*CronJobStatus, error) {
+	status := &CronJobStatus{Schedule: schedule, Suspended: suspend != nil && *suspend}
+	if status.Suspended {
+		return status, nil
+	}
+	expression := schedule
+	if timeZone != nil && *timeZone != "" && !strings.Contains(schedule, "TZ=") {
+		expression = "CRON_TZ=" + *timeZone + " " + schedule
+	}
+	parsed, err := cron.ParseStandard(expression)
+	if err != nil {
+		return nil, err
+	}
+	next := metav1.NewTime(parsed.Next(now.UTC()))
+	status.NextScheduleTime = &next
+	return status, nil
+}
+
+// DeletePod delete pods with label job-name
+
+const Running = "Running"
+
+func (impl K8sUtil) DeletePodByLabel(namespace string, labels string, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(context.Background(), clusterConfig, namespace, true); err != nil {
+		return err
+	}
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		impl.logger.Errorw("clientSet err, DeletePod", "err", err)
+		return err
+	}
+
+	time.Sleep(2 * time.Second)
+
+	pods := clientSet.CoreV1().Pods(namespace)
+	podList, err := pods.List(context.Background(), metav1.ListOptions{LabelSelector: labels})
+	if err != nil && errors.IsNotFound(err) {
+		impl.logger.Errorw("get pod err, DeletePod", "err", err)
+		return nil
+	}
+
+	for _, pod := range (*podList).Items {
+		if pod.Status.Phase != Running {
+			podName := pod.ObjectMeta.Name
+			err := pods.Delete(context.Background(), podName, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				impl.logger.Errorw("delete err, DeletePod", "err", err)
+				return err
 			}
-		case kube.DeploymentKind, K8sClusterResourceCronJobKind, kube.StatefulSetKind, kube.DaemonSetKind, K8sClusterResourceRolloutKind, K8sClusterResourceReplicationControllerKind:
-			allowed := validateCallback(namespace, groupName, resKind, resName)
-			if allowed {
-				return true
+		}
+	}
+	return nil
+}
+
+// EvictPod removes the pod through the eviction api so that PodDisruptionBudgets are respected,
+// ErrPDBBlocked is returned if a budget does not allow the disruption at the moment
+func (impl K8sUtil) EvictPod(ctx context.Context, namespace, podName string, gracePeriodSeconds int64, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, EvictPod", "err", err)
+		return err
+	}
+	objectMeta := metav1.ObjectMeta{Name: podName, Namespace: namespace}
+	deleteOptions := &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriodSeconds}
+	if impl.RequireClusterFeature(ctx, clusterConfig, ClusterFeatureEvictionPolicyV1) == nil {
+		err = clientSet.CoreV1().Pods(namespace).EvictV1(ctx, &policyV1.Eviction{ObjectMeta: objectMeta, DeleteOptions: deleteOptions})
+	} else {
+		err = clientSet.CoreV1().Pods(namespace).EvictV1beta1(ctx, &policyV1beta1.Eviction{ObjectMeta: objectMeta, DeleteOptions: deleteOptions})
+	}
+	if err != nil {
+		if errors.IsTooManyRequests(err) {
+			retryAfterSeconds, _ := errors.SuggestsClientDelay(err)
+			logger.Infow("eviction blocked by pod disruption budget", "namespace", namespace, "podName", podName, "err", err)
+			return &ErrPDBBlocked{Namespace: namespace, PodName: podName, RetryAfterSeconds: retryAfterSeconds, Message: err.Error()}
+		}
+		logger.Errorw("evict err, EvictPod", "namespace", namespace, "podName", podName, "err", err)
+		return err
+	}
+	return nil
+}
+
+// DeletePod deletes the pod when it matches labelSelector, pods not owned by a controller are not recreated and are only
+// deleted with force. The returned state is read after the delete request, Deleted is set once the pod is gone
+func (impl K8sUtil) DeletePod(ctx context.Context, namespace, name, labelSelector string, gracePeriodSeconds *int64, force bool, clusterConfig *ClusterConfig) (*PodState, error) {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return nil, err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, DeletePod", "err", err)
+		return nil, err
+	}
+	pods := clientSet.CoreV1().Pods(namespace)
+	pod, err := pods.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting pod", "err", err, "namespace", namespace, "name", name)
+		return nil, err
+	}
+	if err = matchLabelSelector(labelSelector, pod.Labels, v1.Resource("pods"), name); err != nil {
+		return nil, err
+	}
+	if metav1.GetControllerOf(pod) == nil && !force {
+		return nil, ErrPodNotControlled
+	}
+	err = pods.Delete(ctx, name, metav1.DeleteOptions{GracePeriodSeconds: gracePeriodSeconds, Preconditions: &metav1.Preconditions{UID: &pod.UID}})
+	if err != nil {
+		logger.Errorw("error in deleting pod", "err", err, "namespace", namespace, "name", name)
+		return nil, err
+	}
+	state := &PodState{Namespace: namespace, Name: name, Phase: string(pod.Status.Phase)}
+	pod, err = pods.Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		state.Deleted = true
+	} else if err != nil {
+		logger.Warnw("error in getting pod after delete", "err", err, "namespace", namespace, "name", name)
+	} else {
+		state.Phase = string(pod.Status.Phase)
+		state.DeletionTimestamp = pod.DeletionTimestamp
+	}
+	return state, nil
+}
+
+// RestartWorkload is kubectl rollout restart, the pod template of the Deployment, StatefulSet or DaemonSet is annotated
+// with the restart time so that its pods are replaced by the rollout strategy of the workload
+func (impl K8sUtil) RestartWorkload(ctx context.Context, namespace, kind, name, labelSelector string, clusterConfig *ClusterConfig) (*WorkloadState, error) {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return nil, err
+	}
+	restartedAt := time.Now().UTC().Format(time.RFC3339)
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{RestartedAtAnnotationKey: restartedAt},
+				},
+			},
+		},
+	}
+	state, err := impl.patchWorkload(ctx, namespace, kind, name, labelSelector, patch, true, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	state.RestartedAt = restartedAt
+	return state, nil
+}
+
+// ScaleWorkload sets the replicas of a Deployment or StatefulSet
+func (impl K8sUtil) ScaleWorkload(ctx context.Context, namespace, kind, name, labelSelector string, replicas int32, clusterConfig *ClusterConfig) (*WorkloadState, error) {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return nil, err
+	}
+	patch := map[string]interface{}{"spec": map[string]interface{}{"replicas": replicas}}
+	return impl.patchWorkload(ctx, namespace, kind, name, labelSelector, patch, false, clusterConfig)
+}
+
+// patchWorkload merge patches the workload when it matches labelSelector, the resource version read is part of the
+// patch so that a workload changed in between fails with a conflict instead of being patched blindly
+func (impl K8sUtil) patchWorkload(ctx context.Context, namespace, kind, name, labelSelector string, patch map[string]interface{}, allowDaemonSet bool, clusterConfig *ClusterConfig) (*WorkloadState, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, patchWorkload", "err", err)
+		return nil, err
+	}
+	patchWithVersion := func(resourceVersion string) ([]byte, error) {
+		patch["metadata"] = map[string]interface{}{"resourceVersion": resourceVersion}
+		return json.Marshal(patch)
+	}
+	var state *WorkloadState
+	switch kind {
+	case kube.DeploymentKind:
+		deployments := clientSet.AppsV1().Deployments(namespace)
+		deployment, err := deployments.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			logger.Errorw("error in getting deployment", "err", err, "namespace", namespace, "name", name)
+			return nil, err
+		}
+		if err = matchLabelSelector(labelSelector, deployment.Labels, appsV1.Resource("deployments"), name); err != nil {
+			return nil, err
+		}
+		patchBytes, err := patchWithVersion(deployment.ResourceVersion)
+		if err != nil {
+			return nil, err
+		}
+		if deployment, err = deployments.Patch(ctx, name, types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
+			logger.Errorw("error in patching deployment", "err", err, "namespace", namespace, "name", name)
+			return nil, err
+		}
+		state = &WorkloadState{Replicas: 1, ReadyReplicas: deployment.Status.ReadyReplicas, UpdatedReplicas: deployment.Status.UpdatedReplicas,
+			Generation: deployment.Generation, ObservedGeneration: deployment.Status.ObservedGeneration}
+		if deployment.Spec.Replicas != nil {
+			state.Replicas = *deployment.Spec.Replicas
+		}
+	case kube.StatefulSetKind:
+		statefulSets := clientSet.AppsV1().StatefulSets(namespace)
+		statefulSet, err := statefulSets.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			logger.Errorw("error in getting statefulset", "err", err, "namespace", namespace, "name", name)
+			return nil, err
+		}
+		if err = matchLabelSelector(labelSelector, statefulSet.Labels, appsV1.Resource("statefulsets"), name); err != nil {
+			return nil, err
+		}
+		patchBytes, err := patchWithVersion(statefulSet.ResourceVersion)
+		if err != nil {
+			return nil, err
+		}
+		if statefulSet, err = statefulSets.Patch(ctx, name, types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
+			logger.Errorw("error in patching statefulset", "err", err, "namespace", namespace, "name", name)
+			return nil, err
+		}
+		state = &WorkloadState{Replicas: 1, ReadyReplicas: statefulSet.Status.ReadyReplicas, UpdatedReplicas: statefulSet.Status.UpdatedReplicas,
+			Generation: statefulSet.Generation, ObservedGeneration: statefulSet.Status.ObservedGeneration}
+		if statefulSet.Spec.Replicas != nil {
+			state.Replicas = *statefulSet.Spec.Replicas
+		}
+	case kube.DaemonSetKind:
+		if !allowDaemonSet {
+			return nil, ErrWorkloadKindNotSupported
+		}
+		daemonSets := clientSet.AppsV1().DaemonSets(namespace)
+		daemonSet, err := daemonSets.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			logger.Errorw("error in getting daemonset", "err", err, "namespace", namespace, "name", name)
+			return nil, err
+		}
+		if err = matchLabelSelector(labelSelector, daemonSet.Labels, appsV1.Resource("daemonsets"), name); err != nil {
+			return nil, err
+		}
+		patchBytes, err := patchWithVersion(daemonSet.ResourceVersion)
+		if err != nil {
+			return nil, err
+		}
+		if daemonSet, err = daemonSets.Patch(ctx, name, types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
+			logger.Errorw("error in patching daemonset", "err", err, "namespace", namespace, "name", name)
+			return nil, err
+		}
+		state = &WorkloadState{Replicas: daemonSet.Status.DesiredNumberScheduled, ReadyReplicas: daemonSet.Status.NumberReady,
+			UpdatedReplicas: daemonSet.Status.UpdatedNumberScheduled, Generation: daemonSet.Generation, ObservedGeneration: daemonSet.Status.ObservedGeneration}
+	default:
+		return nil, ErrWorkloadKindNotSupported
+	}
+	state.Namespace, state.Kind, state.Name = namespace, kind, name
+	return state, nil
+}
+
+// GetStatefulSetPVCs lists the PVCs created from the volumeClaimTemplates of the StatefulSet. The controller labels
+// them with the selector of the StatefulSet, which other PVCs of the namespace may share, so they are also matched on
+// the <template>-<statefulset>-<ordinal> name. PVCs of scaled down replicas are kept by kubernetes and are listed too
+func (impl K8sUtil) GetStatefulSetPVCs(ctx context.Context, namespace, stsName string, clusterConfig *ClusterConfig) ([]*v1.PersistentVolumeClaim, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetStatefulSetPVCs", "err", err)
+		return nil, err
+	}
+	statefulSet, err := clientSet.AppsV1().StatefulSets(namespace).Get(ctx, stsName, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting statefulset", "err", err, "namespace", namespace, "name", stsName)
+		return nil, err
+	}
+	pvcs := make([]*v1.PersistentVolumeClaim, 0)
+	if len(statefulSet.Spec.VolumeClaimTemplates) == 0 {
+		return pvcs, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(statefulSet.Spec.Selector)
+	if err != nil {
+		logger.Errorw("error in parsing statefulset selector", "err", err, "namespace", namespace, "name", stsName)
+		return nil, err
+	}
+	pvcList, err := clientSet.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		logger.Errorw("error in listing pvcs", "err", err, "namespace", namespace, "labelSelector", selector.String())
+		return nil, err
+	}
+	for i := range pvcList.Items {
+		if isStatefulSetPVC(pvcList.Items[i].Name, stsName, statefulSet.Spec.VolumeClaimTemplates) {
+			pvcs = append(pvcs, &pvcList.Items[i])
+		}
+	}
+	sort.Slice(pvcs, func(i, j int) bool {
+		return pvcs[i].Name < pvcs[j].Name
+	})
+	return pvcs, nil
+}
+
+// isStatefulSetPVC matches the <template>-<statefulset>-<ordinal> name given by the StatefulSet controller
+func isStatefulSetPVC(pvcName, stsName string, templates []v1.PersistentVolumeClaim) bool {
+	for _, template := range templates {
+		prefix := template.Name + "-" + stsName + "-"
+		if !strings.HasPrefix(pvcName, prefix) || len(pvcName) == len(prefix) {
+			continue
+		}
+		isOrdinal := true
+		for _, char := range pvcName[len(prefix):] {
+			if char < '0' || char > '9' {
+				isOrdinal = false
+				break
 			}
 		}
+		if isOrdinal {
+			return true
+		}
 	}
 	return false
 }
 
-func (impl K8sUtil) getEventKindHeader() ([]string, map[int]string) {
-	headers := []string{"type", "message", "namespace", "involved object", "source", "count", "age", "last seen"}
-	columnIndexes := make(map[int]string)
-	columnIndexes[0] = "last seen"
-	columnIndexes[1] = "type"
-	columnIndexes[2] = "namespace"
-	columnIndexes[3] = "involved object"
-	columnIndexes[5] = "source"
-	columnIndexes[6] = "message"
-	columnIndexes[7] = "age"
-	columnIndexes[8] = "count"
-	return headers, columnIndexes
+// matchLabelSelector reports objects outside of labelSelector as not found, an empty selector matches everything
+func matchLabelSelector(labelSelector string, objectLabels map[string]string, resource schema.GroupResource, name string) error {
+	if len(labelSelector) == 0 {
+		return nil
+	}
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return err
+	}
+	if !selector.Matches(labels.Set(objectLabels)) {
+		return errors.NewNotFound(resource, name)
+	}
+	return nil
+}
+
+// GetNetworkPolicy fetches a single network policy of the namespace
+func (impl K8sUtil) GetNetworkPolicy(ctx context.Context, namespace, name string, clusterConfig *ClusterConfig) (*networkingV1.NetworkPolicy, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting client set", "err", err)
+		return nil, err
+	}
+	networkPolicy, err := clientSet.NetworkingV1().NetworkPolicies(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting network policy", "err", err, "namespace", namespace, "name", name)
+		return nil, err
+	}
+	return networkPolicy, nil
+}
+
+func (impl K8sUtil) ListNetworkPolicies(ctx context.Context, namespace string, clusterConfig *ClusterConfig) ([]*networkingV1.NetworkPolicy, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting client set", "err", err)
+		return nil, err
+	}
+	networkPolicyList, err := clientSet.NetworkingV1().NetworkPolicies(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Errorw("error in listing network policies", "err", err, "namespace", namespace)
+		return nil, err
+	}
+	networkPolicies := make([]*networkingV1.NetworkPolicy, 0, len(networkPolicyList.Items))
+	for i := range networkPolicyList.Items {
+		networkPolicies = append(networkPolicies, &networkPolicyList.Items[i])
+	}
+	return networkPolicies, nil
+}
+
+// DeleteNetworkPolicy deletes the network policy, a policy which is already gone is not treated as an error
+func (impl K8sUtil) DeleteNetworkPolicy(ctx context.Context, namespace, name string, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting client set", "err", err)
+		return err
+	}
+	err = clientSet.NetworkingV1().NetworkPolicies(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Errorw("error in deleting network policy", "err", err, "namespace", namespace, "name", name)
+		return err
+	}
+	return nil
+}
+
+// DeleteAndCreateJob Deletes and recreates if job exists else creates the job
+func (impl K8sUtil) DeleteAndCreateJob(ctx context.Context, content []byte, namespace string, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	logger := LoggerFromContext(ctx, impl.logger)
+	// Job object from content
+	var job batchV1.Job
+	err := yaml.Unmarshal(content, &job)
+	if err != nil {
+		logger.Errorw("Unmarshal err, CreateJobSafely", "err", err)
+		return err
+	}
+	// checked once up front as the job is deleted before it is created again
+	if err = impl.CheckMutation(ctx, clusterConfig, namespace, "Job", job.Name, MutationActionCreate); err != nil {
+		return err
+	}
+	labelJobForManagement(ctx, &job)
+
+	// delete job if exists
+	err = impl.DeleteJob(namespace, job.Name, clusterConfig)
+	if err != nil {
+		logger.Errorw("DeleteJobIfExists err, CreateJobSafely", "err", err)
+		return err
+	}
+
+	labels := "job-name=" + job.Name
+	err = impl.DeletePodByLabel(namespace, labels, clusterConfig)
+	if err != nil {
+		logger.Errorw("DeleteJobIfExists err, CreateJobSafely", "err", err)
+		return err
+	}
+	// create job
+	err = impl.createJob(ctx, namespace, job.Name, clusterConfig, &job)
+	if err != nil {
+		logger.Errorw("CreateJob err, CreateJobSafely", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (impl K8sUtil) ListNamespaces(client *v12.CoreV1Client) (*v1.NamespaceList, error) {
+	nsList, err := client.Namespaces().List(context.Background(), metav1.ListOptions{})
+	if errors.IsNotFound(err) {
+		return nsList, nil
+	} else if err != nil {
+		return nsList, err
+	} else {
+		return nsList, nil
+	}
+}
+
+func (impl K8sUtil) GetNamespaceAnnotations(ctx context.Context, name string, client *v12.CoreV1Client) (map[string]string, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	ns, err := client.Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in fetching namespace", "namespace", name, "err", err)
+		return nil, err
+	}
+	return ns.Annotations, nil
+}
+
+// UpdateNamespaceAnnotations patches only the given annotations, a nil value removes the annotation
+func (impl K8sUtil) UpdateNamespaceAnnotations(ctx context.Context, name string, patch map[string]interface{}, client *v12.CoreV1Client) error {
+	defer impl.inflightMutations.Begin()()
+	logger := LoggerFromContext(ctx, impl.logger)
+	patchRequest := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": patch,
+		},
+	}
+	patchBytes, err := json.Marshal(patchRequest)
+	if err != nil {
+		logger.Errorw("error in marshalling namespace annotations patch", "namespace", name, "err", err)
+		return err
+	}
+	_, err = client.Namespaces().Patch(ctx, name, types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{})
+	if err != nil {
+		logger.Errorw("error in patching namespace annotations", "namespace", name, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (impl K8sUtil) GetClientByToken(serverUrl string, token map[string]string) (*v12.CoreV1Client, error) {
+	bearerToken := token["bearer_token"]
+	clusterCfg := &ClusterConfig{Host: serverUrl, BearerToken: bearerToken}
+	client, err := impl.GetClient(clusterCfg)
+	if err != nil {
+		impl.logger.Errorw("error in k8s client", "error", err)
+		return nil, err
+	}
+	return client, nil
+}
+
+func (impl K8sUtil) GetResourceInfoByLabelSelector(ctx context.Context, namespace string, labelSelector string) (*v1.Pod, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	client, err := impl.GetClientForInCluster()
+	if err != nil {
+		logger.Errorw("cluster config error", "err", err)
+		return nil, err
+	}
+	pods, err := client.Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, err
+	} else if len(pods.Items) > 1 {
+		err = &ApiError{Code: "409", HttpStatusCode: http.StatusConflict, UserMessage: "found more than one pod for label selector"}
+		return nil, err
+	} else if len(pods.Items) == 0 {
+		err = &ApiError{Code: "404", HttpStatusCode: http.StatusNotFound, UserMessage: "no pod found for label selector"}
+		return nil, err
+	} else {
+		return &pods.Items[0], nil
+	}
+}
+
+func (impl K8sUtil) GetK8sClusterRestConfig() (*rest.Config, error) {
+	impl.logger.Debug("getting k8s rest config")
+	if impl.runTimeConfig.LocalDevMode {
+		restConfig, err := clientcmd.BuildConfigFromFlags("", *impl.kubeconfig)
+		if err != nil {
+			impl.logger.Errorw("Error while building kubernetes cluster rest config", "error", err)
+			return nil, err
+		}
+		return restConfig, nil
+	} else {
+		clusterConfig, err := rest.InClusterConfig()
+		if err != nil {
+			impl.logger.Errorw("error in fetch default cluster config", "err", err)
+			return nil, err
+		}
+		return clusterConfig, nil
+	}
+}
+
+func (impl K8sUtil) GetPodByName(namespace string, name string, client *v12.CoreV1Client) (*v1.Pod, error) {
+	pod, err := client.Pods(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		impl.logger.Errorw("error in fetch pod name", "err", err)
+		return nil, err
+	} else {
+		return pod, nil
+	}
+}
+
+// GetPodConditions returns the status of each condition reported on the pod, e.g. Ready or ContainersReady
+func (impl K8sUtil) GetPodConditions(ctx context.Context, namespace, podName string, client *v12.CoreV1Client) (map[v1.PodConditionType]v1.ConditionStatus, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	pod, err := client.Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting pod", "namespace", namespace, "podName", podName, "err", err)
+		return nil, err
+	}
+	conditions := make(map[v1.PodConditionType]v1.ConditionStatus, len(pod.Status.Conditions))
+	for _, condition := range pod.Status.Conditions {
+		conditions[condition.Type] = condition.Status
+	}
+	return conditions, nil
+}
+
+// GetContainerImages returns the image of every container as desired in the pod spec, see GetRunningContainerImages
+// for the images the containers actually run
+func (impl K8sUtil) GetContainerImages(ctx context.Context, namespace, podName string, client *v12.CoreV1Client) (map[string]string, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	pod, err := client.Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting pod", "namespace", namespace, "podName", podName, "err", err)
+		return nil, err
+	}
+	images := make(map[string]string, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		images[container.Name] = container.Image
+	}
+	return images, nil
+}
+
+// GetRunningContainerImages returns the image reported in the container statuses, it differs from the spec image while
+// a container is not yet restarted after an update and can be resolved by the runtime, e.g. a tag for a digest. Containers
+// without a status yet are left out
+func (impl K8sUtil) GetRunningContainerImages(ctx context.Context, namespace, podName string, client *v12.CoreV1Client) (map[string]string, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	pod, err := client.Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting pod", "namespace", namespace, "podName", podName, "err", err)
+		return nil, err
+	}
+	images := make(map[string]string, len(pod.Status.ContainerStatuses))
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Image != "" {
+			images[status.Name] = status.Image
+		}
+	}
+	return images, nil
+}
+
+// GetPodContainerResources returns the resource requests and limits of the init and app containers of the pod
+func (impl K8sUtil) GetPodContainerResources(ctx context.Context, namespace, podName string, client *v12.CoreV1Client) ([]ContainerResourceInfo, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	pod, err := client.Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting pod", "namespace", namespace, "podName", podName, "err", err)
+		return nil, err
+	}
+	return containerResourceInfos(pod), nil
+}
+
+func containerResourceInfos(pod *v1.Pod) []ContainerResourceInfo {
+	resources := make([]ContainerResourceInfo, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	for _, container := range pod.Spec.InitContainers {
+		resources = append(resources, containerResourceInfo(container, true))
+	}
+	for _, container := range pod.Spec.Containers {
+		resources = append(resources, containerResourceInfo(container, false))
+	}
+	return resources
+}
+
+func containerResourceInfo(container v1.Container, initContainer bool) ContainerResourceInfo {
+	quantity := func(list v1.ResourceList, name v1.ResourceName) string {
+		if value, ok := list[name]; ok {
+			return value.String()
+		}
+		return ""
+	}
+	requests, limits := container.Resources.Requests, container.Resources.Limits
+	return ContainerResourceInfo{
+		ContainerName:           container.Name,
+		InitContainer:           initContainer,
+		CpuRequest:              quantity(requests, v1.ResourceCPU),
+		CpuLimit:                quantity(limits, v1.ResourceCPU),
+		MemoryRequest:           quantity(requests, v1.ResourceMemory),
+		MemoryLimit:             quantity(limits, v1.ResourceMemory),
+		EphemeralStorageRequest: quantity(requests, v1.ResourceEphemeralStorage),
+		EphemeralStorageLimit:   quantity(limits, v1.ResourceEphemeralStorage),
+	}
+}
+
+// GetPodInjectedSidecarNames returns the containers of the pod which are service mesh proxies, known by their name or
+// listed in the injection status annotation of istio. Native sidecars, i.e. init containers, are included
+func (impl K8sUtil) GetPodInjectedSidecarNames(pod *v1.Pod) []string {
+	if pod == nil {
+		return nil
+	}
+	sidecarNames := make(map[string]bool)
+	for _, name := range KnownSidecarContainerNames {
+		sidecarNames[name] = true
+	}
+	for _, annotation := range IstioSidecarStatusAnnotations {
+		value, ok := pod.Annotations[annotation]
+		if !ok {
+			continue
+		}
+		var status struct {
+			Containers     []string `json:"containers"`
+			InitContainers []string `json:"initContainers"`
+		}
+		if err := json.Unmarshal([]byte(value), &status); err != nil {
+			impl.logger.Debugw("invalid istio sidecar status annotation", "pod", pod.Name, "annotation", annotation, "err", err)
+			continue
+		}
+		for _, name := range status.Containers {
+			sidecarNames[name] = true
+		}
+	}
+	var sidecars []string
+	for _, containers := range [][]v1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			if sidecarNames[container.Name] && !util.ContainsString(sidecars, container.Name) {
+				sidecars = append(sidecars, container.Name)
+			}
+		}
+	}
+	return sidecars
+}
+
+// ReviewSelfAccess asks the api server whether the identity of the cluster config may perform each of the actions,
+// clusters are reached with their own bearer token so a self review answers for the identity requests will be made as
+func (impl K8sUtil) ReviewSelfAccess(ctx context.Context, clusterConfig *ClusterConfig, attributes []authorizationV1.ResourceAttributes) ([]AccessReviewResult, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, ReviewSelfAccess", "err", err)
+		return nil, err
+	}
+	results := make([]AccessReviewResult, 0, len(attributes))
+	for i := range attributes {
+		review := &authorizationV1.SelfSubjectAccessReview{
+			Spec: authorizationV1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes[i]},
+		}
+		review, err = clientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			logger.Errorw("error in creating self subject access review", "err", err, "attributes", attributes[i])
+			return nil, err
+		}
+		reason := review.Status.Reason
+		if len(review.Status.EvaluationError) > 0 && !review.Status.Allowed {
+			reason = strings.TrimSpace(reason + " " + review.Status.EvaluationError)
+		}
+		results = append(results, AccessReviewResult{
+			Verb:        attributes[i].Verb,
+			Resource:    attributes[i].Resource,
+			Subresource: attributes[i].Subresource,
+			Namespace:   attributes[i].Namespace,
+			Allowed:     review.Status.Allowed,
+			Reason:      reason,
+		})
+	}
+	return results, nil
+}
+
+// GetTopologySpreadConstraints returns the topology spread constraints of the pod spec
+func (impl K8sUtil) GetTopologySpreadConstraints(ctx context.Context, namespace, podName string, client *v12.CoreV1Client) ([]v1.TopologySpreadConstraint, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	pod, err := client.Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting pod", "namespace", namespace, "podName", podName, "err", err)
+		return nil, err
+	}
+	return pod.Spec.TopologySpreadConstraints, nil
+}
+
+// EvaluateTopologySpreadConstraints checks the current distribution of the pods against the topology spread constraints
+// of the deployment, a deployment without constraints is satisfied
+func (impl K8sUtil) EvaluateTopologySpreadConstraints(ctx context.Context, namespace, deploymentName string, clusterConfig *ClusterConfig) (*TopologySpreadEvaluation, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, EvaluateTopologySpreadConstraints", "err", err)
+		return nil, err
+	}
+	deployment, err := clientSet.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting deployment", "err", err, "namespace", namespace, "name", deploymentName)
+		return nil, err
+	}
+	evaluation := &TopologySpreadEvaluation{
+		Namespace:      namespace,
+		DeploymentName: deploymentName,
+		Satisfied:      true,
+		Constraints:    []*TopologySpreadConstraintStatus{},
+		Warnings:       []string{},
+	}
+	template := &deployment.Spec.Template.Spec
+	if len(template.TopologySpreadConstraints) == 0 {
+		return evaluation, nil
+	}
+	nodes, err := clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Errorw("error in listing nodes", "err", err)
+		return nil, err
+	}
+	pods, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Errorw("error in listing pods", "err", err, "namespace", namespace)
+		return nil, err
+	}
+	constraints, warnings := evaluateTopologySpread(deploymentName, template, pods.Items, nodes.Items)
+	evaluation.Constraints = constraints
+	if len(warnings) > 0 {
+		evaluation.Satisfied = false
+		evaluation.Warnings = warnings
+	}
+	return evaluation, nil
+}
+
+func (impl K8sUtil) BuildK8sObjectListTableData(manifest *unstructured.UnstructuredList, namespaced bool, gvk schema.GroupVersionKind, validateResourceAccess func(namespace string, group string, kind string, resourceName string) bool) (*ClusterResourceListMap, error) {
+	clusterResourceListMap := &ClusterResourceListMap{}
+	// build headers
+	var headers []string
+	columnIndexes := make(map[int]string)
+	kind := gvk.Kind
+	if kind == "Event" {
+		headers, columnIndexes = impl.getEventKindHeader()
+	} else {
+		columnDefinitionsUncast := manifest.Object[K8sClusterResourceColumnDefinitionKey]
+		if columnDefinitionsUncast != nil {
+			columnDefinitions := columnDefinitionsUncast.([]interface{})
+			for index, cd := range columnDefinitions {
+				if cd == nil {
+					continue
+				}
+				columnMap := cd.(map[string]interface{})
+				columnNameUncast := columnMap[K8sClusterResourceNameKey]
+				if columnNameUncast == nil {
+					continue
+				}
+				priorityUncast := columnMap[K8sClusterResourcePriorityKey]
+				if priorityUncast == nil {
+					continue
+				}
+				columnName := columnNameUncast.(string)
+				columnName = strings.ToLower(columnName)
+				priority := priorityUncast.(int64)
+				if namespaced && index == 1 {
+					headers = append(headers, K8sClusterResourceNamespaceKey)
+				}
+				if priority == 0 || (manifest.GetKind() == "Event" && columnName == "source") {
+					columnIndexes[index] = columnName
+					headers = append(headers, columnName)
+				}
+			}
+		}
+	}
+
+	// build rows
+	rowsMapping := make([]map[string]interface{}, 0)
+	rowsDataUncast := manifest.Object[K8sClusterResourceRowsKey]
+	var namespace string
+	var allowed bool
+	if rowsDataUncast != nil {
+		rows := rowsDataUncast.([]interface{})
+		for _, row := range rows {
+			namespace = ""
+			allowed = true
+			rowIndex := make(map[string]interface{})
+			rowMap := row.(map[string]interface{})
+			cellsUncast := rowMap[K8sClusterResourceCellKey]
+			if cellsUncast == nil {
+				continue
+			}
+			rowCells := cellsUncast.([]interface{})
+			for index, columnName := range columnIndexes {
+				cellValUncast := rowCells[index]
+				var cell interface{}
+				if cellValUncast == nil {
+					cell = ""
+				} else {
+					cell = cellValUncast.(interface{})
+				}
+				rowIndex[columnName] = cell
+			}
+
+			cellObjUncast := rowMap[K8sClusterResourceObjectKey]
+			var cellObj map[string]interface{}
+			if cellObjUncast != nil {
+				cellObj = cellObjUncast.(map[string]interface{})
+				if cellObj != nil && cellObj[K8sClusterResourceMetadataKey] != nil {
+					metadata := cellObj[K8sClusterResourceMetadataKey].(map[string]interface{})
+					if metadata[K8sClusterResourceNamespaceKey] != nil {
+						namespace = metadata[K8sClusterResourceNamespaceKey].(string)
+						if namespaced {
+							rowIndex[K8sClusterResourceNamespaceKey] = namespace
+						}
+					}
+				}
+			}
+			allowed = impl.ValidateResource(cellObj, gvk, validateResourceAccess)
+			if allowed {
+				rowsMapping = append(rowsMapping, rowIndex)
+			}
+		}
+	}
+
+	clusterResourceListMap.Headers = headers
+	clusterResourceListMap.Data = rowsMapping
+	impl.logger.Debugw("resource listing response", "clusterResourceListMap", clusterResourceListMap)
+	return clusterResourceListMap, nil
+}
+
+func (impl K8sUtil) ValidateResource(resourceObj map[string]interface{}, gvk schema.GroupVersionKind, validateCallback func(namespace string, group string, kind string, resourceName string) bool) bool {
+	resKind := gvk.Kind
+	groupName := gvk.Group
+	metadata := resourceObj[K8sClusterResourceMetadataKey]
+	if metadata == nil {
+		return false
+	}
+	metadataMap := metadata.(map[string]interface{})
+	var namespace, resourceName string
+	var ownerReferences []interface{}
+	if metadataMap[K8sClusterResourceNamespaceKey] != nil {
+		namespace = metadataMap[K8sClusterResourceNamespaceKey].(string)
+	}
+	if metadataMap[K8sClusterResourceMetadataNameKey] != nil {
+		resourceName = metadataMap[K8sClusterResourceMetadataNameKey].(string)
+	}
+	if metadataMap[K8sClusterResourceOwnerReferenceKey] != nil {
+		ownerReferences = metadataMap[K8sClusterResourceOwnerReferenceKey].([]interface{})
+	}
+	if len(ownerReferences) > 0 {
+		for _, ownerRef := range ownerReferences {
+			allowed := impl.validateForResource(namespace, ownerRef, validateCallback)
+			if allowed {
+				return allowed
+			}
+		}
+	}
+	// check current RBAC in case not matched with above one
+	return validateCallback(namespace, groupName, resKind, resourceName)
+}
+
+func (impl K8sUtil) validateForResource(namespace string, resourceRef interface{}, validateCallback func(namespace string, group string, kind string, resourceName string) bool) bool {
+	resourceReference := resourceRef.(map[string]interface{})
+	resKind := resourceReference[K8sClusterResourceKindKey].(string)
+	apiVersion := resourceReference[K8sClusterResourceApiVersionKey].(string)
+	groupName := ""
+	if strings.Contains(apiVersion, "/") {
+		groupName = apiVersion[:strings.LastIndex(apiVersion, "/")] // extracting group from this apiVersion
+	}
+	resName := ""
+	if resourceReference["name"] != "" {
+		resName = resourceReference["name"].(string)
+		switch resKind {
+		case kube.ReplicaSetKind:
+			// check deployment first, then RO and then RS
+			if strings.Contains(resName, "-") {
+				deploymentName := resName[:strings.LastIndex(resName, "-")]
+				allowed := validateCallback(namespace, groupName, kube.DeploymentKind, deploymentName)
+				if allowed {
+					return true
+				}
+				allowed = validateCallback(namespace, K8sClusterResourceRolloutGroup, K8sClusterResourceRolloutKind, deploymentName)
+				if allowed {
+					return true
+				}
+			}
+			allowed := validateCallback(namespace, groupName, resKind, resName)
+			if allowed {
+				return true
+			}
+		case kube.JobKind:
+			// check CronJob first, then Job
+			if strings.Contains(resName, "-") {
+				cronJobName := resName[:strings.LastIndex(resName, "-")]
+				allowed := validateCallback(namespace, groupName, K8sClusterResourceCronJobKind, cronJobName)
+				if allowed {
+					return true
+				}
+			}
+			allowed := validateCallback(namespace, groupName, resKind, resName)
+			if allowed {
+				return true
+			}
+		case kube.DeploymentKind, K8sClusterResourceCronJobKind, kube.StatefulSetKind, kube.DaemonSetKind, K8sClusterResourceRolloutKind, K8sClusterResourceReplicationControllerKind:
+			allowed := validateCallback(namespace, groupName, resKind, resName)
+			if allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (impl K8sUtil) getEventKindHeader() ([]string, map[int]string) {
+	headers := []string{"type", "message", "namespace", "involved object", "source", "count", "age", "last seen"}
+	columnIndexes := make(map[int]string)
+	columnIndexes[0] = "last seen"
+	columnIndexes[1] = "type"
+	columnIndexes[2] = "namespace"
+	columnIndexes[3] = "involved object"
+	columnIndexes[5] = "source"
+	columnIndexes[6] = "message"
+	columnIndexes[7] = "age"
+	columnIndexes[8] = "count"
+	return headers, columnIndexes
+}
+
+// GetReplicationControllerStatus reads the legacy ReplicationController workload, DesiredReplicas defaults to 1 like the
+// api server does when spec.replicas is not set
+func (impl K8sUtil) GetReplicationControllerStatus(ctx context.Context, namespace, name string, clusterConfig *ClusterConfig) (*RCStatus, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetReplicationControllerStatus", "err", err)
+		return nil, err
+	}
+	rc, err := clientSet.CoreV1().ReplicationControllers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting replication controller", "err", err, "namespace", namespace, "name", name)
+		return nil, err
+	}
+	status := &RCStatus{
+		Namespace:            namespace,
+		Name:                 name,
+		DesiredReplicas:      1,
+		Replicas:             rc.Status.Replicas,
+		ReadyReplicas:        rc.Status.ReadyReplicas,
+		AvailableReplicas:    rc.Status.AvailableReplicas,
+		FullyLabeledReplicas: rc.Status.FullyLabeledReplicas,
+		Selector:             rc.Spec.Selector,
+	}
+	if rc.Spec.Replicas != nil {
+		status.DesiredReplicas = *rc.Spec.Replicas
+	}
+	return status, nil
+}
+
+// GetRolloutStatus fetches the argo rollout through the dynamic client as rollout types are not part of client-go
+func (impl K8sUtil) GetRolloutStatus(ctx context.Context, namespace, name string, clusterConfig *ClusterConfig) (*RolloutStatus, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return nil, err
+	}
+	rollout, err := dynamicClient.Resource(RolloutGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting rollout", "err", err, "namespace", namespace, "name", name)
+		return nil, err
+	}
+	return parseRolloutStatus(rollout), nil
+}
+
+func parseRolloutStatus(rollout *unstructured.Unstructured) *RolloutStatus {
+	rolloutStatus := &RolloutStatus{Name: rollout.GetName(), Namespace: rollout.GetNamespace()}
+	rolloutStatus.Phase, _, _ = unstructured.NestedString(rollout.Object, "status", "phase")
+	rolloutStatus.Message, _, _ = unstructured.NestedString(rollout.Object, "status", "message")
+	rolloutStatus.ReadyReplicas, _, _ = unstructured.NestedInt64(rollout.Object, "status", "readyReplicas")
+	rolloutStatus.UpdatedReplicas, _, _ = unstructured.NestedInt64(rollout.Object, "status", "updatedReplicas")
+	weights, found, _ := unstructured.NestedMap(rollout.Object, "status", "canary", "weights")
+	if !found {
+		return rolloutStatus
+	}
+	canaryWeights := &RolloutCanaryWeights{}
+	if canary, ok := weights["canary"].(map[string]interface{}); ok {
+		canaryWeights.Canary = parseRolloutWeightDestination(canary)
+	}
+	if stable, ok := weights["stable"].(map[string]interface{}); ok {
+		canaryWeights.Stable = parseRolloutWeightDestination(stable)
+	}
+	if additional, ok := weights["additional"].([]interface{}); ok {
+		for _, destination := range additional {
+			if destinationMap, ok := destination.(map[string]interface{}); ok {
+				canaryWeights.Additional = append(canaryWeights.Additional, parseRolloutWeightDestination(destinationMap))
+			}
+		}
+	}
+	rolloutStatus.CanaryWeights = canaryWeights
+	return rolloutStatus
+}
+
+func parseRolloutWeightDestination(destination map[string]interface{}) RolloutWeightDestination {
+	weightDestination := RolloutWeightDestination{}
+	weightDestination.Weight, _, _ = unstructured.NestedInt64(destination, "weight")
+	weightDestination.ServiceName, _, _ = unstructured.NestedString(destination, "serviceName")
+	weightDestination.PodTemplateHash, _, _ = unstructured.NestedString(destination, "podTemplateHash")
+	return weightDestination
+}
+
+// ListRollouts lists argo rollouts of the namespace matching the label selector
+func (impl K8sUtil) ListRollouts(ctx context.Context, namespace string, labelSelector string, clusterConfig *ClusterConfig) ([]unstructured.Unstructured, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return nil, err
+	}
+	rollouts, err := dynamicClient.Resource(RolloutGVR).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		logger.Errorw("error in listing rollouts", "err", err, "namespace", namespace, "labelSelector", labelSelector)
+		return nil, err
+	}
+	return rollouts.Items, nil
+}
+
+// PauseRollout sets spec.paused on the rollout, the rollout controller stops progressing the canary until resumed
+func (impl K8sUtil) PauseRollout(ctx context.Context, namespace, name string, clusterConfig *ClusterConfig) error {
+	return impl.patchRolloutPaused(ctx, namespace, name, true, clusterConfig)
+}
+
+func (impl K8sUtil) ResumeRollout(ctx context.Context, namespace, name string, clusterConfig *ClusterConfig) error {
+	return impl.patchRolloutPaused(ctx, namespace, name, false, clusterConfig)
+}
+
+// PromoteRollout advances a canary paused at a step, fullPromotion skips the remaining steps and analysis by setting
+// status.promoteFull through the status subresource
+func (impl K8sUtil) PromoteRollout(ctx context.Context, namespace, name string, fullPromotion bool, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return err
+	}
+	rollouts := dynamicClient.Resource(RolloutGVR).Namespace(namespace)
+	if fullPromotion {
+		_, err = rollouts.Patch(ctx, name, types.MergePatchType, []byte(`{"status":{"promoteFull":true}}`), metav1.PatchOptions{}, "status")
+	} else {
+		patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, RolloutPromoteAnnotation, time.Now().UTC().Format(time.RFC3339))
+		_, err = rollouts.Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	}
+	if err != nil {
+		logger.Errorw("error in promoting rollout", "err", err, "namespace", namespace, "name", name, "fullPromotion", fullPromotion)
+		return err
+	}
+	return nil
+}
+
+// AbortRollout stops the canary and scales the stable version back up, abort is a status field and is set through the
+// status subresource the same way kubectl argo rollouts abort does
+func (impl K8sUtil) AbortRollout(ctx context.Context, namespace, name string, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return err
+	}
+	_, err = dynamicClient.Resource(RolloutGVR).Namespace(namespace).Patch(ctx, name, types.MergePatchType, []byte(`{"status":{"abort":true}}`), metav1.PatchOptions{}, "status")
+	if err != nil {
+		logger.Errorw("error in aborting rollout", "err", err, "namespace", namespace, "name", name)
+		return err
+	}
+	return nil
+}
+
+// GetRolloutRevisionHistory builds the revisions of the rollout from the replica sets it owns, latest revision first
+func (impl K8sUtil) GetRolloutRevisionHistory(ctx context.Context, namespace, name string, clusterConfig *ClusterConfig) ([]RolloutRevision, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return nil, err
+	}
+	rollout, err := dynamicClient.Resource(RolloutGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting rollout", "err", err, "namespace", namespace, "name", name)
+		return nil, err
+	}
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting client set", "err", err)
+		return nil, err
+	}
+	replicaSets, err := clientSet.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Errorw("error in listing replica sets", "err", err, "namespace", namespace)
+		return nil, err
+	}
+	stableHash, _, _ := unstructured.NestedString(rollout.Object, "status", "stableRS")
+	return buildRolloutRevisions(rollout.GetUID(), stableHash, replicaSets.Items), nil
+}
+
+func buildRolloutRevisions(rolloutUid types.UID, stableHash string, replicaSets []appsV1.ReplicaSet) []RolloutRevision {
+	revisions := make([]RolloutRevision, 0)
+	for _, replicaSet := range replicaSets {
+		owner := metav1.GetControllerOf(&replicaSet)
+		if owner == nil || owner.UID != rolloutUid {
+			continue
+		}
+		revision, err := strconv.ParseInt(replicaSet.Annotations[RolloutRevisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+		var images []string
+		for _, container := range replicaSet.Spec.Template.Spec.Containers {
+			images = append(images, container.Image)
+		}
+		podTemplateHash := replicaSet.Labels[RolloutPodTemplateHashLabel]
+		rolloutRevision := RolloutRevision{
+			Revision:        revision,
+			ReplicaSetName:  replicaSet.Name,
+			PodTemplateHash: podTemplateHash,
+			Images:          images,
+			ReadyReplicas:   replicaSet.Status.ReadyReplicas,
+			Stable:          stableHash != "" && podTemplateHash == stableHash,
+			CreatedOn:       replicaSet.CreationTimestamp.Time,
+		}
+		if replicaSet.Spec.Replicas != nil {
+			rolloutRevision.Replicas = *replicaSet.Spec.Replicas
+		}
+		revisions = append(revisions, rolloutRevision)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+	return revisions
+}
+
+func (impl K8sUtil) patchRolloutPaused(ctx context.Context, namespace, name string, paused bool, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return err
+	}
+	patch := fmt.Sprintf(`{"spec":{"paused":%t}}`, paused)
+	_, err = dynamicClient.Resource(RolloutGVR).Namespace(namespace).Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		logger.Errorw("error in patching rollout", "err", err, "namespace", namespace, "name", name, "paused", paused)
+		return err
+	}
+	return nil
+}
+
+func (impl K8sUtil) GetPodTolerations(pod *v1.Pod) []v1.Toleration {
+	if pod == nil {
+		return nil
+	}
+	return pod.Spec.Tolerations
+}
+
+func (impl K8sUtil) GetNodeTaints(ctx context.Context, nodeName string, clusterConfig *ClusterConfig) ([]v1.Taint, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	client, err := impl.GetClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting k8s client", "err", err)
+		return nil, err
+	}
+	node, err := client.Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in fetching node", "nodeName", nodeName, "err", err)
+		return nil, err
+	}
+	return node.Spec.Taints, nil
+}
+
+// GetNodeDetail returns the capacity, the requests of the scheduled pods, the live usage and the recent events of the
+// node. The result is cached for NodeDetailCacheExpiry, missing metrics-server only leaves the usage empty
+func (impl K8sUtil) GetNodeDetail(ctx context.Context, nodeName string, clusterConfig *ClusterConfig) (*NodeDetail, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	cacheKey := nodeDetailCacheKeyPrefix + clusterConfig.Host + "/" + nodeName
+	if impl.clusterInfoCache != nil {
+		if detail, found := impl.clusterInfoCache.Get(cacheKey); found {
+			return detail.(*NodeDetail), nil
+		}
+	}
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetNodeDetail", "err", err)
+		return nil, err
+	}
+	node, err := clientSet.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in fetching node", "nodeName", nodeName, "err", err)
+		return nil, err
+	}
+	detail := newNodeDetail(node)
+	pods, err := clientSet.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "spec.nodeName=" + nodeName})
+	if err != nil {
+		logger.Errorw("error in listing pods of node", "nodeName", nodeName, "err", err)
+		return nil, err
+	}
+	detail.Requests, detail.PodCount = sumNodePodRequests(pods.Items)
+	events, err := clientSet.CoreV1().Events("").List(ctx, metav1.ListOptions{FieldSelector: "involvedObject.kind=Node,involvedObject.name=" + nodeName})
+	if err != nil {
+		logger.Warnw("error in listing node events", "nodeName", nodeName, "err", err)
+	} else {
+		detail.Events = recentNodeEvents(events.Items, NodeDetailMaxEvents)
+	}
+	detail.Usage, detail.MetricsAvailable = impl.getNodeUsage(ctx, nodeName, clusterConfig)
+	if impl.clusterInfoCache != nil {
+		impl.clusterInfoCache.Set(cacheKey, detail, NodeDetailCacheExpiry)
+	}
+	return detail, nil
+}
+
+func (impl K8sUtil) getNodeUsage(ctx context.Context, nodeName string, clusterConfig *ClusterConfig) (v1.ResourceList, bool) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	cfg := &rest.Config{}
+	cfg.Host = clusterConfig.Host
+	cfg.BearerToken = clusterConfig.BearerToken
+	cfg.Insecure = true
+	impl.applyClientTunables(cfg)
+	impl.circuitBreakers.wrap(cfg, clusterConfig.ClusterId)
+	httpClient, err := OverrideK8sHttpClientWithTracer(cfg)
+	if err != nil {
+		return nil, false
+	}
+	metricsClientSet, err := metrics.NewForConfigAndClient(cfg, httpClient)
+	if err != nil {
+		logger.Warnw("error in getting metrics client set", "err", err)
+		return nil, false
+	}
+	nodeMetrics, err := metricsClientSet.MetricsV1beta1().NodeMetricses().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		logger.Debugw("node metrics not available", "nodeName", nodeName, "err", err)
+		return nil, false
+	}
+	return nodeMetrics.Usage, true
+}
+
+func newNodeDetail(node *v1.Node) *NodeDetail {
+	detail := &NodeDetail{
+		Name:          node.Name,
+		Unschedulable: node.Spec.Unschedulable,
+		Capacity:      node.Status.Capacity,
+		Allocatable:   node.Status.Allocatable,
+		Events:        []*NodeEvent{},
+		FetchedAt:     time.Now(),
+	}
+	if pods, ok := node.Status.Allocatable[v1.ResourcePods]; ok {
+		detail.PodCapacity = pods.Value()
+	}
+	for _, condition := range node.Status.Conditions {
+		switch condition.Type {
+		case v1.NodeReady:
+			detail.Ready = condition.Status == v1.ConditionTrue
+		case v1.NodeMemoryPressure, v1.NodeDiskPressure, v1.NodePIDPressure, v1.NodeNetworkUnavailable:
+			if condition.Status == v1.ConditionTrue {
+				detail.Pressure = append(detail.Pressure, string(condition.Type))
+			}
+		}
+	}
+	return detail
+}
+
+// sumNodePodRequests adds up the requests of the pods still holding resources on the node, an init container holds
+// its requests only while it runs so a pod requests the larger of its biggest init container and its containers
+func sumNodePodRequests(pods []v1.Pod) (v1.ResourceList, int) {
+	requests := v1.ResourceList{}
+	count := 0
+	for _, pod := range pods {
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		count++
+		podRequests := v1.ResourceList{}
+		for _, container := range pod.Spec.Containers {
+			addResourceList(podRequests, container.Resources.Requests)
+		}
+		for _, container := range pod.Spec.InitContainers {
+			for name, quantity := range container.Resources.Requests {
+				if current, ok := podRequests[name]; !ok || quantity.Cmp(current) > 0 {
+					podRequests[name] = quantity.DeepCopy()
+				}
+			}
+		}
+		addResourceList(podRequests, pod.Spec.Overhead)
+		addResourceList(requests, podRequests)
+	}
+	return requests, count
+}
+
+func addResourceList(list v1.ResourceList, add v1.ResourceList) {
+	for name, quantity := range add {
+		if current, ok := list[name]; ok {
+			current.Add(quantity)
+			list[name] = current
+		} else {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+func recentNodeEvents(events []v1.Event, limit int) []*NodeEvent {
+	nodeEvents := make([]*NodeEvent, 0, len(events))
+	for _, event := range events {
+		lastTimestamp := event.LastTimestamp.Time
+		if lastTimestamp.IsZero() {
+			lastTimestamp = event.EventTime.Time
+		}
+		nodeEvents = append(nodeEvents, &NodeEvent{Type: event.Type, Reason: event.Reason, Message: event.Message, Count: event.Count, LastTimestamp: lastTimestamp})
+	}
+	sort.Slice(nodeEvents, func(i, j int) bool {
+		return nodeEvents[i].LastTimestamp.After(nodeEvents[j].LastTimestamp)
+	})
+	if len(nodeEvents) > limit {
+		nodeEvents = nodeEvents[:limit]
+	}
+	return nodeEvents
+}
+
+// FindConfigReferences returns the workloads of the namespace using the ConfigMap or Secret, found through their
+// running pods and, with includeWorkloads, through the pod templates of workloads which have no pods right now
+func (impl K8sUtil) FindConfigReferences(ctx context.Context, clusterConfig *ClusterConfig, namespace, kind, name string, includeWorkloads bool) ([]ConfigReference, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	if kind != ConfigMapKind && kind != SecretKind {
+		return nil, fmt.Errorf("references can only be found for %s and %s, got %s", ConfigMapKind, SecretKind, kind)
+	}
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, FindConfigReferences", "err", err)
+		return nil, err
+	}
+	collector := newConfigReferenceCollector()
+	pods, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Errorw("error in listing pods", "err", err, "namespace", namespace)
+		return nil, err
+	}
+	owners := make(map[types.UID]*metav1.OwnerReference)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		references := podSpecConfigReferences(&pod.Spec, kind, name)
+		if len(references) == 0 {
+			continue
+		}
+		ownerKind, ownerName := impl.topLevelPodOwner(ctx, clientSet, pod, owners)
+		collector.add(ownerKind, namespace, ownerName, references)
+	}
+	if includeWorkloads {
+		err = impl.collectWorkloadConfigReferences(ctx, clientSet, clusterConfig, namespace, kind, name, collector)
+		if err != nil {
+			logger.Errorw("error in listing workloads", "err", err, "namespace", namespace)
+			return nil, err
+		}
+	}
+	return collector.list(), nil
+}
+
+// GetSecretUsage returns the pods using the secret, unlike FindConfigReferences pods are not grouped by workload so
+// that the pods restarted by a rotation are known exactly
+func (impl K8sUtil) GetSecretUsage(ctx context.Context, namespace, secretName string, clusterConfig *ClusterConfig) (*SecretUsage, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetSecretUsage", "err", err)
+		return nil, err
+	}
+	pods, err := impl.listPodConfigUsage(ctx, clientSet, namespace, SecretKind, secretName)
+	if err != nil {
+		logger.Errorw("error in listing pods", "err", err, "namespace", namespace)
+		return nil, err
+	}
+	return &SecretUsage{Namespace: namespace, SecretName: secretName, Pods: pods}, nil
+}
+
+// GetConfigMapUsage is GetSecretUsage for a ConfigMap
+func (impl K8sUtil) GetConfigMapUsage(ctx context.Context, namespace, configMapName string, clusterConfig *ClusterConfig) (*ConfigMapUsage, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetConfigMapUsage", "err", err)
+		return nil, err
+	}
+	pods, err := impl.listPodConfigUsage(ctx, clientSet, namespace, ConfigMapKind, configMapName)
+	if err != nil {
+		logger.Errorw("error in listing pods", "err", err, "namespace", namespace)
+		return nil, err
+	}
+	return &ConfigMapUsage{Namespace: namespace, ConfigMapName: configMapName, Pods: pods}, nil
+}
+
+func (impl K8sUtil) listPodConfigUsage(ctx context.Context, clientSet *kubernetes.Clientset, namespace, kind, name string) ([]PodConfigUsage, error) {
+	pods, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	usages := make([]PodConfigUsage, 0)
+	owners := make(map[types.UID]*metav1.OwnerReference)
+	for i := range pods.Items {
+		references := podSpecConfigReferences(&pods.Items[i].Spec, kind, name)
+		if len(references) > 0 {
+			ownerKind, ownerName := impl.topLevelPodOwner(ctx, clientSet, &pods.Items[i], owners)
+			usages = append(usages, PodConfigUsage{PodName: pods.Items[i].Name, OwnerKind: ownerKind, OwnerName: ownerName, References: references})
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].PodName < usages[j].PodName
+	})
+	return usages, nil
+}
+
+// GetPodTopLevelOwnerName returns the kind and name of the workload the pod belongs to, following ReplicaSet to
+// Deployment and Job to CronJob. Only the metadata of the intermediate owner is read, pods without a controller are
+// reported as is
+func (impl K8sUtil) GetPodTopLevelOwnerName(ctx context.Context, namespace string, pod *v1.Pod, clusterConfig *ClusterConfig) (kind, name string, err error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetPodTopLevelOwnerName", "err", err)
+		return "", "", err
+	}
+	kind, name, err = podTopLevelOwnerName(ctx, clientSet, namespace, pod, make(map[types.UID]*metav1.OwnerReference))
+	if err != nil {
+		logger.Errorw("error in fetching pod owner", "err", err, "namespace", namespace, "pod", pod.Name)
+		return "", "", err
+	}
+	return kind, name, nil
+}
+
+// podTopLevelOwnerName is GetPodTopLevelOwnerName with the lookups cached in owners by the uid of the intermediate
+// owner, so that pods of the same ReplicaSet or Job are resolved with a single call. An intermediate owner which is
+// gone is reported as the top level owner
+func podTopLevelOwnerName(ctx context.Context, clientSet kubernetes.Interface, namespace string, pod *v1.Pod, owners map[types.UID]*metav1.OwnerReference) (string, string, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "Pod", pod.Name, nil
+	}
+	if owner.Kind != "ReplicaSet" && owner.Kind != "Job" {
+		return owner.Kind, owner.Name, nil
+	}
+	parent, ok := owners[owner.UID]
+	if !ok {
+		metadata, err := getOwnerMetadata(ctx, clientSet, namespace, owner)
+		if err != nil && !errors.IsNotFound(err) {
+			return "", "", err
+		}
+		if err == nil {
+			parent = metav1.GetControllerOf(metadata)
+		}
+		owners[owner.UID] = parent
+	}
+	if parent == nil {
+		return owner.Kind, owner.Name, nil
+	}
+	return parent.Kind, parent.Name, nil
+}
+
+// getOwnerMetadata reads the ReplicaSet or Job as PartialObjectMetadata, the spec and status are not sent
+func getOwnerMetadata(ctx context.Context, clientSet kubernetes.Interface, namespace string, owner *metav1.OwnerReference) (*metav1.PartialObjectMetadata, error) {
+	var request *rest.Request
+	if owner.Kind == "ReplicaSet" {
+		request = clientSet.AppsV1().RESTClient().Get().Resource("replicasets")
+	} else {
+		request = clientSet.BatchV1().RESTClient().Get().Resource("jobs")
+	}
+	body, err := request.Namespace(namespace).Name(owner.Name).SetHeader("Accept", partialObjectMetadataAccept).DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &metav1.PartialObjectMetadata{}
+	if err = json.Unmarshal(body, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// topLevelPodOwner is podTopLevelOwnerName falling back to the direct controller when its owner cannot be read
+func (impl K8sUtil) topLevelPodOwner(ctx context.Context, clientSet *kubernetes.Clientset, pod *v1.Pod, owners map[types.UID]*metav1.OwnerReference) (string, string) {
+	kind, name, err := podTopLevelOwnerName(ctx, clientSet, pod.Namespace, pod, owners)
+	if err != nil {
+		LoggerFromContext(ctx, impl.logger).Warnw("error in fetching pod owner", "err", err, "pod", pod.Name)
+		owner := metav1.GetControllerOf(pod)
+		return owner.Kind, owner.Name
+	}
+	return kind, name
+}
+
+func (impl K8sUtil) collectWorkloadConfigReferences(ctx context.Context, clientSet *kubernetes.Clientset, clusterConfig *ClusterConfig, namespace, kind, name string, collector *configReferenceCollector) error {
+	deployments, err := clientSet.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		collector.add("Deployment", namespace, deployment.Name, podSpecConfigReferences(&deployment.Spec.Template.Spec, kind, name))
+	}
+	statefulSets, err := clientSet.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		collector.add("StatefulSet", namespace, statefulSet.Name, podSpecConfigReferences(&statefulSet.Spec.Template.Spec, kind, name))
+	}
+	daemonSets, err := clientSet.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, daemonSet := range daemonSets.Items {
+		collector.add("DaemonSet", namespace, daemonSet.Name, podSpecConfigReferences(&daemonSet.Spec.Template.Spec, kind, name))
+	}
+	if impl.RequireClusterFeature(ctx, clusterConfig, ClusterFeatureCronJobBatchV1) != nil {
+		cronJobs, err := clientSet.BatchV1beta1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, cronJob := range cronJobs.Items {
+			collector.add("CronJob", namespace, cronJob.Name, podSpecConfigReferences(&cronJob.Spec.JobTemplate.Spec.Template.Spec, kind, name))
+		}
+		return nil
+	}
+	cronJobs, err := clientSet.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, cronJob := range cronJobs.Items {
+		collector.add("CronJob", namespace, cronJob.Name, podSpecConfigReferences(&cronJob.Spec.JobTemplate.Spec.Template.Spec, kind, name))
+	}
+	return nil
+}
+
+func (impl K8sUtil) GetContainerSecurityContexts(ctx context.Context, namespace string, clusterConfig *ClusterConfig) ([]ContainerSecurityAudit, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetContainerSecurityContexts", "err", err)
+		return nil, err
+	}
+	pods, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Errorw("error in listing pods", "err", err, "namespace", namespace)
+		return nil, err
+	}
+	audits := make([]ContainerSecurityAudit, 0)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for j := range pod.Spec.InitContainers {
+			audits = append(audits, containerSecurityAudit(pod, &pod.Spec.InitContainers[j], true))
+		}
+		for j := range pod.Spec.Containers {
+			audits = append(audits, containerSecurityAudit(pod, &pod.Spec.Containers[j], false))
+		}
+	}
+	return audits, nil
+}
+
+// containerSecurityAudit applies kubernetes defaults, privilege escalation is allowed unless it is disabled explicitly
+func containerSecurityAudit(pod *v1.Pod, container *v1.Container, initContainer bool) ContainerSecurityAudit {
+	audit := ContainerSecurityAudit{
+		PodName:                  pod.Name,
+		ContainerName:            container.Name,
+		InitContainer:            initContainer,
+		AllowPrivilegeEscalation: true,
+		Capabilities:             make([]string, 0),
+		DroppedCapabilities:      make([]string, 0),
+	}
+	var runAsNonRoot *bool
+	if podSecurityContext := pod.Spec.SecurityContext; podSecurityContext != nil {
+		audit.RunAsUser = podSecurityContext.RunAsUser
+		runAsNonRoot = podSecurityContext.RunAsNonRoot
+	}
+	if securityContext := container.SecurityContext; securityContext != nil {
+		if securityContext.RunAsUser != nil {
+			audit.RunAsUser = securityContext.RunAsUser
+		}
+		if securityContext.RunAsNonRoot != nil {
+			runAsNonRoot = securityContext.RunAsNonRoot
+		}
+		if securityContext.Privileged != nil {
+			audit.Privileged = *securityContext.Privileged
+		}
+		if securityContext.AllowPrivilegeEscalation != nil {
+			audit.AllowPrivilegeEscalation = *securityContext.AllowPrivilegeEscalation
+		}
+		if securityContext.ReadOnlyRootFilesystem != nil {
+			audit.ReadOnlyRootFilesystem = *securityContext.ReadOnlyRootFilesystem
+		}
+		if securityContext.Capabilities != nil {
+			for _, capability := range securityContext.Capabilities.Add {
+				audit.Capabilities = append(audit.Capabilities, string(capability))
+			}
+			for _, capability := range securityContext.Capabilities.Drop {
+				audit.DroppedCapabilities = append(audit.DroppedCapabilities, string(capability))
+			}
+		}
+	}
+	if audit.RunAsUser != nil {
+		audit.RunAsRoot = *audit.RunAsUser == 0
+	} else {
+		audit.RunAsRoot = runAsNonRoot == nil || !*runAsNonRoot
+	}
+	return audit
+}
+
+// MatchTolerationToTaints returns the taints which are not tolerated by any of the given tolerations
+func (impl K8sUtil) MatchTolerationToTaints(tolerations []v1.Toleration, taints []v1.Taint) []v1.Taint {
+	var untoleratedTaints []v1.Taint
+	for i := range taints {
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(&taints[i]) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			untoleratedTaints = append(untoleratedTaints, taints[i])
+		}
+	}
+	return untoleratedTaints
+}
+
+// GetClusterInfo returns server url and version details of the cluster, feature gates are read from the
+// kubernetes_feature_enabled metric of the api server and are left empty if metrics are not accessible.
+// Result is cached per cluster for ClusterInfoCacheExpiry
+func (impl K8sUtil) GetClusterInfo(ctx context.Context, clusterConfig *ClusterConfig) (*ClusterInfo, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	if impl.clusterInfoCache != nil {
+		if clusterInfo, found := impl.clusterInfoCache.Get(clusterConfig.Host); found {
+			return clusterInfo.(*ClusterInfo), nil
+		}
+	}
+	discoveryClient, err := impl.GetK8sDiscoveryClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting discovery client", "host", clusterConfig.Host, "err", err)
+		return nil, err
+	}
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		logger.Errorw("error in fetching server version", "host", clusterConfig.Host, "err", err)
+		return nil, err
+	}
+	clusterInfo := &ClusterInfo{
+		ServerURL:    clusterConfig.Host,
+		Version:      fmt.Sprintf("%s.%s", serverVersion.Major, serverVersion.Minor),
+		Platform:     serverVersion.Platform,
+		GitVersion:   serverVersion.GitVersion,
+		FeatureGates: make(map[string]bool),
+	}
+	metrics, err := discoveryClient.RESTClient().Get().AbsPath("/metrics").DoRaw(ctx)
+	if err != nil {
+		logger.Warnw("unable to read api server metrics for feature gates", "host", clusterConfig.Host, "err", err)
+	} else {
+		clusterInfo.FeatureGates = parseFeatureGatesFromMetrics(string(metrics))
+	}
+	if impl.clusterInfoCache != nil {
+		impl.clusterInfoCache.SetDefault(clusterConfig.Host, clusterInfo)
+	}
+	return clusterInfo, nil
+}
+
+// GetClusterCIDR returns the pod and service ranges and the cluster dns address used to generate network policies, ranges
+// are read from the control plane pods and fall back to the kubeadm configuration
+func (impl K8sUtil) GetClusterCIDR(ctx context.Context, clusterConfig *ClusterConfig) (*ClusterCIDR, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetClusterCIDR", "err", err)
+		return nil, err
+	}
+	cidr := &ClusterCIDR{}
+	if err = controlPlaneCIDRs(ctx, clientSet, cidr); err != nil {
+		logger.Errorw("error in reading control plane flags", "host", clusterConfig.Host, "err", err)
+		return nil, err
+	}
+	if err = configMapCIDRs(ctx, clientSet, cidr); err != nil {
+		logger.Errorw("error in reading cluster configuration", "host", clusterConfig.Host, "err", err)
+		return nil, err
+	}
+	cidr.DNSServiceIP, err = dnsServiceIP(ctx, clientSet)
+	if err != nil {
+		logger.Errorw("error in getting dns service", "host", clusterConfig.Host, "err", err)
+		return nil, err
+	}
+	return cidr, nil
+}
+
+// GetAPIServerHealthz reads /healthz?verbose and returns the status of every check of the api server, e.g. etcd -> ok.
+// An unhealthy api server answers with 500 and the failing checks, that is returned as unhealthy and not as an error
+func (impl K8sUtil) GetAPIServerHealthz(ctx context.Context, clusterConfig *ClusterConfig) (bool, map[string]string, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetAPIServerHealthz", "err", err)
+		return false, nil, err
+	}
+	response, err := clientSet.Discovery().RESTClient().Get().AbsPath("/healthz").Param("verbose", "").DoRaw(ctx)
+	components := parseHealthzVerbose(string(response))
+	if err != nil && len(components) == 0 {
+		logger.Errorw("error in getting api server healthz", "host", clusterConfig.Host, "err", err)
+		return false, nil, err
+	}
+	healthy := err == nil
+	for _, status := range components {
+		if status != HealthzStatusOk {
+			healthy = false
+		}
+	}
+	return healthy, components, nil
+}
+
+// GetClusterCapabilities returns the capability matrix of the cluster, it is refreshed by the cluster health cron and
+// cached for ClusterInfoCacheExpiry
+func (impl K8sUtil) GetClusterCapabilities(ctx context.Context, clusterConfig *ClusterConfig) (*ClusterCapabilities, error) {
+	if impl.clusterInfoCache != nil {
+		if capabilities, found := impl.clusterInfoCache.Get(clusterCapabilitiesCacheKeyPrefix + clusterConfig.Host); found {
+			return capabilities.(*ClusterCapabilities), nil
+		}
+	}
+	return impl.RefreshClusterCapabilities(ctx, clusterConfig)
+}
+
+func (impl K8sUtil) RefreshClusterCapabilities(ctx context.Context, clusterConfig *ClusterConfig) (*ClusterCapabilities, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	discoveryClient, err := impl.GetK8sDiscoveryClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting discovery client", "host", clusterConfig.Host, "err", err)
+		return nil, err
+	}
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		logger.Errorw("error in fetching server version", "host", clusterConfig.Host, "err", err)
+		return nil, err
+	}
+	// managed offerings report versions like "1" and "21+"
+	major, _ := strconv.Atoi(strings.TrimRight(serverVersion.Major, "+"))
+	minor, _ := strconv.Atoi(strings.TrimRight(serverVersion.Minor, "+"))
+	capabilities := &ClusterCapabilities{
+		Version:  fmt.Sprintf("%d.%d", major, minor),
+		Features: make(map[string]bool),
+	}
+	for feature, requirement := range clusterFeatureRequirements {
+		capabilities.Features[feature] = major > 1 || (major == 1 && minor >= requirement.MinMinor)
+	}
+	// discovery is authoritative for what is served, version checks above cover what is enabled by default
+	cronJobServed, err := impl.isKindServed(discoveryClient, "batch/v1", "CronJob")
+	if err == nil {
+		capabilities.Features[ClusterFeatureCronJobBatchV1] = cronJobServed
+	}
+	coreResources, err := discoveryClient.ServerResourcesForGroupVersion("v1")
+	if err == nil {
+		evictionV1, ephemeralContainers := false, false
+		for _, resource := range coreResources.APIResources {
+			switch resource.Name {
+			case "pods/eviction":
+				evictionV1 = resource.Group == "policy" && resource.Version == "v1"
+			case "pods/ephemeralcontainers":
+				ephemeralContainers = true
+			}
+		}
+		capabilities.Features[ClusterFeatureEvictionPolicyV1] = evictionV1
+		capabilities.Features[ClusterFeatureEphemeralContainers] = capabilities.Features[ClusterFeatureEphemeralContainers] && ephemeralContainers
+	}
+	capabilities.EncryptionAtRest = &EncryptionAtRestStatus{Status: EncryptionAtRestUnknown, Kms: KmsUnknown}
+	if clientSet, err := impl.GetClientSet(clusterConfig); err == nil {
+		capabilities.EncryptionAtRest = impl.probeEncryptionAtRest(ctx, clientSet)
+	}
+	if impl.clusterInfoCache != nil {
+		impl.clusterInfoCache.SetDefault(clusterCapabilitiesCacheKeyPrefix+clusterConfig.Host, capabilities)
+	}
+	return capabilities, nil
+}
+
+// RequireClusterFeature returns ErrClusterFeatureUnsupported if the cluster lacks the feature, if capabilities can not
+// be read the call is let through so that the api server decides
+func (impl K8sUtil) RequireClusterFeature(ctx context.Context, clusterConfig *ClusterConfig, feature string) error {
+	capabilities, err := impl.GetClusterCapabilities(ctx, clusterConfig)
+	if err != nil {
+		LoggerFromContext(ctx, impl.logger).Warnw("unable to read cluster capabilities", "host", clusterConfig.Host, "feature", feature, "err", err)
+		return nil
+	}
+	if capabilities.Supports(feature) {
+		return nil
+	}
+	return &ErrClusterFeatureUnsupported{Feature: feature, Version: capabilities.Version, RequiredVersion: fmt.Sprintf("1.%d", clusterFeatureRequirements[feature].MinMinor)}
+}
+
+// SecretEncryptionWarning returns the warning for flows storing secrets in the cluster, it never fails the flow
+func (impl K8sUtil) SecretEncryptionWarning(ctx context.Context, clusterConfig *ClusterConfig) string {
+	capabilities, err := impl.GetClusterCapabilities(ctx, clusterConfig)
+	if err != nil {
+		LoggerFromContext(ctx, impl.logger).Warnw("unable to read cluster capabilities for encryption warning", "host", clusterConfig.Host, "err", err)
+		return EncryptionAtRestWarning(nil)
+	}
+	return EncryptionAtRestWarning(capabilities.EncryptionAtRest)
+}
+
+func (impl K8sUtil) GetVirtualService(ctx context.Context, namespace, name string, clusterConfig *ClusterConfig) (*unstructured.Unstructured, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return nil, err
+	}
+	virtualService, err := dynamicClient.Resource(VirtualServiceGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting virtual service", "err", err, "namespace", namespace, "name", name)
+		return nil, err
+	}
+	return virtualService, nil
+}
+
+// UpsertVirtualService creates the virtual service or replaces the spec of the existing one, labels and annotations
+// of the existing object are kept so that those added by other controllers are not lost
+func (impl K8sUtil) UpsertVirtualService(ctx context.Context, vs *unstructured.Unstructured, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, vs.GetNamespace(), true); err != nil {
+		return err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return err
+	}
+	virtualServices := dynamicClient.Resource(VirtualServiceGVR).Namespace(vs.GetNamespace())
+	existing, err := virtualServices.Get(ctx, vs.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		impl.annotateWithRequestId(ctx, vs)
+		_, err = virtualServices.Create(ctx, vs, metav1.CreateOptions{})
+		if err != nil {
+			logger.Errorw("error in creating virtual service", "err", err, "namespace", vs.GetNamespace(), "name", vs.GetName())
+		}
+		return err
+	} else if err != nil {
+		logger.Errorw("error in getting virtual service", "err", err, "namespace", vs.GetNamespace(), "name", vs.GetName())
+		return err
+	}
+	spec, _, err := unstructured.NestedFieldCopy(vs.Object, "spec")
+	if err != nil {
+		return err
+	}
+	if err = unstructured.SetNestedField(existing.Object, spec, "spec"); err != nil {
+		return err
+	}
+	existing.SetLabels(mergeStringMaps(existing.GetLabels(), vs.GetLabels()))
+	existing.SetAnnotations(mergeStringMaps(existing.GetAnnotations(), vs.GetAnnotations()))
+	_, err = virtualServices.Update(ctx, existing, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Errorw("error in updating virtual service", "err", err, "namespace", vs.GetNamespace(), "name", vs.GetName())
+		return err
+	}
+	return nil
+}
+
+// BuildVirtualService builds an istio virtual service for the routes, hosts are the distinct destination hosts of the
+// routes in order of appearance
+func BuildVirtualService(name, namespace string, routes []HTTPRoute) *unstructured.Unstructured {
+	var hosts []interface{}
+	seenHosts := make(map[string]bool)
+	var httpRoutes []interface{}
+	for _, route := range routes {
+		httpRoute := map[string]interface{}{}
+		if len(route.Name) > 0 {
+			httpRoute["name"] = route.Name
+		}
+		var matches []interface{}
+		for _, match := range route.Match {
+			matchRequest := map[string]interface{}{}
+			if len(match.UriPrefix) > 0 {
+				matchRequest["uri"] = map[string]interface{}{"prefix": match.UriPrefix}
+			}
+			if len(match.Headers) > 0 {
+				headers := map[string]interface{}{}
+				for header, value := range match.Headers {
+					headers[header] = map[string]interface{}{"exact": value}
+				}
+				matchRequest["headers"] = headers
+			}
+			matches = append(matches, matchRequest)
+		}
+		if len(matches) > 0 {
+			httpRoute["match"] = matches
+		}
+		var destinations []interface{}
+		for _, destination := range route.Route {
+			if !seenHosts[destination.Host] {
+				seenHosts[destination.Host] = true
+				hosts = append(hosts, destination.Host)
+			}
+			target := map[string]interface{}{"host": destination.Host}
+			if len(destination.Subset) > 0 {
+				target["subset"] = destination.Subset
+			}
+			if destination.Port > 0 {
+				target["port"] = map[string]interface{}{"number": destination.Port}
+			}
+			routeDestination := map[string]interface{}{"destination": target}
+			if destination.Weight > 0 {
+				routeDestination["weight"] = destination.Weight
+			}
+			destinations = append(destinations, routeDestination)
+		}
+		httpRoute["route"] = destinations
+		httpRoutes = append(httpRoutes, httpRoute)
+	}
+	virtualService := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"hosts": hosts,
+			"http":  httpRoutes,
+		},
+	}}
+	virtualService.SetAPIVersion(VirtualServiceGVR.GroupVersion().String())
+	virtualService.SetKind(VirtualServiceKind)
+	virtualService.SetName(name)
+	virtualService.SetNamespace(namespace)
+	return virtualService
+}
+
+func mergeStringMaps(base map[string]string, overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(overrides))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	return merged
+}
+
+// GetServiceMeshInfo detects istio, openshift service mesh and linkerd from their crds and control plane deployments.
+// Result is cached per cluster for ClusterInfoCacheExpiry
+func (impl K8sUtil) GetServiceMeshInfo(ctx context.Context, clusterConfig *ClusterConfig) (*ServiceMeshInfo, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	cacheKey := serviceMeshInfoCacheKeyPrefix + clusterConfig.Host
+	if impl.clusterInfoCache != nil {
+		if serviceMeshInfo, found := impl.clusterInfoCache.Get(cacheKey); found {
+			return serviceMeshInfo.(*ServiceMeshInfo), nil
+		}
+	}
+	discoveryClient, err := impl.GetK8sDiscoveryClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting discovery client", "host", clusterConfig.Host, "err", err)
+		return nil, err
+	}
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting client set", "host", clusterConfig.Host, "err", err)
+		return nil, err
+	}
+	serviceMeshInfo := &ServiceMeshInfo{}
+	for _, crd := range serviceMeshCrds {
+		found, err := impl.isKindServed(discoveryClient, crd.groupVersion, crd.kind)
+		if err != nil {
+			logger.Errorw("error in checking service mesh crd", "host", clusterConfig.Host, "groupVersion", crd.groupVersion, "err", err)
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		serviceMeshInfo.DetectedCrds = append(serviceMeshInfo.DetectedCrds, crd.kind+"."+crd.groupVersion)
+		if len(serviceMeshInfo.Type) == 0 {
+			serviceMeshInfo.Type = crd.meshType
+		}
+	}
+	// crds can be left behind by an uninstall, the control plane deployment decides if the mesh is actually running
+	controlPlaneNamespace, controlPlaneType := IstioNamespace, ServiceMeshTypeIstio
+	if serviceMeshInfo.Type == ServiceMeshTypeLinkerd {
+		controlPlaneNamespace, controlPlaneType = LinkerdNamespace, ServiceMeshTypeLinkerd
+	}
+	for _, deploymentName := range serviceMeshControlPlanes[controlPlaneType] {
+		deployment, err := clientSet.AppsV1().Deployments(controlPlaneNamespace).Get(ctx, deploymentName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			logger.Errorw("error in getting service mesh control plane", "host", clusterConfig.Host, "deployment", deploymentName, "err", err)
+			return nil, err
+		}
+		serviceMeshInfo.Installed = true
+		if len(serviceMeshInfo.Type) == 0 {
+			serviceMeshInfo.Type = controlPlaneType
+		}
+		serviceMeshInfo.Version = serviceMeshVersion(deployment)
+		break
+	}
+	if serviceMeshInfo.Installed {
+		serviceMeshInfo.InjectionMode = ServiceMeshInjectionSidecar
+		if controlPlaneType == ServiceMeshTypeIstio {
+			_, err = clientSet.AppsV1().DaemonSets(IstioNamespace).Get(ctx, IstioAmbientDaemonSetName, metav1.GetOptions{})
+			if err == nil {
+				serviceMeshInfo.InjectionMode = ServiceMeshInjectionAmbient
+			} else if !errors.IsNotFound(err) {
+				logger.Warnw("unable to check istio ambient mode", "host", clusterConfig.Host, "err", err)
+			}
+		}
+	}
+	if impl.clusterInfoCache != nil {
+		impl.clusterInfoCache.SetDefault(cacheKey, serviceMeshInfo)
+	}
+	return serviceMeshInfo, nil
+}
+
+func (impl K8sUtil) isKindServed(discoveryClient *discovery.DiscoveryClient, groupVersion string, kind string) (bool, error) {
+	apiResource, err := impl.serverResourceForKind(discoveryClient, groupVersion, kind)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return apiResource != nil, nil
+}
+
+// serverResourceForKind returns nil if the group version is served but does not have the kind, subresources are skipped
+func (impl K8sUtil) serverResourceForKind(discoveryClient *discovery.DiscoveryClient, groupVersion string, kind string) (*metav1.APIResource, error) {
+	resourceList, err := discoveryClient.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range resourceList.APIResources {
+		if resource.Kind == kind && !strings.Contains(resource.Name, "/") {
+			return &resource, nil
+		}
+	}
+	return nil, nil
+}
+
+// getResourceInterface resolves the resource of gvk through discovery, namespace is ignored for cluster scoped kinds
+func (impl K8sUtil) getResourceInterface(ctx context.Context, gvk schema.GroupVersionKind, namespace string, clusterConfig *ClusterConfig) (dynamic.ResourceInterface, *metav1.APIResource, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	discoveryClient, err := impl.GetK8sDiscoveryClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting discovery client", "err", err)
+		return nil, nil, err
+	}
+	apiResource, err := impl.serverResourceForKind(discoveryClient, gvk.GroupVersion().String(), gvk.Kind)
+	if err != nil {
+		logger.Errorw("error in getting server resources", "err", err, "groupVersion", gvk.GroupVersion().String())
+		return nil, nil, err
+	}
+	if apiResource == nil {
+		return nil, nil, NewKindNotServedError(gvk)
+	}
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return nil, nil, err
+	}
+	resourceIf := dynamicClient.Resource(gvk.GroupVersion().WithResource(apiResource.Name))
+	if apiResource.Namespaced {
+		return resourceIf.Namespace(namespace), apiResource, nil
+	}
+	return resourceIf, apiResource, nil
+}
+
+// GetResource fetches any resource, namespace is ignored for cluster scoped kinds and is required for namespaced ones
+func (impl K8sUtil) GetResource(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string, clusterConfig *ClusterConfig) (*unstructured.Unstructured, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	resourceIf, apiResource, err := impl.getResourceInterface(ctx, gvk, namespace, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	if apiResource.Namespaced && namespace == "" {
+		return nil, fmt.Errorf("namespace is required for namespaced kind %s", gvk.Kind)
+	}
+	resource, err := resourceIf.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting resource", "err", err, "gvk", gvk, "namespace", namespace, "name", name)
+		return nil, err
+	}
+	return resource, nil
+}
+
+func (impl K8sUtil) DeleteResource(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	resourceIf, _, err := impl.getResourceInterface(ctx, gvk, namespace, clusterConfig)
+	if err != nil {
+		return err
+	}
+	err = resourceIf.Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		logger.Errorw("error in deleting resource", "err", err, "gvk", gvk, "namespace", namespace, "name", name)
+		return err
+	}
+	return nil
+}
+
+// DeleteResourceByUid deletes the object only while it still has the uid, so that an object recreated under the same
+// name is left alone. Dependents such as the pods of a job are deleted in the background
+func (impl K8sUtil) DeleteResourceByUid(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string, uid types.UID, clusterConfig *ClusterConfig) error {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	resourceIf, _, err := impl.getResourceInterface(ctx, gvk, namespace, clusterConfig)
+	if err != nil {
+		return err
+	}
+	propagationPolicy := metav1.DeletePropagationBackground
+	err = resourceIf.Delete(ctx, name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}, PropagationPolicy: &propagationPolicy})
+	if err != nil {
+		logger.Errorw("error in deleting resource", "err", err, "gvk", gvk, "namespace", namespace, "name", name, "uid", uid)
+		return err
+	}
+	return nil
+}
+
+// GetResourceManifest fetches any resource as yaml for the manifest viewer, managed fields are stripped as they are
+// noise for users. namespace is ignored for cluster scoped kinds and is required for namespaced ones
+func (impl K8sUtil) GetResourceManifest(ctx context.Context, namespace, name, group, version, kind string, clusterConfig *ClusterConfig) ([]byte, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	gvk := schema.GroupVersionKind{Group: group, Version: version, Kind: kind}
+	resource, err := impl.GetResource(ctx, gvk, namespace, name, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(resource.Object, "metadata", "managedFields")
+	manifest, err := yaml.Marshal(resource.Object)
+	if err != nil {
+		logger.Errorw("error in marshalling resource to yaml", "err", err, "gvk", gvk, "namespace", namespace, "name", name)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// DiffAgainstLive diffs the desired object against the live one before it is applied. The desired object is run
+// through a server side dry run apply when the server allows it so that defaulted fields do not show up as changes.
+// Secret values are redacted on both sides and objects are never logged
+func (impl K8sUtil) DiffAgainstLive(ctx context.Context, clusterConfig *ClusterConfig, desired *unstructured.Unstructured) (*ManifestDiff, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	gvk := desired.GroupVersionKind()
+	namespace, name := desired.GetNamespace(), desired.GetName()
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	resourceIf, _, err := impl.getResourceInterface(ctx, gvk, namespace, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	live, err := resourceIf.Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		live = nil
+	} else if err != nil {
+		logger.Errorw("error in getting live object", "err", err, "gvk", gvk, "namespace", namespace, "name", name)
+		return nil, err
+	}
+	serverDryRun := true
+	dryRun, err := impl.dryRunApply(ctx, resourceIf, desired)
+	if err != nil {
+		// older servers, admission webhooks without dry run support or missing patch permission, diff what we have
+		logger.Warnw("server dry run apply failed, diffing without server defaults", "err", err, "gvk", gvk, "namespace", namespace, "name", name)
+		dryRun, serverDryRun = desired, false
+	}
+	liveObject, desiredObject := normalizeForDiff(live), normalizeForDiff(dryRun)
+	if gvk.Group == "" && gvk.Kind == SecretKind {
+		redactSecretData(liveObject, desiredObject)
+	}
+	manifestDiff, err := buildManifestDiff(liveObject, desiredObject)
+	if err != nil {
+		logger.Errorw("error in building manifest diff", "err", err, "gvk", gvk, "namespace", namespace, "name", name)
+		return nil, err
+	}
+	manifestDiff.ServerDryRun = serverDryRun
+	return manifestDiff, nil
+}
+
+func (impl K8sUtil) dryRunApply(ctx context.Context, resourceIf dynamic.ResourceInterface, desired *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	data, err := desired.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	force := true
+	return resourceIf.Patch(ctx, desired.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		DryRun:       []string{metav1.DryRunAll},
+		FieldManager: ManifestDiffFieldManager,
+		Force:        &force,
+	})
+}
+
+// ApplyManifest server side applies the manifest, conflicts with other managers are forced as devtron owns what it
+// applies. Namespaced objects without a namespace go to the default namespace like kubectl does
+func (impl K8sUtil) ApplyManifest(ctx context.Context, manifest *unstructured.Unstructured, clusterConfig *ClusterConfig, fieldManager string) (*AppliedResource, error) {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, ManifestNamespace(manifest, ""), true); err != nil {
+		return nil, err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	if fieldManager == "" {
+		fieldManager = DefaultApplyFieldManager
+	}
+	gvk := manifest.GroupVersionKind()
+	namespace := manifest.GetNamespace()
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	resourceIf, apiResource, err := impl.getResourceInterface(ctx, gvk, namespace, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	manifest = manifest.DeepCopy()
+	if apiResource.Namespaced {
+		manifest.SetNamespace(namespace)
+	}
+	appliedResource := &AppliedResource{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind, Namespace: manifest.GetNamespace(), Name: manifest.GetName()}
+	_, err = resourceIf.Get(ctx, manifest.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		appliedResource.Created = true
+		impl.annotateWithRequestId(ctx, manifest)
+	} else if err != nil {
+		logger.Errorw("error in getting resource before apply", "err", err, "gvk", gvk, "namespace", manifest.GetNamespace(), "name", manifest.GetName())
+		return nil, err
+	}
+	data, err := manifest.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	force := true
+	_, err = resourceIf.Patch(ctx, manifest.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: fieldManager, Force: &force})
+	if err != nil {
+		logger.Errorw("error in applying resource", "err", err, "gvk", gvk, "namespace", manifest.GetNamespace(), "name", manifest.GetName())
+		return nil, err
+	}
+	return appliedResource, nil
+}
+
+// ApplyKustomization renders the overlay at kustomizationPath and applies the resources in kustomize legacy order so
+// that namespaces and crds go first. On failure the resources applied so far are returned with the error
+func (impl K8sUtil) ApplyKustomization(ctx context.Context, kustomizationPath string, clusterConfig *ClusterConfig, fieldManager string) ([]AppliedResource, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	options := krusty.MakeDefaultOptions()
+	options.DoLegacyResourceSort = true
+	resMap, err := krusty.MakeKustomizer(options).Run(filesys.MakeFsOnDisk(), kustomizationPath)
+	if err != nil {
+		logger.Errorw("error in rendering kustomization", "err", err, "path", kustomizationPath)
+		return nil, err
+	}
+	appliedResources := make([]AppliedResource, 0, resMap.Size())
+	for _, resource := range resMap.Resources() {
+		object, err := resource.Map()
+		if err != nil {
+			logger.Errorw("error in reading rendered resource", "err", err, "path", kustomizationPath, "resource", resource.CurId().String())
+			return appliedResources, err
+		}
+		appliedResource, err := impl.ApplyManifest(ctx, &unstructured.Unstructured{Object: object}, clusterConfig, fieldManager)
+		if err != nil {
+			return appliedResources, fmt.Errorf("applying %s: %w", resource.CurId().String(), err)
+		}
+		appliedResources = append(appliedResources, *appliedResource)
+	}
+	return appliedResources, nil
+}
+
+// ListVolumeSnapshots lists the csi volume snapshots of the namespace, all namespaces when it is empty. Clusters without
+// the snapshot crds have no snapshots
+func (impl K8sUtil) ListVolumeSnapshots(ctx context.Context, namespace string, clusterConfig *ClusterConfig) ([]*unstructured.Unstructured, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return nil, err
+	}
+	snapshotList, err := dynamicClient.Resource(VolumeSnapshotGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if errors.IsNotFound(err) {
+		logger.Debugw("volume snapshot crd not installed", "clusterId", clusterConfig.ClusterId)
+		return []*unstructured.Unstructured{}, nil
+	} else if err != nil {
+		logger.Errorw("error in listing volume snapshots", "err", err, "namespace", namespace)
+		return nil, err
+	}
+	snapshots := make([]*unstructured.Unstructured, 0, len(snapshotList.Items))
+	for i := range snapshotList.Items {
+		snapshots = append(snapshots, &snapshotList.Items[i])
+	}
+	return snapshots, nil
+}
+
+// GetVolumeSnapshotList lists the volume snapshots of the namespace with their source pvc and readiness
+func (impl K8sUtil) GetVolumeSnapshotList(ctx context.Context, namespace string, clusterConfig *ClusterConfig) ([]*VolumeSnapshotInfo, error) {
+	snapshots, err := impl.ListVolumeSnapshots(ctx, namespace, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	snapshotInfos := make([]*VolumeSnapshotInfo, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		snapshotInfos = append(snapshotInfos, ParseVolumeSnapshotInfo(snapshot))
+	}
+	return snapshotInfos, nil
+}
+
+// ListMountedPvcNames lists the pvcs mounted by the pods of the namespace matching labelSelector
+func (impl K8sUtil) ListMountedPvcNames(ctx context.Context, namespace, labelSelector string, clusterConfig *ClusterConfig) ([]string, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, ListMountedPvcNames", "err", err)
+		return nil, err
+	}
+	pods, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		logger.Errorw("error in listing pods", "err", err, "namespace", namespace, "labelSelector", labelSelector)
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	pvcNames := make([]string, 0)
+	for _, pod := range pods.Items {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil || seen[volume.PersistentVolumeClaim.ClaimName] {
+				continue
+			}
+			seen[volume.PersistentVolumeClaim.ClaimName] = true
+			pvcNames = append(pvcNames, volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	sort.Strings(pvcNames)
+	return pvcNames, nil
+}
+
+// CreateVolumeSnapshot snapshots the pvc, the name is generated from the pvc name and an empty snapshotClassName
+// leaves the choice to the default snapshot class of the cluster
+func (impl K8sUtil) CreateVolumeSnapshot(ctx context.Context, namespace, pvcName, snapshotClassName string, clusterConfig *ClusterConfig) (*VolumeSnapshotInfo, error) {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return nil, err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return nil, err
+	}
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{"persistentVolumeClaimName": pvcName},
+	}
+	if len(snapshotClassName) > 0 {
+		spec["volumeSnapshotClassName"] = snapshotClassName
+	}
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	snapshot.SetAPIVersion(VolumeSnapshotGVR.GroupVersion().String())
+	snapshot.SetKind(VolumeSnapshotKind)
+	snapshot.SetGenerateName(fmt.Sprintf("%.40s-snapshot-", pvcName))
+	snapshot.SetNamespace(namespace)
+	snapshot, err = dynamicClient.Resource(VolumeSnapshotGVR).Namespace(namespace).Create(ctx, snapshot, metav1.CreateOptions{})
+	if err != nil {
+		logger.Errorw("error in creating volume snapshot", "err", err, "namespace", namespace, "pvcName", pvcName, "snapshotClassName", snapshotClassName)
+		return nil, err
+	}
+	return ParseVolumeSnapshotInfo(snapshot), nil
+}
+
+// ParseVolumeSnapshotInfo reads a snapshot.storage.k8s.io/v1 VolumeSnapshot, CreationTime is when the storage system
+// took the snapshot and stays nil until then
+func ParseVolumeSnapshotInfo(snapshot *unstructured.Unstructured) *VolumeSnapshotInfo {
+	snapshotInfo := &VolumeSnapshotInfo{Name: snapshot.GetName(), Namespace: snapshot.GetNamespace()}
+	snapshotInfo.SourcePvcName, _, _ = unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	snapshotInfo.SnapshotClassName, _, _ = unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+	snapshotInfo.ReadyToUse, _, _ = unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	snapshotInfo.RestoreSize, _, _ = unstructured.NestedString(snapshot.Object, "status", "restoreSize")
+	snapshotInfo.Error, _, _ = unstructured.NestedString(snapshot.Object, "status", "error", "message")
+	if creationTime, found, _ := unstructured.NestedString(snapshot.Object, "status", "creationTime"); found {
+		if parsed, err := time.Parse(time.RFC3339, creationTime); err == nil {
+			snapshotInfo.CreationTime = &parsed
+		}
+	}
+	return snapshotInfo
+}
+
+// serviceMeshVersion reads the version label set by linkerd and falls back to the control plane image tag
+func serviceMeshVersion(deployment *appsV1.Deployment) string {
+	if version, ok := deployment.Labels[LinkerdVersionLabel]; ok {
+		return version
+	}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if tagIndex := strings.LastIndex(container.Image, ":"); tagIndex > strings.LastIndex(container.Image, "/") {
+			return container.Image[tagIndex+1:]
+		}
+	}
+	return ""
+}
+
+// parseFeatureGatesFromMetrics reads lines like kubernetes_feature_enabled{name="X",stage="BETA"} 1
+func parseFeatureGatesFromMetrics(metrics string) map[string]bool {
+	featureGates := make(map[string]bool)
+	for _, line := range strings.Split(metrics, "\n") {
+		if !strings.HasPrefix(line, FeatureEnabledMetricName+"{") {
+			continue
+		}
+		nameStart := strings.Index(line, `name="`)
+		if nameStart < 0 {
+			continue
+		}
+		name := line[nameStart+len(`name="`):]
+		nameEnd := strings.Index(name, `"`)
+		if nameEnd < 0 {
+			continue
+		}
+		fields := strings.Fields(line)
+		featureGates[name[:nameEnd]] = fields[len(fields)-1] == "1"
+	}
+	return featureGates
+}
+
+// parseCgroupUsage reads the output of cat on the memory and cpu accounting files, one value per line
+func parseCgroupUsage(output string) (*ResourceConsumption, error) {
+	lines := strings.Fields(output)
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("unexpected cgroup usage output %q", output)
+	}
+	memoryBytes, err := strconv.ParseInt(lines[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory usage %q: %v", lines[0], err)
+	}
+	cpuUsage, err := strconv.ParseInt(lines[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cpu usage %q: %v", lines[1], err)
+	}
+	return &ResourceConsumption{MemoryBytes: memoryBytes, CpuUsageNanoseconds: cpuUsage}, nil
+}
+
+// parseHealthzVerbose reads the check lines of a verbose healthz response, "[+]ping ok" or "[-]etcd failed: reason withheld"
+func parseHealthzVerbose(output string) map[string]string {
+	components := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "[+]") && !strings.HasPrefix(line, "[-]") {
+			continue
+		}
+		check := strings.SplitN(line[3:], " ", 2)
+		status := HealthzStatusFailed
+		if len(check) == 2 {
+			status = strings.TrimSpace(check[1])
+		}
+		components[check[0]] = status
+	}
+	return components
 }
 
 func OverrideK8sHttpClientWithTracer(restConfig *rest.Config) (*http.Client, error) {
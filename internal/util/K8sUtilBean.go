@@ -1,8 +1,12 @@
 package util
 
 import (
+	"fmt"
 	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"time"
 )
 
 type ClusterResourceListMap struct {
@@ -44,3 +48,461 @@ var KindVsChildrenGvk = map[string][]schema.GroupVersionKind{
 	kube.StatefulSetKind:                        append(make([]schema.GroupVersionKind, 0), schema.GroupVersionKind{Version: V1VERSION, Kind: kube.PodKind}),
 	K8sClusterResourceReplicationControllerKind: append(make([]schema.GroupVersionKind, 0), schema.GroupVersionKind{Version: V1VERSION, Kind: kube.PodKind}),
 }
+
+// labels and names used to decide whether a namespace can be safely deleted, anything carrying the devtron app/env
+// labels or installed through helm is considered devtron managed, the rest are defaults created by kubernetes itself
+const DevtronAppIdLabelKey = "appId"
+const DevtronEnvIdLabelKey = "envId"
+const K8sManagedByLabelKey = "app.kubernetes.io/managed-by"
+const K8sDefaultServiceAccountName = "default"
+const K8sRootCAConfigMapName = "kube-root-ca.crt"
+
+const DevtronManagedByLabelValue = "devtron"
+
+const (
+	NamespaceNotFound  = "NotFound"
+	NamespaceManaged   = "Managed"
+	NamespaceUnmanaged = "Unmanaged"
+)
+
+// NamespaceCleanupIgnoredResources are resources which are either recreated by kubernetes or have no meaning without
+// their owners, they are never counted as leftovers of a namespace
+var NamespaceCleanupIgnoredResources = map[string]bool{
+	"events":         true,
+	"endpoints":      true,
+	"endpointslices": true,
+	"leases":         true,
+}
+
+// ErrPDBBlocked is returned when a pod eviction is rejected by a PodDisruptionBudget, callers are expected to back off
+// and retry after RetryAfterSeconds (0 if the api server did not suggest a delay)
+type ErrPDBBlocked struct {
+	Namespace         string
+	PodName           string
+	RetryAfterSeconds int
+	Message           string
+}
+
+func (e *ErrPDBBlocked) Error() string {
+	return fmt.Sprintf("eviction of pod %s/%s blocked by pod disruption budget: %s", e.Namespace, e.PodName, e.Message)
+}
+
+const ClusterInfoCacheExpiry = 15 * time.Minute
+const FeatureEnabledMetricName = "kubernetes_feature_enabled"
+
+type ClusterInfo struct {
+	ServerURL    string          `json:"serverUrl"`
+	Version      string          `json:"version"`
+	Platform     string          `json:"platform"`
+	GitVersion   string          `json:"gitVersion"`
+	FeatureGates map[string]bool `json:"featureGates"`
+}
+
+var RolloutGVR = schema.GroupVersionResource{Group: K8sClusterResourceRolloutGroup, Version: "v1alpha1", Resource: "rollouts"}
+
+// RolloutPromoteAnnotation asks the rollout controller to move a paused canary to its next step
+const RolloutPromoteAnnotation = "argo-rollouts.argoproj.io/promote"
+const RolloutRevisionAnnotation = "rollout.argoproj.io/revision"
+const RolloutPodTemplateHashLabel = "rollouts-pod-template-hash"
+
+type RolloutRevision struct {
+	Revision        int64     `json:"revision"`
+	ReplicaSetName  string    `json:"replicaSetName"`
+	PodTemplateHash string    `json:"podTemplateHash"`
+	Images          []string  `json:"images"`
+	Replicas        int32     `json:"replicas"`
+	ReadyReplicas   int32     `json:"readyReplicas"`
+	Stable          bool      `json:"stable"`
+	CreatedOn       time.Time `json:"createdOn"`
+}
+
+type RolloutStatus struct {
+	Name            string                `json:"name"`
+	Namespace       string                `json:"namespace"`
+	Phase           string                `json:"phase"`
+	Message         string                `json:"message,omitempty"`
+	ReadyReplicas   int64                 `json:"readyReplicas"`
+	UpdatedReplicas int64                 `json:"updatedReplicas"`
+	CanaryWeights   *RolloutCanaryWeights `json:"canaryWeights,omitempty"`
+}
+
+type RolloutCanaryWeights struct {
+	Canary     RolloutWeightDestination   `json:"canary"`
+	Stable     RolloutWeightDestination   `json:"stable"`
+	Additional []RolloutWeightDestination `json:"additional,omitempty"`
+}
+
+type RolloutWeightDestination struct {
+	Weight          int64  `json:"weight"`
+	ServiceName     string `json:"serviceName,omitempty"`
+	PodTemplateHash string `json:"podTemplateHash,omitempty"`
+}
+
+const (
+	ServiceMeshTypeIstio     = "istio"
+	ServiceMeshTypeLinkerd   = "linkerd"
+	ServiceMeshTypeOpenShift = "openshift-service-mesh"
+
+	ServiceMeshInjectionSidecar = "sidecar"
+	ServiceMeshInjectionAmbient = "ambient"
+
+	IstioNamespace   = "istio-system"
+	LinkerdNamespace = "linkerd"
+
+	serviceMeshInfoCacheKeyPrefix = "service-mesh/"
+)
+
+type ServiceMeshInfo struct {
+	Installed     bool     `json:"installed"`
+	Type          string   `json:"type,omitempty"`
+	Version       string   `json:"version,omitempty"`
+	InjectionMode string   `json:"injectionMode,omitempty"`
+	DetectedCrds  []string `json:"detectedCrds,omitempty"`
+}
+
+// serviceMeshCrd is a crd whose presence indicates that a mesh is installed
+type serviceMeshCrd struct {
+	groupVersion string
+	kind         string
+	meshType     string
+}
+
+// ServiceMeshMember is only shipped by openshift service mesh, it is checked first as that mesh also serves the istio crds
+var serviceMeshCrds = []serviceMeshCrd{
+	{groupVersion: "maistra.io/v1", kind: "ServiceMeshMember", meshType: ServiceMeshTypeOpenShift},
+	{groupVersion: "networking.istio.io/v1beta1", kind: "VirtualService", meshType: ServiceMeshTypeIstio},
+	{groupVersion: "networking.istio.io/v1alpha3", kind: "VirtualService", meshType: ServiceMeshTypeIstio},
+	{groupVersion: "linkerd.io/v1alpha2", kind: "ServiceProfile", meshType: ServiceMeshTypeLinkerd},
+}
+
+// control plane deployments by mesh, the first one found is used to read the installed version
+var serviceMeshControlPlanes = map[string][]string{
+	ServiceMeshTypeIstio:   {"istiod"},
+	ServiceMeshTypeLinkerd: {"linkerd-destination", "linkerd-controller"},
+}
+
+const LinkerdVersionLabel = "linkerd.io/control-plane-version"
+const IstioAmbientDaemonSetName = "ztunnel"
+
+// well known names of the proxy containers injected by service meshes
+var KnownSidecarContainerNames = []string{"istio-proxy", "linkerd-proxy", "envoy"}
+
+// IstioSidecarStatusAnnotations are set by the istio injector on the pods it injected, the value lists the injected
+// containers. inject.istio.io/status is the key of older injector releases
+var IstioSidecarStatusAnnotations = []string{"sidecar.istio.io/status", "inject.istio.io/status"}
+
+var VirtualServiceGVR = schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"}
+
+const VirtualServiceKind = "VirtualService"
+
+type HTTPRoute struct {
+	Name  string                 `json:"name,omitempty"`
+	Match []HTTPMatchRequest     `json:"match,omitempty"`
+	Route []HTTPRouteDestination `json:"route"`
+}
+
+type HTTPMatchRequest struct {
+	UriPrefix string `json:"uriPrefix,omitempty"`
+	// Headers are matched exactly
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+type HTTPRouteDestination struct {
+	Host   string `json:"host"`
+	Subset string `json:"subset,omitempty"`
+	Port   int64  `json:"port,omitempty"`
+	Weight int64  `json:"weight,omitempty"`
+}
+
+const DefaultNamespace = "default"
+const DefaultApplyFieldManager = "devtron"
+
+// AppliedResource is one resource applied by ApplyManifest, Created is false when an existing object was updated
+type AppliedResource struct {
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Created   bool   `json:"created"`
+}
+
+type JobCompletionRatio struct {
+	Namespace string        `json:"namespace"`
+	Window    time.Duration `json:"window"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Ratio     float64       `json:"ratio"`
+}
+
+// partialObjectMetadataAccept asks the api server for the metadata of an object only
+const partialObjectMetadataAccept = "application/json;as=PartialObjectMetadata;g=meta.k8s.io;v=v1"
+
+// RestartedAtAnnotationKey is the pod template annotation kubectl rollout restart sets
+const RestartedAtAnnotationKey = "kubectl.kubernetes.io/restartedAt"
+
+// PodState is the pod as read right after a delete, the ui shows it until the next refresh
+type PodState struct {
+	Namespace         string       `json:"namespace"`
+	Name              string       `json:"name"`
+	Phase             string       `json:"phase"`
+	DeletionTimestamp *metav1.Time `json:"deletionTimestamp,omitempty"`
+	Deleted           bool         `json:"deleted"`
+}
+
+// WorkloadState is the workload as returned by a restart or scale, Replicas is the desired count
+type WorkloadState struct {
+	Namespace          string `json:"namespace"`
+	Kind               string `json:"kind"`
+	Name               string `json:"name"`
+	Replicas           int32  `json:"replicas"`
+	ReadyReplicas      int32  `json:"readyReplicas"`
+	UpdatedReplicas    int32  `json:"updatedReplicas"`
+	Generation         int64  `json:"generation"`
+	ObservedGeneration int64  `json:"observedGeneration"`
+	RestartedAt        string `json:"restartedAt,omitempty"`
+}
+
+// RCStatus is the status of a ReplicationController, Selector is the equality based selector of its pods
+type RCStatus struct {
+	Namespace            string            `json:"namespace"`
+	Name                 string            `json:"name"`
+	DesiredReplicas      int32             `json:"desiredReplicas"`
+	Replicas             int32             `json:"replicas"`
+	ReadyReplicas        int32             `json:"readyReplicas"`
+	AvailableReplicas    int32             `json:"availableReplicas"`
+	FullyLabeledReplicas int32             `json:"fullyLabeledReplicas"`
+	Selector             map[string]string `json:"selector"`
+}
+
+// PodSummaryStats counts the pods of a namespace by phase, Restarts is the sum of the restarts of all their containers
+type PodSummaryStats struct {
+	Namespace string `json:"namespace"`
+	Total     int    `json:"total"`
+	Running   int    `json:"running"`
+	Pending   int    `json:"pending"`
+	Failed    int    `json:"failed"`
+	Succeeded int    `json:"succeeded"`
+	Unknown   int    `json:"unknown"`
+	Restarts  int    `json:"restarts"`
+}
+
+// CronJobStatus is the aggregated status of a CronJob, NextScheduleTime is nil when the cronjob is suspended
+type CronJobStatus struct {
+	Namespace          string       `json:"namespace"`
+	Name               string       `json:"name"`
+	Schedule           string       `json:"schedule"`
+	LastScheduleTime   *metav1.Time `json:"lastScheduleTime,omitempty"`
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	ActiveJobCount     int          `json:"activeJobCount"`
+	NextScheduleTime   *metav1.Time `json:"nextScheduleTime,omitempty"`
+	Suspended          bool         `json:"suspended"`
+}
+
+const (
+	CronJobRunActive    = "Active"
+	CronJobRunSucceeded = "Succeeded"
+	CronJobRunFailed    = "Failed"
+)
+
+// CronJobRun is the most recent job created by a cronjob
+type CronJobRun struct {
+	JobName        string       `json:"jobName"`
+	Status         string       `json:"status"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// ContainerSecurityAudit holds the effective security settings of a container, pod level settings apply when the
+// container does not set them. RunAsRoot is also true when the user comes from the image and runAsNonRoot is not set
+type ContainerSecurityAudit struct {
+	PodName                  string   `json:"podName"`
+	ContainerName            string   `json:"containerName"`
+	InitContainer            bool     `json:"initContainer,omitempty"`
+	RunAsUser                *int64   `json:"runAsUser,omitempty"`
+	RunAsRoot                bool     `json:"runAsRoot"`
+	Privileged               bool     `json:"privileged"`
+	AllowPrivilegeEscalation bool     `json:"allowPrivilegeEscalation"`
+	Capabilities             []string `json:"capabilities"`
+	DroppedCapabilities      []string `json:"droppedCapabilities"`
+	ReadOnlyRootFilesystem   bool     `json:"readOnlyRootFilesystem"`
+}
+
+// ContainerResourceInfo holds the resource requests and limits of a container as quantity strings, e.g. 250m or 512Mi,
+// a value is empty when it is not set in the spec
+type ContainerResourceInfo struct {
+	ContainerName           string `json:"containerName"`
+	InitContainer           bool   `json:"initContainer,omitempty"`
+	CpuRequest              string `json:"cpuRequest"`
+	CpuLimit                string `json:"cpuLimit"`
+	MemoryRequest           string `json:"memoryRequest"`
+	MemoryLimit             string `json:"memoryLimit"`
+	EphemeralStorageRequest string `json:"ephemeralStorageRequest"`
+	EphemeralStorageLimit   string `json:"ephemeralStorageLimit"`
+}
+
+// TopologySpreadEvaluation tells whether the running pods of a deployment satisfy the topology spread constraints of
+// its pod template, Warnings describe the violated constraints
+type TopologySpreadEvaluation struct {
+	Namespace      string                            `json:"namespace"`
+	DeploymentName string                            `json:"deploymentName"`
+	Satisfied      bool                              `json:"satisfied"`
+	Constraints    []*TopologySpreadConstraintStatus `json:"constraints"`
+	Warnings       []string                          `json:"warnings"`
+}
+
+// TopologySpreadConstraintStatus holds the matching pods per topology domain, domains without pods are listed with 0
+type TopologySpreadConstraintStatus struct {
+	TopologyKey       string         `json:"topologyKey"`
+	MaxSkew           int32          `json:"maxSkew"`
+	WhenUnsatisfiable string         `json:"whenUnsatisfiable"`
+	PodsPerDomain     map[string]int `json:"podsPerDomain"`
+	Skew              int32          `json:"skew"`
+	Satisfied         bool           `json:"satisfied"`
+}
+
+// AccessReviewResult tells whether the identity devtron uses for the cluster may perform the verb on the resource
+type AccessReviewResult struct {
+	Verb        string `json:"verb"`
+	Resource    string `json:"resource"`
+	Subresource string `json:"subresource,omitempty"`
+	Namespace   string `json:"namespace"`
+	Allowed     bool   `json:"allowed"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+func (r AccessReviewResult) String() string {
+	resource := r.Resource
+	if len(r.Subresource) > 0 {
+		resource += "/" + r.Subresource
+	}
+	outcome := "allowed"
+	if !r.Allowed {
+		outcome = "denied"
+		if len(r.Reason) > 0 {
+			outcome += ", " + r.Reason
+		}
+	}
+	return fmt.Sprintf("%s %s in namespace %s: %s", r.Verb, resource, r.Namespace, outcome)
+}
+
+var VolumeSnapshotGVR = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+
+const VolumeSnapshotKind = "VolumeSnapshot"
+
+// VolumeSnapshotInfo is a csi volume snapshot, a snapshot can be restored into a new pvc once ReadyToUse is set
+type VolumeSnapshotInfo struct {
+	Name              string     `json:"name"`
+	Namespace         string     `json:"namespace"`
+	SourcePvcName     string     `json:"sourcePvcName,omitempty"`
+	SnapshotClassName string     `json:"snapshotClassName,omitempty"`
+	CreationTime      *time.Time `json:"creationTime,omitempty"`
+	ReadyToUse        bool       `json:"readyToUse"`
+	RestoreSize       string     `json:"restoreSize,omitempty"`
+	Error             string     `json:"error,omitempty"`
+}
+
+// KubeconfigCluster is a context of a kubeconfig, Server is empty when the context names a cluster missing from it
+type KubeconfigCluster struct {
+	ContextName string `json:"contextName"`
+	Server      string `json:"server"`
+	Current     bool   `json:"current"`
+}
+
+const (
+	ClusterFeatureCronJobBatchV1      = "CronJobBatchV1"
+	ClusterFeatureEvictionPolicyV1    = "EvictionPolicyV1"
+	ClusterFeatureEphemeralContainers = "EphemeralContainers"
+	ClusterFeatureJobTTLAfterFinished = "JobTTLAfterFinished"
+	clusterCapabilitiesCacheKeyPrefix = "capabilities/"
+)
+
+type clusterFeatureRequirement struct {
+	Description string
+	// MinMinor is the first 1.x release where the feature is served and enabled by default
+	MinMinor int
+}
+
+var clusterFeatureRequirements = map[string]clusterFeatureRequirement{
+	ClusterFeatureCronJobBatchV1:      {Description: "batch/v1 CronJob", MinMinor: 21},
+	ClusterFeatureEvictionPolicyV1:    {Description: "policy/v1 Eviction", MinMinor: 22},
+	ClusterFeatureEphemeralContainers: {Description: "ephemeral containers", MinMinor: 23},
+	ClusterFeatureJobTTLAfterFinished: {Description: "Job ttlSecondsAfterFinished", MinMinor: 21},
+}
+
+// ClusterCapabilities tells which api features used by devtron the cluster supports, keyed by ClusterFeature* constants
+type ClusterCapabilities struct {
+	Version          string                  `json:"version"`
+	Features         map[string]bool         `json:"features"`
+	EncryptionAtRest *EncryptionAtRestStatus `json:"encryptionAtRest"`
+}
+
+func (c *ClusterCapabilities) Supports(feature string) bool {
+	return c != nil && c.Features[feature]
+}
+
+// ErrClusterFeatureUnsupported is returned instead of the raw NotFound of the api server when the cluster is too old
+type ErrClusterFeatureUnsupported struct {
+	Feature         string
+	Version         string
+	RequiredVersion string
+}
+
+func (e *ErrClusterFeatureUnsupported) Error() string {
+	return fmt.Sprintf("cluster version %s does not support %s, requires %s+", e.Version, clusterFeatureRequirements[e.Feature].Description, e.RequiredVersion)
+}
+
+// JobLogsPodWaitTimeout is how long GetJobLogs waits for a pod of the job to start
+const JobLogsPodWaitTimeout = 2 * time.Minute
+
+// statuses of the checks of a verbose healthz response, failed checks carry the reason after failed
+const (
+	HealthzStatusOk     = "ok"
+	HealthzStatusFailed = "failed"
+)
+
+const CgroupMemoryUsagePath = "/sys/fs/cgroup/memory/memory.usage_in_bytes"
+const CgroupCpuUsagePath = "/sys/fs/cgroup/cpu/cpuacct.usage"
+
+// ResourceConsumption is the usage of a container read from its cgroup, CpuUsageNanoseconds is the cpu time consumed
+// since the container started and not a rate
+type ResourceConsumption struct {
+	PodName             string `json:"podName"`
+	ContainerName       string `json:"containerName"`
+	MemoryBytes         int64  `json:"memoryBytes"`
+	CpuUsageNanoseconds int64  `json:"cpuUsageNanoseconds"`
+}
+
+// NodeDetailCacheExpiry is how long the pod, metrics and event lookups of GetNodeDetail are reused for a node
+const NodeDetailCacheExpiry = 30 * time.Second
+const nodeDetailCacheKeyPrefix = "node-detail/"
+
+// NodeDetailMaxEvents caps the recent events returned for a node
+const NodeDetailMaxEvents = 10
+
+// NodeDetail is the load of a node, Requests is the sum of the requests of its non terminated pods. Usage is read from
+// metrics-server and is empty with MetricsAvailable unset when metrics-server is not installed or not responding
+type NodeDetail struct {
+	Name             string          `json:"name"`
+	Ready            bool            `json:"ready"`
+	Unschedulable    bool            `json:"unschedulable"`
+	Capacity         v1.ResourceList `json:"capacity"`
+	Allocatable      v1.ResourceList `json:"allocatable"`
+	Requests         v1.ResourceList `json:"requests"`
+	Usage            v1.ResourceList `json:"usage,omitempty"`
+	MetricsAvailable bool            `json:"metricsAvailable"`
+	PodCount         int             `json:"podCount"`
+	PodCapacity      int64           `json:"podCapacity"`
+	// Pressure lists the pressure conditions which are currently true, like MemoryPressure
+	Pressure  []string     `json:"pressure,omitempty"`
+	Events    []*NodeEvent `json:"events"`
+	FetchedAt time.Time    `json:"fetchedAt"`
+}
+
+type NodeEvent struct {
+	Type          string    `json:"type"`
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
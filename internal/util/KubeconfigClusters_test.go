@@ -0,0 +1,53 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const multiContextKubeconfig = `apiVersion: v1
+kind: Config
+current-context: staging
+clusters:
+- name: prod-cluster
+  cluster:
+    server: https://prod.example.com:6443
+- name: staging-cluster
+  cluster:
+    server: https://staging.example.com:6443
+contexts:
+- name: staging
+  context:
+    cluster: staging-cluster
+- name: prod
+  context:
+    cluster: prod-cluster
+- name: dangling
+  context:
+    cluster: removed-cluster
+`
+
+func TestListKubeconfigClusters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	assert.Nil(t, os.WriteFile(path, []byte(multiContextKubeconfig), 0600))
+	k8sUtil := &K8sUtil{logger: zap.NewNop().Sugar(), kubeconfig: &path}
+
+	clusters, err := k8sUtil.ListKubeconfigClusters(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []KubeconfigCluster{
+		{ContextName: "dangling"},
+		{ContextName: "prod", Server: "https://prod.example.com:6443"},
+		{ContextName: "staging", Server: "https://staging.example.com:6443", Current: true},
+	}, clusters)
+
+	defaultClusters, err := k8sUtil.ListKubeconfigClusters("")
+	assert.Nil(t, err)
+	assert.Equal(t, clusters, defaultClusters)
+
+	_, err = k8sUtil.ListKubeconfigClusters(filepath.Join(t.TempDir(), "missing"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,99 @@
+package util
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	batchV1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// labels stamped on the pods and jobs devtron renders itself, cleanup selects on these instead of matching names
+const (
+	DevtronPurposeLabelKey     = "devtron.ai/purpose"
+	DevtronUserIdLabelKey      = "devtron.ai/user-id"
+	DevtronSessionIdLabelKey   = "devtron.ai/session-id"
+	DevtronOperationIdLabelKey = "devtron.ai/operation-id"
+
+	DevtronPurposeTerminal = "terminal"
+	DevtronPurposeJob      = "job"
+)
+
+// userIdLabelHashLength keeps the user id label short, the raw user id is not exposed on the cluster
+const userIdLabelHashLength = 16
+
+// ManagementLabels describe why and for whom a pod or job was created, empty fields are not labelled
+type ManagementLabels struct {
+	Purpose     string
+	UserId      int32
+	SessionId   string
+	OperationId string
+}
+
+// UserIdLabelValue is the hash of the user id used as the value of DevtronUserIdLabelKey
+func UserIdLabelValue(userId int32) string {
+	sum := sha256.Sum256([]byte(strconv.FormatInt(int64(userId), 10)))
+	return hex.EncodeToString(sum[:])[:userIdLabelHashLength]
+}
+
+func (m ManagementLabels) Labels() map[string]string {
+	labels := map[string]string{K8sManagedByLabelKey: DevtronManagedByLabelValue}
+	if len(m.Purpose) > 0 {
+		labels[DevtronPurposeLabelKey] = m.Purpose
+	}
+	if m.UserId > 0 {
+		labels[DevtronUserIdLabelKey] = UserIdLabelValue(m.UserId)
+	}
+	if len(m.SessionId) > 0 {
+		labels[DevtronSessionIdLabelKey] = m.SessionId
+	}
+	if len(m.OperationId) > 0 {
+		labels[DevtronOperationIdLabelKey] = m.OperationId
+	}
+	return labels
+}
+
+// ManagementLabelSelector selects the objects devtron created for the purpose
+func ManagementLabelSelector(purpose string) string {
+	return K8sManagedByLabelKey + "=" + DevtronManagedByLabelValue + "," + DevtronPurposeLabelKey + "=" + purpose
+}
+
+// ApplyManagementLabels merges the management labels into the labels of the object, labels set by user templates are
+// kept unless they use one of our keys. The request id is always annotated as these objects are traced back to the
+// request which created them
+func ApplyManagementLabels(ctx context.Context, object metav1.Object, m ManagementLabels) {
+	labels := object.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	for key, value := range m.Labels() {
+		labels[key] = value
+	}
+	object.SetLabels(labels)
+	AnnotateWithRequestId(ctx, object)
+}
+
+// labelJobForManagement labels the job and the pods it runs with the job name as the operation id
+func labelJobForManagement(ctx context.Context, job *batchV1.Job) {
+	managementLabels := ManagementLabels{Purpose: DevtronPurposeJob, UserId: UserIdFromContext(ctx), OperationId: job.Name}
+	ApplyManagementLabels(ctx, job, managementLabels)
+	ApplyManagementLabels(ctx, &job.Spec.Template, managementLabels)
+}
+
+// ApplyManagementLabelsJson is ApplyManagementLabels for json manifests
+func ApplyManagementLabelsJson(ctx context.Context, manifest string, m ManagementLabels) (string, error) {
+	object := &unstructured.Unstructured{}
+	if err := json.Unmarshal([]byte(manifest), &object.Object); err != nil {
+		return "", err
+	}
+	ApplyManagementLabels(ctx, object, m)
+	labelled, err := json.Marshal(object.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(labelled), nil
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	batchV1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestLabelJobForManagement(t *testing.T) {
+	content := `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: chart-sync
+  labels:
+    team: platform
+    app.kubernetes.io/managed-by: helm
+spec:
+  template:
+    metadata:
+      labels:
+        app: chart-sync
+    spec:
+      containers:
+      - name: sync
+        image: chart-sync:latest
+`
+	var job batchV1.Job
+	assert.Nil(t, yaml.Unmarshal([]byte(content), &job))
+	ctx := ContextWithUserId(ContextWithRequestId(context.Background(), "req-1"), 7)
+	labelJobForManagement(ctx, &job)
+
+	for _, labels := range []map[string]string{job.Labels, job.Spec.Template.Labels} {
+		assert.Equal(t, DevtronManagedByLabelValue, labels[K8sManagedByLabelKey])
+		assert.Equal(t, DevtronPurposeJob, labels[DevtronPurposeLabelKey])
+		assert.Equal(t, "chart-sync", labels[DevtronOperationIdLabelKey])
+		assert.Equal(t, UserIdLabelValue(7), labels[DevtronUserIdLabelKey])
+	}
+	assert.Equal(t, "platform", job.Labels["team"])
+	assert.Equal(t, "chart-sync", job.Spec.Template.Labels["app"])
+	assert.Equal(t, "req-1", job.Annotations[RequestIdAnnotation])
+	assert.Equal(t, "req-1", job.Spec.Template.Annotations[RequestIdAnnotation])
+}
+
+func TestApplyManagementLabelsJson(t *testing.T) {
+	manifest := `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"terminal-access-1-2-abcde","labels":{"team":"sre"}},"spec":{"serviceAccountName":"terminal-access-1-2-abcde-sa"}}`
+	labelled, err := ApplyManagementLabelsJson(context.Background(), manifest, ManagementLabels{Purpose: DevtronPurposeTerminal, UserId: 2, SessionId: "terminal-access-1-2-abcde"})
+	assert.Nil(t, err)
+
+	pod := &v1.Pod{}
+	assert.Nil(t, json.Unmarshal([]byte(labelled), pod))
+	assert.Equal(t, map[string]string{
+		"team":                   "sre",
+		K8sManagedByLabelKey:     DevtronManagedByLabelValue,
+		DevtronPurposeLabelKey:   DevtronPurposeTerminal,
+		DevtronUserIdLabelKey:    UserIdLabelValue(2),
+		DevtronSessionIdLabelKey: "terminal-access-1-2-abcde",
+	}, pod.Labels)
+	// no request id outside a request
+	assert.Empty(t, pod.Annotations)
+	assert.Equal(t, "terminal-access-1-2-abcde-sa", pod.Spec.ServiceAccountName)
+
+	_, err = ApplyManagementLabelsJson(context.Background(), "{", ManagementLabels{Purpose: DevtronPurposeTerminal})
+	assert.NotNil(t, err)
+}
+
+func TestUserIdLabelValue(t *testing.T) {
+	assert.Len(t, UserIdLabelValue(2), userIdLabelHashLength)
+	assert.Equal(t, UserIdLabelValue(2), UserIdLabelValue(2))
+	assert.NotEqual(t, UserIdLabelValue(2), UserIdLabelValue(3))
+	assert.NotContains(t, ManagementLabels{Purpose: DevtronPurposeJob}.Labels(), DevtronUserIdLabelKey)
+}
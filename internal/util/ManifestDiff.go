@@ -0,0 +1,184 @@
+package util
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"reflect"
+	"sort"
+)
+
+const (
+	ManifestDiffActionCreate    = "create"
+	ManifestDiffActionUpdate    = "update"
+	ManifestDiffActionUnchanged = "unchanged"
+
+	ManifestChangeAdded    = "added"
+	ManifestChangeRemoved  = "removed"
+	ManifestChangeModified = "modified"
+
+	redactedValue         = "<redacted>"
+	redactedChangedValue  = "<redacted, changed>"
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+	ManifestDiffFieldManager = "devtron-diff"
+)
+
+type ManifestDiff struct {
+	Action string `json:"action"`
+	// Message is "will be created" when the object does not exist yet
+	Message string           `json:"message,omitempty"`
+	Diff    string           `json:"diff"`
+	Changes []ManifestChange `json:"changes"`
+	// ServerDryRun tells if server defaults were applied to the desired object, without it defaulted fields show up as removed
+	ServerDryRun bool `json:"serverDryRun"`
+}
+
+// ManifestChange path is a json pointer, arrays are compared as a whole
+type ManifestChange struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+// metadata written by the api server which is never part of a desired manifest
+var serverSetMetadataFields = []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "generation", "selfLink"}
+
+// normalizeForDiff returns a copy of the object without status and server set metadata
+func normalizeForDiff(object *unstructured.Unstructured) map[string]interface{} {
+	if object == nil {
+		return nil
+	}
+	normalized := object.DeepCopy().Object
+	delete(normalized, "status")
+	for _, field := range serverSetMetadataFields {
+		unstructured.RemoveNestedField(normalized, "metadata", field)
+	}
+	unstructured.RemoveNestedField(normalized, "metadata", "annotations", lastAppliedAnnotation)
+	if annotations, found, _ := unstructured.NestedMap(normalized, "metadata", "annotations"); found && len(annotations) == 0 {
+		unstructured.RemoveNestedField(normalized, "metadata", "annotations")
+	}
+	return normalized
+}
+
+// redactSecretData replaces secret values on both sides, stringData is folded into data first like the api server does.
+// Updated keys are marked on the desired side so that the diff and changed paths still tell which keys change
+func redactSecretData(live map[string]interface{}, desired map[string]interface{}) {
+	foldStringData(live)
+	foldStringData(desired)
+	liveData, _, _ := unstructured.NestedMap(live, "data")
+	desiredData, _, _ := unstructured.NestedMap(desired, "data")
+	if live != nil && liveData != nil {
+		redacted := make(map[string]interface{}, len(liveData))
+		for key := range liveData {
+			redacted[key] = redactedValue
+		}
+		live["data"] = redacted
+	}
+	if desired != nil && desiredData != nil {
+		redacted := make(map[string]interface{}, len(desiredData))
+		for key, value := range desiredData {
+			if liveValue, ok := liveData[key]; ok && !reflect.DeepEqual(value, liveValue) {
+				redacted[key] = redactedChangedValue
+			} else {
+				redacted[key] = redactedValue
+			}
+		}
+		desired["data"] = redacted
+	}
+}
+
+func foldStringData(secret map[string]interface{}) {
+	if secret == nil {
+		return
+	}
+	stringData, found, _ := unstructured.NestedMap(secret, "stringData")
+	if !found {
+		return
+	}
+	data, _, _ := unstructured.NestedMap(secret, "data")
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	for key, value := range stringData {
+		data[key] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(value)))
+	}
+	secret["data"] = data
+	delete(secret, "stringData")
+}
+
+// changedPaths lists the leaves which differ, a map on one side and anything else on the other is a single change
+func changedPaths(live interface{}, desired interface{}, tokens []string, changes []ManifestChange) []ManifestChange {
+	liveMap, liveIsMap := live.(map[string]interface{})
+	desiredMap, desiredIsMap := desired.(map[string]interface{})
+	if !liveIsMap || !desiredIsMap {
+		if !reflect.DeepEqual(live, desired) {
+			changes = append(changes, ManifestChange{Path: JsonPointer(tokens...), Type: ManifestChangeModified})
+		}
+		return changes
+	}
+	keys := make([]string, 0, len(liveMap)+len(desiredMap))
+	for key := range liveMap {
+		keys = append(keys, key)
+	}
+	for key := range desiredMap {
+		if _, ok := liveMap[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		path := append(append(make([]string, 0, len(tokens)+1), tokens...), key)
+		liveValue, inLive := liveMap[key]
+		desiredValue, inDesired := desiredMap[key]
+		switch {
+		case !inLive:
+			changes = append(changes, ManifestChange{Path: JsonPointer(path...), Type: ManifestChangeAdded})
+		case !inDesired:
+			changes = append(changes, ManifestChange{Path: JsonPointer(path...), Type: ManifestChangeRemoved})
+		default:
+			changes = changedPaths(liveValue, desiredValue, path, changes)
+		}
+	}
+	return changes
+}
+
+// buildManifestDiff expects both sides normalized and redacted, live is nil when the object does not exist
+func buildManifestDiff(live map[string]interface{}, desired map[string]interface{}) (*ManifestDiff, error) {
+	liveYaml := ""
+	if live != nil {
+		b, err := yaml.Marshal(live)
+		if err != nil {
+			return nil, err
+		}
+		liveYaml = string(b)
+	}
+	desiredYaml, err := yaml.Marshal(desired)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(liveYaml),
+		B:        difflib.SplitLines(string(desiredYaml)),
+		FromFile: "live",
+		ToFile:   "desired",
+		Context:  3,
+	})
+	if err != nil {
+		return nil, err
+	}
+	manifestDiff := &ManifestDiff{Diff: diff, Changes: make([]ManifestChange, 0)}
+	if live == nil {
+		manifestDiff.Action = ManifestDiffActionCreate
+		manifestDiff.Message = "will be created"
+		return manifestDiff, nil
+	}
+	manifestDiff.Changes = changedPaths(live, desired, nil, manifestDiff.Changes)
+	if len(manifestDiff.Changes) == 0 {
+		manifestDiff.Action = ManifestDiffActionUnchanged
+	} else {
+		manifestDiff.Action = ManifestDiffActionUpdate
+	}
+	return manifestDiff, nil
+}
@@ -0,0 +1,80 @@
+package util
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"strings"
+	"testing"
+)
+
+func newDiffObject(kind string, data map[string]interface{}, extra map[string]interface{}) *unstructured.Unstructured {
+	object := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      "app-config",
+			"namespace": "devtron-demo",
+		},
+		"data": data,
+	}
+	for key, value := range extra {
+		object[key] = value
+	}
+	return &unstructured.Unstructured{Object: object}
+}
+
+func TestBuildManifestDiff_ConfigMap(t *testing.T) {
+	live := newDiffObject("ConfigMap", map[string]interface{}{"LOG_LEVEL": "info", "nginx/conf.d": "a"}, nil)
+	unstructured.SetNestedField(live.Object, "42", "metadata", "resourceVersion")
+	unstructured.SetNestedSlice(live.Object, []interface{}{map[string]interface{}{"manager": "kubectl"}}, "metadata", "managedFields")
+	desired := newDiffObject("ConfigMap", map[string]interface{}{"LOG_LEVEL": "debug", "FEATURE_X": "true"}, nil)
+
+	diff, err := buildManifestDiff(normalizeForDiff(live), normalizeForDiff(desired))
+	assert.Nil(t, err)
+	assert.Equal(t, ManifestDiffActionUpdate, diff.Action)
+	assert.Equal(t, []ManifestChange{
+		{Path: "/data/FEATURE_X", Type: ManifestChangeAdded},
+		{Path: "/data/LOG_LEVEL", Type: ManifestChangeModified},
+		{Path: "/data/nginx~1conf.d", Type: ManifestChangeRemoved},
+	}, diff.Changes)
+	assert.Contains(t, diff.Diff, "-  LOG_LEVEL: info")
+	assert.Contains(t, diff.Diff, "+  LOG_LEVEL: debug")
+	assert.NotContains(t, diff.Diff, "managedFields")
+	assert.NotContains(t, diff.Diff, "resourceVersion")
+
+	diff, err = buildManifestDiff(normalizeForDiff(live), normalizeForDiff(live))
+	assert.Nil(t, err)
+	assert.Equal(t, ManifestDiffActionUnchanged, diff.Action)
+	assert.Empty(t, diff.Diff)
+}
+
+func TestBuildManifestDiff_NotFoundWillBeCreated(t *testing.T) {
+	desired := newDiffObject("ConfigMap", map[string]interface{}{"LOG_LEVEL": "debug"}, nil)
+	diff, err := buildManifestDiff(nil, normalizeForDiff(desired))
+	assert.Nil(t, err)
+	assert.Equal(t, ManifestDiffActionCreate, diff.Action)
+	assert.Equal(t, "will be created", diff.Message)
+	assert.Contains(t, diff.Diff, "+  LOG_LEVEL: debug")
+}
+
+func TestBuildManifestDiff_SecretValuesAreRedacted(t *testing.T) {
+	// c2VjcmV0LW9sZA== is "secret-old"
+	live := newDiffObject("Secret", map[string]interface{}{"PASSWORD": "c2VjcmV0LW9sZA==", "USER": "YWRtaW4="}, nil)
+	desired := newDiffObject("Secret", map[string]interface{}{"USER": "YWRtaW4="}, map[string]interface{}{
+		"stringData": map[string]interface{}{"PASSWORD": "secret-new", "TOKEN": "secret-token"},
+	})
+	liveObject, desiredObject := normalizeForDiff(live), normalizeForDiff(desired)
+	redactSecretData(liveObject, desiredObject)
+
+	diff, err := buildManifestDiff(liveObject, desiredObject)
+	assert.Nil(t, err)
+	for _, secretValue := range []string{"c2VjcmV0LW9sZA==", "YWRtaW4=", "secret-new", "secret-token", "stringData"} {
+		assert.False(t, strings.Contains(diff.Diff, secretValue), "diff leaks %q", secretValue)
+	}
+	assert.Equal(t, []ManifestChange{
+		{Path: "/data/PASSWORD", Type: ManifestChangeModified},
+		{Path: "/data/TOKEN", Type: ManifestChangeAdded},
+	}, diff.Changes)
+	// the live object is untouched, only the normalized copies are redacted
+	assert.Equal(t, "c2VjcmV0LW9sZA==", live.Object["data"].(map[string]interface{})["PASSWORD"])
+}
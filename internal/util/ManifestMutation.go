@@ -0,0 +1,274 @@
+package util
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"strings"
+	"sync"
+)
+
+const ManifestDefaultsMutatorName = "manifest-defaults"
+
+type ManifestMutationConfig struct {
+	// DisabledMutators are names of registered mutators which are skipped
+	DisabledMutators           []string `env:"MANIFEST_MUTATORS_DISABLED" envSeparator:","`
+	DefaultsConfigMapName      string   `env:"MANIFEST_DEFAULTS_CONFIGMAP_NAME" envDefault:"devtron-manifest-defaults"`
+	DefaultsConfigMapNamespace string   `env:"MANIFEST_DEFAULTS_CONFIGMAP_NAMESPACE" envDefault:"devtroncd"`
+}
+
+func GetManifestMutationConfig() (*ManifestMutationConfig, error) {
+	config := &ManifestMutationConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+// ManifestMutator changes a manifest devtron is about to create, e.g. a job or a terminal pod. Mutate must only
+// depend on the object passed, anything read from outside has to be loaded before the chain runs
+type ManifestMutator interface {
+	Name() string
+	Mutate(object *unstructured.Unstructured) error
+}
+
+// ManifestMutatorChain applies the registered mutators in the order of registration
+type ManifestMutatorChain struct {
+	lock     sync.RWMutex
+	mutators []ManifestMutator
+	disabled map[string]bool
+}
+
+func NewManifestMutatorChain(disabledMutators []string) *ManifestMutatorChain {
+	disabled := make(map[string]bool, len(disabledMutators))
+	for _, name := range disabledMutators {
+		if name = strings.TrimSpace(name); len(name) > 0 {
+			disabled[name] = true
+		}
+	}
+	return &ManifestMutatorChain{disabled: disabled}
+}
+
+func (chain *ManifestMutatorChain) Register(mutator ManifestMutator) {
+	chain.lock.Lock()
+	defer chain.lock.Unlock()
+	chain.mutators = append(chain.mutators, mutator)
+}
+
+// Apply runs the enabled mutators on the object, the first failure aborts the chain with the name of the mutator
+func (chain *ManifestMutatorChain) Apply(object *unstructured.Unstructured) error {
+	if chain == nil {
+		return nil
+	}
+	chain.lock.RLock()
+	defer chain.lock.RUnlock()
+	for _, mutator := range chain.mutators {
+		if chain.disabled[mutator.Name()] {
+			continue
+		}
+		if err := mutator.Mutate(object); err != nil {
+			return fmt.Errorf("manifest mutator %s failed: %w", mutator.Name(), err)
+		}
+	}
+	return nil
+}
+
+// ManifestDefaults are read from the defaults ConfigMap, every key holds yaml:
+// labels and annotations are string maps, podSecurityContext and containerSecurityContext follow the pod spec and
+// registryMirrors maps an image prefix, e.g. docker.io, to the prefix of the mirror
+type ManifestDefaults struct {
+	Labels                   map[string]string      `json:"labels"`
+	Annotations              map[string]string      `json:"annotations"`
+	PodSecurityContext       map[string]interface{} `json:"podSecurityContext"`
+	ContainerSecurityContext map[string]interface{} `json:"containerSecurityContext"`
+	RegistryMirrors          map[string]string      `json:"registryMirrors"`
+}
+
+func ParseManifestDefaults(data map[string]string) (*ManifestDefaults, error) {
+	defaults := &ManifestDefaults{}
+	targets := map[string]interface{}{
+		"labels":                   &defaults.Labels,
+		"annotations":              &defaults.Annotations,
+		"podSecurityContext":       &defaults.PodSecurityContext,
+		"containerSecurityContext": &defaults.ContainerSecurityContext,
+		"registryMirrors":          &defaults.RegistryMirrors,
+	}
+	for key, target := range targets {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		if err := yaml.Unmarshal([]byte(value), target); err != nil {
+			return nil, fmt.Errorf("invalid %s in manifest defaults: %w", key, err)
+		}
+	}
+	return defaults, nil
+}
+
+// ManifestDefaultsMutator fills in ManifestDefaults, values present in the manifest always win over the defaults
+type ManifestDefaultsMutator struct {
+	// load returns the defaults to apply, nil when none are configured
+	load func() (*ManifestDefaults, error)
+}
+
+func NewManifestDefaultsMutator(load func() (*ManifestDefaults, error)) *ManifestDefaultsMutator {
+	return &ManifestDefaultsMutator{load: load}
+}
+
+func (m *ManifestDefaultsMutator) Name() string {
+	return ManifestDefaultsMutatorName
+}
+
+func (m *ManifestDefaultsMutator) Mutate(object *unstructured.Unstructured) error {
+	defaults, err := m.load()
+	if err != nil {
+		return err
+	}
+	return applyManifestDefaults(object, defaults)
+}
+
+func applyManifestDefaults(object *unstructured.Unstructured, defaults *ManifestDefaults) error {
+	if defaults == nil {
+		return nil
+	}
+	object.SetLabels(mergeStringDefaults(object.GetLabels(), defaults.Labels))
+	object.SetAnnotations(mergeStringDefaults(object.GetAnnotations(), defaults.Annotations))
+	podTemplatePath := podTemplateFields(object.GetKind())
+	if podTemplatePath == nil {
+		return nil
+	}
+	// pods created by controllers only carry the labels and annotations of the template
+	if len(podTemplatePath) > 0 {
+		for field, values := range map[string]map[string]string{"labels": defaults.Labels, "annotations": defaults.Annotations} {
+			path := append(append([]string{}, podTemplatePath...), "metadata", field)
+			existing, _, err := unstructured.NestedStringMap(object.Object, path...)
+			if err != nil {
+				return err
+			}
+			if merged := mergeStringDefaults(existing, values); len(merged) > 0 {
+				if err = unstructured.SetNestedStringMap(object.Object, merged, path...); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	specPath := append(append([]string{}, podTemplatePath...), "spec")
+	spec, found, err := unstructured.NestedMap(object.Object, specPath...)
+	if err != nil || !found {
+		return err
+	}
+	if len(defaults.PodSecurityContext) > 0 {
+		existing, _, _ := unstructured.NestedMap(spec, "securityContext")
+		spec["securityContext"] = mergeMapDefaults(existing, defaults.PodSecurityContext)
+	}
+	for _, containerField := range []string{"initContainers", "containers"} {
+		containers, found, err := unstructured.NestedSlice(spec, containerField)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		for i, item := range containers {
+			container, ok := item.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid container at %s[%d]", containerField, i)
+			}
+			if len(defaults.ContainerSecurityContext) > 0 {
+				existing, _, _ := unstructured.NestedMap(container, "securityContext")
+				container["securityContext"] = mergeMapDefaults(existing, defaults.ContainerSecurityContext)
+			}
+			if image, ok := container["image"].(string); ok {
+				container["image"] = mirrorImage(image, defaults.RegistryMirrors)
+			}
+		}
+		spec[containerField] = containers
+	}
+	return unstructured.SetNestedMap(object.Object, spec, specPath...)
+}
+
+// podTemplateFields returns the path to the pod template of workload kinds, empty for a pod and nil for other kinds
+func podTemplateFields(kind string) []string {
+	switch kind {
+	case "Pod":
+		return []string{}
+	case "Job", "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet":
+		return []string{"spec", "template"}
+	case "CronJob":
+		return []string{"spec", "jobTemplate", "spec", "template"}
+	}
+	return nil
+}
+
+func mergeStringDefaults(values map[string]string, defaults map[string]string) map[string]string {
+	if len(defaults) == 0 {
+		return values
+	}
+	merged := make(map[string]string, len(values)+len(defaults))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range values {
+		merged[key] = value
+	}
+	return merged
+}
+
+// mergeMapDefaults merges nested maps key by key, any non map value present in values wins
+func mergeMapDefaults(values map[string]interface{}, defaults map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(values)+len(defaults))
+	for key, value := range defaults {
+		merged[key] = runtimeDeepCopy(value)
+	}
+	for key, value := range values {
+		valueMap, valueIsMap := value.(map[string]interface{})
+		defaultMap, defaultIsMap := merged[key].(map[string]interface{})
+		if valueIsMap && defaultIsMap {
+			merged[key] = mergeMapDefaults(valueMap, defaultMap)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+func runtimeDeepCopy(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			copied[key] = runtimeDeepCopy(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(typed))
+		for i, item := range typed {
+			copied[i] = runtimeDeepCopy(item)
+		}
+		return copied
+	}
+	return value
+}
+
+// mirrorImage rewrites the registry of the image using the longest matching prefix, images without a registry are
+// docker hub images and match docker.io
+func mirrorImage(image string, mirrors map[string]string) string {
+	if len(mirrors) == 0 {
+		return image
+	}
+	normalized := image
+	if parts := strings.SplitN(image, "/", 2); len(parts) == 1 {
+		normalized = "docker.io/library/" + image
+	} else if !strings.ContainsAny(parts[0], ".:") && parts[0] != "localhost" {
+		normalized = "docker.io/" + image
+	}
+	matched := ""
+	for prefix := range mirrors {
+		if (normalized == prefix || strings.HasPrefix(normalized, strings.TrimSuffix(prefix, "/")+"/")) && len(prefix) > len(matched) {
+			matched = prefix
+		}
+	}
+	if len(matched) == 0 {
+		return image
+	}
+	return strings.TrimSuffix(mirrors[matched], "/") + strings.TrimPrefix(normalized, strings.TrimSuffix(matched, "/"))
+}
@@ -0,0 +1,119 @@
+package util
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+type labelMutator struct {
+	name  string
+	value string
+	err   error
+}
+
+func (m labelMutator) Name() string {
+	return m.name
+}
+
+func (m labelMutator) Mutate(object *unstructured.Unstructured) error {
+	if m.err != nil {
+		return m.err
+	}
+	labels := object.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels["order"] = labels["order"] + m.value
+	object.SetLabels(labels)
+	return nil
+}
+
+func newMutationJob() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":   "ci-build",
+			"labels": map[string]interface{}{"team": "ci"},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"securityContext": map[string]interface{}{"runAsUser": int64(1000)},
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":            "build",
+							"image":           "alpine:3.18",
+							"securityContext": map[string]interface{}{"allowPrivilegeEscalation": true},
+						},
+						map[string]interface{}{"name": "sidecar", "image": "quay.io/devtron/sidecar:v1"},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestManifestMutatorChain_Order(t *testing.T) {
+	chain := NewManifestMutatorChain([]string{" b "})
+	chain.Register(labelMutator{name: "a", value: "a"})
+	chain.Register(labelMutator{name: "b", value: "b"})
+	chain.Register(labelMutator{name: "c", value: "c"})
+	object := newMutationJob()
+	assert.Nil(t, chain.Apply(object))
+	assert.Equal(t, "ac", object.GetLabels()["order"])
+}
+
+func TestManifestMutatorChain_FailureAbortsWithMutatorName(t *testing.T) {
+	chain := NewManifestMutatorChain(nil)
+	chain.Register(labelMutator{name: "a", value: "a"})
+	chain.Register(labelMutator{name: "broken", err: errors.New("boom")})
+	chain.Register(labelMutator{name: "c", value: "c"})
+	err := chain.Apply(newMutationJob())
+	assert.EqualError(t, err, "manifest mutator broken failed: boom")
+}
+
+func TestApplyManifestDefaults_ExplicitValuesWin(t *testing.T) {
+	defaults, err := ParseManifestDefaults(map[string]string{
+		"labels":                   "team: security\ncompliance: pci",
+		"podSecurityContext":       "runAsUser: 0\nseccompProfile:\n  type: RuntimeDefault",
+		"containerSecurityContext": "allowPrivilegeEscalation: false\nrunAsNonRoot: true",
+		"registryMirrors":          "docker.io: mirror.example.com/dockerhub",
+	})
+	assert.Nil(t, err)
+	object := newMutationJob()
+	assert.Nil(t, applyManifestDefaults(object, defaults))
+
+	assert.Equal(t, map[string]string{"team": "ci", "compliance": "pci"}, object.GetLabels())
+	templateLabels, _, _ := unstructured.NestedStringMap(object.Object, "spec", "template", "metadata", "labels")
+	assert.Equal(t, map[string]string{"team": "security", "compliance": "pci"}, templateLabels)
+	podSecurityContext, _, _ := unstructured.NestedMap(object.Object, "spec", "template", "spec", "securityContext")
+	assert.Equal(t, map[string]interface{}{"runAsUser": int64(1000), "seccompProfile": map[string]interface{}{"type": "RuntimeDefault"}}, podSecurityContext)
+	containers, _, _ := unstructured.NestedSlice(object.Object, "spec", "template", "spec", "containers")
+	build, sidecar := containers[0].(map[string]interface{}), containers[1].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"allowPrivilegeEscalation": true, "runAsNonRoot": true}, build["securityContext"])
+	assert.Equal(t, map[string]interface{}{"allowPrivilegeEscalation": false, "runAsNonRoot": true}, sidecar["securityContext"])
+	assert.Equal(t, "mirror.example.com/dockerhub/library/alpine:3.18", build["image"])
+	assert.Equal(t, "quay.io/devtron/sidecar:v1", sidecar["image"])
+}
+
+func TestMirrorImage(t *testing.T) {
+	mirrors := map[string]string{"docker.io": "mirror.example.com/dockerhub", "quay.io/devtron": "mirror.example.com/devtron/"}
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "nginx", want: "mirror.example.com/dockerhub/library/nginx"},
+		{image: "bitnami/kubectl:1.27", want: "mirror.example.com/dockerhub/bitnami/kubectl:1.27"},
+		{image: "quay.io/devtron/inception:v1", want: "mirror.example.com/devtron/inception:v1"},
+		{image: "quay.io/devtronx/other:v1", want: "quay.io/devtronx/other:v1"},
+		{image: "localhost:5000/app", want: "localhost:5000/app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			assert.Equal(t, tt.want, mirrorImage(tt.image, mirrors))
+		})
+	}
+}
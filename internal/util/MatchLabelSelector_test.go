@@ -0,0 +1,20 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestMatchLabelSelector(t *testing.T) {
+	podLabels := map[string]string{DevtronAppIdLabelKey: "1", DevtronEnvIdLabelKey: "2"}
+	assert.NoError(t, matchLabelSelector("", nil, v1.Resource("pods"), "demo"))
+	assert.NoError(t, matchLabelSelector("appId=1,envId=2", podLabels, v1.Resource("pods"), "demo"))
+
+	// resources of other apps are reported as not found
+	err := matchLabelSelector("appId=3,envId=2", podLabels, v1.Resource("pods"), "demo")
+	assert.True(t, errors.IsNotFound(err))
+	assert.Error(t, matchLabelSelector("appId in (", podLabels, v1.Resource("pods"), "demo"))
+}
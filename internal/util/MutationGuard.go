@@ -0,0 +1,55 @@
+package util
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	MutationActionCreate  = "create"
+	MutationActionApply   = "apply"
+	MutationActionPause   = "pause"
+	MutationActionResume  = "resume"
+	MutationActionPromote = "promote"
+	MutationActionAbort   = "abort"
+	MutationActionDelete  = "delete"
+	MutationActionRestart = "restart"
+	MutationActionScale   = "scale"
+	// MutationActionSnapshot takes a volume snapshot of a pvc, the pvc itself is left as it is
+	MutationActionSnapshot = "snapshot"
+)
+
+// ClusterMutation is a change devtron is about to make in a cluster on behalf of UserId, 0 for background work
+type ClusterMutation struct {
+	ClusterId int
+	Namespace string
+	Kind      string
+	Name      string
+	Action    string
+	UserId    int32
+}
+
+// MutationGuard decides whether a mutation may go ahead, read only calls are never checked
+type MutationGuard interface {
+	CheckMutation(ctx context.Context, mutation *ClusterMutation) error
+}
+
+type mutationGuardRegistry struct {
+	lock  sync.RWMutex
+	guard MutationGuard
+}
+
+func (r *mutationGuardRegistry) get() MutationGuard {
+	if r == nil {
+		return nil
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.guard
+}
+
+func (r *mutationGuardRegistry) set(guard MutationGuard) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.guard = guard
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestIsNamespaceCleanupSafe(t *testing.T) {
+	newObject := func(name string, labels, annotations map[string]string, owned bool) unstructured.Unstructured {
+		object := unstructured.Unstructured{}
+		object.SetName(name)
+		object.SetLabels(labels)
+		object.SetAnnotations(annotations)
+		if owned {
+			object.SetOwnerReferences([]metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-5d8f"}})
+		}
+		return object
+	}
+	tests := []struct {
+		name     string
+		resource string
+		object   unstructured.Unstructured
+		want     bool
+	}{
+		{name: "owned pod", resource: "pods", object: newObject("web-5d8f-x2", nil, nil, true), want: true},
+		{name: "default service account", resource: "serviceaccounts", object: newObject(K8sDefaultServiceAccountName, nil, nil, false), want: true},
+		{name: "root ca", resource: "configmaps", object: newObject(K8sRootCAConfigMapName, nil, nil, false), want: true},
+		{name: "default token", resource: "secrets", object: newObject("default-token-x", nil, map[string]string{v1.ServiceAccountNameKey: K8sDefaultServiceAccountName}, false), want: true},
+		{name: "devtron managed", resource: "configmaps", object: newObject("cm", map[string]string{K8sManagedByLabelKey: DevtronManagedByLabelValue}, nil, false), want: true},
+		{name: "devtron app", resource: "deployments", object: newObject("web", map[string]string{DevtronAppIdLabelKey: "3", DevtronEnvIdLabelKey: "5"}, nil, false), want: true},
+		{name: "third party helm release", resource: "deployments", object: newObject("redis", map[string]string{K8sManagedByLabelKey: "Helm"}, nil, false), want: false},
+		{name: "user service account", resource: "serviceaccounts", object: newObject("ci", nil, nil, false), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isNamespaceCleanupSafe(tt.resource, tt.object))
+		})
+	}
+}
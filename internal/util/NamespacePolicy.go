@@ -0,0 +1,124 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// NamespacePolicyAllow limits devtron to the namespaces matching one of the patterns
+	NamespacePolicyAllow = "allow"
+	// NamespacePolicyDeny keeps devtron out of the namespaces matching one of the patterns
+	NamespacePolicyDeny = "deny"
+)
+
+// NamespacePolicy is the allowlist or denylist of namespaces devtron may touch in a cluster. Patterns are regular
+// expressions matched against the whole namespace name, reads are only restricted with RestrictReads
+type NamespacePolicy struct {
+	Mode          string
+	Patterns      []string
+	RestrictReads bool
+}
+
+// ErrNamespacePolicyDenied is returned for calls touching a namespace the policy of the cluster keeps devtron out of,
+// Pattern is the denylist pattern matching the namespace and empty for namespaces missing from an allowlist
+type ErrNamespacePolicyDenied struct {
+	ClusterId int
+	Namespace string
+	Mode      string
+	Pattern   string
+	Read      bool
+}
+
+func (e *ErrNamespacePolicyDenied) Error() string {
+	access := "changes to"
+	if e.Read {
+		access = "reads of"
+	}
+	if e.Mode == NamespacePolicyDeny {
+		return fmt.Sprintf("%s namespace %s are blocked by the namespace denylist of cluster %d, pattern %q", access, e.Namespace, e.ClusterId, e.Pattern)
+	}
+	return fmt.Sprintf("%s namespace %s are blocked by the namespace allowlist of cluster %d, no pattern matches", access, e.Namespace, e.ClusterId)
+}
+
+// CompileNamespacePatterns anchors each pattern so that it has to match the whole namespace name, the error names the
+// first invalid pattern
+func CompileNamespacePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		expression, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace pattern %q: %v", pattern, err)
+		}
+		compiled = append(compiled, expression)
+	}
+	return compiled, nil
+}
+
+// Check returns ErrNamespacePolicyDenied when the policy keeps devtron out of the namespace. Cluster scoped calls, i.e.
+// an empty namespace, and reads without RestrictReads are always allowed, as are all calls without a mode
+func (p *NamespacePolicy) Check(clusterId int, namespace string, mutating bool) error {
+	if p == nil || len(p.Mode) == 0 || len(namespace) == 0 || (!mutating && !p.RestrictReads) {
+		return nil
+	}
+	patterns, err := CompileNamespacePatterns(p.Patterns)
+	if err != nil {
+		return err
+	}
+	denied := &ErrNamespacePolicyDenied{ClusterId: clusterId, Namespace: namespace, Mode: p.Mode, Read: !mutating}
+	for i, pattern := range patterns {
+		if !pattern.MatchString(namespace) {
+			continue
+		}
+		if p.Mode == NamespacePolicyDeny {
+			denied.Pattern = p.Patterns[i]
+			return denied
+		}
+		return nil
+	}
+	if p.Mode == NamespacePolicyAllow {
+		return denied
+	}
+	return nil
+}
+
+// ManifestNamespace is the namespace a manifest touches, for a Namespace it is the namespace itself and defaultNamespace
+// when the manifest leaves it empty
+func ManifestNamespace(manifest *unstructured.Unstructured, defaultNamespace string) string {
+	if manifest.GetKind() == "Namespace" && manifest.GroupVersionKind().Group == "" {
+		return manifest.GetName()
+	}
+	if len(manifest.GetNamespace()) == 0 {
+		return defaultNamespace
+	}
+	return manifest.GetNamespace()
+}
+
+// NamespacePolicyEnforcer looks up the namespace policy of the cluster and checks the call against it
+type NamespacePolicyEnforcer interface {
+	CheckNamespaceAccess(ctx context.Context, clusterId int, namespace string, mutating bool) error
+}
+
+type namespacePolicyRegistry struct {
+	lock     sync.RWMutex
+	enforcer NamespacePolicyEnforcer
+}
+
+func (r *namespacePolicyRegistry) get() NamespacePolicyEnforcer {
+	if r == nil {
+		return nil
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.enforcer
+}
+
+func (r *namespacePolicyRegistry) set(enforcer NamespacePolicyEnforcer) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.enforcer = enforcer
+}
@@ -0,0 +1,92 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNamespacePolicy_Check(t *testing.T) {
+	deny := &NamespacePolicy{Mode: NamespacePolicyDeny, Patterns: []string{"kube-.*", "default"}}
+	allow := &NamespacePolicy{Mode: NamespacePolicyAllow, Patterns: []string{"team-[a-z]+"}, RestrictReads: true}
+	tests := []struct {
+		name      string
+		policy    *NamespacePolicy
+		namespace string
+		mutating  bool
+		wantErr   bool
+		pattern   string
+	}{
+		{name: "no policy", policy: nil, namespace: "kube-system", mutating: true},
+		{name: "denied namespace", policy: deny, namespace: "kube-system", mutating: true, wantErr: true, pattern: "kube-.*"},
+		{name: "patterns match the whole name", policy: deny, namespace: "default-apps", mutating: true},
+		{name: "reads are not restricted by default", policy: deny, namespace: "kube-system"},
+		{name: "cluster scoped call", policy: allow, mutating: true},
+		{name: "allowed namespace", policy: allow, namespace: "team-payments", mutating: true},
+		{name: "namespace missing from allowlist", policy: allow, namespace: "payments", mutating: true, wantErr: true},
+		{name: "restricted read", policy: allow, namespace: "payments", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(3, tt.namespace, tt.mutating)
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				return
+			}
+			var denied *ErrNamespacePolicyDenied
+			assert.True(t, errors.As(err, &denied))
+			assert.Equal(t, tt.pattern, denied.Pattern)
+			assert.Equal(t, !tt.mutating, denied.Read)
+			assert.Contains(t, err.Error(), tt.namespace)
+		})
+	}
+}
+
+func TestCompileNamespacePatterns(t *testing.T) {
+	_, err := CompileNamespacePatterns([]string{"team-.*", "prod-(a|b"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `"prod-(a|b"`)
+
+	patterns, err := CompileNamespacePatterns([]string{"a|b"})
+	assert.Nil(t, err)
+	assert.True(t, patterns[0].MatchString("b"))
+	assert.False(t, patterns[0].MatchString("ab"))
+}
+
+func TestManifestNamespace(t *testing.T) {
+	namespace := &unstructured.Unstructured{}
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind("Namespace")
+	namespace.SetName("team-a")
+	assert.Equal(t, "team-a", ManifestNamespace(namespace, "default"))
+
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	assert.Equal(t, "default", ManifestNamespace(configMap, "default"))
+	configMap.SetNamespace("team-b")
+	assert.Equal(t, "team-b", ManifestNamespace(configMap, "default"))
+}
+
+type staticNamespacePolicyEnforcer struct {
+	policy *NamespacePolicy
+}
+
+func (e *staticNamespacePolicyEnforcer) CheckNamespaceAccess(ctx context.Context, clusterId int, namespace string, mutating bool) error {
+	return e.policy.Check(clusterId, namespace, mutating)
+}
+
+func TestK8sUtil_CheckNamespaceAccess(t *testing.T) {
+	k8sUtil := &K8sUtil{logger: zap.NewNop().Sugar(), namespacePolicy: &namespacePolicyRegistry{}}
+	cluster := &ClusterConfig{ClusterId: 2}
+	assert.Nil(t, k8sUtil.CheckNamespaceAccess(context.Background(), cluster, "kube-system", true))
+
+	k8sUtil.SetNamespacePolicyEnforcer(&staticNamespacePolicyEnforcer{policy: &NamespacePolicy{Mode: NamespacePolicyDeny, Patterns: []string{"kube-.*"}}})
+	assert.NotNil(t, k8sUtil.CheckNamespaceAccess(context.Background(), cluster, "kube-system", true))
+	// configs of clusters which are not added to devtron are left alone
+	assert.Nil(t, k8sUtil.CheckNamespaceAccess(context.Background(), &ClusterConfig{}, "kube-system", true))
+}
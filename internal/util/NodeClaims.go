@@ -0,0 +1,196 @@
+package util
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// NodeClaimGVR is the karpenter NodeClaim, a request for a node created by a NodePool and kept until the node is removed
+var NodeClaimGVR = schema.GroupVersionResource{Group: "karpenter.sh", Version: "v1", Resource: "nodeclaims"}
+
+const (
+	NodeClaimKind             = "NodeClaim"
+	KarpenterNodePoolLabelKey = "karpenter.sh/nodepool"
+	KarpenterCapacityTypeKey  = "karpenter.sh/capacity-type"
+	nodeInstanceTypeLabelKey  = "node.kubernetes.io/instance-type"
+	topologyZoneLabelKey      = "topology.kubernetes.io/zone"
+
+	NodeClaimPhaseLaunching    = "Launching"
+	NodeClaimPhaseRegistering  = "Registering"
+	NodeClaimPhaseInitializing = "Initializing"
+	NodeClaimPhaseReady        = "Ready"
+	NodeClaimPhaseDeleting     = "Deleting"
+)
+
+// NodeClaimInfo is a karpenter NodeClaim, Phase is the first of the Launched, Registered, Initialized and Ready
+// conditions which is not true yet and Message explains it when karpenter reports a reason
+type NodeClaimInfo struct {
+	Name         string    `json:"name"`
+	NodePoolName string    `json:"nodePoolName,omitempty"`
+	NodeName     string    `json:"nodeName,omitempty"`
+	InstanceType string    `json:"instanceType,omitempty"`
+	CapacityType string    `json:"capacityType,omitempty"`
+	Zone         string    `json:"zone,omitempty"`
+	Phase        string    `json:"phase"`
+	Message      string    `json:"message,omitempty"`
+	CreatedOn    time.Time `json:"createdOn"`
+}
+
+// NodeProvisioningStatus summarises the NodeClaims of a cluster, Enabled is false when karpenter is not installed
+type NodeProvisioningStatus struct {
+	Enabled    bool             `json:"enabled"`
+	Pending    int              `json:"pending"`
+	Ready      int              `json:"ready"`
+	Deleting   int              `json:"deleting"`
+	NodeClaims []*NodeClaimInfo `json:"nodeClaims,omitempty"`
+}
+
+// GetNodeClaim gets the karpenter NodeClaim, NodeClaims are cluster scoped
+func (impl K8sUtil) GetNodeClaim(ctx context.Context, name string, clusterConfig *ClusterConfig) (*unstructured.Unstructured, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return nil, err
+	}
+	nodeClaim, err := dynamicClient.Resource(NodeClaimGVR).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorw("error in getting node claim", "err", err, "name", name)
+		return nil, err
+	}
+	return nodeClaim, nil
+}
+
+// ListNodeClaims lists the karpenter NodeClaims of the cluster, clusters without karpenter have none
+func (impl K8sUtil) ListNodeClaims(ctx context.Context, clusterConfig *ClusterConfig) ([]*unstructured.Unstructured, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return nil, err
+	}
+	nodeClaimList, err := dynamicClient.Resource(NodeClaimGVR).List(ctx, metav1.ListOptions{})
+	if errors.IsNotFound(err) {
+		logger.Debugw("node claim crd not installed", "clusterId", clusterConfig.ClusterId)
+		return []*unstructured.Unstructured{}, nil
+	} else if err != nil {
+		logger.Errorw("error in listing node claims", "err", err)
+		return nil, err
+	}
+	nodeClaims := make([]*unstructured.Unstructured, 0, len(nodeClaimList.Items))
+	for i := range nodeClaimList.Items {
+		nodeClaims = append(nodeClaims, &nodeClaimList.Items[i])
+	}
+	return nodeClaims, nil
+}
+
+// GetNodeProvisioningStatus reads the NodeClaims of the cluster into a NodeProvisioningStatus
+func (impl K8sUtil) GetNodeProvisioningStatus(ctx context.Context, clusterConfig *ClusterConfig) (*NodeProvisioningStatus, error) {
+	nodeClaims, err := impl.ListNodeClaims(ctx, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	nodeClaimInfos := make([]*NodeClaimInfo, 0, len(nodeClaims))
+	for _, nodeClaim := range nodeClaims {
+		nodeClaimInfos = append(nodeClaimInfos, ParseNodeClaimInfo(nodeClaim))
+	}
+	// karpenter may be installed with no claims yet, the crd answering the list is what tells it apart
+	status := NewNodeProvisioningStatus(nodeClaimInfos)
+	status.Enabled = status.Enabled || impl.isNodeClaimServed(ctx, clusterConfig)
+	return status, nil
+}
+
+func (impl K8sUtil) isNodeClaimServed(ctx context.Context, clusterConfig *ClusterConfig) bool {
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		return false
+	}
+	resources, err := clientSet.Discovery().ServerResourcesForGroupVersion(NodeClaimGVR.GroupVersion().String())
+	if err != nil {
+		LoggerFromContext(ctx, impl.logger).Debugw("node claims not served", "err", err)
+		return false
+	}
+	for _, apiResource := range resources.APIResources {
+		if apiResource.Name == NodeClaimGVR.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// NewNodeProvisioningStatus counts the NodeClaims by phase, claims are sorted with the pending ones first
+func NewNodeProvisioningStatus(nodeClaims []*NodeClaimInfo) *NodeProvisioningStatus {
+	status := &NodeProvisioningStatus{Enabled: len(nodeClaims) > 0, NodeClaims: nodeClaims}
+	for _, nodeClaim := range nodeClaims {
+		switch nodeClaim.Phase {
+		case NodeClaimPhaseReady:
+			status.Ready++
+		case NodeClaimPhaseDeleting:
+			status.Deleting++
+		default:
+			status.Pending++
+		}
+	}
+	sort.SliceStable(status.NodeClaims, func(i, j int) bool {
+		iReady, jReady := status.NodeClaims[i].Phase == NodeClaimPhaseReady, status.NodeClaims[j].Phase == NodeClaimPhaseReady
+		if iReady != jReady {
+			return jReady
+		}
+		return status.NodeClaims[i].Name < status.NodeClaims[j].Name
+	})
+	return status
+}
+
+// ParseNodeClaimInfo reads a karpenter.sh/v1 NodeClaim
+func ParseNodeClaimInfo(nodeClaim *unstructured.Unstructured) *NodeClaimInfo {
+	labels := nodeClaim.GetLabels()
+	nodeClaimInfo := &NodeClaimInfo{
+		Name:         nodeClaim.GetName(),
+		NodePoolName: labels[KarpenterNodePoolLabelKey],
+		InstanceType: labels[nodeInstanceTypeLabelKey],
+		CapacityType: labels[KarpenterCapacityTypeKey],
+		Zone:         labels[topologyZoneLabelKey],
+		CreatedOn:    nodeClaim.GetCreationTimestamp().Time,
+	}
+	nodeClaimInfo.NodeName, _, _ = unstructured.NestedString(nodeClaim.Object, "status", "nodeName")
+	if nodeClaim.GetDeletionTimestamp() != nil {
+		nodeClaimInfo.Phase = NodeClaimPhaseDeleting
+		return nodeClaimInfo
+	}
+	conditions, _, _ := unstructured.NestedSlice(nodeClaim.Object, "status", "conditions")
+	conditionsByType := make(map[string]map[string]interface{}, len(conditions))
+	for _, condition := range conditions {
+		if conditionObject, ok := condition.(map[string]interface{}); ok {
+			if conditionType, ok := conditionObject["type"].(string); ok {
+				conditionsByType[conditionType] = conditionObject
+			}
+		}
+	}
+	phases := []struct{ conditionType, phase string }{
+		{"Launched", NodeClaimPhaseLaunching},
+		{"Registered", NodeClaimPhaseRegistering},
+		{"Initialized", NodeClaimPhaseInitializing},
+		{"Ready", NodeClaimPhaseInitializing},
+	}
+	nodeClaimInfo.Phase = NodeClaimPhaseReady
+	for _, phase := range phases {
+		condition := conditionsByType[phase.conditionType]
+		if condition["status"] == "True" {
+			continue
+		}
+		nodeClaimInfo.Phase = phase.phase
+		if message, ok := condition["message"].(string); ok && len(message) > 0 {
+			nodeClaimInfo.Message = message
+		} else if reason, ok := condition["reason"].(string); ok {
+			nodeClaimInfo.Message = reason
+		}
+		break
+	}
+	return nodeClaimInfo
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func nodeClaim(t *testing.T, content string) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{}
+	assert.Nil(t, yaml.Unmarshal([]byte(content), &object.Object))
+	return object
+}
+
+func TestParseNodeClaimInfo(t *testing.T) {
+	ready := ParseNodeClaimInfo(nodeClaim(t, `
+apiVersion: karpenter.sh/v1
+kind: NodeClaim
+metadata:
+  name: default-x7k2p
+  labels:
+    karpenter.sh/nodepool: default
+    karpenter.sh/capacity-type: spot
+    node.kubernetes.io/instance-type: m5.large
+    topology.kubernetes.io/zone: us-east-1a
+status:
+  nodeName: ip-10-0-1-12.ec2.internal
+  conditions:
+  - type: Launched
+    status: "True"
+  - type: Registered
+    status: "True"
+  - type: Initialized
+    status: "True"
+  - type: Ready
+    status: "True"
+`))
+	assert.Equal(t, NodeClaimInfo{Name: "default-x7k2p", NodePoolName: "default", NodeName: "ip-10-0-1-12.ec2.internal",
+		InstanceType: "m5.large", CapacityType: "spot", Zone: "us-east-1a", Phase: NodeClaimPhaseReady, CreatedOn: ready.CreatedOn}, *ready)
+
+	launching := ParseNodeClaimInfo(nodeClaim(t, `
+apiVersion: karpenter.sh/v1
+kind: NodeClaim
+metadata:
+  name: default-9qzvt
+status:
+  conditions:
+  - type: Launched
+    status: "False"
+    reason: InsufficientCapacity
+    message: all requested instance types were unavailable during launch
+`))
+	assert.Equal(t, NodeClaimPhaseLaunching, launching.Phase)
+	assert.Equal(t, "all requested instance types were unavailable during launch", launching.Message)
+
+	registering := ParseNodeClaimInfo(nodeClaim(t, `
+apiVersion: karpenter.sh/v1
+kind: NodeClaim
+metadata:
+  name: default-4hm8d
+status:
+  conditions:
+  - type: Launched
+    status: "True"
+  - type: Registered
+    status: Unknown
+    reason: AwaitingReconciliation
+`))
+	assert.Equal(t, NodeClaimPhaseRegistering, registering.Phase)
+	assert.Equal(t, "AwaitingReconciliation", registering.Message)
+
+	deleting := ParseNodeClaimInfo(nodeClaim(t, `
+apiVersion: karpenter.sh/v1
+kind: NodeClaim
+metadata:
+  name: default-b2lrc
+  deletionTimestamp: "2024-01-01T00:00:00Z"
+`))
+	assert.Equal(t, NodeClaimPhaseDeleting, deleting.Phase)
+
+	status := NewNodeProvisioningStatus([]*NodeClaimInfo{ready, launching, registering, deleting})
+	assert.True(t, status.Enabled)
+	assert.Equal(t, 1, status.Ready)
+	assert.Equal(t, 2, status.Pending)
+	assert.Equal(t, 1, status.Deleting)
+	assert.Equal(t, "default-x7k2p", status.NodeClaims[3].Name)
+	assert.False(t, NewNodeProvisioningStatus(nil).Enabled)
+}
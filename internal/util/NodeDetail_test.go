@@ -0,0 +1,72 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func requests(cpu, memory string) v1.ResourceRequirements {
+	return v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu), v1.ResourceMemory: resource.MustParse(memory)}}
+}
+
+func TestSumNodePodRequests(t *testing.T) {
+	pods := []v1.Pod{
+		{
+			Spec: v1.PodSpec{
+				Containers:     []v1.Container{{Resources: requests("100m", "64Mi")}, {Resources: requests("200m", "64Mi")}},
+				InitContainers: []v1.Container{{Resources: requests("500m", "32Mi")}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		},
+		{
+			Spec:   v1.PodSpec{Containers: []v1.Container{{Resources: requests("250m", "128Mi")}}},
+			Status: v1.PodStatus{Phase: v1.PodPending},
+		},
+		{
+			Spec:   v1.PodSpec{Containers: []v1.Container{{Resources: requests("4", "4Gi")}}},
+			Status: v1.PodStatus{Phase: v1.PodSucceeded},
+		},
+	}
+	total, count := sumNodePodRequests(pods)
+	assert.Equal(t, 2, count)
+	cpu := total[v1.ResourceCPU]
+	memory := total[v1.ResourceMemory]
+	assert.Equal(t, int64(750), cpu.MilliValue())
+	assert.Equal(t, int64(256*1024*1024), memory.Value())
+}
+
+func TestNewNodeDetail(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{v1.ResourcePods: resource.MustParse("110")},
+			Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionTrue},
+				{Type: v1.NodeMemoryPressure, Status: v1.ConditionTrue},
+				{Type: v1.NodeDiskPressure, Status: v1.ConditionFalse},
+			},
+		},
+	}
+	detail := newNodeDetail(node)
+	assert.True(t, detail.Ready)
+	assert.Equal(t, int64(110), detail.PodCapacity)
+	assert.Equal(t, []string{string(v1.NodeMemoryPressure)}, detail.Pressure)
+}
+
+func TestRecentNodeEvents(t *testing.T) {
+	now := time.Now()
+	events := []v1.Event{
+		{Reason: "NodeNotReady", LastTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+		{Reason: "NodeReady", LastTimestamp: metav1.NewTime(now)},
+		{Reason: "NodeHasDiskPressure", EventTime: metav1.NewMicroTime(now.Add(-time.Minute))},
+	}
+	recent := recentNodeEvents(events, 2)
+	assert.Len(t, recent, 2)
+	assert.Equal(t, "NodeReady", recent[0].Reason)
+	assert.Equal(t, "NodeHasDiskPressure", recent[1].Reason)
+}
@@ -0,0 +1,140 @@
+package util
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sync"
+)
+
+// PodPlacement is where pods created by devtron, i.e. jobs and terminal pods, are scheduled when their spec says nothing
+type PodPlacement struct {
+	NodeSelector     map[string]string `json:"nodeSelector,omitempty"`
+	Tolerations      []v1.Toleration   `json:"tolerations,omitempty"`
+	RuntimeClassName string            `json:"runtimeClassName,omitempty"`
+}
+
+func (p *PodPlacement) IsEmpty() bool {
+	return p == nil || (len(p.NodeSelector) == 0 && len(p.Tolerations) == 0 && len(p.RuntimeClassName) == 0)
+}
+
+// PodPlacementResolver returns the effective placement for pods created in the namespace of the cluster, nil if none
+type PodPlacementResolver interface {
+	ResolvePodPlacement(clusterId int, namespace string) (*PodPlacement, error)
+}
+
+type podPlacementRegistry struct {
+	lock     sync.RWMutex
+	resolver PodPlacementResolver
+}
+
+func (r *podPlacementRegistry) get() PodPlacementResolver {
+	if r == nil {
+		return nil
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.resolver
+}
+
+func (r *podPlacementRegistry) set(resolver PodPlacementResolver) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.resolver = resolver
+}
+
+// MergePodPlacement overrides the cluster policy with every field set in the namespace policy, tolerations are
+// replaced as a whole and not merged so that a namespace can drop the tolerations of its cluster
+func MergePodPlacement(clusterPolicy *PodPlacement, namespacePolicy *PodPlacement) *PodPlacement {
+	if clusterPolicy.IsEmpty() && namespacePolicy.IsEmpty() {
+		return nil
+	}
+	merged := &PodPlacement{}
+	for _, policy := range []*PodPlacement{clusterPolicy, namespacePolicy} {
+		if policy == nil {
+			continue
+		}
+		if len(policy.NodeSelector) > 0 {
+			merged.NodeSelector = policy.NodeSelector
+		}
+		if len(policy.Tolerations) > 0 {
+			merged.Tolerations = policy.Tolerations
+		}
+		if len(policy.RuntimeClassName) > 0 {
+			merged.RuntimeClassName = policy.RuntimeClassName
+		}
+	}
+	return merged
+}
+
+// ApplyPodPlacement fills the placement into the pod template of the object, whatever the spec already specifies wins:
+// the node selector is skipped when the spec has a nodeName, nodeSelector or node affinity, tolerations are added
+// unless the spec tolerates the same key and effect and the runtime class is only set when missing
+func ApplyPodPlacement(object *unstructured.Unstructured, placement *PodPlacement) error {
+	podTemplatePath := podTemplateFields(object.GetKind())
+	if placement.IsEmpty() || podTemplatePath == nil {
+		return nil
+	}
+	specPath := append(append([]string{}, podTemplatePath...), "spec")
+	spec, found, err := unstructured.NestedMap(object.Object, specPath...)
+	if err != nil || !found {
+		return err
+	}
+	if len(placement.NodeSelector) > 0 && !hasNodePlacement(spec) {
+		nodeSelector := make(map[string]interface{}, len(placement.NodeSelector))
+		for key, value := range placement.NodeSelector {
+			nodeSelector[key] = value
+		}
+		spec["nodeSelector"] = nodeSelector
+	}
+	if len(placement.Tolerations) > 0 {
+		tolerations, _, err := unstructured.NestedSlice(spec, "tolerations")
+		if err != nil {
+			return err
+		}
+		for i := range placement.Tolerations {
+			toleration := placement.Tolerations[i]
+			if hasToleration(tolerations, toleration) {
+				continue
+			}
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&toleration)
+			if err != nil {
+				return err
+			}
+			tolerations = append(tolerations, content)
+		}
+		spec["tolerations"] = tolerations
+	}
+	if len(placement.RuntimeClassName) > 0 {
+		if runtimeClassName, _, _ := unstructured.NestedString(spec, "runtimeClassName"); len(runtimeClassName) == 0 {
+			spec["runtimeClassName"] = placement.RuntimeClassName
+		}
+	}
+	return unstructured.SetNestedMap(object.Object, spec, specPath...)
+}
+
+func hasNodePlacement(spec map[string]interface{}) bool {
+	if nodeName, _, _ := unstructured.NestedString(spec, "nodeName"); len(nodeName) > 0 {
+		return true
+	}
+	if nodeSelector, _, _ := unstructured.NestedMap(spec, "nodeSelector"); len(nodeSelector) > 0 {
+		return true
+	}
+	nodeAffinity, _, _ := unstructured.NestedMap(spec, "affinity", "nodeAffinity")
+	return len(nodeAffinity) > 0
+}
+
+func hasToleration(tolerations []interface{}, toleration v1.Toleration) bool {
+	for _, item := range tolerations {
+		existing, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key, _ := existing["key"].(string)
+		effect, _ := existing["effect"].(string)
+		if key == toleration.Key && effect == string(toleration.Effect) {
+			return true
+		}
+	}
+	return false
+}
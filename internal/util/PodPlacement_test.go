@@ -0,0 +1,107 @@
+package util
+
+import (
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+var ciToleration = v1.Toleration{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "ci", Effect: v1.TaintEffectNoSchedule}
+
+func newPlacementPod(spec map[string]interface{}) *unstructured.Unstructured {
+	spec["containers"] = []interface{}{map[string]interface{}{"name": "main", "image": "alpine"}}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "terminal"},
+		"spec":       spec,
+	}}
+}
+
+func TestMergePodPlacement(t *testing.T) {
+	clusterPolicy := &PodPlacement{NodeSelector: map[string]string{"pool": "utility"}, Tolerations: []v1.Toleration{ciToleration}, RuntimeClassName: "gvisor"}
+	namespacePolicy := &PodPlacement{NodeSelector: map[string]string{"pool": "team-a"}}
+
+	merged := MergePodPlacement(clusterPolicy, namespacePolicy)
+	assert.Equal(t, map[string]string{"pool": "team-a"}, merged.NodeSelector, "namespace policy wins over cluster policy")
+	assert.Equal(t, []v1.Toleration{ciToleration}, merged.Tolerations, "fields missing in the namespace policy fall back to the cluster")
+	assert.Equal(t, "gvisor", merged.RuntimeClassName)
+
+	assert.Equal(t, clusterPolicy, MergePodPlacement(clusterPolicy, nil))
+	assert.Nil(t, MergePodPlacement(nil, &PodPlacement{}))
+}
+
+func TestApplyPodPlacement(t *testing.T) {
+	placement := &PodPlacement{NodeSelector: map[string]string{"pool": "utility"}, Tolerations: []v1.Toleration{ciToleration}, RuntimeClassName: "gvisor"}
+	tests := []struct {
+		name                 string
+		spec                 map[string]interface{}
+		wantNodeSelector     map[string]interface{}
+		wantTolerations      int
+		wantRuntimeClassName string
+	}{
+		{
+			name:                 "policy fills an empty spec",
+			spec:                 map[string]interface{}{},
+			wantNodeSelector:     map[string]interface{}{"pool": "utility"},
+			wantTolerations:      1,
+			wantRuntimeClassName: "gvisor",
+		},
+		{
+			name:                 "node selector of the request wins",
+			spec:                 map[string]interface{}{"nodeSelector": map[string]interface{}{"kubernetes.io/hostname": "node-1"}},
+			wantNodeSelector:     map[string]interface{}{"kubernetes.io/hostname": "node-1"},
+			wantTolerations:      1,
+			wantRuntimeClassName: "gvisor",
+		},
+		{
+			name: "node affinity and runtime class of the request win",
+			spec: map[string]interface{}{
+				"affinity":         map[string]interface{}{"nodeAffinity": map[string]interface{}{"requiredDuringSchedulingIgnoredDuringExecution": map[string]interface{}{}}},
+				"runtimeClassName": "kata",
+			},
+			wantTolerations:      1,
+			wantRuntimeClassName: "kata",
+		},
+		{
+			name: "toleration of the request for the same key and effect is kept",
+			spec: map[string]interface{}{
+				"tolerations": []interface{}{map[string]interface{}{"key": "dedicated", "operator": "Exists", "effect": "NoSchedule"}},
+			},
+			wantNodeSelector:     map[string]interface{}{"pool": "utility"},
+			wantTolerations:      1,
+			wantRuntimeClassName: "gvisor",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newPlacementPod(tt.spec)
+			assert.NoError(t, ApplyPodPlacement(pod, placement))
+			nodeSelector, _, _ := unstructured.NestedMap(pod.Object, "spec", "nodeSelector")
+			if tt.wantNodeSelector == nil {
+				assert.Empty(t, nodeSelector)
+			} else {
+				assert.Equal(t, tt.wantNodeSelector, nodeSelector)
+			}
+			tolerations, _, _ := unstructured.NestedSlice(pod.Object, "spec", "tolerations")
+			assert.Len(t, tolerations, tt.wantTolerations)
+			runtimeClassName, _, _ := unstructured.NestedString(pod.Object, "spec", "runtimeClassName")
+			assert.Equal(t, tt.wantRuntimeClassName, runtimeClassName)
+		})
+	}
+}
+
+func TestApplyPodPlacementOnJobTemplate(t *testing.T) {
+	job := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   map[string]interface{}{"name": "chart-sync"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "main", "image": "alpine"}},
+		}}},
+	}}
+	assert.NoError(t, ApplyPodPlacement(job, &PodPlacement{NodeSelector: map[string]string{"pool": "utility"}}))
+	nodeSelector, _, _ := unstructured.NestedStringMap(job.Object, "spec", "template", "spec", "nodeSelector")
+	assert.Equal(t, map[string]string{"pool": "utility"}, nodeSelector)
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetPodInjectedSidecarNames(t *testing.T) {
+	k8sUtil := &K8sUtil{logger: zap.NewNop().Sugar()}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			"sidecar.istio.io/status": `{"initContainers":["istio-init"],"containers":["istio-proxy","mesh-agent"]}`,
+		}},
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{Name: "istio-init"}, {Name: "linkerd-proxy"}},
+			Containers:     []v1.Container{{Name: "app"}, {Name: "istio-proxy"}, {Name: "mesh-agent"}},
+		},
+	}
+	assert.Equal(t, []string{"linkerd-proxy", "istio-proxy", "mesh-agent"}, k8sUtil.GetPodInjectedSidecarNames(pod))
+
+	pod.Annotations = map[string]string{"sidecar.istio.io/status": "not json"}
+	pod.Spec.InitContainers = nil
+	assert.Equal(t, []string{"istio-proxy"}, k8sUtil.GetPodInjectedSidecarNames(pod))
+
+	assert.Nil(t, k8sUtil.GetPodInjectedSidecarNames(&v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "app"}}}}))
+	assert.Nil(t, k8sUtil.GetPodInjectedSidecarNames(nil))
+}
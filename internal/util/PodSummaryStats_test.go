@@ -0,0 +1,22 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestNewPodSummaryStats(t *testing.T) {
+	pods := []v1.Pod{
+		{Status: v1.PodStatus{Phase: v1.PodRunning, ContainerStatuses: []v1.ContainerStatus{{RestartCount: 2}, {RestartCount: 1}}}},
+		{Status: v1.PodStatus{Phase: v1.PodRunning}},
+		{Status: v1.PodStatus{Phase: v1.PodPending, InitContainerStatuses: []v1.ContainerStatus{{RestartCount: 3}}}},
+		{Status: v1.PodStatus{Phase: v1.PodFailed}},
+		{Status: v1.PodStatus{Phase: v1.PodSucceeded}},
+		{Status: v1.PodStatus{}},
+	}
+	stats := newPodSummaryStats("demo", pods)
+	assert.Equal(t, &PodSummaryStats{Namespace: "demo", Total: 6, Running: 2, Pending: 1, Failed: 1, Succeeded: 1, Unknown: 1, Restarts: 6}, stats)
+	assert.Equal(t, &PodSummaryStats{Namespace: "demo"}, newPodSummaryStats("demo", nil))
+}
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	RequestIdHeader = "X-Request-ID"
+	// RequestIdAnnotation is stamped on resources created while serving a request when enabled
+	RequestIdAnnotation = "devtron.ai/request-id"
+	RequestIdLogKey     = "requestId"
+)
+
+type RequestIdConfig struct {
+	AnnotateResources bool `env:"REQUEST_ID_ANNOTATE_RESOURCES" envDefault:"false"`
+}
+
+type requestIdContextKey struct{}
+
+type userIdContextKey struct{}
+
+func GetRequestIdConfig() (*RequestIdConfig, error) {
+	config := &RequestIdConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+func ContextWithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey{}, requestId)
+}
+
+// RequestIdFromContext returns the id of the request being served, empty for background work
+func RequestIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestId, _ := ctx.Value(requestIdContextKey{}).(string)
+	return requestId
+}
+
+// ContextWithUserId carries the logged in user to checks done below the handlers, e.g. the MutationGuard
+func ContextWithUserId(ctx context.Context, userId int32) context.Context {
+	return context.WithValue(ctx, userIdContextKey{}, userId)
+}
+
+// UserIdFromContext returns the user the request is served for, 0 for background work
+func UserIdFromContext(ctx context.Context) int32 {
+	if ctx == nil {
+		return 0
+	}
+	userId, _ := ctx.Value(userIdContextKey{}).(int32)
+	return userId
+}
+
+// LoggerFromContext returns the logger with the request id attached so that logs across layers can be correlated
+func LoggerFromContext(ctx context.Context, logger *zap.SugaredLogger) *zap.SugaredLogger {
+	if requestId := RequestIdFromContext(ctx); len(requestId) > 0 {
+		return logger.With(RequestIdLogKey, requestId)
+	}
+	return logger
+}
+
+// AnnotateWithRequestId stamps the request id on the object, nothing is done outside a request
+func AnnotateWithRequestId(ctx context.Context, object metav1.Object) {
+	requestId := RequestIdFromContext(ctx)
+	if len(requestId) == 0 {
+		return
+	}
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[RequestIdAnnotation] = requestId
+	object.SetAnnotations(annotations)
+}
+
+// AnnotateManifestWithRequestId is AnnotateWithRequestId for json manifests
+func AnnotateManifestWithRequestId(ctx context.Context, manifest string) (string, error) {
+	if len(RequestIdFromContext(ctx)) == 0 {
+		return manifest, nil
+	}
+	object := &unstructured.Unstructured{}
+	if err := json.Unmarshal([]byte(manifest), &object.Object); err != nil {
+		return "", err
+	}
+	AnnotateWithRequestId(ctx, object)
+	annotated, err := json.Marshal(object.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(annotated), nil
+}
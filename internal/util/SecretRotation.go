@@ -0,0 +1,225 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+const (
+	SecretRotationRotated   = "rotated"
+	SecretRotationUnchanged = "unchanged"
+	SecretRotationPending   = "pending"
+	SecretRotationSkipped   = "skipped"
+	SecretRotationFailed    = "failed"
+
+	secretUpsertMaxAttempts = 5
+	secretUpsertBackoff     = 200 * time.Millisecond
+)
+
+// DefaultSecretRotationSelector limits rotation to the copies of a secret created by devtron
+var DefaultSecretRotationSelector = K8sManagedByLabelKey + "=" + DevtronManagedByLabelValue
+
+// SecretRotationResult is the outcome of rotating the secret of one namespace, RestartedWorkloads are kind/name
+type SecretRotationResult struct {
+	Namespace          string   `json:"namespace"`
+	Status             string   `json:"status"`
+	Error              string   `json:"error,omitempty"`
+	RestartedWorkloads []string `json:"restartedWorkloads,omitempty"`
+}
+
+type SecretRotationOptions struct {
+	DryRun bool
+	// RestartWorkloads rollout restarts the Deployments, StatefulSets and DaemonSets using a rotated secret
+	RestartWorkloads bool
+	// RestartUnchanged also restarts them when the secret already held the new data, a resumed rotation sets it as
+	// the interrupted run may have updated the secret without getting to the restarts
+	RestartUnchanged bool
+	// Done holds the namespaces to leave alone, e.g. those completed by the run being resumed
+	Done map[string]bool
+	// OnResult is called once a namespace is processed
+	OnResult func(result *SecretRotationResult)
+}
+
+// RotateSecretEverywhere replaces the data of the secret in every namespace having a copy matching selector, the
+// selector defaults to DefaultSecretRotationSelector. A failing namespace does not stop the others, an error is only
+// returned when the copies can not be listed or ctx is done, along with the results so far
+func (impl K8sUtil) RotateSecretEverywhere(ctx context.Context, clusterConfig *ClusterConfig, secretName string, newData map[string][]byte, selector string, options *SecretRotationOptions) ([]*SecretRotationResult, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	if len(selector) == 0 {
+		selector = DefaultSecretRotationSelector
+	}
+	namespaces, err := impl.FindSecretNamespaces(ctx, clusterConfig, secretName, selector)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*SecretRotationResult, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		if err = ctx.Err(); err != nil {
+			return results, err
+		}
+		result := &SecretRotationResult{Namespace: namespace}
+		if options.Done[namespace] {
+			result.Status = SecretRotationSkipped
+		} else {
+			impl.rotateSecret(ctx, clusterConfig, namespace, secretName, newData, options, result)
+		}
+		if result.Status == SecretRotationFailed {
+			logger.Errorw("error in rotating secret", "namespace", namespace, "secretName", secretName, "err", result.Error)
+		}
+		results = append(results, result)
+		if options.OnResult != nil {
+			options.OnResult(result)
+		}
+	}
+	return results, nil
+}
+
+func (impl K8sUtil) rotateSecret(ctx context.Context, clusterConfig *ClusterConfig, namespace, secretName string, newData map[string][]byte, options *SecretRotationOptions, result *SecretRotationResult) {
+	if options.DryRun {
+		client, err := impl.GetClient(clusterConfig)
+		if err == nil {
+			var secret *v1.Secret
+			secret, err = client.Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+			if err == nil {
+				result.Status = SecretRotationPending
+				if secretDataEqual(secret.Data, newData) {
+					result.Status = SecretRotationUnchanged
+				}
+			}
+		}
+		if err != nil {
+			result.Status, result.Error = SecretRotationFailed, err.Error()
+		}
+		return
+	}
+	changed, err := impl.UpsertSecret(ctx, clusterConfig, namespace, &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName}, Data: newData})
+	if err != nil {
+		result.Status, result.Error = SecretRotationFailed, err.Error()
+		return
+	}
+	result.Status = SecretRotationUnchanged
+	if changed {
+		result.Status = SecretRotationRotated
+	}
+	if !options.RestartWorkloads || (!changed && !options.RestartUnchanged) {
+		return
+	}
+	restarted, err := impl.restartSecretWorkloads(ctx, clusterConfig, namespace, secretName)
+	result.RestartedWorkloads = restarted
+	if err != nil {
+		// the secret is rotated, the error tells which restarts are missing
+		result.Error = err.Error()
+	}
+}
+
+// restartSecretWorkloads rollout restarts the workloads using the secret, bare pods and jobs are not restarted
+func (impl K8sUtil) restartSecretWorkloads(ctx context.Context, clusterConfig *ClusterConfig, namespace, secretName string) ([]string, error) {
+	references, err := impl.FindConfigReferences(ctx, clusterConfig, namespace, SecretKind, secretName, true)
+	if err != nil {
+		return nil, err
+	}
+	var restarted, failed []string
+	for _, reference := range references {
+		switch reference.Kind {
+		case kube.DeploymentKind, kube.StatefulSetKind, kube.DaemonSetKind:
+		default:
+			continue
+		}
+		workload := reference.Kind + "/" + reference.Name
+		if _, err = impl.RestartWorkload(ctx, namespace, reference.Kind, reference.Name, "", clusterConfig); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", workload, err.Error()))
+			continue
+		}
+		restarted = append(restarted, workload)
+	}
+	if len(failed) > 0 {
+		return restarted, fmt.Errorf("error in restarting workloads %s", strings.Join(failed, "; "))
+	}
+	return restarted, nil
+}
+
+// FindSecretNamespaces returns the sorted namespaces having a secret of the name matching selector
+func (impl K8sUtil) FindSecretNamespaces(ctx context.Context, clusterConfig *ClusterConfig, secretName, selector string) ([]string, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	client, err := impl.GetClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("client err, FindSecretNamespaces", "err", err)
+		return nil, err
+	}
+	secrets, err := client.Secrets(v1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", secretName).String(),
+		LabelSelector: selector,
+	})
+	if err != nil {
+		logger.Errorw("error in listing secrets", "err", err, "secretName", secretName, "selector", selector)
+		return nil, err
+	}
+	namespaces := make([]string, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		namespaces = append(namespaces, secret.Namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// UpsertSecret creates the secret or replaces the data of the existing one keeping its type, labels and annotations.
+// Updates failing with a conflict are retried on the latest version, false is returned when the data was already set
+func (impl K8sUtil) UpsertSecret(ctx context.Context, clusterConfig *ClusterConfig, namespace string, secret *v1.Secret) (bool, error) {
+	defer impl.inflightMutations.Begin()()
+	if err := impl.CheckNamespaceAccess(ctx, clusterConfig, namespace, true); err != nil {
+		return false, err
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	client, err := impl.GetClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("client err, UpsertSecret", "err", err)
+		return false, err
+	}
+	secrets := client.Secrets(namespace)
+	for attempt := 1; ; attempt++ {
+		existing, err := secrets.Get(ctx, secret.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+			if err == nil {
+				return true, nil
+			}
+		} else if err == nil {
+			if secretDataEqual(existing.Data, secret.Data) {
+				return false, nil
+			}
+			existing.Data = secret.Data
+			existing.StringData = nil
+			_, err = secrets.Update(ctx, existing, metav1.UpdateOptions{})
+			if err == nil {
+				return true, nil
+			}
+		}
+		if !(errors.IsConflict(err) || errors.IsAlreadyExists(err)) || attempt == secretUpsertMaxAttempts {
+			logger.Errorw("error in upserting secret", "err", err, "namespace", namespace, "name", secret.Name, "attempt", attempt)
+			return false, err
+		}
+		logger.Warnw("retrying secret upsert after conflict", "namespace", namespace, "name", secret.Name, "attempt", attempt)
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(time.Duration(attempt) * secretUpsertBackoff):
+		}
+	}
+}
+
+func secretDataEqual(a, b map[string][]byte) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,22 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsStatefulSetPVC(t *testing.T) {
+	templates := []v1.PersistentVolumeClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "logs"}},
+	}
+	assert.True(t, isStatefulSetPVC("data-mysql-0", "mysql", templates))
+	assert.True(t, isStatefulSetPVC("logs-mysql-12", "mysql", templates))
+	assert.False(t, isStatefulSetPVC("data-mysql-", "mysql", templates))
+	assert.False(t, isStatefulSetPVC("data-mysql-backup-0", "mysql", templates))
+	assert.False(t, isStatefulSetPVC("data-mysql-1a", "mysql", templates))
+	assert.False(t, isStatefulSetPVC("cache-mysql-0", "mysql", templates))
+}
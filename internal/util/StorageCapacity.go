@@ -0,0 +1,160 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// defaultStorageClassAnnotation marks the class used for claims which do not name one
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// GetCSIStorageCapacity lists the storage capacity published by CSI drivers for each topology segment, usually a node,
+// across all namespaces when namespace is empty. Clusters older than 1.24 or without drivers publishing capacity
+// return an empty list
+func (impl K8sUtil) GetCSIStorageCapacity(ctx context.Context, namespace string, clusterConfig *ClusterConfig) ([]*storagev1.CSIStorageCapacity, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		logger.Errorw("clientSet err, GetCSIStorageCapacity", "err", err)
+		return nil, err
+	}
+	capacityList, err := clientSet.StorageV1().CSIStorageCapacities(namespace).List(ctx, metav1.ListOptions{})
+	if errors.IsNotFound(err) {
+		return []*storagev1.CSIStorageCapacity{}, nil
+	} else if err != nil {
+		logger.Errorw("error in listing csi storage capacity", "err", err, "namespace", namespace)
+		return nil, err
+	}
+	capacities := make([]*storagev1.CSIStorageCapacity, 0, len(capacityList.Items))
+	for i := range capacityList.Items {
+		capacities = append(capacities, &capacityList.Items[i])
+	}
+	return capacities, nil
+}
+
+// StorageCapacityWarning returns the warning to attach when a claim of the manifest, a PVC or a volumeClaimTemplate
+// of a StatefulSet, requests more storage than any topology segment of its storage class has available. Nothing is
+// returned when capacity is not published for the class, lookups are best effort
+func (impl K8sUtil) StorageCapacityWarning(ctx context.Context, manifest *unstructured.Unstructured, clusterConfig *ClusterConfig) string {
+	claims := manifestStorageClaims(manifest)
+	if len(claims) == 0 {
+		return ""
+	}
+	logger := LoggerFromContext(ctx, impl.logger)
+	capacities, err := impl.GetCSIStorageCapacity(ctx, metav1.NamespaceAll, clusterConfig)
+	if err != nil || len(capacities) == 0 {
+		return ""
+	}
+	defaultStorageClass := ""
+	for _, claim := range claims {
+		if claim.storageClassName == nil {
+			defaultStorageClass, err = impl.getDefaultStorageClassName(ctx, clusterConfig)
+			if err != nil {
+				logger.Debugw("unable to find default storage class", "err", err)
+			}
+			break
+		}
+	}
+	return storageCapacityWarning(claims, capacities, defaultStorageClass)
+}
+
+func (impl K8sUtil) getDefaultStorageClassName(ctx context.Context, clusterConfig *ClusterConfig) (string, error) {
+	clientSet, err := impl.GetClientSet(clusterConfig)
+	if err != nil {
+		return "", err
+	}
+	storageClasses, err := clientSet.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, storageClass := range storageClasses.Items {
+		if storageClass.Annotations[defaultStorageClassAnnotation] == "true" {
+			return storageClass.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// storageClaim is a claim of a manifest, storageClassName is nil when the default class is used
+type storageClaim struct {
+	name             string
+	storageClassName *string
+	request          resource.Quantity
+}
+
+func manifestStorageClaims(manifest *unstructured.Unstructured) []storageClaim {
+	gvk := manifest.GroupVersionKind()
+	var pvcs []v1.PersistentVolumeClaim
+	switch {
+	case gvk.Group == "" && gvk.Kind == "PersistentVolumeClaim":
+		pvc := v1.PersistentVolumeClaim{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(manifest.Object, &pvc); err != nil {
+			return nil
+		}
+		pvcs = append(pvcs, pvc)
+	case gvk.Group == AppsGroup && gvk.Kind == "StatefulSet":
+		templates, _, _ := unstructured.NestedSlice(manifest.Object, "spec", "volumeClaimTemplates")
+		for _, template := range templates {
+			templateObject, ok := template.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			pvc := v1.PersistentVolumeClaim{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(templateObject, &pvc); err == nil {
+				pvcs = append(pvcs, pvc)
+			}
+		}
+	}
+	claims := make([]storageClaim, 0, len(pvcs))
+	for _, pvc := range pvcs {
+		request, ok := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+		if !ok || request.IsZero() {
+			continue
+		}
+		claims = append(claims, storageClaim{name: pvc.Name, storageClassName: pvc.Spec.StorageClassName, request: request})
+	}
+	return claims
+}
+
+// storageCapacityWarning compares each claim with the largest volume any segment of its class can provision, the
+// maximum volume size when the driver reports one and the capacity otherwise
+func storageCapacityWarning(claims []storageClaim, capacities []*storagev1.CSIStorageCapacity, defaultStorageClass string) string {
+	largest := make(map[string]resource.Quantity)
+	for _, capacity := range capacities {
+		available := capacity.MaximumVolumeSize
+		if available == nil {
+			available = capacity.Capacity
+		}
+		if available == nil {
+			continue
+		}
+		if current, ok := largest[capacity.StorageClassName]; !ok || available.Cmp(current) > 0 {
+			largest[capacity.StorageClassName] = *available
+		}
+	}
+	var warnings []string
+	for _, claim := range claims {
+		storageClassName := defaultStorageClass
+		if claim.storageClassName != nil {
+			storageClassName = *claim.storageClassName
+		}
+		available, ok := largest[storageClassName]
+		if len(storageClassName) == 0 || !ok || claim.request.Cmp(available) <= 0 {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("pvc %s requests %s but no node has more than %s available in storage class %s",
+			claim.name, claim.request.String(), available.String(), storageClassName))
+	}
+	sort.Strings(warnings)
+	return strings.Join(warnings, "; ")
+}
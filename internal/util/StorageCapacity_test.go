@@ -0,0 +1,95 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func storageManifest(t *testing.T, content string) *unstructured.Unstructured {
+	manifest := &unstructured.Unstructured{}
+	assert.Nil(t, yaml.Unmarshal([]byte(content), &manifest.Object))
+	return manifest
+}
+
+func storageCapacity(storageClassName string, capacity string, maximumVolumeSize string) *storagev1.CSIStorageCapacity {
+	storageCapacity := &storagev1.CSIStorageCapacity{StorageClassName: storageClassName}
+	if len(capacity) > 0 {
+		quantity := resource.MustParse(capacity)
+		storageCapacity.Capacity = &quantity
+	}
+	if len(maximumVolumeSize) > 0 {
+		quantity := resource.MustParse(maximumVolumeSize)
+		storageCapacity.MaximumVolumeSize = &quantity
+	}
+	return storageCapacity
+}
+
+func TestManifestStorageClaims(t *testing.T) {
+	pvc := storageManifest(t, `
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+spec:
+  storageClassName: local-ssd
+  resources:
+    requests:
+      storage: 20Gi
+`)
+	claims := manifestStorageClaims(pvc)
+	assert.Len(t, claims, 1)
+	assert.Equal(t, "local-ssd", *claims[0].storageClassName)
+	assert.Equal(t, "20Gi", claims[0].request.String())
+
+	statefulSet := storageManifest(t, `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+    spec:
+      resources:
+        requests:
+          storage: 5Gi
+  - metadata:
+      name: scratch
+    spec:
+      storageClassName: local-ssd
+`)
+	claims = manifestStorageClaims(statefulSet)
+	assert.Len(t, claims, 1)
+	assert.Equal(t, "data", claims[0].name)
+	assert.Nil(t, claims[0].storageClassName)
+
+	assert.Empty(t, manifestStorageClaims(storageManifest(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n")))
+}
+
+func TestStorageCapacityWarning(t *testing.T) {
+	localSsd := "local-ssd"
+	capacities := []*storagev1.CSIStorageCapacity{
+		storageCapacity(localSsd, "8Gi", ""),
+		storageCapacity(localSsd, "100Gi", "10Gi"),
+		storageCapacity("standard", "50Gi", ""),
+	}
+	claim := func(name string, storageClassName *string, request string) storageClaim {
+		return storageClaim{name: name, storageClassName: storageClassName, request: resource.MustParse(request)}
+	}
+	// the maximum volume size bounds a segment over its capacity
+	assert.Empty(t, storageCapacityWarning([]storageClaim{claim("data", &localSsd, "10Gi")}, capacities, ""))
+	assert.Equal(t, "pvc data requests 12Gi but no node has more than 10Gi available in storage class local-ssd",
+		storageCapacityWarning([]storageClaim{claim("data", &localSsd, "12Gi")}, capacities, ""))
+	// claims without a class use the default one
+	assert.NotEmpty(t, storageCapacityWarning([]storageClaim{claim("data", nil, "60Gi")}, capacities, "standard"))
+	assert.Empty(t, storageCapacityWarning([]storageClaim{claim("data", nil, "60Gi")}, capacities, ""))
+	// classes without published capacity are not warned about
+	unknown := "nfs"
+	assert.Empty(t, storageCapacityWarning([]storageClaim{claim("data", &unknown, "1Ti")}, capacities, ""))
+}
@@ -0,0 +1,94 @@
+package util
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// evaluateTopologySpread counts the scheduled pods matching each constraint per domain like the scheduler does, the
+// domains are the values of the topology key on the nodes matching the node selector of the pod template. Node
+// affinity and taints are not considered, so a domain the pods can not be scheduled to may be reported as empty
+func evaluateTopologySpread(deploymentName string, template *v1.PodSpec, pods []v1.Pod, nodes []v1.Node) ([]*TopologySpreadConstraintStatus, []string) {
+	nodeSelector := labels.SelectorFromSet(template.NodeSelector)
+	nodeDomains := make(map[string]map[string]string, len(nodes))
+	for _, node := range nodes {
+		if nodeSelector.Matches(labels.Set(node.Labels)) {
+			nodeDomains[node.Name] = node.Labels
+		}
+	}
+	statuses := make([]*TopologySpreadConstraintStatus, 0, len(template.TopologySpreadConstraints))
+	var warnings []string
+	for _, constraint := range template.TopologySpreadConstraints {
+		status := &TopologySpreadConstraintStatus{
+			TopologyKey:       constraint.TopologyKey,
+			MaxSkew:           constraint.MaxSkew,
+			WhenUnsatisfiable: string(constraint.WhenUnsatisfiable),
+			PodsPerDomain:     make(map[string]int),
+		}
+		for _, nodeLabels := range nodeDomains {
+			if domain, ok := nodeLabels[constraint.TopologyKey]; ok {
+				status.PodsPerDomain[domain] = 0
+			}
+		}
+		selector, err := metav1.LabelSelectorAsSelector(constraint.LabelSelector)
+		if err != nil || constraint.LabelSelector == nil {
+			// the scheduler matches no pod with an empty or invalid selector
+			selector = labels.Nothing()
+		}
+		for _, pod := range pods {
+			if !isSpreadCountedPod(&pod) || !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			nodeLabels, ok := nodeDomains[pod.Spec.NodeName]
+			if !ok {
+				continue
+			}
+			if domain, ok := nodeLabels[constraint.TopologyKey]; ok {
+				status.PodsPerDomain[domain]++
+			}
+		}
+		status.Skew = topologySkew(status.PodsPerDomain, constraint.MinDomains)
+		status.Satisfied = status.Skew <= constraint.MaxSkew
+		if !status.Satisfied {
+			warning := fmt.Sprintf("pods of deployment %s are spread across %s with a skew of %d, more than the max skew of %d", deploymentName, constraint.TopologyKey, status.Skew, constraint.MaxSkew)
+			if constraint.WhenUnsatisfiable == v1.DoNotSchedule {
+				warning += ", new pods may stay pending"
+			}
+			warnings = append(warnings, warning)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, warnings
+}
+
+// topologySkew is the difference between the most and least populated domains, the least is 0 while there are fewer
+// domains than minDomains
+func topologySkew(podsPerDomain map[string]int, minDomains *int32) int32 {
+	if len(podsPerDomain) == 0 {
+		return 0
+	}
+	first := true
+	var max, min int
+	for _, count := range podsPerDomain {
+		if first || count > max {
+			max = count
+		}
+		if first || count < min {
+			min = count
+		}
+		first = false
+	}
+	if minDomains != nil && int32(len(podsPerDomain)) < *minDomains {
+		min = 0
+	}
+	return int32(max - min)
+}
+
+// isSpreadCountedPod leaves out pods which are not scheduled, finished or terminating
+func isSpreadCountedPod(pod *v1.Pod) bool {
+	return len(pod.Spec.NodeName) > 0 && pod.DeletionTimestamp == nil &&
+		pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed
+}
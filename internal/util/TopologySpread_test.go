@@ -0,0 +1,63 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func topologyNode(name, zone string) v1.Node {
+	return v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"topology.kubernetes.io/zone": zone}}}
+}
+
+func topologyPod(nodeName string, labels map[string]string, phase v1.PodPhase) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec:       v1.PodSpec{NodeName: nodeName},
+		Status:     v1.PodStatus{Phase: phase},
+	}
+}
+
+func TestEvaluateTopologySpread(t *testing.T) {
+	appLabels := map[string]string{"app": "payments"}
+	template := &v1.PodSpec{TopologySpreadConstraints: []v1.TopologySpreadConstraint{{
+		MaxSkew:           1,
+		TopologyKey:       "topology.kubernetes.io/zone",
+		WhenUnsatisfiable: v1.DoNotSchedule,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: appLabels},
+	}}}
+	nodes := []v1.Node{topologyNode("node-a", "zone-a"), topologyNode("node-b", "zone-b"), topologyNode("node-c", "zone-c")}
+	pods := []v1.Pod{
+		topologyPod("node-a", appLabels, v1.PodRunning),
+		topologyPod("node-a", appLabels, v1.PodRunning),
+		topologyPod("node-b", appLabels, v1.PodRunning),
+		topologyPod("node-c", appLabels, v1.PodSucceeded),
+		topologyPod("node-c", map[string]string{"app": "orders"}, v1.PodRunning),
+		topologyPod("", appLabels, v1.PodPending),
+	}
+	statuses, warnings := evaluateTopologySpread("payments", template, pods, nodes)
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, map[string]int{"zone-a": 2, "zone-b": 1, "zone-c": 0}, statuses[0].PodsPerDomain)
+	assert.Equal(t, int32(2), statuses[0].Skew)
+	assert.False(t, statuses[0].Satisfied)
+	assert.Equal(t, []string{"pods of deployment payments are spread across topology.kubernetes.io/zone with a skew of 2, more than the max skew of 1, new pods may stay pending"}, warnings)
+
+	// node-c is not eligible for the pods of the template
+	template.NodeSelector = map[string]string{"pool": "general"}
+	for i := range nodes[:2] {
+		nodes[i].Labels["pool"] = "general"
+	}
+	statuses, warnings = evaluateTopologySpread("payments", template, pods, nodes)
+	assert.Equal(t, map[string]int{"zone-a": 2, "zone-b": 1}, statuses[0].PodsPerDomain)
+	assert.True(t, statuses[0].Satisfied)
+	assert.Empty(t, warnings)
+}
+
+func TestTopologySkew(t *testing.T) {
+	minDomains := int32(3)
+	assert.Equal(t, int32(0), topologySkew(map[string]int{}, nil))
+	assert.Equal(t, int32(1), topologySkew(map[string]int{"a": 2, "b": 1}, nil))
+	assert.Equal(t, int32(2), topologySkew(map[string]int{"a": 2, "b": 1}, &minDomains))
+}
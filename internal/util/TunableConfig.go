@@ -0,0 +1,290 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	v12 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	TunableSourceEnv       = "env"
+	TunableSourceConfigMap = "configMap"
+)
+
+// TunableConfig holds the values which can be changed without a restart through the tunables ConfigMap, the env is
+// the default and the keys of the ConfigMap are the env names. Changes apply to new terminal sessions and new clients
+type TunableConfig struct {
+	MaxSessionPerUser                 int     `env:"MAX_SESSION_PER_USER" envDefault:"5"`
+	TerminalPodInActiveDurationInMins int     `env:"TERMINAL_POD_INACTIVE_DURATION_IN_MINS" envDefault:"10"`
+	TerminalBaseImageAllowList        string  `env:"TERMINAL_BASE_IMAGE_ALLOW_LIST" envDefault:""`
+	K8sClientQPS                      float32 `env:"K8S_CLIENT_QPS" envDefault:"5"`
+	K8sClientBurst                    int     `env:"K8S_CLIENT_BURST" envDefault:"10"`
+}
+
+// TunableConfigMapConfig names the ConfigMap overriding the tunables, it is read with the in-cluster client and not
+// watched when the name is empty
+type TunableConfigMapConfig struct {
+	Name      string `env:"TUNABLES_CONFIG_MAP_NAME" envDefault:""`
+	Namespace string `env:"TUNABLES_CONFIG_MAP_NAMESPACE" envDefault:"devtroncd"`
+}
+
+func GetTunableConfigMapConfig() (*TunableConfigMapConfig, error) {
+	config := &TunableConfigMapConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+// tunable parses and validates the ConfigMap value of a key into the config, value formats it back
+type tunable struct {
+	key   string
+	set   func(config *TunableConfig, value string) error
+	value func(config *TunableConfig) string
+}
+
+var tunables = []tunable{
+	{
+		key: "MAX_SESSION_PER_USER",
+		set: func(config *TunableConfig, value string) (err error) {
+			config.MaxSessionPerUser, err = parsePositiveInt(value)
+			return err
+		},
+		value: func(config *TunableConfig) string { return strconv.Itoa(config.MaxSessionPerUser) },
+	},
+	{
+		key: "TERMINAL_POD_INACTIVE_DURATION_IN_MINS",
+		set: func(config *TunableConfig, value string) (err error) {
+			config.TerminalPodInActiveDurationInMins, err = parsePositiveInt(value)
+			return err
+		},
+		value: func(config *TunableConfig) string { return strconv.Itoa(config.TerminalPodInActiveDurationInMins) },
+	},
+	{
+		key: "TERMINAL_BASE_IMAGE_ALLOW_LIST",
+		set: func(config *TunableConfig, value string) error {
+			config.TerminalBaseImageAllowList = strings.TrimSpace(value)
+			return nil
+		},
+		value: func(config *TunableConfig) string { return config.TerminalBaseImageAllowList },
+	},
+	{
+		key: "K8S_CLIENT_QPS",
+		set: func(config *TunableConfig, value string) error {
+			qps, err := strconv.ParseFloat(strings.TrimSpace(value), 32)
+			if err != nil || qps <= 0 {
+				return fmt.Errorf("%q is not a positive number", value)
+			}
+			config.K8sClientQPS = float32(qps)
+			return nil
+		},
+		value: func(config *TunableConfig) string {
+			return strconv.FormatFloat(float64(config.K8sClientQPS), 'f', -1, 32)
+		},
+	},
+	{
+		key: "K8S_CLIENT_BURST",
+		set: func(config *TunableConfig, value string) (err error) {
+			config.K8sClientBurst, err = parsePositiveInt(value)
+			return err
+		},
+		value: func(config *TunableConfig) string { return strconv.Itoa(config.K8sClientBurst) },
+	},
+}
+
+func parsePositiveInt(value string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("%q is not a positive integer", value)
+	}
+	return number, nil
+}
+
+// EffectiveTunable is the value in use for a key and whether it comes from the env or the ConfigMap
+type EffectiveTunable struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// EffectiveTunables lists the values in use, LastError is the reason the latest ConfigMap change was rejected
+type EffectiveTunables struct {
+	ConfigMap string             `json:"configMap,omitempty"`
+	Tunables  []EffectiveTunable `json:"tunables"`
+	UpdatedOn *time.Time         `json:"updatedOn,omitempty"`
+	LastError string             `json:"lastError,omitempty"`
+}
+
+type tunableSnapshot struct {
+	config    TunableConfig
+	overrides map[string]string
+	updatedOn *time.Time
+	lastErr   string
+}
+
+// TunableConfigStore swaps the tunables atomically, a ConfigMap change with a bad value is rejected as a whole and the
+// last good values are kept
+type TunableConfigStore struct {
+	defaults  TunableConfig
+	configMap string
+	current   atomic.Value
+	mutex     sync.Mutex
+}
+
+func NewTunableConfigStore() (*TunableConfigStore, error) {
+	store := &TunableConfigStore{}
+	err := env.Parse(&store.defaults)
+	store.current.Store(&tunableSnapshot{config: store.defaults})
+	return store, err
+}
+
+// Get returns the tunables in use, the zero config when there is no store
+func (s *TunableConfigStore) Get() TunableConfig {
+	if s == nil {
+		return TunableConfig{}
+	}
+	return s.snapshot().config
+}
+
+func (s *TunableConfigStore) snapshot() *tunableSnapshot {
+	return s.current.Load().(*tunableSnapshot)
+}
+
+// Apply overrides the env defaults with the ConfigMap data, nil data restores the defaults
+func (s *TunableConfigStore) Apply(data map[string]string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	config := s.defaults
+	overrides := make(map[string]string)
+	var errs []string
+	for key, value := range data {
+		t, ok := findTunable(key)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s is not a tunable", key))
+			continue
+		}
+		if err := t.set(&config, value); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", key, err.Error()))
+			continue
+		}
+		overrides[key] = value
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		err := fmt.Errorf("tunables rejected, keeping the last good values: %s", strings.Join(errs, "; "))
+		rejected := *s.snapshot()
+		rejected.lastErr = err.Error()
+		s.current.Store(&rejected)
+		return err
+	}
+	s.current.Store(&tunableSnapshot{config: config, overrides: overrides, updatedOn: &now})
+	return nil
+}
+
+// Effective lists every tunable with its value in use and where it comes from
+func (s *TunableConfigStore) Effective() *EffectiveTunables {
+	snapshot := s.snapshot()
+	effective := &EffectiveTunables{ConfigMap: s.configMap, UpdatedOn: snapshot.updatedOn, LastError: snapshot.lastErr}
+	for _, t := range tunables {
+		source := TunableSourceEnv
+		if _, ok := snapshot.overrides[t.key]; ok {
+			source = TunableSourceConfigMap
+		}
+		effective.Tunables = append(effective.Tunables, EffectiveTunable{Key: t.key, Value: t.value(&snapshot.config), Source: source})
+	}
+	return effective
+}
+
+func findTunable(key string) (tunable, bool) {
+	for _, t := range tunables {
+		if t.key == key {
+			return t, true
+		}
+	}
+	return tunable{}, false
+}
+
+// Watch applies the data of the ConfigMap on every change till stopCh is closed, a deleted ConfigMap restores the env
+// defaults
+func (s *TunableConfigStore) Watch(client v12.ConfigMapsGetter, namespace, name string, logger *zap.SugaredLogger, stopCh <-chan struct{}) cache.Controller {
+	s.configMap = namespace + "/" + name
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return client.ConfigMaps(namespace).List(context.Background(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return client.ConfigMaps(namespace).Watch(context.Background(), options)
+		},
+	}
+	apply := func(obj interface{}) {
+		configMap, ok := obj.(*v1.ConfigMap)
+		if !ok {
+			return
+		}
+		if err := s.Apply(configMap.Data); err != nil {
+			logger.Errorw("error in applying tunables config map", "configMap", s.configMap, "err", err)
+			return
+		}
+		logger.Infow("applied tunables config map", "configMap", s.configMap, "resourceVersion", configMap.ResourceVersion)
+	}
+	_, controller := cache.NewInformer(listWatch, &v1.ConfigMap{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: apply,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			apply(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			_ = s.Apply(nil)
+			logger.Infow("tunables config map deleted, using env values", "configMap", s.configMap)
+		},
+	})
+	go controller.Run(stopCh)
+	return controller
+}
+
+// applyClientTunables sets the rate limits of clients built by K8sUtil, 0 keeps the client-go defaults
+func (impl K8sUtil) applyClientTunables(cfg *rest.Config) {
+	config := impl.tunables.Get()
+	cfg.QPS = config.K8sClientQPS
+	cfg.Burst = config.K8sClientBurst
+}
+
+// Tunables returns the tunables in use, see TunableConfig
+func (impl K8sUtil) Tunables() TunableConfig {
+	return impl.tunables.Get()
+}
+
+func (impl K8sUtil) GetEffectiveTunables() *EffectiveTunables {
+	return impl.tunables.Effective()
+}
+
+// watchTunableConfigMap starts watching the tunables ConfigMap with the in-cluster client when one is configured
+func (impl K8sUtil) watchTunableConfigMap() error {
+	config, err := GetTunableConfigMapConfig()
+	if err != nil || len(config.Name) == 0 {
+		return err
+	}
+	client, err := impl.GetClientForInCluster()
+	if err != nil {
+		return err
+	}
+	impl.tunables.Watch(client, config.Namespace, config.Name, impl.logger, make(chan struct{}))
+	return nil
+}
@@ -0,0 +1,94 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestTunableConfigStore_Apply(t *testing.T) {
+	store, err := NewTunableConfigStore()
+	assert.Nil(t, err)
+	assert.Equal(t, 5, store.Get().MaxSessionPerUser)
+	assert.Equal(t, float32(5), store.Get().K8sClientQPS)
+
+	assert.Nil(t, store.Apply(map[string]string{"MAX_SESSION_PER_USER": "8", "K8S_CLIENT_QPS": "25.5"}))
+	assert.Equal(t, 8, store.Get().MaxSessionPerUser)
+	assert.Equal(t, float32(25.5), store.Get().K8sClientQPS)
+
+	// a bad value rejects the whole change
+	err = store.Apply(map[string]string{"MAX_SESSION_PER_USER": "2", "K8S_CLIENT_BURST": "-1", "MAX_SESSIONS": "3"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "K8S_CLIENT_BURST")
+	assert.Contains(t, err.Error(), "MAX_SESSIONS is not a tunable")
+	assert.Equal(t, 8, store.Get().MaxSessionPerUser)
+
+	effective := store.Effective()
+	assert.Equal(t, err.Error(), effective.LastError)
+	sources := make(map[string]EffectiveTunable)
+	for _, tunable := range effective.Tunables {
+		sources[tunable.Key] = tunable
+	}
+	assert.Equal(t, EffectiveTunable{Key: "MAX_SESSION_PER_USER", Value: "8", Source: TunableSourceConfigMap}, sources["MAX_SESSION_PER_USER"])
+	assert.Equal(t, EffectiveTunable{Key: "K8S_CLIENT_BURST", Value: "10", Source: TunableSourceEnv}, sources["K8S_CLIENT_BURST"])
+
+	assert.Nil(t, store.Apply(nil))
+	assert.Equal(t, 5, store.Get().MaxSessionPerUser)
+	assert.Empty(t, store.Effective().LastError)
+}
+
+func TestTunableConfigStore_Watch(t *testing.T) {
+	store, err := NewTunableConfigStore()
+	assert.Nil(t, err)
+	client := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "devtron-tunables", Namespace: "devtroncd"},
+		Data:       map[string]string{"TERMINAL_POD_INACTIVE_DURATION_IN_MINS": "30"},
+	})
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	k8sUtil := &K8sUtil{logger: zap.NewNop().Sugar(), tunables: store}
+	store.Watch(client.CoreV1(), "devtroncd", "devtron-tunables", k8sUtil.logger, stopCh)
+	assert.Eventually(t, func() bool {
+		return k8sUtil.Tunables().TerminalPodInActiveDurationInMins == 30
+	}, 5*time.Second, 10*time.Millisecond)
+
+	configMaps := client.CoreV1().ConfigMaps("devtroncd")
+	_, err = configMaps.Update(context.Background(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "devtron-tunables", Namespace: "devtroncd"},
+		Data:       map[string]string{"K8S_CLIENT_QPS": "50", "K8S_CLIENT_BURST": "100"},
+	}, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		return k8sUtil.Tunables().K8sClientBurst == 100
+	}, 5*time.Second, 10*time.Millisecond)
+	// values dropped from the config map fall back to the env
+	assert.Equal(t, 10, k8sUtil.Tunables().TerminalPodInActiveDurationInMins)
+	cfg := &rest.Config{}
+	k8sUtil.applyClientTunables(cfg)
+	assert.Equal(t, float32(50), cfg.QPS)
+	assert.Equal(t, 100, cfg.Burst)
+	assert.Equal(t, "devtroncd/devtron-tunables", k8sUtil.GetEffectiveTunables().ConfigMap)
+
+	// a bad update keeps the last good values
+	_, err = configMaps.Update(context.Background(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "devtron-tunables", Namespace: "devtroncd"},
+		Data:       map[string]string{"K8S_CLIENT_BURST": "many"},
+	}, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		return len(k8sUtil.GetEffectiveTunables().LastError) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 100, k8sUtil.Tunables().K8sClientBurst)
+
+	assert.Nil(t, configMaps.Delete(context.Background(), "devtron-tunables", metav1.DeleteOptions{}))
+	assert.Eventually(t, func() bool {
+		return k8sUtil.Tunables().K8sClientBurst == 10
+	}, 5*time.Second, 10*time.Millisecond)
+}
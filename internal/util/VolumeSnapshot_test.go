@@ -0,0 +1,46 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseVolumeSnapshotInfo(t *testing.T) {
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "snapshot.storage.k8s.io/v1",
+		"kind":       "VolumeSnapshot",
+		"metadata":   map[string]interface{}{"name": "data-backup", "namespace": "payments"},
+		"spec": map[string]interface{}{
+			"volumeSnapshotClassName": "csi-snapclass",
+			"source":                  map[string]interface{}{"persistentVolumeClaimName": "data-postgres-0"},
+		},
+		"status": map[string]interface{}{
+			"creationTime": "2023-05-04T10:15:00Z",
+			"readyToUse":   true,
+			"restoreSize":  "10Gi",
+		},
+	}}
+	creationTime := time.Date(2023, 5, 4, 10, 15, 0, 0, time.UTC)
+	assert.Equal(t, &VolumeSnapshotInfo{
+		Name:              "data-backup",
+		Namespace:         "payments",
+		SourcePvcName:     "data-postgres-0",
+		SnapshotClassName: "csi-snapclass",
+		CreationTime:      &creationTime,
+		ReadyToUse:        true,
+		RestoreSize:       "10Gi",
+	}, ParseVolumeSnapshotInfo(snapshot))
+
+	// a snapshot the storage system has not taken yet
+	pending := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "data-backup-2", "namespace": "payments"},
+		"status":   map[string]interface{}{"readyToUse": false, "error": map[string]interface{}{"message": "quota exceeded"}},
+	}}
+	info := ParseVolumeSnapshotInfo(pending)
+	assert.Nil(t, info.CreationTime)
+	assert.False(t, info.ReadyToUse)
+	assert.Equal(t, "quota exceeded", info.Error)
+}
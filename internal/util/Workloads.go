@@ -0,0 +1,109 @@
+package util
+
+import (
+	"context"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+	appsV1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// workloadGVRs are the kinds whose replicas make up the availability of a namespace, rollouts are skipped on clusters
+// without the argo rollouts crd
+var workloadGVRs = []schema.GroupVersionResource{
+	appsV1.SchemeGroupVersion.WithResource("deployments"),
+	appsV1.SchemeGroupVersion.WithResource("statefulsets"),
+	appsV1.SchemeGroupVersion.WithResource("daemonsets"),
+	RolloutGVR,
+}
+
+// WorkloadReplicas is the replica counts and health of a Deployment, StatefulSet, DaemonSet or Rollout. Hibernated is
+// set when no replica is desired, HealthStatus is the gitops-engine health status
+type WorkloadReplicas struct {
+	Kind            string `json:"kind"`
+	Name            string `json:"name"`
+	DesiredReplicas int64  `json:"desiredReplicas"`
+	ReadyReplicas   int64  `json:"readyReplicas"`
+	UpdatedReplicas int64  `json:"updatedReplicas"`
+	Paused          bool   `json:"paused"`
+	Hibernated      bool   `json:"hibernated"`
+	HealthStatus    string `json:"healthStatus"`
+	Message         string `json:"message,omitempty"`
+}
+
+// ListWorkloads lists the Deployments, StatefulSets, DaemonSets and Rollouts of the namespace
+func (impl K8sUtil) ListWorkloads(ctx context.Context, namespace string, clusterConfig *ClusterConfig) ([]*unstructured.Unstructured, error) {
+	logger := LoggerFromContext(ctx, impl.logger)
+	dynamicClient, err := impl.GetDynamicClient(clusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting dynamic client", "err", err)
+		return nil, err
+	}
+	var workloads []*unstructured.Unstructured
+	for _, gvr := range workloadGVRs {
+		workloadList, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if errors.IsNotFound(err) && gvr == RolloutGVR {
+			continue
+		} else if err != nil {
+			logger.Errorw("error in listing workloads", "err", err, "namespace", namespace, "resource", gvr.Resource)
+			return nil, err
+		}
+		for i := range workloadList.Items {
+			workloads = append(workloads, &workloadList.Items[i])
+		}
+	}
+	return workloads, nil
+}
+
+// ParseWorkloadReplicas reads the replica counts of a workload, nil for other kinds
+func ParseWorkloadReplicas(workload *unstructured.Unstructured) *WorkloadReplicas {
+	replicas := &WorkloadReplicas{Kind: workload.GetKind(), Name: workload.GetName()}
+	switch workload.GroupVersionKind().GroupKind() {
+	case schema.GroupKind{Group: AppsGroup, Kind: "Deployment"}, schema.GroupKind{Group: AppsGroup, Kind: "StatefulSet"},
+		schema.GroupKind{Group: K8sClusterResourceRolloutGroup, Kind: K8sClusterResourceRolloutKind}:
+		replicas.DesiredReplicas = 1
+		if desired, found, _ := unstructured.NestedInt64(workload.Object, "spec", "replicas"); found {
+			replicas.DesiredReplicas = desired
+		}
+		replicas.ReadyReplicas, _, _ = unstructured.NestedInt64(workload.Object, "status", "readyReplicas")
+		replicas.UpdatedReplicas, _, _ = unstructured.NestedInt64(workload.Object, "status", "updatedReplicas")
+		replicas.Paused, _, _ = unstructured.NestedBool(workload.Object, "spec", "paused")
+	case schema.GroupKind{Group: AppsGroup, Kind: "DaemonSet"}:
+		replicas.DesiredReplicas, _, _ = unstructured.NestedInt64(workload.Object, "status", "desiredNumberScheduled")
+		replicas.ReadyReplicas, _, _ = unstructured.NestedInt64(workload.Object, "status", "numberReady")
+		replicas.UpdatedReplicas, _, _ = unstructured.NestedInt64(workload.Object, "status", "updatedNumberScheduled")
+	default:
+		return nil
+	}
+	replicas.Hibernated = replicas.DesiredReplicas == 0
+	healthStatus := workloadHealth(workload)
+	replicas.HealthStatus, replicas.Message = string(healthStatus.Status), healthStatus.Message
+	return replicas
+}
+
+// workloadHealth uses the gitops-engine health checks, which do not cover rollouts. The phase reported by the rollout
+// controller is used for those
+func workloadHealth(workload *unstructured.Unstructured) *health.HealthStatus {
+	if workload.GroupVersionKind().Group == K8sClusterResourceRolloutGroup {
+		phase, _, _ := unstructured.NestedString(workload.Object, "status", "phase")
+		message, _, _ := unstructured.NestedString(workload.Object, "status", "message")
+		switch health.HealthStatusCode(phase) {
+		case health.HealthStatusHealthy, health.HealthStatusProgressing, health.HealthStatusDegraded:
+			return &health.HealthStatus{Status: health.HealthStatusCode(phase), Message: message}
+		case "Paused":
+			return &health.HealthStatus{Status: health.HealthStatusSuspended, Message: message}
+		}
+		return &health.HealthStatus{Status: health.HealthStatusUnknown, Message: message}
+	}
+	healthStatus, err := health.GetResourceHealth(workload, nil)
+	if err != nil || healthStatus == nil {
+		healthStatus = &health.HealthStatus{Status: health.HealthStatusUnknown}
+		if err != nil {
+			healthStatus.Message = err.Error()
+		}
+	}
+	return healthStatus
+}
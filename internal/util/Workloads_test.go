@@ -0,0 +1,94 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func workload(t *testing.T, content string) *unstructured.Unstructured {
+	// decoded like the dynamic client does, numbers are int64
+	jsonContent, err := yaml.YAMLToJSON([]byte(content))
+	assert.Nil(t, err)
+	object := &unstructured.Unstructured{}
+	assert.Nil(t, object.UnmarshalJSON(jsonContent))
+	return object
+}
+
+func TestParseWorkloadReplicas(t *testing.T) {
+	deployment := ParseWorkloadReplicas(workload(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: payments
+  generation: 2
+spec:
+  replicas: 3
+status:
+  observedGeneration: 2
+  replicas: 3
+  readyReplicas: 3
+  updatedReplicas: 3
+  availableReplicas: 3
+`))
+	assert.Equal(t, WorkloadReplicas{Kind: "Deployment", Name: "payments", DesiredReplicas: 3, ReadyReplicas: 3, UpdatedReplicas: 3, HealthStatus: "Healthy"}, *deployment)
+
+	paused := ParseWorkloadReplicas(workload(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ledger
+spec:
+  replicas: 2
+  paused: true
+status:
+  readyReplicas: 1
+`))
+	assert.True(t, paused.Paused)
+	assert.Equal(t, "Suspended", paused.HealthStatus)
+
+	hibernated := ParseWorkloadReplicas(workload(t, `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: cache
+spec:
+  replicas: 0
+`))
+	assert.True(t, hibernated.Hibernated)
+	assert.Equal(t, int64(0), hibernated.DesiredReplicas)
+
+	daemonSet := ParseWorkloadReplicas(workload(t, `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: log-agent
+status:
+  desiredNumberScheduled: 4
+  numberReady: 3
+  updatedNumberScheduled: 4
+`))
+	assert.Equal(t, int64(4), daemonSet.DesiredReplicas)
+	assert.Equal(t, int64(3), daemonSet.ReadyReplicas)
+
+	rollout := ParseWorkloadReplicas(workload(t, `
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: checkout
+spec:
+  replicas: 5
+status:
+  phase: Degraded
+  message: ProgressDeadlineExceeded
+  readyReplicas: 2
+  updatedReplicas: 1
+`))
+	assert.Equal(t, "Degraded", rollout.HealthStatus)
+	assert.Equal(t, "ProgressDeadlineExceeded", rollout.Message)
+	assert.Equal(t, int64(5), rollout.DesiredReplicas)
+
+	assert.Nil(t, ParseWorkloadReplicas(workload(t, "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n")))
+}
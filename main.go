@@ -21,6 +21,9 @@ import (
 	"fmt"
 	_ "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	_ "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/sql/columnEncryption"
 	"log"
 	"os"
 	"os/signal"
@@ -28,13 +31,17 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == columnEncryption.CommandName {
+		encryptColumns()
+		return
+	}
 
 	app, err := InitializeApp()
 	if err != nil {
 		log.Panic(err)
 	}
 	//     gracefulStop start
-	var gracefulStop = make(chan os.Signal)
+	var gracefulStop = make(chan os.Signal, 1)
 	signal.Notify(gracefulStop, syscall.SIGTERM)
 	signal.Notify(gracefulStop, syscall.SIGINT)
 	go func() {
@@ -48,3 +55,25 @@ func main() {
 	app.Start()
 
 }
+
+// encryptColumns is run as `devtron encrypt-columns` after rolling out column encryption or a new active key
+func encryptColumns() {
+	logger, err := util.NewSugardLogger()
+	if err != nil {
+		log.Panic(err)
+	}
+	cfg, err := sql.GetConfig()
+	if err != nil {
+		log.Panic(err)
+	}
+	dbConnection, err := sql.NewDbConnection(cfg, logger)
+	if err != nil {
+		log.Panic(err)
+	}
+	defer dbConnection.Close()
+	summary, err := columnEncryption.EncryptExistingRows(dbConnection, logger)
+	if err != nil {
+		log.Panic(err)
+	}
+	logger.Infow("encrypted sensitive columns", "clusters", summary.Clusters, "dockerRegistries", summary.DockerRegistries)
+}
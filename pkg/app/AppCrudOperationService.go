@@ -18,16 +18,21 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/devtron-labs/devtron/internal/sql/repository/app"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
 	repository2 "github.com/devtron-labs/devtron/pkg/appStore/deployment/repository"
 	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/devtron-labs/devtron/pkg/user/repository"
 	util2 "github.com/devtron-labs/devtron/util"
+	"github.com/devtron-labs/devtron/util/pagination"
 	"github.com/go-pg/pg"
 	"go.uber.org/zap"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -37,32 +42,88 @@ type AppCrudOperationService interface {
 	Create(request *bean.AppLabelDto, tx *pg.Tx) (*bean.AppLabelDto, error)
 	FindById(id int) (*bean.AppLabelDto, error)
 	FindAll() ([]*bean.AppLabelDto, error)
+	FindAllByListingRequest(request *pagination.ListingRequest) ([]*bean.AppLabelDto, error)
+	FindByProjectId(projectId int) ([]*bean.AppLabelDto, error)
+	FindByEnvironmentId(environmentId int) ([]*bean.AppLabelDto, error)
+	// ValidateNewAppLabels checks the labels of an app being created against the label limits
+	ValidateNewAppLabels(labels []*bean.Label) (string, error)
+	// FindAppsAboveLabelSoftLimit lists the apps having more labels than APP_LABEL_SOFT_LIMIT
+	FindAppsAboveLabelSoftLimit() ([]*bean.AppLabelCountDto, error)
+	// FindLabelFeed serves the labels of all apps in pages ordered by update time, deletions are reported in the
+	// pages after the start of the walk or the UpdatedAfter of an incremental sync
+	FindLabelFeed(request *bean.AppLabelFeedRequest) (*bean.AppLabelFeedResponse, error)
+	CreateLabelTemplate(request *bean.LabelTemplateDto) (*bean.LabelTemplateDto, error)
+	// ApplyTemplate bulk-creates the labels of a template on the app, overrides are keyed by label key
+	ApplyTemplate(appId int, templateId int, overrides map[string]string) error
+	// ApplyTemplateForUser is ApplyTemplate recording userId as the author of the labels instead of the system user
+	ApplyTemplateForUser(appId int, templateId int, overrides map[string]string, userId int32) error
+	// CreateLabelWebhook registers a url which is posted every label change of the selected event types
+	CreateLabelWebhook(request *bean.LabelWebhookDto) (*bean.LabelWebhookDto, error)
 	GetAppMetaInfo(appId int) (*bean.AppMetaInfoDto, error)
+	// GetAppDeployments is not cached, it lists every environment of the app with its latest deployment
+	GetAppDeployments(appId int) ([]*bean.AppEnvironmentDeployment, error)
 	GetHelmAppMetaInfo(appId string) (*bean.AppMetaInfoDto, error)
 	GetLabelsByAppIdForDeployment(appId int) ([]byte, error)
 	GetLabelsByAppId(appId int) (map[string]string, error)
 	UpdateApp(request *bean.CreateAppDTO) (*bean.CreateAppDTO, error)
 	UpdateProjectForApps(request *bean.UpdateProjectBulkAppsRequest) (*bean.UpdateProjectBulkAppsRequest, error)
+	// AddLabelsToApps adds the labels to every app of the request in a single transaction, with the outcome per app
+	AddLabelsToApps(request *bean.AppLabelBulkRequest) (*bean.AppLabelBulkResponse, error)
+	// RemoveLabelsFromApps removes the labels with the keys of the request from every app in a single transaction
+	RemoveLabelsFromApps(request *bean.AppLabelBulkDeleteRequest) (*bean.AppLabelBulkDeleteResponse, error)
 	GetAppMetaInfoByAppName(appName string) (*bean.AppMetaInfoDto, error)
 	GetAppListByTeamIds(teamIds []int, appType string) ([]*TeamAppBean, error)
+	// InvalidateAppMetaInfo is called after app or label writes made outside this service have committed
+	InvalidateAppMetaInfo(appId int)
 }
 type AppCrudOperationServiceImpl struct {
-	logger                 *zap.SugaredLogger
-	appLabelRepository     pipelineConfig.AppLabelRepository
-	appRepository          app.AppRepository
-	userRepository         repository.UserRepository
-	installedAppRepository repository2.InstalledAppRepository
+	logger                  *zap.SugaredLogger
+	appLabelRepository      pipelineConfig.AppLabelRepository
+	appRepository           app.AppRepository
+	userRepository          repository.UserRepository
+	installedAppRepository  repository2.InstalledAppRepository
+	transactionUtil         sql.TransactionUtil
+	appMetaInfoCache        *appMetaInfoCache
+	labelLimitConfig        *AppLabelLimitConfig
+	labelFeedConfig         *AppLabelFeedConfig
+	labelFeedNow            func() time.Time
+	labelTemplateRepository pipelineConfig.LabelTemplateRepository
+	labelEventPublisher     LabelEventPublisher
 }
 
 func NewAppCrudOperationServiceImpl(appLabelRepository pipelineConfig.AppLabelRepository,
-	logger *zap.SugaredLogger, appRepository app.AppRepository, userRepository repository.UserRepository, installedAppRepository repository2.InstalledAppRepository) *AppCrudOperationServiceImpl {
-	return &AppCrudOperationServiceImpl{
-		appLabelRepository:     appLabelRepository,
-		logger:                 logger,
-		appRepository:          appRepository,
-		userRepository:         userRepository,
-		installedAppRepository: installedAppRepository,
+	logger *zap.SugaredLogger, appRepository app.AppRepository, userRepository repository.UserRepository, installedAppRepository repository2.InstalledAppRepository,
+	transactionUtil sql.TransactionUtil, labelTemplateRepository pipelineConfig.LabelTemplateRepository,
+	labelEventPublisher LabelEventPublisher) (*AppCrudOperationServiceImpl, error) {
+	cacheConfig, err := GetAppMetaInfoCacheConfig()
+	if err != nil {
+		logger.Errorw("error in parsing app meta info cache config", "err", err)
+		return nil, err
+	}
+	labelLimitConfig, err := GetAppLabelLimitConfig()
+	if err != nil {
+		logger.Errorw("error in parsing app label limit config", "err", err)
+		return nil, err
 	}
+	labelFeedConfig, err := GetAppLabelFeedConfig()
+	if err != nil {
+		logger.Errorw("error in parsing app label feed config", "err", err)
+		return nil, err
+	}
+	return &AppCrudOperationServiceImpl{
+		appLabelRepository:      appLabelRepository,
+		logger:                  logger,
+		appRepository:           appRepository,
+		userRepository:          userRepository,
+		installedAppRepository:  installedAppRepository,
+		transactionUtil:         transactionUtil,
+		appMetaInfoCache:        newAppMetaInfoCache(cacheConfig),
+		labelLimitConfig:        labelLimitConfig,
+		labelFeedConfig:         labelFeedConfig,
+		labelFeedNow:            time.Now,
+		labelTemplateRepository: labelTemplateRepository,
+		labelEventPublisher:     labelEventPublisher,
+	}, nil
 }
 
 type AppBean struct {
@@ -80,82 +141,65 @@ type TeamAppBean struct {
 func (impl AppCrudOperationServiceImpl) UpdateApp(request *bean.CreateAppDTO) (*bean.CreateAppDTO, error) {
 	// validate the labels key-value if propagate is true
 	for _, label := range request.AppLabels {
-		if !label.Propagate {
-			continue
-		}
-		labelKey := label.Key
-		labelValue := label.Value
-		err := util2.CheckIfValidLabel(labelKey, labelValue)
-		if err != nil {
+		if err := ValidateAppLabel(label); err != nil {
 			return nil, err
 		}
 	}
-
-	dbConnection := impl.appRepository.GetConnection()
-	tx, err := dbConnection.Begin()
-	if err != nil {
-		return nil, err
-	}
-	// Rollback tx on error.
-	defer tx.Rollback()
-
-	app, err := impl.appRepository.FindById(request.Id)
-	if err != nil {
-		impl.logger.Errorw("error in fetching app", "error", err)
-		return nil, err
-	}
-	app.TeamId = request.TeamId
-	app.UpdatedOn = time.Now()
-	app.UpdatedBy = request.UserId
-	err = impl.appRepository.Update(app)
-	if err != nil {
-		impl.logger.Errorw("error in updating app", "error", err)
-		return nil, err
-	}
-
-	_, err = impl.UpdateLabelsInApp(request, tx)
-	if err != nil {
-		impl.logger.Errorw("error in updating app labels", "error", err)
+	if err := impl.checkPropagatedSize(request.AppLabels); err != nil {
 		return nil, err
 	}
 
-	err = tx.Commit()
+	// the app and its labels are committed together
+	err := impl.transactionUtil.WithTx(context.Background(), func(tx *pg.Tx) error {
+		app, err := impl.appRepository.FindById(request.Id)
+		if err != nil {
+			impl.logger.Errorw("error in fetching app", "error", err)
+			return err
+		}
+		app.TeamId = request.TeamId
+		app.UpdatedOn = time.Now()
+		app.UpdatedBy = request.UserId
+		err = impl.appRepository.UpdateWithTxn(app, tx)
+		if err != nil {
+			impl.logger.Errorw("error in updating app", "error", err)
+			return err
+		}
+		_, err = impl.UpdateLabelsInApp(request, tx)
+		if err != nil {
+			impl.logger.Errorw("error in updating app labels", "error", err)
+		}
+		return err
+	})
 	if err != nil {
-		impl.logger.Errorw("error in commit db transaction", "error", err)
 		return nil, err
 	}
-
+	impl.InvalidateAppMetaInfo(request.Id)
 	return request, nil
 }
 
 func (impl AppCrudOperationServiceImpl) UpdateProjectForApps(request *bean.UpdateProjectBulkAppsRequest) (*bean.UpdateProjectBulkAppsRequest, error) {
-	dbConnection := impl.appRepository.GetConnection()
-	tx, err := dbConnection.Begin()
-	if err != nil {
-		return nil, err
-	}
-	// Rollback tx on error.
-	defer tx.Rollback()
-	apps, err := impl.appRepository.FindAppsByTeamId(request.TeamId)
-	if err != nil {
-		impl.logger.Errorw("error in fetching apps", "error", err)
-		return nil, err
-	}
-	for _, app := range apps {
-		app.TeamId = request.TeamId
-		app.UpdatedOn = time.Now()
-		app.UpdatedBy = request.UserId
-		err = impl.appRepository.Update(app)
+	err := impl.transactionUtil.WithTx(context.Background(), func(tx *pg.Tx) error {
+		apps, err := impl.appRepository.FindAppsByTeamId(request.TeamId)
 		if err != nil {
-			impl.logger.Errorw("error in updating app", "error", err)
-			return nil, err
+			impl.logger.Errorw("error in fetching apps", "error", err)
+			return err
 		}
-	}
-	err = tx.Commit()
+		for _, app := range apps {
+			app.TeamId = request.TeamId
+			app.UpdatedOn = time.Now()
+			app.UpdatedBy = request.UserId
+			err = impl.appRepository.UpdateWithTxn(app, tx)
+			if err != nil {
+				impl.logger.Errorw("error in updating app", "error", err)
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		impl.logger.Errorw("error in commit db transaction", "error", err)
 		return nil, err
 	}
+	impl.appMetaInfoCache.invalidateAll()
 	return nil, nil
 }
 
@@ -171,6 +215,8 @@ func (impl AppCrudOperationServiceImpl) Create(request *bean.AppLabelDto, tx *pg
 			Value:     request.Value,
 			Propagate: request.Propagate,
 			AppId:     request.AppId,
+			Source:    labelSourceOrUnknown(request.Source),
+			Type:      appLabelType(request.Type),
 		}
 		model.CreatedBy = request.UserId
 		model.UpdatedBy = request.UserId
@@ -181,6 +227,10 @@ func (impl AppCrudOperationServiceImpl) Create(request *bean.AppLabelDto, tx *pg
 			impl.logger.Errorw("error in creating new app labels", "error", err)
 			return nil, err
 		}
+		err = impl.labelEventPublisher.Publish(labelChangeEvents(request.AppId, []*pipelineConfig.AppLabel{model}, nil, request.UserId, model.CreatedOn), tx)
+		if err != nil {
+			return nil, err
+		}
 	} else {
 		return nil, fmt.Errorf("duplicate key found for app %d, %s", request.AppId, request.Key)
 	}
@@ -195,14 +245,15 @@ func (impl AppCrudOperationServiceImpl) UpdateLabelsInApp(request *bean.CreateAp
 	}
 	appLabelMap := make(map[string]*pipelineConfig.AppLabel)
 	for _, appLabel := range appLabels {
-		uniqueLabelExists := fmt.Sprintf("%s:%s:%t", appLabel.Key, appLabel.Value, appLabel.Propagate)
+		uniqueLabelExists := fmt.Sprintf("%s:%s:%t:%s", appLabel.Key, appLabel.Value, appLabel.Propagate, appLabelType(appLabel.Type))
 		if _, ok := appLabelMap[uniqueLabelExists]; !ok {
 			appLabelMap[uniqueLabelExists] = appLabel
 		}
 	}
 
+	var createdLabels, deletedLabels []*pipelineConfig.AppLabel
 	for _, label := range request.AppLabels {
-		uniqueLabelRequest := fmt.Sprintf("%s:%s:%t", label.Key, label.Value, label.Propagate)
+		uniqueLabelRequest := fmt.Sprintf("%s:%s:%t:%s", label.Key, label.Value, label.Propagate, appLabelType(label.Type))
 		if _, ok := appLabelMap[uniqueLabelRequest]; !ok {
 			// create new
 			model := &pipelineConfig.AppLabel{
@@ -210,6 +261,8 @@ func (impl AppCrudOperationServiceImpl) UpdateLabelsInApp(request *bean.CreateAp
 				Value:     label.Value,
 				Propagate: label.Propagate,
 				AppId:     request.Id,
+				Source:    labelSourceOrUnknown(request.LabelSource),
+				Type:      appLabelType(label.Type),
 			}
 			model.CreatedBy = request.UserId
 			model.UpdatedBy = request.UserId
@@ -220,62 +273,269 @@ func (impl AppCrudOperationServiceImpl) UpdateLabelsInApp(request *bean.CreateAp
 				impl.logger.Errorw("error in creating new app labels", "error", err)
 				return nil, err
 			}
+			createdLabels = append(createdLabels, model)
 		} else {
 			// delete from map so that item remain live, all other item will be delete from this app
 			delete(appLabelMap, uniqueLabelRequest)
 		}
 	}
 	for _, appLabel := range appLabelMap {
+		appLabel.UpdatedBy = request.UserId
+		appLabel.UpdatedOn = time.Now()
 		err = impl.appLabelRepository.Delete(appLabel, tx)
 		if err != nil {
 			impl.logger.Errorw("error in delete app label", "error", err)
 			return nil, err
 		}
+		deletedLabels = append(deletedLabels, appLabel)
+	}
+	// counted on the transaction after the sync, the app row updated before the sync serializes concurrent updates
+	count, err := impl.appLabelRepository.CountByAppId(request.Id, tx)
+	if err != nil {
+		impl.logger.Errorw("error in counting app labels", "error", err, "appId", request.Id)
+		return nil, err
+	}
+	request.LabelWarning, err = impl.labelLimitConfig.checkLabelCount(count)
+	if err != nil {
+		return nil, err
+	}
+	err = impl.labelEventPublisher.Publish(labelChangeEvents(request.Id, createdLabels, deletedLabels, request.UserId, time.Now()), tx)
+	if err != nil {
+		return nil, err
 	}
 	return request, nil
 }
 
-func (impl AppCrudOperationServiceImpl) FindById(id int) (*bean.AppLabelDto, error) {
-	model, err := impl.appLabelRepository.FindById(id)
+func (impl AppCrudOperationServiceImpl) ValidateNewAppLabels(labels []*bean.Label) (string, error) {
+	unique := make(map[string]bool)
+	for _, label := range labels {
+		unique[fmt.Sprintf("%s:%s:%t:%s", label.Key, label.Value, label.Propagate, appLabelType(label.Type))] = true
+	}
+	warning, err := impl.labelLimitConfig.checkLabelCount(len(unique))
+	if err != nil {
+		return "", err
+	}
+	return warning, impl.checkPropagatedSize(labels)
+}
+
+func (impl AppCrudOperationServiceImpl) FindAppsAboveLabelSoftLimit() ([]*bean.AppLabelCountDto, error) {
+	results := make([]*bean.AppLabelCountDto, 0)
+	if impl.labelLimitConfig.SoftLimit <= 0 {
+		return results, nil
+	}
+	counts, err := impl.appLabelRepository.FindAppsWithLabelCountAbove(impl.labelLimitConfig.SoftLimit)
 	if err != nil && err != pg.ErrNoRows {
-		impl.logger.Errorw("error in fetching app labels", "error", err)
+		impl.logger.Errorw("error in fetching apps above label soft limit", "error", err)
 		return nil, err
 	}
+	for _, count := range counts {
+		results = append(results, &bean.AppLabelCountDto{AppId: count.AppId, AppName: count.AppName, TeamId: count.TeamId, LabelCount: count.LabelCount})
+	}
+	return results, nil
+}
+
+func (impl AppCrudOperationServiceImpl) CreateLabelWebhook(request *bean.LabelWebhookDto) (*bean.LabelWebhookDto, error) {
+	return impl.labelEventPublisher.RegisterWebhook(request)
+}
+
+func (impl AppCrudOperationServiceImpl) checkPropagatedSize(labels []*bean.Label) error {
+	propagatedLabels, propagatedAnnotations := propagatedMetadata(labels)
+	if err := impl.labelLimitConfig.checkPropagatedLabelSize(propagatedLabels); err != nil {
+		return err
+	}
+	return impl.labelLimitConfig.checkPropagatedLabelSize(propagatedAnnotations)
+}
+
+// propagatedMetadata splits the labels of the request which are propagated to kubernetes metadata into labels and
+// annotations, tags are left out
+func propagatedMetadata(labels []*bean.Label) (map[string]string, map[string]string) {
+	propagatedLabels, propagatedAnnotations := make(map[string]string), make(map[string]string)
+	for _, label := range labels {
+		if !label.Propagate {
+			continue
+		}
+		switch appLabelType(label.Type) {
+		case pipelineConfig.AppLabelTypeLabel:
+			propagatedLabels[strings.TrimSpace(label.Key)] = strings.TrimSpace(label.Value)
+		case pipelineConfig.AppLabelTypeAnnotation:
+			propagatedAnnotations[strings.TrimSpace(label.Key)] = strings.TrimSpace(label.Value)
+		}
+	}
+	return propagatedLabels, propagatedAnnotations
+}
+
+const (
+	AppLabelValueMaxLength      = 255
+	AppAnnotationValueMaxLength = 65536
+)
+
+// ValidateAppLabel checks the value length allowed for the type of the label and that a propagated label can be set on
+// kubernetes metadata, annotation values are free form and may be longer than the values of labels and tags
+func ValidateAppLabel(label *bean.Label) error {
+	maxLength := AppLabelValueMaxLength
+	if appLabelType(label.Type) == pipelineConfig.AppLabelTypeAnnotation {
+		maxLength = AppAnnotationValueMaxLength
+	}
+	if len(label.Value) > maxLength {
+		message := fmt.Sprintf("value of %s %s is %d characters long, at most %d are allowed", appLabelType(label.Type), label.Key, len(label.Value), maxLength)
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: message, InternalMessage: message}
+	}
+	return ValidatePropagatedLabel(label)
+}
+
+// ValidatePropagatedLabel checks that a propagated label can be set on kubernetes metadata of its type
+func ValidatePropagatedLabel(label *bean.Label) error {
+	if !label.Propagate {
+		return nil
+	}
+	switch appLabelType(label.Type) {
+	case pipelineConfig.AppLabelTypeAnnotation:
+		return util2.CheckIfValidAnnotation(label.Key)
+	case pipelineConfig.AppLabelTypeTag:
+		return nil
+	}
+	return util2.CheckIfValidLabel(label.Key, label.Value)
+}
+
+func appLabelType(labelType string) string {
+	if len(labelType) == 0 {
+		return pipelineConfig.AppLabelTypeLabel
+	}
+	return labelType
+}
+
+func (impl AppCrudOperationServiceImpl) FindById(id int) (*bean.AppLabelDto, error) {
+	model, err := impl.appLabelRepository.FindById(id)
 	if err == pg.ErrNoRows {
-		return &bean.AppLabelDto{}, nil
+		return nil, util.ErrLabelNotFound
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching app labels", "error", err)
+		return nil, err
 	}
-	label := &bean.AppLabelDto{
+	return newAppLabelDto(model), nil
+}
+
+func newAppLabelDto(model *pipelineConfig.AppLabel) *bean.AppLabelDto {
+	return &bean.AppLabelDto{
+		AppId:     model.AppId,
 		Key:       model.Key,
 		Value:     model.Value,
 		Propagate: model.Propagate,
-		AppId:     model.AppId,
+		Source:    model.Source,
+		Type:      appLabelType(model.Type),
 	}
-	return label, nil
 }
 
+// appLabelDtos is never nil so that an empty list is returned as [] rather than null
+func appLabelDtos(models []*pipelineConfig.AppLabel) []*bean.AppLabelDto {
+	results := make([]*bean.AppLabelDto, 0, len(models))
+	for _, model := range models {
+		results = append(results, newAppLabelDto(model))
+	}
+	return results
+}
+
+// InvalidateAppMetaInfo drops the cached meta info of the app and the label list, it must be called only after the
+// write has committed or a concurrent read can cache the old rows again. Other instances keep serving their entries
+// until the cache ttl expires
+func (impl AppCrudOperationServiceImpl) InvalidateAppMetaInfo(appId int) {
+	impl.appMetaInfoCache.invalidate(appMetaInfoCacheName, strconv.Itoa(appId))
+	impl.appMetaInfoCache.invalidate(appLabelsCacheName, allAppLabelsCacheKey)
+}
+
+// FindAll serves the label suggestions from cache, callers get their own copy of the list
 func (impl AppCrudOperationServiceImpl) FindAll() ([]*bean.AppLabelDto, error) {
-	results := make([]*bean.AppLabelDto, 0)
+	labels, err := impl.appMetaInfoCache.get(appLabelsCacheName, allAppLabelsCacheKey, func() (interface{}, error) {
+		return impl.findAllLabels()
+	})
+	if err != nil {
+		return nil, err
+	}
+	cachedLabels := labels.([]*bean.AppLabelDto)
+	results := make([]*bean.AppLabelDto, 0, len(cachedLabels))
+	for _, label := range cachedLabels {
+		labelCopy := *label
+		results = append(results, &labelCopy)
+	}
+	return results, nil
+}
+
+func (impl AppCrudOperationServiceImpl) findAllLabels() ([]*bean.AppLabelDto, error) {
 	models, err := impl.appLabelRepository.FindAll()
 	if err != nil && err != pg.ErrNoRows {
 		impl.logger.Errorw("error in fetching FindAll app labels", "error", err)
 		return nil, err
 	}
-	if err == pg.ErrNoRows {
-		return results, nil
+	return appLabelDtos(models), nil
+}
+
+func (impl AppCrudOperationServiceImpl) FindAllByListingRequest(request *pagination.ListingRequest) ([]*bean.AppLabelDto, error) {
+	models, err := impl.appLabelRepository.FindAllByListingRequest(request)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching app labels", "error", err, "request", request)
+		return nil, err
 	}
-	for _, model := range models {
-		dto := &bean.AppLabelDto{
-			AppId:     model.AppId,
-			Key:       model.Key,
-			Value:     model.Value,
-			Propagate: model.Propagate,
-		}
-		results = append(results, dto)
+	return appLabelDtos(models), nil
+}
+
+func (impl AppCrudOperationServiceImpl) FindByProjectId(projectId int) ([]*bean.AppLabelDto, error) {
+	models, err := impl.appLabelRepository.FindByProjectId(projectId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching app labels of project", "error", err, "projectId", projectId)
+		return nil, err
 	}
-	return results, nil
+	return appLabelDtos(models), nil
+}
+
+func (impl AppCrudOperationServiceImpl) FindByEnvironmentId(environmentId int) ([]*bean.AppLabelDto, error) {
+	models, err := impl.appLabelRepository.FindByEnvironmentId(environmentId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching app labels of environment", "error", err, "environmentId", environmentId)
+		return nil, err
+	}
+	return appLabelDtos(models), nil
 }
 
+// GetAppMetaInfo is served from cache, callers get their own copy of the meta info and its labels
 func (impl AppCrudOperationServiceImpl) GetAppMetaInfo(appId int) (*bean.AppMetaInfoDto, error) {
+	info, err := impl.appMetaInfoCache.get(appMetaInfoCacheName, strconv.Itoa(appId), func() (interface{}, error) {
+		return impl.getAppMetaInfo(appId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	infoCopy := *info.(*bean.AppMetaInfoDto)
+	infoCopy.Labels = make([]*bean.Label, 0, len(infoCopy.Labels))
+	for _, label := range info.(*bean.AppMetaInfoDto).Labels {
+		labelCopy := *label
+		infoCopy.Labels = append(infoCopy.Labels, &labelCopy)
+	}
+	return &infoCopy, nil
+}
+
+func (impl AppCrudOperationServiceImpl) GetAppDeployments(appId int) ([]*bean.AppEnvironmentDeployment, error) {
+	summaries, err := impl.appRepository.FindDeploymentSummaryByAppId(appId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching deployments of app", "appId", appId, "err", err)
+		return nil, err
+	}
+	deployments := make([]*bean.AppEnvironmentDeployment, 0, len(summaries))
+	for _, summary := range summaries {
+		deployments = append(deployments, &bean.AppEnvironmentDeployment{
+			EnvironmentId:         summary.EnvironmentId,
+			EnvironmentName:       summary.EnvironmentName,
+			EnvironmentIdentifier: summary.EnvironmentIdentifier,
+			ClusterId:             summary.ClusterId,
+			ClusterName:           summary.ClusterName,
+			Namespace:             summary.Namespace,
+			LastDeployedOn:        summary.LastDeployedOn,
+			LastDeploymentStatus:  summary.LastDeploymentStatus,
+		})
+	}
+	return deployments, nil
+}
+
+func (impl AppCrudOperationServiceImpl) getAppMetaInfo(appId int) (*bean.AppMetaInfoDto, error) {
 	app, err := impl.appRepository.FindAppAndProjectByAppId(appId)
 	if err != nil {
 		impl.logger.Errorw("error in fetching GetAppMetaInfo", "error", err)
@@ -295,6 +555,7 @@ func (impl AppCrudOperationServiceImpl) GetAppMetaInfo(appId int) (*bean.AppMeta
 				Key:       model.Key,
 				Value:     model.Value,
 				Propagate: model.Propagate,
+				Type:      appLabelType(model.Type),
 			}
 			labels = append(labels, dto)
 		}
@@ -401,6 +662,7 @@ func (impl AppCrudOperationServiceImpl) GetLabelsByAppIdForDeployment(appId int)
 		return nil, err
 	}
 	labelsDto := make(map[string]string)
+	annotationsDto := make(map[string]string)
 	for _, label := range labels {
 		labelKey := strings.TrimSpace(label.Key)
 		labelValue := strings.TrimSpace(label.Value)
@@ -409,6 +671,21 @@ func (impl AppCrudOperationServiceImpl) GetLabelsByAppIdForDeployment(appId int)
 			impl.logger.Warnw("Ignoring label to propagate to app level as propagation is false", "labelKey", labelKey, "labelValue", labelValue, "appId", appId)
 			continue
 		}
+		labelType := appLabelType(label.Type)
+		if labelType == pipelineConfig.AppLabelTypeTag {
+			continue
+		}
+		if labelType == pipelineConfig.AppLabelTypeAnnotation {
+			if len(labelKey) == 0 {
+				continue
+			}
+			if err = util2.CheckIfValidAnnotation(labelKey); err != nil {
+				impl.logger.Warnw("Ignoring annotation to propagate to app level", "err", err, "appId", appId)
+				continue
+			}
+			annotationsDto[labelKey] = labelValue
+			continue
+		}
 
 		// if labelKey or labelValue is empty then don't add in labels
 		if len(labelKey) == 0 || len(labelValue) == 0 {
@@ -426,7 +703,18 @@ func (impl AppCrudOperationServiceImpl) GetLabelsByAppIdForDeployment(appId int)
 
 		labelsDto[labelKey] = labelValue
 	}
+	if err = impl.labelLimitConfig.checkPropagatedLabelSize(labelsDto); err != nil {
+		impl.logger.Errorw("propagated labels are above the size limit", "err", err, "appId", appId)
+		return nil, err
+	}
+	if err = impl.labelLimitConfig.checkPropagatedLabelSize(annotationsDto); err != nil {
+		impl.logger.Errorw("propagated annotations are above the size limit", "err", err, "appId", appId)
+		return nil, err
+	}
 	appLabelJson.Labels = labelsDto
+	if len(annotationsDto) > 0 {
+		appLabelJson.Annotations = annotationsDto
+	}
 	appLabelByte, err := json.Marshal(appLabelJson)
 	if err != nil {
 		impl.logger.Errorw("error in marshaling appLabels json", "err", err, "appLabelJson", appLabelJson)
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAppDeployments(t *testing.T) {
+	deployedOn := time.Date(2023, 3, 10, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		summaries []*app.AppDeploymentSummary
+		wantJson  string
+	}{
+		{
+			name:     "app without pipelines",
+			wantJson: `[]`,
+		},
+		{
+			name:      "pipeline never deployed",
+			summaries: []*app.AppDeploymentSummary{{EnvironmentId: 1, EnvironmentName: "dev", EnvironmentIdentifier: "default_cluster__dev", Namespace: "dev", ClusterId: 1, ClusterName: "default_cluster"}},
+			wantJson:  `[{"environmentId":1,"environmentName":"dev","clusterId":1,"clusterName":"default_cluster","namespace":"dev"}]`,
+		},
+		{
+			name: "deployed pipeline",
+			summaries: []*app.AppDeploymentSummary{{EnvironmentId: 2, EnvironmentName: "prod", Namespace: "prod", ClusterId: 1, ClusterName: "default_cluster",
+				LastDeployedOn: &deployedOn, LastDeploymentStatus: "Succeeded"}},
+			wantJson: `[{"environmentId":2,"environmentName":"prod","clusterId":1,"clusterName":"default_cluster","namespace":"prod","lastDeployedOn":"2023-03-10T10:30:00Z","lastDeploymentStatus":"Succeeded"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl, _, _ := newTestAppCrudOperationService(t, &AppMetaInfoCacheConfig{})
+			impl.appRepository = &appRepositoryStub{deployments: tt.summaries}
+			deployments, err := impl.GetAppDeployments(1)
+			assert.Nil(t, err)
+			deploymentsJson, err := json.Marshal(deployments)
+			assert.Nil(t, err)
+			assert.JSONEq(t, tt.wantJson, string(deploymentsJson))
+		})
+	}
+}
+
+func TestAppMetaInfoWithoutExpandHasNoDeployments(t *testing.T) {
+	impl, _, _ := newTestAppCrudOperationService(t, &AppMetaInfoCacheConfig{})
+	info, err := impl.GetAppMetaInfo(1)
+	assert.Nil(t, err)
+	infoJson, err := json.Marshal(info)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(infoJson), "deployments")
+}
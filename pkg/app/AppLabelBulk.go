@@ -0,0 +1,234 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+)
+
+// bulkAppLabelsSavepoint scopes the writes of one app of a bulk request, an app going above the label limit is rolled
+// back to it
+const bulkAppLabelsSavepoint = "app_labels_bulk"
+
+// AddLabelsToApps adds the labels to the apps in a single transaction. Apps which are missing or would go above the
+// label limits are reported as failed and left unchanged, the other apps get the labels they do not have yet
+func (impl AppCrudOperationServiceImpl) AddLabelsToApps(request *bean.AppLabelBulkRequest) (*bean.AppLabelBulkResponse, error) {
+	appIds := uniqueSortedAppIds(request.AppIds)
+	if err := impl.labelLimitConfig.checkBulkAppCount(len(appIds)); err != nil {
+		return nil, err
+	}
+	for _, label := range request.Labels {
+		if err := ValidateAppLabel(label); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]*bean.AppLabelBulkResult, 0, len(appIds))
+	// apps are locked in id order by addLabelsToApp so that concurrent bulk requests do not deadlock
+	err := impl.transactionUtil.WithTx(context.Background(), func(tx *pg.Tx) error {
+		results = results[:0]
+		for _, appId := range appIds {
+			result, err := impl.addLabelsToApp(appId, request, tx)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		impl.logger.Errorw("error in adding labels to apps", "err", err, "appIds", appIds)
+		return nil, err
+	}
+	response := &bean.AppLabelBulkResponse{Results: results}
+	for _, result := range results {
+		if result.Success {
+			response.Succeeded++
+			impl.InvalidateAppMetaInfo(result.AppId)
+		} else {
+			response.Failed++
+		}
+	}
+	return response, nil
+}
+
+// RemoveLabelsFromApps removes the labels with the keys from the apps with one statement, the removals of each app are
+// published on the same transaction
+func (impl AppCrudOperationServiceImpl) RemoveLabelsFromApps(request *bean.AppLabelBulkDeleteRequest) (*bean.AppLabelBulkDeleteResponse, error) {
+	appIds := uniqueSortedAppIds(request.AppIds)
+	if err := impl.labelLimitConfig.checkBulkAppCount(len(appIds)); err != nil {
+		return nil, err
+	}
+	var deletedLabels []*pipelineConfig.AppLabel
+	err := impl.transactionUtil.WithTx(context.Background(), func(tx *pg.Tx) error {
+		var err error
+		deletedLabels, err = impl.appLabelRepository.DeleteByAppIdsAndKeys(appIds, request.Keys, request.UserId, tx)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for appId, labels := range labelsByAppId(deletedLabels) {
+			err = impl.labelEventPublisher.Publish(labelChangeEvents(appId, nil, labels, request.UserId, now), tx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		impl.logger.Errorw("error in removing labels from apps", "err", err, "appIds", appIds, "keys", request.Keys)
+		return nil, err
+	}
+	deletedByAppId := labelsByAppId(deletedLabels)
+	response := &bean.AppLabelBulkDeleteResponse{Deleted: len(deletedLabels), Results: make([]*bean.AppLabelBulkDeleteResult, 0, len(appIds))}
+	for _, appId := range appIds {
+		deleted := len(deletedByAppId[appId])
+		if deleted > 0 {
+			impl.InvalidateAppMetaInfo(appId)
+		}
+		response.Results = append(response.Results, &bean.AppLabelBulkDeleteResult{AppId: appId, Deleted: deleted})
+	}
+	return response, nil
+}
+
+// addLabelsToApp returns an error only for failures which abort the whole transaction, problems with the app itself
+// are part of the result and leave the app unchanged
+func (impl AppCrudOperationServiceImpl) addLabelsToApp(appId int, request *bean.AppLabelBulkRequest, tx *pg.Tx) (*bean.AppLabelBulkResult, error) {
+	result := &bean.AppLabelBulkResult{AppId: appId}
+	// the app row stays locked until the end of tx, so the labels read and counted on tx below can not be changed by a
+	// concurrent writer of the app
+	app, err := impl.appRepository.FindByIdForUpdate(appId, tx)
+	if err == pg.ErrNoRows || (err == nil && !app.Active) {
+		result.Error = "app not found"
+		return result, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching app", "error", err, "appId", appId)
+		return nil, err
+	}
+	appLabels, err := impl.appLabelRepository.FindAllByAppIdWithTxn(appId, tx)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching app label", "error", err, "appId", appId)
+		return nil, err
+	}
+	newLabels, allLabels := bulkLabelsToCreate(appLabels, request.Labels)
+	if len(newLabels) == 0 {
+		result.Success = true
+		return result, nil
+	}
+	err = impl.checkPropagatedSize(allLabels)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	var limitErr error
+	err = sql.WithSavepoint(tx, bulkAppLabelsSavepoint, func() error {
+		createdLabels, err := impl.createBulkLabels(app, newLabels, request, tx)
+		if err != nil {
+			return err
+		}
+		// counted on the transaction after the insert like in UpdateLabelsInApp
+		count, err := impl.appLabelRepository.CountByAppId(appId, tx)
+		if err != nil {
+			impl.logger.Errorw("error in counting app labels", "error", err, "appId", appId)
+			return err
+		}
+		result.Warning, limitErr = impl.labelLimitConfig.checkLabelCount(count)
+		if limitErr != nil {
+			return limitErr
+		}
+		return impl.labelEventPublisher.Publish(labelChangeEvents(appId, createdLabels, nil, request.UserId, time.Now()), tx)
+	})
+	if limitErr != nil && err == limitErr {
+		// the labels of the app are rolled back, the other apps of the request keep theirs
+		result.Warning, result.Error = "", limitErr.Error()
+		return result, nil
+	} else if err != nil {
+		return nil, err
+	}
+	result.Success = true
+	return result, nil
+}
+
+func (impl AppCrudOperationServiceImpl) createBulkLabels(model *app.App, labels []*bean.Label, request *bean.AppLabelBulkRequest, tx *pg.Tx) ([]*pipelineConfig.AppLabel, error) {
+	model.UpdatedOn = time.Now()
+	model.UpdatedBy = request.UserId
+	err := impl.appRepository.UpdateWithTxn(model, tx)
+	if err != nil {
+		impl.logger.Errorw("error in updating app", "error", err, "appId", model.Id)
+		return nil, err
+	}
+	createdLabels := make([]*pipelineConfig.AppLabel, 0, len(labels))
+	for _, label := range labels {
+		appLabel := &pipelineConfig.AppLabel{
+			Key:       label.Key,
+			Value:     label.Value,
+			Propagate: label.Propagate,
+			AppId:     model.Id,
+			Source:    labelSourceOrUnknown(request.LabelSource),
+			Type:      appLabelType(label.Type),
+		}
+		appLabel.CreatedBy = request.UserId
+		appLabel.UpdatedBy = request.UserId
+		appLabel.CreatedOn = time.Now()
+		appLabel.UpdatedOn = time.Now()
+		_, err = impl.appLabelRepository.Create(appLabel, tx)
+		if err != nil {
+			impl.logger.Errorw("error in creating new app labels", "error", err, "appId", model.Id)
+			return nil, err
+		}
+		createdLabels = append(createdLabels, appLabel)
+	}
+	return createdLabels, nil
+}
+
+// bulkLabelsToCreate returns the labels of the request the app does not have yet, along with all labels the app has
+// once they are added. Labels are compared like in UpdateLabelsInApp
+func bulkLabelsToCreate(appLabels []*pipelineConfig.AppLabel, labels []*bean.Label) ([]*bean.Label, []*bean.Label) {
+	uniqueKey := func(key, value string, propagate bool, labelType string) string {
+		return fmt.Sprintf("%s:%s:%t:%s", key, value, propagate, appLabelType(labelType))
+	}
+	existing := make(map[string]bool, len(appLabels))
+	allLabels := make([]*bean.Label, 0, len(appLabels)+len(labels))
+	for _, appLabel := range appLabels {
+		existing[uniqueKey(appLabel.Key, appLabel.Value, appLabel.Propagate, appLabel.Type)] = true
+		allLabels = append(allLabels, &bean.Label{Key: appLabel.Key, Value: appLabel.Value, Propagate: appLabel.Propagate, Type: appLabel.Type})
+	}
+	var newLabels []*bean.Label
+	for _, label := range labels {
+		key := uniqueKey(label.Key, label.Value, label.Propagate, label.Type)
+		if existing[key] {
+			continue
+		}
+		existing[key] = true
+		newLabels = append(newLabels, label)
+		allLabels = append(allLabels, label)
+	}
+	return newLabels, allLabels
+}
+
+func uniqueSortedAppIds(appIds []int) []int {
+	seen := make(map[int]bool, len(appIds))
+	unique := make([]int, 0, len(appIds))
+	for _, appId := range appIds {
+		if !seen[appId] {
+			seen[appId] = true
+			unique = append(unique, appId)
+		}
+	}
+	sort.Ints(unique)
+	return unique
+}
+
+func labelsByAppId(labels []*pipelineConfig.AppLabel) map[int][]*pipelineConfig.AppLabel {
+	labelsByAppId := make(map[int][]*pipelineConfig.AppLabel)
+	for _, label := range labels {
+		labelsByAppId[label.AppId] = append(labelsByAppId[label.AppId], label)
+	}
+	return labelsByAppId
+}
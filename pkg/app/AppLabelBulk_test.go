@@ -0,0 +1,57 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkLabelsToCreate(t *testing.T) {
+	appLabels := []*pipelineConfig.AppLabel{
+		{Key: "team", Value: "payments", Type: pipelineConfig.AppLabelTypeLabel},
+		{Key: "owner", Value: "ledger", Propagate: true},
+	}
+	labels := []*bean.Label{
+		{Key: "team", Value: "payments"},
+		{Key: "owner", Value: "ledger"},
+		{Key: "tier", Value: "critical", Propagate: true},
+		{Key: "tier", Value: "critical", Propagate: true},
+	}
+	newLabels, allLabels := bulkLabelsToCreate(appLabels, labels)
+	// the owner label differs from the existing one by propagation so it is a new label
+	assert.Equal(t, []*bean.Label{labels[1], labels[2]}, newLabels)
+	assert.Len(t, allLabels, 4)
+	assert.Equal(t, "owner", allLabels[1].Key)
+	assert.True(t, allLabels[1].Propagate)
+
+	newLabels, allLabels = bulkLabelsToCreate(nil, labels[:1])
+	assert.Len(t, newLabels, 1)
+	assert.Len(t, allLabels, 1)
+}
+
+func TestUniqueSortedAppIds(t *testing.T) {
+	assert.Equal(t, []int{1, 3, 7}, uniqueSortedAppIds([]int{7, 3, 7, 1, 3}))
+	assert.Empty(t, uniqueSortedAppIds(nil))
+}
+
+func TestLabelsByAppId(t *testing.T) {
+	labels := []*pipelineConfig.AppLabel{
+		{AppId: 2, Key: "team"},
+		{AppId: 1, Key: "team"},
+		{AppId: 2, Key: "team", Type: pipelineConfig.AppLabelTypeTag},
+	}
+	labelsByAppId := labelsByAppId(labels)
+	assert.Len(t, labelsByAppId, 2)
+	assert.Equal(t, []*pipelineConfig.AppLabel{labels[0], labels[2]}, labelsByAppId[2])
+	assert.Empty(t, labelsByAppId[3])
+}
+
+func TestCheckBulkAppCount(t *testing.T) {
+	config := &AppLabelLimitConfig{BulkMaxApps: 2}
+	assert.NoError(t, config.checkBulkAppCount(2))
+	assert.Error(t, config.checkBulkAppCount(3))
+	config.BulkMaxApps = 0
+	assert.NoError(t, config.checkBulkAppCount(500))
+}
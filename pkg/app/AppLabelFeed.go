@@ -0,0 +1,121 @@
+package app
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/go-pg/pg"
+)
+
+// AppLabelFeedConfig sizes the pages of the label feed. Labels are served once they are SettleSecs old, a label
+// written by a transaction taking longer than that to commit can be missed by a walk which already passed it
+type AppLabelFeedConfig struct {
+	DefaultPageSize int `env:"APP_LABEL_FEED_PAGE_SIZE" envDefault:"500"`
+	MaxPageSize     int `env:"APP_LABEL_FEED_MAX_PAGE_SIZE" envDefault:"5000"`
+	SettleSecs      int `env:"APP_LABEL_FEED_SETTLE_SECS" envDefault:"5"`
+}
+
+func GetAppLabelFeedConfig() (*AppLabelFeedConfig, error) {
+	config := &AppLabelFeedConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+// appLabelFeedCursor is the position of a walk of the feed, Since is the start of the walk or the UpdatedAfter of an
+// incremental sync, labels removed after it are reported as deleted
+type appLabelFeedCursor struct {
+	UpdatedOn time.Time `json:"u"`
+	Id        int       `json:"i"`
+	Since     time.Time `json:"s"`
+}
+
+func (cursor *appLabelFeedCursor) encode() string {
+	content, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+func decodeAppLabelFeedCursor(value string) (*appLabelFeedCursor, error) {
+	content, err := base64.RawURLEncoding.DecodeString(value)
+	cursor := &appLabelFeedCursor{}
+	if err == nil {
+		err = json.Unmarshal(content, cursor)
+	}
+	if err != nil || cursor.Since.IsZero() {
+		message := "invalid cursor, start again without one"
+		return nil, &util.ApiError{
+			HttpStatusCode:  http.StatusBadRequest,
+			Code:            strconv.Itoa(http.StatusBadRequest),
+			UserMessage:     message,
+			InternalMessage: message,
+		}
+	}
+	return cursor, nil
+}
+
+// startAppLabelFeedCursor starts a full walk from the beginning at now, or an incremental sync of the labels updated
+// strictly after updatedAfter as every id is below the max of the int4 id column
+func startAppLabelFeedCursor(updatedAfter *time.Time, now time.Time) *appLabelFeedCursor {
+	if updatedAfter != nil {
+		return &appLabelFeedCursor{UpdatedOn: *updatedAfter, Id: math.MaxInt32, Since: *updatedAfter}
+	}
+	return &appLabelFeedCursor{Since: now}
+}
+
+func (impl AppCrudOperationServiceImpl) FindLabelFeed(request *bean.AppLabelFeedRequest) (*bean.AppLabelFeedResponse, error) {
+	now := impl.labelFeedNow()
+	cursor := startAppLabelFeedCursor(request.UpdatedAfter, now)
+	if len(request.Cursor) > 0 {
+		var err error
+		if cursor, err = decodeAppLabelFeedCursor(request.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = impl.labelFeedConfig.DefaultPageSize
+	}
+	if limit > impl.labelFeedConfig.MaxPageSize {
+		limit = impl.labelFeedConfig.MaxPageSize
+	}
+	// one more label than the page is read to tell whether another page follows
+	models, err := impl.appLabelRepository.FindFeedPage(&pipelineConfig.AppLabelFeedQuery{
+		AfterUpdatedOn: cursor.UpdatedOn,
+		AfterId:        cursor.Id,
+		DeletedAfter:   cursor.Since,
+		SettledBefore:  now.Add(-time.Duration(impl.labelFeedConfig.SettleSecs) * time.Second),
+		Limit:          limit + 1,
+	})
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching label feed page", "error", err, "cursor", cursor)
+		return nil, err
+	}
+	response := &bean.AppLabelFeedResponse{Labels: make([]*bean.AppLabelFeedItem, 0, len(models))}
+	if len(models) > limit {
+		models, response.HasMore = models[:limit], true
+	}
+	for _, model := range models {
+		response.Labels = append(response.Labels, &bean.AppLabelFeedItem{
+			Id:        model.Id,
+			AppId:     model.AppId,
+			AppName:   model.App.AppName,
+			TeamId:    model.App.TeamId,
+			Key:       model.Key,
+			Value:     model.Value,
+			Type:      appLabelType(model.Type),
+			Propagate: model.Propagate,
+			UpdatedOn: model.UpdatedOn,
+			Deleted:   !model.Active,
+		})
+		cursor.UpdatedOn, cursor.Id = model.UpdatedOn, model.Id
+	}
+	response.NextCursor = cursor.encode()
+	return response, nil
+}
@@ -0,0 +1,256 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/stretchr/testify/assert"
+)
+
+// labelFeedStore keeps labels like the app_label table, writes stamp updated_on from a clock which only moves every
+// other write so that labels share timestamps and the id breaks the tie
+type labelFeedStore struct {
+	pipelineConfig.AppLabelRepository
+	mutex  sync.Mutex
+	labels map[int]*pipelineConfig.AppLabel
+	nextId int
+	writes int
+	now    time.Time
+}
+
+func newLabelFeedStore() *labelFeedStore {
+	return &labelFeedStore{labels: make(map[int]*pipelineConfig.AppLabel), now: time.Unix(1700000000, 0).UTC()}
+}
+
+func (s *labelFeedStore) stamp() time.Time {
+	s.writes++
+	if s.writes%2 == 0 {
+		s.now = s.now.Add(time.Millisecond)
+	}
+	return s.now
+}
+
+func (s *labelFeedStore) insert(key, value string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.nextId++
+	label := &pipelineConfig.AppLabel{Id: s.nextId, AppId: 1, Key: key, Value: value, Active: true}
+	label.UpdatedOn = s.stamp()
+	s.labels[label.Id] = label
+	return label.Id
+}
+
+func (s *labelFeedStore) update(id int, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.labels[id].Value = value
+	s.labels[id].UpdatedOn = s.stamp()
+}
+
+func (s *labelFeedStore) remove(id int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.labels[id].Active = false
+	s.labels[id].UpdatedOn = s.stamp()
+}
+
+func (s *labelFeedStore) clock() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.now
+}
+
+// tick moves the clock on, labels are only served once the clock has passed their update
+func (s *labelFeedStore) tick() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.now = s.now.Add(time.Millisecond)
+}
+
+func (s *labelFeedStore) active() map[int]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	values := make(map[int]string)
+	for id, label := range s.labels {
+		if label.Active {
+			values[id] = label.Value
+		}
+	}
+	return values
+}
+
+func (s *labelFeedStore) FindFeedPage(query *pipelineConfig.AppLabelFeedQuery) ([]*pipelineConfig.AppLabel, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var page []*pipelineConfig.AppLabel
+	for _, label := range s.labels {
+		after := label.UpdatedOn.After(query.AfterUpdatedOn) ||
+			(label.UpdatedOn.Equal(query.AfterUpdatedOn) && label.Id > query.AfterId)
+		visible := label.Active || (!query.DeletedAfter.IsZero() && label.UpdatedOn.After(query.DeletedAfter))
+		if after && visible && label.UpdatedOn.Before(query.SettledBefore) {
+			labelCopy := *label
+			labelCopy.App = app.App{Id: label.AppId, AppName: "demo", TeamId: 2}
+			page = append(page, &labelCopy)
+		}
+	}
+	sort.Slice(page, func(i, j int) bool {
+		if !page[i].UpdatedOn.Equal(page[j].UpdatedOn) {
+			return page[i].UpdatedOn.Before(page[j].UpdatedOn)
+		}
+		return page[i].Id < page[j].Id
+	})
+	if len(page) > query.Limit {
+		page = page[:query.Limit]
+	}
+	return page, nil
+}
+
+func newTestLabelFeedService(t *testing.T, store *labelFeedStore) *AppCrudOperationServiceImpl {
+	logger, err := util.NewSugardLogger()
+	assert.Nil(t, err)
+	return &AppCrudOperationServiceImpl{
+		logger:             logger,
+		appLabelRepository: store,
+		labelFeedConfig:    &AppLabelFeedConfig{DefaultPageSize: 3, MaxPageSize: 10},
+		labelFeedNow:       store.clock,
+	}
+}
+
+// labelFeedConsumer applies the pages to its copy of the labels like an external system syncing from the feed
+type labelFeedConsumer struct {
+	values map[int]string
+	seen   map[string]bool
+	cursor string
+}
+
+func (c *labelFeedConsumer) apply(t *testing.T, response *bean.AppLabelFeedResponse) {
+	for _, label := range response.Labels {
+		version := fmt.Sprintf("%d@%s", label.Id, label.UpdatedOn.Format(time.RFC3339Nano))
+		assert.False(t, c.seen[version], "label version %s served twice", version)
+		c.seen[version] = true
+		if label.Deleted {
+			delete(c.values, label.Id)
+		} else {
+			c.values[label.Id] = label.Value
+		}
+	}
+	assert.NotEmpty(t, response.NextCursor)
+	c.cursor = response.NextCursor
+}
+
+func TestFindLabelFeed_WalkAcrossConcurrentWrites(t *testing.T) {
+	store := newLabelFeedStore()
+	var ids []int
+	for i := 0; i < 10; i++ {
+		ids = append(ids, store.insert(fmt.Sprintf("key-%d", i), "v0"))
+	}
+	impl := newTestLabelFeedService(t, store)
+	consumer := &labelFeedConsumer{values: make(map[int]string), seen: make(map[string]bool)}
+
+	// writes between the pages hit labels already served, labels not reached yet and new labels
+	writes := []func(){
+		func() { store.update(ids[0], "v1") },
+		func() { store.remove(ids[1]); store.update(ids[8], "v1") },
+		func() { store.insert("late", "v0") },
+		func() { store.remove(ids[9]); store.update(ids[2], "v2") },
+		func() { id := store.insert("short-lived", "v0"); store.remove(id) },
+	}
+	pages := 0
+	for {
+		store.tick()
+		response, err := impl.FindLabelFeed(&bean.AppLabelFeedRequest{Cursor: consumer.cursor})
+		assert.Nil(t, err)
+		assert.LessOrEqual(t, len(response.Labels), 3)
+		consumer.apply(t, response)
+		if pages < len(writes) {
+			writes[pages]()
+		} else if !response.HasMore {
+			break
+		}
+		pages++
+		assert.Less(t, pages, 50, "the walk does not end")
+	}
+	assert.Equal(t, store.active(), consumer.values)
+
+	// polling the last cursor picks up the changes made after the walk
+	store.update(ids[3], "v3")
+	store.remove(ids[4])
+	store.tick()
+	response, err := impl.FindLabelFeed(&bean.AppLabelFeedRequest{Cursor: consumer.cursor})
+	assert.Nil(t, err)
+	assert.False(t, response.HasMore)
+	consumer.apply(t, response)
+	assert.Len(t, response.Labels, 2)
+	assert.Equal(t, store.active(), consumer.values)
+}
+
+func TestFindLabelFeed_UpdatedAfter(t *testing.T) {
+	store := newLabelFeedStore()
+	removedBefore := store.insert("removed-before", "v0")
+	store.remove(removedBefore)
+	unchanged := store.insert("unchanged", "v0")
+	changed := store.insert("changed", "v0")
+	removed := store.insert("removed", "v0")
+	store.mutex.Lock()
+	since := store.now
+	store.now = store.now.Add(time.Second)
+	store.mutex.Unlock()
+	store.update(changed, "v1")
+	store.remove(removed)
+	store.tick()
+	impl := newTestLabelFeedService(t, store)
+
+	response, err := impl.FindLabelFeed(&bean.AppLabelFeedRequest{UpdatedAfter: &since, Limit: 10})
+	assert.Nil(t, err)
+	assert.False(t, response.HasMore)
+	var served []string
+	for _, label := range response.Labels {
+		served = append(served, fmt.Sprintf("%d:%t", label.Id, label.Deleted))
+		assert.Equal(t, "demo", label.AppName)
+	}
+	assert.Equal(t, []string{fmt.Sprintf("%d:false", changed), fmt.Sprintf("%d:true", removed)}, served)
+	assert.NotContains(t, served, fmt.Sprintf("%d:false", unchanged))
+
+	// a full walk leaves out the labels removed before it started
+	response, err = impl.FindLabelFeed(&bean.AppLabelFeedRequest{Limit: 10})
+	assert.Nil(t, err)
+	for _, label := range response.Labels {
+		assert.False(t, label.Deleted)
+		assert.NotEqual(t, removedBefore, label.Id)
+	}
+	assert.Len(t, response.Labels, 2)
+}
+
+func TestFindLabelFeed_InvalidCursor(t *testing.T) {
+	impl := newTestLabelFeedService(t, newLabelFeedStore())
+	_, err := impl.FindLabelFeed(&bean.AppLabelFeedRequest{Cursor: "not-a-cursor"})
+	apiErr, ok := err.(*util.ApiError)
+	assert.True(t, ok)
+	assert.Equal(t, 400, apiErr.HttpStatusCode)
+}
+
+func TestFindLabelFeed_ServesSettledLabels(t *testing.T) {
+	store := newLabelFeedStore()
+	store.insert("first", "v0")
+	impl := newTestLabelFeedService(t, store)
+	impl.labelFeedConfig.SettleSecs = 1
+
+	response, err := impl.FindLabelFeed(&bean.AppLabelFeedRequest{})
+	assert.Nil(t, err)
+	assert.Empty(t, response.Labels)
+
+	// the label is served from the cursor of the empty page once it is old enough
+	store.mutex.Lock()
+	store.now = store.now.Add(time.Second + time.Millisecond)
+	store.mutex.Unlock()
+	response, err = impl.FindLabelFeed(&bean.AppLabelFeedRequest{Cursor: response.NextCursor})
+	assert.Nil(t, err)
+	assert.Len(t, response.Labels, 1)
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/devtron-labs/devtron/internal/util"
+)
+
+// AppLabelLimitConfig caps the labels of an app. Apps above the soft limit are reported and their label updates carry a
+// warning, updates taking an app above the hard limit are rejected. PropagatedMaxSizeBytes is the budget of the labels
+// propagated to kubernetes metadata, it defaults to the 256KiB kubernetes allows for all annotations of an object.
+// BulkMaxApps caps the apps of a bulk label request
+type AppLabelLimitConfig struct {
+	SoftLimit              int `env:"APP_LABEL_SOFT_LIMIT" envDefault:"50"`
+	HardLimit              int `env:"APP_LABEL_HARD_LIMIT" envDefault:"100"`
+	PropagatedMaxSizeBytes int `env:"APP_LABEL_PROPAGATED_MAX_SIZE_BYTES" envDefault:"262144"`
+	BulkMaxApps            int `env:"APP_LABEL_BULK_MAX_APPS" envDefault:"100"`
+}
+
+func GetAppLabelLimitConfig() (*AppLabelLimitConfig, error) {
+	config := &AppLabelLimitConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+// checkLabelCount returns a warning above the soft limit and a bad request error above the hard limit, a limit of 0
+// disables the check
+func (config *AppLabelLimitConfig) checkLabelCount(count int) (string, error) {
+	if config.HardLimit > 0 && count > config.HardLimit {
+		message := fmt.Sprintf("app can have at most %d labels, found %d", config.HardLimit, count)
+		return "", &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: message, InternalMessage: message}
+	}
+	if config.SoftLimit > 0 && count > config.SoftLimit {
+		return fmt.Sprintf("app has %d labels which is above the recommended limit of %d", count, config.SoftLimit), nil
+	}
+	return "", nil
+}
+
+// checkBulkAppCount rejects bulk label requests for more apps than BulkMaxApps, 0 disables the check
+func (config *AppLabelLimitConfig) checkBulkAppCount(count int) error {
+	if config.BulkMaxApps > 0 && count > config.BulkMaxApps {
+		message := fmt.Sprintf("labels can be changed on at most %d apps at once, found %d", config.BulkMaxApps, count)
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: message, InternalMessage: message}
+	}
+	return nil
+}
+
+// checkPropagatedLabelSize fails when the propagated labels do not fit the size budget, the error names the largest
+// labels which have to be dropped or shortened to fit
+func (config *AppLabelLimitConfig) checkPropagatedLabelSize(labels map[string]string) error {
+	if config.PropagatedMaxSizeBytes <= 0 {
+		return nil
+	}
+	total, offending := oversizedLabels(labels, config.PropagatedMaxSizeBytes)
+	if len(offending) == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("propagated labels take %d bytes which is above the limit of %d bytes, largest labels: %s", total, config.PropagatedMaxSizeBytes, strings.Join(offending, ", "))
+	return &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: message, InternalMessage: message}
+}
+
+// oversizedLabels returns the total size of the labels and, when it is above limit, the keys of the largest labels
+// whose removal brings the size within limit
+func oversizedLabels(labels map[string]string, limit int) (int, []string) {
+	total := 0
+	keys := make([]string, 0, len(labels))
+	for key, value := range labels {
+		total += len(key) + len(value)
+		keys = append(keys, key)
+	}
+	if total <= limit {
+		return total, nil
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		sizeI, sizeJ := len(keys[i])+len(labels[keys[i]]), len(keys[j])+len(labels[keys[j]])
+		if sizeI != sizeJ {
+			return sizeI > sizeJ
+		}
+		return keys[i] < keys[j]
+	})
+	var offending []string
+	remaining := total
+	for _, key := range keys {
+		if remaining <= limit {
+			break
+		}
+		offending = append(offending, key)
+		remaining -= len(key) + len(labels[key])
+	}
+	return total, offending
+}
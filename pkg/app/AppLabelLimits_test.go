@@ -0,0 +1,42 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppLabelLimitConfig_CheckLabelCount(t *testing.T) {
+	config := &AppLabelLimitConfig{SoftLimit: 2, HardLimit: 3}
+	warning, err := config.checkLabelCount(2)
+	assert.NoError(t, err)
+	assert.Empty(t, warning)
+	warning, err = config.checkLabelCount(3)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, warning)
+	_, err = config.checkLabelCount(4)
+	assert.Error(t, err)
+
+	warning, err = (&AppLabelLimitConfig{}).checkLabelCount(1000)
+	assert.NoError(t, err)
+	assert.Empty(t, warning)
+}
+
+func TestOversizedLabels(t *testing.T) {
+	labels := map[string]string{
+		"team":        "payments",
+		"description": strings.Repeat("x", 40),
+		"owner":       strings.Repeat("y", 20),
+	}
+	total, offending := oversizedLabels(labels, 100)
+	assert.Equal(t, 4+8+11+40+5+20, total)
+	assert.Empty(t, offending)
+
+	total, offending = oversizedLabels(labels, 30)
+	assert.Equal(t, 88, total)
+	assert.Equal(t, []string{"description", "owner"}, offending)
+
+	err := (&AppLabelLimitConfig{PropagatedMaxSizeBytes: 30}).checkPropagatedLabelSize(labels)
+	assert.ErrorContains(t, err, "description, owner")
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/apiToken"
+)
+
+// ResolveLabelSource returns the source to record on the labels written by the user of email. Writes are ui or api
+// depending on whether the user is an api token, callers may declare import or template for their writes instead.
+// system is only set by internal flows and is rejected here
+func ResolveLabelSource(requested string, email string) (string, error) {
+	switch requested {
+	case pipelineConfig.AppLabelSourceImport, pipelineConfig.AppLabelSourceTemplate:
+		return requested, nil
+	case "", pipelineConfig.AppLabelSourceUi, pipelineConfig.AppLabelSourceApi:
+		if strings.HasPrefix(email, apiToken.API_TOKEN_USER_EMAIL_PREFIX) {
+			return pipelineConfig.AppLabelSourceApi, nil
+		}
+		return pipelineConfig.AppLabelSourceUi, nil
+	}
+	return "", fmt.Errorf("%w: %s can not be set by the request", util.ErrLabelSourceInvalid, requested)
+}
+
+func labelSourceOrUnknown(source string) string {
+	if len(source) == 0 {
+		return pipelineConfig.AppLabelSourceUnknown
+	}
+	return source
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveLabelSource(t *testing.T) {
+	tests := []struct {
+		requested string
+		email     string
+		want      string
+		wantErr   bool
+	}{
+		{requested: "", email: "admin@example.com", want: pipelineConfig.AppLabelSourceUi},
+		{requested: "", email: "API-TOKEN:ci-bot", want: pipelineConfig.AppLabelSourceApi},
+		{requested: pipelineConfig.AppLabelSourceUi, email: "API-TOKEN:ci-bot", want: pipelineConfig.AppLabelSourceApi},
+		{requested: pipelineConfig.AppLabelSourceImport, email: "API-TOKEN:ci-bot", want: pipelineConfig.AppLabelSourceImport},
+		{requested: pipelineConfig.AppLabelSourceTemplate, email: "admin@example.com", want: pipelineConfig.AppLabelSourceTemplate},
+		{requested: pipelineConfig.AppLabelSourceSystem, email: "admin@example.com", wantErr: true},
+		{requested: pipelineConfig.AppLabelSourceUnknown, email: "admin@example.com", wantErr: true},
+		{requested: "terraform", email: "admin@example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ResolveLabelSource(tt.requested, tt.email)
+		if tt.wantErr {
+			assert.True(t, errors.Is(err, util.ErrLabelSourceInvalid), tt.requested)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.requested)
+	}
+}
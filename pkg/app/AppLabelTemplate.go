@@ -0,0 +1,228 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	bean2 "github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/go-pg/pg"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (impl AppCrudOperationServiceImpl) CreateLabelTemplate(request *bean.LabelTemplateDto) (*bean.LabelTemplateDto, error) {
+	request.Name = strings.TrimSpace(request.Name)
+	if err := validateLabelTemplateEntries(request.Entries); err != nil {
+		return nil, err
+	}
+	_, err := impl.labelTemplateRepository.FindActiveByName(request.Name)
+	if err == nil {
+		message := fmt.Sprintf("label template %s already exists", request.Name)
+		return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, Code: strconv.Itoa(http.StatusConflict), UserMessage: message, InternalMessage: message}
+	} else if err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching label template", "error", err, "name", request.Name)
+		return nil, err
+	}
+	err = impl.transactionUtil.WithTx(context.Background(), func(tx *pg.Tx) error {
+		template := &pipelineConfig.LabelTemplate{Name: request.Name, Active: true}
+		template.CreatedBy = request.UserId
+		template.UpdatedBy = request.UserId
+		template.CreatedOn = time.Now()
+		template.UpdatedOn = time.Now()
+		err := impl.labelTemplateRepository.Create(template, tx)
+		if err != nil {
+			impl.logger.Errorw("error in creating label template", "error", err, "name", request.Name)
+			return err
+		}
+		var entries []*pipelineConfig.LabelTemplateEntry
+		for _, entry := range request.Entries {
+			model := &pipelineConfig.LabelTemplateEntry{
+				LabelTemplateId: template.Id,
+				Key:             strings.TrimSpace(entry.Key),
+				DefaultValue:    strings.TrimSpace(entry.DefaultValue),
+				Required:        entry.Required,
+			}
+			model.AuditLog = template.AuditLog
+			entries = append(entries, model)
+		}
+		err = impl.labelTemplateRepository.CreateEntries(entries, tx)
+		if err != nil {
+			impl.logger.Errorw("error in creating label template entries", "error", err, "name", request.Name)
+			return err
+		}
+		request.Id = template.Id
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ApplyTemplate applies the template as the system user, callers acting for a logged in user use ApplyTemplateForUser
+func (impl AppCrudOperationServiceImpl) ApplyTemplate(appId int, templateId int, overrides map[string]string) error {
+	return impl.ApplyTemplateForUser(appId, templateId, overrides, bean2.SYSTEM_USER_ID)
+}
+
+// ApplyTemplateForUser sets a label on the app for every key of the template, existing labels of the key get the value
+// of the template so that applying a template twice does not duplicate labels. The writes and their label events are
+// recorded as done by userId
+func (impl AppCrudOperationServiceImpl) ApplyTemplateForUser(appId int, templateId int, overrides map[string]string, userId int32) error {
+	_, err := impl.labelTemplateRepository.FindActiveById(templateId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching label template", "error", err, "templateId", templateId)
+		return err
+	}
+	entries, err := impl.labelTemplateRepository.FindEntriesByTemplateId(templateId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching label template entries", "error", err, "templateId", templateId)
+		return err
+	}
+	values, err := resolveTemplateLabels(entries, overrides)
+	if err != nil {
+		return err
+	}
+	err = impl.transactionUtil.WithTx(context.Background(), func(tx *pg.Tx) error {
+		app, err := impl.appRepository.FindById(appId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching app", "error", err, "appId", appId)
+			return err
+		}
+		// the app row is updated first so that concurrent label writes of the app are serialized with this one
+		app.UpdatedOn = time.Now()
+		app.UpdatedBy = userId
+		err = impl.appRepository.UpdateWithTxn(app, tx)
+		if err != nil {
+			impl.logger.Errorw("error in updating app", "error", err, "appId", appId)
+			return err
+		}
+		appLabels, err := impl.appLabelRepository.FindAllByAppId(appId)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in fetching app label", "error", err, "appId", appId)
+			return err
+		}
+		existing := make(map[string]*pipelineConfig.AppLabel)
+		for _, appLabel := range appLabels {
+			if appLabelType(appLabel.Type) == pipelineConfig.AppLabelTypeLabel {
+				existing[appLabel.Key] = appLabel
+			}
+		}
+		var events []*bean.LabelEvent
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := values[key]
+			if appLabel, ok := existing[key]; ok {
+				if appLabel.Value == value {
+					continue
+				}
+				if err := ValidateAppLabel(&bean.Label{Key: key, Value: value, Propagate: appLabel.Propagate, Type: appLabel.Type}); err != nil {
+					return err
+				}
+				event := newLabelEvent(LabelEventUpdated, appId, appLabel, userId, time.Now())
+				event.OldValue = appLabel.Value
+				event.Value = value
+				events = append(events, event)
+				appLabel.Value = value
+				appLabel.Source = pipelineConfig.AppLabelSourceTemplate
+				appLabel.UpdatedBy = userId
+				appLabel.UpdatedOn = time.Now()
+				_, err = impl.appLabelRepository.Update(appLabel, tx)
+				if err != nil {
+					impl.logger.Errorw("error in updating app label", "error", err, "appId", appId, "key", key)
+					return err
+				}
+				continue
+			}
+			model := &pipelineConfig.AppLabel{
+				Key:    key,
+				Value:  value,
+				AppId:  appId,
+				Source: pipelineConfig.AppLabelSourceTemplate,
+				Type:   pipelineConfig.AppLabelTypeLabel,
+			}
+			model.CreatedBy = userId
+			model.UpdatedBy = userId
+			model.CreatedOn = time.Now()
+			model.UpdatedOn = time.Now()
+			_, err = impl.appLabelRepository.Create(model, tx)
+			if err != nil {
+				impl.logger.Errorw("error in creating app label", "error", err, "appId", appId, "key", key)
+				return err
+			}
+			events = append(events, newLabelEvent(LabelEventCreated, appId, model, userId, model.CreatedOn))
+		}
+		count, err := impl.appLabelRepository.CountByAppId(appId, tx)
+		if err != nil {
+			impl.logger.Errorw("error in counting app labels", "error", err, "appId", appId)
+			return err
+		}
+		_, err = impl.labelLimitConfig.checkLabelCount(count)
+		if err != nil {
+			return err
+		}
+		return impl.labelEventPublisher.Publish(events, tx)
+	})
+	if err != nil {
+		return err
+	}
+	impl.InvalidateAppMetaInfo(appId)
+	return nil
+}
+
+func validateLabelTemplateEntries(entries []*bean.LabelTemplateEntryDto) error {
+	keys := make(map[string]bool)
+	for _, entry := range entries {
+		key := strings.TrimSpace(entry.Key)
+		if keys[key] {
+			return labelTemplateError(fmt.Sprintf("label key %s is repeated in the template", key))
+		}
+		keys[key] = true
+	}
+	return nil
+}
+
+// resolveTemplateLabels returns the value of every key of the template, overrides take precedence over defaults and
+// optional keys left without a value are skipped
+func resolveTemplateLabels(entries []*pipelineConfig.LabelTemplateEntry, overrides map[string]string) (map[string]string, error) {
+	templateKeys := make(map[string]bool)
+	for _, entry := range entries {
+		templateKeys[entry.Key] = true
+	}
+	for key := range overrides {
+		if !templateKeys[key] {
+			return nil, labelTemplateError(fmt.Sprintf("label key %s is not part of the template", key))
+		}
+	}
+	values := make(map[string]string)
+	var missing []string
+	for _, entry := range entries {
+		value := entry.DefaultValue
+		if override, ok := overrides[entry.Key]; ok {
+			value = strings.TrimSpace(override)
+		}
+		if len(value) == 0 {
+			if entry.Required {
+				missing = append(missing, entry.Key)
+			}
+			continue
+		}
+		values[entry.Key] = value
+	}
+	if len(missing) > 0 {
+		return nil, labelTemplateError(fmt.Sprintf("no value for required label keys %s", strings.Join(missing, ", ")))
+	}
+	return values, nil
+}
+
+func labelTemplateError(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: message, InternalMessage: message}
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTemplateLabels(t *testing.T) {
+	entries := []*pipelineConfig.LabelTemplateEntry{
+		{Key: "team", Required: true},
+		{Key: "cost-center", DefaultValue: "cc-0"},
+		{Key: "compliance-tier"},
+	}
+	values, err := resolveTemplateLabels(entries, map[string]string{"team": " payments "})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "payments", "cost-center": "cc-0"}, values)
+
+	_, err = resolveTemplateLabels(entries, map[string]string{"cost-center": "cc-1"})
+	assert.EqualError(t, err, "no value for required label keys team")
+
+	_, err = resolveTemplateLabels(entries, map[string]string{"team": "payments", "owner": "me"})
+	assert.EqualError(t, err, "label key owner is not part of the template")
+}
+
+func TestValidateLabelTemplateEntries(t *testing.T) {
+	assert.NoError(t, validateLabelTemplateEntries([]*bean.LabelTemplateEntryDto{{Key: "team"}, {Key: "cost-center"}}))
+	assert.Error(t, validateLabelTemplateEntries([]*bean.LabelTemplateEntryDto{{Key: "team"}, {Key: " team"}}))
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePropagatedLabel(t *testing.T) {
+	longValue := strings.Repeat("v", 100)
+	assert.Error(t, ValidatePropagatedLabel(&bean.Label{Key: "owner", Value: longValue, Propagate: true}))
+	assert.NoError(t, ValidatePropagatedLabel(&bean.Label{Key: "owner", Value: longValue, Propagate: true, Type: pipelineConfig.AppLabelTypeAnnotation}))
+	assert.Error(t, ValidatePropagatedLabel(&bean.Label{Key: "not a key", Value: "x", Propagate: true, Type: pipelineConfig.AppLabelTypeAnnotation}))
+	assert.NoError(t, ValidatePropagatedLabel(&bean.Label{Key: "not a key", Value: longValue, Propagate: true, Type: pipelineConfig.AppLabelTypeTag}))
+	assert.NoError(t, ValidatePropagatedLabel(&bean.Label{Key: "not a key", Value: longValue}))
+}
+
+func TestPropagatedMetadata(t *testing.T) {
+	labels, annotations := propagatedMetadata([]*bean.Label{
+		{Key: "team", Value: "payments", Propagate: true},
+		{Key: "description", Value: "handles card payments", Propagate: true, Type: pipelineConfig.AppLabelTypeAnnotation},
+		{Key: "cost-center", Value: "cc-42", Propagate: true, Type: pipelineConfig.AppLabelTypeTag},
+		{Key: "tier", Value: "1"},
+	})
+	assert.Equal(t, map[string]string{"team": "payments"}, labels)
+	assert.Equal(t, map[string]string{"description": "handles card payments"}, annotations)
+}
+
+func TestValidateAppLabelValueLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		label   *bean.Label
+		wantErr bool
+	}{
+		{name: "label at limit", label: &bean.Label{Key: "owner", Value: strings.Repeat("v", AppLabelValueMaxLength)}},
+		{name: "label above limit", label: &bean.Label{Key: "owner", Value: strings.Repeat("v", AppLabelValueMaxLength+1)}, wantErr: true},
+		{name: "tag above limit", label: &bean.Label{Key: "owner", Value: strings.Repeat("v", AppLabelValueMaxLength+1), Type: pipelineConfig.AppLabelTypeTag}, wantErr: true},
+		{name: "annotation above label limit", label: &bean.Label{Key: "description", Value: strings.Repeat("v", 4096), Propagate: true, Type: pipelineConfig.AppLabelTypeAnnotation}},
+		{name: "annotation above limit", label: &bean.Label{Key: "description", Value: strings.Repeat("v", AppAnnotationValueMaxLength+1), Type: pipelineConfig.AppLabelTypeAnnotation}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAppLabel(tt.label)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
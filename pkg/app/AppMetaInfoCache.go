@@ -0,0 +1,155 @@
+package app
+
+import (
+	"container/list"
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/singleflight"
+	"sync"
+	"time"
+)
+
+const (
+	appMetaInfoCacheName = "app-meta-info"
+	appLabelsCacheName   = "app-labels"
+	allAppLabelsCacheKey = "all"
+
+	// appMetaInfoCacheMaxTtlInSecs caps APP_META_INFO_CACHE_TTL_IN_SECS, see AppMetaInfoCacheConfig
+	appMetaInfoCacheMaxTtlInSecs = 30
+)
+
+var appMetaInfoCacheCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "orchestrator_app_meta_cache_requests_total",
+	Help: "app meta info and label cache lookups, partitioned by cache and result",
+}, []string{"cache", "result"})
+
+// AppMetaInfoCacheConfig configures the cache every orchestrator instance keeps for itself. A write only invalidates
+// the cache of the instance which made it, the other instances serve the old labels until their entry expires. So the
+// ttl is the bound on stale reads across instances and is capped at appMetaInfoCacheMaxTtlInSecs
+type AppMetaInfoCacheConfig struct {
+	Enabled   bool `env:"APP_META_INFO_CACHE_ENABLED" envDefault:"true"`
+	MaxSize   int  `env:"APP_META_INFO_CACHE_MAX_SIZE" envDefault:"1000"`
+	TtlInSecs int  `env:"APP_META_INFO_CACHE_TTL_IN_SECS" envDefault:"10"`
+}
+
+func GetAppMetaInfoCacheConfig() (*AppMetaInfoCacheConfig, error) {
+	config := &AppMetaInfoCacheConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+type cacheEntry struct {
+	key       string
+	value     interface{}
+	expiresOn time.Time
+}
+
+// appMetaInfoCache is a size bounded lru with ttl, concurrent misses for a key are collapsed into one load.
+// Every invalidation bumps generation, loads which started before it are neither stored nor shared with callers
+// arriving after it so that a value read before a write is never served once the write has committed
+type appMetaInfoCache struct {
+	config *AppMetaInfoCacheConfig
+	ttl    time.Duration
+	now    func() time.Time
+	group  singleflight.Group
+
+	mutex      sync.Mutex
+	generation uint64
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+func newAppMetaInfoCache(config *AppMetaInfoCacheConfig) *appMetaInfoCache {
+	ttlInSecs := config.TtlInSecs
+	if ttlInSecs > appMetaInfoCacheMaxTtlInSecs {
+		ttlInSecs = appMetaInfoCacheMaxTtlInSecs
+	}
+	return &appMetaInfoCache{
+		config:  config,
+		ttl:     time.Duration(ttlInSecs) * time.Second,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (impl *appMetaInfoCache) get(cacheName string, key string, load func() (interface{}, error)) (interface{}, error) {
+	if !impl.config.Enabled {
+		return load()
+	}
+	cacheKey := cacheName + "/" + key
+	value, found, generation := impl.lookup(cacheKey)
+	if found {
+		appMetaInfoCacheCounter.WithLabelValues(cacheName, "hit").Inc()
+		return value, nil
+	}
+	appMetaInfoCacheCounter.WithLabelValues(cacheName, "miss").Inc()
+	value, err, _ := impl.group.Do(fmt.Sprintf("%s#%d", cacheKey, generation), func() (interface{}, error) {
+		loaded, err := load()
+		if err != nil {
+			return nil, err
+		}
+		impl.store(cacheKey, loaded, generation)
+		return loaded, nil
+	})
+	return value, err
+}
+
+func (impl *appMetaInfoCache) lookup(cacheKey string) (interface{}, bool, uint64) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	element, ok := impl.entries[cacheKey]
+	if !ok {
+		return nil, false, impl.generation
+	}
+	entry := element.Value.(*cacheEntry)
+	if !impl.now().Before(entry.expiresOn) {
+		impl.lru.Remove(element)
+		delete(impl.entries, cacheKey)
+		return nil, false, impl.generation
+	}
+	impl.lru.MoveToFront(element)
+	return entry.value, true, impl.generation
+}
+
+func (impl *appMetaInfoCache) store(cacheKey string, value interface{}, generation uint64) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	if generation != impl.generation {
+		return
+	}
+	entry := &cacheEntry{key: cacheKey, value: value, expiresOn: impl.now().Add(impl.ttl)}
+	if element, ok := impl.entries[cacheKey]; ok {
+		element.Value = entry
+		impl.lru.MoveToFront(element)
+		return
+	}
+	impl.entries[cacheKey] = impl.lru.PushFront(entry)
+	for impl.lru.Len() > impl.config.MaxSize {
+		oldest := impl.lru.Back()
+		impl.lru.Remove(oldest)
+		delete(impl.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (impl *appMetaInfoCache) invalidate(cacheName string, keys ...string) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	impl.generation++
+	for _, key := range keys {
+		if element, ok := impl.entries[cacheName+"/"+key]; ok {
+			impl.lru.Remove(element)
+			delete(impl.entries, cacheName+"/"+key)
+		}
+	}
+}
+
+func (impl *appMetaInfoCache) invalidateAll() {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	impl.generation++
+	impl.entries = make(map[string]*list.Element)
+	impl.lru.Init()
+}
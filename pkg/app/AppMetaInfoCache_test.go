@@ -0,0 +1,238 @@
+package app
+
+import (
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/devtron-labs/devtron/pkg/user/repository"
+	"github.com/go-pg/pg"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type appRepositoryStub struct {
+	app.AppRepository
+	deployments []*app.AppDeploymentSummary
+}
+
+func (s *appRepositoryStub) FindAppAndProjectByAppId(appId int) (*app.App, error) {
+	return &app.App{Id: appId, AppName: "demo", Active: true}, nil
+}
+
+func (s *appRepositoryStub) FindDeploymentSummaryByAppId(appId int) ([]*app.AppDeploymentSummary, error) {
+	return s.deployments, nil
+}
+
+type appLabelRepositoryStub struct {
+	pipelineConfig.AppLabelRepository
+	mutex  sync.Mutex
+	labels []*pipelineConfig.AppLabel
+	loads  int32
+}
+
+func (s *appLabelRepositoryStub) setLabels(labels ...*pipelineConfig.AppLabel) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.labels = labels
+}
+
+func (s *appLabelRepositoryStub) FindAllByAppId(appId int) ([]*pipelineConfig.AppLabel, error) {
+	atomic.AddInt32(&s.loads, 1)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.labels, nil
+}
+
+func (s *appLabelRepositoryStub) FindAll() ([]*pipelineConfig.AppLabel, error) {
+	return s.FindAllByAppId(0)
+}
+
+type userRepositoryStub struct {
+	repository.UserRepository
+}
+
+func (s *userRepositoryStub) GetByIdIncludeDeleted(id int32) (*repository.UserModel, error) {
+	return nil, pg.ErrNoRows
+}
+
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestAppCrudOperationService(t *testing.T, config *AppMetaInfoCacheConfig) (*AppCrudOperationServiceImpl, *appLabelRepositoryStub, *fakeClock) {
+	logger, err := util.NewSugardLogger()
+	assert.Nil(t, err)
+	labelRepository := &appLabelRepositoryStub{}
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	cache := newAppMetaInfoCache(config)
+	cache.now = clock.Now
+	impl := &AppCrudOperationServiceImpl{
+		logger:             logger,
+		appLabelRepository: labelRepository,
+		appRepository:      &appRepositoryStub{},
+		userRepository:     &userRepositoryStub{},
+		appMetaInfoCache:   cache,
+		labelLimitConfig:   &AppLabelLimitConfig{},
+	}
+	return impl, labelRepository, clock
+}
+
+func labelKeys(labels []*bean.Label) []string {
+	keys := make([]string, 0)
+	for _, label := range labels {
+		keys = append(keys, label.Key)
+	}
+	return keys
+}
+
+func TestAppMetaInfoCache_InvalidatedOnLabelUpdate(t *testing.T) {
+	impl, labelRepository, _ := newTestAppCrudOperationService(t, &AppMetaInfoCacheConfig{Enabled: true, MaxSize: 10, TtlInSecs: 60})
+	labelRepository.setLabels(&pipelineConfig.AppLabel{Key: "team", Value: "payments"})
+
+	info, err := impl.GetAppMetaInfo(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"team"}, labelKeys(info.Labels))
+	_, err = impl.FindAll()
+	assert.Nil(t, err)
+
+	// the label update commits, UpdateApp invalidates right after the commit
+	labelRepository.setLabels(&pipelineConfig.AppLabel{Key: "owner", Value: "platform"})
+	impl.InvalidateAppMetaInfo(1)
+
+	info, err = impl.GetAppMetaInfo(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"owner"}, labelKeys(info.Labels))
+	labels, err := impl.FindAll()
+	assert.Nil(t, err)
+	assert.Len(t, labels, 1)
+	assert.Equal(t, "owner", labels[0].Key)
+}
+
+func TestAppMetaInfoCache_HitsAndCopies(t *testing.T) {
+	impl, labelRepository, clock := newTestAppCrudOperationService(t, &AppMetaInfoCacheConfig{Enabled: true, MaxSize: 10, TtlInSecs: 60})
+	labelRepository.setLabels(&pipelineConfig.AppLabel{Key: "team", Value: "payments"})
+
+	info, err := impl.GetAppMetaInfo(1)
+	assert.Nil(t, err)
+	// callers modifying the response must not change what is cached
+	info.Labels[0].Key = "modified"
+	info.Labels = nil
+	info, err = impl.GetAppMetaInfo(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"team"}, labelKeys(info.Labels))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&labelRepository.loads))
+
+	clock.Advance(61 * time.Second)
+	_, err = impl.GetAppMetaInfo(1)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&labelRepository.loads))
+}
+
+func TestAppMetaInfoCache_Lru(t *testing.T) {
+	impl, labelRepository, _ := newTestAppCrudOperationService(t, &AppMetaInfoCacheConfig{Enabled: true, MaxSize: 2, TtlInSecs: 60})
+	for _, appId := range []int{1, 2, 1, 3} {
+		_, err := impl.GetAppMetaInfo(appId)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&labelRepository.loads))
+	// app 2 was the least recently used and is evicted, app 1 is still cached
+	_, _ = impl.GetAppMetaInfo(1)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&labelRepository.loads))
+	_, _ = impl.GetAppMetaInfo(2)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&labelRepository.loads))
+}
+
+func TestAppMetaInfoCache_ConcurrentMissesAreCollapsed(t *testing.T) {
+	cache := newAppMetaInfoCache(&AppMetaInfoCacheConfig{Enabled: true, MaxSize: 10, TtlInSecs: 60})
+	release := make(chan struct{})
+	var loads int32
+	load := func() (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return "value", nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := cache.get(appMetaInfoCacheName, "1", load)
+			assert.Nil(t, err)
+			assert.Equal(t, "value", value)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+}
+
+func TestAppMetaInfoCache_LoadBeforeInvalidationIsNotStored(t *testing.T) {
+	cache := newAppMetaInfoCache(&AppMetaInfoCacheConfig{Enabled: true, MaxSize: 10, TtlInSecs: 60})
+	loaded := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _ = cache.get(appMetaInfoCacheName, "1", func() (interface{}, error) {
+			close(loaded)
+			<-release
+			return "stale", nil
+		})
+	}()
+	<-loaded
+	cache.invalidate(appMetaInfoCacheName, "1")
+	close(release)
+
+	value, err := cache.get(appMetaInfoCacheName, "1", func() (interface{}, error) {
+		return "fresh", nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "fresh", value)
+}
+
+func TestAppMetaInfoCache_TtlIsCapped(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttlInSecs int
+		wantTtl   time.Duration
+	}{
+		{name: "short ttl is kept", ttlInSecs: 5, wantTtl: 5 * time.Second},
+		{name: "long ttl is capped", ttlInSecs: 3600, wantTtl: appMetaInfoCacheMaxTtlInSecs * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newAppMetaInfoCache(&AppMetaInfoCacheConfig{Enabled: true, MaxSize: 10, TtlInSecs: tt.ttlInSecs})
+			now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+			cache.now = func() time.Time { return now }
+			var loads int32
+			load := func() (interface{}, error) {
+				atomic.AddInt32(&loads, 1)
+				return "value", nil
+			}
+			_, _ = cache.get(appMetaInfoCacheName, "1", load)
+			now = now.Add(tt.wantTtl - time.Second)
+			_, _ = cache.get(appMetaInfoCacheName, "1", load)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+			// an instance which did not make a write serves the old entry no longer than the ttl
+			now = now.Add(time.Second)
+			_, _ = cache.get(appMetaInfoCacheName, "1", load)
+			assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+		})
+	}
+}
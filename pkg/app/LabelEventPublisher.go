@@ -0,0 +1,306 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/go-pg/pg"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	LabelEventCreated = "label.created"
+	LabelEventUpdated = "label.updated"
+	LabelEventDeleted = "label.deleted"
+
+	labelEventTypeHeader  = "X-Devtron-Label-Event"
+	labelEventMaxBackoff  = time.Hour
+	labelEventLastErrSize = 1000
+)
+
+// LabelEventPublisherConfig controls the delivery of label events, a failed delivery is retried with an exponential
+// backoff starting at the poll interval till MaxAttempts deliveries have failed
+type LabelEventPublisherConfig struct {
+	PollIntervalSecs int `env:"LABEL_WEBHOOK_POLL_INTERVAL_SECS" envDefault:"10"`
+	MaxAttempts      int `env:"LABEL_WEBHOOK_MAX_ATTEMPTS" envDefault:"5"`
+	TimeoutSecs      int `env:"LABEL_WEBHOOK_TIMEOUT_SECS" envDefault:"5"`
+	BatchSize        int `env:"LABEL_WEBHOOK_BATCH_SIZE" envDefault:"50"`
+}
+
+func GetLabelEventPublisherConfig() (*LabelEventPublisherConfig, error) {
+	config := &LabelEventPublisherConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+type LabelEventPublisher interface {
+	RegisterWebhook(request *bean.LabelWebhookDto) (*bean.LabelWebhookDto, error)
+	// Publish writes the events to the outbox of every matching webhook on tx, so they are only delivered once the
+	// label change commits and are not lost when the delivery fails
+	Publish(events []*bean.LabelEvent, tx *pg.Tx) error
+}
+
+type LabelEventPublisherImpl struct {
+	logger                 *zap.SugaredLogger
+	labelWebhookRepository pipelineConfig.LabelWebhookRepository
+	config                 *LabelEventPublisherConfig
+	httpClient             *http.Client
+	deliveryCron           *cron.Cron
+	deliveryInProgress     int32
+	now                    func() time.Time
+}
+
+func NewLabelEventPublisherImpl(logger *zap.SugaredLogger, labelWebhookRepository pipelineConfig.LabelWebhookRepository) (*LabelEventPublisherImpl, error) {
+	config, err := GetLabelEventPublisherConfig()
+	if err != nil {
+		logger.Errorw("error in parsing label event publisher config", "err", err)
+		return nil, err
+	}
+	deliveryCron := cron.New(cron.WithChain())
+	impl := &LabelEventPublisherImpl{
+		logger:                 logger,
+		labelWebhookRepository: labelWebhookRepository,
+		config:                 config,
+		httpClient:             &http.Client{Timeout: time.Duration(config.TimeoutSecs) * time.Second},
+		deliveryCron:           deliveryCron,
+		now:                    time.Now,
+	}
+	deliveryCron.Start()
+	_, err = deliveryCron.AddFunc(fmt.Sprintf("@every %ds", config.PollIntervalSecs), impl.deliverDueEvents)
+	if err != nil {
+		logger.Errorw("error in adding label event delivery cron", "err", err)
+		return nil, err
+	}
+	return impl, nil
+}
+
+func (impl *LabelEventPublisherImpl) RegisterWebhook(request *bean.LabelWebhookDto) (*bean.LabelWebhookDto, error) {
+	model := &pipelineConfig.LabelWebhook{
+		Url:        strings.TrimSpace(request.Url),
+		EventTypes: strings.Join(request.EventTypes, ","),
+		Active:     true,
+	}
+	model.CreatedBy = request.UserId
+	model.UpdatedBy = request.UserId
+	model.CreatedOn = time.Now()
+	model.UpdatedOn = time.Now()
+	err := impl.labelWebhookRepository.Create(model)
+	if err != nil {
+		impl.logger.Errorw("error in creating label webhook", "err", err, "url", request.Url)
+		return nil, err
+	}
+	request.Id = model.Id
+	return request, nil
+}
+
+func (impl *LabelEventPublisherImpl) Publish(events []*bean.LabelEvent, tx *pg.Tx) error {
+	if len(events) == 0 {
+		return nil
+	}
+	webhooks, err := impl.labelWebhookRepository.FindAllActive()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching label webhooks", "err", err)
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+	now := impl.now()
+	var outboxEvents []*pipelineConfig.LabelEventOutbox
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			impl.logger.Errorw("error in marshalling label event", "err", err, "appId", event.AppId)
+			return err
+		}
+		for _, webhook := range webhooks {
+			if !labelEventTypeMatches(webhook.EventTypes, event.EventType) {
+				continue
+			}
+			outboxEvents = append(outboxEvents, &pipelineConfig.LabelEventOutbox{
+				WebhookId:     webhook.Id,
+				EventType:     event.EventType,
+				Payload:       string(payload),
+				Status:        pipelineConfig.LabelEventStatusPending,
+				NextAttemptOn: now,
+				CreatedOn:     now,
+				UpdatedOn:     now,
+			})
+		}
+	}
+	err = impl.labelWebhookRepository.CreateOutboxEvents(outboxEvents, tx)
+	if err != nil {
+		impl.logger.Errorw("error in writing label events to outbox", "err", err)
+	}
+	return err
+}
+
+func (impl *LabelEventPublisherImpl) deliverDueEvents() {
+	if !atomic.CompareAndSwapInt32(&impl.deliveryInProgress, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&impl.deliveryInProgress, 0)
+	now := impl.now()
+	// the lease outlasts the delivery of the whole batch so that other instances do not pick the events meanwhile
+	lease := time.Duration(impl.config.BatchSize+1) * time.Duration(impl.config.TimeoutSecs) * time.Second
+	events, err := impl.labelWebhookRepository.ClaimDueOutboxEvents(impl.config.BatchSize, now, now.Add(lease))
+	if err != nil {
+		impl.logger.Errorw("error in claiming due label events", "err", err)
+		return
+	}
+	if len(events) == 0 {
+		return
+	}
+	webhookIds := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, event := range events {
+		if !seen[event.WebhookId] {
+			seen[event.WebhookId] = true
+			webhookIds = append(webhookIds, event.WebhookId)
+		}
+	}
+	webhooks, err := impl.labelWebhookRepository.FindByIds(webhookIds)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching label webhooks", "err", err, "ids", webhookIds)
+		return
+	}
+	webhookMap := make(map[int]*pipelineConfig.LabelWebhook)
+	for _, webhook := range webhooks {
+		webhookMap[webhook.Id] = webhook
+	}
+	for _, event := range events {
+		webhook, ok := webhookMap[event.WebhookId]
+		if !ok || !webhook.Active {
+			event.Status = pipelineConfig.LabelEventStatusFailed
+			event.LastError = "webhook is not active"
+		} else {
+			impl.applyDeliveryResult(event, impl.post(webhook.Url, event))
+		}
+		event.UpdatedOn = impl.now()
+		err = impl.labelWebhookRepository.UpdateOutboxEvent(event)
+		if err != nil {
+			impl.logger.Errorw("error in updating label event", "err", err, "id", event.Id)
+		}
+	}
+}
+
+func (impl *LabelEventPublisherImpl) post(url string, event *pipelineConfig.LabelEventOutbox) error {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(event.Payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(labelEventTypeHeader, event.EventType)
+	response, err := impl.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// applyDeliveryResult marks the event delivered, or schedules its retry till it runs out of attempts
+func (impl *LabelEventPublisherImpl) applyDeliveryResult(event *pipelineConfig.LabelEventOutbox, deliveryErr error) {
+	event.Attempts++
+	if deliveryErr == nil {
+		event.Status = pipelineConfig.LabelEventStatusDelivered
+		event.LastError = ""
+		return
+	}
+	impl.logger.Warnw("error in delivering label event", "err", deliveryErr, "id", event.Id, "attempts", event.Attempts)
+	event.LastError = deliveryErr.Error()
+	if len(event.LastError) > labelEventLastErrSize {
+		event.LastError = event.LastError[:labelEventLastErrSize]
+	}
+	if event.Attempts >= impl.config.MaxAttempts {
+		event.Status = pipelineConfig.LabelEventStatusFailed
+		return
+	}
+	event.NextAttemptOn = impl.now().Add(labelEventBackoff(event.Attempts, time.Duration(impl.config.PollIntervalSecs)*time.Second))
+}
+
+// labelEventBackoff doubles the wait after every failed attempt, capped at an hour
+func labelEventBackoff(attempts int, interval time.Duration) time.Duration {
+	backoff := interval
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= labelEventMaxBackoff {
+			return labelEventMaxBackoff
+		}
+	}
+	return backoff
+}
+
+func labelEventTypeMatches(eventTypes string, eventType string) bool {
+	if len(strings.TrimSpace(eventTypes)) == 0 {
+		return true
+	}
+	for _, filter := range strings.Split(eventTypes, ",") {
+		if strings.TrimSpace(filter) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// labelChangeEvents turns the labels created and deleted by a sync into events, a key of a type which is both deleted
+// and created is reported as an update of its value
+func labelChangeEvents(appId int, created []*pipelineConfig.AppLabel, deleted []*pipelineConfig.AppLabel, userId int32, now time.Time) []*bean.LabelEvent {
+	labelId := func(label *pipelineConfig.AppLabel) string {
+		return appLabelType(label.Type) + "/" + label.Key
+	}
+	createdCount := make(map[string]int)
+	for _, label := range created {
+		createdCount[labelId(label)]++
+	}
+	deletedCount := make(map[string]int)
+	for _, label := range deleted {
+		deletedCount[labelId(label)]++
+	}
+	isUpdate := func(label *pipelineConfig.AppLabel) bool {
+		return createdCount[labelId(label)] == 1 && deletedCount[labelId(label)] == 1
+	}
+	oldValues := make(map[string]string)
+	var events []*bean.LabelEvent
+	for _, label := range deleted {
+		if isUpdate(label) {
+			oldValues[labelId(label)] = label.Value
+			continue
+		}
+		events = append(events, newLabelEvent(LabelEventDeleted, appId, label, userId, now))
+	}
+	for _, label := range created {
+		if isUpdate(label) {
+			event := newLabelEvent(LabelEventUpdated, appId, label, userId, now)
+			event.OldValue = oldValues[labelId(label)]
+			events = append(events, event)
+			continue
+		}
+		events = append(events, newLabelEvent(LabelEventCreated, appId, label, userId, now))
+	}
+	return events
+}
+
+func newLabelEvent(eventType string, appId int, label *pipelineConfig.AppLabel, userId int32, now time.Time) *bean.LabelEvent {
+	return &bean.LabelEvent{
+		EventType: eventType,
+		AppId:     appId,
+		Key:       label.Key,
+		Value:     label.Value,
+		Type:      appLabelType(label.Type),
+		UserId:    userId,
+		Time:      now,
+	}
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelEventTypeMatches(t *testing.T) {
+	assert.True(t, labelEventTypeMatches("", LabelEventCreated))
+	assert.True(t, labelEventTypeMatches("label.created, label.deleted", LabelEventDeleted))
+	assert.False(t, labelEventTypeMatches("label.created", LabelEventUpdated))
+}
+
+func TestLabelEventBackoff(t *testing.T) {
+	interval := 10 * time.Second
+	assert.Equal(t, 10*time.Second, labelEventBackoff(1, interval))
+	assert.Equal(t, 40*time.Second, labelEventBackoff(3, interval))
+	assert.Equal(t, labelEventMaxBackoff, labelEventBackoff(20, interval))
+}
+
+func TestLabelChangeEvents(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	created := []*pipelineConfig.AppLabel{
+		{Key: "team", Value: "payments", Type: pipelineConfig.AppLabelTypeLabel},
+		{Key: "tier", Value: "gold", Type: pipelineConfig.AppLabelTypeLabel},
+	}
+	deleted := []*pipelineConfig.AppLabel{
+		{Key: "team", Value: "billing", Type: pipelineConfig.AppLabelTypeLabel},
+		{Key: "owner", Value: "alice", Type: pipelineConfig.AppLabelTypeLabel},
+	}
+	events := labelChangeEvents(7, created, deleted, 2, now)
+	assert.Len(t, events, 3)
+	assert.Equal(t, LabelEventDeleted, events[0].EventType)
+	assert.Equal(t, "owner", events[0].Key)
+	assert.Equal(t, LabelEventUpdated, events[1].EventType)
+	assert.Equal(t, "payments", events[1].Value)
+	assert.Equal(t, "billing", events[1].OldValue)
+	assert.Equal(t, LabelEventCreated, events[2].EventType)
+	assert.Equal(t, 7, events[2].AppId)
+}
+
+func TestApplyDeliveryResult(t *testing.T) {
+	logger, err := util.NewSugardLogger()
+	assert.Nil(t, err)
+	now := time.Unix(1700000000, 0)
+	impl := &LabelEventPublisherImpl{
+		logger: logger,
+		config: &LabelEventPublisherConfig{PollIntervalSecs: 10, MaxAttempts: 2},
+		now:    func() time.Time { return now },
+	}
+	event := &pipelineConfig.LabelEventOutbox{Status: pipelineConfig.LabelEventStatusPending}
+	impl.applyDeliveryResult(event, errors.New("connection refused"))
+	assert.Equal(t, pipelineConfig.LabelEventStatusPending, event.Status)
+	assert.Equal(t, now.Add(10*time.Second), event.NextAttemptOn)
+	impl.applyDeliveryResult(event, errors.New("connection refused"))
+	assert.Equal(t, pipelineConfig.LabelEventStatusFailed, event.Status)
+
+	event = &pipelineConfig.LabelEventOutbox{Status: pipelineConfig.LabelEventStatusPending}
+	impl.applyDeliveryResult(event, nil)
+	assert.Equal(t, pipelineConfig.LabelEventStatusDelivered, event.Status)
+	assert.Equal(t, 1, event.Attempts)
+}
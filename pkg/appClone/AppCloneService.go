@@ -189,10 +189,11 @@ func (impl *AppCloneServiceImpl) CloneApp(createReq *bean.CreateAppDTO, context
 
 func (impl *AppCloneServiceImpl) CreateApp(cloneReq *CloneRequest, userId int32) (*bean.CreateAppDTO, error) {
 	createAppReq := &bean.CreateAppDTO{
-		AppName:   cloneReq.Name,
-		UserId:    userId,
-		TeamId:    cloneReq.ProjectId,
-		AppLabels: cloneReq.AppLabels,
+		AppName:     cloneReq.Name,
+		UserId:      userId,
+		TeamId:      cloneReq.ProjectId,
+		AppLabels:   cloneReq.AppLabels,
+		LabelSource: pipelineConfig.AppLabelSourceTemplate,
 	}
 	createRes, err := impl.pipelineBuilder.CreateApp(createAppReq)
 	return createRes, err
@@ -45,6 +45,10 @@ type CreateAppDTO struct {
 	TeamId     int            `json:"teamId,omitempty" validate:"number,required"`
 	TemplateId int            `json:"templateId"`
 	AppLabels  []*Label       `json:"labels,omitempty" validate:"dive"`
+	// LabelSource is recorded on the labels created by the request, see app.ResolveLabelSource
+	LabelSource string `json:"labelSource,omitempty"`
+	// LabelWarning is set when the app is above the soft limit of labels after the update
+	LabelWarning string `json:"labelWarning,omitempty"`
 }
 
 type CreateMaterialDTO struct {
@@ -586,13 +590,17 @@ type AppLabelDto struct {
 	Value     string `json:"value,notnull"`
 	Propagate bool   `json:"propagate,notnull"`
 	AppId     int    `json:"appId,omitempty"`
+	Source    string `json:"source,omitempty"`
+	Type      string `json:"type,omitempty"`
 	UserId    int32  `json:"-"`
 }
 
 type Label struct {
-	Key       string `json:"key" validate:"required"`
-	Value     string `json:"value" validate:"required"`
+	Key       string `json:"key" validate:"required,max=255"`
+	Value     string `json:"value" validate:"required,max=65536"`
 	Propagate bool   `json:"propagate"`
+	// Type is label when not set, max is the limit of annotations and app.ValidateAppLabel checks the limit of the type
+	Type string `json:"type,omitempty" validate:"omitempty,oneof=label annotation tag"`
 }
 
 type AppMetaInfoDto struct {
@@ -605,18 +613,145 @@ type AppMetaInfoDto struct {
 	Active      bool      `json:"active,notnull"`
 	Labels      []*Label  `json:"labels"`
 	UserId      int32     `json:"-"`
+	// Deployments is only set when requested with expand=deployments
+	Deployments []*AppEnvironmentDeployment `json:"deployments,omitempty"`
+}
+
+type AppEnvironmentDeployment struct {
+	EnvironmentId         int        `json:"environmentId"`
+	EnvironmentName       string     `json:"environmentName"`
+	EnvironmentIdentifier string     `json:"-"`
+	ClusterId             int        `json:"clusterId"`
+	ClusterName           string     `json:"clusterName"`
+	Namespace             string     `json:"namespace"`
+	LastDeployedOn        *time.Time `json:"lastDeployedOn,omitempty"`
+	LastDeploymentStatus  string     `json:"lastDeploymentStatus,omitempty"`
+}
+
+type AppLabelCountDto struct {
+	AppId      int    `json:"appId"`
+	AppName    string `json:"appName"`
+	TeamId     int    `json:"teamId"`
+	LabelCount int    `json:"labelCount"`
+}
+
+// AppLabelFeedRequest reads a page of the labels of all apps for external systems. Cursor continues from the previous
+// page, UpdatedAfter starts an incremental sync of the labels changed after it instead of a full walk
+type AppLabelFeedRequest struct {
+	Cursor       string
+	Limit        int
+	UpdatedAfter *time.Time
+}
+
+// AppLabelFeedItem is a label of the feed, Deleted is set when the label was removed from the app
+type AppLabelFeedItem struct {
+	Id        int       `json:"id"`
+	AppId     int       `json:"appId"`
+	AppName   string    `json:"appName"`
+	TeamId    int       `json:"teamId"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	Type      string    `json:"type"`
+	Propagate bool      `json:"propagate"`
+	UpdatedOn time.Time `json:"updatedOn"`
+	Deleted   bool      `json:"deleted"`
+}
+
+// AppLabelFeedResponse always carries NextCursor, after the last page it is polled for the labels changed since
+type AppLabelFeedResponse struct {
+	Labels     []*AppLabelFeedItem `json:"labels"`
+	NextCursor string              `json:"nextCursor"`
+	HasMore    bool                `json:"hasMore"`
+}
+
+// LabelTemplateDto is a named set of label keys applied to apps in bulk, required keys must get a value from the
+// default or an override when the template is applied
+type LabelTemplateDto struct {
+	Id      int                      `json:"id"`
+	Name    string                   `json:"name" validate:"required,max=250"`
+	Entries []*LabelTemplateEntryDto `json:"entries" validate:"required,min=1,dive"`
+	UserId  int32                    `json:"-"`
+}
+
+type LabelTemplateEntryDto struct {
+	Key          string `json:"key" validate:"required,max=255"`
+	DefaultValue string `json:"defaultValue" validate:"max=255"`
+	Required     bool   `json:"required"`
+}
+
+// LabelWebhookDto registers a url notified of app label changes, no event types subscribes to all label events
+type LabelWebhookDto struct {
+	Id         int      `json:"id"`
+	Url        string   `json:"url" validate:"required,url,max=2000"`
+	EventTypes []string `json:"eventTypes" validate:"dive,oneof=label.created label.updated label.deleted"`
+	UserId     int32    `json:"-"`
+}
+
+// LabelEvent is the payload posted to label webhooks
+type LabelEvent struct {
+	EventType string    `json:"eventType"`
+	AppId     int       `json:"appId"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	OldValue  string    `json:"oldValue,omitempty"`
+	Type      string    `json:"type"`
+	UserId    int32     `json:"userId"`
+	Time      time.Time `json:"time"`
 }
 
 type AppLabelsJsonForDeployment struct {
-	Labels map[string]string `json:"appLabels"`
+	Labels      map[string]string `json:"appLabels"`
+	Annotations map[string]string `json:"appAnnotations,omitempty"`
 }
 
 type UpdateProjectBulkAppsRequest struct {
-	AppIds []int `json:"appIds"`
-	TeamId int   `json:"teamId"`
+	AppIds []int `json:"appIds" validate:"required,min=1"`
+	TeamId int   `json:"teamId" validate:"number,gt=0"`
 	UserId int32 `json:"-"`
 }
 
+// AppLabelBulkRequest adds the labels to every app, labels the app already has are kept. LabelSource is resolved like
+// in CreateAppDTO
+type AppLabelBulkRequest struct {
+	AppIds      []int    `json:"appIds" validate:"required,min=1"`
+	Labels      []*Label `json:"labels" validate:"required,min=1,dive"`
+	LabelSource string   `json:"labelSource,omitempty"`
+	UserId      int32    `json:"-"`
+}
+
+// AppLabelBulkResult is the outcome for an app of a bulk label request, Warning is set when the app ends up above the
+// soft limit of labels
+type AppLabelBulkResult struct {
+	AppId   int    `json:"appId"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	Warning string `json:"warning,omitempty"`
+}
+
+type AppLabelBulkResponse struct {
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []*AppLabelBulkResult `json:"results"`
+}
+
+// AppLabelBulkDeleteRequest removes the labels of every type having one of the keys from the apps
+type AppLabelBulkDeleteRequest struct {
+	AppIds []int    `json:"appIds" validate:"required,min=1"`
+	Keys   []string `json:"keys" validate:"required,min=1,dive,required"`
+	UserId int32    `json:"-"`
+}
+
+// AppLabelBulkDeleteResult is the number of labels removed from an app, apps without matching labels have 0
+type AppLabelBulkDeleteResult struct {
+	AppId   int `json:"appId"`
+	Deleted int `json:"deleted"`
+}
+
+type AppLabelBulkDeleteResponse struct {
+	Deleted int                         `json:"deleted"`
+	Results []*AppLabelBulkDeleteResult `json:"results"`
+}
+
 type CdBulkAction int
 
 const (
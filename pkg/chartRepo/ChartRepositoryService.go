@@ -19,12 +19,14 @@ package chartRepo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/devtron-labs/devtron/internal/sql/repository"
 	"github.com/devtron-labs/devtron/internal/util"
 	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
 	"github.com/devtron-labs/devtron/pkg/cluster"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
 	serverEnvConfig "github.com/devtron-labs/devtron/pkg/server/config"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	util2 "github.com/devtron-labs/devtron/pkg/util"
@@ -51,30 +53,35 @@ type ChartRepositoryService interface {
 	ValidateChartRepo(request *ChartRepoDto) *DetailedErrorHelmRepoValidation
 	ValidateAndCreateChartRepo(request *ChartRepoDto) (*chartRepoRepository.ChartRepo, error, *DetailedErrorHelmRepoValidation)
 	ValidateAndUpdateChartRepo(request *ChartRepoDto) (*chartRepoRepository.ChartRepo, error, *DetailedErrorHelmRepoValidation)
-	TriggerChartSyncManual() error
+	TriggerChartSyncManual(ctx context.Context) error
+	// TriggerChartSyncManualAsync recreates the chart sync job in the background and returns the id of the tracking operation
+	TriggerChartSyncManualAsync(ctx context.Context, userId int32) (int, error)
 	DeleteChartRepo(request *ChartRepoDto) error
 }
 
 type ChartRepositoryServiceImpl struct {
-	logger          *zap.SugaredLogger
-	repoRepository  chartRepoRepository.ChartRepoRepository
-	K8sUtil         *util.K8sUtil
-	clusterService  cluster.ClusterService
-	aCDAuthConfig   *util2.ACDAuthConfig
-	client          *http.Client
-	serverEnvConfig *serverEnvConfig.ServerEnvConfig
+	logger                  *zap.SugaredLogger
+	repoRepository          chartRepoRepository.ChartRepoRepository
+	K8sUtil                 *util.K8sUtil
+	clusterService          cluster.ClusterService
+	aCDAuthConfig           *util2.ACDAuthConfig
+	client                  *http.Client
+	serverEnvConfig         *serverEnvConfig.ServerEnvConfig
+	clusterOperationService clusterOperation.ClusterOperationService
 }
 
 func NewChartRepositoryServiceImpl(logger *zap.SugaredLogger, repoRepository chartRepoRepository.ChartRepoRepository, K8sUtil *util.K8sUtil, clusterService cluster.ClusterService,
-	aCDAuthConfig *util2.ACDAuthConfig, client *http.Client, serverEnvConfig *serverEnvConfig.ServerEnvConfig) *ChartRepositoryServiceImpl {
+	aCDAuthConfig *util2.ACDAuthConfig, client *http.Client, serverEnvConfig *serverEnvConfig.ServerEnvConfig,
+	clusterOperationService clusterOperation.ClusterOperationService) *ChartRepositoryServiceImpl {
 	return &ChartRepositoryServiceImpl{
-		logger:          logger,
-		repoRepository:  repoRepository,
-		K8sUtil:         K8sUtil,
-		clusterService:  clusterService,
-		aCDAuthConfig:   aCDAuthConfig,
-		client:          client,
-		serverEnvConfig: serverEnvConfig,
+		logger:                  logger,
+		repoRepository:          repoRepository,
+		K8sUtil:                 K8sUtil,
+		clusterService:          clusterService,
+		aCDAuthConfig:           aCDAuthConfig,
+		client:                  client,
+		serverEnvConfig:         serverEnvConfig,
+		clusterOperationService: clusterOperationService,
 	}
 }
 
@@ -340,7 +347,7 @@ func (impl *ChartRepositoryServiceImpl) ValidateAndCreateChartRepo(request *Char
 	}
 
 	// Trigger chart sync job, ignore error
-	err = impl.TriggerChartSyncManual()
+	err = impl.TriggerChartSyncManual(context.Background())
 	if err != nil {
 		impl.logger.Errorw("Error in triggering chart sync job manually ", "err", err)
 	}
@@ -359,7 +366,7 @@ func (impl *ChartRepositoryServiceImpl) ValidateAndUpdateChartRepo(request *Char
 	}
 
 	// Trigger chart sync job, ignore error
-	err = impl.TriggerChartSyncManual()
+	err = impl.TriggerChartSyncManual(context.Background())
 	if err != nil {
 		impl.logger.Errorw("Error in triggering chart sync job manually", "err", err)
 	}
@@ -367,7 +374,26 @@ func (impl *ChartRepositoryServiceImpl) ValidateAndUpdateChartRepo(request *Char
 	return chartRepo, err, validationResult
 }
 
-func (impl *ChartRepositoryServiceImpl) TriggerChartSyncManual() error {
+func (impl *ChartRepositoryServiceImpl) TriggerChartSyncManualAsync(ctx context.Context, userId int32) (int, error) {
+	defaultClusterBean, err := impl.clusterService.FindOne(cluster.DefaultClusterName)
+	if err != nil {
+		impl.logger.Errorw("defaultClusterBean err, TriggerChartSyncManualAsync", "err", err)
+		return 0, err
+	}
+	operation := &clusterOperation.ClusterOperationBean{
+		OperationType: clusterOperation.OperationTypeChartSync,
+		ClusterId:     defaultClusterBean.Id,
+		Namespace:     impl.aCDAuthConfig.ACDConfigMapNamespace,
+		ResourceName:  "app-manual-sync-job",
+		UserId:        userId,
+	}
+	return impl.clusterOperationService.Start(ctx, operation, func(ctx context.Context, progress clusterOperation.ProgressFunc) error {
+		progress("recreating chart sync job")
+		return impl.TriggerChartSyncManual(ctx)
+	})
+}
+
+func (impl *ChartRepositoryServiceImpl) TriggerChartSyncManual(ctx context.Context) error {
 	defaultClusterBean, err := impl.clusterService.FindOne(cluster.DefaultClusterName)
 	if err != nil {
 		impl.logger.Errorw("defaultClusterBean err, TriggerChartSyncManual", "err", err)
@@ -382,7 +408,7 @@ func (impl *ChartRepositoryServiceImpl) TriggerChartSyncManual() error {
 
 	manualAppSyncJobByteArr := manualAppSyncJobByteArr(impl.serverEnvConfig.AppSyncImage, impl.serverEnvConfig.AppSyncJobResourcesObj)
 
-	err = impl.K8sUtil.DeleteAndCreateJob(manualAppSyncJobByteArr, impl.aCDAuthConfig.ACDConfigMapNamespace, defaultClusterConfig)
+	err = impl.K8sUtil.DeleteAndCreateJob(ctx, manualAppSyncJobByteArr, impl.aCDAuthConfig.ACDConfigMapNamespace, defaultClusterConfig)
 	if err != nil {
 		impl.logger.Errorw("DeleteAndCreateJob err, TriggerChartSyncManual", "err", err)
 		return err
@@ -0,0 +1,26 @@
+package cluster
+
+import "github.com/devtron-labs/devtron/internal/util"
+
+// ClusterHealthBean is the connection status found by the periodic connectivity check along with the circuit breaker
+// guarding calls to the cluster, an open breaker means calls fail fast until RetryAfter of the breaker
+type ClusterHealthBean struct {
+	ClusterId         int                       `json:"clusterId"`
+	ClusterName       string                    `json:"clusterName"`
+	ErrorInConnecting string                    `json:"errorInConnecting,omitempty"`
+	CircuitBreaker    *util.ClusterCircuitState `json:"circuitBreaker"`
+}
+
+func (impl *ClusterServiceImpl) GetClusterHealth(clusterId int) (*ClusterHealthBean, error) {
+	model, err := impl.clusterRepository.FindById(clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cluster", "err", err, "clusterId", clusterId)
+		return nil, err
+	}
+	return &ClusterHealthBean{
+		ClusterId:         model.Id,
+		ClusterName:       model.ClusterName,
+		ErrorInConnecting: model.ErrorInConnecting,
+		CircuitBreaker:    impl.K8sUtil.GetClusterCircuitState(model.Id),
+	}, nil
+}
@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"context"
+	"time"
+
+	bean2 "github.com/devtron-labs/devtron/api/bean"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FindNamespacesForPicker serves the namespaces of the cluster from the watch cache. They are listed live when refresh
+// is set, when the cluster is not cached yet or when its watch has been failing for longer than the max staleness, a
+// failing live list falls back to the cached namespaces so that pickers keep working while a cluster is unreachable
+func (impl *ClusterServiceImpl) FindNamespacesForPicker(userId int32, clusterId int, isActionUserSuperAdmin bool, refresh bool) (*bean2.CachedNamespaces, error) {
+	clusterBean, err := impl.FindById(clusterId)
+	if err != nil {
+		impl.logger.Errorw("failed to find cluster for id", "error", err, "clusterId", clusterId)
+		return nil, err
+	}
+	cachedNamespaces, cacheErr := impl.K8sInformerFactory.GetCachedNamespaces(clusterId)
+	namespaces := cachedNamespaces
+	if refresh || cacheErr != nil || impl.K8sInformerFactory.IsNamespaceCacheExpired(cachedNamespaces, time.Now()) {
+		namespaces, err = impl.listNamespacesLive(clusterBean)
+		if err != nil {
+			if cacheErr != nil {
+				return nil, err
+			}
+			impl.logger.Warnw("error in listing namespaces live, serving cached namespaces", "err", err, "clusterId", clusterId)
+			namespaces = cachedNamespaces
+		}
+	}
+	if isActionUserSuperAdmin {
+		return namespaces, nil
+	}
+	isAllowed, err := impl.namespaceAccessFilter(userId, clusterBean.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make([]*bean2.NamespaceInfo, 0, len(namespaces.Namespaces))
+	for _, namespace := range namespaces.Namespaces {
+		if isAllowed(namespace.Name) {
+			allowed = append(allowed, namespace)
+		}
+	}
+	filtered := *namespaces
+	filtered.Namespaces = allowed
+	return &filtered, nil
+}
+
+func (impl *ClusterServiceImpl) listNamespacesLive(clusterBean *ClusterBean) (*bean2.CachedNamespaces, error) {
+	clusterConfig, err := impl.GetClusterConfig(clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster config", "err", err, "clusterId", clusterBean.Id)
+		return nil, err
+	}
+	clientSet, err := impl.K8sUtil.GetClientSet(clusterConfig)
+	if err != nil {
+		impl.logger.Errorw("error in getting client set", "err", err, "clusterId", clusterBean.Id)
+		return nil, err
+	}
+	namespaceList, err := clientSet.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		impl.logger.Errorw("error in listing namespaces", "err", err, "clusterId", clusterBean.Id)
+		return nil, err
+	}
+	namespaces := make([]*bean2.NamespaceInfo, 0, len(namespaceList.Items))
+	for _, namespace := range namespaceList.Items {
+		namespaces = append(namespaces, &bean2.NamespaceInfo{Name: namespace.Name, Labels: namespace.Labels})
+	}
+	return &bean2.CachedNamespaces{ClusterId: clusterBean.Id, Namespaces: namespaces, SyncedOn: time.Now(), Live: true}, nil
+}
+
+// namespaceAccessFilter tells whether the roles of the user give access to a namespace of the cluster
+func (impl *ClusterServiceImpl) namespaceAccessFilter(userId int32, clusterName string) (func(namespace string) bool, error) {
+	roles, err := impl.FetchRolesFromGroup(userId)
+	if err != nil {
+		impl.logger.Errorw("error on fetching user roles for cluster list", "err", err)
+		return nil, err
+	}
+	allowedAll := false
+	allowedNamespaceMap := make(map[string]bool)
+	for _, role := range roles {
+		if clusterName == role.Cluster {
+			allowedNamespaceMap[role.Namespace] = true
+			if role.Namespace == "" {
+				allowedAll = true
+			}
+		}
+	}
+	return func(namespace string) bool {
+		return allowedAll || allowedNamespaceMap[namespace]
+	}, nil
+}
@@ -0,0 +1,123 @@
+package cluster
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/patrickmn/go-cache"
+)
+
+// namespacePolicyCacheExpiry bounds how long a changed policy takes to reach the other instances, every devtron
+// mutation looks the policy up so it is not read from the db each time
+const namespacePolicyCacheExpiry = 30 * time.Second
+
+// ClusterNamespacePolicyBean is the allowlist or denylist of namespaces devtron may create resources in, patterns are
+// regular expressions matching the whole namespace name. Reads are only restricted with RestrictReads
+type ClusterNamespacePolicyBean struct {
+	ClusterId     int      `json:"clusterId"`
+	Mode          string   `json:"mode,omitempty" validate:"omitempty,oneof=allow deny"`
+	Patterns      []string `json:"patterns,omitempty" validate:"max=100,dive,min=1,max=253"`
+	RestrictReads bool     `json:"restrictReads"`
+}
+
+func newNamespacePolicyCache() *cache.Cache {
+	return cache.New(namespacePolicyCacheExpiry, 5*time.Minute)
+}
+
+func (impl *ClusterServiceImpl) GetNamespacePolicy(clusterId int) (*ClusterNamespacePolicyBean, error) {
+	model, err := impl.clusterRepository.FindById(clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cluster", "err", err, "clusterId", clusterId)
+		return nil, err
+	}
+	return &ClusterNamespacePolicyBean{
+		ClusterId:     model.Id,
+		Mode:          model.NamespacePolicyMode,
+		Patterns:      model.NamespacePolicyPatterns,
+		RestrictReads: model.NamespacePolicyRestrictReads,
+	}, nil
+}
+
+func (impl *ClusterServiceImpl) UpdateNamespacePolicy(bean *ClusterNamespacePolicyBean, userId int32) (*ClusterNamespacePolicyBean, error) {
+	patterns := make([]string, 0, len(bean.Patterns))
+	for _, pattern := range bean.Patterns {
+		if pattern = strings.TrimSpace(pattern); len(pattern) > 0 {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if _, err := util.CompileNamespacePatterns(patterns); err != nil {
+		return nil, newNamespacePolicyValidationError(err.Error())
+	}
+	if len(bean.Mode) > 0 && len(patterns) == 0 {
+		return nil, newNamespacePolicyValidationError("at least one namespace pattern is required for mode " + bean.Mode)
+	}
+	if len(bean.Mode) == 0 {
+		patterns, bean.RestrictReads = nil, false
+	}
+	bean.Patterns = patterns
+	_, err := impl.clusterRepository.FindById(bean.ClusterId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cluster", "err", err, "clusterId", bean.ClusterId)
+		return nil, err
+	}
+	err = impl.clusterRepository.UpdateNamespacePolicy(bean.ClusterId, bean.Mode, bean.Patterns, bean.RestrictReads, userId)
+	if err != nil {
+		impl.logger.Errorw("error in updating cluster namespace policy", "err", err, "clusterId", bean.ClusterId)
+		return nil, err
+	}
+	if impl.namespacePolicyCache != nil {
+		impl.namespacePolicyCache.Delete(strconv.Itoa(bean.ClusterId))
+	}
+	return bean, nil
+}
+
+// DeleteNamespacePolicy removes the policy of the cluster, devtron may touch every namespace afterwards
+func (impl *ClusterServiceImpl) DeleteNamespacePolicy(clusterId int, userId int32) error {
+	_, err := impl.UpdateNamespacePolicy(&ClusterNamespacePolicyBean{ClusterId: clusterId}, userId)
+	return err
+}
+
+// CheckNamespaceAccess enforces the namespace policy of the cluster for K8sUtil, see util.NamespacePolicyEnforcer
+func (impl *ClusterServiceImpl) CheckNamespaceAccess(ctx context.Context, clusterId int, namespace string, mutating bool) error {
+	policy, err := impl.findNamespacePolicy(clusterId)
+	if err != nil {
+		util.LoggerFromContext(ctx, impl.logger).Errorw("error in fetching cluster namespace policy", "err", err, "clusterId", clusterId)
+		return err
+	}
+	return policy.Check(clusterId, namespace, mutating)
+}
+
+func (impl *ClusterServiceImpl) findNamespacePolicy(clusterId int) (*util.NamespacePolicy, error) {
+	key := strconv.Itoa(clusterId)
+	if impl.namespacePolicyCache != nil {
+		if policy, ok := impl.namespacePolicyCache.Get(key); ok {
+			return policy.(*util.NamespacePolicy), nil
+		}
+	}
+	bean, err := impl.GetNamespacePolicy(clusterId)
+	if err != nil {
+		if util.IsErrNoRows(err) {
+			// clusters which are gone have no policy, the call fails on the cluster itself
+			return nil, nil
+		}
+		return nil, err
+	}
+	policy := &util.NamespacePolicy{Mode: bean.Mode, Patterns: bean.Patterns, RestrictReads: bean.RestrictReads}
+	if impl.namespacePolicyCache != nil {
+		impl.namespacePolicyCache.SetDefault(key, policy)
+	}
+	return policy, nil
+}
+
+func newNamespacePolicyValidationError(message string) *util.ApiError {
+	return &util.ApiError{
+		HttpStatusCode:  http.StatusBadRequest,
+		Code:            strconv.Itoa(http.StatusBadRequest),
+		UserMessage:     message,
+		InternalMessage: message,
+	}
+}
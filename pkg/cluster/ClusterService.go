@@ -30,6 +30,8 @@ import (
 	"k8s.io/client-go/rest"
 	"net/url"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	bean2 "github.com/devtron-labs/devtron/api/bean"
@@ -37,8 +39,10 @@ import (
 	"github.com/devtron-labs/devtron/internal/constants"
 	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
 	util2 "github.com/devtron-labs/devtron/util"
 	"github.com/go-pg/pg"
+	"github.com/patrickmn/go-cache"
 	"go.uber.org/zap"
 )
 
@@ -83,6 +87,10 @@ type ClusterService interface {
 	FindAllWithoutConfig() ([]*ClusterBean, error)
 	FindAllActive() ([]ClusterBean, error)
 	DeleteFromDb(bean *ClusterBean, userId int32) error
+	// DeleteFromDbWithTx marks the cluster deleted on tx, the caller cleans its namespace informer with
+	// CleanNamespaceInformer once tx is committed
+	DeleteFromDbWithTx(bean *ClusterBean, userId int32, tx *pg.Tx) error
+	CleanNamespaceInformer(clusterName string)
 
 	FindById(id int) (*ClusterBean, error)
 	FindByIdWithoutConfig(id int) (*ClusterBean, error)
@@ -96,8 +104,19 @@ type ClusterService interface {
 	GetK8sClient() (*v12.CoreV1Client, error)
 	GetAllClusterNamespaces() map[string][]string
 	FindAllNamespacesByUserIdAndClusterId(userId int32, clusterId int, isActionUserSuperAdmin bool) ([]string, error)
+	// FindNamespacesForPicker returns the namespaces of the cluster the user has access to with their labels, refresh
+	// lists them live instead of reading the watch cache
+	FindNamespacesForPicker(userId int32, clusterId int, isActionUserSuperAdmin bool, refresh bool) (*bean2.CachedNamespaces, error)
 	FindAllForClusterByUserId(userId int32, isActionUserSuperAdmin bool) ([]ClusterBean, error)
 	FetchRolesFromGroup(userId int32) ([]*repository2.RoleModel, error)
+	GetTerminalDefaults(clusterId int) (*ClusterTerminalDefaultsBean, error)
+	UpdateTerminalDefaults(bean *ClusterTerminalDefaultsBean, userId int32) (*ClusterTerminalDefaultsBean, error)
+	DeleteTerminalDefaults(clusterId int, userId int32) error
+	GetNamespacePolicy(clusterId int) (*ClusterNamespacePolicyBean, error)
+	UpdateNamespacePolicy(bean *ClusterNamespacePolicyBean, userId int32) (*ClusterNamespacePolicyBean, error)
+	DeleteNamespacePolicy(clusterId int, userId int32) error
+	// GetClusterHealth returns the connection status and the circuit breaker state of the cluster
+	GetClusterHealth(clusterId int) (*ClusterHealthBean, error)
 }
 
 type ClusterServiceImpl struct {
@@ -108,6 +127,8 @@ type ClusterServiceImpl struct {
 	userAuthRepository  repository2.UserAuthRepository
 	userRepository      repository2.UserRepository
 	roleGroupRepository repository2.RoleGroupRepository
+	// namespacePolicyCache keeps the namespace policy by cluster id for CheckNamespaceAccess
+	namespacePolicyCache *cache.Cache
 }
 
 func NewClusterServiceImpl(repository repository.ClusterRepository, logger *zap.SugaredLogger,
@@ -115,13 +136,17 @@ func NewClusterServiceImpl(repository repository.ClusterRepository, logger *zap.
 	userAuthRepository repository2.UserAuthRepository, userRepository repository2.UserRepository,
 	roleGroupRepository repository2.RoleGroupRepository) *ClusterServiceImpl {
 	clusterService := &ClusterServiceImpl{
-		clusterRepository:   repository,
-		logger:              logger,
-		K8sUtil:             K8sUtil,
-		K8sInformerFactory:  K8sInformerFactory,
-		userAuthRepository:  userAuthRepository,
-		userRepository:      userRepository,
-		roleGroupRepository: roleGroupRepository,
+		clusterRepository:    repository,
+		logger:               logger,
+		K8sUtil:              K8sUtil,
+		K8sInformerFactory:   K8sInformerFactory,
+		userAuthRepository:   userAuthRepository,
+		userRepository:       userRepository,
+		roleGroupRepository:  roleGroupRepository,
+		namespacePolicyCache: newNamespacePolicyCache(),
+	}
+	if K8sUtil != nil {
+		K8sUtil.SetNamespacePolicyEnforcer(clusterService)
 	}
 	go clusterService.buildInformer()
 	return clusterService
@@ -151,7 +176,7 @@ func (impl *ClusterServiceImpl) GetClusterConfig(cluster *ClusterBean) (*util.Cl
 			bearerToken = string(content)
 		}
 	}
-	clusterCfg := &util.ClusterConfig{Host: host, BearerToken: bearerToken}
+	clusterCfg := &util.ClusterConfig{Host: host, BearerToken: bearerToken, ClusterId: cluster.Id}
 	return clusterCfg, nil
 }
 
@@ -181,9 +206,9 @@ func (impl *ClusterServiceImpl) Save(parent context.Context, bean *ClusterBean,
 
 	if bean.PrometheusAuth != nil {
 		model.PUserName = bean.PrometheusAuth.UserName
-		model.PPassword = bean.PrometheusAuth.Password
+		model.PPassword = sql.EncryptedString(bean.PrometheusAuth.Password)
 		model.PTlsClientCert = bean.PrometheusAuth.TlsClientCert
-		model.PTlsClientKey = bean.PrometheusAuth.TlsClientKey
+		model.PTlsClientKey = sql.EncryptedString(bean.PrometheusAuth.TlsClientKey)
 	}
 
 	model.CreatedBy = userId
@@ -195,6 +220,10 @@ func (impl *ClusterServiceImpl) Save(parent context.Context, bean *ClusterBean,
 	if err != nil {
 		return nil, err
 	}
+	err = impl.checkApiServerHealth(parent, cfg)
+	if err != nil {
+		return nil, err
+	}
 	client, err := impl.K8sUtil.GetK8sDiscoveryClient(cfg)
 	if err != nil {
 		return nil, err
@@ -331,9 +360,9 @@ func (impl *ClusterServiceImpl) FindById(id int) (*ClusterBean, error) {
 	}
 	prometheusAuth := &PrometheusAuth{
 		UserName:      model.PUserName,
-		Password:      model.PPassword,
+		Password:      string(model.PPassword),
 		TlsClientCert: model.PTlsClientCert,
-		TlsClientKey:  model.PTlsClientKey,
+		TlsClientKey:  string(model.PTlsClientKey),
 	}
 	bean.PrometheusAuth = prometheusAuth
 	return bean, nil
@@ -400,6 +429,14 @@ func (impl *ClusterServiceImpl) Update(ctx context.Context, bean *ClusterBean, u
 		if err != nil {
 			return nil, err
 		}
+		cfg, err := impl.GetClusterConfig(bean)
+		if err != nil {
+			return nil, err
+		}
+		err = impl.checkApiServerHealth(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
 	}
 	model.ClusterName = bean.ClusterName
 	model.ServerUrl = bean.ServerUrl
@@ -410,13 +447,13 @@ func (impl *ClusterServiceImpl) Update(ctx context.Context, bean *ClusterBean, u
 			model.PUserName = bean.PrometheusAuth.UserName
 		}
 		if bean.PrometheusAuth.Password != "" {
-			model.PPassword = bean.PrometheusAuth.Password
+			model.PPassword = sql.EncryptedString(bean.PrometheusAuth.Password)
 		}
 		if bean.PrometheusAuth.TlsClientCert != "" {
 			model.PTlsClientCert = bean.PrometheusAuth.TlsClientCert
 		}
 		if bean.PrometheusAuth.TlsClientKey != "" {
-			model.PTlsClientKey = bean.PrometheusAuth.TlsClientKey
+			model.PTlsClientKey = sql.EncryptedString(bean.PrometheusAuth.TlsClientKey)
 		}
 	}
 	model.ErrorInConnecting = "" //setting empty because config to be updated is already validated
@@ -529,7 +566,24 @@ func (impl ClusterServiceImpl) DeleteFromDb(bean *ClusterBean, userId int32) err
 	deleteReq := existingCluster
 	deleteReq.UpdatedOn = time.Now()
 	deleteReq.UpdatedBy = userId
-	err = impl.clusterRepository.MarkClusterDeleted(deleteReq)
+	err = impl.clusterRepository.MarkClusterDeleted(deleteReq, nil)
+	if err != nil {
+		impl.logger.Errorw("error in deleting cluster", "id", bean.Id, "err", err)
+		return err
+	}
+	impl.K8sInformerFactory.CleanNamespaceInformer(existingCluster.ClusterName)
+	return nil
+}
+
+func (impl ClusterServiceImpl) DeleteFromDbWithTx(bean *ClusterBean, userId int32, tx *pg.Tx) error {
+	existingCluster, err := impl.clusterRepository.FindById(bean.Id)
+	if err != nil {
+		impl.logger.Errorw("No matching entry found for delete.", "id", bean.Id)
+		return err
+	}
+	existingCluster.UpdatedOn = time.Now()
+	existingCluster.UpdatedBy = userId
+	err = impl.clusterRepository.MarkClusterDeleted(existingCluster, tx)
 	if err != nil {
 		impl.logger.Errorw("error in deleting cluster", "id", bean.Id, "err", err)
 		return err
@@ -537,6 +591,10 @@ func (impl ClusterServiceImpl) DeleteFromDb(bean *ClusterBean, userId int32) err
 	return nil
 }
 
+func (impl ClusterServiceImpl) CleanNamespaceInformer(clusterName string) {
+	impl.K8sInformerFactory.CleanNamespaceInformer(clusterName)
+}
+
 func (impl ClusterServiceImpl) CheckIfConfigIsValid(cluster *ClusterBean) error {
 	configMap := cluster.Config
 	bearerToken := configMap["bearer_token"]
@@ -588,6 +646,27 @@ func (impl ClusterServiceImpl) CheckIfConfigIsValid(cluster *ClusterBean) error
 	return nil
 }
 
+// checkApiServerHealth fails registration when the api server reports failing checks, a healthz endpoint which can not be
+// read is let through as reachability is already verified by CheckIfConfigIsValid
+func (impl *ClusterServiceImpl) checkApiServerHealth(ctx context.Context, clusterConfig *util.ClusterConfig) error {
+	healthy, components, err := impl.K8sUtil.GetAPIServerHealthz(ctx, clusterConfig)
+	if err != nil {
+		impl.logger.Warnw("unable to read api server healthz, skipping health check", "host", clusterConfig.Host, "err", err)
+		return nil
+	}
+	if healthy {
+		return nil
+	}
+	var failing []string
+	for component, status := range components {
+		if status != util.HealthzStatusOk {
+			failing = append(failing, fmt.Sprintf("%s (%s)", component, status))
+		}
+	}
+	sort.Strings(failing)
+	return fmt.Errorf("Validation failed, api server is unhealthy : %s", strings.Join(failing, ", "))
+}
+
 func (impl *ClusterServiceImpl) GetAllClusterNamespaces() map[string][]string {
 	result := make(map[string][]string)
 	namespaceListGroupByCLuster := impl.K8sInformerFactory.GetLatestNamespaceListGroupByCLuster()
@@ -620,28 +699,15 @@ func (impl *ClusterServiceImpl) FindAllNamespacesByUserIdAndClusterId(userId int
 			}
 		}
 	} else {
-		roles, err := impl.FetchRolesFromGroup(userId)
+		isAllowed, err := impl.namespaceAccessFilter(userId, clusterBean.ClusterName)
 		if err != nil {
-			impl.logger.Errorw("error on fetching user roles for cluster list", "err", err)
 			return nil, err
 		}
-		allowedAll := false
-		allowedNamespaceMap := make(map[string]bool)
-		for _, role := range roles {
-			if clusterBean.ClusterName == role.Cluster {
-				allowedNamespaceMap[role.Namespace] = true
-				if role.Namespace == "" {
-					allowedAll = true
-				}
-			}
-		}
 
 		//adding final namespace list
 		for namespace, value := range namespaces {
-			if _, ok := allowedNamespaceMap[namespace]; ok || allowedAll {
-				if value {
-					result = append(result, namespace)
-				}
+			if value && isAllowed(namespace) {
+				result = append(result, namespace)
 			}
 		}
 	}
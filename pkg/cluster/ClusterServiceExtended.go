@@ -46,15 +46,19 @@ func NewClusterServiceImplExtended(repository repository.ClusterRepository, envi
 		clusterServiceCD:       clusterServiceCD,
 		gitOpsRepository:       gitOpsRepository,
 		ClusterServiceImpl: &ClusterServiceImpl{
-			clusterRepository:   repository,
-			logger:              logger,
-			K8sUtil:             K8sUtil,
-			K8sInformerFactory:  K8sInformerFactory,
-			userAuthRepository:  userAuthRepository,
-			userRepository:      userRepository,
-			roleGroupRepository: roleGroupRepository,
+			clusterRepository:    repository,
+			logger:               logger,
+			K8sUtil:              K8sUtil,
+			K8sInformerFactory:   K8sInformerFactory,
+			userAuthRepository:   userAuthRepository,
+			userRepository:       userRepository,
+			roleGroupRepository:  roleGroupRepository,
+			namespacePolicyCache: newNamespacePolicyCache(),
 		},
 	}
+	if K8sUtil != nil {
+		K8sUtil.SetNamespacePolicyEnforcer(clusterServiceExt.ClusterServiceImpl)
+	}
 	go clusterServiceExt.buildInformer()
 	return clusterServiceExt
 }
@@ -387,10 +391,11 @@ func (impl ClusterServiceImplExtended) DeleteFromDb(bean *ClusterBean, userId in
 	deleteReq := existingCluster
 	deleteReq.UpdatedOn = time.Now()
 	deleteReq.UpdatedBy = userId
-	err = impl.clusterRepository.MarkClusterDeleted(deleteReq)
+	err = impl.clusterRepository.MarkClusterDeleted(deleteReq, nil)
 	if err != nil {
 		impl.logger.Errorw("error in deleting cluster", "id", bean.Id, "err", err)
 		return err
 	}
+	impl.K8sInformerFactory.CleanNamespaceInformer(existingCluster.ClusterName)
 	return nil
 }
@@ -0,0 +1,45 @@
+package cluster
+
+import "strings"
+
+// ClusterTerminalDefaultsBean holds the base image and shell used for terminal sessions of the cluster which leave them
+// empty, empty values fall back to the global defaults
+type ClusterTerminalDefaultsBean struct {
+	ClusterId int    `json:"clusterId"`
+	BaseImage string `json:"baseImage,omitempty" validate:"max=500"`
+	ShellName string `json:"shellName,omitempty" validate:"omitempty,oneof=bash sh powershell cmd"`
+}
+
+func (impl *ClusterServiceImpl) GetTerminalDefaults(clusterId int) (*ClusterTerminalDefaultsBean, error) {
+	model, err := impl.clusterRepository.FindById(clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cluster", "err", err, "clusterId", clusterId)
+		return nil, err
+	}
+	return &ClusterTerminalDefaultsBean{
+		ClusterId: model.Id,
+		BaseImage: model.TerminalDefaultBaseImage,
+		ShellName: model.TerminalDefaultShell,
+	}, nil
+}
+
+func (impl *ClusterServiceImpl) UpdateTerminalDefaults(bean *ClusterTerminalDefaultsBean, userId int32) (*ClusterTerminalDefaultsBean, error) {
+	_, err := impl.clusterRepository.FindById(bean.ClusterId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cluster", "err", err, "clusterId", bean.ClusterId)
+		return nil, err
+	}
+	bean.BaseImage = strings.TrimSpace(bean.BaseImage)
+	err = impl.clusterRepository.UpdateTerminalDefaults(bean.ClusterId, bean.BaseImage, bean.ShellName, userId)
+	if err != nil {
+		impl.logger.Errorw("error in updating cluster terminal defaults", "err", err, "clusterId", bean.ClusterId)
+		return nil, err
+	}
+	return bean, nil
+}
+
+// DeleteTerminalDefaults clears the defaults of the cluster, sessions use the global defaults afterwards
+func (impl *ClusterServiceImpl) DeleteTerminalDefaults(clusterId int, userId int32) error {
+	_, err := impl.UpdateTerminalDefaults(&ClusterTerminalDefaultsBean{ClusterId: clusterId}, userId)
+	return err
+}
@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/patrickmn/go-cache"
+)
+
+// availabilityCacheExpiry keeps the environment overview from listing every workload of the namespace on each poll
+const availabilityCacheExpiry = 5 * time.Second
+
+// EnvironmentAvailability rolls up the replicas of the workloads in the namespace of an environment. Paused and
+// hibernated workloads, those scaled to zero, are counted apart and are neither healthy nor degraded
+type EnvironmentAvailability struct {
+	EnvironmentId     int                      `json:"environmentId"`
+	Namespace         string                   `json:"namespace"`
+	GeneratedOn       time.Time                `json:"generatedOn"`
+	Summary           string                   `json:"summary"`
+	Workloads         int                      `json:"workloads"`
+	DesiredReplicas   int64                    `json:"desiredReplicas"`
+	ReadyReplicas     int64                    `json:"readyReplicas"`
+	UpdatedReplicas   int64                    `json:"updatedReplicas"`
+	Healthy           int                      `json:"healthy"`
+	Progressing       int                      `json:"progressing"`
+	Degraded          int                      `json:"degraded"`
+	Paused            int                      `json:"paused"`
+	Hibernated        int                      `json:"hibernated"`
+	DegradedWorkloads []*util.WorkloadReplicas `json:"degradedWorkloads"`
+	Items             []*util.WorkloadReplicas `json:"items"`
+}
+
+func newAvailabilityCache() *cache.Cache {
+	return cache.New(availabilityCacheExpiry, time.Minute)
+}
+
+func (impl EnvironmentServiceImpl) GetEnvironmentAvailability(ctx context.Context, environment *EnvironmentBean) (*EnvironmentAvailability, error) {
+	cacheKey := strconv.Itoa(environment.Id)
+	if availability, ok := impl.availabilityCache.Get(cacheKey); ok {
+		return availability.(*EnvironmentAvailability), nil
+	}
+	clusterBean, err := impl.clusterService.FindById(environment.ClusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster", "err", err, "clusterId", environment.ClusterId)
+		return nil, err
+	}
+	clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster config", "err", err, "clusterId", environment.ClusterId)
+		return nil, err
+	}
+	workloads, err := impl.K8sUtil.ListWorkloads(ctx, environment.Namespace, clusterConfig)
+	if err != nil {
+		impl.logger.Errorw("error in listing workloads", "err", err, "envId", environment.Id, "namespace", environment.Namespace)
+		return nil, err
+	}
+	items := make([]*util.WorkloadReplicas, 0, len(workloads))
+	for _, workload := range workloads {
+		if replicas := util.ParseWorkloadReplicas(workload); replicas != nil {
+			items = append(items, replicas)
+		}
+	}
+	availability := newEnvironmentAvailability(items)
+	availability.EnvironmentId, availability.Namespace, availability.GeneratedOn = environment.Id, environment.Namespace, time.Now()
+	impl.availabilityCache.SetDefault(cacheKey, availability)
+	return availability, nil
+}
+
+func newEnvironmentAvailability(items []*util.WorkloadReplicas) *EnvironmentAvailability {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind < items[j].Kind
+		}
+		return items[i].Name < items[j].Name
+	})
+	availability := &EnvironmentAvailability{Workloads: len(items), Items: items, DegradedWorkloads: []*util.WorkloadReplicas{}}
+	for _, item := range items {
+		availability.DesiredReplicas += item.DesiredReplicas
+		availability.ReadyReplicas += item.ReadyReplicas
+		availability.UpdatedReplicas += item.UpdatedReplicas
+		switch {
+		case item.Hibernated:
+			availability.Hibernated++
+		case item.Paused || item.HealthStatus == string(health.HealthStatusSuspended):
+			availability.Paused++
+		case item.HealthStatus == string(health.HealthStatusHealthy):
+			availability.Healthy++
+		case item.HealthStatus == string(health.HealthStatusDegraded) || item.HealthStatus == string(health.HealthStatusMissing):
+			availability.Degraded++
+			availability.DegradedWorkloads = append(availability.DegradedWorkloads, item)
+		default:
+			availability.Progressing++
+		}
+	}
+	availability.Summary = fmt.Sprintf("%d/%d pods ready across %d workloads", availability.ReadyReplicas, availability.DesiredReplicas, availability.Workloads)
+	if availability.Hibernated > 0 {
+		availability.Summary += fmt.Sprintf(", %d hibernated", availability.Hibernated)
+	}
+	if availability.Paused > 0 {
+		availability.Summary += fmt.Sprintf(", %d paused", availability.Paused)
+	}
+	return availability
+}
@@ -0,0 +1,31 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEnvironmentAvailability(t *testing.T) {
+	availability := newEnvironmentAvailability([]*util.WorkloadReplicas{
+		{Kind: "Deployment", Name: "payments", DesiredReplicas: 3, ReadyReplicas: 3, UpdatedReplicas: 3, HealthStatus: "Healthy"},
+		{Kind: "Deployment", Name: "ledger", DesiredReplicas: 2, ReadyReplicas: 1, UpdatedReplicas: 1, Paused: true, HealthStatus: "Suspended"},
+		{Kind: "StatefulSet", Name: "cache", Hibernated: true, HealthStatus: "Healthy"},
+		{Kind: "Rollout", Name: "checkout", DesiredReplicas: 5, ReadyReplicas: 2, UpdatedReplicas: 1, HealthStatus: "Degraded"},
+		{Kind: "DaemonSet", Name: "log-agent", DesiredReplicas: 4, ReadyReplicas: 3, UpdatedReplicas: 4, HealthStatus: "Progressing"},
+	})
+	assert.Equal(t, "9/14 pods ready across 5 workloads, 1 hibernated, 1 paused", availability.Summary)
+	assert.Equal(t, 1, availability.Healthy)
+	assert.Equal(t, 1, availability.Paused)
+	assert.Equal(t, 1, availability.Hibernated)
+	assert.Equal(t, 1, availability.Degraded)
+	assert.Equal(t, 1, availability.Progressing)
+	assert.Len(t, availability.DegradedWorkloads, 1)
+	assert.Equal(t, "checkout", availability.DegradedWorkloads[0].Name)
+	assert.Equal(t, "DaemonSet", availability.Items[0].Kind)
+
+	empty := newEnvironmentAvailability(nil)
+	assert.Equal(t, "0/0 pods ready across 0 workloads", empty.Summary)
+	assert.NotNil(t, empty.DegradedWorkloads)
+}
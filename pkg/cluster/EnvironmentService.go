@@ -18,17 +18,21 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/caarlos0/env/v6"
 	"github.com/devtron-labs/devtron/client/k8s/informer"
 	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/cluster/repository"
 	"github.com/devtron-labs/devtron/pkg/user"
 	repository2 "github.com/devtron-labs/devtron/pkg/user/repository"
 	"github.com/go-pg/pg"
+	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -44,6 +48,9 @@ type EnvironmentBean struct {
 	Namespace             string `json:"namespace,omitempty" validate:"name-space-component,max=50"`
 	CdArgoSetup           bool   `json:"isClusterCdActive"`
 	EnvironmentIdentifier string `json:"environmentIdentifier"`
+	// Team is only used to render the namespace naming template
+	Team             string `json:"team,omitempty"`
+	NamespaceWarning string `json:"namespaceWarning,omitempty"`
 }
 
 type EnvDto struct {
@@ -53,6 +60,27 @@ type EnvDto struct {
 	EnvironmentIdentifier string `json:"environmentIdentifier,omitempty"`
 }
 
+// SecurityPostureReport lists the effective security settings of every container in the namespace of an environment
+type SecurityPostureReport struct {
+	EnvironmentId   int                           `json:"environmentId"`
+	EnvironmentName string                        `json:"environmentName"`
+	ClusterId       int                           `json:"clusterId"`
+	Namespace       string                        `json:"namespace"`
+	GeneratedOn     time.Time                     `json:"generatedOn"`
+	Summary         SecurityPostureSummary        `json:"summary"`
+	Containers      []util.ContainerSecurityAudit `json:"containers"`
+}
+
+// SecurityPostureSummary counts containers per finding, a container can be counted under several findings
+type SecurityPostureSummary struct {
+	Containers               int `json:"containers"`
+	RunAsRoot                int `json:"runAsRoot"`
+	Privileged               int `json:"privileged"`
+	AllowPrivilegeEscalation int `json:"allowPrivilegeEscalation"`
+	AddedCapabilities        int `json:"addedCapabilities"`
+	WritableRootFilesystem   int `json:"writableRootFilesystem"`
+}
+
 type ClusterEnvDto struct {
 	ClusterId    int       `json:"clusterId"`
 	ClusterName  string    `json:"clusterName,omitempty"`
@@ -65,6 +93,9 @@ type EnvironmentService interface {
 	GetAll() ([]EnvironmentBean, error)
 	GetAllActive() ([]EnvironmentBean, error)
 	Delete(deleteReq *EnvironmentBean, userId int32) error
+	// DeleteWithTx marks the environment deleted and removes its roles on tx, devtron network policies of the
+	// namespace are left in place
+	DeleteWithTx(deleteReq *EnvironmentBean, userId int32, tx *pg.Tx) error
 
 	FindById(id int) (*EnvironmentBean, error)
 	Update(mappings *EnvironmentBean, userId int32) (*EnvironmentBean, error)
@@ -75,6 +106,12 @@ type EnvironmentService interface {
 	GetByClusterId(id int) ([]*EnvironmentBean, error)
 	GetCombinedEnvironmentListForDropDown(token string, isActionUserSuperAdmin bool, auth func(token string, object string) bool) ([]*ClusterEnvDto, error)
 	GetCombinedEnvironmentListForDropDownByClusterIds(token string, clusterIds []int, auth func(token string, object string) bool) ([]*ClusterEnvDto, error)
+	GetSecurityPostureReport(ctx context.Context, environment *EnvironmentBean) (*SecurityPostureReport, error)
+	PreviewNamespace(ctx context.Context, request *NamespacePreviewRequest) (*NamespacePreview, error)
+	// ListConfigObjects lists a page of the ConfigMaps or Secrets of the namespace of the environment, without values
+	ListConfigObjects(ctx context.Context, environment *EnvironmentBean, kind string, options util.ConfigObjectListOptions) (*util.ConfigObjectList, error)
+	// GetEnvironmentAvailability summarises the replicas and health of the workloads in the namespace of the environment
+	GetEnvironmentAvailability(ctx context.Context, environment *EnvironmentBean) (*EnvironmentAvailability, error)
 }
 
 type EnvironmentServiceImpl struct {
@@ -84,7 +121,9 @@ type EnvironmentServiceImpl struct {
 	K8sUtil               *util.K8sUtil
 	k8sInformerFactory    informer.K8sInformerFactory
 	//propertiesConfigService pipeline.PropertiesConfigService
-	userAuthService user.UserAuthService
+	userAuthService       user.UserAuthService
+	namespaceNamingConfig *NamespaceNamingConfig
+	availabilityCache     *cache.Cache
 }
 
 func NewEnvironmentServiceImpl(environmentRepository repository.EnvironmentRepository,
@@ -92,6 +131,11 @@ func NewEnvironmentServiceImpl(environmentRepository repository.EnvironmentRepos
 	K8sUtil *util.K8sUtil, k8sInformerFactory informer.K8sInformerFactory,
 	//  propertiesConfigService pipeline.PropertiesConfigService,
 	userAuthService user.UserAuthService) *EnvironmentServiceImpl {
+	namespaceNamingConfig := &NamespaceNamingConfig{}
+	if err := env.Parse(namespaceNamingConfig); err != nil {
+		logger.Errorw("error in parsing namespace naming config, namespaces are not rendered", "err", err)
+		namespaceNamingConfig = &NamespaceNamingConfig{UnmanagedPolicy: NamespaceUnmanagedPolicyWarn}
+	}
 	return &EnvironmentServiceImpl{
 		environmentRepository: environmentRepository,
 		logger:                logger,
@@ -99,7 +143,9 @@ func NewEnvironmentServiceImpl(environmentRepository repository.EnvironmentRepos
 		K8sUtil:               K8sUtil,
 		k8sInformerFactory:    k8sInformerFactory,
 		//propertiesConfigService: propertiesConfigService,
-		userAuthService: userAuthService,
+		userAuthService:       userAuthService,
+		namespaceNamingConfig: namespaceNamingConfig,
+		availabilityCache:     newAvailabilityCache(),
 	}
 }
 
@@ -109,15 +155,30 @@ func (impl EnvironmentServiceImpl) Create(mappings *EnvironmentBean, userId int3
 		impl.logger.Errorw("error while fetch", "err", err)
 		return nil, err
 	}
-	err = impl.validateNamespaces(mappings.Namespace, existingEnvs)
+	clusterBean, err := impl.clusterService.FindById(mappings.ClusterId)
 	if err != nil {
 		return nil, err
 	}
-
-	clusterBean, err := impl.clusterService.FindById(mappings.ClusterId)
+	if len(mappings.Namespace) == 0 && len(impl.namespaceNamingConfig.Template) > 0 {
+		mappings.Namespace, err = impl.renderNamespace(mappings.Environment, clusterBean.ClusterName, mappings.Team)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = impl.validateNamespaces(mappings.Namespace, existingEnvs)
 	if err != nil {
 		return nil, err
 	}
+	ownership := ""
+	if len(mappings.Namespace) > 0 {
+		ownership, err = impl.checkNamespaceOwnership(context.Background(), clusterBean, mappings.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if ownership == util.NamespaceUnmanaged {
+			mappings.NamespaceWarning = fmt.Sprintf("namespace %s already exists and is not managed by devtron", mappings.Namespace)
+		}
+	}
 
 	identifier := clusterBean.ClusterName + "__" + mappings.Namespace
 
@@ -148,12 +209,12 @@ func (impl EnvironmentServiceImpl) Create(mappings *EnvironmentBean, userId int3
 		impl.logger.Errorw("error in saving environment", "err", err)
 		return mappings, err
 	}
-	if len(model.Namespace) > 0 {
+	if len(model.Namespace) > 0 && ownership != util.NamespaceManaged && ownership != util.NamespaceUnmanaged {
 		cfg, err := impl.clusterService.GetClusterConfig(clusterBean)
 		if err != nil {
 			return nil, err
 		}
-		if err := impl.K8sUtil.CreateNsIfNotExists(model.Namespace, cfg); err != nil {
+		if err := impl.K8sUtil.CreateManagedNamespace(context.Background(), model.Namespace, cfg); err != nil {
 			impl.logger.Errorw("error in creating ns", "ns", model.Namespace, "err", err)
 		}
 
@@ -365,6 +426,87 @@ func (impl EnvironmentServiceImpl) GetEnvironmentListForAutocomplete() ([]Enviro
 	return beans, nil
 }
 
+func (impl EnvironmentServiceImpl) renderNamespace(environment, clusterName, team string) (string, error) {
+	namespace, err := renderNamespaceName(impl.namespaceNamingConfig.Template, map[string]string{"env": environment, "cluster": clusterName, "team": team})
+	if err != nil {
+		return "", &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: err.Error(), InternalMessage: err.Error()}
+	}
+	if errs := validateNamespaceName(namespace, impl.namespaceNamingConfig.MaxLength); len(errs) > 0 {
+		message := fmt.Sprintf("rendered namespace %s is invalid: %s", namespace, strings.Join(errs, ", "))
+		return "", &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: message, InternalMessage: message}
+	}
+	return namespace, nil
+}
+
+// checkNamespaceOwnership returns an empty ownership when the cluster can not be reached, the namespace is then created
+// as before. Unmanaged namespaces fail when the policy blocks them
+func (impl EnvironmentServiceImpl) checkNamespaceOwnership(ctx context.Context, clusterBean *ClusterBean, namespace string) (string, error) {
+	cfg, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		return "", err
+	}
+	ownership, err := impl.K8sUtil.GetNamespaceOwnership(ctx, namespace, cfg)
+	if err != nil {
+		impl.logger.Warnw("namespace ownership could not be checked", "clusterId", clusterBean.Id, "namespace", namespace, "err", err)
+		return "", nil
+	}
+	if ownership == util.NamespaceUnmanaged && impl.namespaceNamingConfig.UnmanagedPolicy == NamespaceUnmanagedPolicyBlock {
+		message := fmt.Sprintf("namespace %s already exists and is not managed by devtron", namespace)
+		return ownership, &util.ApiError{HttpStatusCode: http.StatusConflict, Code: strconv.Itoa(http.StatusConflict), UserMessage: message, InternalMessage: message}
+	}
+	return ownership, nil
+}
+
+func (impl EnvironmentServiceImpl) PreviewNamespace(ctx context.Context, request *NamespacePreviewRequest) (*NamespacePreview, error) {
+	clusterBean, err := impl.clusterService.FindById(request.ClusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster", "clusterId", request.ClusterId, "err", err)
+		return nil, err
+	}
+	preview := &NamespacePreview{Namespace: request.Namespace}
+	if len(preview.Namespace) == 0 {
+		preview.Template = impl.namespaceNamingConfig.Template
+		if len(preview.Template) == 0 {
+			preview.Errors = []string{"namespace is required as no naming template is configured"}
+			return preview, nil
+		}
+		preview.Namespace, err = renderNamespaceName(preview.Template, map[string]string{"env": request.Environment, "cluster": clusterBean.ClusterName, "team": request.Team})
+		if err != nil {
+			preview.Errors = []string{err.Error()}
+			return preview, nil
+		}
+	}
+	if preview.Errors = validateNamespaceName(preview.Namespace, impl.namespaceNamingConfig.MaxLength); len(preview.Errors) > 0 {
+		return preview, nil
+	}
+	environment, err := impl.environmentRepository.FindOneByNamespaceAndClusterId(preview.Namespace, request.ClusterId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting environment by namespace", "clusterId", request.ClusterId, "namespace", preview.Namespace, "err", err)
+		return nil, err
+	}
+	if environment != nil && environment.Id > 0 {
+		preview.UsedByEnvironment = environment.Name
+		preview.Errors = []string{fmt.Sprintf("namespace is used by environment %s", environment.Name)}
+		return preview, nil
+	}
+	preview.Valid = true
+	cfg, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		return nil, err
+	}
+	preview.Ownership, err = impl.K8sUtil.GetNamespaceOwnership(ctx, preview.Namespace, cfg)
+	switch {
+	case err != nil:
+		preview.Message = fmt.Sprintf("namespace could not be checked in the cluster: %s", err.Error())
+	case preview.Ownership == util.NamespaceNotFound:
+		preview.Message = "namespace will be created"
+	case preview.Ownership == util.NamespaceUnmanaged:
+		preview.Blocked = impl.namespaceNamingConfig.UnmanagedPolicy == NamespaceUnmanagedPolicyBlock
+		preview.Message = "namespace already exists and is not managed by devtron"
+	}
+	return preview, nil
+}
+
 func (impl EnvironmentServiceImpl) validateNamespaces(namespace string, envs []*repository.Environment) error {
 	if len(envs) >= 1 {
 		if namespace == "" {
@@ -598,23 +740,135 @@ func (impl EnvironmentServiceImpl) Delete(deleteReq *EnvironmentBean, userId int
 	}
 	// Rollback tx on error.
 	defer tx.Rollback()
-	deleteRequest := existingEnv
-	deleteRequest.UpdatedOn = time.Now()
-	deleteRequest.UpdatedBy = userId
-	err = impl.environmentRepository.MarkEnvironmentDeleted(deleteRequest, tx)
+	err = impl.markEnvironmentDeleted(existingEnv, userId, tx)
 	if err != nil {
-		impl.logger.Errorw("error in deleting environment", "envId", deleteReq.Id, "envName", deleteReq.Environment)
 		return err
 	}
-	//deleting auth roles entries for this environment
-	err = impl.userAuthService.DeleteRoles(repository2.ENV_TYPE, deleteRequest.Name, tx, existingEnv.EnvironmentIdentifier)
+	err = tx.Commit()
 	if err != nil {
-		impl.logger.Errorw("error in deleting auth roles", "err", err)
 		return err
 	}
-	err = tx.Commit()
+	impl.deleteDevtronNetworkPolicies(existingEnv)
+	return nil
+}
+
+func (impl EnvironmentServiceImpl) DeleteWithTx(deleteReq *EnvironmentBean, userId int32, tx *pg.Tx) error {
+	existingEnv, err := impl.environmentRepository.FindById(deleteReq.Id)
+	if err != nil {
+		impl.logger.Errorw("No matching entry found for delete.", "id", deleteReq.Id)
+		return err
+	}
+	return impl.markEnvironmentDeleted(existingEnv, userId, tx)
+}
+
+func (impl EnvironmentServiceImpl) markEnvironmentDeleted(existingEnv *repository.Environment, userId int32, tx *pg.Tx) error {
+	existingEnv.UpdatedOn = time.Now()
+	existingEnv.UpdatedBy = userId
+	err := impl.environmentRepository.MarkEnvironmentDeleted(existingEnv, tx)
 	if err != nil {
+		impl.logger.Errorw("error in deleting environment", "envId", existingEnv.Id, "envName", existingEnv.Name)
+		return err
+	}
+	//deleting auth roles entries for this environment
+	err = impl.userAuthService.DeleteRoles(repository2.ENV_TYPE, existingEnv.Name, tx, existingEnv.EnvironmentIdentifier)
+	if err != nil {
+		impl.logger.Errorw("error in deleting auth roles", "err", err)
 		return err
 	}
 	return nil
 }
+
+// deleteDevtronNetworkPolicies removes network policies created by devtron in the environment namespace, failures are
+// only logged as the environment is already deleted
+func (impl EnvironmentServiceImpl) deleteDevtronNetworkPolicies(env *repository.Environment) {
+	if len(env.Namespace) == 0 {
+		return
+	}
+	clusterBean, err := impl.clusterService.FindById(env.ClusterId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cluster for network policy cleanup", "err", err, "envId", env.Id)
+		return
+	}
+	cfg, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster config for network policy cleanup", "err", err, "envId", env.Id)
+		return
+	}
+	ctx := context.Background()
+	networkPolicies, err := impl.K8sUtil.ListNetworkPolicies(ctx, env.Namespace, cfg)
+	if err != nil {
+		impl.logger.Errorw("error in listing network policies for cleanup", "err", err, "envId", env.Id, "namespace", env.Namespace)
+		return
+	}
+	for _, networkPolicy := range networkPolicies {
+		if networkPolicy.Labels[util.K8sManagedByLabelKey] != util.DevtronManagedByLabelValue {
+			continue
+		}
+		err = impl.K8sUtil.DeleteNetworkPolicy(ctx, env.Namespace, networkPolicy.Name, cfg)
+		if err != nil {
+			impl.logger.Errorw("error in deleting network policy", "err", err, "envId", env.Id, "name", networkPolicy.Name)
+		}
+	}
+}
+
+func (impl EnvironmentServiceImpl) ListConfigObjects(ctx context.Context, environment *EnvironmentBean, kind string, options util.ConfigObjectListOptions) (*util.ConfigObjectList, error) {
+	clusterBean, err := impl.clusterService.FindById(environment.ClusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster", "err", err, "clusterId", environment.ClusterId)
+		return nil, err
+	}
+	clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster config", "err", err, "clusterId", environment.ClusterId)
+		return nil, err
+	}
+	if kind == util.SecretKind {
+		return impl.K8sUtil.ListSecrets(ctx, environment.Namespace, clusterConfig, options)
+	}
+	return impl.K8sUtil.ListConfigMaps(ctx, environment.Namespace, clusterConfig, options)
+}
+
+func (impl EnvironmentServiceImpl) GetSecurityPostureReport(ctx context.Context, environment *EnvironmentBean) (*SecurityPostureReport, error) {
+	clusterBean, err := impl.clusterService.FindById(environment.ClusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster", "err", err, "clusterId", environment.ClusterId)
+		return nil, err
+	}
+	clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster config", "err", err, "clusterId", environment.ClusterId)
+		return nil, err
+	}
+	audits, err := impl.K8sUtil.GetContainerSecurityContexts(ctx, environment.Namespace, clusterConfig)
+	if err != nil {
+		impl.logger.Errorw("error in getting container security contexts", "err", err, "envId", environment.Id, "namespace", environment.Namespace)
+		return nil, err
+	}
+	report := &SecurityPostureReport{
+		EnvironmentId:   environment.Id,
+		EnvironmentName: environment.Environment,
+		ClusterId:       environment.ClusterId,
+		Namespace:       environment.Namespace,
+		GeneratedOn:     time.Now(),
+		Containers:      audits,
+	}
+	for _, audit := range audits {
+		report.Summary.Containers++
+		if audit.RunAsRoot {
+			report.Summary.RunAsRoot++
+		}
+		if audit.Privileged {
+			report.Summary.Privileged++
+		}
+		if audit.AllowPrivilegeEscalation {
+			report.Summary.AllowPrivilegeEscalation++
+		}
+		if len(audit.Capabilities) > 0 {
+			report.Summary.AddedCapabilities++
+		}
+		if !audit.ReadOnlyRootFilesystem {
+			report.Summary.WritableRootFilesystem++
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,285 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	auditRepository "github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const (
+	// FreezeOverrideRoleSuperAdmin can be used in OverrideRoles instead of the casbin role of super admins
+	FreezeOverrideRoleSuperAdmin = "super-admin"
+	freezeOverrideActionType     = "freeze_override"
+)
+
+type FreezeWindowBean struct {
+	Id            int       `json:"id"`
+	Name          string    `json:"name" validate:"required,max=250"`
+	ClusterId     int       `json:"clusterId,omitempty"`
+	EnvironmentId int       `json:"environmentId,omitempty"`
+	StartTime     time.Time `json:"startTime" validate:"required"`
+	EndTime       time.Time `json:"endTime" validate:"required"`
+	Reason        string    `json:"reason,omitempty"`
+	// OverrideRoles are casbin roles, e.g. role:admin_devtron-demo_prod_, allowed to make changes during the window
+	OverrideRoles []string `json:"overrideRoles"`
+}
+
+type FreezeWindowService interface {
+	Create(bean *FreezeWindowBean, userId int32) (*FreezeWindowBean, error)
+	Update(bean *FreezeWindowBean, userId int32) (*FreezeWindowBean, error)
+	Delete(id int, userId int32) error
+	// FindAll returns windows which are running or upcoming
+	FindAll(clusterId int) ([]*FreezeWindowBean, error)
+	CheckMutation(ctx context.Context, mutation *util.ClusterMutation) error
+}
+
+type FreezeWindowServiceImpl struct {
+	logger                       *zap.SugaredLogger
+	freezeWindowRepository       repository.FreezeWindowRepository
+	environmentRepository        repository.EnvironmentRepository
+	userService                  user.UserService
+	k8sResourceHistoryRepository auditRepository.K8sResourceHistoryRepository
+}
+
+func NewFreezeWindowServiceImpl(logger *zap.SugaredLogger, freezeWindowRepository repository.FreezeWindowRepository,
+	environmentRepository repository.EnvironmentRepository, userService user.UserService,
+	k8sResourceHistoryRepository auditRepository.K8sResourceHistoryRepository, K8sUtil *util.K8sUtil) *FreezeWindowServiceImpl {
+	impl := &FreezeWindowServiceImpl{
+		logger:                       logger,
+		freezeWindowRepository:       freezeWindowRepository,
+		environmentRepository:        environmentRepository,
+		userService:                  userService,
+		k8sResourceHistoryRepository: k8sResourceHistoryRepository,
+	}
+	K8sUtil.SetMutationGuard(impl)
+	return impl
+}
+
+func (impl *FreezeWindowServiceImpl) Create(bean *FreezeWindowBean, userId int32) (*FreezeWindowBean, error) {
+	if err := impl.validate(bean); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	model := &repository.FreezeWindow{
+		Name:          bean.Name,
+		ClusterId:     bean.ClusterId,
+		EnvironmentId: bean.EnvironmentId,
+		StartTime:     bean.StartTime,
+		EndTime:       bean.EndTime,
+		Reason:        bean.Reason,
+		OverrideRoles: bean.OverrideRoles,
+		Active:        true,
+		AuditLog:      sql.AuditLog{CreatedOn: now, CreatedBy: userId, UpdatedOn: now, UpdatedBy: userId},
+	}
+	err := impl.freezeWindowRepository.Save(model)
+	if err != nil {
+		impl.logger.Errorw("error in saving freeze window", "name", bean.Name, "err", err)
+		return nil, err
+	}
+	return adaptFreezeWindow(model), nil
+}
+
+func (impl *FreezeWindowServiceImpl) Update(bean *FreezeWindowBean, userId int32) (*FreezeWindowBean, error) {
+	model, err := impl.findById(bean.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err = impl.validate(bean); err != nil {
+		return nil, err
+	}
+	model.Name = bean.Name
+	model.ClusterId = bean.ClusterId
+	model.EnvironmentId = bean.EnvironmentId
+	model.StartTime = bean.StartTime
+	model.EndTime = bean.EndTime
+	model.Reason = bean.Reason
+	model.OverrideRoles = bean.OverrideRoles
+	model.UpdatedOn = time.Now()
+	model.UpdatedBy = userId
+	err = impl.freezeWindowRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("error in updating freeze window", "id", bean.Id, "err", err)
+		return nil, err
+	}
+	return adaptFreezeWindow(model), nil
+}
+
+func (impl *FreezeWindowServiceImpl) Delete(id int, userId int32) error {
+	model, err := impl.findById(id)
+	if err != nil {
+		return err
+	}
+	model.Active = false
+	model.UpdatedOn = time.Now()
+	model.UpdatedBy = userId
+	err = impl.freezeWindowRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("error in deleting freeze window", "id", id, "err", err)
+	}
+	return err
+}
+
+func (impl *FreezeWindowServiceImpl) FindAll(clusterId int) ([]*FreezeWindowBean, error) {
+	models, err := impl.freezeWindowRepository.FindAllActive(clusterId, time.Now())
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting freeze windows", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+	beans := make([]*FreezeWindowBean, 0, len(models))
+	for _, model := range models {
+		beans = append(beans, adaptFreezeWindow(model))
+	}
+	return beans, nil
+}
+
+// CheckMutation rejects the mutation with 423 while a window of the cluster or of the environment of the namespace
+// runs, unless the user holds an override role of every running window. Overrides are recorded in the k8s resource
+// history
+func (impl *FreezeWindowServiceImpl) CheckMutation(ctx context.Context, mutation *util.ClusterMutation) error {
+	logger := util.LoggerFromContext(ctx, impl.logger)
+	environmentId := 0
+	if len(mutation.Namespace) > 0 {
+		environment, err := impl.environmentRepository.FindOneByNamespaceAndClusterId(mutation.Namespace, mutation.ClusterId)
+		if err != nil && err != pg.ErrNoRows {
+			logger.Errorw("error in getting environment for freeze check", "clusterId", mutation.ClusterId, "namespace", mutation.Namespace, "err", err)
+			return err
+		}
+		if environment != nil {
+			environmentId = environment.Id
+		}
+	}
+	windows, err := impl.freezeWindowRepository.FindInEffect(mutation.ClusterId, environmentId, time.Now())
+	if err != nil && err != pg.ErrNoRows {
+		logger.Errorw("error in getting freeze windows in effect", "clusterId", mutation.ClusterId, "err", err)
+		return err
+	}
+	if len(windows) == 0 {
+		return nil
+	}
+	var userRoles []string
+	if mutation.UserId > 0 {
+		userRoles, err = impl.userService.CheckUserRoles(mutation.UserId)
+		if err != nil {
+			logger.Errorw("error in getting user roles for freeze check", "userId", mutation.UserId, "err", err)
+			return err
+		}
+	}
+	for _, window := range windows {
+		if !canOverrideFreezeWindow(window, userRoles) {
+			return freezeWindowError(window, mutation)
+		}
+	}
+	for _, window := range windows {
+		logger.Infow("freeze window overridden", "windowId", window.Id, "window", window.Name, "userId", mutation.UserId,
+			"clusterId", mutation.ClusterId, "namespace", mutation.Namespace, "kind", mutation.Kind, "name", mutation.Name, "action", mutation.Action)
+		impl.saveOverrideHistory(ctx, window, mutation, environmentId)
+	}
+	return nil
+}
+
+// saveOverrideHistory failures do not fail the mutation, the override is logged either way
+func (impl *FreezeWindowServiceImpl) saveOverrideHistory(ctx context.Context, window *repository.FreezeWindow, mutation *util.ClusterMutation, environmentId int) {
+	now := time.Now()
+	history := &auditRepository.K8sResourceHistory{
+		EnvId:        environmentId,
+		Namespace:    mutation.Namespace,
+		ResourceName: mutation.Name,
+		Kind:         mutation.Kind,
+		ActionType:   fmt.Sprintf("%s:%s:%d", freezeOverrideActionType, mutation.Action, window.Id),
+		AuditLog:     sql.AuditLog{CreatedOn: now, CreatedBy: mutation.UserId, UpdatedOn: now, UpdatedBy: mutation.UserId},
+	}
+	if err := impl.k8sResourceHistoryRepository.SaveK8sResourceHistory(history, nil); err != nil {
+		util.LoggerFromContext(ctx, impl.logger).Errorw("error in saving freeze override history", "windowId", window.Id, "userId", mutation.UserId, "err", err)
+	}
+}
+
+func canOverrideFreezeWindow(window *repository.FreezeWindow, userRoles []string) bool {
+	for _, overrideRole := range window.OverrideRoles {
+		if overrideRole == FreezeOverrideRoleSuperAdmin {
+			overrideRole = bean.SUPERADMIN
+		}
+		for _, role := range userRoles {
+			if role == overrideRole {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func freezeWindowError(window *repository.FreezeWindow, mutation *util.ClusterMutation) error {
+	overrideBy := "nobody"
+	if len(window.OverrideRoles) > 0 {
+		overrideBy = "roles " + strings.Join(window.OverrideRoles, ", ")
+	}
+	return &util.ApiError{
+		HttpStatusCode:  http.StatusLocked,
+		Code:            strconv.Itoa(http.StatusLocked),
+		InternalMessage: fmt.Sprintf("%s of %s %s blocked by freeze window %d", mutation.Action, mutation.Kind, mutation.Name, window.Id),
+		UserMessage: fmt.Sprintf("changes are frozen by window %q until %s, it can be overridden by %s",
+			window.Name, window.EndTime.Format(time.RFC3339), overrideBy),
+	}
+}
+
+func (impl *FreezeWindowServiceImpl) findById(id int) (*repository.FreezeWindow, error) {
+	model, err := impl.freezeWindowRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, Code: strconv.Itoa(http.StatusNotFound), UserMessage: "freeze window not found"}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting freeze window", "id", id, "err", err)
+		return nil, err
+	}
+	return model, nil
+}
+
+// validate resolves the cluster of environment scoped windows so that windows can always be looked up by cluster
+func (impl *FreezeWindowServiceImpl) validate(bean *FreezeWindowBean) error {
+	badRequest := func(message string) error {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: message}
+	}
+	if !bean.EndTime.After(bean.StartTime) {
+		return badRequest("endTime must be after startTime")
+	}
+	if bean.EnvironmentId > 0 {
+		environment, err := impl.environmentRepository.FindById(bean.EnvironmentId)
+		if err == pg.ErrNoRows {
+			return badRequest(fmt.Sprintf("environment %d not found", bean.EnvironmentId))
+		} else if err != nil {
+			impl.logger.Errorw("error in getting environment", "environmentId", bean.EnvironmentId, "err", err)
+			return err
+		}
+		if bean.ClusterId > 0 && bean.ClusterId != environment.ClusterId {
+			return badRequest(fmt.Sprintf("environment %d does not belong to cluster %d", bean.EnvironmentId, bean.ClusterId))
+		}
+		bean.ClusterId = environment.ClusterId
+	}
+	if bean.ClusterId == 0 {
+		return badRequest("clusterId or environmentId is required")
+	}
+	return nil
+}
+
+func adaptFreezeWindow(model *repository.FreezeWindow) *FreezeWindowBean {
+	return &FreezeWindowBean{
+		Id:            model.Id,
+		Name:          model.Name,
+		ClusterId:     model.ClusterId,
+		EnvironmentId: model.EnvironmentId,
+		StartTime:     model.StartTime,
+		EndTime:       model.EndTime,
+		Reason:        model.Reason,
+		OverrideRoles: model.OverrideRoles,
+	}
+}
@@ -0,0 +1,47 @@
+package cluster
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanOverrideFreezeWindow(t *testing.T) {
+	tests := []struct {
+		name          string
+		overrideRoles []string
+		userRoles     []string
+		want          bool
+	}{
+		{name: "no override roles", overrideRoles: nil, userRoles: []string{bean.SUPERADMIN}, want: false},
+		{name: "super admin shorthand", overrideRoles: []string{FreezeOverrideRoleSuperAdmin}, userRoles: []string{bean.SUPERADMIN}, want: true},
+		{name: "matching casbin role", overrideRoles: []string{"role:admin_devtron-demo_prod_"}, userRoles: []string{"role:admin_devtron-demo_prod_"}, want: true},
+		{name: "other role", overrideRoles: []string{"role:admin_devtron-demo_prod_"}, userRoles: []string{"role:trigger_devtron-demo_prod_"}, want: false},
+		{name: "user without roles", overrideRoles: []string{FreezeOverrideRoleSuperAdmin}, userRoles: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := &repository.FreezeWindow{Id: 1, OverrideRoles: tt.overrideRoles}
+			assert.Equal(t, tt.want, canOverrideFreezeWindow(window, tt.userRoles))
+		})
+	}
+}
+
+func TestFreezeWindowError(t *testing.T) {
+	endTime := time.Date(2023, 3, 1, 18, 0, 0, 0, time.UTC)
+	window := &repository.FreezeWindow{Id: 4, Name: "quarter close", EndTime: endTime, OverrideRoles: []string{FreezeOverrideRoleSuperAdmin}}
+	mutation := &util.ClusterMutation{ClusterId: 1, Namespace: "prod", Kind: "Job", Name: "migrate", Action: util.MutationActionCreate}
+
+	err := freezeWindowError(window, mutation)
+	apiErr, ok := err.(*util.ApiError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusLocked, apiErr.HttpStatusCode)
+	assert.Contains(t, apiErr.UserMessage, "quarter close")
+	assert.Contains(t, apiErr.UserMessage, "2023-03-01T18:00:00Z")
+	assert.Contains(t, apiErr.UserMessage, FreezeOverrideRoleSuperAdmin)
+}
@@ -0,0 +1,76 @@
+package cluster
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	NamespaceUnmanagedPolicyWarn  = "warn"
+	NamespaceUnmanagedPolicyBlock = "block"
+)
+
+// NamespaceNamingConfig renders the namespace of environments created without one, ${env}, ${cluster} and ${team}
+// are replaced in the template. UnmanagedPolicy decides whether an existing namespace not created by devtron is used
+// with a warning or blocks the environment
+type NamespaceNamingConfig struct {
+	Template        string `env:"NAMESPACE_NAME_TEMPLATE" envDefault:""`
+	MaxLength       int    `env:"NAMESPACE_NAME_MAX_LENGTH" envDefault:"50"`
+	UnmanagedPolicy string `env:"NAMESPACE_UNMANAGED_POLICY" envDefault:"warn"`
+}
+
+type NamespacePreviewRequest struct {
+	ClusterId   int    `json:"clusterId"`
+	Environment string `json:"environment"`
+	Team        string `json:"team,omitempty"`
+	// Namespace is previewed as it is instead of the template when set
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// NamespacePreview is the namespace an environment would get, Ownership is empty when the cluster could not be checked
+type NamespacePreview struct {
+	Namespace         string   `json:"namespace"`
+	Template          string   `json:"template,omitempty"`
+	Valid             bool     `json:"valid"`
+	Errors            []string `json:"errors,omitempty"`
+	UsedByEnvironment string   `json:"usedByEnvironment,omitempty"`
+	Ownership         string   `json:"ownership,omitempty"`
+	Blocked           bool     `json:"blocked"`
+	Message           string   `json:"message,omitempty"`
+}
+
+var namespaceTemplateVariable = regexp.MustCompile(`\$\{([a-zA-Z]+)\}`)
+
+// renderNamespaceName fails on variables without a value rather than rendering a name with a missing part
+func renderNamespaceName(template string, vars map[string]string) (string, error) {
+	missing := make(map[string]bool)
+	rendered := namespaceTemplateVariable.ReplaceAllStringFunc(template, func(variable string) string {
+		name := namespaceTemplateVariable.FindStringSubmatch(variable)[1]
+		value := vars[name]
+		if len(value) == 0 {
+			missing[name] = true
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("namespace template %q has no value for %s", template, strings.Join(names, ", "))
+	}
+	return strings.ToLower(rendered), nil
+}
+
+func validateNamespaceName(namespace string, maxLength int) []string {
+	errs := validation.IsDNS1123Label(namespace)
+	if maxLength > 0 && len(namespace) > maxLength {
+		errs = append(errs, fmt.Sprintf("must be no more than %d characters", maxLength))
+	}
+	return errs
+}
@@ -0,0 +1,40 @@
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderNamespaceName(t *testing.T) {
+	vars := map[string]string{"env": "Prod", "cluster": "default_cluster", "team": "payments"}
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{name: "team and env", template: "${team}-${env}", want: "payments-prod"},
+		{name: "static text", template: "apps-${env}", want: "apps-prod"},
+		{name: "missing value", template: "${team}-${env}-${region}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderNamespaceName(tt.template, vars)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateNamespaceName(t *testing.T) {
+	assert.Empty(t, validateNamespaceName("payments-prod", 50))
+	assert.NotEmpty(t, validateNamespaceName("default_cluster-prod", 50), "underscore is not allowed")
+	assert.NotEmpty(t, validateNamespaceName("-prod", 50))
+	assert.NotEmpty(t, validateNamespaceName(strings.Repeat("a", 51), 50))
+}
@@ -0,0 +1,245 @@
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	PodPlacementSourceCluster   = "cluster"
+	PodPlacementSourceNamespace = "namespace"
+)
+
+type PodPlacementPolicyBean struct {
+	Id               int               `json:"id"`
+	ClusterId        int               `json:"clusterId" validate:"number,required"`
+	Namespace        string            `json:"namespace,omitempty"`
+	NodeSelector     map[string]string `json:"nodeSelector,omitempty"`
+	Tolerations      []v1.Toleration   `json:"tolerations,omitempty"`
+	RuntimeClassName string            `json:"runtimeClassName,omitempty"`
+}
+
+// EffectivePodPlacement is the placement applied to pods created in the namespace, Sources tells for every field set
+// whether it comes from the namespace or the cluster policy. Fields already present in a pod spec always win
+type EffectivePodPlacement struct {
+	ClusterId         int                `json:"clusterId"`
+	Namespace         string             `json:"namespace"`
+	Placement         *util.PodPlacement `json:"placement"`
+	Sources           map[string]string  `json:"sources"`
+	ClusterPolicyId   int                `json:"clusterPolicyId,omitempty"`
+	NamespacePolicyId int                `json:"namespacePolicyId,omitempty"`
+}
+
+type PodPlacementPolicyService interface {
+	Create(bean *PodPlacementPolicyBean, userId int32) (*PodPlacementPolicyBean, error)
+	Update(bean *PodPlacementPolicyBean, userId int32) (*PodPlacementPolicyBean, error)
+	Delete(id int, userId int32) error
+	FindAll(clusterId int) ([]*PodPlacementPolicyBean, error)
+	GetEffectivePlacement(clusterId int, namespace string) (*EffectivePodPlacement, error)
+	ResolvePodPlacement(clusterId int, namespace string) (*util.PodPlacement, error)
+}
+
+type PodPlacementPolicyServiceImpl struct {
+	logger                       *zap.SugaredLogger
+	podPlacementPolicyRepository repository.PodPlacementPolicyRepository
+	clusterRepository            repository.ClusterRepository
+}
+
+func NewPodPlacementPolicyServiceImpl(logger *zap.SugaredLogger, podPlacementPolicyRepository repository.PodPlacementPolicyRepository,
+	clusterRepository repository.ClusterRepository, K8sUtil *util.K8sUtil) *PodPlacementPolicyServiceImpl {
+	impl := &PodPlacementPolicyServiceImpl{
+		logger:                       logger,
+		podPlacementPolicyRepository: podPlacementPolicyRepository,
+		clusterRepository:            clusterRepository,
+	}
+	K8sUtil.SetPodPlacementResolver(impl)
+	return impl
+}
+
+func (impl *PodPlacementPolicyServiceImpl) Create(bean *PodPlacementPolicyBean, userId int32) (*PodPlacementPolicyBean, error) {
+	if err := impl.validate(bean); err != nil {
+		return nil, err
+	}
+	policies, err := impl.podPlacementPolicyRepository.FindActiveForNamespace(bean.ClusterId, bean.Namespace)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting pod placement policies", "clusterId", bean.ClusterId, "namespace", bean.Namespace, "err", err)
+		return nil, err
+	}
+	for _, policy := range policies {
+		if policy.Namespace == bean.Namespace {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, Code: strconv.Itoa(http.StatusConflict),
+				UserMessage: fmt.Sprintf("pod placement policy %d already exists for this cluster and namespace", policy.Id)}
+		}
+	}
+	now := time.Now()
+	model := &repository.PodPlacementPolicy{
+		ClusterId:        bean.ClusterId,
+		Namespace:        bean.Namespace,
+		NodeSelector:     bean.NodeSelector,
+		Tolerations:      bean.Tolerations,
+		RuntimeClassName: bean.RuntimeClassName,
+		Active:           true,
+		AuditLog:         sql.AuditLog{CreatedOn: now, CreatedBy: userId, UpdatedOn: now, UpdatedBy: userId},
+	}
+	err = impl.podPlacementPolicyRepository.Save(model)
+	if err != nil {
+		impl.logger.Errorw("error in saving pod placement policy", "clusterId", bean.ClusterId, "namespace", bean.Namespace, "err", err)
+		return nil, err
+	}
+	return adaptPodPlacementPolicy(model), nil
+}
+
+// Update changes the placement, the cluster and namespace of a policy can not be changed
+func (impl *PodPlacementPolicyServiceImpl) Update(bean *PodPlacementPolicyBean, userId int32) (*PodPlacementPolicyBean, error) {
+	model, err := impl.findById(bean.Id)
+	if err != nil {
+		return nil, err
+	}
+	bean.ClusterId, bean.Namespace = model.ClusterId, model.Namespace
+	if err = impl.validate(bean); err != nil {
+		return nil, err
+	}
+	model.NodeSelector = bean.NodeSelector
+	model.Tolerations = bean.Tolerations
+	model.RuntimeClassName = bean.RuntimeClassName
+	model.UpdatedOn = time.Now()
+	model.UpdatedBy = userId
+	err = impl.podPlacementPolicyRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("error in updating pod placement policy", "id", bean.Id, "err", err)
+		return nil, err
+	}
+	return adaptPodPlacementPolicy(model), nil
+}
+
+func (impl *PodPlacementPolicyServiceImpl) Delete(id int, userId int32) error {
+	model, err := impl.findById(id)
+	if err != nil {
+		return err
+	}
+	model.Active = false
+	model.UpdatedOn = time.Now()
+	model.UpdatedBy = userId
+	err = impl.podPlacementPolicyRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("error in deleting pod placement policy", "id", id, "err", err)
+	}
+	return err
+}
+
+func (impl *PodPlacementPolicyServiceImpl) FindAll(clusterId int) ([]*PodPlacementPolicyBean, error) {
+	models, err := impl.podPlacementPolicyRepository.FindAllActive(clusterId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting pod placement policies", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+	beans := make([]*PodPlacementPolicyBean, 0, len(models))
+	for _, model := range models {
+		beans = append(beans, adaptPodPlacementPolicy(model))
+	}
+	return beans, nil
+}
+
+func (impl *PodPlacementPolicyServiceImpl) GetEffectivePlacement(clusterId int, namespace string) (*EffectivePodPlacement, error) {
+	policies, err := impl.podPlacementPolicyRepository.FindActiveForNamespace(clusterId, namespace)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting pod placement policies", "clusterId", clusterId, "namespace", namespace, "err", err)
+		return nil, err
+	}
+	return effectivePodPlacement(clusterId, namespace, policies), nil
+}
+
+// ResolvePodPlacement implements util.PodPlacementResolver for the jobs and terminal pods created through K8sUtil
+func (impl *PodPlacementPolicyServiceImpl) ResolvePodPlacement(clusterId int, namespace string) (*util.PodPlacement, error) {
+	effective, err := impl.GetEffectivePlacement(clusterId, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return effective.Placement, nil
+}
+
+func (impl *PodPlacementPolicyServiceImpl) findById(id int) (*repository.PodPlacementPolicy, error) {
+	model, err := impl.podPlacementPolicyRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, Code: strconv.Itoa(http.StatusNotFound), UserMessage: "pod placement policy not found"}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting pod placement policy", "id", id, "err", err)
+		return nil, err
+	}
+	return model, nil
+}
+
+func (impl *PodPlacementPolicyServiceImpl) validate(bean *PodPlacementPolicyBean) error {
+	badRequest := func(message string) error {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: message}
+	}
+	placement := &util.PodPlacement{NodeSelector: bean.NodeSelector, Tolerations: bean.Tolerations, RuntimeClassName: bean.RuntimeClassName}
+	if placement.IsEmpty() {
+		return badRequest("at least one of nodeSelector, tolerations or runtimeClassName is required")
+	}
+	for _, toleration := range bean.Tolerations {
+		if toleration.Operator == v1.TolerationOpExists && len(toleration.Value) > 0 {
+			return badRequest(fmt.Sprintf("toleration %s with operator Exists must not have a value", toleration.Key))
+		}
+		if len(toleration.Key) == 0 && toleration.Operator != v1.TolerationOpExists {
+			return badRequest("toleration without key must use operator Exists")
+		}
+	}
+	if _, err := impl.clusterRepository.FindById(bean.ClusterId); err == pg.ErrNoRows {
+		return badRequest(fmt.Sprintf("cluster %d not found", bean.ClusterId))
+	} else if err != nil {
+		impl.logger.Errorw("error in getting cluster", "clusterId", bean.ClusterId, "err", err)
+		return err
+	}
+	return nil
+}
+
+// effectivePodPlacement merges the cluster wide policy with the policy of the namespace, see util.MergePodPlacement
+func effectivePodPlacement(clusterId int, namespace string, policies []*repository.PodPlacementPolicy) *EffectivePodPlacement {
+	effective := &EffectivePodPlacement{ClusterId: clusterId, Namespace: namespace, Sources: make(map[string]string)}
+	var clusterPlacement, namespacePlacement *util.PodPlacement
+	for _, policy := range policies {
+		placement := &util.PodPlacement{NodeSelector: policy.NodeSelector, Tolerations: policy.Tolerations, RuntimeClassName: policy.RuntimeClassName}
+		if len(policy.Namespace) == 0 {
+			clusterPlacement, effective.ClusterPolicyId = placement, policy.Id
+		} else if policy.Namespace == namespace {
+			namespacePlacement, effective.NamespacePolicyId = placement, policy.Id
+		}
+	}
+	for source, placement := range map[string]*util.PodPlacement{PodPlacementSourceCluster: clusterPlacement, PodPlacementSourceNamespace: namespacePlacement} {
+		if placement == nil {
+			continue
+		}
+		// the namespace wins, a field set in both is attributed to the namespace whatever the order of the map
+		setSource := func(field string, isSet bool) {
+			if isSet && (source == PodPlacementSourceNamespace || len(effective.Sources[field]) == 0) {
+				effective.Sources[field] = source
+			}
+		}
+		setSource("nodeSelector", len(placement.NodeSelector) > 0)
+		setSource("tolerations", len(placement.Tolerations) > 0)
+		setSource("runtimeClassName", len(placement.RuntimeClassName) > 0)
+	}
+	effective.Placement = util.MergePodPlacement(clusterPlacement, namespacePlacement)
+	return effective
+}
+
+func adaptPodPlacementPolicy(model *repository.PodPlacementPolicy) *PodPlacementPolicyBean {
+	return &PodPlacementPolicyBean{
+		Id:               model.Id,
+		ClusterId:        model.ClusterId,
+		Namespace:        model.Namespace,
+		NodeSelector:     model.NodeSelector,
+		Tolerations:      model.Tolerations,
+		RuntimeClassName: model.RuntimeClassName,
+	}
+}
@@ -0,0 +1,34 @@
+package cluster
+
+import (
+	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"testing"
+)
+
+func TestEffectivePodPlacement(t *testing.T) {
+	toleration := v1.Toleration{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "ci", Effect: v1.TaintEffectNoSchedule}
+	clusterPolicy := &repository.PodPlacementPolicy{Id: 1, ClusterId: 2, NodeSelector: map[string]string{"pool": "utility"}, Tolerations: []v1.Toleration{toleration}}
+	namespacePolicy := &repository.PodPlacementPolicy{Id: 3, ClusterId: 2, Namespace: "team-a", NodeSelector: map[string]string{"pool": "team-a"}, RuntimeClassName: "gvisor"}
+
+	effective := effectivePodPlacement(2, "team-a", []*repository.PodPlacementPolicy{namespacePolicy, clusterPolicy})
+	assert.Equal(t, 1, effective.ClusterPolicyId)
+	assert.Equal(t, 3, effective.NamespacePolicyId)
+	assert.Equal(t, map[string]string{"pool": "team-a"}, effective.Placement.NodeSelector)
+	assert.Equal(t, []v1.Toleration{toleration}, effective.Placement.Tolerations)
+	assert.Equal(t, "gvisor", effective.Placement.RuntimeClassName)
+	assert.Equal(t, map[string]string{
+		"nodeSelector":     PodPlacementSourceNamespace,
+		"tolerations":      PodPlacementSourceCluster,
+		"runtimeClassName": PodPlacementSourceNamespace,
+	}, effective.Sources)
+
+	effective = effectivePodPlacement(2, "team-b", []*repository.PodPlacementPolicy{clusterPolicy})
+	assert.Equal(t, map[string]string{"pool": "utility"}, effective.Placement.NodeSelector)
+	assert.Equal(t, 0, effective.NamespacePolicyId)
+
+	effective = effectivePodPlacement(2, "team-b", nil)
+	assert.Nil(t, effective.Placement)
+	assert.Empty(t, effective.Sources)
+}
@@ -21,24 +21,32 @@ import (
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/go-pg/pg"
 	"go.uber.org/zap"
+	"time"
 )
 
 type Cluster struct {
-	tableName              struct{}          `sql:"cluster" pg:",discard_unknown_columns"`
-	Id                     int               `sql:"id,pk"`
-	ClusterName            string            `sql:"cluster_name"`
-	ServerUrl              string            `sql:"server_url"`
-	PrometheusEndpoint     string            `sql:"prometheus_endpoint"`
-	Active                 bool              `sql:"active,notnull"`
-	CdArgoSetup            bool              `sql:"cd_argo_setup,notnull"`
-	Config                 map[string]string `sql:"config"`
-	PUserName              string            `sql:"p_username"`
-	PPassword              string            `sql:"p_password"`
-	PTlsClientCert         string            `sql:"p_tls_client_cert"`
-	PTlsClientKey          string            `sql:"p_tls_client_key"`
-	AgentInstallationStage int               `sql:"agent_installation_stage"`
-	K8sVersion             string            `sql:"k8s_version"`
-	ErrorInConnecting      string            `sql:"error_in_connecting"`
+	tableName              struct{}                   `sql:"cluster" pg:",discard_unknown_columns"`
+	Id                     int                        `sql:"id,pk"`
+	ClusterName            string                     `sql:"cluster_name"`
+	ServerUrl              string                     `sql:"server_url"`
+	PrometheusEndpoint     string                     `sql:"prometheus_endpoint"`
+	Active                 bool                       `sql:"active,notnull"`
+	CdArgoSetup            bool                       `sql:"cd_argo_setup,notnull"`
+	Config                 sql.EncryptedClusterConfig `sql:"config"`
+	PUserName              string                     `sql:"p_username"`
+	PPassword              sql.EncryptedString        `sql:"p_password"`
+	PTlsClientCert         string                     `sql:"p_tls_client_cert"`
+	PTlsClientKey          sql.EncryptedString        `sql:"p_tls_client_key"`
+	AgentInstallationStage int                        `sql:"agent_installation_stage"`
+	K8sVersion             string                     `sql:"k8s_version"`
+	ErrorInConnecting      string                     `sql:"error_in_connecting"`
+	// terminal defaults are used for sessions which do not set base image or shell, empty falls back to global defaults
+	TerminalDefaultBaseImage string `sql:"terminal_default_base_image"`
+	TerminalDefaultShell     string `sql:"terminal_default_shell"`
+	// namespace policy limits the namespaces devtron touches in the cluster, an empty mode means no policy
+	NamespacePolicyMode          string   `sql:"namespace_policy_mode"`
+	NamespacePolicyPatterns      []string `sql:"namespace_policy_patterns"`
+	NamespacePolicyRestrictReads bool     `sql:"namespace_policy_restrict_reads,notnull"`
 	sql.AuditLog
 }
 
@@ -53,8 +61,10 @@ type ClusterRepository interface {
 	FindByIds(id []int) ([]Cluster, error)
 	Update(model *Cluster) error
 	Delete(model *Cluster) error
-	MarkClusterDeleted(model *Cluster) error
+	MarkClusterDeleted(model *Cluster, tx *pg.Tx) error
 	UpdateClusterConnectionStatus(clusterId int, errorInConnecting string) error
+	UpdateTerminalDefaults(clusterId int, baseImage string, shell string, userId int32) error
+	UpdateNamespacePolicy(clusterId int, mode string, patterns []string, restrictReads bool, userId int32) error
 }
 
 func NewClusterRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *ClusterRepositoryImpl {
@@ -142,9 +152,9 @@ func (impl ClusterRepositoryImpl) Delete(model *Cluster) error {
 	return impl.dbConnection.Delete(model)
 }
 
-func (impl ClusterRepositoryImpl) MarkClusterDeleted(model *Cluster) error {
+func (impl ClusterRepositoryImpl) MarkClusterDeleted(model *Cluster, tx *pg.Tx) error {
 	model.Active = false
-	return impl.dbConnection.Update(model)
+	return sql.Connection(impl.dbConnection, tx).Update(model)
 }
 
 func (impl ClusterRepositoryImpl) UpdateClusterConnectionStatus(clusterId int, errorInConnecting string) error {
@@ -154,3 +164,33 @@ func (impl ClusterRepositoryImpl) UpdateClusterConnectionStatus(clusterId int, e
 		Update()
 	return err
 }
+
+func (impl ClusterRepositoryImpl) UpdateTerminalDefaults(clusterId int, baseImage string, shell string, userId int32) error {
+	cluster := &Cluster{}
+	_, err := impl.dbConnection.Model(cluster).
+		Set("terminal_default_base_image = ?", baseImage).
+		Set("terminal_default_shell = ?", shell).
+		Set("updated_by = ?", userId).
+		Set("updated_on = ?", time.Now()).
+		Where("id = ?", clusterId).
+		Where("active = ?", true).
+		Update()
+	return err
+}
+
+func (impl ClusterRepositoryImpl) UpdateNamespacePolicy(clusterId int, mode string, patterns []string, restrictReads bool, userId int32) error {
+	cluster := &Cluster{
+		Id:                           clusterId,
+		NamespacePolicyMode:          mode,
+		NamespacePolicyPatterns:      patterns,
+		NamespacePolicyRestrictReads: restrictReads,
+	}
+	cluster.UpdatedBy = userId
+	cluster.UpdatedOn = time.Now()
+	_, err := impl.dbConnection.Model(cluster).
+		Column("namespace_policy_mode", "namespace_policy_patterns", "namespace_policy_restrict_reads", "updated_by", "updated_on").
+		WherePK().
+		Where("active = ?", true).
+		Update()
+	return err
+}
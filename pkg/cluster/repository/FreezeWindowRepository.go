@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+	"time"
+)
+
+// FreezeWindow blocks changes made by devtron in a cluster, or in the namespace of one of its environments when
+// EnvironmentId is set, between StartTime and EndTime. Users with one of OverrideRoles may still make changes
+type FreezeWindow struct {
+	tableName     struct{}  `sql:"freeze_window" pg:",discard_unknown_columns"`
+	Id            int       `sql:"id,pk"`
+	Name          string    `sql:"name"`
+	ClusterId     int       `sql:"cluster_id"`
+	EnvironmentId int       `sql:"environment_id"`
+	StartTime     time.Time `sql:"start_time"`
+	EndTime       time.Time `sql:"end_time"`
+	Reason        string    `sql:"reason"`
+	OverrideRoles []string  `sql:"override_roles"`
+	Active        bool      `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type FreezeWindowRepository interface {
+	Save(window *FreezeWindow) error
+	Update(window *FreezeWindow) error
+	FindById(id int) (*FreezeWindow, error)
+	// FindAllActive returns windows which did not end before since, of all clusters when clusterId is 0
+	FindAllActive(clusterId int, since time.Time) ([]*FreezeWindow, error)
+	// FindInEffect returns the windows of the cluster and of the environment running at the time
+	FindInEffect(clusterId int, environmentId int, at time.Time) ([]*FreezeWindow, error)
+}
+
+type FreezeWindowRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewFreezeWindowRepositoryImpl(dbConnection *pg.DB) *FreezeWindowRepositoryImpl {
+	return &FreezeWindowRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl FreezeWindowRepositoryImpl) Save(window *FreezeWindow) error {
+	return impl.dbConnection.Insert(window)
+}
+
+func (impl FreezeWindowRepositoryImpl) Update(window *FreezeWindow) error {
+	return impl.dbConnection.Update(window)
+}
+
+func (impl FreezeWindowRepositoryImpl) FindById(id int) (*FreezeWindow, error) {
+	window := &FreezeWindow{}
+	err := impl.dbConnection.Model(window).Where("id = ?", id).Where("active = ?", true).Select()
+	return window, err
+}
+
+func (impl FreezeWindowRepositoryImpl) FindAllActive(clusterId int, since time.Time) ([]*FreezeWindow, error) {
+	var windows []*FreezeWindow
+	query := impl.dbConnection.Model(&windows).Where("active = ?", true).Where("end_time > ?", since)
+	if clusterId > 0 {
+		query = query.Where("cluster_id = ?", clusterId)
+	}
+	err := query.Order("start_time").Select()
+	return windows, err
+}
+
+func (impl FreezeWindowRepositoryImpl) FindInEffect(clusterId int, environmentId int, at time.Time) ([]*FreezeWindow, error) {
+	var windows []*FreezeWindow
+	err := impl.dbConnection.Model(&windows).
+		Where("cluster_id = ?", clusterId).
+		WhereGroup(func(query *orm.Query) (*orm.Query, error) {
+			query = query.WhereOr("environment_id IS NULL").WhereOr("environment_id = ?", environmentId)
+			return query, nil
+		}).
+		Where("start_time <= ?", at).
+		Where("end_time > ?", at).
+		Where("active = ?", true).
+		Order("end_time DESC").
+		Select()
+	return windows, err
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	v1 "k8s.io/api/core/v1"
+)
+
+// PodPlacementPolicy is the placement of jobs and terminal pods of a cluster, or of one of its namespaces when
+// Namespace is set
+type PodPlacementPolicy struct {
+	tableName        struct{}          `sql:"pod_placement_policy" pg:",discard_unknown_columns"`
+	Id               int               `sql:"id,pk"`
+	ClusterId        int               `sql:"cluster_id"`
+	Namespace        string            `sql:"namespace,notnull"`
+	NodeSelector     map[string]string `sql:"node_selector"`
+	Tolerations      []v1.Toleration   `sql:"tolerations"`
+	RuntimeClassName string            `sql:"runtime_class_name"`
+	Active           bool              `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type PodPlacementPolicyRepository interface {
+	Save(policy *PodPlacementPolicy) error
+	Update(policy *PodPlacementPolicy) error
+	FindById(id int) (*PodPlacementPolicy, error)
+	FindAllActive(clusterId int) ([]*PodPlacementPolicy, error)
+	// FindActiveForNamespace returns the cluster wide policy and the policy of the namespace, whichever exist
+	FindActiveForNamespace(clusterId int, namespace string) ([]*PodPlacementPolicy, error)
+}
+
+type PodPlacementPolicyRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewPodPlacementPolicyRepositoryImpl(dbConnection *pg.DB) *PodPlacementPolicyRepositoryImpl {
+	return &PodPlacementPolicyRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl PodPlacementPolicyRepositoryImpl) Save(policy *PodPlacementPolicy) error {
+	return impl.dbConnection.Insert(policy)
+}
+
+func (impl PodPlacementPolicyRepositoryImpl) Update(policy *PodPlacementPolicy) error {
+	return impl.dbConnection.Update(policy)
+}
+
+func (impl PodPlacementPolicyRepositoryImpl) FindById(id int) (*PodPlacementPolicy, error) {
+	policy := &PodPlacementPolicy{}
+	err := impl.dbConnection.Model(policy).Where("id = ?", id).Where("active = ?", true).Select()
+	return policy, err
+}
+
+// FindAllActive returns the policies of all clusters when clusterId is 0
+func (impl PodPlacementPolicyRepositoryImpl) FindAllActive(clusterId int) ([]*PodPlacementPolicy, error) {
+	var policies []*PodPlacementPolicy
+	query := impl.dbConnection.Model(&policies).Where("active = ?", true)
+	if clusterId > 0 {
+		query = query.Where("cluster_id = ?", clusterId)
+	}
+	err := query.Order("cluster_id").Order("namespace").Select()
+	return policies, err
+}
+
+func (impl PodPlacementPolicyRepositoryImpl) FindActiveForNamespace(clusterId int, namespace string) ([]*PodPlacementPolicy, error) {
+	var policies []*PodPlacementPolicy
+	err := impl.dbConnection.Model(&policies).
+		Where("cluster_id = ?", clusterId).
+		Where("namespace in (?)", pg.In([]string{"", namespace})).
+		Where("active = ?", true).
+		Select()
+	return policies, err
+}
@@ -0,0 +1,337 @@
+package clusterOperation
+
+import (
+	"context"
+	"fmt"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/pagination"
+	"github.com/go-pg/pg"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	OperationTypeNodeDrain         = "NODE_DRAIN"
+	OperationTypeConfigPropagation = "CONFIG_PROPAGATION"
+	OperationTypeChartSync         = "CHART_SYNC"
+	OperationTypeOrphanCleanup     = "ORPHAN_CLEANUP"
+	OperationTypeSecretRotation    = "SECRET_ROTATION"
+
+	orphanedOperationMessage = "the orchestrator instance running the operation stopped responding"
+	ClusterRemovedMessage    = "cancelled, the cluster was removed"
+)
+
+// ClusterOperationConfig sets how often an instance refreshes the heartbeat of the operations it runs and after how
+// long without one an operation is considered orphaned by an instance which went away
+type ClusterOperationConfig struct {
+	HeartbeatIntervalInSecs int `env:"CLUSTER_OPERATION_HEARTBEAT_INTERVAL_IN_SECS" envDefault:"30"`
+	StaleAfterInSecs        int `env:"CLUSTER_OPERATION_STALE_AFTER_IN_SECS" envDefault:"120"`
+}
+
+func GetClusterOperationConfig() (*ClusterOperationConfig, error) {
+	cfg := &ClusterOperationConfig{}
+	err := env.Parse(cfg)
+	return cfg, err
+}
+
+type ClusterOperationBean struct {
+	Id              int        `json:"id"`
+	OperationType   string     `json:"operationType"`
+	ClusterId       int        `json:"clusterId,omitempty"`
+	Namespace       string     `json:"namespace,omitempty"`
+	ResourceName    string     `json:"resourceName,omitempty"`
+	State           string     `json:"state"`
+	ProgressMessage string     `json:"progressMessage"`
+	StartedOn       time.Time  `json:"startedOn"`
+	FinishedOn      *time.Time `json:"finishedOn,omitempty"`
+	UserId          int32      `json:"userId"`
+	Checkpoint      string     `json:"checkpoint,omitempty"`
+}
+
+type ClusterOperationStartedResponse struct {
+	OperationId int `json:"operationId"`
+}
+
+// ProgressFunc records a progress message on the operation, failures to record are logged and not returned
+type ProgressFunc func(message string)
+
+// CheckpointFunc records the state a resumed operation continues from, failures to record are logged and not returned
+type CheckpointFunc func(checkpoint string)
+
+type ClusterOperationService interface {
+	// Start registers the operation as running and runs it in the background, the operation id is returned right away
+	Start(ctx context.Context, operation *ClusterOperationBean, run func(ctx context.Context, progress ProgressFunc) error) (int, error)
+	// StartResumable is Start for operations recording checkpoints, the operation starts from its Checkpoint which is
+	// usually the one of the failed operation being resumed
+	StartResumable(ctx context.Context, operation *ClusterOperationBean, run func(ctx context.Context, progress ProgressFunc, checkpoint CheckpointFunc) error) (int, error)
+	FindById(id int) (*ClusterOperationBean, error)
+	FindAll(filter *repository.ClusterOperationFilter, request *pagination.ListingRequest) (*pagination.ListingResponse, error)
+	FindRunningByClusterId(clusterId int) ([]*ClusterOperationBean, error)
+	// MarkClusterOperationsCancelled fails the running operations of the cluster on tx, CancelClusterOperations has to
+	// be called once tx is committed to stop their goroutines
+	MarkClusterOperationsCancelled(clusterId int, tx *pg.Tx) (int, error)
+	CancelClusterOperations(clusterId int)
+}
+
+type ClusterOperationServiceImpl struct {
+	logger                     *zap.SugaredLogger
+	clusterOperationRepository repository.ClusterOperationRepository
+	config                     *ClusterOperationConfig
+	// instanceId identifies this process as the owner of the operations it starts
+	instanceId string
+	// runningLock guards running, the cancel funcs of the operations of this process keyed by operation id
+	runningLock sync.Mutex
+	running     map[int]*runningOperation
+}
+
+type runningOperation struct {
+	clusterId int
+	cancel    context.CancelFunc
+}
+
+func NewClusterOperationServiceImpl(logger *zap.SugaredLogger, clusterOperationRepository repository.ClusterOperationRepository) *ClusterOperationServiceImpl {
+	config, err := GetClusterOperationConfig()
+	if err != nil {
+		logger.Errorw("error in parsing cluster operation config, using defaults", "err", err)
+		config = &ClusterOperationConfig{HeartbeatIntervalInSecs: 30, StaleAfterInSecs: 120}
+	}
+	impl := newClusterOperationService(logger, clusterOperationRepository, config, newInstanceId())
+	impl.reconcileOrphanedOperations(time.Now())
+	go impl.heartbeatLoop()
+	return impl
+}
+
+func newClusterOperationService(logger *zap.SugaredLogger, clusterOperationRepository repository.ClusterOperationRepository, config *ClusterOperationConfig, instanceId string) *ClusterOperationServiceImpl {
+	return &ClusterOperationServiceImpl{
+		logger:                     logger,
+		clusterOperationRepository: clusterOperationRepository,
+		config:                     config,
+		instanceId:                 instanceId,
+		running:                    make(map[int]*runningOperation),
+	}
+}
+
+// newInstanceId is the pod name followed by a random suffix, a restarted pod keeps its name but not its operations
+func newInstanceId() string {
+	hostname, err := os.Hostname()
+	if err != nil || len(hostname) == 0 {
+		hostname = "orchestrator"
+	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8])
+}
+
+// heartbeatLoop keeps the operations of this instance alive and fails the ones other instances stopped refreshing
+func (impl *ClusterOperationServiceImpl) heartbeatLoop() {
+	if impl.config.HeartbeatIntervalInSecs <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(impl.config.HeartbeatIntervalInSecs) * time.Second)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		impl.heartbeat(now)
+		impl.reconcileOrphanedOperations(now)
+	}
+}
+
+func (impl *ClusterOperationServiceImpl) heartbeat(now time.Time) {
+	impl.runningLock.Lock()
+	ids := make([]int, 0, len(impl.running))
+	for id := range impl.running {
+		ids = append(ids, id)
+	}
+	impl.runningLock.Unlock()
+	if _, err := impl.clusterOperationRepository.UpdateHeartbeat(ids, impl.instanceId, now); err != nil {
+		impl.logger.Errorw("error in updating heartbeat of cluster operations", "instanceId", impl.instanceId, "err", err)
+	}
+}
+
+// reconcileOrphanedOperations fails the running operations whose heartbeat is stale, the instance which ran them is
+// gone. Operations of live instances, this one included, keep running
+func (impl *ClusterOperationServiceImpl) reconcileOrphanedOperations(now time.Time) {
+	staleBefore := now.Add(-time.Duration(impl.config.StaleAfterInSecs) * time.Second)
+	count, err := impl.clusterOperationRepository.MarkStaleRunningAsFailed(orphanedOperationMessage, staleBefore, now)
+	if err != nil {
+		impl.logger.Errorw("error in marking orphaned cluster operations as failed", "err", err)
+		return
+	}
+	if count > 0 {
+		impl.logger.Infow("marked orphaned cluster operations as failed", "count", count, "staleBefore", staleBefore)
+	}
+}
+
+func (impl *ClusterOperationServiceImpl) Start(ctx context.Context, operation *ClusterOperationBean, run func(ctx context.Context, progress ProgressFunc) error) (int, error) {
+	return impl.StartResumable(ctx, operation, func(ctx context.Context, progress ProgressFunc, _ CheckpointFunc) error {
+		return run(ctx, progress)
+	})
+}
+
+func (impl *ClusterOperationServiceImpl) StartResumable(ctx context.Context, operation *ClusterOperationBean, run func(ctx context.Context, progress ProgressFunc, checkpoint CheckpointFunc) error) (int, error) {
+	logger := util.LoggerFromContext(ctx, impl.logger)
+	now := time.Now()
+	model := &repository.ClusterOperation{
+		OperationType:   operation.OperationType,
+		ClusterId:       operation.ClusterId,
+		Namespace:       operation.Namespace,
+		ResourceName:    operation.ResourceName,
+		State:           repository.ClusterOperationRunning,
+		ProgressMessage: "started",
+		StartedOn:       now,
+		UserId:          operation.UserId,
+		Checkpoint:      operation.Checkpoint,
+		OwnerInstanceId: impl.instanceId,
+		HeartbeatOn:     &now,
+		AuditLog:        sql.AuditLog{CreatedOn: now, CreatedBy: operation.UserId, UpdatedOn: now, UpdatedBy: operation.UserId},
+	}
+	err := impl.clusterOperationRepository.Save(model)
+	if err != nil {
+		logger.Errorw("error in saving cluster operation", "operationType", operation.OperationType, "err", err)
+		return 0, err
+	}
+	// the request context is cancelled once the response is written, only the request id and user are carried over
+	runCtx := util.ContextWithRequestId(context.Background(), util.RequestIdFromContext(ctx))
+	runCtx = util.ContextWithUserId(runCtx, operation.UserId)
+	runCtx, cancel := context.WithCancel(runCtx)
+	impl.runningLock.Lock()
+	impl.running[model.Id] = &runningOperation{clusterId: model.ClusterId, cancel: cancel}
+	impl.runningLock.Unlock()
+	go impl.run(runCtx, model, run)
+	return model.Id, nil
+}
+
+func (impl *ClusterOperationServiceImpl) run(ctx context.Context, model *repository.ClusterOperation, run func(ctx context.Context, progress ProgressFunc, checkpoint CheckpointFunc) error) {
+	logger := util.LoggerFromContext(ctx, impl.logger)
+	defer func() {
+		impl.runningLock.Lock()
+		if running, ok := impl.running[model.Id]; ok {
+			running.cancel()
+			delete(impl.running, model.Id)
+		}
+		impl.runningLock.Unlock()
+	}()
+	lastMessage := ""
+	progress := func(message string) {
+		lastMessage = message
+		impl.update(ctx, model, repository.ClusterOperationRunning, message)
+	}
+	checkpoint := func(checkpoint string) {
+		model.Checkpoint = checkpoint
+		model.UpdatedOn = time.Now()
+		if err := impl.clusterOperationRepository.UpdateCheckpoint(model); err != nil {
+			logger.Errorw("error in updating cluster operation checkpoint", "operationId", model.Id, "err", err)
+		}
+	}
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("operation panicked: %v", r)
+				logger.Errorw("panic in cluster operation", "operationId", model.Id, "operationType", model.OperationType, "panic", r)
+			}
+		}()
+		err = run(ctx, progress, checkpoint)
+	}()
+	if ctx.Err() == context.Canceled {
+		// the state was already written by whoever cancelled the operation
+		return
+	}
+	if err != nil {
+		impl.update(ctx, model, repository.ClusterOperationFailed, err.Error())
+		return
+	}
+	// the last progress message of a successful run usually is its outcome
+	if len(lastMessage) == 0 {
+		lastMessage = "completed"
+	}
+	impl.update(ctx, model, repository.ClusterOperationSucceeded, lastMessage)
+}
+
+func (impl *ClusterOperationServiceImpl) update(ctx context.Context, model *repository.ClusterOperation, state string, message string) {
+	now := time.Now()
+	model.State = state
+	model.ProgressMessage = message
+	model.UpdatedOn = now
+	if state != repository.ClusterOperationRunning {
+		model.FinishedOn = &now
+	}
+	err := impl.clusterOperationRepository.UpdateProgress(model)
+	if err != nil {
+		util.LoggerFromContext(ctx, impl.logger).Errorw("error in updating cluster operation", "operationId", model.Id, "state", state, "err", err)
+	}
+}
+
+func (impl *ClusterOperationServiceImpl) FindById(id int) (*ClusterOperationBean, error) {
+	model, err := impl.clusterOperationRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster operation", "id", id, "err", err)
+		return nil, err
+	}
+	return adaptClusterOperation(model), nil
+}
+
+func (impl *ClusterOperationServiceImpl) FindAll(filter *repository.ClusterOperationFilter, request *pagination.ListingRequest) (*pagination.ListingResponse, error) {
+	models, totalCount, err := impl.clusterOperationRepository.FindAll(filter, request)
+	if err != nil {
+		impl.logger.Errorw("error in listing cluster operations", "filter", filter, "err", err)
+		return nil, err
+	}
+	operations := make([]*ClusterOperationBean, 0, len(models))
+	for _, model := range models {
+		operations = append(operations, adaptClusterOperation(model))
+	}
+	return pagination.NewListingResponse(request, totalCount, operations), nil
+}
+
+func (impl *ClusterOperationServiceImpl) FindRunningByClusterId(clusterId int) ([]*ClusterOperationBean, error) {
+	models, err := impl.clusterOperationRepository.FindRunningByClusterId(clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting running cluster operations", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+	operations := make([]*ClusterOperationBean, 0, len(models))
+	for _, model := range models {
+		operations = append(operations, adaptClusterOperation(model))
+	}
+	return operations, nil
+}
+
+func (impl *ClusterOperationServiceImpl) MarkClusterOperationsCancelled(clusterId int, tx *pg.Tx) (int, error) {
+	count, err := impl.clusterOperationRepository.MarkRunningAsFailedForCluster(clusterId, ClusterRemovedMessage, time.Now(), tx)
+	if err != nil {
+		impl.logger.Errorw("error in cancelling cluster operations", "clusterId", clusterId, "err", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (impl *ClusterOperationServiceImpl) CancelClusterOperations(clusterId int) {
+	impl.runningLock.Lock()
+	defer impl.runningLock.Unlock()
+	for id, running := range impl.running {
+		if running.clusterId == clusterId {
+			running.cancel()
+			delete(impl.running, id)
+		}
+	}
+}
+
+func adaptClusterOperation(model *repository.ClusterOperation) *ClusterOperationBean {
+	return &ClusterOperationBean{
+		Id:              model.Id,
+		OperationType:   model.OperationType,
+		ClusterId:       model.ClusterId,
+		Namespace:       model.Namespace,
+		ResourceName:    model.ResourceName,
+		State:           model.State,
+		ProgressMessage: model.ProgressMessage,
+		StartedOn:       model.StartedOn,
+		FinishedOn:      model.FinishedOn,
+		UserId:          model.UserId,
+		Checkpoint:      model.Checkpoint,
+	}
+}
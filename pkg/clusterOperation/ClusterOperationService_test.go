@@ -0,0 +1,96 @@
+package clusterOperation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type clusterOperationRepositoryStub struct {
+	repository.ClusterOperationRepository
+	lock        sync.Mutex
+	saved       []*repository.ClusterOperation
+	updates     chan string
+	heartbeats  [][]int
+	staleBefore []time.Time
+}
+
+func newClusterOperationRepositoryStub() *clusterOperationRepositoryStub {
+	return &clusterOperationRepositoryStub{updates: make(chan string, 10)}
+}
+
+func (s *clusterOperationRepositoryStub) Save(operation *repository.ClusterOperation) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	operation.Id = len(s.saved) + 1
+	s.saved = append(s.saved, operation)
+	return nil
+}
+
+func (s *clusterOperationRepositoryStub) UpdateProgress(operation *repository.ClusterOperation) error {
+	s.updates <- operation.State
+	return nil
+}
+
+func (s *clusterOperationRepositoryStub) UpdateHeartbeat(ids []int, ownerInstanceId string, now time.Time) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.heartbeats = append(s.heartbeats, ids)
+	return len(ids), nil
+}
+
+func (s *clusterOperationRepositoryStub) MarkStaleRunningAsFailed(message string, staleBefore time.Time, now time.Time) (int, error) {
+	s.staleBefore = append(s.staleBefore, staleBefore)
+	return 0, nil
+}
+
+func newTestClusterOperationService(operationRepository *clusterOperationRepositoryStub) *ClusterOperationServiceImpl {
+	config := &ClusterOperationConfig{HeartbeatIntervalInSecs: 30, StaleAfterInSecs: 120}
+	return newClusterOperationService(zap.NewNop().Sugar(), operationRepository, config, "orchestrator-0-1a2b3c4d")
+}
+
+func TestReconcileOrphanedOperationsOnlyFailsStaleOperations(t *testing.T) {
+	operationRepository := newClusterOperationRepositoryStub()
+	impl := newTestClusterOperationService(operationRepository)
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	impl.reconcileOrphanedOperations(now)
+	assert.Equal(t, []time.Time{now.Add(-2 * time.Minute)}, operationRepository.staleBefore)
+}
+
+func TestStartRecordsOwnerAndHeartbeat(t *testing.T) {
+	operationRepository := newClusterOperationRepositoryStub()
+	impl := newTestClusterOperationService(operationRepository)
+	release := make(chan struct{})
+	id, err := impl.Start(context.Background(), &ClusterOperationBean{OperationType: OperationTypeChartSync, UserId: 2},
+		func(ctx context.Context, progress ProgressFunc) error {
+			<-release
+			return nil
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, id)
+	saved := operationRepository.saved[0]
+	assert.Equal(t, "orchestrator-0-1a2b3c4d", saved.OwnerInstanceId)
+	assert.NotNil(t, saved.HeartbeatOn)
+	assert.Equal(t, repository.ClusterOperationRunning, saved.State)
+
+	// only the operations still running in this instance are kept alive
+	impl.heartbeat(time.Now())
+	close(release)
+	assert.Equal(t, repository.ClusterOperationSucceeded, <-operationRepository.updates)
+	assert.Eventually(t, func() bool {
+		impl.runningLock.Lock()
+		defer impl.runningLock.Unlock()
+		return len(impl.running) == 0
+	}, time.Second, 10*time.Millisecond)
+	impl.heartbeat(time.Now())
+	assert.Equal(t, [][]int{{1}, {}}, operationRepository.heartbeats)
+}
+
+func TestNewInstanceIdIsUniquePerProcessStart(t *testing.T) {
+	assert.NotEqual(t, newInstanceId(), newInstanceId())
+}
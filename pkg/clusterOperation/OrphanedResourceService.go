@@ -0,0 +1,547 @@
+package clusterOperation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/cluster"
+	clusterRepository "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	OrphanReasonAppDeleted       = "app or environment of the appId and envId labels no longer exists"
+	OrphanReasonSessionEnded     = "no live terminal session uses the pod"
+	OrphanReasonOperationUnknown = "no cluster operation was recorded for the job"
+	orphanScanAllNamespaces      = ""
+	// appId and envId are common label names, only objects devtron labelled as its own are candidates
+	orphanAppSelector = util.K8sManagedByLabelKey + "=" + util.DevtronManagedByLabelValue + "," + util.DevtronAppIdLabelKey + "," + util.DevtronEnvIdLabelKey
+	// jobs rendered by DeleteAndCreateJob are labelled managed by devtron too but are not cluster operations
+	orphanOperationJobSelector     = util.K8sManagedByLabelKey + "=" + util.DevtronManagedByLabelValue + ",!" + util.DevtronAppIdLabelKey + "," + util.DevtronPurposeLabelKey + " notin (" + util.DevtronPurposeJob + ")"
+	orphanCleanupProgressBatchSize = 20
+)
+
+var orphanTerminalPodSelector = util.ManagementLabelSelector(util.DevtronPurposeTerminal)
+
+var (
+	orphanConfigMapGvk = schema.GroupVersionKind{Version: util.V1VERSION, Kind: "ConfigMap"}
+	orphanPodGvk       = schema.GroupVersionKind{Version: util.V1VERSION, Kind: "Pod"}
+	orphanJobGvk       = schema.GroupVersionKind{Group: util.BatchGroup, Version: util.V1VERSION, Kind: "Job"}
+)
+
+type OrphanScanConfig struct {
+	PageSize          int     `env:"ORPHAN_SCAN_PAGE_SIZE" envDefault:"100"`
+	RequestsPerSecond float64 `env:"ORPHAN_SCAN_REQUESTS_PER_SECOND" envDefault:"5"`
+}
+
+func GetOrphanScanConfig() (*OrphanScanConfig, error) {
+	cfg := &OrphanScanConfig{}
+	err := env.Parse(cfg)
+	return cfg, err
+}
+
+// OrphanedResource is an object carrying devtron labels whose database record is gone
+type OrphanedResource struct {
+	ClusterId    int       `json:"clusterId"`
+	ClusterName  string    `json:"clusterName"`
+	Namespace    string    `json:"namespace"`
+	Kind         string    `json:"kind"`
+	Name         string    `json:"name"`
+	Uid          types.UID `json:"uid"`
+	Reason       string    `json:"reason"`
+	CreatedOn    time.Time `json:"createdOn"`
+	AgeInSeconds int64     `json:"ageInSeconds"`
+	SizeInBytes  int       `json:"sizeInBytes"`
+}
+
+func (o *OrphanedResource) key() string {
+	return fmt.Sprintf("%d/%s/%s/%s/%s", o.ClusterId, o.Kind, o.Namespace, o.Name, o.Uid)
+}
+
+type OrphanScanResult struct {
+	Orphans []*OrphanedResource `json:"orphans"`
+	// ConfirmationToken identifies the orphans found, cleanup only runs when a new scan yields the same token
+	ConfirmationToken string `json:"confirmationToken"`
+	// ClusterErrors holds clusters which could not be scanned by name, only filled when scanning every cluster
+	ClusterErrors map[string]string `json:"clusterErrors,omitempty"`
+	ScannedOn     time.Time         `json:"scannedOn"`
+}
+
+type OrphanCleanupRequest struct {
+	ClusterId         int    `json:"clusterId"`
+	ConfirmationToken string `json:"confirmationToken" validate:"required"`
+}
+
+type OrphanedResourceService interface {
+	// Scan looks for orphans in the cluster or in every active cluster when clusterId is 0
+	Scan(ctx context.Context, clusterId int) (*OrphanScanResult, error)
+	// Cleanup deletes the orphans of the dry run confirmed by the token in the background, the operation id is returned
+	Cleanup(ctx context.Context, request *OrphanCleanupRequest, userId int32) (int, error)
+}
+
+type OrphanedResourceServiceImpl struct {
+	logger                     *zap.SugaredLogger
+	clusterService             cluster.ClusterService
+	clusterOperationService    ClusterOperationService
+	clusterOperationRepository repository.ClusterOperationRepository
+	terminalAccessRepository   repository.TerminalAccessRepository
+	appRepository              app.AppRepository
+	environmentRepository      clusterRepository.EnvironmentRepository
+	K8sUtil                    *util.K8sUtil
+	config                     *OrphanScanConfig
+	limitersLock               sync.Mutex
+	limiters                   map[int]*rate.Limiter
+}
+
+func NewOrphanedResourceServiceImpl(logger *zap.SugaredLogger, clusterService cluster.ClusterService,
+	clusterOperationService ClusterOperationService, clusterOperationRepository repository.ClusterOperationRepository,
+	terminalAccessRepository repository.TerminalAccessRepository, appRepository app.AppRepository,
+	environmentRepository clusterRepository.EnvironmentRepository, K8sUtil *util.K8sUtil) *OrphanedResourceServiceImpl {
+	config, err := GetOrphanScanConfig()
+	if err != nil {
+		logger.Errorw("error in parsing orphan scan config, using defaults", "err", err)
+		config = &OrphanScanConfig{PageSize: 100, RequestsPerSecond: 5}
+	}
+	return &OrphanedResourceServiceImpl{
+		logger:                     logger,
+		clusterService:             clusterService,
+		clusterOperationService:    clusterOperationService,
+		clusterOperationRepository: clusterOperationRepository,
+		terminalAccessRepository:   terminalAccessRepository,
+		appRepository:              appRepository,
+		environmentRepository:      environmentRepository,
+		K8sUtil:                    K8sUtil,
+		config:                     config,
+		limiters:                   make(map[int]*rate.Limiter),
+	}
+}
+
+// limiter is shared by every scan and cleanup of the cluster so that concurrent requests do not add up
+func (impl *OrphanedResourceServiceImpl) limiter(clusterId int) *rate.Limiter {
+	impl.limitersLock.Lock()
+	defer impl.limitersLock.Unlock()
+	limiter, ok := impl.limiters[clusterId]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(impl.config.RequestsPerSecond), 1)
+		impl.limiters[clusterId] = limiter
+	}
+	return limiter
+}
+
+func (impl *OrphanedResourceServiceImpl) Scan(ctx context.Context, clusterId int) (*OrphanScanResult, error) {
+	logger := util.LoggerFromContext(ctx, impl.logger)
+	var clusters []cluster.ClusterBean
+	if clusterId > 0 {
+		clusterBean, err := impl.clusterService.FindById(clusterId)
+		if err != nil {
+			logger.Errorw("error in getting cluster", "clusterId", clusterId, "err", err)
+			return nil, err
+		}
+		clusters = append(clusters, *clusterBean)
+	} else {
+		activeClusters, err := impl.clusterService.FindAllActive()
+		if err != nil {
+			logger.Errorw("error in getting active clusters", "err", err)
+			return nil, err
+		}
+		clusters = activeClusters
+	}
+	liveSessions, err := impl.terminalAccessRepository.GetAllRunningUserTerminalData()
+	if err != nil {
+		logger.Errorw("error in getting running terminal sessions", "err", err)
+		return nil, err
+	}
+	livePods := make(map[string]bool, len(liveSessions))
+	for _, session := range liveSessions {
+		livePods[terminalPodKey(session.ClusterId, session.PodName)] = true
+	}
+	result := &OrphanScanResult{Orphans: make([]*OrphanedResource, 0), ScannedOn: time.Now()}
+	for i := range clusters {
+		orphans, err := impl.scanCluster(ctx, &clusters[i], livePods, result.ScannedOn)
+		if err != nil {
+			logger.Errorw("error in scanning cluster for orphaned resources", "clusterId", clusters[i].Id, "err", err)
+			if clusterId > 0 {
+				return nil, err
+			}
+			if result.ClusterErrors == nil {
+				result.ClusterErrors = make(map[string]string)
+			}
+			result.ClusterErrors[clusters[i].ClusterName] = err.Error()
+			continue
+		}
+		result.Orphans = append(result.Orphans, orphans...)
+	}
+	result.ConfirmationToken = orphanConfirmationToken(result.Orphans)
+	return result, nil
+}
+
+func (impl *OrphanedResourceServiceImpl) scanCluster(ctx context.Context, clusterBean *cluster.ClusterBean, livePods map[string]bool, now time.Time) ([]*OrphanedResource, error) {
+	clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		return nil, err
+	}
+	clientSet, err := impl.K8sUtil.GetClientSet(clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	limiter := impl.limiter(clusterBean.Id)
+	newOrphan := func(object metav1.Object, kind string) *OrphanedResource {
+		size := 0
+		if data, err := json.Marshal(object); err == nil {
+			size = len(data)
+		}
+		return &OrphanedResource{
+			ClusterId:    clusterBean.Id,
+			ClusterName:  clusterBean.ClusterName,
+			Namespace:    object.GetNamespace(),
+			Kind:         kind,
+			Name:         object.GetName(),
+			Uid:          object.GetUID(),
+			CreatedOn:    object.GetCreationTimestamp().Time,
+			AgeInSeconds: int64(now.Sub(object.GetCreationTimestamp().Time).Seconds()),
+			SizeInBytes:  size,
+		}
+	}
+	appCandidates := make(map[*OrphanedResource]map[string]string)
+	var orphans []*OrphanedResource
+
+	err = impl.listPages(ctx, limiter, func(options metav1.ListOptions) (string, error) {
+		options.LabelSelector = orphanAppSelector
+		configMaps, err := clientSet.CoreV1().ConfigMaps(orphanScanAllNamespaces).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for i := range configMaps.Items {
+			appCandidates[newOrphan(&configMaps.Items[i], orphanConfigMapGvk.Kind)] = configMaps.Items[i].Labels
+		}
+		return configMaps.Continue, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = impl.listPages(ctx, limiter, func(options metav1.ListOptions) (string, error) {
+		options.LabelSelector = orphanAppSelector
+		jobs, err := clientSet.BatchV1().Jobs(orphanScanAllNamespaces).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for i := range jobs.Items {
+			if len(jobs.Items[i].OwnerReferences) == 0 {
+				appCandidates[newOrphan(&jobs.Items[i], orphanJobGvk.Kind)] = jobs.Items[i].Labels
+			}
+		}
+		return jobs.Continue, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = impl.listPages(ctx, limiter, func(options metav1.ListOptions) (string, error) {
+		options.LabelSelector = orphanOperationJobSelector
+		jobs, err := clientSet.BatchV1().Jobs(orphanScanAllNamespaces).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for i := range jobs.Items {
+			job := &jobs.Items[i]
+			if len(job.OwnerReferences) > 0 {
+				continue
+			}
+			exists, err := impl.clusterOperationRepository.ExistsForResource(clusterBean.Id, job.Namespace, job.Name)
+			if err != nil {
+				return "", err
+			}
+			if !exists {
+				orphan := newOrphan(job, orphanJobGvk.Kind)
+				orphan.Reason = OrphanReasonOperationUnknown
+				orphans = append(orphans, orphan)
+			}
+		}
+		return jobs.Continue, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = impl.listPages(ctx, limiter, func(options metav1.ListOptions) (string, error) {
+		options.LabelSelector = orphanAppSelector
+		pods, err := clientSet.CoreV1().Pods(orphanScanAllNamespaces).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for i := range pods.Items {
+			if len(pods.Items[i].OwnerReferences) == 0 {
+				appCandidates[newOrphan(&pods.Items[i], orphanPodGvk.Kind)] = pods.Items[i].Labels
+			}
+		}
+		return pods.Continue, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = impl.listPages(ctx, limiter, func(options metav1.ListOptions) (string, error) {
+		options.LabelSelector = orphanTerminalPodSelector
+		pods, err := clientSet.CoreV1().Pods(orphanScanAllNamespaces).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if len(pod.OwnerReferences) == 0 && !livePods[terminalPodKey(clusterBean.Id, pod.Name)] {
+				orphan := newOrphan(pod, orphanPodGvk.Kind)
+				orphan.Reason = OrphanReasonSessionEnded
+				orphans = append(orphans, orphan)
+			}
+		}
+		return pods.Continue, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	appOrphans, err := impl.findAppOrphans(appCandidates)
+	if err != nil {
+		return nil, err
+	}
+	return append(orphans, appOrphans...), nil
+}
+
+// listPages calls list with the continue token of the previous page until the last page, waiting on the limiter
+// before each call
+func (impl *OrphanedResourceServiceImpl) listPages(ctx context.Context, limiter *rate.Limiter, list func(options metav1.ListOptions) (string, error)) error {
+	options := metav1.ListOptions{Limit: int64(impl.config.PageSize)}
+	for {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+		continueToken, err := list(options)
+		if err != nil {
+			return err
+		}
+		if len(continueToken) == 0 {
+			return nil
+		}
+		options.Continue = continueToken
+	}
+}
+
+// findAppOrphans returns candidates whose app or environment is not active, labels which are not ids were not set
+// by devtron and are skipped
+func (impl *OrphanedResourceServiceImpl) findAppOrphans(candidates map[*OrphanedResource]map[string]string) ([]*OrphanedResource, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	appIdSet := make(map[int]bool)
+	envIdSet := make(map[int]bool)
+	for _, labels := range candidates {
+		if ids, ok := devtronAppLabels(labels); ok {
+			appIdSet[ids[0]] = true
+			envIdSet[ids[1]] = true
+		}
+	}
+	var appIds, envIds []*int
+	for id := range appIdSet {
+		id := id
+		appIds = append(appIds, &id)
+	}
+	for id := range envIdSet {
+		id := id
+		envIds = append(envIds, &id)
+	}
+	if len(appIds) == 0 {
+		return nil, nil
+	}
+	activeApps, err := impl.appRepository.FindByIds(appIds)
+	if err != nil {
+		return nil, err
+	}
+	activeEnvs, err := impl.environmentRepository.FindByIds(envIds)
+	if err != nil {
+		return nil, err
+	}
+	activeAppIds := make(map[int]bool, len(activeApps))
+	for _, activeApp := range activeApps {
+		activeAppIds[activeApp.Id] = true
+	}
+	activeEnvIds := make(map[int]bool, len(activeEnvs))
+	for _, activeEnv := range activeEnvs {
+		activeEnvIds[activeEnv.Id] = true
+	}
+	var orphans []*OrphanedResource
+	for candidate, labels := range candidates {
+		ids, ok := devtronAppLabels(labels)
+		if !ok || (activeAppIds[ids[0]] && activeEnvIds[ids[1]]) {
+			continue
+		}
+		candidate.Reason = OrphanReasonAppDeleted
+		orphans = append(orphans, candidate)
+	}
+	return orphans, nil
+}
+
+func (impl *OrphanedResourceServiceImpl) Cleanup(ctx context.Context, request *OrphanCleanupRequest, userId int32) (int, error) {
+	result, err := impl.Scan(ctx, request.ClusterId)
+	if err != nil {
+		return 0, err
+	}
+	if result.ConfirmationToken != request.ConfirmationToken {
+		return 0, &util.ApiError{
+			HttpStatusCode: http.StatusConflict,
+			Code:           strconv.Itoa(http.StatusConflict),
+			UserMessage:    "orphaned resources changed since the dry run, scan again and confirm the new result",
+		}
+	}
+	operation := &ClusterOperationBean{
+		OperationType: OperationTypeOrphanCleanup,
+		ClusterId:     request.ClusterId,
+		UserId:        userId,
+	}
+	orphans := result.Orphans
+	return impl.clusterOperationService.Start(ctx, operation, func(ctx context.Context, progress ProgressFunc) error {
+		return impl.deleteOrphans(ctx, orphans, progress)
+	})
+}
+
+// deleteOrphans skips orphans which are still in use or frozen, skipped orphans do not fail the operation and are
+// listed in the last progress message
+func (impl *OrphanedResourceServiceImpl) deleteOrphans(ctx context.Context, orphans []*OrphanedResource, progress ProgressFunc) error {
+	logger := util.LoggerFromContext(ctx, impl.logger)
+	clusterConfigs := make(map[int]*util.ClusterConfig)
+	deleted := 0
+	var skipped []string
+	for i, orphan := range orphans {
+		clusterConfig, ok := clusterConfigs[orphan.ClusterId]
+		if !ok {
+			clusterBean, err := impl.clusterService.FindById(orphan.ClusterId)
+			if err != nil {
+				return err
+			}
+			clusterConfig, err = impl.clusterService.GetClusterConfig(clusterBean)
+			if err != nil {
+				return err
+			}
+			clusterConfigs[orphan.ClusterId] = clusterConfig
+		}
+		if err := impl.limiter(orphan.ClusterId).Wait(ctx); err != nil {
+			return err
+		}
+		reason, err := impl.deleteOrphan(ctx, clusterConfig, orphan)
+		if err != nil {
+			logger.Errorw("error in deleting orphaned resource", "orphan", orphan.key(), "err", err)
+			reason = err.Error()
+		}
+		if len(reason) > 0 {
+			skipped = append(skipped, fmt.Sprintf("%s %s/%s in cluster %s: %s", orphan.Kind, orphan.Namespace, orphan.Name, orphan.ClusterName, reason))
+		} else {
+			deleted++
+		}
+		if (i+1)%orphanCleanupProgressBatchSize == 0 {
+			progress(fmt.Sprintf("processed %d of %d orphaned resources, deleted %d", i+1, len(orphans), deleted))
+		}
+	}
+	message := fmt.Sprintf("deleted %d of %d orphaned resources", deleted, len(orphans))
+	if len(skipped) > 0 {
+		message = fmt.Sprintf("%s, skipped %d: %s", message, len(skipped), strings.Join(skipped, "; "))
+	}
+	progress(message)
+	return nil
+}
+
+// deleteOrphan returns why the orphan was left alone, config maps mounted by pods and jobs with active pods are in
+// use and freeze windows are honoured like for any other delete
+func (impl *OrphanedResourceServiceImpl) deleteOrphan(ctx context.Context, clusterConfig *util.ClusterConfig, orphan *OrphanedResource) (string, error) {
+	var gvk schema.GroupVersionKind
+	switch orphan.Kind {
+	case orphanConfigMapGvk.Kind:
+		gvk = orphanConfigMapGvk
+		usage, err := impl.K8sUtil.GetConfigMapUsage(ctx, orphan.Namespace, orphan.Name, clusterConfig)
+		if err != nil {
+			return "", err
+		}
+		if len(usage.Pods) > 0 {
+			return fmt.Sprintf("in use by %d pods", len(usage.Pods)), nil
+		}
+	case orphanJobGvk.Kind:
+		gvk = orphanJobGvk
+		active, err := impl.activeJobPods(ctx, clusterConfig, orphan)
+		if err != nil {
+			return "", err
+		}
+		if active > 0 {
+			return fmt.Sprintf("job has %d active pods", active), nil
+		}
+	case orphanPodGvk.Kind:
+		gvk = orphanPodGvk
+	default:
+		return "unsupported kind", nil
+	}
+	if err := impl.K8sUtil.CheckMutation(ctx, clusterConfig, orphan.Namespace, orphan.Kind, orphan.Name, util.MutationActionDelete); err != nil {
+		if apiErr, ok := err.(*util.ApiError); ok {
+			return fmt.Sprint(apiErr.UserMessage), nil
+		}
+		return "", err
+	}
+	return "", impl.K8sUtil.DeleteResourceByUid(ctx, gvk, orphan.Namespace, orphan.Name, orphan.Uid, clusterConfig)
+}
+
+func (impl *OrphanedResourceServiceImpl) activeJobPods(ctx context.Context, clusterConfig *util.ClusterConfig, orphan *OrphanedResource) (int32, error) {
+	clientSet, err := impl.K8sUtil.GetClientSet(clusterConfig)
+	if err != nil {
+		return 0, err
+	}
+	job, err := clientSet.BatchV1().Jobs(orphan.Namespace).Get(ctx, orphan.Name, metav1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return job.Status.Active, nil
+}
+
+// devtronAppLabels returns the app and environment ids of resources deployed by devtron
+func devtronAppLabels(labels map[string]string) ([2]int, bool) {
+	appIdLabel, ok := labels[util.DevtronAppIdLabelKey]
+	if !ok {
+		return [2]int{}, false
+	}
+	envIdLabel, ok := labels[util.DevtronEnvIdLabelKey]
+	if !ok {
+		return [2]int{}, false
+	}
+	appId, err := strconv.Atoi(appIdLabel)
+	if err != nil {
+		return [2]int{}, false
+	}
+	envId, err := strconv.Atoi(envIdLabel)
+	if err != nil {
+		return [2]int{}, false
+	}
+	return [2]int{appId, envId}, true
+}
+
+func terminalPodKey(clusterId int, podName string) string {
+	return fmt.Sprintf("%d/%s", clusterId, podName)
+}
+
+// orphanConfirmationToken does not depend on the order in which orphans were found
+func orphanConfirmationToken(orphans []*OrphanedResource) string {
+	keys := make([]string, 0, len(orphans))
+	for _, orphan := range orphans {
+		keys = append(keys, orphan.key())
+	}
+	sort.Strings(keys)
+	hash := sha256.Sum256([]byte(strings.Join(keys, "\n")))
+	return hex.EncodeToString(hash[:])
+}
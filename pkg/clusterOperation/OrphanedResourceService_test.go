@@ -0,0 +1,64 @@
+package clusterOperation
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestDevtronAppLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   [2]int
+		wantOk bool
+	}{
+		{name: "app and env ids", labels: map[string]string{"appId": "12", "envId": "3"}, want: [2]int{12, 3}, wantOk: true},
+		{name: "env id missing", labels: map[string]string{"appId": "12"}},
+		{name: "not an id", labels: map[string]string{"appId": "payments", "envId": "3"}},
+		{name: "no labels"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, ok := devtronAppLabels(tt.labels)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestOrphanSelectors(t *testing.T) {
+	terminalPod := util.ManagementLabels{Purpose: util.DevtronPurposeTerminal, UserId: 2, SessionId: "terminal-access-1-2-abcde"}.Labels()
+	job := util.ManagementLabels{Purpose: util.DevtronPurposeJob, OperationId: "chart-sync"}.Labels()
+	operationJob := map[string]string{util.K8sManagedByLabelKey: util.DevtronManagedByLabelValue}
+
+	terminalSelector, err := labels.Parse(orphanTerminalPodSelector)
+	assert.Nil(t, err)
+	assert.True(t, terminalSelector.Matches(labels.Set(terminalPod)))
+	assert.False(t, terminalSelector.Matches(labels.Set(job)))
+	assert.False(t, terminalSelector.Matches(labels.Set(map[string]string{"app": "nginx"})))
+
+	appSelector, err := labels.Parse(orphanAppSelector)
+	assert.Nil(t, err)
+	assert.True(t, appSelector.Matches(labels.Set(map[string]string{util.K8sManagedByLabelKey: util.DevtronManagedByLabelValue, "appId": "12", "envId": "3"})))
+	assert.False(t, appSelector.Matches(labels.Set(map[string]string{"appId": "12", "envId": "3"})))
+	assert.False(t, appSelector.Matches(labels.Set(map[string]string{util.K8sManagedByLabelKey: "Helm", "appId": "12", "envId": "3"})))
+
+	// jobs created with DeleteAndCreateJob are not cluster operations
+	operationSelector, err := labels.Parse(orphanOperationJobSelector)
+	assert.Nil(t, err)
+	assert.True(t, operationSelector.Matches(labels.Set(operationJob)))
+	assert.False(t, operationSelector.Matches(labels.Set(job)))
+}
+
+func TestOrphanConfirmationToken(t *testing.T) {
+	configMap := &OrphanedResource{ClusterId: 1, Kind: "ConfigMap", Namespace: "prod", Name: "payments-cm", Uid: "a"}
+	pod := &OrphanedResource{ClusterId: 1, Kind: "Pod", Namespace: "default", Name: "terminal-access-1-2-abcde", Uid: "b"}
+	recreatedPod := &OrphanedResource{ClusterId: 1, Kind: "Pod", Namespace: "default", Name: "terminal-access-1-2-abcde", Uid: "c"}
+
+	assert.Equal(t, orphanConfirmationToken([]*OrphanedResource{configMap, pod}), orphanConfirmationToken([]*OrphanedResource{pod, configMap}))
+	assert.NotEqual(t, orphanConfirmationToken([]*OrphanedResource{configMap, pod}), orphanConfirmationToken([]*OrphanedResource{configMap, recreatedPod}))
+	assert.NotEqual(t, orphanConfirmationToken([]*OrphanedResource{configMap, pod}), orphanConfirmationToken([]*OrphanedResource{configMap}))
+}
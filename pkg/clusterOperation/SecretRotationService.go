@@ -0,0 +1,214 @@
+package clusterOperation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/cluster"
+	"go.uber.org/zap"
+)
+
+// SecretRotationRequest replaces the data of the secret in every namespace of the cluster having a copy matching
+// Selector, e.g. when a registry password rotates. ResumeOperationId continues a failed rotation of the same secret,
+// the namespaces it completed are skipped
+type SecretRotationRequest struct {
+	ClusterId         int               `json:"clusterId" validate:"required,min=1"`
+	SecretName        string            `json:"secretName" validate:"required"`
+	Data              map[string][]byte `json:"data" validate:"required,min=1"`
+	Selector          string            `json:"selector"`
+	RestartWorkloads  bool              `json:"restartWorkloads"`
+	DryRun            bool              `json:"dryRun"`
+	ResumeOperationId int               `json:"resumeOperationId"`
+}
+
+// SecretRotationResponse holds the results of a dry run or the id of the rotation operation
+type SecretRotationResponse struct {
+	DryRun      bool                         `json:"dryRun"`
+	OperationId int                          `json:"operationId,omitempty"`
+	Results     []*util.SecretRotationResult `json:"results,omitempty"`
+}
+
+// secretRotationCheckpoint is the checkpoint of a rotation operation, the data is never recorded so a resume has to
+// send it again
+type secretRotationCheckpoint struct {
+	Selector string                                `json:"selector"`
+	Results  map[string]*util.SecretRotationResult `json:"results"`
+}
+
+// done returns the namespaces which need nothing more, a namespace whose workloads could not all be restarted is retried
+func (c *secretRotationCheckpoint) done() map[string]bool {
+	done := make(map[string]bool, len(c.Results))
+	for namespace, result := range c.Results {
+		switch result.Status {
+		case util.SecretRotationRotated, util.SecretRotationUnchanged, util.SecretRotationSkipped:
+			done[namespace] = len(result.Error) == 0
+		}
+	}
+	return done
+}
+
+type SecretRotationService interface {
+	// RotateSecret returns the results right away for a dry run, otherwise the id of the operation rotating the secret
+	RotateSecret(ctx context.Context, request *SecretRotationRequest, userId int32) (*SecretRotationResponse, error)
+}
+
+type SecretRotationServiceImpl struct {
+	logger                  *zap.SugaredLogger
+	clusterService          cluster.ClusterService
+	clusterOperationService ClusterOperationService
+	K8sUtil                 *util.K8sUtil
+}
+
+func NewSecretRotationServiceImpl(logger *zap.SugaredLogger, clusterService cluster.ClusterService,
+	clusterOperationService ClusterOperationService, K8sUtil *util.K8sUtil) *SecretRotationServiceImpl {
+	return &SecretRotationServiceImpl{
+		logger:                  logger,
+		clusterService:          clusterService,
+		clusterOperationService: clusterOperationService,
+		K8sUtil:                 K8sUtil,
+	}
+}
+
+func (impl *SecretRotationServiceImpl) RotateSecret(ctx context.Context, request *SecretRotationRequest, userId int32) (*SecretRotationResponse, error) {
+	logger := util.LoggerFromContext(ctx, impl.logger)
+	if len(request.Selector) == 0 {
+		request.Selector = util.DefaultSecretRotationSelector
+	}
+	checkpoint, err := impl.resumedCheckpoint(request)
+	if err != nil {
+		return nil, err
+	}
+	clusterBean, err := impl.clusterService.FindById(request.ClusterId)
+	if err != nil {
+		logger.Errorw("error in getting cluster", "clusterId", request.ClusterId, "err", err)
+		return nil, err
+	}
+	clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		logger.Errorw("error in getting cluster config", "clusterId", request.ClusterId, "err", err)
+		return nil, err
+	}
+	options := &util.SecretRotationOptions{
+		DryRun:           request.DryRun,
+		RestartWorkloads: request.RestartWorkloads,
+		RestartUnchanged: request.ResumeOperationId > 0,
+		Done:             checkpoint.done(),
+	}
+	if request.DryRun {
+		results, err := impl.K8sUtil.RotateSecretEverywhere(ctx, clusterConfig, request.SecretName, request.Data, request.Selector, options)
+		if err != nil {
+			return nil, err
+		}
+		return &SecretRotationResponse{DryRun: true, Results: results}, nil
+	}
+	initialCheckpoint, err := json.Marshal(checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	operation := &ClusterOperationBean{
+		OperationType: OperationTypeSecretRotation,
+		ClusterId:     request.ClusterId,
+		ResourceName:  request.SecretName,
+		UserId:        userId,
+		Checkpoint:    string(initialCheckpoint),
+	}
+	secretName, data := request.SecretName, request.Data
+	operationId, err := impl.clusterOperationService.StartResumable(ctx, operation, func(ctx context.Context, progress ProgressFunc, checkpointFunc CheckpointFunc) error {
+		processed := 0
+		options.OnResult = func(result *util.SecretRotationResult) {
+			processed++
+			if result.Status != util.SecretRotationSkipped {
+				checkpoint.Results[result.Namespace] = result
+				if content, err := json.Marshal(checkpoint); err == nil {
+					checkpointFunc(string(content))
+				}
+			}
+			progress(fmt.Sprintf("processed %d namespaces", processed))
+		}
+		results, err := impl.K8sUtil.RotateSecretEverywhere(ctx, clusterConfig, secretName, data, checkpoint.Selector, options)
+		if err != nil {
+			return err
+		}
+		message, failed := secretRotationSummary(results)
+		if failed {
+			return fmt.Errorf("%s", message)
+		}
+		progress(message)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SecretRotationResponse{OperationId: operationId}, nil
+}
+
+// resumedCheckpoint returns the checkpoint of the operation being resumed, or an empty one for a new rotation
+func (impl *SecretRotationServiceImpl) resumedCheckpoint(request *SecretRotationRequest) (*secretRotationCheckpoint, error) {
+	checkpoint := &secretRotationCheckpoint{Selector: request.Selector, Results: make(map[string]*util.SecretRotationResult)}
+	if request.ResumeOperationId == 0 {
+		return checkpoint, nil
+	}
+	operation, err := impl.clusterOperationService.FindById(request.ResumeOperationId)
+	if err != nil {
+		return nil, err
+	}
+	if operation.OperationType != OperationTypeSecretRotation || operation.ClusterId != request.ClusterId || operation.ResourceName != request.SecretName {
+		return nil, secretRotationConflict(fmt.Sprintf("operation %d is not a rotation of secret %s in cluster %d", operation.Id, request.SecretName, request.ClusterId))
+	}
+	if operation.State == repository.ClusterOperationRunning {
+		return nil, secretRotationConflict(fmt.Sprintf("operation %d is still running", operation.Id))
+	}
+	if len(operation.Checkpoint) > 0 {
+		if err = json.Unmarshal([]byte(operation.Checkpoint), checkpoint); err != nil {
+			impl.logger.Errorw("error in parsing secret rotation checkpoint", "operationId", operation.Id, "err", err)
+			return nil, err
+		}
+		if checkpoint.Results == nil {
+			checkpoint.Results = make(map[string]*util.SecretRotationResult)
+		}
+	}
+	if checkpoint.Selector != request.Selector {
+		return nil, secretRotationConflict(fmt.Sprintf("operation %d rotated secrets matching %q, resume it with the same selector", operation.Id, checkpoint.Selector))
+	}
+	return checkpoint, nil
+}
+
+func secretRotationConflict(message string) error {
+	return &util.ApiError{
+		HttpStatusCode:  http.StatusConflict,
+		Code:            strconv.Itoa(http.StatusConflict),
+		UserMessage:     message,
+		InternalMessage: message,
+	}
+}
+
+// secretRotationSummary counts the results by status and lists the namespaces with errors
+func secretRotationSummary(results []*util.SecretRotationResult) (string, bool) {
+	counts := make(map[string]int)
+	var errs []string
+	failed := false
+	for _, result := range results {
+		counts[result.Status]++
+		if result.Status == util.SecretRotationFailed {
+			failed = true
+		}
+		if len(result.Error) > 0 {
+			failed = true
+			errs = append(errs, fmt.Sprintf("%s: %s", result.Namespace, result.Error))
+		}
+	}
+	sort.Strings(errs)
+	message := fmt.Sprintf("rotated %d, unchanged %d, skipped %d, failed %d of %d namespaces", counts[util.SecretRotationRotated],
+		counts[util.SecretRotationUnchanged], counts[util.SecretRotationSkipped], counts[util.SecretRotationFailed], len(results))
+	if len(errs) > 0 {
+		message = fmt.Sprintf("%s, errors: %s", message, strings.Join(errs, "; "))
+	}
+	return message, failed
+}
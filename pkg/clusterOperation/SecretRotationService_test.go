@@ -0,0 +1,38 @@
+package clusterOperation
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretRotationCheckpointDone(t *testing.T) {
+	checkpoint := &secretRotationCheckpoint{Results: map[string]*util.SecretRotationResult{
+		"payments": {Namespace: "payments", Status: util.SecretRotationRotated},
+		"orders":   {Namespace: "orders", Status: util.SecretRotationUnchanged},
+		"billing":  {Namespace: "billing", Status: util.SecretRotationRotated, Error: "error in restarting workloads Deployment/api: forbidden"},
+		"search":   {Namespace: "search", Status: util.SecretRotationFailed, Error: "timeout"},
+	}}
+	done := checkpoint.done()
+	assert.True(t, done["payments"])
+	assert.True(t, done["orders"])
+	assert.False(t, done["billing"])
+	assert.False(t, done["search"])
+}
+
+func TestSecretRotationSummary(t *testing.T) {
+	message, failed := secretRotationSummary([]*util.SecretRotationResult{
+		{Namespace: "payments", Status: util.SecretRotationRotated},
+		{Namespace: "orders", Status: util.SecretRotationSkipped},
+	})
+	assert.False(t, failed)
+	assert.Equal(t, "rotated 1, unchanged 0, skipped 1, failed 0 of 2 namespaces", message)
+
+	message, failed = secretRotationSummary([]*util.SecretRotationResult{
+		{Namespace: "search", Status: util.SecretRotationFailed, Error: "timeout"},
+		{Namespace: "billing", Status: util.SecretRotationRotated, Error: "restart failed"},
+	})
+	assert.True(t, failed)
+	assert.Equal(t, "rotated 1, unchanged 0, skipped 0, failed 1 of 2 namespaces, errors: billing: restart failed; search: timeout", message)
+}
@@ -0,0 +1,95 @@
+package clusterTerminalAccess
+
+import (
+	"fmt"
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	TerminalPodNameRandomIdLength    = 5
+	TerminalPodNameMaxRenderAttempts = 3
+)
+
+var terminalPodNameTemplateVarRegex = regexp.MustCompile(`\$\{[^}]*\}`)
+
+var terminalPodNameRequiredVars = []string{
+	models.TerminalAccessClusterIdTemplateVar,
+	models.TerminalAccessUserIdTemplateVar,
+	models.TerminalAccessRandomIdVar,
+}
+
+// TerminalPodNameRenderer renders terminal pod names from a validated template, rendered names are always
+// valid DNS-1123 labels and keep the random id so that retries on collision produce a different name
+type TerminalPodNameRenderer struct {
+	template string
+}
+
+func NewTerminalPodNameRenderer(template string) (*TerminalPodNameRenderer, error) {
+	err := ValidateTerminalPodNameTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	return &TerminalPodNameRenderer{template: template}, nil
+}
+
+// ValidateTerminalPodNameTemplate rejects templates which miss a required variable, use an unknown one
+// or can not produce a valid pod name
+func ValidateTerminalPodNameTemplate(template string) error {
+	for _, requiredVar := range terminalPodNameRequiredVars {
+		if !strings.Contains(template, requiredVar) {
+			return fmt.Errorf("terminal pod name template %q is missing required variable %s", template, requiredVar)
+		}
+	}
+	if strings.Count(template, models.TerminalAccessRandomIdVar) != 1 {
+		return fmt.Errorf("terminal pod name template %q must contain %s exactly once", template, models.TerminalAccessRandomIdVar)
+	}
+	for _, templateVar := range terminalPodNameTemplateVarRegex.FindAllString(template, -1) {
+		if !isTerminalPodNameTemplateVar(templateVar) {
+			return fmt.Errorf("terminal pod name template %q contains unsupported variable %s", template, templateVar)
+		}
+	}
+	_, err := renderTerminalPodName(template, 1, 1, strings.Repeat("a", TerminalPodNameRandomIdLength))
+	return err
+}
+
+func isTerminalPodNameTemplateVar(templateVar string) bool {
+	for _, requiredVar := range terminalPodNameRequiredVars {
+		if requiredVar == templateVar {
+			return true
+		}
+	}
+	return false
+}
+
+func (impl *TerminalPodNameRenderer) Render(clusterId int, userId int32) (string, error) {
+	return renderTerminalPodName(impl.template, clusterId, userId, rand.String(TerminalPodNameRandomIdLength))
+}
+
+// renderTerminalPodName truncates the part before the random id when the name exceeds the label limit,
+// so the result only depends on the inputs and the random id is never cut
+func renderTerminalPodName(template string, clusterId int, userId int32, randomId string) (string, error) {
+	podName := strings.ReplaceAll(template, models.TerminalAccessClusterIdTemplateVar, strconv.Itoa(clusterId))
+	podName = strings.ReplaceAll(podName, models.TerminalAccessUserIdTemplateVar, strconv.FormatInt(int64(userId), 10))
+	parts := strings.SplitN(podName, models.TerminalAccessRandomIdVar, 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("terminal pod name template %q is missing required variable %s", template, models.TerminalAccessRandomIdVar)
+	}
+	prefix, suffix := parts[0], parts[1]
+	maxPrefixLength := validation.DNS1123LabelMaxLength - len(randomId) - len(suffix)
+	if maxPrefixLength < 1 {
+		return "", fmt.Errorf("terminal pod name template %q leaves no room for a name prefix", template)
+	}
+	if len(prefix) > maxPrefixLength {
+		prefix = prefix[:maxPrefixLength]
+	}
+	podName = prefix + randomId + suffix
+	if errs := validation.IsDNS1123Label(podName); len(errs) > 0 {
+		return "", fmt.Errorf("invalid terminal pod name %q: %s", podName, strings.Join(errs, ", "))
+	}
+	return podName, nil
+}
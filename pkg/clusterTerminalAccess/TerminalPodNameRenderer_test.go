@@ -0,0 +1,128 @@
+package clusterTerminalAccess
+
+import (
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTerminalPodNameRenderer(t *testing.T) {
+	t.Run("DefaultTemplateFromConfig", func(tt *testing.T) {
+		config, err := GetTerminalAccessConfig()
+		assert.Nil(tt, err)
+		assert.Equal(tt, models.TerminalAccessPodNameTemplate, config.TerminalPodNameTemplate)
+		_, err = NewTerminalPodNameRenderer(config.TerminalPodNameTemplate)
+		assert.Nil(tt, err)
+	})
+
+	renderTests := []struct {
+		name      string
+		template  string
+		clusterId int
+		userId    int32
+		randomId  string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "short ids",
+			template:  models.TerminalAccessPodNameTemplate,
+			clusterId: 1,
+			userId:    2,
+			randomId:  "abcde",
+			want:      "terminal-access-1-2-abcde",
+		},
+		{
+			name:      "max ids fit in the label",
+			template:  models.TerminalAccessPodNameTemplate,
+			clusterId: math.MaxInt64,
+			userId:    math.MaxInt32,
+			randomId:  "abcde",
+			want:      "terminal-access-9223372036854775807-2147483647-abcde",
+		},
+		{
+			name:      "long static prefix is truncated keeping random id",
+			template:  strings.Repeat("x", 70) + "-${cluster_id}-${user_id}-${random_id}",
+			clusterId: 1,
+			userId:    2,
+			randomId:  "abcde",
+			want:      strings.Repeat("x", 58) + "abcde",
+		},
+		{
+			name:      "suffix after random id is kept",
+			template:  strings.Repeat("x", 70) + "-${cluster_id}-${user_id}-${random_id}-tty",
+			clusterId: 1,
+			userId:    2,
+			randomId:  "abcde",
+			want:      strings.Repeat("x", 54) + "abcde-tty",
+		},
+		{
+			name:      "upper case template",
+			template:  "Terminal-${cluster_id}-${user_id}-${random_id}",
+			clusterId: 1,
+			userId:    2,
+			randomId:  "abcde",
+			wantErr:   true,
+		},
+		{
+			name:      "suffix leaves no room for prefix",
+			template:  "${cluster_id}${user_id}${random_id}" + strings.Repeat("x", 60),
+			clusterId: 1,
+			userId:    2,
+			randomId:  "abcde",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range renderTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderTerminalPodName(tt.template, tt.clusterId, tt.userId, tt.randomId)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Empty(t, validation.IsDNS1123Label(got))
+		})
+	}
+
+	t.Run("RenderIsAlwaysValidLabel", func(tt *testing.T) {
+		renderer, err := NewTerminalPodNameRenderer(strings.Repeat("a", 60) + "-${cluster_id}-${user_id}-${random_id}")
+		assert.Nil(tt, err)
+		seen := make(map[string]bool)
+		for i := 0; i < 10; i++ {
+			podName, err := renderer.Render(math.MaxInt64, math.MaxInt32)
+			assert.Nil(tt, err)
+			assert.LessOrEqual(tt, len(podName), validation.DNS1123LabelMaxLength)
+			assert.Empty(tt, validation.IsDNS1123Label(podName))
+			seen[podName] = true
+		}
+		assert.Greater(tt, len(seen), 1)
+	})
+
+	validationTests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{name: "default template", template: models.TerminalAccessPodNameTemplate},
+		{name: "missing random id", template: "terminal-${cluster_id}-${user_id}", wantErr: true},
+		{name: "missing user id", template: "terminal-${cluster_id}-${random_id}", wantErr: true},
+		{name: "missing cluster id", template: "terminal-${user_id}-${random_id}", wantErr: true},
+		{name: "random id twice", template: "terminal-${cluster_id}-${user_id}-${random_id}-${random_id}", wantErr: true},
+		{name: "unknown variable", template: "terminal-${cluster_id}-${user_id}-${random_id}-${pod_name}", wantErr: true},
+		{name: "unterminated variable", template: "terminal-${cluster_id}-${user_id}-${random_id}-${", wantErr: true},
+		{name: "invalid characters", template: "terminal_${cluster_id}.${user_id}-${random_id}", wantErr: true},
+		{name: "leading hyphen", template: "-${cluster_id}-${user_id}-${random_id}", wantErr: true},
+		{name: "empty", template: "", wantErr: true},
+	}
+	for _, tt := range validationTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTerminalPodNameTemplate(tt.template)
+			assert.Equal(t, tt.wantErr, err != nil, "err: %v", err)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package clusterTerminalAccess
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/devtron-labs/devtron/internal/util"
+	authorizationV1 "k8s.io/api/authorization/v1"
+)
+
+const terminalPreflightMetadataKey = "PreflightDenied"
+
+// terminalSessionPermissions are the actions a session needs in its namespace, the pod is created, exec'd into and
+// its logs are read
+var terminalSessionPermissions = []authorizationV1.ResourceAttributes{
+	{Verb: "create", Resource: "pods"},
+	{Verb: "create", Resource: "pods", Subresource: "exec"},
+	{Verb: "get", Resource: "pods", Subresource: "log"},
+}
+
+func terminalPreflightCacheKey(request *models.UserTerminalSessionRequest) string {
+	return fmt.Sprintf("%d/%d/%s", request.UserId, request.ClusterId, request.Namespace)
+}
+
+// preflightTerminalSession fails the start right away when the cluster does not allow what the session needs, instead
+// of the pod failing after the Starting phase. A denied start is recorded as a session with the denied status
+func (impl *UserTerminalAccessServiceImpl) preflightTerminalSession(ctx context.Context, request *models.UserTerminalSessionRequest) error {
+	logger := util.LoggerFromContext(ctx, impl.Logger)
+	results, err := impl.reviewTerminalPermissions(ctx, request)
+	if err != nil {
+		logger.Errorw("error in reviewing terminal permissions", "clusterId", request.ClusterId, "namespace", request.Namespace, "err", err)
+		return err
+	}
+	details, denied := terminalPreflightDetails(results)
+	if !denied {
+		return nil
+	}
+	logger.Infow("terminal start denied by permission preflight", "userId", request.UserId, "clusterId", request.ClusterId, "namespace", request.Namespace, "details", details)
+	impl.saveDeniedTerminalSession(request, results)
+	return &util.ApiError{
+		HttpStatusCode:  http.StatusForbidden,
+		Code:            strconv.Itoa(http.StatusForbidden),
+		UserMessage:     models.TerminalPermissionDeniedMsg,
+		InternalMessage: models.TerminalPermissionDeniedMsg,
+		Details:         details,
+	}
+}
+
+// reviewTerminalPermissions reuses the outcome for the same user, cluster and namespace for a short while so that
+// retried starts do not review again
+func (impl *UserTerminalAccessServiceImpl) reviewTerminalPermissions(ctx context.Context, request *models.UserTerminalSessionRequest) ([]util.AccessReviewResult, error) {
+	cacheKey := terminalPreflightCacheKey(request)
+	if impl.preflightCache != nil {
+		if results, found := impl.preflightCache.Get(cacheKey); found {
+			return results.([]util.AccessReviewResult), nil
+		}
+	}
+	attributes := make([]authorizationV1.ResourceAttributes, 0, len(terminalSessionPermissions))
+	for _, permission := range terminalSessionPermissions {
+		permission.Namespace = request.Namespace
+		attributes = append(attributes, permission)
+	}
+	results, err := impl.k8sApplicationService.ReviewSelfAccess(ctx, request.ClusterId, attributes)
+	if err != nil {
+		return nil, err
+	}
+	if impl.preflightCache != nil {
+		impl.preflightCache.SetDefault(cacheKey, results)
+	}
+	return results, nil
+}
+
+// terminalPreflightDetails is the per permission breakdown, denied is set when any permission is missing
+func terminalPreflightDetails(results []util.AccessReviewResult) ([]string, bool) {
+	details := make([]string, 0, len(results))
+	denied := false
+	for _, result := range results {
+		details = append(details, result.String())
+		denied = denied || !result.Allowed
+	}
+	return details, denied
+}
+
+func (impl *UserTerminalAccessServiceImpl) saveDeniedTerminalSession(request *models.UserTerminalSessionRequest, results []util.AccessReviewResult) {
+	metadata, err := impl.getMetadataMap(impl.extractMetadataString(request))
+	if err != nil {
+		metadata = make(map[string]string)
+	}
+	breakdown, err := json.Marshal(results)
+	if err == nil {
+		metadata[terminalPreflightMetadataKey] = string(breakdown)
+	}
+	metadataJsonBytes, err := json.Marshal(metadata)
+	if err != nil {
+		impl.Logger.Errorw("error occurred while converting metadata to json", "request", request, "err", err)
+		return
+	}
+	userAccessData := &models.UserTerminalAccessData{
+		UserId:    request.UserId,
+		ClusterId: request.ClusterId,
+		NodeName:  request.NodeName,
+		Status:    string(models.TerminalPodDenied),
+		Metadata:  string(metadataJsonBytes),
+	}
+	err = impl.TerminalAccessRepository.SaveUserTerminalAccessData(userAccessData, nil)
+	if err != nil {
+		impl.Logger.Errorw("error occurred while saving denied terminal session", "userId", request.UserId, "clusterId", request.ClusterId, "err", err)
+	}
+}
@@ -0,0 +1,39 @@
+package clusterTerminalAccess
+
+import (
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"strings"
+)
+
+var terminalShellNames = map[string]bool{"bash": true, "sh": true, "powershell": true, "cmd": true}
+
+func isValidTerminalShell(shellName string) bool {
+	return terminalShellNames[shellName]
+}
+
+// resolveSessionDefault returns the first non empty value in request, cluster and global order along with the source
+// of the default, the source is empty when the request value is used
+func resolveSessionDefault(requested string, clusterDefault string, globalDefault string) (string, string) {
+	if len(requested) > 0 {
+		return requested, ""
+	}
+	if clusterDefault = strings.TrimSpace(clusterDefault); len(clusterDefault) > 0 {
+		return clusterDefault, models.TerminalDefaultSourceCluster
+	}
+	if globalDefault = strings.TrimSpace(globalDefault); len(globalDefault) > 0 {
+		return globalDefault, models.TerminalDefaultSourceGlobal
+	}
+	return "", ""
+}
+
+// parseImageAllowList parses a comma separated list of images, an empty map is returned for an empty list
+func parseImageAllowList(allowList string) map[string]bool {
+	allowedImages := make(map[string]bool)
+	for _, image := range strings.Split(allowList, ",") {
+		image = strings.TrimSpace(image)
+		if len(image) > 0 {
+			allowedImages[image] = true
+		}
+	}
+	return allowedImages
+}
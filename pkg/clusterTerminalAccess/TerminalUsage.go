@@ -0,0 +1,109 @@
+package clusterTerminalAccess
+
+import (
+	"fmt"
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	v1 "k8s.io/api/core/v1"
+	"sort"
+	"time"
+)
+
+const bytesInGib = 1024 * 1024 * 1024
+
+// ParseUsageMonth returns the first instant of the month in UTC, the current month is used when month is empty
+func ParseUsageMonth(month string, now time.Time) (time.Time, error) {
+	if len(month) == 0 {
+		now = now.UTC()
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	monthStart, err := time.Parse(models.TerminalUsageMonthLayout, month)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month %q, expected format YYYY-MM", month)
+	}
+	return monthStart, nil
+}
+
+// podRunningSince returns the time the pod became ready, falling back to its start time
+func podRunningSince(pod *v1.Pod, now time.Time) time.Time {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady && condition.Status == v1.ConditionTrue && !condition.LastTransitionTime.IsZero() {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time
+	}
+	return now
+}
+
+func podRequestedResources(pod *v1.Pod) (cpuMillis int64, memoryBytes int64) {
+	for _, container := range pod.Spec.Containers {
+		if cpu, ok := container.Resources.Requests[v1.ResourceCPU]; ok {
+			cpuMillis += cpu.MilliValue()
+		}
+		if memory, ok := container.Resources.Requests[v1.ResourceMemory]; ok {
+			memoryBytes += memory.Value()
+		}
+	}
+	return cpuMillis, memoryBytes
+}
+
+// runningDurationBetween clips the running duration of the session to [from, to), sessions which are still running
+// are counted till now
+func runningDurationBetween(accessData *models.UserTerminalAccessData, from time.Time, to time.Time, now time.Time) time.Duration {
+	if accessData.RunningStartedOn == nil {
+		return 0
+	}
+	start := *accessData.RunningStartedOn
+	if start.Before(from) {
+		start = from
+	}
+	end := now
+	if accessData.RunningEndedOn != nil {
+		end = *accessData.RunningEndedOn
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// aggregateTerminalUsage sums the usage of sessions per user and cluster, sorted by user and then cluster
+func aggregateTerminalUsage(accessDataArray []*models.UserTerminalAccessData, from time.Time, to time.Time, now time.Time) []*models.UserTerminalUsage {
+	type usageKey struct {
+		userId    int32
+		clusterId int
+	}
+	usageMap := make(map[usageKey]*models.UserTerminalUsage)
+	for _, accessData := range accessDataArray {
+		duration := runningDurationBetween(accessData, from, to, now)
+		if duration == 0 {
+			continue
+		}
+		key := usageKey{userId: accessData.UserId, clusterId: accessData.ClusterId}
+		usage, ok := usageMap[key]
+		if !ok {
+			usage = &models.UserTerminalUsage{UserId: accessData.UserId, ClusterId: accessData.ClusterId}
+			usageMap[key] = usage
+		}
+		hours := duration.Hours()
+		usage.SessionCount++
+		usage.RunningHours += hours
+		usage.CpuCoreHours += hours * float64(accessData.RequestedCpuMillis) / 1000
+		usage.MemoryGibHours += hours * float64(accessData.RequestedMemoryBytes) / bytesInGib
+	}
+	usages := make([]*models.UserTerminalUsage, 0, len(usageMap))
+	for _, usage := range usageMap {
+		usages = append(usages, usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].UserId != usages[j].UserId {
+			return usages[i].UserId < usages[j].UserId
+		}
+		return usages[i].ClusterId < usages[j].ClusterId
+	})
+	return usages
+}
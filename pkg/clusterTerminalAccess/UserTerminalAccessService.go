@@ -10,17 +10,27 @@ import (
 	"github.com/devtron-labs/devtron/client/k8s/application"
 	"github.com/devtron-labs/devtron/internal/sql/models"
 	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/util"
+	clusterRepository "github.com/devtron-labs/devtron/pkg/cluster/repository"
 	"github.com/devtron-labs/devtron/pkg/terminal"
 	"github.com/devtron-labs/devtron/util/k8s"
+	"github.com/devtron-labs/devtron/util/pagination"
+	"github.com/patrickmn/go-cache"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
+	"net/http"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -32,8 +42,19 @@ type UserTerminalAccessService interface {
 	StopTerminalSession(ctx context.Context, userTerminalAccessId int)
 	DisconnectTerminalSession(ctx context.Context, userTerminalAccessId int) error
 	DisconnectAllSessionsForUser(ctx context.Context, userId int32)
+	// TerminateClusterSessions closes the sessions of a cluster which is being removed, their status is expected to be
+	// terminated in db already. Pods are deleted when deletePods is set, the outcome is returned by terminal access id
+	TerminateClusterSessions(ctx context.Context, clusterId int, deletePods bool) map[int]error
 	FetchPodManifest(ctx context.Context, userTerminalAccessId int) (resp *application.ManifestResponse, err error)
 	FetchPodEvents(ctx context.Context, userTerminalAccessId int) (*application.EventsResponse, error)
+	PrePullTerminalImages(ctx context.Context, request *models.UserTerminalImagePrePullRequest) error
+	// GetNodeDetail is used by the node picker of the terminal to show the load of a node before a session is started
+	GetNodeDetail(ctx context.Context, clusterId int, nodeName string) (*util.NodeDetail, error)
+	GetSessionTranscript(ctx context.Context, sessionId string) (*models.UserTerminalSessionTranscript, error)
+	ListTerminalSessions(ctx context.Context, userId int32, request *pagination.ListingRequest) (*pagination.ListingResponse, error)
+	// GetTerminalUsageReport returns the usage per user and cluster for the month starting at month
+	GetTerminalUsageReport(ctx context.Context, month time.Time) (*models.UserTerminalUsageReport, error)
+	Drain(ctx context.Context, countdown time.Duration)
 }
 
 type UserTerminalAccessServiceImpl struct {
@@ -46,6 +67,13 @@ type UserTerminalAccessServiceImpl struct {
 	k8sApplicationService        k8s.K8sApplicationService
 	k8sClientService             application.K8sClientService
 	terminalSessionHandler       terminal.TerminalSessionHandler
+	podNameRenderer              *TerminalPodNameRenderer
+	k8sUtil                      *util.K8sUtil
+	clusterRepository            clusterRepository.ClusterRepository
+	// preflightCache holds the permission preflight results by user, cluster and namespace
+	preflightCache *cache.Cache
+	// draining is set on shutdown, no new sessions are started once set
+	draining int32
 }
 
 type UserTerminalAccessSessionData struct {
@@ -53,6 +81,7 @@ type UserTerminalAccessSessionData struct {
 	latestActivityTime       time.Time
 	terminalAccessDataEntity *models.UserTerminalAccessData
 	terminateTriggered       bool
+	statusReason             string
 }
 
 func GetTerminalAccessConfig() (*models.UserTerminalSessionConfig, error) {
@@ -65,7 +94,13 @@ func GetTerminalAccessConfig() (*models.UserTerminalSessionConfig, error) {
 }
 
 func NewUserTerminalAccessServiceImpl(logger *zap.SugaredLogger, terminalAccessRepository repository.TerminalAccessRepository, config *models.UserTerminalSessionConfig,
-	k8sApplicationService k8s.K8sApplicationService, k8sClientService application.K8sClientService, terminalSessionHandler terminal.TerminalSessionHandler) (*UserTerminalAccessServiceImpl, error) {
+	k8sApplicationService k8s.K8sApplicationService, k8sClientService application.K8sClientService, terminalSessionHandler terminal.TerminalSessionHandler, k8sUtil *util.K8sUtil,
+	clusterRepository clusterRepository.ClusterRepository) (*UserTerminalAccessServiceImpl, error) {
+	podNameRenderer, err := NewTerminalPodNameRenderer(config.TerminalPodNameTemplate)
+	if err != nil {
+		logger.Errorw("invalid terminal pod name template", "template", config.TerminalPodNameTemplate, "err", err)
+		return nil, err
+	}
 	//fetches all running and starting entities from db and start SyncStatus
 	podStatusSyncCron := cron.New(cron.WithChain())
 	terminalAccessDataArrayMutex := &sync.RWMutex{}
@@ -80,9 +115,13 @@ func NewUserTerminalAccessServiceImpl(logger *zap.SugaredLogger, terminalAccessR
 		k8sClientService:             k8sClientService,
 		TerminalAccessSessionDataMap: &map1,
 		terminalSessionHandler:       terminalSessionHandler,
+		podNameRenderer:              podNameRenderer,
+		k8sUtil:                      k8sUtil,
+		clusterRepository:            clusterRepository,
+		preflightCache:               cache.New(time.Duration(config.TerminalPreflightCacheTTLInSecs)*time.Second, time.Minute),
 	}
 	podStatusSyncCron.Start()
-	_, err := podStatusSyncCron.AddFunc(fmt.Sprintf("@every %ds", config.TerminalPodStatusSyncTimeInSecs), accessServiceImpl.SyncPodStatus)
+	_, err = podStatusSyncCron.AddFunc(fmt.Sprintf("@every %ds", config.TerminalPodStatusSyncTimeInSecs), accessServiceImpl.SyncPodStatus)
 	if err != nil {
 		logger.Errorw("error occurred while starting cron job", "time in secs", config.TerminalPodStatusSyncTimeInSecs)
 		return nil, err
@@ -92,48 +131,206 @@ func NewUserTerminalAccessServiceImpl(logger *zap.SugaredLogger, terminalAccessR
 }
 
 func (impl *UserTerminalAccessServiceImpl) StartTerminalSession(ctx context.Context, request *models.UserTerminalSessionRequest) (*models.UserTerminalSessionResponse, error) {
-	impl.Logger.Infow("terminal start request received for user", "request", request)
+	logger := util.LoggerFromContext(ctx, impl.Logger)
+	logger.Infow("terminal start request received for user", "request", request)
 	userId := request.UserId
+	if impl.isDraining() {
+		return nil, util.ErrServerShuttingDown
+	}
+	if request.RecordTranscript && !impl.Config.TerminalTranscriptEnabled {
+		return nil, errors.New(models.TranscriptRecordingDisabledMsg)
+	}
 	// check for max session check
 	err := impl.checkMaxSessionLimit(userId)
 	if err != nil {
 		return nil, err
 	}
-	maxIdForUser := impl.getMaxIdForUser(userId)
-	podNameVar := impl.createPodName(request, maxIdForUser)
+	err = impl.checkMonthlyBudget(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	defaultsApplied, err := impl.resolveSessionDefaults(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	err = impl.preflightTerminalSession(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	podNameVar, err := impl.startTerminalPodWithUniqueName(ctx, request)
+	if err != nil {
+		return nil, err
+	}
 	terminalEntity, err := impl.createTerminalEntity(request, podNameVar)
 	if err != nil {
+		impl.deleteClusterTerminalTemplates(ctx, request.ClusterId, podNameVar, request.Namespace)
+		_ = impl.DeleteTerminalPod(ctx, request.ClusterId, podNameVar, request.Namespace)
 		return nil, err
 	}
-	err = impl.startTerminalPod(ctx, podNameVar, request)
-	return terminalEntity, err
+	terminalEntity.DefaultsApplied = defaultsApplied
+	statusSessionId, err := impl.terminalSessionHandler.GetStatusSession()
+	if err != nil {
+		logger.Errorw("error occurred while creating terminal status session, client has to poll the status", "terminalAccessId", terminalEntity.TerminalAccessId, "err", err)
+	} else {
+		terminalEntity.StatusSessionId = statusSessionId
+		go impl.pushStartupStatus(terminalEntity.TerminalAccessId, statusSessionId)
+	}
+	return terminalEntity, nil
+}
+
+// pushStartupStatus sends the status of a starting terminal over its status session every interval until the pod
+// is running or has failed, the final status is resent until a client bound to the session receives it
+func (impl *UserTerminalAccessServiceImpl) pushStartupStatus(terminalAccessId int, statusSessionId string) {
+	defer impl.terminalSessionHandler.Close(statusSessionId, 1, models.TerminalStatusSessionCloseMsg)
+	deadline := time.Now().Add(time.Duration(impl.Config.TerminalStatusPushTimeoutInSecs) * time.Second)
+	ticker := time.NewTicker(time.Duration(impl.Config.TerminalStatusPushIntervalInSecs) * time.Second)
+	defer ticker.Stop()
+	var data []byte
+	finalStatus := false
+	for now := range ticker.C {
+		if now.After(deadline) {
+			return
+		}
+		// once final the status is not fetched again, fetching a running terminal starts an exec session
+		if !finalStatus {
+			status, err := impl.FetchTerminalStatus(context.Background(), terminalAccessId)
+			if err != nil {
+				impl.Logger.Errorw("error occurred while fetching terminal status to push", "terminalAccessId", terminalAccessId, "err", err)
+				return
+			}
+			data, err = json.Marshal(status)
+			if err != nil {
+				impl.Logger.Errorw("error occurred while marshaling terminal status", "terminalAccessId", terminalAccessId, "err", err)
+				return
+			}
+			finalStatus = isStartupStatusFinal(status)
+		}
+		sent, err := impl.terminalSessionHandler.SendStatus(statusSessionId, string(data))
+		if err != nil {
+			impl.Logger.Errorw("error occurred while pushing terminal status", "terminalAccessId", terminalAccessId, "statusSessionId", statusSessionId, "err", err)
+			return
+		}
+		if sent && finalStatus {
+			return
+		}
+	}
+}
+
+func isStartupStatusFinal(status *models.UserTerminalSessionResponse) bool {
+	return status.Status != models.TerminalPodStarting || len(status.UserTerminalSessionId) > 0
+}
+
+// startTerminalPodWithUniqueName renders a fresh pod name for every attempt, an existing pod with the same name
+// is treated as a collision and retried instead of being reused
+func (impl *UserTerminalAccessServiceImpl) startTerminalPodWithUniqueName(ctx context.Context, request *models.UserTerminalSessionRequest) (string, error) {
+	logger := util.LoggerFromContext(ctx, impl.Logger)
+	var err error
+	for attempt := 1; attempt <= TerminalPodNameMaxRenderAttempts; attempt++ {
+		var podNameVar string
+		podNameVar, err = impl.podNameRenderer.Render(request.ClusterId, request.UserId)
+		if err != nil {
+			logger.Errorw("error occurred while rendering terminal pod name", "request", request, "err", err)
+			return "", err
+		}
+		err = impl.startTerminalPod(ctx, podNameVar, request)
+		if err == nil {
+			return podNameVar, nil
+		}
+		if !k8sErrors.IsAlreadyExists(err) {
+			return "", err
+		}
+		logger.Warnw("terminal pod name collision, retrying with new random id", "podName", podNameVar, "attempt", attempt)
+	}
+	return "", fmt.Errorf("unable to find a unique terminal pod name after %d attempts: %w", TerminalPodNameMaxRenderAttempts, err)
+}
+
+// tunables are the session limits which can be changed without a restart through the tunables ConfigMap of K8sUtil,
+// the env config is used when there is no K8sUtil
+func (impl *UserTerminalAccessServiceImpl) tunables() util.TunableConfig {
+	if impl.k8sUtil == nil {
+		return util.TunableConfig{
+			MaxSessionPerUser:                 impl.Config.MaxSessionPerUser,
+			TerminalPodInActiveDurationInMins: impl.Config.TerminalPodInActiveDurationInMins,
+			TerminalBaseImageAllowList:        impl.Config.TerminalBaseImageAllowList,
+		}
+	}
+	return impl.k8sUtil.Tunables()
 }
 
 func (impl *UserTerminalAccessServiceImpl) checkMaxSessionLimit(userId int32) error {
-	maxSessionPerUser := impl.Config.MaxSessionPerUser
+	maxSessionPerUser := impl.tunables().MaxSessionPerUser
 	activeSessionList := impl.getUserActiveSessionList(userId)
 	userRunningSessionCount := len(activeSessionList)
 	if userRunningSessionCount >= maxSessionPerUser {
 		errStr := fmt.Sprintf("cannot start new session more than configured %s", strconv.Itoa(maxSessionPerUser))
 		impl.Logger.Errorw(errStr, "userId", userId)
-		return errors.New(models.MaxSessionLimitReachedMsg)
+		return util.ErrSessionLimitExceeded
 	}
 	return nil
 }
 
-func (impl *UserTerminalAccessServiceImpl) getMaxIdForUser(userId int32) int {
-	accessSessionDataMap := impl.TerminalAccessSessionDataMap
-	maxId := 0
-	for _, userTerminalAccessSessionData := range *accessSessionDataMap {
-		terminalAccessDataEntity := userTerminalAccessSessionData.terminalAccessDataEntity
-		if terminalAccessDataEntity.UserId == userId {
-			accessId := terminalAccessDataEntity.Id
-			if accessId > maxId {
-				maxId = accessId
-			}
+// resolveSessionDefaults fills base image and shell left empty in the request from the cluster and then the global
+// defaults, the resolved image is checked against the allow list whether it was defaulted or not
+func (impl *UserTerminalAccessServiceImpl) resolveSessionDefaults(ctx context.Context, request *models.UserTerminalSessionRequest) (*models.TerminalSessionDefaultsApplied, error) {
+	request.BaseImage = strings.TrimSpace(request.BaseImage)
+	var defaultsApplied *models.TerminalSessionDefaultsApplied
+	if len(request.BaseImage) == 0 || len(request.ShellName) == 0 {
+		cluster, err := impl.clusterRepository.FindById(request.ClusterId)
+		if err != nil {
+			util.LoggerFromContext(ctx, impl.Logger).Errorw("error occurred while fetching cluster for terminal defaults", "clusterId", request.ClusterId, "err", err)
+			return nil, err
 		}
+		defaultsApplied = &models.TerminalSessionDefaultsApplied{}
+		request.BaseImage, defaultsApplied.BaseImage = resolveSessionDefault(request.BaseImage, cluster.TerminalDefaultBaseImage, impl.Config.TerminalDefaultBaseImage)
+		request.ShellName, defaultsApplied.ShellName = resolveSessionDefault(request.ShellName, cluster.TerminalDefaultShell, impl.Config.TerminalDefaultShellName)
+	}
+	if len(request.BaseImage) == 0 || len(request.ShellName) == 0 {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest),
+			UserMessage: "base image and shell are required as no defaults are configured", InternalMessage: "terminal defaults not configured"}
 	}
-	return maxId
+	if !isValidTerminalShell(request.ShellName) {
+		message := fmt.Sprintf("shell %s is not supported", request.ShellName)
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: message, InternalMessage: message}
+	}
+	allowedImages := parseImageAllowList(impl.tunables().TerminalBaseImageAllowList)
+	if len(allowedImages) > 0 && !allowedImages[request.BaseImage] {
+		message := fmt.Sprintf("image %s is not allowed for terminal sessions", request.BaseImage)
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: message, InternalMessage: message}
+	}
+	return defaultsApplied, nil
+}
+
+// checkMonthlyBudget blocks new sessions once the user has used up the cpu or memory budget of the current month
+func (impl *UserTerminalAccessServiceImpl) checkMonthlyBudget(ctx context.Context, userId int32) error {
+	cpuBudget := impl.Config.TerminalUserMonthlyCpuCoreHours
+	memoryBudget := impl.Config.TerminalUserMonthlyMemoryGibHours
+	if cpuBudget <= 0 && memoryBudget <= 0 {
+		return nil
+	}
+	logger := util.LoggerFromContext(ctx, impl.Logger)
+	now := time.Now()
+	monthStart, _ := ParseUsageMonth("", now)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	accessDataArray, err := impl.TerminalAccessRepository.FindUserTerminalAccessDataRunningBetween(userId, monthStart, monthEnd)
+	if err != nil {
+		logger.Errorw("error occurred while fetching terminal usage of user", "userId", userId, "err", err)
+		return err
+	}
+	var cpuCoreHours, memoryGibHours float64
+	for _, usage := range aggregateTerminalUsage(accessDataArray, monthStart, monthEnd, now) {
+		cpuCoreHours += usage.CpuCoreHours
+		memoryGibHours += usage.MemoryGibHours
+	}
+	month := monthStart.Format(models.TerminalUsageMonthLayout)
+	if cpuBudget > 0 && cpuCoreHours >= cpuBudget {
+		logger.Infow("terminal cpu budget exceeded", "userId", userId, "used", cpuCoreHours, "budget", cpuBudget)
+		return fmt.Errorf("used %.2f of %.2f cpu core hours in %s: %w", cpuCoreHours, cpuBudget, month, util.ErrTerminalBudgetExceeded)
+	}
+	if memoryBudget > 0 && memoryGibHours >= memoryBudget {
+		logger.Infow("terminal memory budget exceeded", "userId", userId, "used", memoryGibHours, "budget", memoryBudget)
+		return fmt.Errorf("used %.2f of %.2f memory GiB hours in %s: %w", memoryGibHours, memoryBudget, month, util.ErrTerminalBudgetExceeded)
+	}
+	return nil
 }
 
 func (impl *UserTerminalAccessServiceImpl) getUserActiveSessionList(userId int32) []*UserTerminalAccessSessionData {
@@ -157,7 +354,7 @@ func (impl *UserTerminalAccessServiceImpl) createTerminalEntity(request *models.
 		PodName:   podName,
 		Metadata:  impl.extractMetadataString(request),
 	}
-	err := impl.TerminalAccessRepository.SaveUserTerminalAccessData(userAccessData)
+	err := impl.TerminalAccessRepository.SaveUserTerminalAccessData(userAccessData, nil)
 	if err != nil {
 		impl.Logger.Errorw("error occurred while saving user terminal access data", "err", err)
 		return nil, err
@@ -184,7 +381,7 @@ func (impl *UserTerminalAccessServiceImpl) UpdateTerminalShellSession(ctx contex
 		return nil, err
 	}
 	terminalAccessData.Metadata = impl.mergeToMetadataString(terminalAccessData.Metadata, request)
-	err = impl.TerminalAccessRepository.UpdateUserTerminalAccessData(terminalAccessData)
+	err = impl.TerminalAccessRepository.UpdateUserTerminalAccessData(terminalAccessData, nil)
 	if err != nil {
 		impl.Logger.Errorw("error occurred while updating terminal Access data ", "userTerminalAccessId", userTerminalAccessId, "err", err)
 		return nil, err
@@ -238,6 +435,9 @@ func (impl *UserTerminalAccessServiceImpl) DisconnectTerminalSession(ctx context
 	} else {
 		accessSessionData.terminateTriggered = true
 	}
+	if accessSessionData.terminateTriggered {
+		impl.markSessionRunningEnded(terminalAccessData)
+	}
 	return err
 }
 
@@ -269,6 +469,43 @@ func (impl *UserTerminalAccessServiceImpl) DisconnectAllSessionsForUser(ctx cont
 	}
 }
 
+func (impl *UserTerminalAccessServiceImpl) TerminateClusterSessions(ctx context.Context, clusterId int, deletePods bool) map[int]error {
+	logger := util.LoggerFromContext(ctx, impl.Logger)
+	impl.TerminalAccessDataArrayMutex.Lock()
+	defer impl.TerminalAccessDataArrayMutex.Unlock()
+	outcomes := make(map[int]error)
+	accessSessionDataMap := *impl.TerminalAccessSessionDataMap
+	for terminalAccessId, accessSessionData := range accessSessionDataMap {
+		terminalAccessData := accessSessionData.terminalAccessDataEntity
+		if terminalAccessData.ClusterId != clusterId {
+			continue
+		}
+		impl.closeAndCleanTerminalSession(accessSessionData)
+		accessSessionData.terminateTriggered = true
+		terminalAccessData.Status = string(models.TerminalPodTerminated)
+		delete(accessSessionDataMap, terminalAccessId)
+		if !deletePods {
+			outcomes[terminalAccessId] = nil
+			continue
+		}
+		metadataMap, err := impl.getMetadataMap(terminalAccessData.Metadata)
+		if err != nil {
+			outcomes[terminalAccessId] = err
+			continue
+		}
+		namespace := metadataMap["Namespace"]
+		impl.deleteClusterTerminalTemplates(ctx, clusterId, terminalAccessData.PodName, namespace)
+		err = impl.DeleteTerminalPod(ctx, clusterId, terminalAccessData.PodName, namespace)
+		if err != nil && !isResourceNotFoundErr(err) {
+			logger.Errorw("error in deleting terminal pod of removed cluster", "terminalAccessId", terminalAccessId, "clusterId", clusterId, "err", err)
+			outcomes[terminalAccessId] = err
+			continue
+		}
+		outcomes[terminalAccessId] = nil
+	}
+	return outcomes
+}
+
 func (impl *UserTerminalAccessServiceImpl) closeAndCleanTerminalSession(accessSessionData *UserTerminalAccessSessionData) {
 	sessionId := accessSessionData.sessionId
 	if sessionId != "" {
@@ -289,6 +526,7 @@ func (impl *UserTerminalAccessServiceImpl) extractMetadataString(request *models
 	metadata["BaseImage"] = request.BaseImage
 	metadata["ShellName"] = request.ShellName
 	metadata["Namespace"] = request.Namespace
+	metadata["RecordTranscript"] = strconv.FormatBool(request.RecordTranscript)
 	metadataJsonBytes, err := json.Marshal(metadata)
 	if err != nil {
 		impl.Logger.Errorw("error occurred while converting metadata to json", "request", request, "err", err)
@@ -322,10 +560,10 @@ func (impl *UserTerminalAccessServiceImpl) getMetadataMap(metadata string) (map[
 }
 
 func (impl *UserTerminalAccessServiceImpl) startTerminalPod(ctx context.Context, podNameVar string, request *models.UserTerminalSessionRequest) error {
-
+	logger := util.LoggerFromContext(ctx, impl.Logger)
 	accessTemplates, err := impl.TerminalAccessRepository.FetchAllTemplates()
 	if err != nil {
-		impl.Logger.Errorw("error occurred while fetching terminal access templates", "err", err)
+		logger.Errorw("error occurred while fetching terminal access templates", "err", err)
 		return err
 	}
 	for _, accessTemplate := range accessTemplates {
@@ -337,16 +575,9 @@ func (impl *UserTerminalAccessServiceImpl) startTerminalPod(ctx context.Context,
 	return err
 }
 
-func (impl *UserTerminalAccessServiceImpl) createPodName(request *models.UserTerminalSessionRequest, runningCount int) string {
-	podNameVar := models.TerminalAccessPodNameTemplate
-	podNameVar = strings.ReplaceAll(podNameVar, models.TerminalAccessClusterIdTemplateVar, strconv.Itoa(request.ClusterId))
-	podNameVar = strings.ReplaceAll(podNameVar, models.TerminalAccessUserIdTemplateVar, strconv.FormatInt(int64(request.UserId), 10))
-	podNameVar = strings.ReplaceAll(podNameVar, models.TerminalAccessRandomIdVar, strconv.Itoa(runningCount+1))
-	return podNameVar
-}
-
 func (impl *UserTerminalAccessServiceImpl) applyTemplateData(ctx context.Context, request *models.UserTerminalSessionRequest, podNameVar string,
 	terminalTemplate *models.TerminalAccessTemplates, isUpdate bool) error {
+	logger := util.LoggerFromContext(ctx, impl.Logger)
 	templateName := terminalTemplate.TemplateName
 	templateData := terminalTemplate.TemplateData
 	clusterId := request.ClusterId
@@ -357,16 +588,47 @@ func (impl *UserTerminalAccessServiceImpl) applyTemplateData(ctx context.Context
 	templateData = strings.ReplaceAll(templateData, models.TerminalAccessBaseImageVar, request.BaseImage)
 	templateData = strings.ReplaceAll(templateData, models.TerminalAccessNamespaceVar, namespace)
 	templateData = strings.ReplaceAll(templateData, models.TerminalAccessPodNameVar, podNameVar)
-	err := impl.applyTemplate(ctx, clusterId, terminalTemplate.TemplateData, templateData, isUpdate, namespace)
+	// pod is the only resource which must not be reused from a previous session with the same name
+	failIfExists := templateName == models.TerminalAccessPodTemplateName
+	if failIfExists && !isUpdate {
+		var err error
+		templateData, err = util.ApplyManagementLabelsJson(ctx, templateData, terminalPodManagementLabels(request, podNameVar))
+		if err != nil {
+			logger.Errorw("error in labelling terminal pod", "err", err)
+			return err
+		}
+	}
+	if failIfExists && !isUpdate && impl.k8sUtil != nil {
+		var err error
+		// the node selected in the request wins over the placement policy of the namespace and cluster
+		templateData, err = impl.k8sUtil.ApplyPodPlacementJson(clusterId, namespace, templateData)
+		if err != nil {
+			logger.Errorw("error in applying placement policy on terminal pod manifest", "err", err)
+			return err
+		}
+		templateData, err = impl.k8sUtil.MutateManifestJson(templateData)
+		if err != nil {
+			logger.Errorw("error in mutating terminal pod manifest", "err", err)
+			return err
+		}
+	}
+	err := impl.applyTemplate(ctx, clusterId, terminalTemplate.TemplateData, templateData, isUpdate, failIfExists, namespace)
 	if err != nil {
-		impl.Logger.Errorw("error occurred while applying template ", "name", templateName, "err", err)
+		logger.Errorw("error occurred while applying template ", "name", templateName, "err", err)
 		return err
 	}
 	return nil
 }
 
+// terminalPodManagementLabels identify the pod of a terminal session, the pod name is the session id as a session is
+// bound to its pod
+func terminalPodManagementLabels(request *models.UserTerminalSessionRequest, podName string) util.ManagementLabels {
+	return util.ManagementLabels{Purpose: util.DevtronPurposeTerminal, UserId: request.UserId, SessionId: podName}
+}
+
 func (impl *UserTerminalAccessServiceImpl) SyncPodStatus() {
 	terminalAccessDataMap := *impl.TerminalAccessSessionDataMap
+	inActiveDurationInMins := impl.tunables().TerminalPodInActiveDurationInMins
 	for _, terminalAccessSessionData := range terminalAccessDataMap {
 		sessionId := terminalAccessSessionData.sessionId
 		if sessionId != "" {
@@ -379,7 +641,7 @@ func (impl *UserTerminalAccessServiceImpl) SyncPodStatus() {
 		}
 		//check remaining running which are active from last x minutes
 		timeGapInMinutes := time.Since(terminalAccessSessionData.latestActivityTime).Minutes()
-		if impl.Config.TerminalPodInActiveDurationInMins < int(timeGapInMinutes) {
+		if inActiveDurationInMins < int(timeGapInMinutes) {
 			terminalAccessData := terminalAccessSessionData.terminalAccessDataEntity
 			existingStatus := terminalAccessData.Status
 			terminalPodStatusString := existingStatus
@@ -399,6 +661,7 @@ func (impl *UserTerminalAccessServiceImpl) SyncPodStatus() {
 				}
 			}
 			terminalAccessSessionData.terminateTriggered = true
+			impl.markSessionRunningEnded(terminalAccessData)
 			if existingStatus != terminalPodStatusString {
 				terminalAccessId := terminalAccessData.Id
 				err = impl.TerminalAccessRepository.UpdateUserTerminalStatus(terminalAccessId, terminalPodStatusString)
@@ -414,7 +677,8 @@ func (impl *UserTerminalAccessServiceImpl) SyncPodStatus() {
 	defer impl.TerminalAccessDataArrayMutex.Unlock()
 	for _, terminalAccessSessionData := range terminalAccessDataMap {
 		terminalAccessData := terminalAccessSessionData.terminalAccessDataEntity
-		if terminalAccessData.Status != string(models.TerminalPodStarting) && terminalAccessData.Status != string(models.TerminalPodRunning) {
+		if terminalAccessData.Status != string(models.TerminalPodStarting) && terminalAccessData.Status != string(models.TerminalPodRunning) &&
+			terminalAccessData.Status != string(models.TerminalPodDisconnected) {
 			// check if this is the last data for this cluster and user then delete terminal resource
 			delete(terminalAccessDataMap, terminalAccessData.Id)
 		}
@@ -422,28 +686,43 @@ func (impl *UserTerminalAccessServiceImpl) SyncPodStatus() {
 	impl.TerminalAccessSessionDataMap = &terminalAccessDataMap
 }
 
-func (impl *UserTerminalAccessServiceImpl) checkAndStartSession(ctx context.Context, terminalAccessData *models.UserTerminalAccessData) (string, error) {
+func (impl *UserTerminalAccessServiceImpl) checkAndStartSession(ctx context.Context, terminalAccessData *models.UserTerminalAccessData) (string, string, error) {
 	clusterId := terminalAccessData.ClusterId
 	terminalAccessPodName := terminalAccessData.PodName
 	metadata := terminalAccessData.Metadata
 	metadataMap, err := impl.getMetadataMap(metadata)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	namespace := metadataMap["Namespace"]
-	terminalPodStatusString, err := impl.getPodStatus(ctx, clusterId, terminalAccessPodName, namespace)
+	terminalPodStatusString, statusReason, pod, err := impl.getPodStatus(ctx, clusterId, terminalAccessPodName, namespace)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	sessionID := ""
 	terminalAccessId := terminalAccessData.Id
+	if terminalPodStatusString == string(models.TerminalPodError) {
+		// image pull failures will not recover on their own, mark the session as errored instead of waiting for a timeout
+		if terminalAccessData.Status != terminalPodStatusString {
+			err = impl.TerminalAccessRepository.UpdateUserTerminalStatus(terminalAccessId, terminalPodStatusString)
+			if err != nil {
+				impl.Logger.Errorw("error occurred while updating terminal status", "terminalAccessId", terminalAccessId, "err", err)
+				return "", "", err
+			}
+			terminalAccessData.Status = terminalPodStatusString
+		}
+		return sessionID, statusReason, nil
+	}
 	if terminalPodStatusString == string(models.TerminalPodRunning) {
 		err = impl.TerminalAccessRepository.UpdateUserTerminalStatus(terminalAccessId, terminalPodStatusString)
 		if err != nil {
 			impl.Logger.Errorw("error occurred while updating terminal status", "terminalAccessId", terminalAccessId, "err", err)
-			return "", err
+			return "", "", err
 		}
 		terminalAccessData.Status = terminalPodStatusString
+		if terminalAccessData.RunningStartedOn == nil && pod != nil {
+			impl.markSessionRunning(terminalAccessData, pod)
+		}
 		//create terminal session if status is Running and store sessionId
 		request := &terminal.TerminalSessionRequest{
 			Shell:     metadataMap["ShellName"],
@@ -451,14 +730,49 @@ func (impl *UserTerminalAccessServiceImpl) checkAndStartSession(ctx context.Cont
 			PodName:   terminalAccessPodName,
 			ClusterId: clusterId,
 		}
+		if metadataMap["RecordTranscript"] == "true" && impl.Config.TerminalTranscriptEnabled {
+			request.RecordTranscript = true
+			request.TranscriptMaxSize = impl.Config.TerminalTranscriptMaxSizeInKB * 1024
+			request.OnTranscriptComplete = func(sessionId string, transcript []byte, truncated bool) {
+				impl.saveSessionTranscript(terminalAccessData, sessionId, transcript, truncated)
+			}
+		}
 		_, terminalMessage, err := impl.terminalSessionHandler.GetTerminalSession(request)
 		if err != nil {
 			impl.Logger.Errorw("error occurred while creating terminal session", "terminalAccessId", terminalAccessId, "err", err)
-			return "", err
+			return "", "", err
 		}
 		sessionID = terminalMessage.SessionID
 	}
-	return sessionID, err
+	return sessionID, statusReason, err
+}
+
+// markSessionRunning records the running start and requests of the pod for usage accounting, failures are only logged
+// as they should not block the session
+func (impl *UserTerminalAccessServiceImpl) markSessionRunning(terminalAccessData *models.UserTerminalAccessData, pod *v1.Pod) {
+	startedOn := podRunningSince(pod, time.Now())
+	cpuMillis, memoryBytes := podRequestedResources(pod)
+	err := impl.TerminalAccessRepository.MarkUserTerminalRunning(terminalAccessData.Id, startedOn, cpuMillis, memoryBytes)
+	if err != nil {
+		impl.Logger.Errorw("error occurred while recording terminal running start", "terminalAccessId", terminalAccessData.Id, "err", err)
+		return
+	}
+	terminalAccessData.RunningStartedOn = &startedOn
+	terminalAccessData.RequestedCpuMillis = cpuMillis
+	terminalAccessData.RequestedMemoryBytes = memoryBytes
+}
+
+func (impl *UserTerminalAccessServiceImpl) markSessionRunningEnded(terminalAccessData *models.UserTerminalAccessData) {
+	if terminalAccessData.RunningStartedOn == nil || terminalAccessData.RunningEndedOn != nil {
+		return
+	}
+	endedOn := time.Now()
+	err := impl.TerminalAccessRepository.MarkUserTerminalRunningEnded(terminalAccessData.Id, endedOn)
+	if err != nil {
+		impl.Logger.Errorw("error occurred while recording terminal running end", "terminalAccessId", terminalAccessData.Id, "err", err)
+		return
+	}
+	terminalAccessData.RunningEndedOn = &endedOn
 }
 
 func (impl *UserTerminalAccessServiceImpl) FetchTerminalStatus(ctx context.Context, terminalAccessId int) (*models.UserTerminalSessionResponse, error) {
@@ -496,6 +810,15 @@ func (impl *UserTerminalAccessServiceImpl) FetchTerminalStatus(ctx context.Conte
 		PodName:               terminalAccessData.PodName,
 		UserTerminalSessionId: terminalSessionId,
 	}
+	impl.TerminalAccessDataArrayMutex.RLock()
+	if sessionData, ok := terminalAccessDataMap[terminalAccessId]; ok {
+		if terminalAccessResponse.Status == models.TerminalPodError {
+			terminalAccessResponse.ErrorReason = sessionData.statusReason
+		} else if terminalAccessResponse.Status != models.TerminalPodRunning {
+			terminalAccessResponse.StatusReason = sessionData.statusReason
+		}
+	}
+	impl.TerminalAccessDataArrayMutex.RUnlock()
 	return terminalAccessResponse, nil
 }
 
@@ -510,11 +833,14 @@ func (impl *UserTerminalAccessServiceImpl) validateTerminalAccessFromDb(ctx cont
 		if existingTerminalAccessData.Status == string(models.TerminalPodTerminated) {
 			return nil, errors.New("pod-terminated")
 		}
+		if impl.isDraining() {
+			return nil, util.ErrServerShuttingDown
+		}
 		err = impl.checkMaxSessionLimit(existingTerminalAccessData.UserId)
 		if err != nil {
 			return nil, err
 		}
-		terminalSessionId, err = impl.checkAndStartSession(ctx, existingTerminalAccessData)
+		terminalSessionId, statusReason, err := impl.checkAndStartSession(ctx, existingTerminalAccessData)
 		if err != nil {
 			return nil, err
 		}
@@ -523,6 +849,7 @@ func (impl *UserTerminalAccessServiceImpl) validateTerminalAccessFromDb(ctx cont
 		}
 		impl.TerminalAccessDataArrayMutex.Lock()
 		terminalAccessSessionData.sessionId = terminalSessionId
+		terminalAccessSessionData.statusReason = statusReason
 		terminalAccessSessionData.terminalAccessDataEntity = existingTerminalAccessData
 		terminalAccessDataMap[terminalAccessId] = terminalAccessSessionData
 		impl.TerminalAccessDataArrayMutex.Unlock()
@@ -572,7 +899,13 @@ func (impl *UserTerminalAccessServiceImpl) DeleteTerminalResource(ctx context.Co
 	return err
 }
 
-func (impl *UserTerminalAccessServiceImpl) applyTemplate(ctx context.Context, clusterId int, gvkDataString string, templateData string, isUpdate bool, namespace string) error {
+func (impl *UserTerminalAccessServiceImpl) applyTemplate(ctx context.Context, clusterId int, gvkDataString string, templateData string, isUpdate bool, failIfExists bool, namespace string) error {
+	logger := util.LoggerFromContext(ctx, impl.Logger)
+	if impl.k8sUtil != nil {
+		if err := impl.k8sUtil.CheckNamespaceAccess(ctx, &util.ClusterConfig{ClusterId: clusterId}, namespace, true); err != nil {
+			return err
+		}
+	}
 	restConfig, err := impl.k8sApplicationService.GetRestConfigByClusterId(ctx, clusterId)
 	if err != nil {
 		return err
@@ -580,7 +913,7 @@ func (impl *UserTerminalAccessServiceImpl) applyTemplate(ctx context.Context, cl
 
 	_, groupVersionKind, err := legacyscheme.Codecs.UniversalDeserializer().Decode([]byte(gvkDataString), nil, nil)
 	if err != nil {
-		impl.Logger.Errorw("error occurred while extracting data for gvk", "gvkDataString", gvkDataString, "err", err)
+		logger.Errorw("error occurred while extracting data for gvk", "gvkDataString", gvkDataString, "err", err)
 		return err
 	}
 
@@ -602,35 +935,95 @@ func (impl *UserTerminalAccessServiceImpl) applyTemplate(ctx context.Context, cl
 		_, err = impl.k8sClientService.CreateResource(ctx, restConfig, k8sRequest, templateData)
 	}
 	if err != nil {
-		if errStatus, ok := err.(*k8sErrors.StatusError); !(ok && errStatus.Status().Reason == metav1.StatusReasonAlreadyExists) {
-			impl.Logger.Errorw("error in creating resource", "err", err, "request", k8sRequest)
+		if errStatus, ok := err.(*k8sErrors.StatusError); failIfExists || !(ok && errStatus.Status().Reason == metav1.StatusReasonAlreadyExists) {
+			logger.Errorw("error in creating resource", "err", err, "request", k8sRequest)
 			return err
 		}
 	}
 	return nil
 }
 
-func (impl *UserTerminalAccessServiceImpl) getPodStatus(ctx context.Context, clusterId int, podName string, namespace string) (string, error) {
+// getPodStatus returns the status and status reason of the terminal pod along with the pod, pod is nil once terminated
+func (impl *UserTerminalAccessServiceImpl) getPodStatus(ctx context.Context, clusterId int, podName string, namespace string) (string, string, *v1.Pod, error) {
 	response, err := impl.getPodManifest(ctx, clusterId, podName, namespace)
 	if err != nil {
 		if err.Error() == string(models.TerminalPodTerminated) {
-			return string(models.TerminalPodTerminated), nil
+			return string(models.TerminalPodTerminated), "", nil, nil
 		} else {
-			return "", err
+			return "", "", nil, err
 		}
 	}
 	status := ""
+	statusReason := ""
+	var pod *v1.Pod
 	if response != nil {
-		manifest := response.Manifest
-		for key, value := range manifest.Object {
-			if key == "status" {
-				statusData := value.(map[string]interface{})
-				status = statusData["phase"].(string)
+		pod = &v1.Pod{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(response.Manifest.Object, pod)
+		if err != nil {
+			impl.Logger.Errorw("error occurred while converting pod manifest", "podName", podName, "err", err)
+			return "", "", nil, err
+		}
+		status = string(pod.Status.Phase)
+		if pod.Status.Phase == v1.PodPending {
+			failed, waitingReason := getContainerWaitingReason(pod)
+			if failed {
+				status = string(models.TerminalPodError)
+			}
+			statusReason = waitingReason
+			if len(statusReason) == 0 {
+				statusReason = impl.getPodStartupEventReason(ctx, clusterId, podName, namespace)
 			}
 		}
 	}
-	impl.Logger.Debug("pod status", "podName", podName, "status", status)
-	return status, nil
+	impl.Logger.Debugw("pod status", "podName", podName, "status", status, "statusReason", statusReason)
+	return status, statusReason, pod, nil
+}
+
+// getContainerWaitingReason returns the waiting reason of the first container which is not just being created,
+// failed is true when the reason is one the terminal pod will not recover from, like an image pull failure
+func getContainerWaitingReason(pod *v1.Pod) (failed bool, reason string) {
+	containerStatuses := append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...)
+	for _, containerStatus := range containerStatuses {
+		waiting := containerStatus.State.Waiting
+		if waiting == nil || waiting.Reason == models.ContainerCreatingReason || waiting.Reason == models.PodInitializingReason {
+			continue
+		}
+		reason = waiting.Reason
+		if len(waiting.Message) > 0 {
+			reason = fmt.Sprintf("%s: %s", waiting.Reason, waiting.Message)
+		}
+		switch waiting.Reason {
+		case models.ErrImagePullReason, models.ImagePullBackOffReason, models.InvalidImageNameReason, models.ErrImageNeverPullReason:
+			return true, reason
+		}
+		return false, reason
+	}
+	return false, ""
+}
+
+// getPodStartupEventReason returns the message of latest scheduling/image pull event of the pod, empty if not found
+func (impl *UserTerminalAccessServiceImpl) getPodStartupEventReason(ctx context.Context, clusterId int, podName string, namespace string) string {
+	podRequestBean, err := impl.getPodRequestBean(clusterId, podName, namespace)
+	if err != nil {
+		return ""
+	}
+	eventsResponse, err := impl.k8sApplicationService.ListEvents(ctx, podRequestBean)
+	if err != nil || eventsResponse == nil || eventsResponse.Events == nil {
+		impl.Logger.Debugw("unable to fetch terminal pod events", "podName", podName, "err", err)
+		return ""
+	}
+	events := eventsResponse.Events.Items
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastTimestamp.Before(&events[j].LastTimestamp)
+	})
+	reason := ""
+	for _, event := range events {
+		switch event.Reason {
+		case models.PullingEventReason, models.PulledEventReason, models.ScheduledEventReason, models.FailedSchedulingEventReason:
+			reason = fmt.Sprintf("%s: %s", event.Reason, event.Message)
+		}
+	}
+	return reason
 }
 
 func (impl *UserTerminalAccessServiceImpl) getPodManifest(ctx context.Context, clusterId int, podName string, namespace string) (*application.ManifestResponse, error) {
@@ -771,3 +1164,209 @@ func (impl *UserTerminalAccessServiceImpl) getTerminalAccessDataForId(userTermin
 	}
 	return terminalAccessData, err
 }
+
+func (impl *UserTerminalAccessServiceImpl) PrePullTerminalImages(ctx context.Context, request *models.UserTerminalImagePrePullRequest) error {
+	allowedImages := parseImageAllowList(impl.Config.TerminalImagePrePullAllowList)
+	for _, image := range request.Images {
+		if !allowedImages[image] {
+			return fmt.Errorf("image %s is not allowed for pre-pull", image)
+		}
+	}
+	restConfig, err := impl.k8sApplicationService.GetRestConfigByClusterId(ctx, request.ClusterId)
+	if err != nil {
+		impl.Logger.Errorw("error occurred while fetching rest config", "clusterId", request.ClusterId, "err", err)
+		return err
+	}
+	manifest, err := impl.buildImagePrePullDaemonSet(request.Images)
+	if err != nil {
+		impl.Logger.Errorw("error occurred while building pre-pull daemonset", "images", request.Images, "err", err)
+		return err
+	}
+	k8sRequest := &application.K8sRequestBean{
+		ResourceIdentifier: application.ResourceIdentifier{
+			Name:      models.TerminalImagePrePullDaemonSetName,
+			Namespace: impl.Config.TerminalImagePrePullNamespace,
+			GroupVersionKind: schema.GroupVersionKind{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "DaemonSet",
+			},
+		},
+	}
+	_, err = impl.k8sClientService.CreateResource(ctx, restConfig, k8sRequest, manifest)
+	if err != nil {
+		if errStatus, ok := err.(*k8sErrors.StatusError); ok && errStatus.Status().Reason == metav1.StatusReasonAlreadyExists {
+			_, err = impl.k8sClientService.ApplyResource(ctx, restConfig, k8sRequest, manifest)
+		}
+		if err != nil {
+			impl.Logger.Errorw("error occurred while applying pre-pull daemonset", "clusterId", request.ClusterId, "err", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (impl *UserTerminalAccessServiceImpl) GetNodeDetail(ctx context.Context, clusterId int, nodeName string) (*util.NodeDetail, error) {
+	nodeDetail, err := impl.k8sApplicationService.GetNodeDetail(ctx, clusterId, nodeName)
+	if err != nil {
+		impl.Logger.Errorw("error occurred while fetching node detail", "clusterId", clusterId, "nodeName", nodeName, "err", err)
+		return nil, err
+	}
+	return nodeDetail, nil
+}
+
+// buildImagePrePullDaemonSet runs every image as an init container on each node so that the kubelet pulls it,
+// a pause container keeps the pod alive afterwards so that images are not garbage collected as unused.
+// Images need not ship a shell, the first init container copies a static no-op binary which the others run
+func (impl *UserTerminalAccessServiceImpl) buildImagePrePullDaemonSet(images []string) (string, error) {
+	labels := map[string]string{"app": models.TerminalImagePrePullDaemonSetName}
+	noopMounts := []map[string]interface{}{{"name": models.TerminalImagePrePullNoopVolume, "mountPath": path.Dir(models.TerminalImagePrePullNoopBinary)}}
+	initContainers := []map[string]interface{}{{
+		"name":         "noop-binary",
+		"image":        impl.Config.TerminalImagePrePullNoopImage,
+		"command":      []string{"cp", "/bin/true", models.TerminalImagePrePullNoopBinary},
+		"volumeMounts": noopMounts,
+	}}
+	for index, image := range images {
+		initContainers = append(initContainers, map[string]interface{}{
+			"name":         fmt.Sprintf("pre-pull-%d", index),
+			"image":        image,
+			"command":      []string{models.TerminalImagePrePullNoopBinary},
+			"volumeMounts": noopMounts,
+		})
+	}
+	daemonSet := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "DaemonSet",
+		"metadata": map[string]interface{}{
+			"name":      models.TerminalImagePrePullDaemonSetName,
+			"namespace": impl.Config.TerminalImagePrePullNamespace,
+			"labels":    labels,
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{"matchLabels": labels},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": labels},
+				"spec": map[string]interface{}{
+					"initContainers": initContainers,
+					"containers": []map[string]interface{}{
+						{"name": "pause", "image": impl.Config.TerminalImagePrePullPauseImage},
+					},
+					"tolerations": []map[string]interface{}{{"operator": "Exists"}},
+					"volumes": []map[string]interface{}{
+						{"name": models.TerminalImagePrePullNoopVolume, "emptyDir": map[string]interface{}{}},
+					},
+				},
+			},
+		},
+	}
+	manifest, err := json.Marshal(daemonSet)
+	if err != nil {
+		return "", err
+	}
+	return string(manifest), nil
+}
+
+func (impl *UserTerminalAccessServiceImpl) saveSessionTranscript(terminalAccessData *models.UserTerminalAccessData, sessionId string, transcript []byte, truncated bool) {
+	sessionTranscript := &models.UserTerminalSessionTranscript{
+		TerminalAccessId: terminalAccessData.Id,
+		SessionId:        sessionId,
+		UserId:           terminalAccessData.UserId,
+		Transcript:       string(transcript),
+		Truncated:        truncated,
+	}
+	err := impl.TerminalAccessRepository.SaveSessionTranscript(sessionTranscript, nil)
+	if err != nil {
+		impl.Logger.Errorw("error occurred while saving terminal session transcript", "terminalAccessId", terminalAccessData.Id, "sessionId", sessionId, "err", err)
+	}
+}
+
+func (impl *UserTerminalAccessServiceImpl) GetSessionTranscript(ctx context.Context, sessionId string) (*models.UserTerminalSessionTranscript, error) {
+	transcript, err := impl.TerminalAccessRepository.GetSessionTranscript(sessionId)
+	if err != nil {
+		impl.Logger.Errorw("error occurred while fetching terminal session transcript", "sessionId", sessionId, "err", err)
+		return nil, err
+	}
+	return transcript, nil
+}
+
+func (impl *UserTerminalAccessServiceImpl) ListTerminalSessions(ctx context.Context, userId int32, request *pagination.ListingRequest) (*pagination.ListingResponse, error) {
+	accessDataArray, totalCount, err := impl.TerminalAccessRepository.FindUserTerminalAccessData(userId, request)
+	if err != nil {
+		impl.Logger.Errorw("error occurred while fetching terminal sessions", "userId", userId, "request", request, "err", err)
+		return nil, err
+	}
+	sessions := make([]*models.UserTerminalSessionSummary, 0, len(accessDataArray))
+	for _, accessData := range accessDataArray {
+		sessions = append(sessions, &models.UserTerminalSessionSummary{
+			TerminalAccessId: accessData.Id,
+			ClusterId:        accessData.ClusterId,
+			NodeName:         accessData.NodeName,
+			PodName:          accessData.PodName,
+			Status:           accessData.Status,
+			CreatedOn:        accessData.CreatedOn,
+			UpdatedOn:        accessData.UpdatedOn,
+		})
+	}
+	return pagination.NewListingResponse(request, totalCount, sessions), nil
+}
+
+func (impl *UserTerminalAccessServiceImpl) GetTerminalUsageReport(ctx context.Context, month time.Time) (*models.UserTerminalUsageReport, error) {
+	logger := util.LoggerFromContext(ctx, impl.Logger)
+	monthEnd := month.AddDate(0, 1, 0)
+	accessDataArray, err := impl.TerminalAccessRepository.FindUserTerminalAccessDataRunningBetween(0, month, monthEnd)
+	if err != nil {
+		logger.Errorw("error occurred while fetching terminal usage", "month", month, "err", err)
+		return nil, err
+	}
+	return &models.UserTerminalUsageReport{
+		Month:                month.Format(models.TerminalUsageMonthLayout),
+		CpuCoreHoursBudget:   impl.Config.TerminalUserMonthlyCpuCoreHours,
+		MemoryGibHoursBudget: impl.Config.TerminalUserMonthlyMemoryGibHours,
+		Usage:                aggregateTerminalUsage(accessDataArray, month, monthEnd, time.Now()),
+	}, nil
+}
+
+// Drain stops new sessions and gives connected terminals a countdown before their sockets are closed, sessions which
+// were connected are marked Disconnected instead of Terminated so that the user can resume them on another instance
+func (impl *UserTerminalAccessServiceImpl) Drain(ctx context.Context, countdown time.Duration) {
+	atomic.StoreInt32(&impl.draining, 1)
+	impl.Logger.Infow("draining terminal sessions", "countdown", countdown)
+	impl.notifyShutdownCountdown(ctx, countdown)
+	impl.TerminalAccessDataArrayMutex.Lock()
+	defer impl.TerminalAccessDataArrayMutex.Unlock()
+	for _, accessSessionData := range *impl.TerminalAccessSessionDataMap {
+		if accessSessionData.sessionId == "" {
+			continue
+		}
+		terminalAccessData := accessSessionData.terminalAccessDataEntity
+		impl.terminalSessionHandler.Close(accessSessionData.sessionId, 1, models.TerminalShutdownCloseMsg)
+		accessSessionData.sessionId = ""
+		accessSessionData.latestActivityTime = time.Now()
+		err := impl.TerminalAccessRepository.UpdateUserTerminalStatus(terminalAccessData.Id, string(models.TerminalPodDisconnected))
+		if err != nil {
+			impl.Logger.Errorw("error occurred while marking terminal session disconnected", "terminalAccessId", terminalAccessData.Id, "err", err)
+			continue
+		}
+		terminalAccessData.Status = string(models.TerminalPodDisconnected)
+	}
+	// pod exec sessions are not backed by a terminal access entry, they are only closed
+	impl.terminalSessionHandler.CloseAll(1, models.TerminalShutdownCloseMsg)
+}
+
+func (impl *UserTerminalAccessServiceImpl) notifyShutdownCountdown(ctx context.Context, countdown time.Duration) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for remaining := int(countdown.Seconds()); remaining > 0; remaining-- {
+		impl.terminalSessionHandler.ToastAll(fmt.Sprintf(models.TerminalShutdownCountdownMsg, remaining))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (impl *UserTerminalAccessServiceImpl) isDraining() bool {
+	return atomic.LoadInt32(&impl.draining) == 1
+}
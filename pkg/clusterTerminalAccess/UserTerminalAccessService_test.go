@@ -1,6 +1,7 @@
 package clusterTerminalAccess
 
 import (
+	"encoding/json"
 	"errors"
 	"github.com/devtron-labs/devtron/client/k8s/application"
 	mocks4 "github.com/devtron-labs/devtron/client/k8s/application/mocks"
@@ -10,6 +11,7 @@ import (
 	"github.com/devtron-labs/devtron/pkg/terminal"
 	mocks2 "github.com/devtron-labs/devtron/pkg/terminal/mocks"
 	mocks3 "github.com/devtron-labs/devtron/util/k8s/mocks"
+	"github.com/go-pg/pg"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
@@ -25,8 +27,8 @@ func TestNewUserTerminalAccessService(t *testing.T) {
 		terminalAccessRepository, terminalSessionHandler, k8sApplicationService, terminalAccessServiceImpl := loadUserTerminalAccessService(tt)
 		terminalAccessDataId := 1
 		var savedTerminalAccessData *models.UserTerminalAccessData
-		terminalAccessRepository.On("SaveUserTerminalAccessData", mock.AnythingOfType("*models.UserTerminalAccessData")).
-			Return(func(data *models.UserTerminalAccessData) error {
+		terminalAccessRepository.On("SaveUserTerminalAccessData", mock.AnythingOfType("*models.UserTerminalAccessData"), mock.AnythingOfType("*pg.Tx")).
+			Return(func(data *models.UserTerminalAccessData, tx *pg.Tx) error {
 				data.Id = terminalAccessDataId
 				savedTerminalAccessData = data
 				return nil
@@ -102,8 +104,8 @@ func TestNewUserTerminalAccessService(t *testing.T) {
 		mockedUserId := int32(1)
 		mockedNodeName := "random1"
 		queryExecutionErr := errors.New("query execution failed")
-		terminalAccessRepository.On("SaveUserTerminalAccessData", mock.AnythingOfType("*models.UserTerminalAccessData")).
-			Return(func(data *models.UserTerminalAccessData) error {
+		terminalAccessRepository.On("SaveUserTerminalAccessData", mock.AnythingOfType("*models.UserTerminalAccessData"), mock.AnythingOfType("*pg.Tx")).
+			Return(func(data *models.UserTerminalAccessData, tx *pg.Tx) error {
 				assert.Equal(tt, mockedClusterId, data.ClusterId)
 				assert.Equal(tt, mockedUserId, data.UserId)
 				assert.Equal(tt, mockedNodeName, data.NodeName)
@@ -149,7 +151,54 @@ func loadUserTerminalAccessService(t *testing.T) (*mocks.TerminalAccessRepositor
 	k8sApplicationService := mocks3.NewK8sApplicationService(t)
 	k8sClientService := mocks4.NewK8sClientService(t)
 	terminalAccessRepository.On("GetAllRunningUserTerminalData").Return(nil, nil)
-	terminalAccessServiceImpl, err := NewUserTerminalAccessServiceImpl(logger, terminalAccessRepository, userTerminalSessionConfig, k8sApplicationService, k8sClientService, terminalSessionHandler)
+	terminalAccessServiceImpl, err := NewUserTerminalAccessServiceImpl(logger, terminalAccessRepository, userTerminalSessionConfig, k8sApplicationService, k8sClientService, terminalSessionHandler, nil, nil)
 	assert.Nil(t, err)
 	return terminalAccessRepository, terminalSessionHandler, k8sApplicationService, terminalAccessServiceImpl
 }
+
+func TestBuildImagePrePullDaemonSetNeedsNoShell(t *testing.T) {
+	impl := &UserTerminalAccessServiceImpl{Config: &models.UserTerminalSessionConfig{TerminalImagePrePullNoopImage: "busybox:1.36-musl"}}
+	manifest, err := impl.buildImagePrePullDaemonSet([]string{"distroless/static", "alpine:3.18"})
+	assert.Nil(t, err)
+	var daemonSet struct {
+		Spec struct {
+			Template struct {
+				Spec struct {
+					InitContainers []struct {
+						Image   string   `json:"image"`
+						Command []string `json:"command"`
+					} `json:"initContainers"`
+					Volumes []map[string]interface{} `json:"volumes"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(manifest), &daemonSet))
+	podSpec := daemonSet.Spec.Template.Spec
+	assert.Len(t, podSpec.InitContainers, 3)
+	assert.Equal(t, "busybox:1.36-musl", podSpec.InitContainers[0].Image)
+	assert.Equal(t, []string{"cp", "/bin/true", models.TerminalImagePrePullNoopBinary}, podSpec.InitContainers[0].Command)
+	for _, container := range podSpec.InitContainers[1:] {
+		assert.Equal(t, []string{models.TerminalImagePrePullNoopBinary}, container.Command)
+	}
+	assert.Equal(t, models.TerminalImagePrePullNoopVolume, podSpec.Volumes[0]["name"])
+}
+
+func TestIsStartupStatusFinal(t *testing.T) {
+	tests := []struct {
+		name   string
+		status *models.UserTerminalSessionResponse
+		final  bool
+	}{
+		{name: "pod starting", status: &models.UserTerminalSessionResponse{Status: models.TerminalPodStarting}, final: false},
+		{name: "session started", status: &models.UserTerminalSessionResponse{Status: models.TerminalPodStarting, UserTerminalSessionId: "abc"}, final: true},
+		{name: "pod running", status: &models.UserTerminalSessionResponse{Status: models.TerminalPodRunning, UserTerminalSessionId: "abc"}, final: true},
+		{name: "pod failed", status: &models.UserTerminalSessionResponse{Status: models.TerminalPodError}, final: true},
+		{name: "pod terminated", status: &models.UserTerminalSessionResponse{Status: models.TerminalPodTerminated}, final: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.final, isStartupStatusFinal(tt.status))
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package delete
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/cluster"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
+	"github.com/devtron-labs/devtron/pkg/clusterTerminalAccess"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const (
+	CleanupSucceeded = "Succeeded"
+	CleanupFailed    = "Failed"
+	CleanupSkipped   = "Skipped"
+)
+
+type ClusterTerminalSessionRef struct {
+	Id        int    `json:"id"`
+	UserId    int32  `json:"userId"`
+	PodName   string `json:"podName"`
+	Namespace string `json:"namespace"`
+	Status    string `json:"status"`
+}
+
+type ClusterEnvironmentRef struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// ClusterResourceCleanup is the outcome of the best effort cleanup of one resource created by devtron in the cluster
+type ClusterResourceCleanup struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+// ClusterDependencyReport lists what still refers to a cluster, Cleanup is only set once the dependencies are released
+type ClusterDependencyReport struct {
+	ClusterId        int                                      `json:"clusterId"`
+	TerminalSessions []*ClusterTerminalSessionRef             `json:"terminalSessions"`
+	Operations       []*clusterOperation.ClusterOperationBean `json:"operations"`
+	Environments     []*ClusterEnvironmentRef                 `json:"environments"`
+	Cleanup          []*ClusterResourceCleanup                `json:"cleanup,omitempty"`
+}
+
+func (report *ClusterDependencyReport) HasDependencies() bool {
+	return len(report.TerminalSessions) > 0 || len(report.Operations) > 0 || len(report.Environments) > 0
+}
+
+type ClusterDeleteCheckService interface {
+	CheckClusterDependencies(ctx context.Context, clusterId int) (*ClusterDependencyReport, error)
+	// ReleaseAndDeleteCluster terminates the terminal sessions, cancels the running operations, deletes the
+	// environments and then the cluster in one transaction. Environments are deleted through DeleteService so the
+	// release fails when cd pipelines or helm apps are still deployed to one of them. Terminal pods and environment
+	// namespaces left empty are then removed from the cluster if it is reachable, failures there are only reported
+	ReleaseAndDeleteCluster(ctx context.Context, clusterId int, userId int32) (*ClusterDependencyReport, error)
+}
+
+type ClusterDeleteCheckServiceImpl struct {
+	logger                    *zap.SugaredLogger
+	clusterService            cluster.ClusterService
+	environmentRepository     repository2.EnvironmentRepository
+	terminalAccessRepository  repository.TerminalAccessRepository
+	userTerminalAccessService clusterTerminalAccess.UserTerminalAccessService
+	clusterOperationService   clusterOperation.ClusterOperationService
+	deleteService             DeleteService
+	transactionUtil           sql.TransactionUtil
+	k8sUtil                   *util.K8sUtil
+}
+
+func NewClusterDeleteCheckServiceImpl(logger *zap.SugaredLogger, clusterService cluster.ClusterService,
+	environmentRepository repository2.EnvironmentRepository, terminalAccessRepository repository.TerminalAccessRepository,
+	userTerminalAccessService clusterTerminalAccess.UserTerminalAccessService, clusterOperationService clusterOperation.ClusterOperationService,
+	deleteService DeleteService, transactionUtil sql.TransactionUtil, k8sUtil *util.K8sUtil) *ClusterDeleteCheckServiceImpl {
+	return &ClusterDeleteCheckServiceImpl{
+		logger:                    logger,
+		clusterService:            clusterService,
+		environmentRepository:     environmentRepository,
+		terminalAccessRepository:  terminalAccessRepository,
+		userTerminalAccessService: userTerminalAccessService,
+		clusterOperationService:   clusterOperationService,
+		deleteService:             deleteService,
+		transactionUtil:           transactionUtil,
+		k8sUtil:                   k8sUtil,
+	}
+}
+
+func (impl *ClusterDeleteCheckServiceImpl) CheckClusterDependencies(ctx context.Context, clusterId int) (*ClusterDependencyReport, error) {
+	logger := util.LoggerFromContext(ctx, impl.logger)
+	report := &ClusterDependencyReport{
+		ClusterId:        clusterId,
+		TerminalSessions: make([]*ClusterTerminalSessionRef, 0),
+		Environments:     make([]*ClusterEnvironmentRef, 0),
+	}
+	sessions, err := impl.terminalAccessRepository.GetAllRunningUserTerminalData()
+	if err != nil {
+		logger.Errorw("error in getting running terminal sessions", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+	for _, session := range sessions {
+		if session.ClusterId != clusterId {
+			continue
+		}
+		ref := &ClusterTerminalSessionRef{Id: session.Id, UserId: session.UserId, PodName: session.PodName, Status: session.Status}
+		metadata := make(map[string]string)
+		if err := json.Unmarshal([]byte(session.Metadata), &metadata); err == nil {
+			ref.Namespace = metadata["Namespace"]
+		}
+		report.TerminalSessions = append(report.TerminalSessions, ref)
+	}
+	report.Operations, err = impl.clusterOperationService.FindRunningByClusterId(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	environments, err := impl.environmentRepository.FindByClusterId(clusterId)
+	if err != nil && err != pg.ErrNoRows {
+		logger.Errorw("error in getting environments of cluster", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+	for _, environment := range environments {
+		report.Environments = append(report.Environments, &ClusterEnvironmentRef{Id: environment.Id, Name: environment.Name, Namespace: environment.Namespace})
+	}
+	return report, nil
+}
+
+func (impl *ClusterDeleteCheckServiceImpl) ReleaseAndDeleteCluster(ctx context.Context, clusterId int, userId int32) (*ClusterDependencyReport, error) {
+	logger := util.LoggerFromContext(ctx, impl.logger)
+	report, err := impl.CheckClusterDependencies(ctx, clusterId)
+	if err != nil {
+		return nil, err
+	}
+	// the cluster is looked up before the delete as inactive clusters are not found afterwards
+	clusterBean, err := impl.clusterService.FindById(clusterId)
+	if err != nil {
+		logger.Errorw("error in getting cluster", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+	err = impl.transactionUtil.WithTx(ctx, func(tx *pg.Tx) error {
+		if _, err := impl.terminalAccessRepository.MarkClusterSessionsTerminated(clusterId, tx); err != nil {
+			logger.Errorw("error in terminating terminal sessions of cluster", "clusterId", clusterId, "err", err)
+			return err
+		}
+		if _, err := impl.clusterOperationService.MarkClusterOperationsCancelled(clusterId, tx); err != nil {
+			return err
+		}
+		for _, ref := range report.Environments {
+			envBean := &cluster.EnvironmentBean{Id: ref.Id, Environment: ref.Name, ClusterId: clusterId, Namespace: ref.Namespace}
+			if err := impl.deleteService.DeleteEnvironmentWithTx(envBean, userId, tx); err != nil {
+				return err
+			}
+		}
+		return impl.deleteService.DeleteClusterWithTx(&cluster.ClusterBean{Id: clusterId, ClusterName: clusterBean.ClusterName}, userId, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	impl.clusterService.CleanNamespaceInformer(clusterBean.ClusterName)
+	impl.clusterOperationService.CancelClusterOperations(clusterId)
+	logger.Infow("released cluster dependencies and deleted cluster", "clusterId", clusterId, "terminalSessions", len(report.TerminalSessions),
+		"operations", len(report.Operations), "environments", len(report.Environments), "userId", userId)
+	report.Cleanup = impl.cleanupClusterResources(ctx, clusterBean, report)
+	return report, nil
+}
+
+// cleanupClusterResources deletes terminal pods and the environment namespaces which run no pods and only hold
+// resources created by kubernetes or devtron, nothing is attempted when the cluster cannot be reached
+func (impl *ClusterDeleteCheckServiceImpl) cleanupClusterResources(ctx context.Context, clusterBean *cluster.ClusterBean, report *ClusterDependencyReport) []*ClusterResourceCleanup {
+	logger := util.LoggerFromContext(ctx, impl.logger)
+	clusterId := clusterBean.Id
+	var unreachable error
+	clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		unreachable = err
+	} else if len(clusterBean.ErrorInConnecting) > 0 {
+		unreachable = fmt.Errorf("cluster is not reachable: %s", clusterBean.ErrorInConnecting)
+	}
+	outcomes := impl.userTerminalAccessService.TerminateClusterSessions(ctx, clusterId, unreachable == nil)
+	cleanup := make([]*ClusterResourceCleanup, 0, len(report.TerminalSessions)+len(report.Environments))
+	for _, session := range report.TerminalSessions {
+		cleanup = append(cleanup, newClusterResourceCleanup("Pod", session.Namespace, session.PodName, outcomes[session.Id], unreachable))
+	}
+	namespaces := make(map[string]bool)
+	for _, environment := range report.Environments {
+		if namespaces[environment.Namespace] || len(environment.Namespace) == 0 {
+			continue
+		}
+		namespaces[environment.Namespace] = true
+		if unreachable != nil {
+			cleanup = append(cleanup, newClusterResourceCleanup("Namespace", "", environment.Namespace, nil, unreachable))
+			continue
+		}
+		deleted, err := impl.k8sUtil.DeleteNamespaceIfEmpty(ctx, environment.Namespace, clusterConfig)
+		if err != nil {
+			logger.Errorw("error in deleting namespace of removed cluster", "clusterId", clusterId, "namespace", environment.Namespace, "err", err)
+		}
+		outcome := newClusterResourceCleanup("Namespace", "", environment.Namespace, err, nil)
+		if err == nil && !deleted {
+			outcome.Status = CleanupSkipped
+			outcome.Message = "namespace runs pods or holds resources not managed by devtron"
+		}
+		cleanup = append(cleanup, outcome)
+	}
+	return cleanup
+}
+
+func newClusterResourceCleanup(kind, namespace, name string, err error, unreachable error) *ClusterResourceCleanup {
+	cleanup := &ClusterResourceCleanup{Kind: kind, Namespace: namespace, Name: name, Status: CleanupSucceeded}
+	if unreachable != nil {
+		cleanup.Status = CleanupSkipped
+		cleanup.Message = unreachable.Error()
+	} else if err != nil {
+		cleanup.Status = CleanupFailed
+		cleanup.Message = err.Error()
+	}
+	return cleanup
+}
@@ -0,0 +1,211 @@
+package delete
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/cluster"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
+	"github.com/devtron-labs/devtron/pkg/clusterTerminalAccess"
+	"github.com/go-pg/pg"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type clusterServiceStub struct {
+	cluster.ClusterService
+	cluster          *cluster.ClusterBean
+	cleanedInformers []string
+}
+
+func (s *clusterServiceStub) FindById(id int) (*cluster.ClusterBean, error) {
+	return s.cluster, nil
+}
+
+func (s *clusterServiceStub) GetClusterConfig(bean *cluster.ClusterBean) (*util.ClusterConfig, error) {
+	return &util.ClusterConfig{Host: bean.ServerUrl}, nil
+}
+
+func (s *clusterServiceStub) CleanNamespaceInformer(clusterName string) {
+	s.cleanedInformers = append(s.cleanedInformers, clusterName)
+}
+
+type environmentRepositoryStub struct {
+	repository2.EnvironmentRepository
+	environments []*repository2.Environment
+}
+
+func (s *environmentRepositoryStub) FindByClusterId(clusterId int) ([]*repository2.Environment, error) {
+	return s.environments, nil
+}
+
+type terminalAccessRepositoryStub struct {
+	repository.TerminalAccessRepository
+	sessions   []*models.UserTerminalAccessData
+	terminated []int
+}
+
+func (s *terminalAccessRepositoryStub) GetAllRunningUserTerminalData() ([]*models.UserTerminalAccessData, error) {
+	return s.sessions, nil
+}
+
+func (s *terminalAccessRepositoryStub) MarkClusterSessionsTerminated(clusterId int, tx *pg.Tx) (int, error) {
+	s.terminated = append(s.terminated, clusterId)
+	return len(s.sessions), nil
+}
+
+type userTerminalAccessServiceStub struct {
+	clusterTerminalAccess.UserTerminalAccessService
+	deletePods *bool
+}
+
+func (s *userTerminalAccessServiceStub) TerminateClusterSessions(ctx context.Context, clusterId int, deletePods bool) map[int]error {
+	s.deletePods = &deletePods
+	return map[int]error{}
+}
+
+type clusterOperationServiceStub struct {
+	clusterOperation.ClusterOperationService
+	cancelled []int
+}
+
+func (s *clusterOperationServiceStub) FindRunningByClusterId(clusterId int) ([]*clusterOperation.ClusterOperationBean, error) {
+	return []*clusterOperation.ClusterOperationBean{{Id: 7, ClusterId: clusterId}}, nil
+}
+
+func (s *clusterOperationServiceStub) MarkClusterOperationsCancelled(clusterId int, tx *pg.Tx) (int, error) {
+	return 1, nil
+}
+
+func (s *clusterOperationServiceStub) CancelClusterOperations(clusterId int) {
+	s.cancelled = append(s.cancelled, clusterId)
+}
+
+type deleteServiceStub struct {
+	DeleteService
+	environmentErr      map[int]error
+	clusterErr          error
+	deletedEnvironments []int
+	deletedClusters     []int
+}
+
+func (s *deleteServiceStub) DeleteEnvironmentWithTx(deleteRequest *cluster.EnvironmentBean, userId int32, tx *pg.Tx) error {
+	if err := s.environmentErr[deleteRequest.Id]; err != nil {
+		return err
+	}
+	s.deletedEnvironments = append(s.deletedEnvironments, deleteRequest.Id)
+	return nil
+}
+
+func (s *deleteServiceStub) DeleteClusterWithTx(deleteRequest *cluster.ClusterBean, userId int32, tx *pg.Tx) error {
+	if s.clusterErr != nil {
+		return s.clusterErr
+	}
+	s.deletedClusters = append(s.deletedClusters, deleteRequest.Id)
+	return nil
+}
+
+// transactionUtilStub runs fn without a database and records whether the transaction would have been committed
+type transactionUtilStub struct {
+	committed bool
+}
+
+func (s *transactionUtilStub) WithTx(ctx context.Context, fn func(tx *pg.Tx) error) error {
+	err := fn(nil)
+	s.committed = err == nil
+	return err
+}
+
+type clusterDeleteCheckFixture struct {
+	service            *ClusterDeleteCheckServiceImpl
+	clusterService     *clusterServiceStub
+	terminalRepository *terminalAccessRepositoryStub
+	terminalService    *userTerminalAccessServiceStub
+	operationService   *clusterOperationServiceStub
+	deleteService      *deleteServiceStub
+	transactionUtil    *transactionUtilStub
+}
+
+func newClusterDeleteCheckFixture() *clusterDeleteCheckFixture {
+	f := &clusterDeleteCheckFixture{
+		clusterService: &clusterServiceStub{cluster: &cluster.ClusterBean{Id: 3, ClusterName: "staging", ErrorInConnecting: "dial tcp: i/o timeout"}},
+		terminalRepository: &terminalAccessRepositoryStub{sessions: []*models.UserTerminalAccessData{
+			{Id: 11, ClusterId: 3, PodName: "terminal-access-11", Status: "Running", Metadata: `{"Namespace":"default"}`},
+			{Id: 12, ClusterId: 4, PodName: "terminal-access-12", Status: "Running"},
+		}},
+		terminalService:  &userTerminalAccessServiceStub{},
+		operationService: &clusterOperationServiceStub{},
+		deleteService:    &deleteServiceStub{environmentErr: map[int]error{}},
+		transactionUtil:  &transactionUtilStub{},
+	}
+	environments := &environmentRepositoryStub{environments: []*repository2.Environment{
+		{Id: 21, Name: "staging-web", Namespace: "web"},
+		{Id: 22, Name: "staging-web-canary", Namespace: "web"},
+	}}
+	f.service = NewClusterDeleteCheckServiceImpl(zap.NewNop().Sugar(), f.clusterService, environments, f.terminalRepository,
+		f.terminalService, f.operationService, f.deleteService, f.transactionUtil, nil)
+	return f
+}
+
+func TestCheckClusterDependencies(t *testing.T) {
+	f := newClusterDeleteCheckFixture()
+	report, err := f.service.CheckClusterDependencies(context.Background(), 3)
+	assert.Nil(t, err)
+	assert.True(t, report.HasDependencies())
+	assert.Equal(t, []*ClusterTerminalSessionRef{{Id: 11, PodName: "terminal-access-11", Namespace: "default", Status: "Running"}}, report.TerminalSessions)
+	assert.Len(t, report.Operations, 1)
+	assert.Len(t, report.Environments, 2)
+	assert.Nil(t, report.Cleanup)
+}
+
+func TestReleaseAndDeleteCluster(t *testing.T) {
+	f := newClusterDeleteCheckFixture()
+	report, err := f.service.ReleaseAndDeleteCluster(context.Background(), 3, 2)
+	assert.Nil(t, err)
+	assert.True(t, f.transactionUtil.committed)
+	assert.Equal(t, []int{3}, f.terminalRepository.terminated)
+	assert.Equal(t, []int{21, 22}, f.deleteService.deletedEnvironments)
+	assert.Equal(t, []int{3}, f.deleteService.deletedClusters)
+	assert.Equal(t, []string{"staging"}, f.clusterService.cleanedInformers)
+	assert.Equal(t, []int{3}, f.operationService.cancelled)
+	// the cluster is unreachable so pods are left alone and the shared namespace is reported once
+	assert.False(t, *f.terminalService.deletePods)
+	assert.Len(t, report.Cleanup, 2)
+	for _, cleanup := range report.Cleanup {
+		assert.Equal(t, CleanupSkipped, cleanup.Status)
+	}
+	assert.Equal(t, "Namespace", report.Cleanup[1].Kind)
+	assert.Equal(t, "web", report.Cleanup[1].Name)
+}
+
+func TestReleaseAndDeleteClusterRollsBack(t *testing.T) {
+	tests := []struct {
+		name           string
+		environmentErr map[int]error
+		clusterErr     error
+	}{
+		{name: "environment in use", environmentErr: map[int]error{22: errors.New(" Please delete all related cd pipelines before deleting this environment")}},
+		{name: "cluster delete fails", clusterErr: errors.New("connection reset")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newClusterDeleteCheckFixture()
+			f.deleteService.environmentErr = tt.environmentErr
+			f.deleteService.clusterErr = tt.clusterErr
+			report, err := f.service.ReleaseAndDeleteCluster(context.Background(), 3, 2)
+			assert.NotNil(t, err)
+			assert.Nil(t, report)
+			assert.False(t, f.transactionUtil.committed)
+			assert.Empty(t, f.deleteService.deletedClusters)
+			// nothing outside the transaction is touched when it is rolled back
+			assert.Empty(t, f.clusterService.cleanedInformers)
+			assert.Empty(t, f.operationService.cancelled)
+			assert.Nil(t, f.terminalService.deletePods)
+		})
+	}
+}
@@ -5,12 +5,18 @@ import (
 	"github.com/devtron-labs/devtron/pkg/chartRepo"
 	"github.com/devtron-labs/devtron/pkg/cluster"
 	"github.com/devtron-labs/devtron/pkg/team"
+	"github.com/go-pg/pg"
 	"go.uber.org/zap"
 )
 
 type DeleteService interface {
 	DeleteCluster(deleteRequest *cluster.ClusterBean, userId int32) error
 	DeleteEnvironment(deleteRequest *cluster.EnvironmentBean, userId int32) error
+	// DeleteEnvironmentWithTx applies the checks of DeleteEnvironment and deletes the environment on tx
+	DeleteEnvironmentWithTx(deleteRequest *cluster.EnvironmentBean, userId int32, tx *pg.Tx) error
+	// DeleteClusterWithTx deletes the cluster on tx, its environments are expected to be deleted on the same tx so
+	// they are not checked. The namespace informer of the cluster is left to the caller to clean after commit
+	DeleteClusterWithTx(deleteRequest *cluster.ClusterBean, userId int32, tx *pg.Tx) error
 	DeleteTeam(deleteRequest *team.TeamRequest) error
 	DeleteChartRepo(deleteRequest *chartRepo.ChartRepoDto) error
 }
@@ -48,6 +54,15 @@ func (impl DeleteServiceImpl) DeleteCluster(deleteRequest *cluster.ClusterBean,
 	return nil
 }
 
+func (impl DeleteServiceImpl) DeleteClusterWithTx(deleteRequest *cluster.ClusterBean, userId int32, tx *pg.Tx) error {
+	err := impl.clusterService.DeleteFromDbWithTx(deleteRequest, userId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deleting cluster", "err", err, "deleteRequest", deleteRequest)
+		return err
+	}
+	return nil
+}
+
 func (impl DeleteServiceImpl) DeleteEnvironment(deleteRequest *cluster.EnvironmentBean, userId int32) error {
 	err := impl.environmentService.Delete(deleteRequest, userId)
 	if err != nil {
@@ -56,6 +71,16 @@ func (impl DeleteServiceImpl) DeleteEnvironment(deleteRequest *cluster.Environme
 	}
 	return nil
 }
+
+func (impl DeleteServiceImpl) DeleteEnvironmentWithTx(deleteRequest *cluster.EnvironmentBean, userId int32, tx *pg.Tx) error {
+	err := impl.environmentService.DeleteWithTx(deleteRequest, userId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deleting environment", "err", err, "deleteRequest", deleteRequest)
+		return err
+	}
+	return nil
+}
+
 func (impl DeleteServiceImpl) DeleteTeam(deleteRequest *team.TeamRequest) error {
 	err := impl.teamService.Delete(deleteRequest)
 	if err != nil {
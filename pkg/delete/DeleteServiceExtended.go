@@ -64,6 +64,28 @@ func (impl DeleteServiceExtendedImpl) DeleteCluster(deleteRequest *cluster.Clust
 }
 
 func (impl DeleteServiceExtendedImpl) DeleteEnvironment(deleteRequest *cluster.EnvironmentBean, userId int32) error {
+	err := impl.checkEnvironmentUnused(deleteRequest)
+	if err != nil {
+		return err
+	}
+	err = impl.environmentService.Delete(deleteRequest, userId)
+	if err != nil {
+		impl.logger.Errorw("error in deleting environment", "err", err, "deleteRequest", deleteRequest)
+		return err
+	}
+	return nil
+}
+
+func (impl DeleteServiceExtendedImpl) DeleteEnvironmentWithTx(deleteRequest *cluster.EnvironmentBean, userId int32, tx *pg.Tx) error {
+	err := impl.checkEnvironmentUnused(deleteRequest)
+	if err != nil {
+		return err
+	}
+	return impl.DeleteServiceImpl.DeleteEnvironmentWithTx(deleteRequest, userId, tx)
+}
+
+// checkEnvironmentUnused fails when cd pipelines or helm apps are still deployed to the environment
+func (impl DeleteServiceExtendedImpl) checkEnvironmentUnused(deleteRequest *cluster.EnvironmentBean) error {
 	//finding if this env is used in any cd pipelines, if yes then will not delete
 	pipelines, err := impl.pipelineRepository.FindActiveByEnvId(deleteRequest.Id)
 	if err != nil && err != pg.ErrNoRows {
@@ -89,12 +111,6 @@ func (impl DeleteServiceExtendedImpl) DeleteEnvironment(deleteRequest *cluster.E
 		impl.logger.Errorw("err in deleting env, found cd pipelines in this env", "envName", deleteRequest.Environment, "err", err)
 		return fmt.Errorf(" Please delete all related cd pipelines before deleting this environment")
 	}
-
-	err = impl.environmentService.Delete(deleteRequest, userId)
-	if err != nil {
-		impl.logger.Errorw("error in deleting environment", "err", err, "deleteRequest", deleteRequest)
-		return err
-	}
 	return nil
 }
 func (impl DeleteServiceExtendedImpl) DeleteTeam(deleteRequest *team.TeamRequest) error {
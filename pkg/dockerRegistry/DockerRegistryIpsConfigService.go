@@ -159,7 +159,7 @@ func (impl DockerRegistryIpsConfigServiceImpl) createOrUpdateDockerRegistryImage
 	impl.logger.Infow("creating/updating ips", "ipsName", ipsName, "clusterId", clusterId)
 
 	username := dockerRegistryBean.Username
-	password := dockerRegistryBean.Password
+	password := string(dockerRegistryBean.Password)
 	registryURL := dockerRegistryBean.RegistryURL
 	var email string
 
@@ -191,7 +191,7 @@ func (impl DockerRegistryIpsConfigServiceImpl) createOrUpdateDockerRegistryImage
 	// ignore for ecr ec2_iam role
 	if registryType == repository.REGISTRYTYPE_ECR {
 		awsAccessKeyId := dockerRegistryBean.AWSAccessKeyId
-		awsSecretAccessKey := dockerRegistryBean.AWSSecretAccessKey
+		awsSecretAccessKey := string(dockerRegistryBean.AWSSecretAccessKey)
 		if len(awsAccessKeyId) == 0 || len(awsSecretAccessKey) == 0 {
 			impl.logger.Info("ignoring for ecr ec2_iam role")
 			return nil
@@ -0,0 +1,249 @@
+package favorites
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const (
+	FavoriteTypeTerminalPreset   = "terminal-preset"
+	FavoriteTypeResourceBookmark = "resource-bookmark"
+	// maxFavoritesPerUser keeps a user from growing the table without bound
+	maxFavoritesPerUser = 200
+)
+
+// TerminalPreset is the cluster terminal a user opens often, it expands into a UserTerminalSessionRequest
+type TerminalPreset struct {
+	ClusterId        int    `json:"clusterId" validate:"number,gt=0"`
+	NodeName         string `json:"nodeName"`
+	Namespace        string `json:"namespace"`
+	BaseImage        string `json:"baseImage"`
+	ShellName        string `json:"shellName" validate:"omitempty,oneof=bash sh powershell cmd"`
+	RecordTranscript bool   `json:"recordTranscript"`
+}
+
+// ResourceBookmark is a resource list of the resource browser, LabelSelector narrows the list
+type ResourceBookmark struct {
+	ClusterId     int    `json:"clusterId" validate:"number,gt=0"`
+	Namespace     string `json:"namespace"`
+	Group         string `json:"group"`
+	Version       string `json:"version" validate:"required"`
+	Kind          string `json:"kind" validate:"required"`
+	LabelSelector string `json:"labelSelector"`
+}
+
+// FavoriteBean is a favorite of the logged in user, only the payload of its type is set
+type FavoriteBean struct {
+	Id               int               `json:"id"`
+	Type             string            `json:"type" validate:"required,oneof=terminal-preset resource-bookmark"`
+	Name             string            `json:"name" validate:"required,max=250"`
+	TerminalPreset   *TerminalPreset   `json:"terminalPreset,omitempty"`
+	ResourceBookmark *ResourceBookmark `json:"resourceBookmark,omitempty"`
+	UpdatedOn        time.Time         `json:"updatedOn"`
+	UserId           int32             `json:"-"`
+}
+
+type FavoriteService interface {
+	// SaveFavorite creates the favorite or replaces the one of the user with the same type and name
+	SaveFavorite(request *FavoriteBean) (*FavoriteBean, error)
+	// ListFavorites lists the favorites of the user, of all types when favoriteType is empty
+	ListFavorites(userId int32, favoriteType string) ([]*FavoriteBean, error)
+	DeleteFavorite(id int, userId int32) error
+	// ExpandTerminalRequest fills the fields of the request left empty from the terminal preset of FavoriteId, the
+	// expanded request goes through the same validation as one sent in full
+	ExpandTerminalRequest(request *models.UserTerminalSessionRequest) error
+}
+
+type FavoriteServiceImpl struct {
+	logger                 *zap.SugaredLogger
+	userFavoriteRepository repository.UserFavoriteRepository
+}
+
+func NewFavoriteServiceImpl(logger *zap.SugaredLogger, userFavoriteRepository repository.UserFavoriteRepository) *FavoriteServiceImpl {
+	return &FavoriteServiceImpl{
+		logger:                 logger,
+		userFavoriteRepository: userFavoriteRepository,
+	}
+}
+
+func (impl *FavoriteServiceImpl) SaveFavorite(request *FavoriteBean) (*FavoriteBean, error) {
+	request.Name = strings.TrimSpace(request.Name)
+	payload, err := favoritePayload(request)
+	if err != nil {
+		return nil, err
+	}
+	model, err := impl.userFavoriteRepository.FindByUserIdAndTypeAndName(request.UserId, request.Type, request.Name)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching favorite", "err", err, "userId", request.UserId, "name", request.Name)
+		return nil, err
+	}
+	if err == nil {
+		model.Payload = payload
+		model.UpdatedBy = request.UserId
+		model.UpdatedOn = time.Now()
+		err = impl.userFavoriteRepository.Update(model)
+		if err != nil {
+			impl.logger.Errorw("error in updating favorite", "err", err, "id", model.Id)
+			return nil, err
+		}
+		return toFavoriteBean(model)
+	}
+	count, err := impl.userFavoriteRepository.CountByUserId(request.UserId)
+	if err != nil {
+		impl.logger.Errorw("error in counting favorites", "err", err, "userId", request.UserId)
+		return nil, err
+	}
+	if count >= maxFavoritesPerUser {
+		return nil, favoriteError(http.StatusBadRequest, fmt.Sprintf("a user can have at most %d favorites", maxFavoritesPerUser))
+	}
+	model = &repository.UserFavorite{
+		UserId:  request.UserId,
+		Type:    request.Type,
+		Name:    request.Name,
+		Payload: payload,
+	}
+	model.CreatedBy = request.UserId
+	model.UpdatedBy = request.UserId
+	model.CreatedOn = time.Now()
+	model.UpdatedOn = time.Now()
+	err = impl.userFavoriteRepository.Save(model)
+	if err != nil {
+		impl.logger.Errorw("error in saving favorite", "err", err, "userId", request.UserId, "name", request.Name)
+		return nil, err
+	}
+	return toFavoriteBean(model)
+}
+
+func (impl *FavoriteServiceImpl) ListFavorites(userId int32, favoriteType string) ([]*FavoriteBean, error) {
+	models, err := impl.userFavoriteRepository.FindByUserId(userId, favoriteType)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching favorites", "err", err, "userId", userId)
+		return nil, err
+	}
+	favorites := make([]*FavoriteBean, 0, len(models))
+	for _, model := range models {
+		favorite, err := toFavoriteBean(model)
+		if err != nil {
+			impl.logger.Errorw("skipping unreadable favorite", "err", err, "id", model.Id)
+			continue
+		}
+		favorites = append(favorites, favorite)
+	}
+	return favorites, nil
+}
+
+func (impl *FavoriteServiceImpl) DeleteFavorite(id int, userId int32) error {
+	model, err := impl.findFavorite(id, userId)
+	if err != nil {
+		return err
+	}
+	err = impl.userFavoriteRepository.Delete(model)
+	if err != nil {
+		impl.logger.Errorw("error in deleting favorite", "err", err, "id", id)
+	}
+	return err
+}
+
+func (impl *FavoriteServiceImpl) ExpandTerminalRequest(request *models.UserTerminalSessionRequest) error {
+	if request.FavoriteId == 0 {
+		return nil
+	}
+	model, err := impl.findFavorite(request.FavoriteId, request.UserId)
+	if err != nil {
+		return err
+	}
+	favorite, err := toFavoriteBean(model)
+	if err != nil {
+		return err
+	}
+	if favorite.TerminalPreset == nil {
+		return favoriteError(http.StatusBadRequest, fmt.Sprintf("favorite %d is not a terminal preset", request.FavoriteId))
+	}
+	expandTerminalPreset(request, favorite.TerminalPreset)
+	return nil
+}
+
+// findFavorite does not tell the favorites of other users apart from missing ones
+func (impl *FavoriteServiceImpl) findFavorite(id int, userId int32) (*repository.UserFavorite, error) {
+	model, err := impl.userFavoriteRepository.FindByIdAndUserId(id, userId)
+	if err == pg.ErrNoRows {
+		return nil, favoriteError(http.StatusNotFound, fmt.Sprintf("favorite %d not found", id))
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching favorite", "err", err, "id", id)
+		return nil, err
+	}
+	return model, nil
+}
+
+// expandTerminalPreset keeps the fields set on the request, so that a preset can be started on another node
+func expandTerminalPreset(request *models.UserTerminalSessionRequest, preset *TerminalPreset) {
+	if request.ClusterId == 0 {
+		request.ClusterId = preset.ClusterId
+	}
+	if len(request.NodeName) == 0 {
+		request.NodeName = preset.NodeName
+	}
+	if len(request.Namespace) == 0 {
+		request.Namespace = preset.Namespace
+	}
+	if len(request.BaseImage) == 0 {
+		request.BaseImage = preset.BaseImage
+	}
+	if len(request.ShellName) == 0 {
+		request.ShellName = preset.ShellName
+	}
+	request.RecordTranscript = request.RecordTranscript || preset.RecordTranscript
+}
+
+func favoritePayload(request *FavoriteBean) (string, error) {
+	var payload interface{}
+	switch request.Type {
+	case FavoriteTypeTerminalPreset:
+		if request.TerminalPreset == nil || request.ResourceBookmark != nil {
+			return "", favoriteError(http.StatusBadRequest, "terminalPreset is required for a terminal-preset favorite")
+		}
+		payload = request.TerminalPreset
+	case FavoriteTypeResourceBookmark:
+		if request.ResourceBookmark == nil || request.TerminalPreset != nil {
+			return "", favoriteError(http.StatusBadRequest, "resourceBookmark is required for a resource-bookmark favorite")
+		}
+		payload = request.ResourceBookmark
+	default:
+		return "", favoriteError(http.StatusBadRequest, fmt.Sprintf("unknown favorite type %s", request.Type))
+	}
+	content, err := json.Marshal(payload)
+	return string(content), err
+}
+
+func toFavoriteBean(model *repository.UserFavorite) (*FavoriteBean, error) {
+	favorite := &FavoriteBean{Id: model.Id, Type: model.Type, Name: model.Name, UpdatedOn: model.UpdatedOn, UserId: model.UserId}
+	var err error
+	switch model.Type {
+	case FavoriteTypeTerminalPreset:
+		favorite.TerminalPreset = &TerminalPreset{}
+		err = json.Unmarshal([]byte(model.Payload), favorite.TerminalPreset)
+	case FavoriteTypeResourceBookmark:
+		favorite.ResourceBookmark = &ResourceBookmark{}
+		err = json.Unmarshal([]byte(model.Payload), favorite.ResourceBookmark)
+	default:
+		err = fmt.Errorf("unknown favorite type %s", model.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return favorite, nil
+}
+
+func favoriteError(status int, message string) error {
+	return &util.ApiError{HttpStatusCode: status, Code: strconv.Itoa(status), UserMessage: message, InternalMessage: message}
+}
@@ -0,0 +1,38 @@
+package favorites
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandTerminalPreset(t *testing.T) {
+	preset := &TerminalPreset{ClusterId: 2, NodeName: "node-a", Namespace: "payments", BaseImage: "alpine:3.18", ShellName: "bash", RecordTranscript: true}
+	request := &models.UserTerminalSessionRequest{NodeName: "node-b", FavoriteId: 5}
+	expandTerminalPreset(request, preset)
+	assert.Equal(t, 2, request.ClusterId)
+	// the node set on the request wins over the one of the preset
+	assert.Equal(t, "node-b", request.NodeName)
+	assert.Equal(t, "payments", request.Namespace)
+	assert.Equal(t, "alpine:3.18", request.BaseImage)
+	assert.Equal(t, "bash", request.ShellName)
+	assert.True(t, request.RecordTranscript)
+}
+
+func TestFavoritePayload(t *testing.T) {
+	bookmark := &FavoriteBean{Type: FavoriteTypeResourceBookmark, Name: "pods", ResourceBookmark: &ResourceBookmark{ClusterId: 1, Version: "v1", Kind: "Pod", LabelSelector: "app=web"}}
+	payload, err := favoritePayload(bookmark)
+	assert.NoError(t, err)
+
+	favorite, err := toFavoriteBean(&repository.UserFavorite{Id: 3, Type: FavoriteTypeResourceBookmark, Name: "pods", Payload: payload})
+	assert.NoError(t, err)
+	assert.Equal(t, bookmark.ResourceBookmark, favorite.ResourceBookmark)
+	assert.Nil(t, favorite.TerminalPreset)
+
+	_, err = favoritePayload(&FavoriteBean{Type: FavoriteTypeTerminalPreset, Name: "debug", ResourceBookmark: bookmark.ResourceBookmark})
+	assert.Error(t, err)
+	_, err = toFavoriteBean(&repository.UserFavorite{Type: "dashboard", Payload: "{}"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,213 @@
+package health
+
+import (
+	"context"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/shutdown"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"k8s.io/client-go/discovery"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "UP"
+	StatusDown = "DOWN"
+
+	CheckHeartbeat      = "heartbeat"
+	CheckShutdown       = "shutdown"
+	CheckDatabase       = "database"
+	CheckDefaultCluster = "defaultCluster"
+)
+
+type HealthCheckConfig struct {
+	DatabaseTimeoutInMillis       int  `env:"HEALTH_CHECK_DATABASE_TIMEOUT_IN_MILLIS" envDefault:"1000"`
+	DefaultClusterCheckEnabled    bool `env:"HEALTH_CHECK_DEFAULT_CLUSTER_ENABLED" envDefault:"false"`
+	DefaultClusterTimeoutInMillis int  `env:"HEALTH_CHECK_DEFAULT_CLUSTER_TIMEOUT_IN_MILLIS" envDefault:"2000"`
+	DefaultClusterCacheTtlInSecs  int  `env:"HEALTH_CHECK_DEFAULT_CLUSTER_CACHE_TTL_IN_SECS" envDefault:"30"`
+	HeartbeatStallThresholdInSecs int  `env:"HEALTH_CHECK_HEARTBEAT_STALL_THRESHOLD_IN_SECS" envDefault:"30"`
+}
+
+type SubsystemStatus struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+	Cached    bool   `json:"cached,omitempty"`
+}
+
+type HealthStatus struct {
+	Status string             `json:"status"`
+	Checks []*SubsystemStatus `json:"checks"`
+}
+
+func (s *HealthStatus) IsUp() bool {
+	return s.Status == StatusUp
+}
+
+type HealthCheckService interface {
+	// Liveness only looks at the process itself, dependencies being down must not get the pod restarted
+	Liveness() *HealthStatus
+	// Readiness checks the dependencies needed to serve traffic
+	Readiness(ctx context.Context) *HealthStatus
+}
+
+type checkFunc func(ctx context.Context) error
+
+type HealthCheckServiceImpl struct {
+	logger                  *zap.SugaredLogger
+	config                  *HealthCheckConfig
+	gracefulShutdownService shutdown.GracefulShutdownService
+	pingDatabase            checkFunc
+	checkDefaultCluster     checkFunc
+	now                     func() time.Time
+
+	heartbeatMutex sync.Mutex
+	lastHeartbeat  time.Time
+
+	clusterMutex     sync.Mutex
+	clusterStatus    *SubsystemStatus
+	clusterCheckedOn time.Time
+}
+
+func GetHealthCheckConfig() (*HealthCheckConfig, error) {
+	config := &HealthCheckConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+func NewHealthCheckServiceImpl(logger *zap.SugaredLogger, db *pg.DB, k8sUtil *util.K8sUtil,
+	gracefulShutdownService shutdown.GracefulShutdownService) (*HealthCheckServiceImpl, error) {
+	config, err := GetHealthCheckConfig()
+	if err != nil {
+		logger.Errorw("error in parsing health check config", "err", err)
+		return nil, err
+	}
+	impl := newHealthCheckServiceImpl(logger, config, gracefulShutdownService, databasePing(db, config), defaultClusterDiscovery(k8sUtil, config))
+	go impl.heartbeat()
+	return impl, nil
+}
+
+func newHealthCheckServiceImpl(logger *zap.SugaredLogger, config *HealthCheckConfig, gracefulShutdownService shutdown.GracefulShutdownService,
+	pingDatabase checkFunc, checkDefaultCluster checkFunc) *HealthCheckServiceImpl {
+	return &HealthCheckServiceImpl{
+		logger:                  logger,
+		config:                  config,
+		gracefulShutdownService: gracefulShutdownService,
+		pingDatabase:            pingDatabase,
+		checkDefaultCluster:     checkDefaultCluster,
+		now:                     time.Now,
+		lastHeartbeat:           time.Now(),
+	}
+}
+
+func databasePing(db *pg.DB, config *HealthCheckConfig) checkFunc {
+	timeout := time.Duration(config.DatabaseTimeoutInMillis) * time.Millisecond
+	return func(ctx context.Context) error {
+		_, err := db.WithContext(ctx).WithTimeout(timeout).Exec("SELECT 1")
+		return err
+	}
+}
+
+func defaultClusterDiscovery(k8sUtil *util.K8sUtil, config *HealthCheckConfig) checkFunc {
+	timeout := time.Duration(config.DefaultClusterTimeoutInMillis) * time.Millisecond
+	return func(ctx context.Context) error {
+		restConfig, err := k8sUtil.GetK8sClusterRestConfig()
+		if err != nil {
+			return err
+		}
+		restConfig.Timeout = timeout
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+		if err != nil {
+			return err
+		}
+		_, err = discoveryClient.ServerVersion()
+		return err
+	}
+}
+
+// heartbeat keeps ticking as long as the scheduler is healthy, a stale heartbeat means the process is wedged
+func (impl *HealthCheckServiceImpl) heartbeat() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		impl.beat()
+	}
+}
+
+func (impl *HealthCheckServiceImpl) beat() {
+	impl.heartbeatMutex.Lock()
+	defer impl.heartbeatMutex.Unlock()
+	impl.lastHeartbeat = impl.now()
+}
+
+func (impl *HealthCheckServiceImpl) Liveness() *HealthStatus {
+	impl.heartbeatMutex.Lock()
+	sinceLastBeat := impl.now().Sub(impl.lastHeartbeat)
+	impl.heartbeatMutex.Unlock()
+	heartbeat := &SubsystemStatus{Name: CheckHeartbeat, Status: StatusUp, LatencyMs: sinceLastBeat.Milliseconds()}
+	if sinceLastBeat > time.Duration(impl.config.HeartbeatStallThresholdInSecs)*time.Second {
+		heartbeat.Status = StatusDown
+		heartbeat.Error = "heartbeat stalled"
+	}
+	return newHealthStatus(heartbeat)
+}
+
+func (impl *HealthCheckServiceImpl) Readiness(ctx context.Context) *HealthStatus {
+	checks := []*SubsystemStatus{
+		impl.shutdownStatus(),
+		impl.runCheck(ctx, CheckDatabase, impl.pingDatabase),
+	}
+	if impl.config.DefaultClusterCheckEnabled {
+		checks = append(checks, impl.defaultClusterStatus(ctx))
+	}
+	return newHealthStatus(checks...)
+}
+
+func (impl *HealthCheckServiceImpl) shutdownStatus() *SubsystemStatus {
+	status := &SubsystemStatus{Name: CheckShutdown, Status: StatusUp}
+	if impl.gracefulShutdownService.IsDraining() {
+		status.Status = StatusDown
+		status.Error = "draining"
+	}
+	return status
+}
+
+// defaultClusterStatus caches the result for the configured ttl so that frequent probes do not load the api server
+func (impl *HealthCheckServiceImpl) defaultClusterStatus(ctx context.Context) *SubsystemStatus {
+	impl.clusterMutex.Lock()
+	defer impl.clusterMutex.Unlock()
+	if impl.clusterStatus != nil && impl.now().Sub(impl.clusterCheckedOn) < time.Duration(impl.config.DefaultClusterCacheTtlInSecs)*time.Second {
+		cached := *impl.clusterStatus
+		cached.Cached = true
+		return &cached
+	}
+	impl.clusterStatus = impl.runCheck(ctx, CheckDefaultCluster, impl.checkDefaultCluster)
+	impl.clusterCheckedOn = impl.now()
+	status := *impl.clusterStatus
+	return &status
+}
+
+func (impl *HealthCheckServiceImpl) runCheck(ctx context.Context, name string, check checkFunc) *SubsystemStatus {
+	start := impl.now()
+	err := check(ctx)
+	status := &SubsystemStatus{Name: name, Status: StatusUp, LatencyMs: impl.now().Sub(start).Milliseconds()}
+	if err != nil {
+		impl.logger.Errorw("health check failed", "check", name, "err", err)
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
+
+func newHealthStatus(checks ...*SubsystemStatus) *HealthStatus {
+	healthStatus := &HealthStatus{Status: StatusUp, Checks: checks}
+	for _, check := range checks {
+		if check.Status != StatusUp {
+			healthStatus.Status = StatusDown
+		}
+	}
+	return healthStatus
+}
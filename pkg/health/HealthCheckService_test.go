@@ -0,0 +1,124 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type shutdownServiceStub struct {
+	draining bool
+}
+
+func (s *shutdownServiceStub) IsDraining() bool {
+	return s.draining
+}
+
+func (s *shutdownServiceStub) Shutdown(stopServer func(ctx context.Context) error) {}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestHealthCheckService(t *testing.T, dbErr *error, clusterCalls *int, clusterErr *error) (*HealthCheckServiceImpl, *shutdownServiceStub, *fakeClock) {
+	logger, err := util.NewSugardLogger()
+	assert.Nil(t, err)
+	config := &HealthCheckConfig{
+		DefaultClusterCheckEnabled:    true,
+		DefaultClusterCacheTtlInSecs:  30,
+		HeartbeatStallThresholdInSecs: 30,
+	}
+	shutdownService := &shutdownServiceStub{}
+	impl := newHealthCheckServiceImpl(logger, config, shutdownService,
+		func(ctx context.Context) error { return *dbErr },
+		func(ctx context.Context) error {
+			*clusterCalls++
+			return *clusterErr
+		})
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	impl.now = clock.Now
+	impl.lastHeartbeat = clock.now
+	return impl, shutdownService, clock
+}
+
+func findCheck(status *HealthStatus, name string) *SubsystemStatus {
+	for _, check := range status.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	return nil
+}
+
+func TestHealthCheckService_Readiness(t *testing.T) {
+	var dbErr, clusterErr error
+	clusterCalls := 0
+	impl, shutdownService, clock := newTestHealthCheckService(t, &dbErr, &clusterCalls, &clusterErr)
+
+	status := impl.Readiness(context.Background())
+	assert.True(t, status.IsUp())
+	assert.Len(t, status.Checks, 3)
+
+	dbErr = errors.New("connection refused")
+	status = impl.Readiness(context.Background())
+	assert.False(t, status.IsUp())
+	assert.Equal(t, StatusDown, findCheck(status, CheckDatabase).Status)
+	assert.Equal(t, "connection refused", findCheck(status, CheckDatabase).Error)
+	assert.Equal(t, StatusUp, findCheck(status, CheckDefaultCluster).Status)
+	assert.True(t, findCheck(status, CheckDefaultCluster).Cached)
+
+	// cluster failures are only seen once the cached result expires
+	dbErr = nil
+	clusterErr = errors.New("discovery timeout")
+	assert.True(t, impl.Readiness(context.Background()).IsUp())
+	assert.Equal(t, 1, clusterCalls)
+	clock.now = clock.now.Add(31 * time.Second)
+	status = impl.Readiness(context.Background())
+	assert.False(t, status.IsUp())
+	assert.Equal(t, StatusDown, findCheck(status, CheckDefaultCluster).Status)
+	assert.False(t, findCheck(status, CheckDefaultCluster).Cached)
+	assert.Equal(t, 2, clusterCalls)
+
+	clusterErr = nil
+	clock.now = clock.now.Add(31 * time.Second)
+	shutdownService.draining = true
+	status = impl.Readiness(context.Background())
+	assert.False(t, status.IsUp())
+	assert.Equal(t, StatusDown, findCheck(status, CheckShutdown).Status)
+}
+
+func TestHealthCheckService_ReadinessWithoutClusterCheck(t *testing.T) {
+	var dbErr, clusterErr error
+	clusterCalls := 0
+	impl, _, _ := newTestHealthCheckService(t, &dbErr, &clusterCalls, &clusterErr)
+	impl.config.DefaultClusterCheckEnabled = false
+	status := impl.Readiness(context.Background())
+	assert.True(t, status.IsUp())
+	assert.Nil(t, findCheck(status, CheckDefaultCluster))
+	assert.Equal(t, 0, clusterCalls)
+}
+
+func TestHealthCheckService_Liveness(t *testing.T) {
+	var dbErr, clusterErr error
+	clusterCalls := 0
+	impl, shutdownService, clock := newTestHealthCheckService(t, &dbErr, &clusterCalls, &clusterErr)
+	// dependencies and draining do not affect liveness
+	dbErr = errors.New("connection refused")
+	shutdownService.draining = true
+	assert.True(t, impl.Liveness().IsUp())
+
+	clock.now = clock.now.Add(31 * time.Second)
+	status := impl.Liveness()
+	assert.False(t, status.IsUp())
+	assert.Equal(t, "heartbeat stalled", findCheck(status, CheckHeartbeat).Error)
+
+	impl.beat()
+	assert.True(t, impl.Liveness().IsUp())
+}
@@ -0,0 +1,174 @@
+package jobTemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	batchV1 "k8s.io/api/batch/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var jobTemplatePlaceholderRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// jobTemplateValidationValue stands in for every param when a template is validated, it is a valid name and image tag
+const jobTemplateValidationValue = "x"
+
+// parseJobTemplate converts the yaml to a json tree and lists the names of its placeholders. Placeholders are only
+// supported in string values, so a param can never change the structure of the rendered yaml
+func parseJobTemplate(content string) (interface{}, []string, error) {
+	jsonBytes, err := yaml.YAMLToJSON([]byte(content))
+	if err != nil {
+		return nil, nil, jobTemplateError(fmt.Sprintf("invalid template yaml: %s", err.Error()))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+	var tree interface{}
+	err = decoder.Decode(&tree)
+	if err != nil {
+		return nil, nil, jobTemplateError(fmt.Sprintf("invalid template yaml: %s", err.Error()))
+	}
+	placeholderMap := make(map[string]bool)
+	_, err = substituteJobTemplate(tree, func(value string) string {
+		for _, match := range jobTemplatePlaceholderRegex.FindAllStringSubmatch(value, -1) {
+			placeholderMap[match[1]] = true
+		}
+		return value
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	placeholders := make([]string, 0, len(placeholderMap))
+	for placeholder := range placeholderMap {
+		placeholders = append(placeholders, placeholder)
+	}
+	sort.Strings(placeholders)
+	return tree, placeholders, nil
+}
+
+// substituteJobTemplate returns a copy of the tree with substitute applied to every string value
+func substituteJobTemplate(node interface{}, substitute func(value string) string) (interface{}, error) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, child := range value {
+			if jobTemplatePlaceholderRegex.MatchString(key) {
+				return nil, jobTemplateError(fmt.Sprintf("placeholders are not supported in keys, found in %q", key))
+			}
+			substituted, err := substituteJobTemplate(child, substitute)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = substituted
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, 0, len(value))
+		for _, child := range value {
+			substituted, err := substituteJobTemplate(child, substitute)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, substituted)
+		}
+		return result, nil
+	case string:
+		return substitute(value), nil
+	default:
+		return value, nil
+	}
+}
+
+// renderJobTemplate substitutes every placeholder with its param, every placeholder must be supplied and every param
+// must be used. Param values are inserted into the parsed strings and serialized again, so newlines or yaml syntax in
+// a value stay part of that value and values are not scanned for placeholders again
+func renderJobTemplate(content string, params map[string]string) ([]byte, *batchV1.Job, error) {
+	tree, placeholders, err := parseJobTemplate(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	var missing []string
+	placeholderMap := make(map[string]bool)
+	for _, placeholder := range placeholders {
+		placeholderMap[placeholder] = true
+		if _, ok := params[placeholder]; !ok {
+			missing = append(missing, placeholder)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, jobTemplateError(fmt.Sprintf("no value for template params %s", strings.Join(missing, ", ")))
+	}
+	var unknown []string
+	for param := range params {
+		if !placeholderMap[param] {
+			unknown = append(unknown, param)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, nil, jobTemplateError(fmt.Sprintf("params %s are not used by the template", strings.Join(unknown, ", ")))
+	}
+	rendered, err := substituteJobTemplate(tree, func(value string) string {
+		return jobTemplatePlaceholderRegex.ReplaceAllStringFunc(value, func(match string) string {
+			return params[match[2:len(match)-1]]
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	jsonBytes, err := json.Marshal(rendered)
+	if err != nil {
+		return nil, nil, err
+	}
+	yamlBytes, err := yaml.JSONToYAML(jsonBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	job, err := decodeJob(yamlBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return yamlBytes, job, nil
+}
+
+// validateJobTemplate renders the template with a stand in value for every param and strictly decodes it into a Job
+func validateJobTemplate(content string) ([]string, error) {
+	_, placeholders, err := parseJobTemplate(content)
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string]string, len(placeholders))
+	for _, placeholder := range placeholders {
+		params[placeholder] = jobTemplateValidationValue
+	}
+	_, _, err = renderJobTemplate(content, params)
+	if err != nil {
+		return nil, err
+	}
+	return placeholders, nil
+}
+
+func decodeJob(content []byte) (*batchV1.Job, error) {
+	job := &batchV1.Job{}
+	err := yaml.UnmarshalStrict(content, job)
+	if err != nil {
+		return nil, jobTemplateError(fmt.Sprintf("template is not a valid Job: %s", err.Error()))
+	}
+	if job.Kind != "Job" || job.APIVersion != batchV1.SchemeGroupVersion.String() {
+		return nil, jobTemplateError(fmt.Sprintf("template must be a %s Job, found %s %s", batchV1.SchemeGroupVersion.String(), job.APIVersion, job.Kind))
+	}
+	if len(job.Name) == 0 {
+		return nil, jobTemplateError("template must set metadata.name of the Job")
+	}
+	return job, nil
+}
+
+func jobTemplateError(message string) error {
+	return &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: message, InternalMessage: message}
+}
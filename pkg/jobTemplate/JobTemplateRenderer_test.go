@@ -0,0 +1,79 @@
+package jobTemplate
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const testJobTemplate = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: sync-${name}
+spec:
+  backoffLimit: 2
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+        - name: sync
+          image: ${image}
+          args: ["--target", "${target}"]
+`
+
+func TestRenderJobTemplate(t *testing.T) {
+	content, job, err := renderJobTemplate(testJobTemplate, map[string]string{"name": "charts", "image": "quay.io/devtron/sync:v1", "target": "all"})
+	assert.Nil(t, err)
+	assert.Equal(t, "sync-charts", job.Name)
+	assert.Equal(t, int32(2), *job.Spec.BackoffLimit)
+	assert.Equal(t, "quay.io/devtron/sync:v1", job.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, []string{"--target", "all"}, job.Spec.Template.Spec.Containers[0].Args)
+	assert.Contains(t, string(content), "name: sync-charts")
+}
+
+func TestRenderJobTemplateMissingParams(t *testing.T) {
+	_, _, err := renderJobTemplate(testJobTemplate, map[string]string{"name": "charts"})
+	assert.NotNil(t, err)
+	apiErr, ok := err.(*util.ApiError)
+	assert.True(t, ok)
+	assert.Equal(t, 400, apiErr.HttpStatusCode)
+	assert.Equal(t, "no value for template params image, target", apiErr.UserMessage)
+
+	_, _, err = renderJobTemplate(testJobTemplate, map[string]string{"name": "charts", "image": "busybox", "target": "all", "tag": "v1"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "params tag are not used by the template")
+}
+
+func TestRenderJobTemplateInjection(t *testing.T) {
+	injected := "all\"]\n          securityContext:\n            privileged: true\n          args: [\"x"
+	_, job, err := renderJobTemplate(testJobTemplate, map[string]string{"name": "charts", "image": "busybox\nhostNetwork: true", "target": injected})
+	assert.Nil(t, err)
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Nil(t, container.SecurityContext)
+	assert.False(t, job.Spec.Template.Spec.HostNetwork)
+	assert.Equal(t, "busybox\nhostNetwork: true", container.Image)
+	assert.Equal(t, []string{"--target", injected}, container.Args)
+
+	// values are not scanned for placeholders again
+	_, job, err = renderJobTemplate(testJobTemplate, map[string]string{"name": "charts", "image": "${target}", "target": "all"})
+	assert.Nil(t, err)
+	assert.Equal(t, "${target}", job.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestValidateJobTemplate(t *testing.T) {
+	params, err := validateJobTemplate(testJobTemplate)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"image", "name", "target"}, params)
+
+	_, err = validateJobTemplate("apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: sync\nspec:\n  parallelism: 1\n  retries: 2\n")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "template is not a valid Job")
+
+	_, err = validateJobTemplate("apiVersion: v1\nkind: Pod\nmetadata:\n  name: sync\n")
+	assert.NotNil(t, err)
+
+	_, err = validateJobTemplate("apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: sync\n  labels:\n    ${key}: value\n")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "placeholders are not supported in keys")
+}
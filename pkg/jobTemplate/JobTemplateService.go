@@ -0,0 +1,190 @@
+package jobTemplate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+// JobTemplateBean is a Job yaml with ${param} placeholders, Params lists the placeholders found in Template
+type JobTemplateBean struct {
+	Id          int      `json:"id"`
+	Name        string   `json:"name" validate:"required,max=250"`
+	Description string   `json:"description"`
+	Template    string   `json:"template" validate:"required"`
+	Params      []string `json:"params"`
+	UserId      int32    `json:"-"`
+}
+
+type JobTemplateService interface {
+	CreateTemplate(request *JobTemplateBean) (*JobTemplateBean, error)
+	UpdateTemplate(request *JobTemplateBean) (*JobTemplateBean, error)
+	GetTemplate(name string) (*JobTemplateBean, error)
+	ListTemplates() ([]*JobTemplateBean, error)
+	DeleteTemplate(name string, userId int32) error
+	// RenderJobTemplate returns the yaml of the Job, every placeholder of the template must have a param
+	RenderJobTemplate(name string, params map[string]string) ([]byte, error)
+	// CreateJobFromTemplate renders the template and recreates the Job in the namespace through DeleteAndCreateJob
+	CreateJobFromTemplate(ctx context.Context, name string, params map[string]string, namespace string, clusterConfig *util.ClusterConfig) error
+}
+
+type JobTemplateServiceImpl struct {
+	logger                *zap.SugaredLogger
+	jobTemplateRepository repository.JobTemplateRepository
+	k8sUtil               *util.K8sUtil
+}
+
+func NewJobTemplateServiceImpl(logger *zap.SugaredLogger, jobTemplateRepository repository.JobTemplateRepository,
+	k8sUtil *util.K8sUtil) *JobTemplateServiceImpl {
+	return &JobTemplateServiceImpl{
+		logger:                logger,
+		jobTemplateRepository: jobTemplateRepository,
+		k8sUtil:               k8sUtil,
+	}
+}
+
+func (impl *JobTemplateServiceImpl) CreateTemplate(request *JobTemplateBean) (*JobTemplateBean, error) {
+	request.Name = strings.TrimSpace(request.Name)
+	params, err := validateJobTemplate(request.Template)
+	if err != nil {
+		return nil, err
+	}
+	_, err = impl.jobTemplateRepository.FindActiveByName(request.Name)
+	if err == nil {
+		message := fmt.Sprintf("job template %s already exists", request.Name)
+		return nil, &util.ApiError{HttpStatusCode: http.StatusConflict, Code: strconv.Itoa(http.StatusConflict), UserMessage: message, InternalMessage: message}
+	} else if err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching job template", "err", err, "name", request.Name)
+		return nil, err
+	}
+	model := &repository.JobTemplate{
+		Name:        request.Name,
+		Description: request.Description,
+		Template:    request.Template,
+		Active:      true,
+	}
+	model.CreatedBy = request.UserId
+	model.UpdatedBy = request.UserId
+	model.CreatedOn = time.Now()
+	model.UpdatedOn = time.Now()
+	err = impl.jobTemplateRepository.Save(model)
+	if err != nil {
+		impl.logger.Errorw("error in saving job template", "err", err, "name", request.Name)
+		return nil, err
+	}
+	request.Id = model.Id
+	request.Params = params
+	return request, nil
+}
+
+func (impl *JobTemplateServiceImpl) UpdateTemplate(request *JobTemplateBean) (*JobTemplateBean, error) {
+	params, err := validateJobTemplate(request.Template)
+	if err != nil {
+		return nil, err
+	}
+	model, err := impl.findActiveByName(request.Name)
+	if err != nil {
+		return nil, err
+	}
+	model.Description = request.Description
+	model.Template = request.Template
+	model.UpdatedBy = request.UserId
+	model.UpdatedOn = time.Now()
+	err = impl.jobTemplateRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("error in updating job template", "err", err, "name", request.Name)
+		return nil, err
+	}
+	request.Id = model.Id
+	request.Params = params
+	return request, nil
+}
+
+func (impl *JobTemplateServiceImpl) GetTemplate(name string) (*JobTemplateBean, error) {
+	model, err := impl.findActiveByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return adaptJobTemplate(model), nil
+}
+
+func (impl *JobTemplateServiceImpl) ListTemplates() ([]*JobTemplateBean, error) {
+	models, err := impl.jobTemplateRepository.FindAllActive()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in fetching job templates", "err", err)
+		return nil, err
+	}
+	templates := make([]*JobTemplateBean, 0, len(models))
+	for _, model := range models {
+		templates = append(templates, adaptJobTemplate(model))
+	}
+	return templates, nil
+}
+
+func (impl *JobTemplateServiceImpl) DeleteTemplate(name string, userId int32) error {
+	model, err := impl.findActiveByName(name)
+	if err != nil {
+		return err
+	}
+	model.Active = false
+	model.UpdatedBy = userId
+	model.UpdatedOn = time.Now()
+	err = impl.jobTemplateRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("error in deleting job template", "err", err, "name", name)
+	}
+	return err
+}
+
+func (impl *JobTemplateServiceImpl) RenderJobTemplate(name string, params map[string]string) ([]byte, error) {
+	model, err := impl.findActiveByName(name)
+	if err != nil {
+		return nil, err
+	}
+	content, _, err := renderJobTemplate(model.Template, params)
+	return content, err
+}
+
+func (impl *JobTemplateServiceImpl) CreateJobFromTemplate(ctx context.Context, name string, params map[string]string, namespace string, clusterConfig *util.ClusterConfig) error {
+	content, err := impl.RenderJobTemplate(name, params)
+	if err != nil {
+		return err
+	}
+	err = impl.k8sUtil.DeleteAndCreateJob(ctx, content, namespace, clusterConfig)
+	if err != nil {
+		impl.logger.Errorw("DeleteAndCreateJob err, CreateJobFromTemplate", "err", err, "name", name, "namespace", namespace)
+	}
+	return err
+}
+
+func (impl *JobTemplateServiceImpl) findActiveByName(name string) (*repository.JobTemplate, error) {
+	model, err := impl.jobTemplateRepository.FindActiveByName(name)
+	if err == pg.ErrNoRows {
+		message := fmt.Sprintf("job template %s not found", name)
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, Code: strconv.Itoa(http.StatusNotFound), UserMessage: message, InternalMessage: message}
+	} else if err != nil {
+		impl.logger.Errorw("error in fetching job template", "err", err, "name", name)
+		return nil, err
+	}
+	return model, nil
+}
+
+func adaptJobTemplate(model *repository.JobTemplate) *JobTemplateBean {
+	// stored templates were validated on save, a template failing to parse is still listed without its params
+	_, params, _ := parseJobTemplate(model.Template)
+	return &JobTemplateBean{
+		Id:          model.Id,
+		Name:        model.Name,
+		Description: model.Description,
+		Template:    model.Template,
+		Params:      params,
+	}
+}
@@ -21,6 +21,7 @@ const (
 type K8sResourceHistoryService interface {
 	SaveArgoCdAppsResourceDeleteHistory(query *application.ApplicationResourceDeleteRequest, appId int, envId int, userId int32) error
 	SaveHelmAppsResourceHistory(appIdentifier *client.AppIdentifier, k8sRequestBean *application2.K8sRequestBean, userId int32, actionType string) error
+	SaveDevtronAppsResourceHistory(appId int, envId int, resourceIdentifier application2.ResourceIdentifier, force bool, userId int32, actionType string) error
 }
 
 type K8sResourceHistoryServiceImpl struct {
@@ -59,7 +60,7 @@ func (impl K8sResourceHistoryServiceImpl) SaveArgoCdAppsResourceDeleteHistory(qu
 		DeploymentAppType: GitOps,
 	}
 
-	err := impl.K8sResourceHistoryRepository.SaveK8sResourceHistory(&k8sResourceHistory)
+	err := impl.K8sResourceHistoryRepository.SaveK8sResourceHistory(&k8sResourceHistory, nil)
 
 	if err != nil {
 		return err
@@ -92,8 +93,34 @@ func (impl K8sResourceHistoryServiceImpl) SaveHelmAppsResourceHistory(appIdentif
 		DeploymentAppType: helm,
 	}
 
-	err = impl.K8sResourceHistoryRepository.SaveK8sResourceHistory(&k8sResourceHistory)
+	err = impl.K8sResourceHistoryRepository.SaveK8sResourceHistory(&k8sResourceHistory, nil)
 
 	return err
 
 }
+
+func (impl K8sResourceHistoryServiceImpl) SaveDevtronAppsResourceHistory(appId int, envId int, resourceIdentifier application2.ResourceIdentifier, force bool, userId int32, actionType string) error {
+	app, err := impl.appRepository.FindById(appId)
+	if err != nil {
+		impl.logger.Errorw("error in getting app for resource history", "appId", appId, "err", err)
+		return err
+	}
+	k8sResourceHistory := repository.K8sResourceHistory{
+		AppId:        appId,
+		AppName:      app.AppName,
+		EnvId:        envId,
+		Namespace:    resourceIdentifier.Namespace,
+		ResourceName: resourceIdentifier.Name,
+		Kind:         resourceIdentifier.GroupVersionKind.Kind,
+		Group:        resourceIdentifier.GroupVersionKind.Group,
+		ForceDelete:  force,
+		AuditLog: sql.AuditLog{
+			CreatedBy: userId,
+			CreatedOn: time.Now(),
+			UpdatedBy: userId,
+			UpdatedOn: time.Now(),
+		},
+		ActionType: actionType,
+	}
+	return impl.K8sResourceHistoryRepository.SaveK8sResourceHistory(&k8sResourceHistory, nil)
+}
@@ -23,7 +23,8 @@ type K8sResourceHistory struct {
 }
 
 type K8sResourceHistoryRepository interface {
-	SaveK8sResourceHistory(history *K8sResourceHistory) error
+	// SaveK8sResourceHistory writes on tx when it is set
+	SaveK8sResourceHistory(history *K8sResourceHistory, tx *pg.Tx) error
 }
 
 type K8sResourceHistoryRepositoryImpl struct {
@@ -38,6 +39,6 @@ func NewK8sResourceHistoryRepositoryImpl(dbConnection *pg.DB, logger *zap.Sugare
 	}
 }
 
-func (repo K8sResourceHistoryRepositoryImpl) SaveK8sResourceHistory(k8sResourceHistory *K8sResourceHistory) error {
-	return repo.dbConnection.Insert(k8sResourceHistory)
+func (repo K8sResourceHistoryRepositoryImpl) SaveK8sResourceHistory(k8sResourceHistory *K8sResourceHistory, tx *pg.Tx) error {
+	return sql.Connection(repo.dbConnection, tx).Insert(k8sResourceHistory)
 }
@@ -35,7 +35,6 @@ import (
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/devtron-labs/devtron/pkg/user"
 	repository3 "github.com/devtron-labs/devtron/pkg/user/repository"
-	util2 "github.com/devtron-labs/devtron/util"
 	"path"
 	"regexp"
 	"strconv"
@@ -794,16 +793,15 @@ func (impl CiCdPipelineOrchestratorImpl) CheckStringMatchRegex(regex string, val
 func (impl CiCdPipelineOrchestratorImpl) CreateApp(createRequest *bean.CreateAppDTO) (*bean.CreateAppDTO, error) {
 	// validate the labels key-value if propagate is true
 	for _, label := range createRequest.AppLabels {
-		if !label.Propagate {
-			continue
-		}
-		labelKey := label.Key
-		labelValue := label.Value
-		err := util2.CheckIfValidLabel(labelKey, labelValue)
-		if err != nil {
+		if err := app.ValidateAppLabel(label); err != nil {
 			return nil, err
 		}
 	}
+	labelWarning, err := impl.appLabelsService.ValidateNewAppLabels(createRequest.AppLabels)
+	if err != nil {
+		return nil, err
+	}
+	createRequest.LabelWarning = labelWarning
 
 	dbConnection := impl.appRepository.GetConnection()
 	tx, err := dbConnection.Begin()
@@ -824,6 +822,8 @@ func (impl CiCdPipelineOrchestratorImpl) CreateApp(createRequest *bean.CreateApp
 				Key:       label.Key,
 				Value:     label.Value,
 				Propagate: label.Propagate,
+				Source:    createRequest.LabelSource,
+				Type:      label.Type,
 				UserId:    createRequest.UserId,
 			}
 			_, err := impl.appLabelsService.Create(request, tx)
@@ -838,6 +838,7 @@ func (impl CiCdPipelineOrchestratorImpl) CreateApp(createRequest *bean.CreateApp
 		impl.logger.Errorw("error in commit repo", "error", err)
 		return nil, err
 	}
+	impl.appLabelsService.InvalidateAppMetaInfo(app.Id)
 	createRequest.Id = app.Id
 	return createRequest, nil
 }
@@ -904,6 +905,7 @@ func (impl CiCdPipelineOrchestratorImpl) DeleteApp(appId int, userId int32) erro
 	if err != nil {
 		return err
 	}
+	impl.appLabelsService.InvalidateAppMetaInfo(appId)
 	return nil
 }
 
@@ -1454,7 +1456,7 @@ func (impl CiCdPipelineOrchestratorImpl) createDockerRepoIfNeeded(dockerRegistry
 		return err
 	}
 	if dockerArtifactStore.RegistryType == dockerRegistryRepository.REGISTRYTYPE_ECR {
-		err := impl.CreateEcrRepo(dockerRepository, dockerArtifactStore.AWSRegion, dockerArtifactStore.AWSAccessKeyId, dockerArtifactStore.AWSSecretAccessKey)
+		err := impl.CreateEcrRepo(dockerRepository, dockerArtifactStore.AWSRegion, dockerArtifactStore.AWSAccessKeyId, string(dockerArtifactStore.AWSSecretAccessKey))
 		if err != nil {
 			impl.logger.Errorw("ecr repo creation failed while updating ci template", "err", err, "repo", dockerRepository)
 			return err
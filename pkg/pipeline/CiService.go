@@ -453,10 +453,10 @@ func (impl *CiServiceImpl) buildWfRequestForCiPipeline(pipeline *pipelineConfig.
 		DockerRepository:           dockerRepository,
 		CheckoutPath:               checkoutPath,
 		DockerUsername:             dockerRegistry.Username,
-		DockerPassword:             dockerRegistry.Password,
+		DockerPassword:             string(dockerRegistry.Password),
 		AwsRegion:                  dockerRegistry.AWSRegion,
 		AccessKey:                  dockerRegistry.AWSAccessKeyId,
-		SecretKey:                  dockerRegistry.AWSSecretAccessKey,
+		SecretKey:                  string(dockerRegistry.AWSSecretAccessKey),
 		DockerConnection:           dockerRegistry.Connection,
 		DockerCert:                 dockerRegistry.Cert,
 		CiCacheFileName:            pipeline.Name + "-" + strconv.Itoa(pipeline.Id) + ".tar.gz",
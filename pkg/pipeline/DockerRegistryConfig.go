@@ -99,10 +99,10 @@ func (impl DockerRegistryConfigImpl) Create(bean *DockerArtifactStoreBean) (*Doc
 		RegistryURL:        bean.RegistryURL,
 		RegistryType:       bean.RegistryType,
 		AWSAccessKeyId:     bean.AWSAccessKeyId,
-		AWSSecretAccessKey: bean.AWSSecretAccessKey,
+		AWSSecretAccessKey: sql.EncryptedString(bean.AWSSecretAccessKey),
 		AWSRegion:          bean.AWSRegion,
 		Username:           bean.Username,
-		Password:           bean.Password,
+		Password:           sql.EncryptedString(bean.Password),
 		IsDefault:          bean.IsDefault,
 		Connection:         bean.Connection,
 		Cert:               bean.Cert,
@@ -194,10 +194,10 @@ func (impl DockerRegistryConfigImpl) FetchAllDockerAccounts() ([]DockerArtifactS
 			RegistryURL:        store.RegistryURL,
 			RegistryType:       store.RegistryType,
 			AWSAccessKeyId:     store.AWSAccessKeyId,
-			AWSSecretAccessKey: store.AWSSecretAccessKey,
+			AWSSecretAccessKey: string(store.AWSSecretAccessKey),
 			AWSRegion:          store.AWSRegion,
 			Username:           store.Username,
-			Password:           store.Password,
+			Password:           string(store.Password),
 			IsDefault:          store.IsDefault,
 			Connection:         store.Connection,
 			Cert:               store.Cert,
@@ -234,10 +234,10 @@ func (impl DockerRegistryConfigImpl) FetchOneDockerAccount(storeId string) (*Doc
 		RegistryURL:        store.RegistryURL,
 		RegistryType:       store.RegistryType,
 		AWSAccessKeyId:     store.AWSAccessKeyId,
-		AWSSecretAccessKey: store.AWSSecretAccessKey,
+		AWSSecretAccessKey: string(store.AWSSecretAccessKey),
 		AWSRegion:          store.AWSRegion,
 		Username:           store.Username,
-		Password:           store.Password,
+		Password:           string(store.Password),
 		IsDefault:          store.IsDefault,
 		Connection:         store.Connection,
 		Cert:               store.Cert,
@@ -281,10 +281,10 @@ func (impl DockerRegistryConfigImpl) Update(bean *DockerArtifactStoreBean) (*Doc
 		RegistryURL:        bean.RegistryURL,
 		RegistryType:       bean.RegistryType,
 		AWSAccessKeyId:     bean.AWSAccessKeyId,
-		AWSSecretAccessKey: bean.AWSSecretAccessKey,
+		AWSSecretAccessKey: sql.EncryptedString(bean.AWSSecretAccessKey),
 		AWSRegion:          bean.AWSRegion,
 		Username:           bean.Username,
-		Password:           bean.Password,
+		Password:           sql.EncryptedString(bean.Password),
 		IsDefault:          bean.IsDefault,
 		Connection:         bean.Connection,
 		Cert:               bean.Cert,
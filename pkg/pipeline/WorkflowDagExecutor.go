@@ -767,12 +767,12 @@ func (impl *WorkflowDagExecutorImpl) buildWFRequest(runner *pipelineConfig.CdWor
 	if ciPipeline != nil && ciPipeline.Id > 0 {
 		extraEnvVariables["APP_NAME"] = ciPipeline.App.AppName
 		cdStageWorkflowRequest.DockerUsername = ciPipeline.CiTemplate.DockerRegistry.Username
-		cdStageWorkflowRequest.DockerPassword = ciPipeline.CiTemplate.DockerRegistry.Password
+		cdStageWorkflowRequest.DockerPassword = string(ciPipeline.CiTemplate.DockerRegistry.Password)
 		cdStageWorkflowRequest.AwsRegion = ciPipeline.CiTemplate.DockerRegistry.AWSRegion
 		cdStageWorkflowRequest.DockerConnection = ciPipeline.CiTemplate.DockerRegistry.Connection
 		cdStageWorkflowRequest.DockerCert = ciPipeline.CiTemplate.DockerRegistry.Cert
 		cdStageWorkflowRequest.AccessKey = ciPipeline.CiTemplate.DockerRegistry.AWSAccessKeyId
-		cdStageWorkflowRequest.SecretKey = ciPipeline.CiTemplate.DockerRegistry.AWSSecretAccessKey
+		cdStageWorkflowRequest.SecretKey = string(ciPipeline.CiTemplate.DockerRegistry.AWSSecretAccessKey)
 		cdStageWorkflowRequest.DockerRegistryType = string(ciPipeline.CiTemplate.DockerRegistry.RegistryType)
 		cdStageWorkflowRequest.DockerRegistryURL = ciPipeline.CiTemplate.DockerRegistry.RegistryURL
 	} else if cdPipeline.AppId > 0 {
@@ -782,12 +782,12 @@ func (impl *WorkflowDagExecutorImpl) buildWFRequest(runner *pipelineConfig.CdWor
 		}
 		extraEnvVariables["APP_NAME"] = ciTemplate.App.AppName
 		cdStageWorkflowRequest.DockerUsername = ciTemplate.DockerRegistry.Username
-		cdStageWorkflowRequest.DockerPassword = ciTemplate.DockerRegistry.Password
+		cdStageWorkflowRequest.DockerPassword = string(ciTemplate.DockerRegistry.Password)
 		cdStageWorkflowRequest.AwsRegion = ciTemplate.DockerRegistry.AWSRegion
 		cdStageWorkflowRequest.DockerConnection = ciTemplate.DockerRegistry.Connection
 		cdStageWorkflowRequest.DockerCert = ciTemplate.DockerRegistry.Cert
 		cdStageWorkflowRequest.AccessKey = ciTemplate.DockerRegistry.AWSAccessKeyId
-		cdStageWorkflowRequest.SecretKey = ciTemplate.DockerRegistry.AWSSecretAccessKey
+		cdStageWorkflowRequest.SecretKey = string(ciTemplate.DockerRegistry.AWSSecretAccessKey)
 		cdStageWorkflowRequest.DockerRegistryType = string(ciTemplate.DockerRegistry.RegistryType)
 		cdStageWorkflowRequest.DockerRegistryURL = ciTemplate.DockerRegistry.RegistryURL
 		appLabels, err := impl.appLabelRepository.FindAllByAppId(cdPipeline.AppId)
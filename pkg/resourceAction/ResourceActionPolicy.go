@@ -0,0 +1,118 @@
+package resourceAction
+
+import (
+	"fmt"
+
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/pkg/user/repository"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// kubernetes verbs of the actions on the resources of an app, restarts patch the pod template and scaling updates the
+// scale subresource
+const (
+	VerbGet    = "get"
+	VerbCreate = "create"
+	VerbPatch  = "patch"
+	VerbUpdate = "update"
+	VerbDelete = "delete"
+)
+
+// ResourceActionRule allows a verb on a kind to MinRole and every role above it
+type ResourceActionRule struct {
+	Group   string              `json:"group"`
+	Kind    string              `json:"kind"`
+	Verb    string              `json:"verb"`
+	MinRole repository.RoleType `json:"minRole"`
+}
+
+// resourceActionRules is the single mapping from devtron roles to the actions on the kubernetes resources of an app.
+// A role is held on an environment, so it only allows the actions in the namespace of that environment. Kinds and
+// verbs missing here are denied to everyone but super admins
+var resourceActionRules = []ResourceActionRule{
+	{Kind: kube.PodKind, Verb: VerbGet, MinRole: repository.VIEW_TYPE},
+	{Kind: kube.PodKind, Verb: VerbDelete, MinRole: repository.ADMIN_TYPE},
+	{Group: util.AppsGroup, Kind: kube.DeploymentKind, Verb: VerbGet, MinRole: repository.VIEW_TYPE},
+	{Group: util.AppsGroup, Kind: kube.DeploymentKind, Verb: VerbPatch, MinRole: repository.TRIGGER_TYPE},
+	{Group: util.AppsGroup, Kind: kube.DeploymentKind, Verb: VerbUpdate, MinRole: repository.TRIGGER_TYPE},
+	{Group: util.AppsGroup, Kind: kube.StatefulSetKind, Verb: VerbGet, MinRole: repository.VIEW_TYPE},
+	{Group: util.AppsGroup, Kind: kube.StatefulSetKind, Verb: VerbPatch, MinRole: repository.TRIGGER_TYPE},
+	{Group: util.AppsGroup, Kind: kube.StatefulSetKind, Verb: VerbUpdate, MinRole: repository.TRIGGER_TYPE},
+	{Group: util.AppsGroup, Kind: kube.DaemonSetKind, Verb: VerbGet, MinRole: repository.VIEW_TYPE},
+	{Group: util.AppsGroup, Kind: kube.DaemonSetKind, Verb: VerbPatch, MinRole: repository.TRIGGER_TYPE},
+	{Group: util.AppsGroup, Kind: kube.ReplicaSetKind, Verb: VerbGet, MinRole: repository.VIEW_TYPE},
+	{Group: util.BatchGroup, Kind: util.K8sClusterResourceCronJobKind, Verb: VerbGet, MinRole: repository.VIEW_TYPE},
+	{Group: util.BatchGroup, Kind: util.K8sClusterResourceCronJobKind, Verb: VerbPatch, MinRole: repository.TRIGGER_TYPE},
+	{Group: util.BatchGroup, Kind: kube.JobKind, Verb: VerbGet, MinRole: repository.VIEW_TYPE},
+	{Group: util.BatchGroup, Kind: kube.JobKind, Verb: VerbDelete, MinRole: repository.ADMIN_TYPE},
+	{Group: util.K8sClusterResourceRolloutGroup, Kind: util.K8sClusterResourceRolloutKind, Verb: VerbGet, MinRole: repository.VIEW_TYPE},
+	{Group: util.K8sClusterResourceRolloutGroup, Kind: util.K8sClusterResourceRolloutKind, Verb: VerbPatch, MinRole: repository.TRIGGER_TYPE},
+	{Group: util.VolumeSnapshotGVR.Group, Kind: util.VolumeSnapshotKind, Verb: VerbGet, MinRole: repository.VIEW_TYPE},
+	{Group: util.VolumeSnapshotGVR.Group, Kind: util.VolumeSnapshotKind, Verb: VerbCreate, MinRole: repository.TRIGGER_TYPE},
+}
+
+// roleRanks orders the roles, managers hold the same actions on resources as admins
+var roleRanks = map[repository.RoleType]int{
+	repository.VIEW_TYPE:    1,
+	repository.TRIGGER_TYPE: 2,
+	repository.ADMIN_TYPE:   3,
+	repository.MANAGER_TYPE: 3,
+}
+
+// roleCasbinActions are the casbin actions telling the roles of a user on an app environment apart, from the highest
+// role down
+var roleCasbinActions = []struct {
+	role   repository.RoleType
+	action string
+}{
+	{role: repository.ADMIN_TYPE, action: casbin.ActionDelete},
+	{role: repository.TRIGGER_TYPE, action: casbin.ActionTrigger},
+	{role: repository.VIEW_TYPE, action: casbin.ActionGet},
+}
+
+// IsResourceActionAllowed tells whether role allows verb on the kind of gvk, the version is not considered
+func IsResourceActionAllowed(role repository.RoleType, gvk schema.GroupVersionKind, verb string) bool {
+	rank, ok := roleRanks[role]
+	if !ok {
+		return false
+	}
+	for _, rule := range resourceActionRules {
+		if rule.Group == gvk.Group && rule.Kind == gvk.Kind && rule.Verb == verb {
+			return roleRanks[rule.MinRole] <= rank
+		}
+	}
+	return false
+}
+
+// ResourceActionsOfRole lists the rules the role holds
+func ResourceActionsOfRole(role repository.RoleType) []ResourceActionRule {
+	rules := make([]ResourceActionRule, 0)
+	rank, ok := roleRanks[role]
+	if !ok {
+		return rules
+	}
+	for _, rule := range resourceActionRules {
+		if roleRanks[rule.MinRole] <= rank {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// ErrResourceActionDenied is returned when no role of the user on the environments of the namespace allows the action,
+// Role is empty when the user has no role there at all
+type ErrResourceActionDenied struct {
+	Role      repository.RoleType
+	Namespace string
+	Kind      string
+	Verb      string
+}
+
+func (e *ErrResourceActionDenied) Error() string {
+	if len(e.Role) == 0 {
+		return fmt.Sprintf("no role on the environments of namespace %s allows %s on %s", e.Namespace, e.Verb, e.Kind)
+	}
+	return fmt.Sprintf("role %s does not allow %s on %s in namespace %s", e.Role, e.Verb, e.Kind, e.Namespace)
+}
@@ -0,0 +1,140 @@
+package resourceAction
+
+import (
+	"strings"
+
+	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	repository2 "github.com/devtron-labs/devtron/pkg/user/repository"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourceActionUser is the user acting on the kubernetes resources of the app
+type ResourceActionUser struct {
+	UserId int32
+	AppId  int
+}
+
+// ResourceActionMatrix is the role of a user on an app environment and the actions on resources it allows, super
+// admins are allowed every action including those on kinds which are not declared
+type ResourceActionMatrix struct {
+	UserId     int32                `json:"userId"`
+	AppId      int                  `json:"appId"`
+	EnvId      int                  `json:"envId"`
+	ClusterId  int                  `json:"clusterId"`
+	Namespace  string               `json:"namespace"`
+	SuperAdmin bool                 `json:"superAdmin"`
+	Role       repository2.RoleType `json:"role,omitempty"`
+	Actions    []ResourceActionRule `json:"actions"`
+}
+
+type ResourceActionService interface {
+	// CheckResourceAction returns ErrResourceActionDenied unless a role of the user on the app in an environment of the
+	// namespace allows verb on the kind of gvk
+	CheckResourceAction(user *ResourceActionUser, clusterId int, namespace string, gvk schema.GroupVersionKind, verb string) error
+	GetEffectiveMatrix(userId int32, appId int, envId int) (*ResourceActionMatrix, error)
+}
+
+type ResourceActionServiceImpl struct {
+	logger                *zap.SugaredLogger
+	enforcer              casbin.Enforcer
+	enforcerUtil          rbac.EnforcerUtil
+	userService           user.UserService
+	environmentRepository repository.EnvironmentRepository
+}
+
+func NewResourceActionServiceImpl(logger *zap.SugaredLogger, enforcer casbin.Enforcer, enforcerUtil rbac.EnforcerUtil,
+	userService user.UserService, environmentRepository repository.EnvironmentRepository) *ResourceActionServiceImpl {
+	return &ResourceActionServiceImpl{
+		logger:                logger,
+		enforcer:              enforcer,
+		enforcerUtil:          enforcerUtil,
+		userService:           userService,
+		environmentRepository: environmentRepository,
+	}
+}
+
+func (impl *ResourceActionServiceImpl) CheckResourceAction(user *ResourceActionUser, clusterId int, namespace string, gvk schema.GroupVersionKind, verb string) error {
+	isSuperAdmin, err := impl.userService.IsSuperAdmin(int(user.UserId))
+	if err != nil {
+		impl.logger.Errorw("error in checking super admin", "err", err, "userId", user.UserId)
+		return err
+	}
+	if isSuperAdmin {
+		return nil
+	}
+	denied := &ErrResourceActionDenied{Namespace: namespace, Kind: gvk.Kind, Verb: verb}
+	environments, err := impl.environmentRepository.FindByClusterIdAndNamespace([]*repository.ClusterNamespacePair{{ClusterId: clusterId, NamespaceName: namespace}})
+	if err != nil {
+		impl.logger.Errorw("error in fetching environments of namespace", "err", err, "clusterId", clusterId, "namespace", namespace)
+		return err
+	}
+	if len(environments) == 0 {
+		return denied
+	}
+	emailId, err := impl.getEmailId(user.UserId)
+	if err != nil {
+		return err
+	}
+	for _, environment := range environments {
+		role := impl.findRole(emailId, user.AppId, environment.Id)
+		if roleRanks[role] > roleRanks[denied.Role] {
+			denied.Role = role
+		}
+		if IsResourceActionAllowed(role, gvk, verb) {
+			return nil
+		}
+	}
+	return denied
+}
+
+func (impl *ResourceActionServiceImpl) GetEffectiveMatrix(userId int32, appId int, envId int) (*ResourceActionMatrix, error) {
+	environment, err := impl.environmentRepository.FindById(envId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching environment", "err", err, "envId", envId)
+		return nil, err
+	}
+	matrix := &ResourceActionMatrix{UserId: userId, AppId: appId, EnvId: envId, ClusterId: environment.ClusterId, Namespace: environment.Namespace}
+	matrix.SuperAdmin, err = impl.userService.IsSuperAdmin(int(userId))
+	if err != nil {
+		impl.logger.Errorw("error in checking super admin", "err", err, "userId", userId)
+		return nil, err
+	}
+	if matrix.SuperAdmin {
+		matrix.Actions = append([]ResourceActionRule{}, resourceActionRules...)
+		return matrix, nil
+	}
+	emailId, err := impl.getEmailId(userId)
+	if err != nil {
+		return nil, err
+	}
+	matrix.Role = impl.findRole(emailId, appId, envId)
+	matrix.Actions = ResourceActionsOfRole(matrix.Role)
+	return matrix, nil
+}
+
+func (impl *ResourceActionServiceImpl) getEmailId(userId int32) (string, error) {
+	userInfo, err := impl.userService.GetById(userId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching user", "err", err, "userId", userId)
+		return "", err
+	}
+	return userInfo.EmailId, nil
+}
+
+// findRole is the highest role of the user on the app in the environment, empty when the user cannot even view it
+func (impl *ResourceActionServiceImpl) findRole(emailId string, appId int, envId int) repository2.RoleType {
+	appObject := impl.enforcerUtil.GetAppRBACNameByAppId(appId)
+	envObject := impl.enforcerUtil.GetEnvRBACNameByAppId(appId, envId)
+	emailId = strings.ToLower(emailId)
+	for _, roleAction := range roleCasbinActions {
+		if impl.enforcer.EnforceByEmail(emailId, casbin.ResourceApplications, roleAction.action, appObject) &&
+			impl.enforcer.EnforceByEmail(emailId, casbin.ResourceEnvironment, roleAction.action, envObject) {
+			return roleAction.role
+		}
+	}
+	return ""
+}
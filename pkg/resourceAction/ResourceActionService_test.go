@@ -0,0 +1,167 @@
+package resourceAction
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	repository2 "github.com/devtron-labs/devtron/pkg/user/repository"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type fakeUserService struct {
+	user.UserService
+	superAdmins map[int32]bool
+}
+
+func (s *fakeUserService) IsSuperAdmin(userId int) (bool, error) {
+	return s.superAdmins[int32(userId)], nil
+}
+
+func (s *fakeUserService) GetById(id int32) (*bean.UserInfo, error) {
+	return &bean.UserInfo{Id: id, EmailId: fmt.Sprintf("User%d@example.com", id)}, nil
+}
+
+// fakeEnforcer grants casbin actions by email and rbac object
+type fakeEnforcer struct {
+	casbin.Enforcer
+	grants map[string]bool
+}
+
+func (e *fakeEnforcer) EnforceByEmail(emailId string, resource string, action string, resourceItem string) bool {
+	return e.grants[emailId+"|"+resource+"|"+action+"|"+resourceItem]
+}
+
+func (e *fakeEnforcer) grant(emailId string, appObject string, envObject string, actions ...string) {
+	for _, action := range actions {
+		e.grants[emailId+"|"+casbin.ResourceApplications+"|"+action+"|"+appObject] = true
+		e.grants[emailId+"|"+casbin.ResourceEnvironment+"|"+action+"|"+envObject] = true
+	}
+}
+
+type fakeEnforcerUtil struct {
+	rbac.EnforcerUtil
+}
+
+func (u *fakeEnforcerUtil) GetAppRBACNameByAppId(appId int) string {
+	return fmt.Sprintf("team/app-%d", appId)
+}
+
+func (u *fakeEnforcerUtil) GetEnvRBACNameByAppId(appId int, envId int) string {
+	return fmt.Sprintf("env-%d/app-%d", envId, appId)
+}
+
+type fakeEnvironmentRepository struct {
+	repository.EnvironmentRepository
+	environments []*repository.Environment
+}
+
+func (r *fakeEnvironmentRepository) FindByClusterIdAndNamespace(pairs []*repository.ClusterNamespacePair) ([]*repository.Environment, error) {
+	var environments []*repository.Environment
+	for _, environment := range r.environments {
+		for _, pair := range pairs {
+			if environment.ClusterId == pair.ClusterId && environment.Namespace == pair.NamespaceName {
+				environments = append(environments, environment)
+			}
+		}
+	}
+	return environments, nil
+}
+
+func (r *fakeEnvironmentRepository) FindById(id int) (*repository.Environment, error) {
+	for _, environment := range r.environments {
+		if environment.Id == id {
+			return environment, nil
+		}
+	}
+	return nil, fmt.Errorf("environment %d not found", id)
+}
+
+func newTestResourceActionService() (*ResourceActionServiceImpl, *fakeEnforcer) {
+	enforcer := &fakeEnforcer{grants: make(map[string]bool)}
+	return NewResourceActionServiceImpl(zap.NewNop().Sugar(), enforcer, &fakeEnforcerUtil{},
+		&fakeUserService{superAdmins: map[int32]bool{1: true}},
+		&fakeEnvironmentRepository{environments: []*repository.Environment{
+			{Id: 5, ClusterId: 2, Namespace: "payments-prod"},
+			{Id: 6, ClusterId: 2, Namespace: "payments-qa"},
+		}}), enforcer
+}
+
+var podGVK = schema.GroupVersionKind{Version: util.V1VERSION, Kind: kube.PodKind}
+var deploymentGVK = schema.GroupVersionKind{Group: util.AppsGroup, Version: util.V1VERSION, Kind: kube.DeploymentKind}
+
+func TestCheckResourceAction_ByRole(t *testing.T) {
+	impl, enforcer := newTestResourceActionService()
+	// user 2 triggers in prod and is admin in qa
+	enforcer.grant("user2@example.com", "team/app-7", "env-5/app-7", casbin.ActionGet, casbin.ActionTrigger)
+	enforcer.grant("user2@example.com", "team/app-7", "env-6/app-7", casbin.ActionGet, casbin.ActionTrigger, casbin.ActionDelete)
+	user := &ResourceActionUser{UserId: 2, AppId: 7}
+
+	assert.Nil(t, impl.CheckResourceAction(user, 2, "payments-prod", deploymentGVK, VerbPatch))
+	assert.Nil(t, impl.CheckResourceAction(user, 2, "payments-qa", podGVK, VerbDelete))
+
+	err := impl.CheckResourceAction(user, 2, "payments-prod", podGVK, VerbDelete)
+	denied, ok := err.(*ErrResourceActionDenied)
+	assert.True(t, ok)
+	assert.Equal(t, repository2.TRIGGER_TYPE, denied.Role)
+
+	// roles on the app do not reach namespaces which are not an environment
+	_, ok = impl.CheckResourceAction(user, 2, "kube-system", deploymentGVK, VerbGet).(*ErrResourceActionDenied)
+	assert.True(t, ok)
+	_, ok = impl.CheckResourceAction(user, 3, "payments-prod", deploymentGVK, VerbGet).(*ErrResourceActionDenied)
+	assert.True(t, ok)
+}
+
+func TestCheckResourceAction_DeniesUnknownKinds(t *testing.T) {
+	impl, enforcer := newTestResourceActionService()
+	enforcer.grant("user2@example.com", "team/app-7", "env-5/app-7", casbin.ActionGet, casbin.ActionTrigger, casbin.ActionDelete)
+	user := &ResourceActionUser{UserId: 2, AppId: 7}
+
+	for _, gvk := range []schema.GroupVersionKind{
+		{Version: util.V1VERSION, Kind: "Secret"},
+		{Group: "example.com", Version: "v1", Kind: kube.DeploymentKind},
+	} {
+		_, ok := impl.CheckResourceAction(user, 2, "payments-prod", gvk, VerbGet).(*ErrResourceActionDenied)
+		assert.True(t, ok, "%s is allowed", gvk)
+	}
+	_, ok := impl.CheckResourceAction(user, 2, "payments-prod", deploymentGVK, "escalate").(*ErrResourceActionDenied)
+	assert.True(t, ok)
+}
+
+func TestCheckResourceAction_SuperAdminBypass(t *testing.T) {
+	impl, _ := newTestResourceActionService()
+	superAdmin := &ResourceActionUser{UserId: 1, AppId: 7}
+	assert.Nil(t, impl.CheckResourceAction(superAdmin, 2, "payments-prod", podGVK, VerbDelete))
+	assert.Nil(t, impl.CheckResourceAction(superAdmin, 2, "kube-system", schema.GroupVersionKind{Version: util.V1VERSION, Kind: "Secret"}, VerbDelete))
+
+	matrix, err := impl.GetEffectiveMatrix(1, 7, 5)
+	assert.Nil(t, err)
+	assert.True(t, matrix.SuperAdmin)
+	assert.Len(t, matrix.Actions, len(resourceActionRules))
+}
+
+func TestGetEffectiveMatrix(t *testing.T) {
+	impl, enforcer := newTestResourceActionService()
+	enforcer.grant("user2@example.com", "team/app-7", "env-5/app-7", casbin.ActionGet)
+
+	matrix, err := impl.GetEffectiveMatrix(2, 7, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, repository2.VIEW_TYPE, matrix.Role)
+	assert.Equal(t, "payments-prod", matrix.Namespace)
+	for _, action := range matrix.Actions {
+		assert.Equal(t, VerbGet, action.Verb)
+	}
+
+	matrix, err = impl.GetEffectiveMatrix(3, 7, 5)
+	assert.Nil(t, err)
+	assert.Empty(t, matrix.Role)
+	assert.Empty(t, matrix.Actions)
+}
@@ -0,0 +1,79 @@
+package shutdown
+
+import (
+	"context"
+	"github.com/caarlos0/env"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/clusterTerminalAccess"
+	"go.uber.org/zap"
+	"sync/atomic"
+	"time"
+)
+
+type GracefulShutdownConfig struct {
+	DrainPeriodInSecs       int `env:"SHUTDOWN_DRAIN_PERIOD_IN_SECS" envDefault:"30"`
+	TerminalCountdownInSecs int `env:"SHUTDOWN_TERMINAL_COUNTDOWN_IN_SECS" envDefault:"10"`
+}
+
+type GracefulShutdownService interface {
+	// IsDraining is true once shutdown has started, health checks report it so that no new traffic is routed here
+	IsDraining() bool
+	// Shutdown drains terminal sessions, stops the server and waits for in-flight k8s operations, all within the
+	// configured drain period
+	Shutdown(stopServer func(ctx context.Context) error)
+}
+
+type GracefulShutdownServiceImpl struct {
+	logger                    *zap.SugaredLogger
+	config                    *GracefulShutdownConfig
+	userTerminalAccessService clusterTerminalAccess.UserTerminalAccessService
+	k8sUtil                   *util.K8sUtil
+	draining                  int32
+}
+
+func GetGracefulShutdownConfig() (*GracefulShutdownConfig, error) {
+	config := &GracefulShutdownConfig{}
+	err := env.Parse(config)
+	return config, err
+}
+
+func NewGracefulShutdownServiceImpl(logger *zap.SugaredLogger, userTerminalAccessService clusterTerminalAccess.UserTerminalAccessService,
+	k8sUtil *util.K8sUtil) (*GracefulShutdownServiceImpl, error) {
+	config, err := GetGracefulShutdownConfig()
+	if err != nil {
+		logger.Errorw("error in parsing graceful shutdown config", "err", err)
+		return nil, err
+	}
+	return &GracefulShutdownServiceImpl{
+		logger:                    logger,
+		config:                    config,
+		userTerminalAccessService: userTerminalAccessService,
+		k8sUtil:                   k8sUtil,
+	}, nil
+}
+
+func (impl *GracefulShutdownServiceImpl) IsDraining() bool {
+	return atomic.LoadInt32(&impl.draining) == 1
+}
+
+func (impl *GracefulShutdownServiceImpl) Shutdown(stopServer func(ctx context.Context) error) {
+	if !atomic.CompareAndSwapInt32(&impl.draining, 0, 1) {
+		return
+	}
+	drainPeriod := time.Duration(impl.config.DrainPeriodInSecs) * time.Second
+	impl.logger.Infow("draining orchestrator", "drainPeriod", drainPeriod)
+	ctx, cancel := context.WithTimeout(context.Background(), drainPeriod)
+	defer cancel()
+
+	// the server keeps serving during the countdown so that reconnecting terminals land on healthy instances
+	impl.userTerminalAccessService.Drain(ctx, time.Duration(impl.config.TerminalCountdownInSecs)*time.Second)
+
+	if err := stopServer(ctx); err != nil {
+		impl.logger.Errorw("error in stopping server", "err", err)
+	}
+	if err := impl.k8sUtil.WaitForInflightMutations(ctx); err != nil {
+		impl.logger.Warnw("drain period elapsed with k8s operations in progress", "count", impl.k8sUtil.InflightMutationCount())
+		return
+	}
+	impl.logger.Infow("orchestrator drained")
+}
@@ -0,0 +1,52 @@
+package shutdown
+
+import (
+	"context"
+	"github.com/devtron-labs/authenticator/client"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/clusterTerminalAccess"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type terminalAccessServiceStub struct {
+	clusterTerminalAccess.UserTerminalAccessService
+	onDrain func(ctx context.Context, countdown time.Duration)
+}
+
+func (s *terminalAccessServiceStub) Drain(ctx context.Context, countdown time.Duration) {
+	s.onDrain(ctx, countdown)
+}
+
+func TestGracefulShutdownService_Shutdown(t *testing.T) {
+	logger, err := util.NewSugardLogger()
+	assert.Nil(t, err)
+	var steps []string
+	shutdownService := &GracefulShutdownServiceImpl{
+		logger:  logger,
+		config:  &GracefulShutdownConfig{DrainPeriodInSecs: 5, TerminalCountdownInSecs: 2},
+		k8sUtil: util.NewK8sUtil(logger, &client.RuntimeConfig{}),
+	}
+	shutdownService.userTerminalAccessService = &terminalAccessServiceStub{
+		onDrain: func(ctx context.Context, countdown time.Duration) {
+			assert.True(t, shutdownService.IsDraining(), "health should report draining before terminals are notified")
+			assert.Equal(t, 2*time.Second, countdown)
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			steps = append(steps, "drain-terminals")
+		},
+	}
+	assert.False(t, shutdownService.IsDraining())
+	stopServer := func(ctx context.Context) error {
+		steps = append(steps, "stop-server")
+		return nil
+	}
+	shutdownService.Shutdown(stopServer)
+	assert.True(t, shutdownService.IsDraining())
+	assert.Equal(t, []string{"drain-terminals", "stop-server"}, steps)
+
+	// a second signal does not repeat the sequence
+	shutdownService.Shutdown(stopServer)
+	assert.Equal(t, []string{"drain-terminals", "stop-server"}, steps)
+}
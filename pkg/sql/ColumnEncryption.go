@@ -0,0 +1,295 @@
+package sql
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/caarlos0/env"
+)
+
+// encrypted values are stored as enc:v1:<key id>:<base64 of nonce and sealed value>, anything else is legacy plaintext
+const (
+	encryptedValuePrefix = "enc:v1:"
+	columnKeySize        = 32
+)
+
+// SensitiveClusterConfigKeys are the entries of the cluster config map which are stored encrypted
+var SensitiveClusterConfigKeys = []string{"bearer_token"}
+
+var ErrColumnEncryptionNotInitialised = errors.New("column encryption is not initialised")
+
+type ColumnEncryptionConfig struct {
+	// Keys is a comma separated list of <key id>:<base64 of a 32 byte key>
+	Keys string `env:"COLUMN_ENCRYPTION_KEYS" envDefault:"" secretData:"-"`
+	// KeysDir holds mounted keys, one file per key named by the key id and holding the base64 key
+	KeysDir string `env:"COLUMN_ENCRYPTION_KEYS_DIR" envDefault:"/etc/devtron/column-encryption"`
+	// ActiveKeyId encrypts new values, the other keys are only used to read values written before a rotation
+	ActiveKeyId string `env:"COLUMN_ENCRYPTION_ACTIVE_KEY_ID" envDefault:""`
+}
+
+type columnCipher struct {
+	activeKeyId string
+	aeads       map[string]cipher.AEAD
+}
+
+var (
+	columnCipherLock sync.RWMutex
+	activeCipher     *columnCipher
+)
+
+// InitColumnEncryption loads the keys used by EncryptedString and EncryptedClusterConfig, it fails when no key is
+// configured so that sensitive columns are never written in plaintext. Errors never contain key material
+func InitColumnEncryption() error {
+	cfg := &ColumnEncryptionConfig{}
+	if err := env.Parse(cfg); err != nil {
+		return err
+	}
+	keys, err := loadColumnKeys(cfg)
+	if err != nil {
+		return err
+	}
+	c, err := newColumnCipher(cfg.ActiveKeyId, keys)
+	if err != nil {
+		return err
+	}
+	columnCipherLock.Lock()
+	activeCipher = c
+	columnCipherLock.Unlock()
+	return nil
+}
+
+func loadColumnKeys(cfg *ColumnEncryptionConfig) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, entry := range strings.Split(cfg.Keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		keyId, key, found := strings.Cut(entry, ":")
+		if !found || keyId == "" {
+			return nil, errors.New("invalid COLUMN_ENCRYPTION_KEYS, expected comma separated <key id>:<base64 key> entries")
+		}
+		keys[keyId] = key
+	}
+	files, err := ioutil.ReadDir(cfg.KeysDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error in reading column encryption keys dir %s: %v", cfg.KeysDir, err)
+	}
+	for _, file := range files {
+		// secret volumes hold ..data links next to the keys
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(cfg.KeysDir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error in reading column encryption key %s: %v", file.Name(), err)
+		}
+		keys[file.Name()] = strings.TrimSpace(string(content))
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("column encryption key missing, set COLUMN_ENCRYPTION_KEYS or mount keys at %s", cfg.KeysDir)
+	}
+	return keys, nil
+}
+
+func newColumnCipher(activeKeyId string, keys map[string]string) (*columnCipher, error) {
+	if activeKeyId == "" {
+		if len(keys) != 1 {
+			return nil, errors.New("COLUMN_ENCRYPTION_ACTIVE_KEY_ID is required when more than one column encryption key is configured")
+		}
+		for keyId := range keys {
+			activeKeyId = keyId
+		}
+	}
+	if _, ok := keys[activeKeyId]; !ok {
+		return nil, fmt.Errorf("column encryption key %q set as active is not configured", activeKeyId)
+	}
+	c := &columnCipher{activeKeyId: activeKeyId, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for keyId, encodedKey := range keys {
+		if strings.Contains(keyId, ":") {
+			return nil, fmt.Errorf("column encryption key id %q must not contain ':'", keyId)
+		}
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil || len(key) != columnKeySize {
+			return nil, fmt.Errorf("column encryption key %q must be %d bytes encoded in base64", keyId, columnKeySize)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid column encryption key %q", keyId)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid column encryption key %q", keyId)
+		}
+		c.aeads[keyId] = aead
+	}
+	return c, nil
+}
+
+func getColumnCipher() (*columnCipher, error) {
+	columnCipherLock.RLock()
+	defer columnCipherLock.RUnlock()
+	if activeCipher == nil {
+		return nil, ErrColumnEncryptionNotInitialised
+	}
+	return activeCipher, nil
+}
+
+func (impl *columnCipher) encrypt(plaintext string) (string, error) {
+	aead := impl.aeads[impl.activeKeyId]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(impl.activeKeyId))
+	return encryptedValuePrefix + impl.activeKeyId + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (impl *columnCipher) decrypt(value string) (string, error) {
+	keyId, encoded, found := strings.Cut(strings.TrimPrefix(value, encryptedValuePrefix), ":")
+	if !found {
+		return "", errors.New("malformed encrypted column value")
+	}
+	aead, ok := impl.aeads[keyId]
+	if !ok {
+		return "", fmt.Errorf("column encryption key %q of the value is not configured", keyId)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted column value")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(keyId))
+	if err != nil {
+		return "", fmt.Errorf("unable to decrypt column value with key %q", keyId)
+	}
+	return string(plaintext), nil
+}
+
+// EncryptColumnValue encrypts with the active key, empty values are kept empty
+func EncryptColumnValue(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	c, err := getColumnCipher()
+	if err != nil {
+		return "", err
+	}
+	return c.encrypt(plaintext)
+}
+
+// DecryptColumnValue returns legacy plaintext values as they are so that rows not yet migrated keep working
+func DecryptColumnValue(value string) (string, error) {
+	if !IsEncryptedColumnValue(value) {
+		return value, nil
+	}
+	c, err := getColumnCipher()
+	if err != nil {
+		return "", err
+	}
+	return c.decrypt(value)
+}
+
+func IsEncryptedColumnValue(value string) bool {
+	return strings.HasPrefix(value, encryptedValuePrefix)
+}
+
+// EncryptedString is a text column holding a secret, it is encrypted on write and decrypted on read
+type EncryptedString string
+
+func (s EncryptedString) Value() (driver.Value, error) {
+	return EncryptColumnValue(string(s))
+}
+
+func (s *EncryptedString) Scan(src interface{}) error {
+	value, err := scanColumnString(src)
+	if err != nil {
+		return err
+	}
+	plaintext, err := DecryptColumnValue(value)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+func (s EncryptedString) String() string {
+	return string(s)
+}
+
+// EncryptedClusterConfig is the json config of a cluster with the SensitiveClusterConfigKeys entries encrypted
+type EncryptedClusterConfig map[string]string
+
+func (c EncryptedClusterConfig) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	config := make(map[string]string, len(c))
+	for key, value := range c {
+		config[key] = value
+	}
+	for _, key := range SensitiveClusterConfigKeys {
+		if value, ok := config[key]; ok {
+			encrypted, err := EncryptColumnValue(value)
+			if err != nil {
+				return nil, err
+			}
+			config[key] = encrypted
+		}
+	}
+	// a string and not []byte, go-pg would write []byte as bytea
+	configJson, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	return string(configJson), nil
+}
+
+func (c *EncryptedClusterConfig) Scan(src interface{}) error {
+	value, err := scanColumnString(src)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		*c = nil
+		return nil
+	}
+	config := make(map[string]string)
+	if err = json.Unmarshal([]byte(value), &config); err != nil {
+		return err
+	}
+	for _, key := range SensitiveClusterConfigKeys {
+		if encrypted, ok := config[key]; ok {
+			if config[key], err = DecryptColumnValue(encrypted); err != nil {
+				return err
+			}
+		}
+	}
+	*c = config
+	return nil
+}
+
+func scanColumnString(src interface{}) (string, error) {
+	switch value := src.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case []byte:
+		return string(value), nil
+	default:
+		return "", fmt.Errorf("unsupported type %T for encrypted column", src)
+	}
+}
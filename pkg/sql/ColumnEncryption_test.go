@@ -0,0 +1,106 @@
+package sql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testColumnKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), columnKeySize)))
+}
+
+func setTestColumnCipher(t *testing.T, activeKeyId string, keys map[string]string) {
+	c, err := newColumnCipher(activeKeyId, keys)
+	assert.NoError(t, err)
+	columnCipherLock.Lock()
+	activeCipher = c
+	columnCipherLock.Unlock()
+	t.Cleanup(func() {
+		columnCipherLock.Lock()
+		activeCipher = nil
+		columnCipherLock.Unlock()
+	})
+}
+
+func TestColumnEncryption(t *testing.T) {
+	setTestColumnCipher(t, "k1", map[string]string{"k1": testColumnKey('a')})
+
+	encrypted, err := EncryptedString("token").Value()
+	assert.NoError(t, err)
+	assert.True(t, IsEncryptedColumnValue(encrypted.(string)))
+	assert.True(t, strings.HasPrefix(encrypted.(string), "enc:v1:k1:"))
+	assert.NotContains(t, encrypted, "token")
+
+	var s EncryptedString
+	assert.NoError(t, s.Scan([]byte(encrypted.(string))))
+	assert.Equal(t, EncryptedString("token"), s)
+
+	// rows written before encryption was enabled are read as they are
+	assert.NoError(t, s.Scan("legacy-token"))
+	assert.Equal(t, EncryptedString("legacy-token"), s)
+
+	empty, err := EncryptedString("").Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "", empty)
+}
+
+func TestColumnEncryptionKeyRotation(t *testing.T) {
+	setTestColumnCipher(t, "k1", map[string]string{"k1": testColumnKey('a')})
+	oldValue, err := EncryptColumnValue("token")
+	assert.NoError(t, err)
+
+	setTestColumnCipher(t, "k2", map[string]string{"k1": testColumnKey('a'), "k2": testColumnKey('b')})
+	plaintext, err := DecryptColumnValue(oldValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "token", plaintext)
+	newValue, err := EncryptColumnValue(plaintext)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(newValue, "enc:v1:k2:"))
+
+	// once the old key is dropped its values can no longer be read
+	setTestColumnCipher(t, "k2", map[string]string{"k2": testColumnKey('b')})
+	_, err = DecryptColumnValue(oldValue)
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), testColumnKey('a'))
+}
+
+func TestColumnEncryptionConfig(t *testing.T) {
+	_, err := EncryptColumnValue("token")
+	assert.Equal(t, ErrColumnEncryptionNotInitialised, err)
+
+	t.Setenv("COLUMN_ENCRYPTION_KEYS", "")
+	t.Setenv("COLUMN_ENCRYPTION_KEYS_DIR", t.TempDir())
+	assert.Error(t, InitColumnEncryption(), "missing key fails")
+
+	t.Setenv("COLUMN_ENCRYPTION_KEYS", "k1:"+testColumnKey('a')+",k2:"+testColumnKey('b'))
+	assert.Error(t, InitColumnEncryption(), "active key id is required with more than one key")
+
+	t.Setenv("COLUMN_ENCRYPTION_KEYS", "k1:c2hvcnQ=")
+	err = InitColumnEncryption()
+	assert.Error(t, err, "short key fails")
+	assert.NotContains(t, err.Error(), "c2hvcnQ=")
+}
+
+func TestEncryptedClusterConfig(t *testing.T) {
+	setTestColumnCipher(t, "k1", map[string]string{"k1": testColumnKey('a')})
+	config := EncryptedClusterConfig{"bearer_token": "token", "cert_data": "cert"}
+
+	value, err := config.Value()
+	assert.NoError(t, err)
+	stored := map[string]string{}
+	assert.NoError(t, json.Unmarshal([]byte(value.(string)), &stored))
+	assert.True(t, IsEncryptedColumnValue(stored["bearer_token"]))
+	assert.Equal(t, "cert", stored["cert_data"])
+	assert.Equal(t, "token", config["bearer_token"], "value does not change the model")
+
+	var scanned EncryptedClusterConfig
+	assert.NoError(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, config, scanned)
+
+	assert.NoError(t, scanned.Scan(`{"bearer_token":"legacy-token"}`))
+	assert.Equal(t, EncryptedClusterConfig{"bearer_token": "legacy-token"}, scanned)
+}
@@ -0,0 +1,111 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+	"go.uber.org/zap"
+)
+
+const (
+	// sql states postgres returns when a transaction lost a conflict, rerunning the whole transaction is safe
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+type TransactionConfig struct {
+	MaxAttempts          int `env:"PG_TX_MAX_ATTEMPTS" envDefault:"3"`
+	RetryBackoffInMillis int `env:"PG_TX_RETRY_BACKOFF_IN_MILLIS" envDefault:"50"`
+}
+
+// TransactionUtil runs multi step writes of services in one transaction. Repositories take the *pg.Tx of fn as an
+// optional argument, with a nil tx they write directly on the connection
+type TransactionUtil interface {
+	// WithTx commits when fn returns nil and rolls back otherwise. fn is run again in a new transaction when postgres
+	// reports a serialization failure or deadlock, up to PG_TX_MAX_ATTEMPTS times, so it must not have side effects
+	// outside the transaction
+	WithTx(ctx context.Context, fn func(tx *pg.Tx) error) error
+}
+
+type TransactionUtilImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+	config       *TransactionConfig
+}
+
+func NewTransactionUtilImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) (*TransactionUtilImpl, error) {
+	config := &TransactionConfig{}
+	err := env.Parse(config)
+	if err != nil {
+		logger.Errorw("error in parsing transaction config", "err", err)
+		return nil, err
+	}
+	return &TransactionUtilImpl{dbConnection: dbConnection, logger: logger, config: config}, nil
+}
+
+func (impl *TransactionUtilImpl) WithTx(ctx context.Context, fn func(tx *pg.Tx) error) error {
+	return retryTransaction(ctx, impl.config, impl.logger, func() error {
+		return impl.dbConnection.WithContext(ctx).RunInTransaction(fn)
+	})
+}
+
+func retryTransaction(ctx context.Context, config *TransactionConfig, logger *zap.SugaredLogger, run func() error) error {
+	maxAttempts := config.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = run()
+		if err == nil || !IsRetryableTxError(err) || attempt == maxAttempts {
+			return err
+		}
+		logger.Warnw("retrying transaction after conflict", "attempt", attempt, "err", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt*config.RetryBackoffInMillis) * time.Millisecond):
+		}
+	}
+	return err
+}
+
+// IsRetryableTxError tells if the transaction failed only because of a conflict with a concurrent transaction
+func IsRetryableTxError(err error) bool {
+	pgErr, ok := err.(pg.Error)
+	if !ok {
+		return false
+	}
+	code := pgErr.Field('C')
+	return code == sqlStateSerializationFailure || code == sqlStateDeadlockDetected
+}
+
+// WithSavepoint runs fn on tx inside a savepoint with the name. When fn returns an error the writes of fn are rolled
+// back and its error is returned, the writes done on tx before stay and tx can be used further
+func WithSavepoint(tx *pg.Tx, name string, fn func() error) error {
+	_, err := tx.Exec("SAVEPOINT " + name)
+	if err != nil {
+		return err
+	}
+	fnErr := fn()
+	if fnErr != nil {
+		_, err = tx.Exec("ROLLBACK TO SAVEPOINT " + name)
+		if err != nil {
+			return err
+		}
+		return fnErr
+	}
+	_, err = tx.Exec("RELEASE SAVEPOINT " + name)
+	return err
+}
+
+// Connection returns tx when it is set, repositories use it to write on the transaction of the caller if there is one
+func Connection(dbConnection *pg.DB, tx *pg.Tx) orm.DB {
+	if tx != nil {
+		return tx
+	}
+	return dbConnection
+}
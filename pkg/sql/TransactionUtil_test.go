@@ -0,0 +1,102 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-pg/pg"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type pgErrorStub struct {
+	code string
+}
+
+func (e pgErrorStub) Error() string {
+	return "ERROR #" + e.code
+}
+
+func (e pgErrorStub) Field(field byte) string {
+	if field == 'C' {
+		return e.code
+	}
+	return ""
+}
+
+func (e pgErrorStub) IntegrityViolation() bool {
+	return false
+}
+
+func TestRetryTransaction(t *testing.T) {
+	config := &TransactionConfig{MaxAttempts: 3}
+	logger := zap.NewNop().Sugar()
+	tests := []struct {
+		name         string
+		errs         []error
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "committed at first attempt", errs: []error{nil}, wantAttempts: 1},
+		{name: "serialization failure is retried", errs: []error{pgErrorStub{code: "40001"}, nil}, wantAttempts: 2},
+		{name: "deadlock is retried", errs: []error{pgErrorStub{code: "40P01"}, nil}, wantAttempts: 2},
+		{name: "other errors are not retried", errs: []error{pgErrorStub{code: "23505"}}, wantAttempts: 1, wantErr: pgErrorStub{code: "23505"}},
+		{
+			name:         "attempts are bounded",
+			errs:         []error{pgErrorStub{code: "40001"}, pgErrorStub{code: "40001"}, pgErrorStub{code: "40001"}, nil},
+			wantAttempts: 3,
+			wantErr:      pgErrorStub{code: "40001"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := retryTransaction(context.Background(), config, logger, func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantAttempts, attempts)
+		})
+	}
+}
+
+// TestWithTxRollsBack runs against the database of PG_* env, it is skipped when the database is not reachable
+func TestWithTxRollsBack(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	cfg, _ := GetConfig()
+	db, err := NewDbConnection(cfg, logger)
+	if err != nil {
+		t.Skipf("test database not reachable: %v", err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS transaction_util_test (id serial PRIMARY KEY, name text)`)
+	assert.NoError(t, err)
+	defer db.Exec(`DROP TABLE IF EXISTS transaction_util_test`)
+	transactionUtil, err := NewTransactionUtilImpl(db, logger)
+	assert.NoError(t, err)
+
+	failure := errors.New("label sync failed")
+	err = transactionUtil.WithTx(context.Background(), func(tx *pg.Tx) error {
+		if _, err := tx.Exec(`INSERT INTO transaction_util_test (name) VALUES ('app')`); err != nil {
+			return err
+		}
+		return failure
+	})
+	assert.Equal(t, failure, err)
+	var count int
+	_, err = db.QueryOne(pg.Scan(&count), `SELECT count(*) FROM transaction_util_test`)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count, "insert before the failure is rolled back")
+
+	err = transactionUtil.WithTx(context.Background(), func(tx *pg.Tx) error {
+		_, err := tx.Exec(`INSERT INTO transaction_util_test (name) VALUES ('app')`)
+		return err
+	})
+	assert.NoError(t, err)
+	_, err = db.QueryOne(pg.Scan(&count), `SELECT count(*) FROM transaction_util_test`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
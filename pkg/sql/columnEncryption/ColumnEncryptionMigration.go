@@ -0,0 +1,56 @@
+package columnEncryption
+
+import (
+	dockerRegistryRepository "github.com/devtron-labs/devtron/internal/sql/repository/dockerRegistry"
+	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const CommandName = "encrypt-columns"
+
+type MigrationSummary struct {
+	Clusters         int
+	DockerRegistries int
+}
+
+// EncryptExistingRows rewrites the sensitive columns of every row, reading tolerates legacy plaintext and values of
+// older keys and writing always uses the active key, so the command both encrypts legacy rows and rotates keys. It
+// is idempotent and every table is rewritten in a single transaction
+func EncryptExistingRows(dbConnection *pg.DB, logger *zap.SugaredLogger) (*MigrationSummary, error) {
+	summary := &MigrationSummary{}
+	err := dbConnection.RunInTransaction(func(tx *pg.Tx) error {
+		var clusters []*repository.Cluster
+		if err := tx.Model(&clusters).Column("id", "config", "p_password", "p_tls_client_key").Select(); err != nil && err != pg.ErrNoRows {
+			logger.Errorw("error in getting clusters", "err", err)
+			return err
+		}
+		for _, cluster := range clusters {
+			if _, err := tx.Model(cluster).Column("config", "p_password", "p_tls_client_key").WherePK().Update(); err != nil {
+				logger.Errorw("error in encrypting cluster columns", "clusterId", cluster.Id, "err", err)
+				return err
+			}
+		}
+		summary.Clusters = len(clusters)
+		return nil
+	})
+	if err != nil {
+		return summary, err
+	}
+	err = dbConnection.RunInTransaction(func(tx *pg.Tx) error {
+		var stores []*dockerRegistryRepository.DockerArtifactStore
+		if err := tx.Model(&stores).Column("id", "password", "aws_secret_accesskey").Select(); err != nil && err != pg.ErrNoRows {
+			logger.Errorw("error in getting docker registries", "err", err)
+			return err
+		}
+		for _, store := range stores {
+			if _, err := tx.Model(store).Column("password", "aws_secret_accesskey").WherePK().Update(); err != nil {
+				logger.Errorw("error in encrypting docker registry columns", "registryId", store.Id, "err", err)
+				return err
+			}
+		}
+		summary.DockerRegistries = len(stores)
+		return nil
+	})
+	return summary, err
+}
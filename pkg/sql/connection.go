@@ -43,6 +43,11 @@ func GetConfig() (*Config, error) {
 }
 
 func NewDbConnection(cfg *Config, logger *zap.SugaredLogger) (*pg.DB, error) {
+	// sensitive columns can neither be read nor written without the keys, so fail before serving anything
+	if err := InitColumnEncryption(); err != nil {
+		logger.Errorw("error in initialising column encryption", "err", err)
+		return nil, err
+	}
 	options := pg.Options{
 		Addr:            cfg.Addr + ":" + cfg.Port,
 		User:            cfg.User,
@@ -5,4 +5,6 @@ import "github.com/google/wire"
 var PgSqlWireSet = wire.NewSet(
 	GetConfig,
 	NewDbConnection,
+	NewTransactionUtilImpl,
+	wire.Bind(new(TransactionUtil), new(*TransactionUtilImpl)),
 )
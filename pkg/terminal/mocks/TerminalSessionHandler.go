@@ -17,6 +17,11 @@ func (_m *TerminalSessionHandler) Close(sessionId string, statusCode uint32, msg
 	_m.Called(sessionId, statusCode, msg)
 }
 
+// CloseAll provides a mock function with given fields: statusCode, msg
+func (_m *TerminalSessionHandler) CloseAll(statusCode uint32, msg string) {
+	_m.Called(statusCode, msg)
+}
+
 // GetTerminalSession provides a mock function with given fields: req
 func (_m *TerminalSessionHandler) GetTerminalSession(req *terminal.TerminalSessionRequest) (int, *terminal.TerminalMessage, error) {
 	ret := _m.Called(req)
@@ -47,6 +52,53 @@ func (_m *TerminalSessionHandler) GetTerminalSession(req *terminal.TerminalSessi
 	return r0, r1, r2
 }
 
+// GetStatusSession provides a mock function with given fields:
+func (_m *TerminalSessionHandler) GetStatusSession() (string, error) {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendStatus provides a mock function with given fields: sessionId, data
+func (_m *TerminalSessionHandler) SendStatus(sessionId string, data string) (bool, error) {
+	ret := _m.Called(sessionId, data)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
+		r0 = rf(sessionId, data)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(sessionId, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ToastAll provides a mock function with given fields: msg
+func (_m *TerminalSessionHandler) ToastAll(msg string) {
+	_m.Called(msg)
+}
+
 // ValidateSession provides a mock function with given fields: sessionId
 func (_m *TerminalSessionHandler) ValidateSession(sessionId string) bool {
 	ret := _m.Called(sessionId)
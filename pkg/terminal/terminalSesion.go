@@ -51,6 +51,7 @@ type TerminalSession struct {
 	sockJSSession sockjs.Session
 	sizeChan      chan remotecommand.TerminalSize
 	doneChan      chan struct{}
+	transcript    *TranscriptBuffer
 }
 
 // TerminalMessage is the messaging protocol between ShellController and TerminalSession.
@@ -62,6 +63,7 @@ type TerminalSession struct {
 // resize  fe->be     Rows, Cols     New terminal size
 // stdout  be->fe     Data           Output from the process
 // toast   be->fe     Data           OOB message to be shown to the user
+// status  be->fe     Data           Startup status of the terminal pod, sent on status sessions only
 type TerminalMessage struct {
 	Op, Data, SessionID string
 	Rows, Cols          uint16
@@ -117,6 +119,9 @@ func (t TerminalSession) Write(p []byte) (int, error) {
 	if err = t.sockJSSession.Send(string(msg)); err != nil {
 		return 0, err
 	}
+	if t.transcript != nil {
+		t.transcript.Write(p)
+	}
 	return len(p), nil
 }
 
@@ -137,6 +142,18 @@ func (t TerminalSession) Toast(p string) error {
 	return nil
 }
 
+// Status sends the startup status of the terminal pod, data is the json of the status
+func (t TerminalSession) Status(data string) error {
+	msg, err := json.Marshal(TerminalMessage{
+		Op:   "status",
+		Data: data,
+	})
+	if err != nil {
+		return err
+	}
+	return t.sockJSSession.Send(string(msg))
+}
+
 // SessionMap stores a map of all TerminalSession objects and a lock to avoid concurrent conflict
 type SessionMap struct {
 	Sessions map[string]TerminalSession
@@ -170,9 +187,38 @@ func (sm *SessionMap) Close(sessionId string, status uint32, reason string) {
 		if err != nil {
 			log.Println(err)
 		}
-		delete(sm.Sessions, sessionId)
 	}
+	// sessions which were never bound are dropped too, a late bind is refused as for any closed session
+	delete(sm.Sessions, sessionId)
+}
+
+// ToastAll sends the message to every connected session, failures are only logged as the session may be closing
+func (sm *SessionMap) ToastAll(msg string) {
+	sm.Lock.RLock()
+	defer sm.Lock.RUnlock()
+	for sessionId, terminalSession := range sm.Sessions {
+		if terminalSession.sockJSSession == nil {
+			continue
+		}
+		if err := terminalSession.Toast(msg); err != nil {
+			log.Printf("ToastAll: can't send message to session '%s': %v", sessionId, err)
+		}
+	}
+}
 
+// CloseAll closes every connected session with the given status and reason
+func (sm *SessionMap) CloseAll(status uint32, reason string) {
+	sm.Lock.Lock()
+	defer sm.Lock.Unlock()
+	for sessionId, terminalSession := range sm.Sessions {
+		if terminalSession.sockJSSession == nil {
+			continue
+		}
+		if err := terminalSession.sockJSSession.Close(status, reason); err != nil {
+			log.Println(err)
+		}
+		delete(sm.Sessions, sessionId)
+	}
 }
 
 var terminalSessions = SessionMap{Sessions: make(map[string]TerminalSession)}
@@ -296,6 +342,11 @@ type TerminalSessionRequest struct {
 	AppId         int
 	//ClusterId is optional
 	ClusterId int
+	//RecordTranscript tees the session output to a transcript buffer of TranscriptMaxSize bytes,
+	//OnTranscriptComplete is called with its content once the process exits
+	RecordTranscript     bool
+	TranscriptMaxSize    int
+	OnTranscriptComplete func(sessionId string, transcript []byte, truncated bool)
 }
 
 // WaitForTerminal is called from apihandler.handleAttach as a goroutine
@@ -306,6 +357,11 @@ func WaitForTerminal(k8sClient kubernetes.Interface, cfg *rest.Config, request *
 	case <-terminalSessions.Get(request.SessionId).bound:
 		close(terminalSessions.Get(request.SessionId).bound)
 
+		if transcript := terminalSessions.Get(request.SessionId).transcript; transcript != nil {
+			defer completeTranscript(request, transcript)
+			terminalSessions.Get(request.SessionId).Write([]byte(TranscriptRecordingBanner))
+		}
+
 		var err error
 		validShells := []string{"bash", "sh", "powershell", "cmd"}
 
@@ -333,10 +389,22 @@ func WaitForTerminal(k8sClient kubernetes.Interface, cfg *rest.Config, request *
 	}
 }
 
+func completeTranscript(request *TerminalSessionRequest, transcript *TranscriptBuffer) {
+	if request.OnTranscriptComplete != nil {
+		request.OnTranscriptComplete(request.SessionId, transcript.Bytes(), transcript.Truncated())
+	}
+}
+
 type TerminalSessionHandler interface {
 	GetTerminalSession(req *TerminalSessionRequest) (statusCode int, message *TerminalMessage, err error)
+	// GetStatusSession registers a session which is bound like a terminal session but only receives status messages
+	GetStatusSession() (sessionId string, err error)
+	// SendStatus pushes data to the status session, sent reports whether a client was bound to receive it
+	SendStatus(sessionId string, data string) (sent bool, err error)
 	Close(sessionId string, statusCode uint32, msg string)
 	ValidateSession(sessionId string) bool
+	ToastAll(msg string)
+	CloseAll(statusCode uint32, msg string)
 }
 
 type TerminalSessionHandlerImpl struct {
@@ -358,6 +426,14 @@ func (impl *TerminalSessionHandlerImpl) Close(sessionId string, statusCode uint3
 	terminalSessions.Close(sessionId, statusCode, msg)
 }
 
+func (impl *TerminalSessionHandlerImpl) ToastAll(msg string) {
+	terminalSessions.ToastAll(msg)
+}
+
+func (impl *TerminalSessionHandlerImpl) CloseAll(statusCode uint32, msg string) {
+	terminalSessions.CloseAll(statusCode, msg)
+}
+
 func (impl *TerminalSessionHandlerImpl) ValidateSession(sessionId string) bool {
 	if sessionId == "" {
 		return false
@@ -371,6 +447,27 @@ func (impl *TerminalSessionHandlerImpl) ValidateSession(sessionId string) bool {
 	return false
 }
 
+func (impl *TerminalSessionHandlerImpl) GetStatusSession() (string, error) {
+	sessionID, err := genTerminalSessionId()
+	if err != nil {
+		return "", err
+	}
+	// nothing waits for the bind of a status session, the buffer keeps handleTerminalSession from blocking on it
+	terminalSessions.Set(sessionID, TerminalSession{id: sessionID, bound: make(chan error, 1)})
+	return sessionID, nil
+}
+
+func (impl *TerminalSessionHandlerImpl) SendStatus(sessionId string, data string) (bool, error) {
+	terminalSession := terminalSessions.Get(sessionId)
+	if terminalSession.sockJSSession == nil {
+		return false, nil
+	}
+	if err := terminalSession.Status(data); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (impl *TerminalSessionHandlerImpl) GetTerminalSession(req *TerminalSessionRequest) (statusCode int, message *TerminalMessage, err error) {
 	sessionID, err := genTerminalSessionId()
 	if err != nil {
@@ -382,11 +479,15 @@ func (impl *TerminalSessionHandlerImpl) GetTerminalSession(req *TerminalSessionR
 		return statusCode, nil, err
 	}
 	req.SessionId = sessionID
-	terminalSessions.Set(sessionID, TerminalSession{
+	terminalSession := TerminalSession{
 		id:       sessionID,
 		bound:    make(chan error),
 		sizeChan: make(chan remotecommand.TerminalSize),
-	})
+	}
+	if req.RecordTranscript {
+		terminalSession.transcript = NewTranscriptBuffer(req.TranscriptMaxSize)
+	}
+	terminalSessions.Set(sessionID, terminalSession)
 	config, client, err := impl.getClientConfig(req)
 	if err != nil {
 		impl.logger.Errorw("error in fetching config", "err", err)
@@ -0,0 +1,43 @@
+package terminal
+
+import "sync"
+
+const TranscriptRecordingBanner = "\r\n\x1b[33m*** This session is being recorded, do not type secrets at the prompt ***\x1b[0m\r\n"
+
+// TranscriptBuffer is a size capped buffer which keeps the latest output of a terminal session,
+// older output is dropped once maxSize is reached
+type TranscriptBuffer struct {
+	lock      sync.Mutex
+	data      []byte
+	maxSize   int
+	truncated bool
+}
+
+func NewTranscriptBuffer(maxSize int) *TranscriptBuffer {
+	return &TranscriptBuffer{maxSize: maxSize}
+}
+
+func (b *TranscriptBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.data = append(b.data, p...)
+	if overflow := len(b.data) - b.maxSize; b.maxSize > 0 && overflow > 0 {
+		b.data = append(b.data[:0], b.data[overflow:]...)
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+func (b *TranscriptBuffer) Bytes() []byte {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	data := make([]byte, len(b.data))
+	copy(data, b.data)
+	return data
+}
+
+func (b *TranscriptBuffer) Truncated() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.truncated
+}
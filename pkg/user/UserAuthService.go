@@ -366,6 +366,8 @@ func (impl UserAuthServiceImpl) HandleDexCallback(w http.ResponseWriter, r *http
 func WhitelistChecker(url string) bool {
 	urls := []string{
 		"/health",
+		"/health/live",
+		"/health/ready",
 		"/metrics",
 		"/orchestrator/webhook/ci/gocd/artifact",
 		"/orchestrator/auth/login",
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "public"."user_terminal_session_transcript";
+
+DROP SEQUENCE IF EXISTS public.id_seq_user_terminal_session_transcript;
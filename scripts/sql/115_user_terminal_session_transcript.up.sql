@@ -0,0 +1,19 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_user_terminal_session_transcript;
+
+CREATE TABLE IF NOT EXISTS "public"."user_terminal_session_transcript"
+(
+    "id"                 int4        NOT NULL DEFAULT nextval('id_seq_user_terminal_session_transcript'::regclass),
+    "terminal_access_id" int4        NOT NULL,
+    "session_id"         varchar(50) NOT NULL,
+    "user_id"            int4        NOT NULL,
+    "transcript"         TEXT,
+    "truncated"          bool        NOT NULL DEFAULT false,
+    "created_on"         timestamptz NOT NULL,
+    "created_by"         int4        NOT NULL,
+    "updated_on"         timestamptz,
+    "updated_by"         int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "user_terminal_session_transcript_terminal_access_id_fkey" FOREIGN KEY ("terminal_access_id") REFERENCES "public"."user_terminal_access_data" ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_terminal_session_transcript_session_id_idx ON public.user_terminal_session_transcript (session_id);
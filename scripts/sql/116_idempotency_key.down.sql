@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "public"."idempotency_key";
+
+DROP SEQUENCE IF EXISTS public.id_seq_idempotency_key;
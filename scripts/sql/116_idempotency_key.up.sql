@@ -0,0 +1,23 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_idempotency_key;
+
+CREATE TABLE IF NOT EXISTS "public"."idempotency_key"
+(
+    "id"              int4         NOT NULL DEFAULT nextval('id_seq_idempotency_key'::regclass),
+    "idempotency_key" varchar(255) NOT NULL,
+    "user_id"         int4         NOT NULL,
+    "route"           varchar(255) NOT NULL,
+    "request_hash"    varchar(64)  NOT NULL,
+    "completed"       bool         NOT NULL DEFAULT false,
+    "response_status" int4,
+    "response_type"   varchar(255),
+    "response_body"   TEXT,
+    "expires_on"      timestamptz  NOT NULL,
+    "created_on"      timestamptz  NOT NULL,
+    "created_by"      int4         NOT NULL,
+    "updated_on"      timestamptz,
+    "updated_by"      int4,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idempotency_key_key_user_route_idx ON public.idempotency_key (idempotency_key, user_id, route);
+CREATE INDEX IF NOT EXISTS idempotency_key_expires_on_idx ON public.idempotency_key (expires_on);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "public"."cluster_operation";
+
+DROP SEQUENCE IF EXISTS public.id_seq_cluster_operation;
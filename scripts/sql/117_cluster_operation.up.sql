@@ -0,0 +1,23 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_cluster_operation;
+
+CREATE TABLE IF NOT EXISTS "public"."cluster_operation"
+(
+    "id"               int4         NOT NULL DEFAULT nextval('id_seq_cluster_operation'::regclass),
+    "operation_type"   varchar(100) NOT NULL,
+    "cluster_id"       int4,
+    "namespace"        varchar(250),
+    "resource_name"    varchar(250),
+    "state"            varchar(50)  NOT NULL,
+    "progress_message" TEXT,
+    "started_on"       timestamptz  NOT NULL,
+    "finished_on"      timestamptz,
+    "user_id"          int4         NOT NULL,
+    "created_on"       timestamptz  NOT NULL,
+    "created_by"       int4         NOT NULL,
+    "updated_on"       timestamptz,
+    "updated_by"       int4,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS cluster_operation_state_idx ON public.cluster_operation (state);
+CREATE INDEX IF NOT EXISTS cluster_operation_user_id_idx ON public.cluster_operation (user_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "public"."pod_placement_policy";
+
+DROP SEQUENCE IF EXISTS public.id_seq_pod_placement_policy;
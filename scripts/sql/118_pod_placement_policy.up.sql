@@ -0,0 +1,21 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_pod_placement_policy;
+
+CREATE TABLE IF NOT EXISTS "public"."pod_placement_policy"
+(
+    "id"                 int4         NOT NULL DEFAULT nextval('id_seq_pod_placement_policy'::regclass),
+    "cluster_id"         int4         NOT NULL,
+    "namespace"          varchar(250) NOT NULL DEFAULT '',
+    "node_selector"      TEXT,
+    "tolerations"        TEXT,
+    "runtime_class_name" varchar(250),
+    "active"             bool         NOT NULL,
+    "created_on"         timestamptz  NOT NULL,
+    "created_by"         int4         NOT NULL,
+    "updated_on"         timestamptz,
+    "updated_by"         int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "pod_placement_policy_cluster_id_fkey" FOREIGN KEY ("cluster_id") REFERENCES "public"."cluster" ("id")
+);
+
+-- an empty namespace is the policy of the whole cluster
+CREATE UNIQUE INDEX IF NOT EXISTS pod_placement_policy_cluster_namespace_unique ON public.pod_placement_policy (cluster_id, namespace) WHERE active = true;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "public"."freeze_window";
+
+DROP SEQUENCE IF EXISTS public.id_seq_freeze_window;
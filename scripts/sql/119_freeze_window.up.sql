@@ -0,0 +1,24 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_freeze_window;
+
+CREATE TABLE IF NOT EXISTS "public"."freeze_window"
+(
+    "id"             int4         NOT NULL DEFAULT nextval('id_seq_freeze_window'::regclass),
+    "name"           varchar(250) NOT NULL,
+    "cluster_id"     int4         NOT NULL,
+    "environment_id" int4,
+    "start_time"     timestamptz  NOT NULL,
+    "end_time"       timestamptz  NOT NULL,
+    "reason"         TEXT,
+    "override_roles" TEXT,
+    "active"         bool         NOT NULL,
+    "created_on"     timestamptz  NOT NULL,
+    "created_by"     int4         NOT NULL,
+    "updated_on"     timestamptz,
+    "updated_by"     int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "freeze_window_cluster_id_fkey" FOREIGN KEY ("cluster_id") REFERENCES "public"."cluster" ("id"),
+    CONSTRAINT "freeze_window_environment_id_fkey" FOREIGN KEY ("environment_id") REFERENCES "public"."environment" ("id")
+);
+
+-- environment_id is empty for windows freezing the whole cluster
+CREATE INDEX IF NOT EXISTS freeze_window_cluster_id_end_time_idx ON public.freeze_window (cluster_id, end_time) WHERE active = true;
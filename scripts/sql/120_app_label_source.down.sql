@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_app_label_source;
+
+ALTER TABLE app_label DROP COLUMN IF EXISTS source;
@@ -0,0 +1,3 @@
+ALTER TABLE app_label ADD COLUMN IF NOT EXISTS source varchar(20) NOT NULL DEFAULT 'unknown';
+
+CREATE INDEX IF NOT EXISTS idx_app_label_source ON app_label (source);
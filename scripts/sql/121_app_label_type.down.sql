@@ -0,0 +1 @@
+ALTER TABLE app_label DROP COLUMN IF EXISTS type;
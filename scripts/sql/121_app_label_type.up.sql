@@ -0,0 +1 @@
+ALTER TABLE app_label ADD COLUMN IF NOT EXISTS type varchar(20) NOT NULL DEFAULT 'label';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_user_terminal_access_data_running_started_on;
+
+ALTER TABLE user_terminal_access_data DROP COLUMN IF EXISTS requested_memory_bytes;
+ALTER TABLE user_terminal_access_data DROP COLUMN IF EXISTS requested_cpu_millis;
+ALTER TABLE user_terminal_access_data DROP COLUMN IF EXISTS running_ended_on;
+ALTER TABLE user_terminal_access_data DROP COLUMN IF EXISTS running_started_on;
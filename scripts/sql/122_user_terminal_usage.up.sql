@@ -0,0 +1,6 @@
+ALTER TABLE user_terminal_access_data ADD COLUMN IF NOT EXISTS running_started_on timestamptz;
+ALTER TABLE user_terminal_access_data ADD COLUMN IF NOT EXISTS running_ended_on timestamptz;
+ALTER TABLE user_terminal_access_data ADD COLUMN IF NOT EXISTS requested_cpu_millis bigint NOT NULL DEFAULT 0;
+ALTER TABLE user_terminal_access_data ADD COLUMN IF NOT EXISTS requested_memory_bytes bigint NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_user_terminal_access_data_running_started_on ON user_terminal_access_data (running_started_on);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "public"."label_template_entry";
+
+DROP SEQUENCE IF EXISTS public.id_seq_label_template_entry;
+
+DROP TABLE IF EXISTS "public"."label_template";
+
+DROP SEQUENCE IF EXISTS public.id_seq_label_template;
@@ -0,0 +1,34 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_label_template;
+
+CREATE TABLE IF NOT EXISTS "public"."label_template"
+(
+    "id"         int4         NOT NULL DEFAULT nextval('id_seq_label_template'::regclass),
+    "name"       varchar(250) NOT NULL,
+    "active"     bool         NOT NULL DEFAULT true,
+    "created_on" timestamptz  NOT NULL,
+    "created_by" int4         NOT NULL,
+    "updated_on" timestamptz,
+    "updated_by" int4,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS label_template_name_idx ON public.label_template (name) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_label_template_entry;
+
+CREATE TABLE IF NOT EXISTS "public"."label_template_entry"
+(
+    "id"                int4         NOT NULL DEFAULT nextval('id_seq_label_template_entry'::regclass),
+    "label_template_id" int4         NOT NULL,
+    "key"               varchar(255) NOT NULL,
+    "default_value"     varchar(255) NOT NULL DEFAULT '',
+    "required"          bool         NOT NULL DEFAULT false,
+    "created_on"        timestamptz  NOT NULL,
+    "created_by"        int4         NOT NULL,
+    "updated_on"        timestamptz,
+    "updated_by"        int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "label_template_entry_label_template_id_fkey" FOREIGN KEY ("label_template_id") REFERENCES "public"."label_template" ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS label_template_entry_template_key_idx ON public.label_template_entry (label_template_id, key);
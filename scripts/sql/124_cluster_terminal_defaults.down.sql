@@ -0,0 +1,2 @@
+ALTER TABLE cluster DROP COLUMN IF EXISTS terminal_default_shell;
+ALTER TABLE cluster DROP COLUMN IF EXISTS terminal_default_base_image;
@@ -0,0 +1,2 @@
+ALTER TABLE cluster ADD COLUMN IF NOT EXISTS terminal_default_base_image varchar(500);
+ALTER TABLE cluster ADD COLUMN IF NOT EXISTS terminal_default_shell varchar(50);
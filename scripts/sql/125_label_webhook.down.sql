@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "public"."label_event_outbox";
+
+DROP SEQUENCE IF EXISTS public.id_seq_label_event_outbox;
+
+DROP TABLE IF EXISTS "public"."label_webhook";
+
+DROP SEQUENCE IF EXISTS public.id_seq_label_webhook;
@@ -0,0 +1,34 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_label_webhook;
+
+CREATE TABLE IF NOT EXISTS "public"."label_webhook"
+(
+    "id"          int4          NOT NULL DEFAULT nextval('id_seq_label_webhook'::regclass),
+    "url"         varchar(2000) NOT NULL,
+    "event_types" varchar(250)  NOT NULL DEFAULT '',
+    "active"      bool          NOT NULL DEFAULT true,
+    "created_on"  timestamptz   NOT NULL,
+    "created_by"  int4          NOT NULL,
+    "updated_on"  timestamptz,
+    "updated_by"  int4,
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_label_event_outbox;
+
+CREATE TABLE IF NOT EXISTS "public"."label_event_outbox"
+(
+    "id"              int4        NOT NULL DEFAULT nextval('id_seq_label_event_outbox'::regclass),
+    "webhook_id"      int4        NOT NULL,
+    "event_type"      varchar(50) NOT NULL,
+    "payload"         text        NOT NULL,
+    "status"          varchar(20) NOT NULL,
+    "attempts"        int4        NOT NULL DEFAULT 0,
+    "last_error"      text,
+    "next_attempt_on" timestamptz NOT NULL,
+    "created_on"      timestamptz NOT NULL,
+    "updated_on"      timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "label_event_outbox_webhook_id_fkey" FOREIGN KEY ("webhook_id") REFERENCES "public"."label_webhook" ("id")
+);
+
+CREATE INDEX IF NOT EXISTS label_event_outbox_pending_idx ON public.label_event_outbox (next_attempt_on) WHERE status = 'pending';
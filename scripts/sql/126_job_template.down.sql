@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "public"."job_template";
+
+DROP SEQUENCE IF EXISTS public.id_seq_job_template;
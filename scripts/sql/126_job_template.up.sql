@@ -0,0 +1,17 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_job_template;
+
+CREATE TABLE IF NOT EXISTS "public"."job_template"
+(
+    "id"          int4         NOT NULL DEFAULT nextval('id_seq_job_template'::regclass),
+    "name"        varchar(250) NOT NULL,
+    "description" text,
+    "template"    text         NOT NULL,
+    "active"      bool         NOT NULL DEFAULT true,
+    "created_on"  timestamptz  NOT NULL,
+    "created_by"  int4         NOT NULL,
+    "updated_on"  timestamptz,
+    "updated_by"  int4,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS job_template_name_active_idx ON public.job_template (name) WHERE active = true;
@@ -0,0 +1 @@
+ALTER TABLE cluster_operation DROP COLUMN IF EXISTS checkpoint;
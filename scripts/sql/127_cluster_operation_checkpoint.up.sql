@@ -0,0 +1 @@
+ALTER TABLE cluster_operation ADD COLUMN IF NOT EXISTS checkpoint TEXT;
@@ -0,0 +1,3 @@
+DELETE FROM app_label WHERE active = false;
+DROP INDEX IF EXISTS app_label_updated_on_id_IX;
+ALTER TABLE app_label DROP COLUMN IF EXISTS active;
@@ -0,0 +1,3 @@
+ALTER TABLE app_label ADD COLUMN IF NOT EXISTS active boolean NOT NULL DEFAULT true;
+UPDATE app_label SET updated_on = COALESCE(created_on, now()) WHERE updated_on IS NULL;
+CREATE INDEX IF NOT EXISTS app_label_updated_on_id_IX ON public.app_label USING btree(updated_on, id);
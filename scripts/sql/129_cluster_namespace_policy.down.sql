@@ -0,0 +1,3 @@
+ALTER TABLE cluster DROP COLUMN IF EXISTS namespace_policy_restrict_reads;
+ALTER TABLE cluster DROP COLUMN IF EXISTS namespace_policy_patterns;
+ALTER TABLE cluster DROP COLUMN IF EXISTS namespace_policy_mode;
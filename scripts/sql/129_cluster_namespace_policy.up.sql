@@ -0,0 +1,3 @@
+ALTER TABLE cluster ADD COLUMN IF NOT EXISTS namespace_policy_mode varchar(10);
+ALTER TABLE cluster ADD COLUMN IF NOT EXISTS namespace_policy_patterns TEXT;
+ALTER TABLE cluster ADD COLUMN IF NOT EXISTS namespace_policy_restrict_reads boolean NOT NULL DEFAULT false;
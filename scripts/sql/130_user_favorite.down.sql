@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "public"."user_favorite";
+
+DROP SEQUENCE IF EXISTS public.id_seq_user_favorite;
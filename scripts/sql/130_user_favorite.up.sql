@@ -0,0 +1,17 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_user_favorite;
+
+CREATE TABLE IF NOT EXISTS "public"."user_favorite"
+(
+    "id"         int4         NOT NULL DEFAULT nextval('id_seq_user_favorite'::regclass),
+    "user_id"    int4         NOT NULL,
+    "type"       varchar(50)  NOT NULL,
+    "name"       varchar(250) NOT NULL,
+    "payload"    text         NOT NULL,
+    "created_on" timestamptz  NOT NULL,
+    "created_by" int4         NOT NULL,
+    "updated_on" timestamptz,
+    "updated_by" int4,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_favorite_user_type_name_idx ON public.user_favorite (user_id, type, name);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS cluster_operation_state_heartbeat_on_idx;
+
+ALTER TABLE cluster_operation DROP COLUMN IF EXISTS heartbeat_on;
+ALTER TABLE cluster_operation DROP COLUMN IF EXISTS owner_instance_id;
@@ -0,0 +1,4 @@
+ALTER TABLE cluster_operation ADD COLUMN IF NOT EXISTS owner_instance_id VARCHAR(250);
+ALTER TABLE cluster_operation ADD COLUMN IF NOT EXISTS heartbeat_on TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS cluster_operation_state_heartbeat_on_idx ON public.cluster_operation (state, heartbeat_on);
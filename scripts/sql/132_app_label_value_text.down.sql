@@ -0,0 +1 @@
+ALTER TABLE "public"."app_label" ALTER COLUMN "value" SET DATA TYPE varchar(255) USING left("value", 255);
@@ -0,0 +1 @@
+ALTER TABLE "public"."app_label" ALTER COLUMN "value" SET DATA TYPE text;
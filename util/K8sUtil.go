@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"strings"
 )
 
 func CheckIfValidLabel(labelKey string, labelValue string) error {
@@ -18,3 +19,12 @@ func CheckIfValidLabel(labelKey string, labelValue string) error {
 	}
 	return nil
 }
+
+// CheckIfValidAnnotation only checks the key, annotation values are free form
+func CheckIfValidAnnotation(annotationKey string) error {
+	errs := validation.IsQualifiedName(strings.ToLower(annotationKey))
+	if len(errs) > 0 {
+		return errors.New(fmt.Sprintf("Validation error - annotation key - %s is not satisfying the annotation key criteria", annotationKey))
+	}
+	return nil
+}
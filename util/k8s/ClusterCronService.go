@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/caarlos0/env/v6"
+	"github.com/devtron-labs/devtron/internal/middleware"
 	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/cluster"
 	clusterRepository "github.com/devtron-labs/devtron/pkg/cluster/repository"
@@ -12,6 +13,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"log"
 	"sync"
+	"time"
 )
 
 type ClusterCronService interface {
@@ -22,19 +24,41 @@ type ClusterCronServiceImpl struct {
 	clusterService        cluster.ClusterService
 	k8sApplicationService K8sApplicationService
 	clusterRepository     clusterRepository.ClusterRepository
+	K8sUtil               *util.K8sUtil
+	buildJobMetricsConfig *BuildJobMetricsConfig
+	jobCleanupConfig      *CompletedJobCleanupConfig
 }
 
 type ClusterStatusConfig struct {
 	ClusterStatusCronTime int `env:"CLUSTER_STATUS_CRON_TIME" envDefault:"15"`
 }
 
+// BuildJobMetricsConfig controls the build job completion ratio exported on /metrics, jobs are read from the default cluster
+type BuildJobMetricsConfig struct {
+	Enabled        bool   `env:"BUILD_JOB_METRICS_ENABLED" envDefault:"false"`
+	Namespace      string `env:"BUILD_JOB_METRICS_NAMESPACE" envDefault:"devtron-ci"`
+	LabelSelector  string `env:"BUILD_JOB_METRICS_LABEL_SELECTOR" envDefault:""`
+	WindowInMins   int    `env:"BUILD_JOB_METRICS_WINDOW_IN_MINS" envDefault:"60"`
+	CronTimeInMins int    `env:"BUILD_JOB_METRICS_CRON_TIME_IN_MINS" envDefault:"5"`
+}
+
+// CompletedJobCleanupConfig controls the deletion of completed jobs which have no ttl, jobs are deleted from the default cluster
+type CompletedJobCleanupConfig struct {
+	Enabled         bool   `env:"COMPLETED_JOB_CLEANUP_ENABLED" envDefault:"false"`
+	Namespace       string `env:"COMPLETED_JOB_CLEANUP_NAMESPACE" envDefault:"devtron-ci"`
+	LabelSelector   string `env:"COMPLETED_JOB_CLEANUP_LABEL_SELECTOR" envDefault:""`
+	RetentionInMins int    `env:"COMPLETED_JOB_RETENTION_IN_MINS" envDefault:"1440"`
+	CronTimeInMins  int    `env:"COMPLETED_JOB_CLEANUP_CRON_TIME_IN_MINS" envDefault:"60"`
+}
+
 func NewClusterCronServiceImpl(logger *zap.SugaredLogger, clusterService cluster.ClusterService,
-	k8sApplicationService K8sApplicationService, clusterRepository clusterRepository.ClusterRepository) (*ClusterCronServiceImpl, error) {
+	k8sApplicationService K8sApplicationService, clusterRepository clusterRepository.ClusterRepository, K8sUtil *util.K8sUtil) (*ClusterCronServiceImpl, error) {
 	clusterCronServiceImpl := &ClusterCronServiceImpl{
 		logger:                logger,
 		clusterService:        clusterService,
 		k8sApplicationService: k8sApplicationService,
 		clusterRepository:     clusterRepository,
+		K8sUtil:               K8sUtil,
 	}
 	// initialise cron
 	newCron := cron.New(cron.WithChain())
@@ -50,9 +74,82 @@ func NewClusterCronServiceImpl(logger *zap.SugaredLogger, clusterService cluster
 		fmt.Println("error in adding cron function into cluster cron service")
 		return clusterCronServiceImpl, err
 	}
+	buildJobMetricsConfig := &BuildJobMetricsConfig{}
+	err = env.Parse(buildJobMetricsConfig)
+	if err != nil {
+		logger.Errorw("error in parsing build job metrics config", "err", err)
+		return clusterCronServiceImpl, err
+	}
+	clusterCronServiceImpl.buildJobMetricsConfig = buildJobMetricsConfig
+	if buildJobMetricsConfig.Enabled {
+		_, err = newCron.AddFunc(fmt.Sprintf("@every %dm", buildJobMetricsConfig.CronTimeInMins), clusterCronServiceImpl.UpdateBuildJobMetrics)
+		if err != nil {
+			logger.Errorw("error in adding build job metrics cron", "err", err)
+			return clusterCronServiceImpl, err
+		}
+	}
+	jobCleanupConfig := &CompletedJobCleanupConfig{}
+	err = env.Parse(jobCleanupConfig)
+	if err != nil {
+		logger.Errorw("error in parsing completed job cleanup config", "err", err)
+		return clusterCronServiceImpl, err
+	}
+	clusterCronServiceImpl.jobCleanupConfig = jobCleanupConfig
+	if jobCleanupConfig.Enabled {
+		_, err = newCron.AddFunc(fmt.Sprintf("@every %dm", jobCleanupConfig.CronTimeInMins), clusterCronServiceImpl.DeleteCompletedJobs)
+		if err != nil {
+			logger.Errorw("error in adding completed job cleanup cron", "err", err)
+			return clusterCronServiceImpl, err
+		}
+	}
 	return clusterCronServiceImpl, nil
 }
 
+func (impl *ClusterCronServiceImpl) DeleteCompletedJobs() {
+	config := impl.jobCleanupConfig
+	clusterBean, err := impl.clusterService.FindOne(cluster.DEFAULT_CLUSTER)
+	if err != nil {
+		impl.logger.Errorw("error in getting default cluster", "err", err)
+		return
+	}
+	clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting default cluster config", "err", err)
+		return
+	}
+	retention := time.Duration(config.RetentionInMins) * time.Minute
+	deleted, err := impl.K8sUtil.DeleteCompletedJobs(context.Background(), config.Namespace, config.LabelSelector, retention, clusterConfig)
+	if err != nil {
+		impl.logger.Errorw("error in deleting completed jobs", "err", err, "namespace", config.Namespace, "deleted", deleted)
+		return
+	}
+	impl.logger.Infow("deleted completed jobs", "namespace", config.Namespace, "deleted", deleted)
+}
+
+// UpdateBuildJobMetrics refreshes the build job gauges, they keep their last value when the cluster can not be reached
+func (impl *ClusterCronServiceImpl) UpdateBuildJobMetrics() {
+	config := impl.buildJobMetricsConfig
+	clusterBean, err := impl.clusterService.FindOne(cluster.DEFAULT_CLUSTER)
+	if err != nil {
+		impl.logger.Errorw("error in getting default cluster", "err", err)
+		return
+	}
+	clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting default cluster config", "err", err)
+		return
+	}
+	window := time.Duration(config.WindowInMins) * time.Minute
+	ratio, err := impl.K8sUtil.GetJobCompletionRatio(context.Background(), config.Namespace, config.LabelSelector, window, clusterConfig)
+	if err != nil {
+		impl.logger.Errorw("error in getting build job completion ratio", "err", err, "namespace", config.Namespace)
+		return
+	}
+	middleware.BuildJobCompletionRatioGauge.WithLabelValues(config.Namespace).Set(ratio.Ratio)
+	middleware.BuildJobsFinishedGauge.WithLabelValues(config.Namespace, "succeeded").Set(float64(ratio.Succeeded))
+	middleware.BuildJobsFinishedGauge.WithLabelValues(config.Namespace, "failed").Set(float64(ratio.Failed))
+}
+
 func (impl *ClusterCronServiceImpl) GetAndUpdateClusterConnectionStatus() {
 	impl.logger.Debug("starting cluster connection status fetch thread")
 	defer impl.logger.Debug("stopped cluster connection status fetch thread")
@@ -94,9 +191,28 @@ func (impl *ClusterCronServiceImpl) GetAndUpdateClusterConnectionStatus() {
 	}
 	wg.Wait()
 	impl.HandleErrorInClusterConnections(respMap)
+	impl.refreshClusterCapabilities(clusters, respMap)
 	return
 }
 
+// refreshClusterCapabilities re-reads the capability matrix of reachable clusters so that version upgrades are
+// picked up without waiting for the cache to expire
+func (impl *ClusterCronServiceImpl) refreshClusterCapabilities(clusters []*cluster.ClusterBean, respMap map[int]error) {
+	for _, clusterBean := range clusters {
+		if err, ok := respMap[clusterBean.Id]; !ok || err != nil {
+			continue
+		}
+		clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+		if err != nil {
+			impl.logger.Errorw("error in getting cluster config", "err", err, "clusterId", clusterBean.Id)
+			continue
+		}
+		if _, err = impl.K8sUtil.RefreshClusterCapabilities(context.Background(), clusterConfig); err != nil {
+			impl.logger.Errorw("error in refreshing cluster capabilities", "err", err, "clusterId", clusterBean.Id)
+		}
+	}
+}
+
 func GetAndUpdateConnectionStatusForOneCluster(k8sClientSet *kubernetes.Clientset, clusterId int, respMap map[int]error, wg *sync.WaitGroup, mutex *sync.Mutex) {
 	defer wg.Done()
 	//using livez path as healthz path is deprecated
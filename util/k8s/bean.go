@@ -1,6 +1,8 @@
 package k8s
 
 import (
+	"github.com/devtron-labs/devtron/internal/util"
+	batchV1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes"
@@ -17,6 +19,7 @@ type ClusterCapacityDetail struct {
 	ServerVersion     string                                `json:"serverVersion,omitempty"`
 	Cpu               *ResourceDetailObject                 `json:"cpu"`
 	Memory            *ResourceDetailObject                 `json:"memory"`
+	NodeProvisioning  *util.NodeProvisioningStatus          `json:"nodeProvisioning,omitempty"`
 }
 
 type NodeCapacityDetail struct {
@@ -116,3 +119,59 @@ type NodeDrainHelper struct {
 const DEFAULT_NAMESPACE = "default"
 const EVENT_K8S_KIND = "Event"
 const LIST_VERB = "list"
+
+const (
+	ConfigPropagationStatusCreated        = "created"
+	ConfigPropagationStatusUpdated        = "updated"
+	ConfigPropagationStatusDiff           = "diff"
+	ConfigPropagationStatusFailed         = "failed"
+	ConfigPropagationStatusSkipped        = "skipped"
+	ConfigPropagationStatusRolledBack     = "rolledBack"
+	ConfigPropagationStatusRollbackFailed = "rollbackFailed"
+)
+
+type ConfigPropagationRequest struct {
+	Source  ConfigPropagationSource   `json:"source"`
+	Targets []ConfigPropagationTarget `json:"targets"`
+	// DryRun returns the diff against every target without applying
+	DryRun bool `json:"dryRun"`
+	// Atomic reverts the targets already applied when any target fails, by default successful targets are kept
+	Atomic bool `json:"atomic"`
+}
+
+type ConfigPropagationSource struct {
+	ClusterId int    `json:"clusterId"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Kind is ConfigMap or Secret
+	Kind string `json:"kind"`
+}
+
+type ConfigPropagationTarget struct {
+	ClusterId int    `json:"clusterId"`
+	Namespace string `json:"namespace"`
+	// Overrides replace data keys of the source, values are go templates with .ClusterId, .ClusterName, .Namespace and
+	// .SourceNamespace, e.g. "https://{{.ClusterName}}.example.com"
+	Overrides map[string]string `json:"overrides,omitempty"`
+}
+
+type ConfigPropagationResult struct {
+	ClusterId int                `json:"clusterId"`
+	Namespace string             `json:"namespace"`
+	Status    string             `json:"status"`
+	Diff      *util.ManifestDiff `json:"diff,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	// Warning is set for secrets propagated to clusters which do not encrypt them at rest
+	Warning string `json:"warning,omitempty"`
+}
+
+type ConfigPropagationResponse struct {
+	Results    []*ConfigPropagationResult `json:"results"`
+	RolledBack bool                       `json:"rolledBack"`
+}
+
+// AppCronJob is a cronjob deployed for an app, LastRun is only set when it was requested and the cronjob ran before
+type AppCronJob struct {
+	CronJob *batchV1.CronJob `json:"cronJob"`
+	LastRun *util.CronJobRun `json:"lastRun,omitempty"`
+}
@@ -0,0 +1,350 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/devtron-labs/devtron/client/k8s/application"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/cluster"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	errors2 "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+const (
+	configPropagationFieldManager = "devtron-config-propagation"
+	// PropagatedFromAnnotation is set on every target as <clusterId>/<namespace>/<name> of the source
+	PropagatedFromAnnotation = "devtron.ai/propagated-from"
+)
+
+type configPropagationTemplateData struct {
+	ClusterId       int
+	ClusterName     string
+	Namespace       string
+	SourceNamespace string
+}
+
+// configPropagationClient is the part of K8sUtil which applies the targets and rolls them back
+type configPropagationClient interface {
+	GetResource(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string, clusterConfig *util.ClusterConfig) (*unstructured.Unstructured, error)
+	ApplyManifest(ctx context.Context, manifest *unstructured.Unstructured, clusterConfig *util.ClusterConfig, fieldManager string) (*util.AppliedResource, error)
+	DeleteResource(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string, clusterConfig *util.ClusterConfig) error
+}
+
+// configPropagationTarget is a target which passed the checks, previous is what was live before apply for rollback
+type configPropagationTarget struct {
+	result        *ConfigPropagationResult
+	clusterConfig *util.ClusterConfig
+	manifest      *unstructured.Unstructured
+	previous      *unstructured.Unstructured
+	created       bool
+	applied       bool
+}
+
+// PropagateConfig copies a ConfigMap or Secret to the targets, fanned out in batches of BATCH_SIZE. Neither the source
+// nor the rendered targets are ever logged as they can hold secret values
+func (impl *K8sApplicationServiceImpl) PropagateConfig(ctx context.Context, token string, request *ConfigPropagationRequest, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool) (*ConfigPropagationResponse, error) {
+	logger := util.LoggerFromContext(ctx, impl.logger)
+	source := request.Source
+	if source.Kind != "ConfigMap" && source.Kind != "Secret" {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: "only ConfigMap and Secret can be propagated"}
+	}
+	if source.Namespace == "" || source.Name == "" || len(request.Targets) == 0 {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: strconv.Itoa(http.StatusBadRequest), UserMessage: "source namespace, name and at least one target are required"}
+	}
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: source.Kind}
+	clusterBeans := make(map[int]*cluster.ClusterBean)
+	clusterConfigs := make(map[int]*util.ClusterConfig)
+	getCluster := func(clusterId int) (*cluster.ClusterBean, *util.ClusterConfig, error) {
+		if clusterBean, ok := clusterBeans[clusterId]; ok {
+			return clusterBean, clusterConfigs[clusterId], nil
+		}
+		clusterBean, err := impl.clusterService.FindById(clusterId)
+		if err != nil {
+			return nil, nil, err
+		}
+		clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+		if err != nil {
+			return nil, nil, err
+		}
+		clusterBeans[clusterId], clusterConfigs[clusterId] = clusterBean, clusterConfig
+		return clusterBean, clusterConfig, nil
+	}
+
+	sourceCluster, sourceClusterConfig, err := getCluster(source.ClusterId)
+	if err != nil {
+		logger.Errorw("error in getting source cluster", "err", err, "clusterId", source.ClusterId)
+		return nil, err
+	}
+	if !validateResourceAccess(token, sourceCluster.ClusterName, configResourceRequest(source.ClusterId, source.Namespace, source.Name, gvk), casbin.ActionGet) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusForbidden, Code: strconv.Itoa(http.StatusForbidden), UserMessage: "unauthorized"}
+	}
+	sourceObject, err := impl.K8sUtil.GetResource(ctx, gvk, source.Namespace, source.Name, sourceClusterConfig)
+	if err != nil {
+		logger.Errorw("error in getting propagation source", "err", err, "clusterId", source.ClusterId, "namespace", source.Namespace, "name", source.Name, "kind", source.Kind)
+		return nil, err
+	}
+
+	response := &ConfigPropagationResponse{Results: make([]*ConfigPropagationResult, 0, len(request.Targets))}
+	targets := make([]*configPropagationTarget, 0, len(request.Targets))
+	for _, target := range request.Targets {
+		result := &ConfigPropagationResult{ClusterId: target.ClusterId, Namespace: target.Namespace}
+		response.Results = append(response.Results, result)
+		if target.Namespace == "" {
+			result.Status, result.Error = ConfigPropagationStatusFailed, "namespace is required"
+			continue
+		}
+		if target.ClusterId == source.ClusterId && target.Namespace == source.Namespace {
+			result.Status, result.Error = ConfigPropagationStatusFailed, "target is the source"
+			continue
+		}
+		clusterBean, clusterConfig, err := getCluster(target.ClusterId)
+		if err != nil {
+			logger.Errorw("error in getting target cluster", "err", err, "clusterId", target.ClusterId)
+			result.Status, result.Error = ConfigPropagationStatusFailed, err.Error()
+			continue
+		}
+		if !validateResourceAccess(token, clusterBean.ClusterName, configResourceRequest(target.ClusterId, target.Namespace, source.Name, gvk), casbin.ActionUpdate) {
+			result.Status, result.Error = ConfigPropagationStatusFailed, "permission-denied"
+			continue
+		}
+		templateData := configPropagationTemplateData{ClusterId: target.ClusterId, ClusterName: clusterBean.ClusterName, Namespace: target.Namespace, SourceNamespace: source.Namespace}
+		manifest, err := buildPropagatedConfig(sourceObject, source, target, templateData)
+		if err != nil {
+			result.Status, result.Error = ConfigPropagationStatusFailed, err.Error()
+			continue
+		}
+		if !request.DryRun {
+			if err = impl.K8sUtil.CheckMutation(ctx, clusterConfig, target.Namespace, source.Kind, source.Name, util.MutationActionApply); err != nil {
+				result.Status, result.Error = ConfigPropagationStatusFailed, err.Error()
+				continue
+			}
+		}
+		if source.Kind == "Secret" {
+			result.Warning = impl.K8sUtil.SecretEncryptionWarning(ctx, clusterConfig)
+		}
+		targets = append(targets, &configPropagationTarget{result: result, clusterConfig: clusterConfig, manifest: manifest})
+	}
+	// atomic propagation does not touch any target when one of them can not be applied at all
+	if request.Atomic && !request.DryRun && len(targets) < len(request.Targets) {
+		for _, target := range targets {
+			target.result.Status = ConfigPropagationStatusSkipped
+		}
+		return response, nil
+	}
+
+	impl.runInBatches(len(targets), func(i int) {
+		target := targets[i]
+		if request.DryRun {
+			impl.diffPropagatedConfig(ctx, target)
+		} else {
+			impl.applyPropagatedConfig(ctx, impl.K8sUtil, target)
+		}
+	})
+	if !request.Atomic || request.DryRun {
+		return response, nil
+	}
+	response.RolledBack = impl.rollbackOnFailure(ctx, impl.K8sUtil, response.Results, targets)
+	return response, nil
+}
+
+// rollbackOnFailure rolls back every applied target when any result failed and tells if it did
+func (impl *K8sApplicationServiceImpl) rollbackOnFailure(ctx context.Context, client configPropagationClient, results []*ConfigPropagationResult, targets []*configPropagationTarget) bool {
+	failed := false
+	for _, result := range results {
+		if result.Status == ConfigPropagationStatusFailed {
+			failed = true
+			break
+		}
+	}
+	if !failed {
+		return false
+	}
+	impl.runInBatches(len(targets), func(i int) {
+		if targets[i].applied {
+			impl.rollbackPropagatedConfig(ctx, client, targets[i])
+		}
+	})
+	return true
+}
+
+// PropagateConfigAsync tracks the propagation as a cluster operation, only counts per status end up in the progress
+// message as the per target results can hold secret values
+func (impl *K8sApplicationServiceImpl) PropagateConfigAsync(ctx context.Context, token string, request *ConfigPropagationRequest, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool, userId int32) (int, error) {
+	source := request.Source
+	operation := &clusterOperation.ClusterOperationBean{
+		OperationType: clusterOperation.OperationTypeConfigPropagation,
+		ClusterId:     source.ClusterId,
+		Namespace:     source.Namespace,
+		ResourceName:  source.Kind + "/" + source.Name,
+		UserId:        userId,
+	}
+	return impl.clusterOperationService.Start(ctx, operation, func(ctx context.Context, progress clusterOperation.ProgressFunc) error {
+		progress(fmt.Sprintf("propagating to %d targets", len(request.Targets)))
+		response, err := impl.PropagateConfig(ctx, token, request, validateResourceAccess)
+		if err != nil {
+			return err
+		}
+		summary, failed := summarizeConfigPropagation(response)
+		if failed {
+			return errors.New(summary)
+		}
+		progress(summary)
+		return nil
+	})
+}
+
+func summarizeConfigPropagation(response *ConfigPropagationResponse) (string, bool) {
+	counts := make(map[string]int)
+	for _, result := range response.Results {
+		counts[result.Status]++
+	}
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	parts := make([]string, 0, len(statuses)+1)
+	for _, status := range statuses {
+		parts = append(parts, fmt.Sprintf("%s: %d", status, counts[status]))
+	}
+	if response.RolledBack {
+		parts = append(parts, "rolled back")
+	}
+	failed := counts[ConfigPropagationStatusFailed] > 0 || counts[ConfigPropagationStatusRollbackFailed] > 0
+	return strings.Join(parts, ", "), failed
+}
+
+func (impl *K8sApplicationServiceImpl) diffPropagatedConfig(ctx context.Context, target *configPropagationTarget) {
+	diff, err := impl.K8sUtil.DiffAgainstLive(ctx, target.clusterConfig, target.manifest)
+	if err != nil {
+		target.result.Status, target.result.Error = ConfigPropagationStatusFailed, err.Error()
+		return
+	}
+	target.result.Status, target.result.Diff = ConfigPropagationStatusDiff, diff
+}
+
+func (impl *K8sApplicationServiceImpl) applyPropagatedConfig(ctx context.Context, client configPropagationClient, target *configPropagationTarget) {
+	manifest := target.manifest
+	previous, err := client.GetResource(ctx, manifest.GroupVersionKind(), manifest.GetNamespace(), manifest.GetName(), target.clusterConfig)
+	if err != nil && !errors2.IsNotFound(err) {
+		target.result.Status, target.result.Error = ConfigPropagationStatusFailed, err.Error()
+		return
+	}
+	if err == nil {
+		target.previous = previous
+	}
+	appliedResource, err := client.ApplyManifest(ctx, manifest, target.clusterConfig, configPropagationFieldManager)
+	if err != nil {
+		target.result.Status, target.result.Error = ConfigPropagationStatusFailed, err.Error()
+		return
+	}
+	target.applied, target.created = true, appliedResource.Created
+	if appliedResource.Created {
+		target.result.Status = ConfigPropagationStatusCreated
+	} else {
+		target.result.Status = ConfigPropagationStatusUpdated
+	}
+}
+
+// rollbackPropagatedConfig deletes created targets and applies back what was live before for updated ones
+func (impl *K8sApplicationServiceImpl) rollbackPropagatedConfig(ctx context.Context, client configPropagationClient, target *configPropagationTarget) {
+	manifest := target.manifest
+	var err error
+	if target.created || target.previous == nil {
+		err = client.DeleteResource(ctx, manifest.GroupVersionKind(), manifest.GetNamespace(), manifest.GetName(), target.clusterConfig)
+	} else {
+		previous := target.previous.DeepCopy()
+		delete(previous.Object, "status")
+		for _, field := range []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "generation"} {
+			unstructured.RemoveNestedField(previous.Object, "metadata", field)
+		}
+		_, err = client.ApplyManifest(ctx, previous, target.clusterConfig, configPropagationFieldManager)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in rolling back propagated config", "err", err, "clusterId", target.result.ClusterId, "namespace", manifest.GetNamespace(), "name", manifest.GetName())
+		target.result.Status, target.result.Error = ConfigPropagationStatusRollbackFailed, err.Error()
+		return
+	}
+	target.result.Status = ConfigPropagationStatusRolledBack
+}
+
+func (impl *K8sApplicationServiceImpl) runInBatches(total int, fn func(i int)) {
+	batchSize := impl.K8sApplicationServiceConfig.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	for i := 0; i < total; i += batchSize {
+		var wg sync.WaitGroup
+		for j := i; j < total && j < i+batchSize; j++ {
+			wg.Add(1)
+			go func(j int) {
+				defer wg.Done()
+				fn(j)
+			}(j)
+		}
+		wg.Wait()
+	}
+}
+
+func configResourceRequest(clusterId int, namespace string, name string, gvk schema.GroupVersionKind) ResourceRequestBean {
+	return ResourceRequestBean{
+		ClusterId: clusterId,
+		K8sRequest: &application.K8sRequestBean{
+			ResourceIdentifier: application.ResourceIdentifier{Name: name, Namespace: namespace, GroupVersionKind: gvk},
+		},
+	}
+}
+
+// buildPropagatedConfig keeps the data, labels and type of the source, overrides are rendered and replace data keys
+func buildPropagatedConfig(sourceObject *unstructured.Unstructured, source ConfigPropagationSource, target ConfigPropagationTarget, templateData configPropagationTemplateData) (*unstructured.Unstructured, error) {
+	manifest := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	manifest.SetAPIVersion("v1")
+	manifest.SetKind(source.Kind)
+	manifest.SetNamespace(target.Namespace)
+	manifest.SetName(source.Name)
+	manifest.SetLabels(sourceObject.GetLabels())
+	manifest.SetAnnotations(map[string]string{PropagatedFromAnnotation: fmt.Sprintf("%d/%s/%s", source.ClusterId, source.Namespace, source.Name)})
+	for _, field := range []string{"data", "binaryData", "type", "immutable"} {
+		if value, ok := sourceObject.Object[field]; ok {
+			manifest.Object[field] = value
+		}
+	}
+	manifest = manifest.DeepCopy()
+	if len(target.Overrides) == 0 {
+		return manifest, nil
+	}
+	data, _, _ := unstructured.NestedMap(manifest.Object, "data")
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	for key, override := range target.Overrides {
+		// the error names the key only, the template text can be a secret value
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(override)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template in override %q", key)
+		}
+		var rendered bytes.Buffer
+		if err = tmpl.Execute(&rendered, templateData); err != nil {
+			return nil, fmt.Errorf("error in rendering override %q", key)
+		}
+		if source.Kind == "Secret" {
+			data[key] = base64.StdEncoding.EncodeToString(rendered.Bytes())
+		} else {
+			data[key] = rendered.String()
+		}
+	}
+	manifest.Object["data"] = data
+	return manifest, nil
+}
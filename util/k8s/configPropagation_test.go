@@ -0,0 +1,228 @@
+package k8s
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestBuildPropagatedConfig(t *testing.T) {
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":            "app-config",
+			"namespace":       "staging",
+			"resourceVersion": "42",
+			"labels":          map[string]interface{}{"team": "payments"},
+			"annotations":     map[string]interface{}{"owner": "payments"},
+		},
+		"data":      map[string]interface{}{"LOG_LEVEL": "info", "API_URL": "https://staging.example.com"},
+		"immutable": true,
+	}}
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "app-secret", "namespace": "staging"},
+		"type":       "Opaque",
+		"data":       map[string]interface{}{"PASSWORD": base64.StdEncoding.EncodeToString([]byte("hunter2"))},
+	}}
+	templateData := configPropagationTemplateData{ClusterId: 2, ClusterName: "prod-eu", Namespace: "payments", SourceNamespace: "staging"}
+	tests := []struct {
+		name         string
+		sourceObject *unstructured.Unstructured
+		source       ConfigPropagationSource
+		overrides    map[string]string
+		wantData     map[string]interface{}
+		wantErr      string
+	}{
+		{
+			name:         "copy without overrides",
+			sourceObject: configMap,
+			source:       ConfigPropagationSource{ClusterId: 1, Namespace: "staging", Name: "app-config", Kind: "ConfigMap"},
+			wantData:     map[string]interface{}{"LOG_LEVEL": "info", "API_URL": "https://staging.example.com"},
+		},
+		{
+			name:         "overrides are rendered",
+			sourceObject: configMap,
+			source:       ConfigPropagationSource{ClusterId: 1, Namespace: "staging", Name: "app-config", Kind: "ConfigMap"},
+			overrides:    map[string]string{"API_URL": "https://{{.ClusterName}}.example.com/{{.Namespace}}", "ORIGIN": "{{.SourceNamespace}}@{{.ClusterId}}"},
+			wantData:     map[string]interface{}{"LOG_LEVEL": "info", "API_URL": "https://prod-eu.example.com/payments", "ORIGIN": "staging@2"},
+		},
+		{
+			name:         "secret overrides are encoded",
+			sourceObject: secret,
+			source:       ConfigPropagationSource{ClusterId: 1, Namespace: "staging", Name: "app-secret", Kind: "Secret"},
+			overrides:    map[string]string{"DB_HOST": "db.{{.Namespace}}"},
+			wantData: map[string]interface{}{
+				"PASSWORD": base64.StdEncoding.EncodeToString([]byte("hunter2")),
+				"DB_HOST":  base64.StdEncoding.EncodeToString([]byte("db.payments")),
+			},
+		},
+		{
+			name:         "invalid template",
+			sourceObject: configMap,
+			source:       ConfigPropagationSource{ClusterId: 1, Namespace: "staging", Name: "app-config", Kind: "ConfigMap"},
+			overrides:    map[string]string{"API_URL": "{{.ClusterName"},
+			wantErr:      `invalid template in override "API_URL"`,
+		},
+		{
+			name:         "unknown template field",
+			sourceObject: configMap,
+			source:       ConfigPropagationSource{ClusterId: 1, Namespace: "staging", Name: "app-config", Kind: "ConfigMap"},
+			overrides:    map[string]string{"API_URL": "{{.Region}}"},
+			wantErr:      `error in rendering override "API_URL"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := ConfigPropagationTarget{ClusterId: 2, Namespace: "payments", Overrides: tt.overrides}
+			manifest, err := buildPropagatedConfig(tt.sourceObject, tt.source, target, templateData)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.source.Kind, manifest.GetKind())
+			assert.Equal(t, "payments", manifest.GetNamespace())
+			assert.Equal(t, tt.source.Name, manifest.GetName())
+			assert.Empty(t, manifest.GetResourceVersion())
+			assert.Equal(t, tt.sourceObject.GetLabels(), manifest.GetLabels())
+			// annotations of the source are not copied, only where the target came from is recorded
+			assert.Equal(t, map[string]string{PropagatedFromAnnotation: "1/staging/" + tt.source.Name}, manifest.GetAnnotations())
+			data, _, _ := unstructured.NestedMap(manifest.Object, "data")
+			assert.Equal(t, tt.wantData, data)
+		})
+	}
+	// the source is never changed by overrides
+	assert.Equal(t, "https://staging.example.com", configMap.Object["data"].(map[string]interface{})["API_URL"])
+}
+
+type configPropagationClientStub struct {
+	lock     sync.Mutex
+	live     map[string]*unstructured.Unstructured
+	applyErr map[string]error
+	applied  map[string][]*unstructured.Unstructured
+	deleted  []string
+}
+
+func newConfigPropagationClientStub() *configPropagationClientStub {
+	return &configPropagationClientStub{live: map[string]*unstructured.Unstructured{}, applyErr: map[string]error{}, applied: map[string][]*unstructured.Unstructured{}}
+}
+
+func (s *configPropagationClientStub) GetResource(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string, clusterConfig *util.ClusterConfig) (*unstructured.Unstructured, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if live, ok := s.live[namespace]; ok {
+		return live.DeepCopy(), nil
+	}
+	return nil, k8sErrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+}
+
+func (s *configPropagationClientStub) ApplyManifest(ctx context.Context, manifest *unstructured.Unstructured, clusterConfig *util.ClusterConfig, fieldManager string) (*util.AppliedResource, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	namespace := manifest.GetNamespace()
+	if err := s.applyErr[namespace]; err != nil {
+		return nil, err
+	}
+	s.applied[namespace] = append(s.applied[namespace], manifest)
+	_, exists := s.live[namespace]
+	return &util.AppliedResource{Kind: manifest.GetKind(), Namespace: namespace, Name: manifest.GetName(), Created: !exists}, nil
+}
+
+func (s *configPropagationClientStub) DeleteResource(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string, clusterConfig *util.ClusterConfig) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.deleted = append(s.deleted, namespace)
+	return s.applyErr["delete/"+namespace]
+}
+
+func newPropagationTargets(namespaces ...string) ([]*configPropagationTarget, []*ConfigPropagationResult) {
+	targets := make([]*configPropagationTarget, 0, len(namespaces))
+	results := make([]*ConfigPropagationResult, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		manifest := &unstructured.Unstructured{Object: map[string]interface{}{"data": map[string]interface{}{"LOG_LEVEL": "debug"}}}
+		manifest.SetAPIVersion("v1")
+		manifest.SetKind("ConfigMap")
+		manifest.SetNamespace(namespace)
+		manifest.SetName("app-config")
+		result := &ConfigPropagationResult{ClusterId: 2, Namespace: namespace}
+		targets = append(targets, &configPropagationTarget{result: result, clusterConfig: &util.ClusterConfig{}, manifest: manifest})
+		results = append(results, result)
+	}
+	return targets, results
+}
+
+func TestPropagatedConfigRollback(t *testing.T) {
+	previous := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "app-config", "namespace": "web", "resourceVersion": "7", "uid": "1234"},
+		"data":       map[string]interface{}{"LOG_LEVEL": "info"},
+	}}
+	tests := []struct {
+		name           string
+		applyErr       map[string]error
+		wantRolledBack bool
+		wantStatus     map[string]string
+		wantDeleted    []string
+	}{
+		{
+			name:       "all applied",
+			wantStatus: map[string]string{"web": ConfigPropagationStatusUpdated, "jobs": ConfigPropagationStatusCreated, "batch": ConfigPropagationStatusCreated},
+		},
+		{
+			name:           "target write fails",
+			applyErr:       map[string]error{"batch": errors.New("admission webhook denied the request")},
+			wantRolledBack: true,
+			wantStatus:     map[string]string{"web": ConfigPropagationStatusRolledBack, "jobs": ConfigPropagationStatusRolledBack, "batch": ConfigPropagationStatusFailed},
+			wantDeleted:    []string{"jobs"},
+		},
+		{
+			name:           "rollback fails",
+			applyErr:       map[string]error{"batch": errors.New("admission webhook denied the request"), "delete/jobs": errors.New("connection reset")},
+			wantRolledBack: true,
+			wantStatus:     map[string]string{"web": ConfigPropagationStatusRolledBack, "jobs": ConfigPropagationStatusRollbackFailed, "batch": ConfigPropagationStatusFailed},
+			wantDeleted:    []string{"jobs"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newConfigPropagationClientStub()
+			client.live["web"] = previous
+			for key, err := range tt.applyErr {
+				client.applyErr[key] = err
+			}
+			impl := &K8sApplicationServiceImpl{logger: zap.NewNop().Sugar(), K8sApplicationServiceConfig: &K8sApplicationServiceConfig{BatchSize: 2}}
+			targets, results := newPropagationTargets("web", "jobs", "batch")
+			impl.runInBatches(len(targets), func(i int) {
+				impl.applyPropagatedConfig(context.Background(), client, targets[i])
+			})
+			rolledBack := impl.rollbackOnFailure(context.Background(), client, results, targets)
+			assert.Equal(t, tt.wantRolledBack, rolledBack)
+			for _, result := range results {
+				assert.Equal(t, tt.wantStatus[result.Namespace], result.Status, result.Namespace)
+			}
+			assert.Equal(t, tt.wantDeleted, client.deleted)
+			if !tt.wantRolledBack {
+				return
+			}
+			// the updated target gets back what was live before, without the fields owned by the api server
+			assert.Len(t, client.applied["web"], 2)
+			restored := client.applied["web"][1]
+			assert.Equal(t, map[string]interface{}{"LOG_LEVEL": "info"}, restored.Object["data"])
+			assert.Empty(t, restored.GetResourceVersion())
+			assert.Empty(t, string(restored.GetUID()))
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/devtron-labs/devtron/api/restHandler/common"
 	"github.com/devtron-labs/devtron/client/k8s/application"
 	util2 "github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
 	"github.com/devtron-labs/devtron/pkg/terminal"
 	"github.com/devtron-labs/devtron/pkg/user"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
@@ -26,6 +27,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type K8sApplicationRestHandler interface {
@@ -40,7 +42,9 @@ type K8sApplicationRestHandler interface {
 	GetHostUrlsByBatch(w http.ResponseWriter, r *http.Request)
 	GetAllApiResources(w http.ResponseWriter, r *http.Request)
 	GetResourceList(w http.ResponseWriter, r *http.Request)
+	SearchResources(w http.ResponseWriter, r *http.Request)
 	ApplyResources(w http.ResponseWriter, r *http.Request)
+	PropagateConfig(w http.ResponseWriter, r *http.Request)
 }
 
 type K8sApplicationRestHandlerImpl struct {
@@ -698,18 +702,88 @@ func (handler *K8sApplicationRestHandlerImpl) GetResourceList(w http.ResponseWri
 		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
 		return
 	}
-	response, err := handler.k8sApplicationService.GetResourceList(r.Context(), token, &request, handler.verifyRbacForCluster)
-	if err != nil {
+	if exportFormat := r.URL.Query().Get("export"); exportFormat != "" {
+		handler.exportResourceList(w, r, token, &request, exportFormat)
+		return
+	}
+	if request.K8sRequest == nil {
+		common.WriteJsonResp(w, errors.New("k8sRequest is required"), nil, http.StatusBadRequest)
+		return
+	}
+	// pages are written as they are listed, the request context stops the listing when the client goes away
+	writer := NewResourceListStreamWriter(w, r.Header.Get("Accept") == ResourceListNdJsonContentType)
+	err = handler.k8sApplicationService.ListResourcePages(r.Context(), token, &request, handler.verifyRbacForCluster, writer.WritePage)
+	if err != nil && !writer.Started() {
 		handler.logger.Errorw("error in getting resource list", "err", err)
 		if statusErr, ok := err.(*errors3.StatusError); ok && statusErr.Status().Code == 404 {
 			err = &util2.ApiError{Code: "404", HttpStatusCode: 404, UserMessage: "no resource found", InternalMessage: err.Error()}
 		}
-		common.WriteJsonResp(w, err, response, http.StatusInternalServerError)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	if err = writer.Finish(err); err != nil {
+		handler.logger.Errorw("error in finishing resource list", "err", err, "clusterId", request.ClusterId, "rows", writer.Total())
+	}
+}
+
+func (handler *K8sApplicationRestHandlerImpl) SearchResources(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	token := r.Header.Get("token")
+	var request ResourceSearchRequest
+	err := decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("error in decoding request body", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if request.K8sRequest == nil {
+		common.WriteJsonResp(w, errors.New("k8sRequest is required"), nil, http.StatusBadRequest)
+		return
+	}
+	if len(strings.TrimSpace(request.Query)) == 0 {
+		common.WriteJsonResp(w, errors.New("query is required"), nil, http.StatusBadRequest)
+		return
+	}
+	response, err := handler.k8sApplicationService.SearchResources(r.Context(), token, &request, handler.verifyRbacForCluster)
+	if err != nil {
+		handler.logger.Errorw("error in searching resources", "err", err, "clusterId", request.ClusterId, "query", request.Query)
+		if statusErr, ok := err.(*errors3.StatusError); ok && statusErr.Status().Code == 404 {
+			err = &util2.ApiError{Code: "404", HttpStatusCode: 404, UserMessage: "no resource found", InternalMessage: err.Error()}
+		}
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
 	}
 	common.WriteJsonResp(w, nil, response, http.StatusOK)
 }
 
+// exportResourceList streams the listing as a file download, once the first chunk is written the status can not change
+// anymore so later failures are reported at the end of the file instead
+func (handler *K8sApplicationRestHandlerImpl) exportResourceList(w http.ResponseWriter, r *http.Request, token string, request *ResourceRequestBean, exportFormat string) {
+	if !IsValidResourceExportFormat(exportFormat) {
+		common.WriteJsonResp(w, fmt.Errorf("unsupported export format %q, supported formats are csv and json", exportFormat), nil, http.StatusBadRequest)
+		return
+	}
+	if request.K8sRequest == nil {
+		common.WriteJsonResp(w, errors.New("k8sRequest is required"), nil, http.StatusBadRequest)
+		return
+	}
+	resourceIdentifier := request.K8sRequest.ResourceIdentifier
+	fileName := ResourceExportFileName(resourceIdentifier.GroupVersionKind.Kind, resourceIdentifier.Namespace, exportFormat, time.Now())
+	writer := NewResourceListRowWriter(w, exportFormat, fileName)
+	summary, err := handler.k8sApplicationService.ExportResourceList(r.Context(), token, request, handler.verifyRbacForCluster, writer)
+	if err != nil && !writer.Started() {
+		handler.logger.Errorw("error in exporting resource list", "err", err, "clusterId", request.ClusterId)
+		if statusErr, ok := err.(*errors3.StatusError); ok && statusErr.Status().Code == 404 {
+			err = &util2.ApiError{Code: "404", HttpStatusCode: 404, UserMessage: "no resource found", InternalMessage: err.Error()}
+		}
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	if err = writer.Finish(summary, err); err != nil {
+		handler.logger.Errorw("error in finishing resource list export", "err", err, "clusterId", request.ClusterId, "rows", summary.Rows)
+	}
+}
+
 func (handler *K8sApplicationRestHandlerImpl) ApplyResources(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
 	var request application.ApplyResourcesRequest
@@ -730,6 +804,40 @@ func (handler *K8sApplicationRestHandlerImpl) ApplyResources(w http.ResponseWrit
 	common.WriteJsonResp(w, nil, response, http.StatusOK)
 }
 
+func (handler *K8sApplicationRestHandlerImpl) PropagateConfig(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	var request ConfigPropagationRequest
+	token := r.Header.Get("token")
+	err := decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("error in decoding request body", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if !request.DryRun {
+		userId, err := handler.userService.GetLoggedInUser(r)
+		if userId == 0 || err != nil {
+			common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+			return
+		}
+		operationId, err := handler.k8sApplicationService.PropagateConfigAsync(r.Context(), token, &request, handler.verifyRbacForCluster, userId)
+		if err != nil {
+			handler.logger.Errorw("error in starting config propagation", "err", err, "clusterId", request.Source.ClusterId, "namespace", request.Source.Namespace, "name", request.Source.Name)
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		common.WriteJsonResp(w, nil, &clusterOperation.ClusterOperationStartedResponse{OperationId: operationId}, http.StatusAccepted)
+		return
+	}
+	response, err := handler.k8sApplicationService.PropagateConfig(r.Context(), token, &request, handler.verifyRbacForCluster)
+	if err != nil {
+		handler.logger.Errorw("error in propagating config", "err", err, "clusterId", request.Source.ClusterId, "namespace", request.Source.Namespace, "name", request.Source.Name)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
+
 func (handler *K8sApplicationRestHandlerImpl) getRbacCallbackForResource(token string, casbinAction string) func(clusterName string, resourceIdentifier application.ResourceIdentifier) bool {
 	return func(clusterName string, resourceIdentifier application.ResourceIdentifier) bool {
 		return handler.verifyRbacForResource(token, clusterName, resourceIdentifier, casbinAction)
@@ -63,6 +63,12 @@ func (impl *K8sApplicationRouterImpl) InitK8sApplicationRouter(k8sAppRouter *mux
 	k8sAppRouter.Path("/resource/list").
 		HandlerFunc(impl.k8sApplicationRestHandler.GetResourceList).Methods("POST")
 
+	k8sAppRouter.Path("/resource/search").
+		HandlerFunc(impl.k8sApplicationRestHandler.SearchResources).Methods("POST")
+
 	k8sAppRouter.Path("/resources/apply").
 		HandlerFunc(impl.k8sApplicationRestHandler.ApplyResources).Methods("POST")
+
+	k8sAppRouter.Path("/config/propagate").
+		HandlerFunc(impl.k8sApplicationRestHandler.PropagateConfig).Methods("POST")
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
 	"github.com/caarlos0/env"
 	"github.com/devtron-labs/devtron/api/bean"
 	"github.com/devtron-labs/devtron/api/connector"
@@ -12,6 +13,7 @@ import (
 	"github.com/devtron-labs/devtron/client/k8s/application"
 	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/cluster"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
 	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
 	util3 "github.com/devtron-labs/devtron/pkg/util"
@@ -19,11 +21,14 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 	"io"
+	authorizationV1 "k8s.io/api/authorization/v1"
 	errors2 "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
@@ -53,7 +58,36 @@ type K8sApplicationService interface {
 	GetUrlsByBatch(ctx context.Context, resp []BatchResourceResponse) []interface{}
 	GetAllApiResources(ctx context.Context, clusterId int, isSuperAdmin bool, userId int32) (*application.GetAllApiResourcesResponse, error)
 	GetResourceList(ctx context.Context, token string, request *ResourceRequestBean, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool) (*util.ClusterResourceListMap, error)
+	// ListResourcePages calls onPage with the rows of every page of the listing as soon as the page is fetched
+	ListResourcePages(ctx context.Context, token string, request *ResourceRequestBean, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool, onPage func(page *util.ClusterResourceListMap) error) error
+	// SearchResources lists a kind across all namespaces of the cluster and keeps the resources whose name contains
+	// the query, the scan stops at RESOURCE_SEARCH_SCAN_LIMIT resources or RESOURCE_SEARCH_TIMEOUT_IN_SECONDS
+	SearchResources(ctx context.Context, token string, request *ResourceSearchRequest, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool) (*ResourceSearchResponse, error)
+	ExportResourceList(ctx context.Context, token string, request *ResourceRequestBean, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool, writer ResourceListRowWriter) (*ResourceListExportSummary, error)
 	ApplyResources(ctx context.Context, token string, request *application.ApplyResourcesRequest, resourceRbacHandler func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool) ([]*application.ApplyResourcesResponse, error)
+	PropagateConfig(ctx context.Context, token string, request *ConfigPropagationRequest, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool) (*ConfigPropagationResponse, error)
+	// PropagateConfigAsync runs PropagateConfig in the background and returns the id of the tracking operation
+	PropagateConfigAsync(ctx context.Context, token string, request *ConfigPropagationRequest, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool, userId int32) (int, error)
+	GetRolloutStatus(ctx context.Context, clusterId int, namespace string, name string) (*util.RolloutStatus, error)
+	FindAppRolloutName(ctx context.Context, clusterId int, namespace string, appId int, envId int) (string, error)
+	PauseRollout(ctx context.Context, clusterId int, namespace string, name string) error
+	ResumeRollout(ctx context.Context, clusterId int, namespace string, name string) error
+	PromoteRollout(ctx context.Context, clusterId int, namespace string, name string, fullPromotion bool) error
+	AbortRollout(ctx context.Context, clusterId int, namespace string, name string) error
+	GetRolloutRevisionHistory(ctx context.Context, clusterId int, namespace string, name string) ([]util.RolloutRevision, error)
+	ListAppCronJobs(ctx context.Context, clusterId int, namespace string, appId int, envId int, labelSelector string, withLastRun bool) ([]*AppCronJob, error)
+	SetAppCronJobSuspended(ctx context.Context, clusterId int, namespace string, appId int, envId int, name string, suspended bool) error
+	// DeleteAppPod, RestartAppWorkload and ScaleAppWorkload only act on resources carrying the app and environment
+	// labels, they are checked against freeze windows and written to the resource history with the user of ctx
+	DeleteAppPod(ctx context.Context, clusterId int, namespace string, appId int, envId int, name string, gracePeriodSeconds *int64, force bool) (*util.PodState, error)
+	RestartAppWorkload(ctx context.Context, clusterId int, namespace string, appId int, envId int, kind string, name string) (*util.WorkloadState, error)
+	ScaleAppWorkload(ctx context.Context, clusterId int, namespace string, appId int, envId int, kind string, name string, replicas int32) (*util.WorkloadState, error)
+	// SnapshotAppPvc takes a volume snapshot of a pvc mounted by the pods of the app in the environment
+	SnapshotAppPvc(ctx context.Context, clusterId int, namespace string, appId int, envId int, pvcName string, snapshotClassName string) (*util.VolumeSnapshotInfo, error)
+	GetClusterCapabilities(ctx context.Context, clusterId int) (*util.ClusterCapabilities, error)
+	GetNodeDetail(ctx context.Context, clusterId int, nodeName string) (*util.NodeDetail, error)
+	// ReviewSelfAccess tells which of the actions devtron may perform on the cluster
+	ReviewSelfAccess(ctx context.Context, clusterId int, attributes []authorizationV1.ResourceAttributes) ([]util.AccessReviewResult, error)
 }
 type K8sApplicationServiceImpl struct {
 	logger                      *zap.SugaredLogger
@@ -65,18 +99,25 @@ type K8sApplicationServiceImpl struct {
 	aCDAuthConfig               *util3.ACDAuthConfig
 	K8sApplicationServiceConfig *K8sApplicationServiceConfig
 	K8sResourceHistoryService   kubernetesResourceAuditLogs.K8sResourceHistoryService
+	clusterOperationService     clusterOperation.ClusterOperationService
 }
 
 type K8sApplicationServiceConfig struct {
-	BatchSize        int `env:"BATCH_SIZE" envDefault:"5"`
-	TimeOutInSeconds int `env:"TIMEOUT_IN_SECONDS" envDefault:"5"`
+	BatchSize               int   `env:"BATCH_SIZE" envDefault:"5"`
+	TimeOutInSeconds        int   `env:"TIMEOUT_IN_SECONDS" envDefault:"5"`
+	ResourceExportMaxRows   int   `env:"RESOURCE_EXPORT_MAX_ROWS" envDefault:"10000"`
+	ResourceExportChunkSize int64 `env:"RESOURCE_EXPORT_CHUNK_SIZE" envDefault:"500"`
+	ResourceListPageSize    int64 `env:"RESOURCE_LIST_PAGE_SIZE" envDefault:"500"`
+	ResourceSearchScanLimit int   `env:"RESOURCE_SEARCH_SCAN_LIMIT" envDefault:"5000"`
+	ResourceSearchTimeout   int   `env:"RESOURCE_SEARCH_TIMEOUT_IN_SECONDS" envDefault:"30"`
 }
 
 func NewK8sApplicationServiceImpl(Logger *zap.SugaredLogger,
 	clusterService cluster.ClusterService,
 	pump connector.Pump, k8sClientService application.K8sClientService,
 	helmAppService client.HelmAppService, K8sUtil *util.K8sUtil, aCDAuthConfig *util3.ACDAuthConfig,
-	K8sResourceHistoryService kubernetesResourceAuditLogs.K8sResourceHistoryService) *K8sApplicationServiceImpl {
+	K8sResourceHistoryService kubernetesResourceAuditLogs.K8sResourceHistoryService,
+	clusterOperationService clusterOperation.ClusterOperationService) *K8sApplicationServiceImpl {
 	cfg := &K8sApplicationServiceConfig{}
 	err := env.Parse(cfg)
 	if err != nil {
@@ -92,6 +133,7 @@ func NewK8sApplicationServiceImpl(Logger *zap.SugaredLogger,
 		aCDAuthConfig:               aCDAuthConfig,
 		K8sApplicationServiceConfig: cfg,
 		K8sResourceHistoryService:   K8sResourceHistoryService,
+		clusterOperationService:     clusterOperationService,
 	}
 }
 
@@ -100,6 +142,8 @@ type ResourceRequestBean struct {
 	AppIdentifier *client.AppIdentifier       `json:"-"`
 	K8sRequest    *application.K8sRequestBean `json:"k8sRequest"`
 	ClusterId     int                         `json:"clusterId"` // clusterId is used when request is for direct cluster (not for helm release)
+	// ForceDelete deletes a ConfigMap or Secret even if workloads still use it
+	ForceDelete bool `json:"forceDelete,omitempty"`
 }
 
 type ResourceInfo struct {
@@ -297,6 +341,9 @@ func (impl *K8sApplicationServiceImpl) getManifestsByBatch(ctx context.Context,
 
 func (impl *K8sApplicationServiceImpl) GetResource(ctx context.Context, request *ResourceRequestBean) (*application.ManifestResponse, error) {
 	clusterId := request.ClusterId
+	if err := impl.checkNamespaceAccess(ctx, clusterId, request.K8sRequest.ResourceIdentifier.Namespace, false); err != nil {
+		return nil, err
+	}
 	//getting rest config by clusterId
 	restConfig, err := impl.GetRestConfigByClusterId(ctx, clusterId)
 	if err != nil {
@@ -330,6 +377,9 @@ func (impl *K8sApplicationServiceImpl) CreateResource(ctx context.Context, reque
 		return nil, fmt.Errorf("no manifest found for this request")
 	}
 
+	if err = impl.checkNamespaceAccess(ctx, request.AppIdentifier.ClusterId, request.K8sRequest.ResourceIdentifier.Namespace, true); err != nil {
+		return nil, err
+	}
 	//getting rest config by clusterId
 	restConfig, err := impl.GetRestConfigByClusterId(ctx, request.AppIdentifier.ClusterId)
 	if err != nil {
@@ -347,6 +397,9 @@ func (impl *K8sApplicationServiceImpl) CreateResource(ctx context.Context, reque
 func (impl *K8sApplicationServiceImpl) UpdateResource(ctx context.Context, request *ResourceRequestBean) (*application.ManifestResponse, error) {
 	//getting rest config by clusterId
 	clusterId := request.ClusterId
+	if err := impl.checkNamespaceAccess(ctx, clusterId, request.K8sRequest.ResourceIdentifier.Namespace, true); err != nil {
+		return nil, err
+	}
 	restConfig, err := impl.GetRestConfigByClusterId(ctx, clusterId)
 	if err != nil {
 		impl.logger.Errorw("error in getting rest config by cluster Id", "err", err, "clusterId", clusterId)
@@ -363,11 +416,20 @@ func (impl *K8sApplicationServiceImpl) UpdateResource(ctx context.Context, reque
 func (impl *K8sApplicationServiceImpl) DeleteResource(ctx context.Context, request *ResourceRequestBean, userId int32) (*application.ManifestResponse, error) {
 	//getting rest config by clusterId
 	clusterId := request.ClusterId
+	if err := impl.checkNamespaceAccess(ctx, clusterId, request.K8sRequest.ResourceIdentifier.Namespace, true); err != nil {
+		return nil, err
+	}
 	restConfig, err := impl.GetRestConfigByClusterId(ctx, clusterId)
 	if err != nil {
 		impl.logger.Errorw("error in getting rest config by cluster Id", "err", err, "clusterId", request.AppIdentifier.ClusterId)
 		return nil, err
 	}
+	if !request.ForceDelete {
+		err = impl.checkConfigNotInUse(ctx, clusterId, request.K8sRequest.ResourceIdentifier)
+		if err != nil {
+			return nil, err
+		}
+	}
 	resp, err := impl.k8sClientService.DeleteResource(ctx, restConfig, request.K8sRequest)
 	if err != nil {
 		impl.logger.Errorw("error in deleting resource", "err", err, "request", request)
@@ -382,8 +444,37 @@ func (impl *K8sApplicationServiceImpl) DeleteResource(ctx context.Context, reque
 	return resp, nil
 }
 
+// checkConfigNotInUse returns a conflict listing the dependents when a ConfigMap or Secret is still used in the namespace
+func (impl *K8sApplicationServiceImpl) checkConfigNotInUse(ctx context.Context, clusterId int, resourceIdentifier application.ResourceIdentifier) error {
+	gvk := resourceIdentifier.GroupVersionKind
+	if gvk.Group != "" || (gvk.Kind != util.ConfigMapKind && gvk.Kind != util.SecretKind) {
+		return nil
+	}
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return err
+	}
+	references, err := impl.K8sUtil.FindConfigReferences(ctx, clusterConfig, resourceIdentifier.Namespace, gvk.Kind, resourceIdentifier.Name, true)
+	if err != nil {
+		impl.logger.Errorw("error in finding config references", "err", err, "clusterId", clusterId, "kind", gvk.Kind, "namespace", resourceIdentifier.Namespace, "name", resourceIdentifier.Name)
+		return err
+	}
+	if len(references) == 0 {
+		return nil
+	}
+	dependents := make([]string, 0, len(references))
+	for _, reference := range references {
+		dependents = append(dependents, reference.String())
+	}
+	message := fmt.Sprintf("%s %s is used by %d workloads, set forceDelete to delete it anyway", gvk.Kind, resourceIdentifier.Name, len(references))
+	return &util.ApiError{HttpStatusCode: http.StatusConflict, Code: strconv.Itoa(http.StatusConflict), UserMessage: message, InternalMessage: message, Details: dependents}
+}
+
 func (impl *K8sApplicationServiceImpl) ListEvents(ctx context.Context, request *ResourceRequestBean) (*application.EventsResponse, error) {
 	clusterId := request.ClusterId
+	if err := impl.checkNamespaceAccess(ctx, clusterId, request.K8sRequest.ResourceIdentifier.Namespace, false); err != nil {
+		return nil, err
+	}
 	//getting rest config by clusterId
 	restConfig, err := impl.GetRestConfigByClusterId(ctx, clusterId)
 	if err != nil {
@@ -400,6 +491,9 @@ func (impl *K8sApplicationServiceImpl) ListEvents(ctx context.Context, request *
 
 func (impl *K8sApplicationServiceImpl) GetPodLogs(ctx context.Context, request *ResourceRequestBean) (io.ReadCloser, error) {
 	clusterId := request.ClusterId
+	if err := impl.checkNamespaceAccess(ctx, clusterId, request.K8sRequest.ResourceIdentifier.Namespace, false); err != nil {
+		return nil, err
+	}
 	//getting rest config by clusterId
 	restConfig, err := impl.GetRestConfigByClusterId(ctx, clusterId)
 	if err != nil {
@@ -670,27 +764,182 @@ func (impl *K8sApplicationServiceImpl) GetResourceList(ctx context.Context, toke
 		return resourceList, err
 	}
 	k8sRequest := request.K8sRequest
+	gvk := k8sRequest.ResourceIdentifier.GroupVersionKind
 	resp, namespaced, err := impl.k8sClientService.GetResourceList(ctx, restConfig, k8sRequest)
 	if err != nil {
 		impl.logger.Errorw("error in getting resource list", "err", err, "request", request)
 		return resourceList, err
 	}
-	checkForResourceCallback := func(namespace, group, kind, resourceName string) bool {
-		resourceIdentifier := k8sRequest.ResourceIdentifier
+	checkForResourceCallback := impl.getResourceListRbacCallback(token, clusterBean.ClusterName, request, validateResourceAccess)
+	resourceList, err = impl.K8sUtil.BuildK8sObjectListTableData(&resp.Resources, namespaced, gvk, checkForResourceCallback)
+	if err != nil {
+		impl.logger.Errorw("error on parsing for k8s resource", "err", err)
+		return resourceList, err
+	}
+	return resourceList, nil
+}
+
+// getResourceListRbacCallback checks access for every row, it overwrites the identifier of a copy of the request so
+// that the filters of the caller's request are left untouched for the next chunk
+func (impl *K8sApplicationServiceImpl) getResourceListRbacCallback(token string, clusterName string, request *ResourceRequestBean, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool) func(namespace, group, kind, resourceName string) bool {
+	k8sRequest := *request.K8sRequest
+	rbacRequest := *request
+	rbacRequest.K8sRequest = &k8sRequest
+	listIdentifier := request.K8sRequest.ResourceIdentifier
+	return func(namespace, group, kind, resourceName string) bool {
+		resourceIdentifier := listIdentifier
 		resourceIdentifier.Name = resourceName
 		resourceIdentifier.Namespace = namespace
 		if group != "" && kind != "" {
 			resourceIdentifier.GroupVersionKind = schema.GroupVersionKind{Group: group, Kind: kind}
 		}
 		k8sRequest.ResourceIdentifier = resourceIdentifier
-		return validateResourceAccess(token, clusterBean.ClusterName, *request, casbin.ActionGet)
+		return validateResourceAccess(token, clusterName, rbacRequest, casbin.ActionGet)
+	}
+}
+
+// ListResourcePages pages through the listing with the same filters and rbac as GetResourceList, listing stops with
+// the error of onPage or once ctx is done
+func (impl *K8sApplicationServiceImpl) ListResourcePages(ctx context.Context, token string, request *ResourceRequestBean, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool, onPage func(page *util.ClusterResourceListMap) error) error {
+	pageSize := impl.K8sApplicationServiceConfig.ResourceListPageSize
+	if pageSize <= 0 {
+		pageSize = defaultResourceListPageSize
 	}
-	resourceList, err = impl.K8sUtil.BuildK8sObjectListTableData(&resp.Resources, namespaced, request.K8sRequest.ResourceIdentifier.GroupVersionKind, checkForResourceCallback)
+	clusterBean, err := impl.clusterService.FindById(request.ClusterId)
 	if err != nil {
-		impl.logger.Errorw("error on parsing for k8s resource", "err", err)
-		return resourceList, err
+		impl.logger.Errorw("error in getting cluster by cluster Id", "err", err, "clusterId", request.ClusterId)
+		return err
 	}
-	return resourceList, nil
+	restConfig, err := impl.GetRestConfigByCluster(ctx, clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting rest config by cluster Id", "err", err, "clusterId", request.ClusterId)
+		return err
+	}
+	gvk := request.K8sRequest.ResourceIdentifier.GroupVersionKind
+	checkForResourceCallback := impl.getResourceListRbacCallback(token, clusterBean.ClusterName, request, validateResourceAccess)
+	err = impl.k8sClientService.ListResourcesInChunks(ctx, restConfig, request.K8sRequest, pageSize, func(resp *application.ResourceListResponse, namespaced bool) (bool, error) {
+		page, err := impl.K8sUtil.BuildK8sObjectListTableData(&resp.Resources, namespaced, gvk, checkForResourceCallback)
+		if err != nil {
+			impl.logger.Errorw("error on parsing for k8s resource", "err", err)
+			return false, err
+		}
+		if err = onPage(page); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+	if err != nil {
+		impl.logger.Errorw("error in listing resource pages", "err", err, "clusterId", request.ClusterId, "gvk", gvk)
+		return err
+	}
+	return nil
+}
+
+// ExportResourceList streams the same rows as GetResourceList to writer chunk by chunk, with the same filters and
+// rbac, and stops once RESOURCE_EXPORT_MAX_ROWS rows are written
+func (impl *K8sApplicationServiceImpl) ExportResourceList(ctx context.Context, token string, request *ResourceRequestBean, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool, writer ResourceListRowWriter) (*ResourceListExportSummary, error) {
+	maxRows, chunkSize := resourceExportLimits(impl.K8sApplicationServiceConfig)
+	summary := &ResourceListExportSummary{MaxRows: maxRows}
+	clusterBean, err := impl.clusterService.FindById(request.ClusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster by cluster Id", "err", err, "clusterId", request.ClusterId)
+		return summary, err
+	}
+	restConfig, err := impl.GetRestConfigByCluster(ctx, clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting rest config by cluster Id", "err", err, "clusterId", request.ClusterId)
+		return summary, err
+	}
+	gvk := request.K8sRequest.ResourceIdentifier.GroupVersionKind
+	checkForResourceCallback := impl.getResourceListRbacCallback(token, clusterBean.ClusterName, request, validateResourceAccess)
+	headersWritten := false
+	err = impl.k8sClientService.ListResourcesInChunks(ctx, restConfig, request.K8sRequest, chunkSize, func(resp *application.ResourceListResponse, namespaced bool) (bool, error) {
+		chunk, err := impl.K8sUtil.BuildK8sObjectListTableData(&resp.Resources, namespaced, gvk, checkForResourceCallback)
+		if err != nil {
+			impl.logger.Errorw("error on parsing for k8s resource", "err", err)
+			return false, err
+		}
+		if !headersWritten {
+			if err = writer.WriteHeaders(chunk.Headers); err != nil {
+				return false, err
+			}
+			headersWritten = true
+		}
+		return writeResourceExportChunk(writer, summary, chunk.Data, resp.Resources.GetContinue() != "")
+	})
+	if err != nil {
+		impl.logger.Errorw("error in exporting resource list", "err", err, "clusterId", request.ClusterId, "gvk", gvk, "rows", summary.Rows)
+		return summary, err
+	}
+	return summary, nil
+}
+
+func (impl *K8sApplicationServiceImpl) SearchResources(ctx context.Context, token string, request *ResourceSearchRequest, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool) (*ResourceSearchResponse, error) {
+	scanLimit := impl.K8sApplicationServiceConfig.ResourceSearchScanLimit
+	if scanLimit <= 0 {
+		scanLimit = defaultResourceSearchScanLimit
+	}
+	timeout := time.Duration(impl.K8sApplicationServiceConfig.ResourceSearchTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultResourceSearchTimeout
+	}
+	pageSize := impl.K8sApplicationServiceConfig.ResourceListPageSize
+	if pageSize <= 0 {
+		pageSize = defaultResourceListPageSize
+	}
+	response := &ResourceSearchResponse{Data: make([]map[string]interface{}, 0), ScanLimit: scanLimit}
+	clusterBean, err := impl.clusterService.FindById(request.ClusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster by cluster Id", "err", err, "clusterId", request.ClusterId)
+		return nil, err
+	}
+	restConfig, err := impl.GetRestConfigByCluster(ctx, clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting rest config by cluster Id", "err", err, "clusterId", request.ClusterId)
+		return nil, err
+	}
+	// one cluster wide listing, the rbac callback drops the rows of namespaces the user can not view
+	k8sRequest := *request.K8sRequest
+	k8sRequest.ResourceIdentifier.Namespace = ""
+	k8sRequest.ResourceIdentifier.Name = ""
+	if request.ExactMatch {
+		k8sRequest.FieldSelector = fields.OneTermEqualSelector("metadata.name", request.Query).String()
+	}
+	listRequest := request.ResourceRequestBean
+	listRequest.K8sRequest = &k8sRequest
+	gvk := k8sRequest.ResourceIdentifier.GroupVersionKind
+	checkForResourceCallback := impl.getResourceListRbacCallback(token, clusterBean.ClusterName, &listRequest, validateResourceAccess)
+	searchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err = impl.k8sClientService.ListResourcesInChunks(searchCtx, restConfig, &k8sRequest, pageSize, func(resp *application.ResourceListResponse, namespaced bool) (bool, error) {
+		scanned, limitReached := filterResourceRowsByName(&resp.Resources, request.Query, scanLimit-response.Scanned)
+		response.Scanned += scanned
+		page, err := impl.K8sUtil.BuildK8sObjectListTableData(&resp.Resources, namespaced, gvk, checkForResourceCallback)
+		if err != nil {
+			impl.logger.Errorw("error on parsing for k8s resource", "err", err)
+			return false, err
+		}
+		if response.Headers == nil {
+			response.Headers = page.Headers
+		}
+		response.Data = append(response.Data, page.Data...)
+		if limitReached || (response.Scanned >= scanLimit && resp.Resources.GetContinue() != "") {
+			response.Truncated = true
+			return false, nil
+		}
+		return true, nil
+	})
+	// what was found before the timeout is returned, marked as truncated
+	if err != nil && searchCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		response.Truncated = true
+		response.TimedOut = true
+		err = nil
+	}
+	if err != nil {
+		impl.logger.Errorw("error in searching resources", "err", err, "clusterId", request.ClusterId, "gvk", gvk, "query", request.Query)
+		return nil, err
+	}
+	return response, nil
 }
 
 func (impl *K8sApplicationServiceImpl) ApplyResources(ctx context.Context, token string, request *application.ApplyResourcesRequest, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool) ([]*application.ApplyResourcesResponse, error) {
@@ -737,14 +986,20 @@ func (impl *K8sApplicationServiceImpl) ApplyResources(ctx context.Context, token
 			},
 		}
 		actionAllowed := validateResourceAccess(token, clusterBean.ClusterName, resourceRequestBean, casbin.ActionUpdate)
-		if actionAllowed {
+		if !actionAllowed {
+			manifestRes.Error = "permission-denied"
+		} else if err = impl.checkNamespaceAccess(ctx, clusterId, util.ManifestNamespace(&manifest, namespace), true); err != nil {
+			manifestRes.Error = err.Error()
+		} else {
 			resourceExists, err := impl.applyResourceFromManifest(ctx, manifest, restConfig, namespace)
 			manifestRes.IsUpdate = resourceExists
 			if err != nil {
 				manifestRes.Error = err.Error()
+			} else if manifest.GetKind() == "Secret" && manifest.GroupVersionKind().Group == "" {
+				manifestRes.Warning = impl.secretEncryptionWarning(ctx, clusterBean)
+			} else {
+				manifestRes.Warning = impl.storageCapacityWarning(ctx, clusterBean, &manifest)
 			}
-		} else {
-			manifestRes.Error = "permission-denied"
 		}
 		response = append(response, manifestRes)
 	}
@@ -752,6 +1007,28 @@ func (impl *K8sApplicationServiceImpl) ApplyResources(ctx context.Context, token
 	return response, nil
 }
 
+func (impl *K8sApplicationServiceImpl) secretEncryptionWarning(ctx context.Context, clusterBean *cluster.ClusterBean) string {
+	clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster config", "clusterId", clusterBean.Id, "err", err)
+		return util.EncryptionAtRestWarning(nil)
+	}
+	return impl.K8sUtil.SecretEncryptionWarning(ctx, clusterConfig)
+}
+
+// storageCapacityWarning warns when a PVC of the manifest may stay pending for lack of storage on the nodes
+func (impl *K8sApplicationServiceImpl) storageCapacityWarning(ctx context.Context, clusterBean *cluster.ClusterBean, manifest *unstructured.Unstructured) string {
+	if kind := manifest.GetKind(); kind != "PersistentVolumeClaim" && kind != "StatefulSet" {
+		return ""
+	}
+	clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster config", "clusterId", clusterBean.Id, "err", err)
+		return ""
+	}
+	return impl.K8sUtil.StorageCapacityWarning(ctx, manifest, clusterConfig)
+}
+
 func (impl *K8sApplicationServiceImpl) applyResourceFromManifest(ctx context.Context, manifest unstructured.Unstructured, restConfig *rest.Config, namespace string) (bool, error) {
 	var isUpdateResource bool
 	k8sRequestBean := &application.K8sRequestBean{
@@ -792,3 +1069,274 @@ func (impl *K8sApplicationServiceImpl) applyResourceFromManifest(ctx context.Con
 
 	return isUpdateResource, nil
 }
+
+func (impl *K8sApplicationServiceImpl) GetRolloutStatus(ctx context.Context, clusterId int, namespace string, name string) (*util.RolloutStatus, error) {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	return impl.K8sUtil.GetRolloutStatus(ctx, namespace, name, clusterConfig)
+}
+
+// FindAppRolloutName finds the rollout deployed for the app in the environment using the appId and envId labels set by
+// devtron charts, util.ErrRolloutNotFound is returned if the app is not deployed as a rollout
+func (impl *K8sApplicationServiceImpl) FindAppRolloutName(ctx context.Context, clusterId int, namespace string, appId int, envId int) (string, error) {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return "", err
+	}
+	labelSelector := fmt.Sprintf("%s=%d,%s=%d", util.DevtronAppIdLabelKey, appId, util.DevtronEnvIdLabelKey, envId)
+	rollouts, err := impl.K8sUtil.ListRollouts(ctx, namespace, labelSelector, clusterConfig)
+	if err != nil {
+		return "", err
+	}
+	if len(rollouts) == 0 {
+		return "", util.ErrRolloutNotFound
+	}
+	return rollouts[0].GetName(), nil
+}
+
+func (impl *K8sApplicationServiceImpl) PauseRollout(ctx context.Context, clusterId int, namespace string, name string) error {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return err
+	}
+	if err = impl.K8sUtil.CheckMutation(ctx, clusterConfig, namespace, util.K8sClusterResourceRolloutKind, name, util.MutationActionPause); err != nil {
+		return err
+	}
+	return impl.K8sUtil.PauseRollout(ctx, namespace, name, clusterConfig)
+}
+
+func (impl *K8sApplicationServiceImpl) ResumeRollout(ctx context.Context, clusterId int, namespace string, name string) error {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return err
+	}
+	if err = impl.K8sUtil.CheckMutation(ctx, clusterConfig, namespace, util.K8sClusterResourceRolloutKind, name, util.MutationActionResume); err != nil {
+		return err
+	}
+	return impl.K8sUtil.ResumeRollout(ctx, namespace, name, clusterConfig)
+}
+
+func (impl *K8sApplicationServiceImpl) PromoteRollout(ctx context.Context, clusterId int, namespace string, name string, fullPromotion bool) error {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return err
+	}
+	if err = impl.K8sUtil.CheckMutation(ctx, clusterConfig, namespace, util.K8sClusterResourceRolloutKind, name, util.MutationActionPromote); err != nil {
+		return err
+	}
+	return impl.K8sUtil.PromoteRollout(ctx, namespace, name, fullPromotion, clusterConfig)
+}
+
+func (impl *K8sApplicationServiceImpl) AbortRollout(ctx context.Context, clusterId int, namespace string, name string) error {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return err
+	}
+	if err = impl.K8sUtil.CheckMutation(ctx, clusterConfig, namespace, util.K8sClusterResourceRolloutKind, name, util.MutationActionAbort); err != nil {
+		return err
+	}
+	return impl.K8sUtil.AbortRollout(ctx, namespace, name, clusterConfig)
+}
+
+func (impl *K8sApplicationServiceImpl) GetNodeDetail(ctx context.Context, clusterId int, nodeName string) (*util.NodeDetail, error) {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	return impl.K8sUtil.GetNodeDetail(ctx, nodeName, clusterConfig)
+}
+
+func (impl *K8sApplicationServiceImpl) ReviewSelfAccess(ctx context.Context, clusterId int, attributes []authorizationV1.ResourceAttributes) ([]util.AccessReviewResult, error) {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	return impl.K8sUtil.ReviewSelfAccess(ctx, clusterConfig, attributes)
+}
+
+func (impl *K8sApplicationServiceImpl) GetRolloutRevisionHistory(ctx context.Context, clusterId int, namespace string, name string) ([]util.RolloutRevision, error) {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	return impl.K8sUtil.GetRolloutRevisionHistory(ctx, namespace, name, clusterConfig)
+}
+
+// ListAppCronJobs lists the cronjobs carrying the appId and envId labels, labelSelector narrows them down further
+func (impl *K8sApplicationServiceImpl) ListAppCronJobs(ctx context.Context, clusterId int, namespace string, appId int, envId int, labelSelector string, withLastRun bool) ([]*AppCronJob, error) {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	appSelector := fmt.Sprintf("%s=%d,%s=%d", util.DevtronAppIdLabelKey, appId, util.DevtronEnvIdLabelKey, envId)
+	if labelSelector != "" {
+		appSelector = appSelector + "," + labelSelector
+	}
+	cronJobs, err := impl.K8sUtil.ListCronJobs(ctx, namespace, appSelector, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	var lastRuns map[string]*util.CronJobRun
+	if withLastRun && len(cronJobs) > 0 {
+		lastRuns, err = impl.K8sUtil.GetLastCronJobRuns(ctx, namespace, cronJobs, clusterConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	appCronJobs := make([]*AppCronJob, 0, len(cronJobs))
+	for _, cronJob := range cronJobs {
+		appCronJobs = append(appCronJobs, &AppCronJob{CronJob: cronJob, LastRun: lastRuns[cronJob.Name]})
+	}
+	return appCronJobs, nil
+}
+
+// SetAppCronJobSuspended only patches cronjobs carrying the appId and envId labels, util.ErrCronJobNotFound is returned
+// for any other cronjob
+func (impl *K8sApplicationServiceImpl) SetAppCronJobSuspended(ctx context.Context, clusterId int, namespace string, appId int, envId int, name string, suspended bool) error {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return err
+	}
+	labelSelector := fmt.Sprintf("%s=%d,%s=%d", util.DevtronAppIdLabelKey, appId, util.DevtronEnvIdLabelKey, envId)
+	cronJobs, err := impl.K8sUtil.ListCronJobs(ctx, namespace, labelSelector, clusterConfig)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, cronJob := range cronJobs {
+		if cronJob.Name == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return util.ErrCronJobNotFound
+	}
+	action := util.MutationActionResume
+	if suspended {
+		action = util.MutationActionPause
+	}
+	if err = impl.K8sUtil.CheckMutation(ctx, clusterConfig, namespace, util.K8sClusterResourceCronJobKind, name, action); err != nil {
+		return err
+	}
+	return impl.K8sUtil.SetCronJobSuspended(ctx, namespace, name, suspended, clusterConfig)
+}
+
+func (impl *K8sApplicationServiceImpl) DeleteAppPod(ctx context.Context, clusterId int, namespace string, appId int, envId int, name string, gracePeriodSeconds *int64, force bool) (*util.PodState, error) {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	if err = impl.K8sUtil.CheckMutation(ctx, clusterConfig, namespace, kube.PodKind, name, util.MutationActionDelete); err != nil {
+		return nil, err
+	}
+	labelSelector := fmt.Sprintf("%s=%d,%s=%d", util.DevtronAppIdLabelKey, appId, util.DevtronEnvIdLabelKey, envId)
+	state, err := impl.K8sUtil.DeletePod(ctx, namespace, name, labelSelector, gracePeriodSeconds, force, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	impl.saveAppResourceHistory(ctx, appId, envId, namespace, schema.GroupVersionKind{Version: util.V1VERSION, Kind: kube.PodKind}, name, force, util.MutationActionDelete)
+	return state, nil
+}
+
+func (impl *K8sApplicationServiceImpl) RestartAppWorkload(ctx context.Context, clusterId int, namespace string, appId int, envId int, kind string, name string) (*util.WorkloadState, error) {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	if err = impl.K8sUtil.CheckMutation(ctx, clusterConfig, namespace, kind, name, util.MutationActionRestart); err != nil {
+		return nil, err
+	}
+	labelSelector := fmt.Sprintf("%s=%d,%s=%d", util.DevtronAppIdLabelKey, appId, util.DevtronEnvIdLabelKey, envId)
+	state, err := impl.K8sUtil.RestartWorkload(ctx, namespace, kind, name, labelSelector, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	impl.saveAppResourceHistory(ctx, appId, envId, namespace, schema.GroupVersionKind{Group: util.AppsGroup, Version: util.V1VERSION, Kind: kind}, name, false, util.MutationActionRestart)
+	return state, nil
+}
+
+func (impl *K8sApplicationServiceImpl) ScaleAppWorkload(ctx context.Context, clusterId int, namespace string, appId int, envId int, kind string, name string, replicas int32) (*util.WorkloadState, error) {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	if err = impl.K8sUtil.CheckMutation(ctx, clusterConfig, namespace, kind, name, util.MutationActionScale); err != nil {
+		return nil, err
+	}
+	labelSelector := fmt.Sprintf("%s=%d,%s=%d", util.DevtronAppIdLabelKey, appId, util.DevtronEnvIdLabelKey, envId)
+	state, err := impl.K8sUtil.ScaleWorkload(ctx, namespace, kind, name, labelSelector, replicas, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	impl.saveAppResourceHistory(ctx, appId, envId, namespace, schema.GroupVersionKind{Group: util.AppsGroup, Version: util.V1VERSION, Kind: kind}, name, false, util.MutationActionScale)
+	return state, nil
+}
+
+func (impl *K8sApplicationServiceImpl) SnapshotAppPvc(ctx context.Context, clusterId int, namespace string, appId int, envId int, pvcName string, snapshotClassName string) (*util.VolumeSnapshotInfo, error) {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	labelSelector := fmt.Sprintf("%s=%d,%s=%d", util.DevtronAppIdLabelKey, appId, util.DevtronEnvIdLabelKey, envId)
+	pvcNames, err := impl.K8sUtil.ListMountedPvcNames(ctx, namespace, labelSelector, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	mounted := false
+	for _, name := range pvcNames {
+		mounted = mounted || name == pvcName
+	}
+	if !mounted {
+		return nil, util.ErrPvcNotMounted
+	}
+	if err = impl.K8sUtil.CheckMutation(ctx, clusterConfig, namespace, util.VolumeSnapshotKind, pvcName, util.MutationActionSnapshot); err != nil {
+		return nil, err
+	}
+	snapshot, err := impl.K8sUtil.CreateVolumeSnapshot(ctx, namespace, pvcName, snapshotClassName, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	impl.saveAppResourceHistory(ctx, appId, envId, namespace, schema.GroupVersionKind{Group: util.VolumeSnapshotGVR.Group, Version: util.VolumeSnapshotGVR.Version, Kind: util.VolumeSnapshotKind}, snapshot.Name, false, util.MutationActionSnapshot)
+	return snapshot, nil
+}
+
+// saveAppResourceHistory failures do not fail the action, it has already been made in the cluster
+func (impl *K8sApplicationServiceImpl) saveAppResourceHistory(ctx context.Context, appId int, envId int, namespace string, gvk schema.GroupVersionKind, name string, force bool, action string) {
+	resourceIdentifier := application.ResourceIdentifier{Name: name, Namespace: namespace, GroupVersionKind: gvk}
+	err := impl.K8sResourceHistoryService.SaveDevtronAppsResourceHistory(appId, envId, resourceIdentifier, force, util.UserIdFromContext(ctx), action)
+	if err != nil {
+		util.LoggerFromContext(ctx, impl.logger).Errorw("error in saving resource history", "err", err, "appId", appId, "envId", envId, "kind", gvk.Kind, "name", name, "action", action)
+	}
+}
+
+// GetClusterCapabilities returns the api features of the cluster as last seen by the cluster connection cron
+func (impl *K8sApplicationServiceImpl) GetClusterCapabilities(ctx context.Context, clusterId int) (*util.ClusterCapabilities, error) {
+	clusterConfig, err := impl.getClusterConfig(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	return impl.K8sUtil.GetClusterCapabilities(ctx, clusterConfig)
+}
+
+// checkNamespaceAccess checks the call against the namespace policy of the cluster, the policy is looked up by the
+// cluster id alone so the cluster config is not built
+func (impl *K8sApplicationServiceImpl) checkNamespaceAccess(ctx context.Context, clusterId int, namespace string, mutating bool) error {
+	return impl.K8sUtil.CheckNamespaceAccess(ctx, &util.ClusterConfig{ClusterId: clusterId}, namespace, mutating)
+}
+
+func (impl *K8sApplicationServiceImpl) getClusterConfig(clusterId int) (*util.ClusterConfig, error) {
+	clusterBean, err := impl.clusterService.FindById(clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting clusterBean by cluster Id", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+	clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster config", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+	return clusterConfig, nil
+}
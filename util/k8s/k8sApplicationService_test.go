@@ -11,6 +11,7 @@ import (
 	"github.com/devtron-labs/devtron/pkg/cluster/repository"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
 	"io"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -80,121 +81,124 @@ var manifest = `{
 		}
 	  }`
 
+// the mocks embed the interfaces so that they keep compiling as methods are added, only the methods below are used
 type NewK8sClientServiceImplMock struct {
 	mock.Mock
+	application.K8sClientService
 }
 type NewClusterServiceMock struct {
 	mock.Mock
+	cluster.ClusterService
 }
 
-func (n NewClusterServiceMock) Save(parent context.Context, bean *cluster.ClusterBean, userId int32) (*cluster.ClusterBean, error) {
+func (n *NewClusterServiceMock) Save(parent context.Context, bean *cluster.ClusterBean, userId int32) (*cluster.ClusterBean, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewClusterServiceMock) FindOne(clusterName string) (*cluster.ClusterBean, error) {
+func (n *NewClusterServiceMock) FindOne(clusterName string) (*cluster.ClusterBean, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewClusterServiceMock) FindOneActive(clusterName string) (*cluster.ClusterBean, error) {
+func (n *NewClusterServiceMock) FindOneActive(clusterName string) (*cluster.ClusterBean, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewClusterServiceMock) FindAll() ([]*cluster.ClusterBean, error) {
+func (n *NewClusterServiceMock) FindAll() ([]*cluster.ClusterBean, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewClusterServiceMock) FindAllActive() ([]cluster.ClusterBean, error) {
+func (n *NewClusterServiceMock) FindAllActive() ([]cluster.ClusterBean, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewClusterServiceMock) DeleteFromDb(bean *cluster.ClusterBean, userId int32) error {
+func (n *NewClusterServiceMock) DeleteFromDb(bean *cluster.ClusterBean, userId int32) error {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewClusterServiceMock) FindById(id int) (*cluster.ClusterBean, error) {
+func (n *NewClusterServiceMock) FindById(id int) (*cluster.ClusterBean, error) {
 	//TODO implement me
 	return &cluster.ClusterBean{}, nil
 }
 
-func (n NewClusterServiceMock) FindByIds(id []int) ([]cluster.ClusterBean, error) {
+func (n *NewClusterServiceMock) FindByIds(id []int) ([]cluster.ClusterBean, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewClusterServiceMock) Update(ctx context.Context, bean *cluster.ClusterBean, userId int32) (*cluster.ClusterBean, error) {
+func (n *NewClusterServiceMock) Update(ctx context.Context, bean *cluster.ClusterBean, userId int32) (*cluster.ClusterBean, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewClusterServiceMock) Delete(bean *cluster.ClusterBean, userId int32) error {
+func (n *NewClusterServiceMock) Delete(bean *cluster.ClusterBean, userId int32) error {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewClusterServiceMock) FindAllForAutoComplete() ([]cluster.ClusterBean, error) {
+func (n *NewClusterServiceMock) FindAllForAutoComplete() ([]cluster.ClusterBean, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewClusterServiceMock) CreateGrafanaDataSource(clusterBean *cluster.ClusterBean, env *repository.Environment) (int, error) {
+func (n *NewClusterServiceMock) CreateGrafanaDataSource(clusterBean *cluster.ClusterBean, env *repository.Environment) (int, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewClusterServiceMock) GetClusterConfig(cluster *cluster.ClusterBean) (*util.ClusterConfig, error) {
+func (n *NewClusterServiceMock) GetClusterConfig(cluster *cluster.ClusterBean) (*util.ClusterConfig, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewClusterServiceMock) GetK8sClient() (*v1.CoreV1Client, error) {
+func (n *NewClusterServiceMock) GetK8sClient() (*v1.CoreV1Client, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewK8sClientServiceImplMock) GetResource(restConfig *rest.Config, request *application.K8sRequestBean) (resp *application.ManifestResponse, err error) {
+func (n *NewK8sClientServiceImplMock) GetResource(ctx context.Context, restConfig *rest.Config, request *application.K8sRequestBean) (resp *application.ManifestResponse, err error) {
 	kind := request.ResourceIdentifier.GroupVersionKind.Kind
 	man := generateTestManifest(kind)
 	return &man, nil
 }
 
-func (n NewK8sClientServiceImplMock) CreateResource(restConfig *rest.Config, request *application.K8sRequestBean, manifest string) (resp *application.ManifestResponse, err error) {
+func (n *NewK8sClientServiceImplMock) CreateResource(ctx context.Context, restConfig *rest.Config, request *application.K8sRequestBean, manifest string) (resp *application.ManifestResponse, err error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewK8sClientServiceImplMock) UpdateResource(restConfig *rest.Config, request *application.K8sRequestBean) (resp *application.ManifestResponse, err error) {
+func (n *NewK8sClientServiceImplMock) UpdateResource(ctx context.Context, restConfig *rest.Config, request *application.K8sRequestBean) (resp *application.ManifestResponse, err error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewK8sClientServiceImplMock) DeleteResource(restConfig *rest.Config, request *application.K8sRequestBean) (resp *application.ManifestResponse, err error) {
+func (n *NewK8sClientServiceImplMock) DeleteResource(ctx context.Context, restConfig *rest.Config, request *application.K8sRequestBean) (resp *application.ManifestResponse, err error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewK8sClientServiceImplMock) ListEvents(restConfig *rest.Config, request *application.K8sRequestBean) (*application.EventsResponse, error) {
+func (n *NewK8sClientServiceImplMock) ListEvents(ctx context.Context, restConfig *rest.Config, request *application.K8sRequestBean) (*application.EventsResponse, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewK8sClientServiceImplMock) GetPodLogs(restConfig *rest.Config, request *application.K8sRequestBean) (io.ReadCloser, error) {
+func (n *NewK8sClientServiceImplMock) GetPodLogs(ctx context.Context, restConfig *rest.Config, request *application.K8sRequestBean) (io.ReadCloser, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
 func Test_GetManifestsInBatch(t *testing.T) {
 	var (
-		k8sCS          = NewK8sClientServiceImplMock{}
-		clusterService = NewClusterServiceMock{}
+		k8sCS          = &NewK8sClientServiceImplMock{}
+		clusterService = &NewClusterServiceMock{}
 		impl           = NewK8sApplicationServiceImpl(
-			nil, clusterService, nil, k8sCS, nil,
-			nil, nil)
+			zap.NewNop().Sugar(), clusterService, nil, k8sCS, nil,
+			&util.K8sUtil{}, nil, nil, nil)
 	)
 	n := 10
 	kinds := []string{"Service", "Ingress", "Random", "Invalid"}
@@ -215,7 +219,10 @@ func Test_GetManifestsInBatch(t *testing.T) {
 	}
 
 	t.Run(fmt.Sprint("test1"), func(t *testing.T) {
-		resultOutput := impl.GetHostUrlsByBatch(testInput)
+		resultOutput, err := impl.GetManifestsByBatch(context.Background(), testInput)
+		if err != nil {
+			t.Fatal(err)
+		}
 		//check if all the output manifests are expected
 		for j, _ := range resultOutput {
 			if !cmp.Equal(resultOutput[j], expectedTestOutputs[j]) {
@@ -248,7 +255,7 @@ type test struct {
 func Test_getUrls(t *testing.T) {
 	impl := NewK8sApplicationServiceImpl(
 		nil, nil, nil, nil, nil,
-		nil, nil)
+		nil, nil, nil, nil)
 	tests := make([]test, 3)
 	tests[0] = test{
 		inp: generateTestManifest("Service"),
@@ -297,11 +304,11 @@ func generateTestManifest(kind string) application.ManifestResponse {
 
 func getObj(kind string) map[string]interface{} {
 	var obj map[string]interface{}
+	objManifest := manifest
 	if (kind != "Service") && (kind != "Ingress") {
-		fmt.Println(kind)
-		manifest = `{"invalid":{}}`
+		objManifest = `{"invalid":{}}`
 	}
-	err := json.Unmarshal([]byte(manifest), &obj)
+	err := json.Unmarshal([]byte(objManifest), &obj)
 	if err != nil {
 		fmt.Print("error in marshaling : ", err)
 		return nil
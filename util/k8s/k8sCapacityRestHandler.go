@@ -5,6 +5,7 @@ import (
 	"errors"
 	"github.com/devtron-labs/devtron/api/restHandler/common"
 	"github.com/devtron-labs/devtron/pkg/cluster"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
 	"github.com/devtron-labs/devtron/pkg/user"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
 	"github.com/gorilla/mux"
@@ -321,13 +322,13 @@ func (handler *K8sCapacityRestHandlerImpl) DrainNode(w http.ResponseWriter, r *h
 		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
 		return
 	}
-	resp, err := handler.k8sCapacityService.DrainNode(r.Context(), &nodeDrainReq)
+	operationId, err := handler.k8sCapacityService.DrainNodeAsync(r.Context(), &nodeDrainReq, userId)
 	if err != nil {
-		handler.logger.Errorw("error in draining node", "err", err, "req", nodeDrainReq)
+		handler.logger.Errorw("error in starting node drain", "err", err, "req", nodeDrainReq)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
 	}
-	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+	common.WriteJsonResp(w, nil, &clusterOperation.ClusterOperationStartedResponse{OperationId: operationId}, http.StatusAccepted)
 }
 
 func (handler *K8sCapacityRestHandlerImpl) EditNodeTaints(w http.ResponseWriter, r *http.Request) {
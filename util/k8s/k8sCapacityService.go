@@ -6,6 +6,7 @@ import (
 	"github.com/devtron-labs/devtron/client/k8s/application"
 	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/cluster"
+	"github.com/devtron-labs/devtron/pkg/clusterOperation"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
@@ -76,27 +77,35 @@ type K8sCapacityService interface {
 	DeleteNode(ctx context.Context, request *NodeUpdateRequestDto) (*application.ManifestResponse, error)
 	CordonOrUnCordonNode(ctx context.Context, request *NodeUpdateRequestDto) (string, error)
 	DrainNode(ctx context.Context, request *NodeUpdateRequestDto) (string, error)
+	// DrainNodeAsync drains the node in the background and returns the id of the tracking operation
+	DrainNodeAsync(ctx context.Context, request *NodeUpdateRequestDto, userId int32) (int, error)
 	EditNodeTaints(ctx context.Context, request *NodeUpdateRequestDto) (string, error)
 }
 type K8sCapacityServiceImpl struct {
-	logger                *zap.SugaredLogger
-	clusterService        cluster.ClusterService
-	k8sApplicationService K8sApplicationService
-	k8sClientService      application.K8sClientService
-	clusterCronService    ClusterCronService
+	logger                  *zap.SugaredLogger
+	clusterService          cluster.ClusterService
+	k8sApplicationService   K8sApplicationService
+	k8sClientService        application.K8sClientService
+	clusterCronService      ClusterCronService
+	clusterOperationService clusterOperation.ClusterOperationService
+	k8sUtil                 *util.K8sUtil
 }
 
 func NewK8sCapacityServiceImpl(Logger *zap.SugaredLogger,
 	clusterService cluster.ClusterService,
 	k8sApplicationService K8sApplicationService,
 	k8sClientService application.K8sClientService,
-	clusterCronService ClusterCronService) *K8sCapacityServiceImpl {
+	clusterCronService ClusterCronService,
+	clusterOperationService clusterOperation.ClusterOperationService,
+	k8sUtil *util.K8sUtil) *K8sCapacityServiceImpl {
 	return &K8sCapacityServiceImpl{
-		logger:                Logger,
-		clusterService:        clusterService,
-		k8sApplicationService: k8sApplicationService,
-		k8sClientService:      k8sClientService,
-		clusterCronService:    clusterCronService,
+		logger:                  Logger,
+		clusterService:          clusterService,
+		k8sApplicationService:   k8sApplicationService,
+		k8sClientService:        k8sClientService,
+		clusterCronService:      clusterCronService,
+		clusterOperationService: clusterOperationService,
+		k8sUtil:                 k8sUtil,
 	}
 }
 
@@ -156,10 +165,27 @@ func (impl *K8sCapacityServiceImpl) GetClusterCapacityDetail(ctx context.Context
 		if err != nil {
 			return nil, err
 		}
+		clusterDetail.NodeProvisioning = impl.getNodeProvisioningStatus(ctx, cluster)
 	}
 	return clusterDetail, nil
 }
 
+// getNodeProvisioningStatus reads the karpenter node claims of the cluster, nil when they can not be read so that the
+// overview is still served
+func (impl *K8sCapacityServiceImpl) getNodeProvisioningStatus(ctx context.Context, cluster *cluster.ClusterBean) *util.NodeProvisioningStatus {
+	clusterConfig, err := impl.clusterService.GetClusterConfig(cluster)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster config", "err", err, "clusterId", cluster.Id)
+		return nil
+	}
+	nodeProvisioningStatus, err := impl.k8sUtil.GetNodeProvisioningStatus(ctx, clusterConfig)
+	if err != nil {
+		impl.logger.Errorw("error in getting node provisioning status", "err", err, "clusterId", cluster.Id)
+		return nil
+	}
+	return nodeProvisioningStatus
+}
+
 func (impl *K8sCapacityServiceImpl) setBasicClusterDetails(nodeList *corev1.NodeList, clusterDetail *ClusterCapacityDetail) (resource.Quantity, resource.Quantity, int) {
 	var clusterCpuCapacity resource.Quantity
 	var clusterMemoryCapacity resource.Quantity
@@ -637,6 +663,23 @@ func (impl *K8sCapacityServiceImpl) CordonOrUnCordonNode(ctx context.Context, re
 }
 
 func (impl *K8sCapacityServiceImpl) DrainNode(ctx context.Context, request *NodeUpdateRequestDto) (string, error) {
+	return impl.drainNode(ctx, request, func(string) {})
+}
+
+func (impl *K8sCapacityServiceImpl) DrainNodeAsync(ctx context.Context, request *NodeUpdateRequestDto, userId int32) (int, error) {
+	operation := &clusterOperation.ClusterOperationBean{
+		OperationType: clusterOperation.OperationTypeNodeDrain,
+		ClusterId:     request.ClusterId,
+		ResourceName:  request.Name,
+		UserId:        userId,
+	}
+	return impl.clusterOperationService.Start(ctx, operation, func(ctx context.Context, progress clusterOperation.ProgressFunc) error {
+		_, err := impl.drainNode(ctx, request, progress)
+		return err
+	})
+}
+
+func (impl *K8sCapacityServiceImpl) drainNode(ctx context.Context, request *NodeUpdateRequestDto, progress clusterOperation.ProgressFunc) (string, error) {
 	impl.logger.Infow("received node drain request", "request", request)
 	respMessage := ""
 	cluster, err := impl.getClusterBean(request.ClusterId)
@@ -656,6 +699,7 @@ func (impl *K8sCapacityServiceImpl) DrainNode(ctx context.Context, request *Node
 	}
 	//checking if node is unschedulable or not, if not then need to unschedule before draining
 	if !node.Spec.Unschedulable {
+		progress("cordoning node")
 		node, err = updateNodeUnschedulableProperty(true, node, k8sClientSet)
 		if err != nil {
 			impl.logger.Errorw("error in making node unschedulable", "err", err)
@@ -663,6 +707,7 @@ func (impl *K8sCapacityServiceImpl) DrainNode(ctx context.Context, request *Node
 		}
 	}
 	request.NodeDrainHelper.k8sClientSet = k8sClientSet
+	progress("evicting pods")
 	err = impl.deleteOrEvictPods(request.Name, request.NodeDrainHelper)
 	if err != nil {
 		impl.logger.Errorw("error in deleting/evicting pods", "err", err, "nodeName", request.Name)
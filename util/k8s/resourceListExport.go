@@ -0,0 +1,220 @@
+package k8s
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ResourceExportFormatCsv  = "csv"
+	ResourceExportFormatJson = "json"
+
+	// ResourceExportTruncatedTrailer is sent as a trailer because truncation is only known once the rows are written
+	ResourceExportTruncatedTrailer = "X-Export-Truncated"
+
+	defaultResourceExportMaxRows   = 10000
+	defaultResourceExportChunkSize = 500
+)
+
+var exportFileNameUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+type ResourceListExportSummary struct {
+	Rows      int  `json:"rows"`
+	MaxRows   int  `json:"maxRows"`
+	Truncated bool `json:"truncated"`
+}
+
+// ResourceListRowWriter receives the table headers once and then the rows of every chunk, in listing order
+type ResourceListRowWriter interface {
+	WriteHeaders(headers []string) error
+	WriteRows(rows []map[string]interface{}) error
+	// Started tells if anything was sent, errors before that can still be returned as a normal api error
+	Started() bool
+	Finish(summary *ResourceListExportSummary, exportErr error) error
+}
+
+func IsValidResourceExportFormat(format string) bool {
+	return format == ResourceExportFormatCsv || format == ResourceExportFormatJson
+}
+
+func NewResourceListRowWriter(w http.ResponseWriter, format string, fileName string) ResourceListRowWriter {
+	base := &resourceListResponseWriter{w: w, fileName: fileName}
+	if format == ResourceExportFormatCsv {
+		return &csvResourceListWriter{resourceListResponseWriter: base, csvWriter: csv.NewWriter(w)}
+	}
+	return &jsonResourceListWriter{resourceListResponseWriter: base, encoder: json.NewEncoder(w)}
+}
+
+// ResourceExportFileName is <kind>-<namespace>-<timestamp>, namespace is "all" for cluster wide listings
+func ResourceExportFileName(kind string, namespace string, format string, now time.Time) string {
+	if namespace == "" {
+		namespace = "all"
+	}
+	extension := "csv"
+	if format == ResourceExportFormatJson {
+		extension = "jsonl"
+	}
+	name := strings.ToLower(fmt.Sprintf("%s-%s-%s", kind, namespace, now.UTC().Format("20060102-150405")))
+	return fmt.Sprintf("%s.%s", exportFileNameUnsafeChars.ReplaceAllString(name, "_"), extension)
+}
+
+// resourceExportLimits falls back to the defaults for limits which are not set
+func resourceExportLimits(config *K8sApplicationServiceConfig) (int, int64) {
+	maxRows, chunkSize := config.ResourceExportMaxRows, config.ResourceExportChunkSize
+	if maxRows <= 0 {
+		maxRows = defaultResourceExportMaxRows
+	}
+	if chunkSize <= 0 {
+		chunkSize = defaultResourceExportChunkSize
+	}
+	return maxRows, chunkSize
+}
+
+// writeResourceExportChunk writes the rows of a chunk which still fit in summary.MaxRows and tells if the listing should
+// go on. An export which stops at the limit while the server has more items is truncated too
+func writeResourceExportChunk(writer ResourceListRowWriter, summary *ResourceListExportSummary, rows []map[string]interface{}, moreOnServer bool) (bool, error) {
+	if remaining := summary.MaxRows - summary.Rows; len(rows) > remaining {
+		rows = rows[:remaining]
+		summary.Truncated = true
+	}
+	if err := writer.WriteRows(rows); err != nil {
+		return false, err
+	}
+	summary.Rows += len(rows)
+	if summary.Rows >= summary.MaxRows && !summary.Truncated && moreOnServer {
+		summary.Truncated = true
+	}
+	return !summary.Truncated, nil
+}
+
+type resourceListResponseWriter struct {
+	w        http.ResponseWriter
+	fileName string
+	headers  []string
+	started  bool
+}
+
+func (impl *resourceListResponseWriter) start(contentType string, headers []string) {
+	impl.headers = headers
+	impl.started = true
+	impl.w.Header().Set("Content-Type", contentType)
+	impl.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", impl.fileName))
+	impl.w.Header().Set("Trailer", ResourceExportTruncatedTrailer)
+	impl.w.WriteHeader(http.StatusOK)
+}
+
+func (impl *resourceListResponseWriter) Started() bool {
+	return impl.started
+}
+
+func (impl *resourceListResponseWriter) flush() {
+	if flusher, ok := impl.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (impl *resourceListResponseWriter) setTruncatedTrailer(summary *ResourceListExportSummary) {
+	impl.w.Header().Set(ResourceExportTruncatedTrailer, strconv.FormatBool(summary.Truncated))
+}
+
+type csvResourceListWriter struct {
+	*resourceListResponseWriter
+	csvWriter *csv.Writer
+}
+
+func (impl *csvResourceListWriter) WriteHeaders(headers []string) error {
+	impl.start("text/csv; charset=utf-8", headers)
+	return impl.write(headers)
+}
+
+func (impl *csvResourceListWriter) WriteRows(rows []map[string]interface{}) error {
+	for _, row := range rows {
+		record := make([]string, 0, len(impl.headers))
+		for _, header := range impl.headers {
+			record = append(record, exportCellValue(row[header]))
+		}
+		if err := impl.csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	impl.csvWriter.Flush()
+	impl.flush()
+	return impl.csvWriter.Error()
+}
+
+// Finish ends the file with a # line when the export is incomplete, so that a truncated file is obvious to whoever
+// opens it even if the trailer was dropped by a proxy
+func (impl *csvResourceListWriter) Finish(summary *ResourceListExportSummary, exportErr error) error {
+	if exportErr != nil {
+		_ = impl.write([]string{fmt.Sprintf("# export failed after %d rows: %v", summary.Rows, exportErr)})
+	} else if summary.Truncated {
+		_ = impl.write([]string{fmt.Sprintf("# export truncated at %d rows, narrow the namespace or label selector to get the rest", summary.MaxRows)})
+	}
+	impl.setTruncatedTrailer(summary)
+	return impl.csvWriter.Error()
+}
+
+func (impl *csvResourceListWriter) write(record []string) error {
+	if err := impl.csvWriter.Write(record); err != nil {
+		return err
+	}
+	impl.csvWriter.Flush()
+	impl.flush()
+	return impl.csvWriter.Error()
+}
+
+// jsonResourceListWriter writes json lines, one object per row holding only the table columns
+type jsonResourceListWriter struct {
+	*resourceListResponseWriter
+	encoder *json.Encoder
+}
+
+func (impl *jsonResourceListWriter) WriteHeaders(headers []string) error {
+	impl.start("application/x-ndjson", headers)
+	return nil
+}
+
+func (impl *jsonResourceListWriter) WriteRows(rows []map[string]interface{}) error {
+	for _, row := range rows {
+		record := make(map[string]interface{}, len(impl.headers))
+		for _, header := range impl.headers {
+			value, ok := row[header]
+			if !ok || value == nil {
+				value = ""
+			}
+			record[header] = value
+		}
+		if err := impl.encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	impl.flush()
+	return nil
+}
+
+func (impl *jsonResourceListWriter) Finish(summary *ResourceListExportSummary, exportErr error) error {
+	var err error
+	if exportErr != nil {
+		err = impl.encoder.Encode(map[string]interface{}{"error": exportErr.Error(), "rows": summary.Rows})
+	} else if summary.Truncated {
+		err = impl.encoder.Encode(summary)
+	}
+	impl.setTruncatedTrailer(summary)
+	return err
+}
+
+func exportCellValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
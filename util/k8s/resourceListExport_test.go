@@ -0,0 +1,180 @@
+package k8s
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var exportTestHeaders = []string{"name", "namespace", "age"}
+
+func exportTestRows(count int) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		rows = append(rows, map[string]interface{}{"name": "pod-" + string(rune('a'+i)), "namespace": "default", "age": i})
+	}
+	return rows
+}
+
+func TestResourceListRowWriter(t *testing.T) {
+	tests := []struct {
+		name            string
+		format          string
+		rows            []map[string]interface{}
+		summary         *ResourceListExportSummary
+		exportErr       error
+		wantContentType string
+		wantBody        string
+		wantTruncated   string
+	}{
+		{
+			name:            "csv",
+			format:          ResourceExportFormatCsv,
+			rows:            []map[string]interface{}{{"name": "web, api", "namespace": "default", "age": 3}, {"name": "db", "age": nil}},
+			summary:         &ResourceListExportSummary{Rows: 2, MaxRows: 10},
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody:        "name,namespace,age\n\"web, api\",default,3\ndb,,\n",
+			wantTruncated:   "false",
+		},
+		{
+			name:            "csv truncated",
+			format:          ResourceExportFormatCsv,
+			rows:            []map[string]interface{}{{"name": "web", "namespace": "default", "age": 3}},
+			summary:         &ResourceListExportSummary{Rows: 1, MaxRows: 1, Truncated: true},
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody:        "name,namespace,age\nweb,default,3\n\"# export truncated at 1 rows, narrow the namespace or label selector to get the rest\"\n",
+			wantTruncated:   "true",
+		},
+		{
+			name:            "csv failed",
+			format:          ResourceExportFormatCsv,
+			rows:            []map[string]interface{}{{"name": "web", "namespace": "default", "age": 3}},
+			summary:         &ResourceListExportSummary{Rows: 1, MaxRows: 10},
+			exportErr:       errors.New("connection reset"),
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody:        "name,namespace,age\nweb,default,3\n# export failed after 1 rows: connection reset\n",
+			wantTruncated:   "false",
+		},
+		{
+			name:            "json lines",
+			format:          ResourceExportFormatJson,
+			rows:            []map[string]interface{}{{"name": "web", "namespace": "default", "age": 3, "extra": "dropped"}, {"name": "db"}},
+			summary:         &ResourceListExportSummary{Rows: 2, MaxRows: 10},
+			wantContentType: "application/x-ndjson",
+			wantBody:        "{\"age\":3,\"name\":\"web\",\"namespace\":\"default\"}\n{\"age\":\"\",\"name\":\"db\",\"namespace\":\"\"}\n",
+			wantTruncated:   "false",
+		},
+		{
+			name:            "json lines truncated",
+			format:          ResourceExportFormatJson,
+			rows:            []map[string]interface{}{{"name": "web", "namespace": "default", "age": 3}},
+			summary:         &ResourceListExportSummary{Rows: 1, MaxRows: 1, Truncated: true},
+			wantContentType: "application/x-ndjson",
+			wantBody:        "{\"age\":3,\"name\":\"web\",\"namespace\":\"default\"}\n{\"rows\":1,\"maxRows\":1,\"truncated\":true}\n",
+			wantTruncated:   "true",
+		},
+		{
+			name:            "json lines failed",
+			format:          ResourceExportFormatJson,
+			summary:         &ResourceListExportSummary{MaxRows: 10},
+			exportErr:       errors.New("connection reset"),
+			wantContentType: "application/x-ndjson",
+			wantBody:        "{\"error\":\"connection reset\",\"rows\":0}\n",
+			wantTruncated:   "false",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			writer := NewResourceListRowWriter(recorder, tt.format, "pod-default.export")
+			assert.False(t, writer.Started())
+			assert.Nil(t, writer.WriteHeaders(exportTestHeaders))
+			assert.True(t, writer.Started())
+			assert.Nil(t, writer.WriteRows(tt.rows))
+			assert.Nil(t, writer.Finish(tt.summary, tt.exportErr))
+			assert.Equal(t, tt.wantBody, recorder.Body.String())
+			assert.Equal(t, tt.wantContentType, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, `attachment; filename="pod-default.export"`, recorder.Header().Get("Content-Disposition"))
+			assert.Equal(t, tt.wantTruncated, recorder.Header().Get(ResourceExportTruncatedTrailer))
+		})
+	}
+}
+
+func TestWriteResourceExportChunk(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxRows       int
+		chunks        []int
+		moreOnServer  bool
+		wantRows      int
+		wantChunks    int
+		wantTruncated bool
+	}{
+		{name: "below limit", maxRows: 10, chunks: []int{3, 4}, wantRows: 7, wantChunks: 2},
+		{name: "chunk cut at limit", maxRows: 5, chunks: []int{3, 4, 2}, moreOnServer: true, wantRows: 5, wantChunks: 2, wantTruncated: true},
+		{name: "limit reached with nothing left", maxRows: 6, chunks: []int{3, 3}, wantRows: 6, wantChunks: 2},
+		{name: "limit reached with more on server", maxRows: 6, chunks: []int{3, 3, 3}, moreOnServer: true, wantRows: 6, wantChunks: 2, wantTruncated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			writer := NewResourceListRowWriter(recorder, ResourceExportFormatJson, "export.jsonl")
+			assert.Nil(t, writer.WriteHeaders(exportTestHeaders))
+			summary := &ResourceListExportSummary{MaxRows: tt.maxRows}
+			chunks := 0
+			for index, size := range tt.chunks {
+				chunks++
+				more := tt.moreOnServer || index < len(tt.chunks)-1
+				next, err := writeResourceExportChunk(writer, summary, exportTestRows(size), more)
+				assert.Nil(t, err)
+				if !next {
+					break
+				}
+			}
+			assert.Equal(t, tt.wantChunks, chunks)
+			assert.Equal(t, tt.wantRows, summary.Rows)
+			assert.Equal(t, tt.wantTruncated, summary.Truncated)
+			lines := 0
+			scanner := bufio.NewScanner(strings.NewReader(recorder.Body.String()))
+			for scanner.Scan() {
+				var record map[string]interface{}
+				assert.Nil(t, json.Unmarshal(scanner.Bytes(), &record))
+				lines++
+			}
+			assert.Equal(t, tt.wantRows, lines)
+		})
+	}
+}
+
+func TestResourceExportLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        *K8sApplicationServiceConfig
+		wantMaxRows   int
+		wantChunkSize int64
+	}{
+		{name: "configured", config: &K8sApplicationServiceConfig{ResourceExportMaxRows: 250, ResourceExportChunkSize: 50}, wantMaxRows: 250, wantChunkSize: 50},
+		{name: "not set", config: &K8sApplicationServiceConfig{}, wantMaxRows: defaultResourceExportMaxRows, wantChunkSize: defaultResourceExportChunkSize},
+		{name: "negative", config: &K8sApplicationServiceConfig{ResourceExportMaxRows: -1, ResourceExportChunkSize: -1}, wantMaxRows: defaultResourceExportMaxRows, wantChunkSize: defaultResourceExportChunkSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxRows, chunkSize := resourceExportLimits(tt.config)
+			assert.Equal(t, tt.wantMaxRows, maxRows)
+			assert.Equal(t, tt.wantChunkSize, chunkSize)
+		})
+	}
+}
+
+func TestResourceExportFileName(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 4, 5, 0, time.UTC)
+	assert.Equal(t, "pod-default-20240301-100405.csv", ResourceExportFileName("Pod", "default", ResourceExportFormatCsv, now))
+	assert.Equal(t, "node-all-20240301-100405.jsonl", ResourceExportFileName("Node", "", ResourceExportFormatJson, now))
+	assert.Equal(t, "pod-team_a-20240301-100405.csv", ResourceExportFileName("Pod", "team a", ResourceExportFormatCsv, now))
+}
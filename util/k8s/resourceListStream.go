@@ -0,0 +1,132 @@
+package k8s
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/devtron-labs/devtron/internal/util"
+)
+
+const (
+	ResourceListNdJsonContentType = "application/x-ndjson"
+
+	// ResourceListTotalTrailer holds the number of rows sent, it is a trailer because it is only known at the end
+	ResourceListTotalTrailer = "X-Resource-List-Total"
+
+	defaultResourceListPageSize = 500
+)
+
+// ResourceListStreamWriter writes the resource list page by page as it is fetched. By default the body is the usual
+// {"code":200,"status":"OK","result":{"headers":[...],"data":[...]}} response, written as a chunked json array; with
+// ndJson the headers, every row and the total are written as separate json lines
+type ResourceListStreamWriter struct {
+	w       http.ResponseWriter
+	buf     *bufio.Writer
+	ndJson  bool
+	started bool
+	total   int
+}
+
+func NewResourceListStreamWriter(w http.ResponseWriter, ndJson bool) *ResourceListStreamWriter {
+	return &ResourceListStreamWriter{w: w, buf: bufio.NewWriter(w), ndJson: ndJson}
+}
+
+// Started tells if anything was sent, errors before that can still be returned as a normal api error
+func (impl *ResourceListStreamWriter) Started() bool {
+	return impl.started
+}
+
+func (impl *ResourceListStreamWriter) Total() int {
+	return impl.total
+}
+
+// WritePage writes the rows of a page and flushes them to the client, headers are taken from the first page
+func (impl *ResourceListStreamWriter) WritePage(page *util.ClusterResourceListMap) error {
+	if !impl.started {
+		if err := impl.start(page.Headers); err != nil {
+			return err
+		}
+	}
+	for _, row := range page.Data {
+		rowJson, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if impl.ndJson {
+			rowJson = append(rowJson, '\n')
+		} else if impl.total > 0 {
+			rowJson = append([]byte{','}, rowJson...)
+		}
+		if _, err = impl.buf.Write(rowJson); err != nil {
+			return err
+		}
+		impl.total++
+	}
+	return impl.flush()
+}
+
+// Finish closes the body, an error of the listing after the first page is reported in it since the status is already
+// sent
+func (impl *ResourceListStreamWriter) Finish(listErr error) error {
+	if !impl.started {
+		if err := impl.start(nil); err != nil {
+			return err
+		}
+	}
+	end := map[string]interface{}{"total": impl.total}
+	if listErr != nil {
+		end["error"] = listErr.Error()
+	}
+	endJson, err := json.Marshal(end)
+	if err != nil {
+		return err
+	}
+	if impl.ndJson {
+		endJson = append(endJson, '\n')
+	} else {
+		// closes the data array and splices total and error into the result object
+		endJson = append([]byte("],"), endJson[1:]...)
+		endJson = append(endJson, '}', '\n')
+	}
+	if _, err = impl.buf.Write(endJson); err != nil {
+		return err
+	}
+	impl.w.Header().Set(ResourceListTotalTrailer, strconv.Itoa(impl.total))
+	return impl.flush()
+}
+
+func (impl *ResourceListStreamWriter) start(headers []string) error {
+	if headers == nil {
+		headers = []string{}
+	}
+	impl.started = true
+	contentType := "application/json"
+	if impl.ndJson {
+		contentType = ResourceListNdJsonContentType
+	}
+	impl.w.Header().Set("Content-Type", contentType)
+	impl.w.Header().Set("Trailer", ResourceListTotalTrailer)
+	impl.w.WriteHeader(http.StatusOK)
+	headersJson, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+	if impl.ndJson {
+		_, err = impl.buf.WriteString(`{"headers":` + string(headersJson) + "}\n")
+	} else {
+		_, err = impl.buf.WriteString(`{"code":200,"status":"OK","result":{"headers":` + string(headersJson) + `,"data":[`)
+	}
+	return err
+}
+
+func (impl *ResourceListStreamWriter) flush() error {
+	if err := impl.buf.Flush(); err != nil {
+		return err
+	}
+	if flusher, ok := impl.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package k8s
+
+import (
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	defaultResourceSearchScanLimit = 5000
+	defaultResourceSearchTimeout   = 30 * time.Second
+)
+
+// ResourceSearchRequest searches the kind of K8sRequest in every namespace, the namespace and name of the request are
+// ignored. Query is matched as a case insensitive substring of the name, or exactly with ExactMatch in which case the
+// api server filters with a field selector
+type ResourceSearchRequest struct {
+	ResourceRequestBean
+	Query      string `json:"query"`
+	ExactMatch bool   `json:"exactMatch,omitempty"`
+}
+
+// ResourceSearchResponse holds the matching rows in the format of the resource list, Truncated is set when the scan
+// limit or the timeout stopped the search before every resource was scanned
+type ResourceSearchResponse struct {
+	Headers   []string                 `json:"headers"`
+	Data      []map[string]interface{} `json:"data"`
+	Scanned   int                      `json:"scanned"`
+	ScanLimit int                      `json:"scanLimit"`
+	Truncated bool                     `json:"truncated"`
+	TimedOut  bool                     `json:"timedOut,omitempty"`
+}
+
+// filterResourceRowsByName keeps the table rows of the page whose name contains query, at most maxRows rows are
+// scanned and limitReached tells if rows were left unscanned
+func filterResourceRowsByName(resources *unstructured.UnstructuredList, query string, maxRows int) (scanned int, limitReached bool) {
+	rows, ok := resources.Object[util.K8sClusterResourceRowsKey].([]interface{})
+	if !ok {
+		return 0, false
+	}
+	if len(rows) > maxRows {
+		rows = rows[:maxRows]
+		limitReached = true
+	}
+	query = strings.ToLower(query)
+	matched := make([]interface{}, 0)
+	for _, row := range rows {
+		if strings.Contains(strings.ToLower(resourceRowName(row)), query) {
+			matched = append(matched, row)
+		}
+	}
+	resources.Object[util.K8sClusterResourceRowsKey] = matched
+	return len(rows), limitReached
+}
+
+func resourceRowName(row interface{}) string {
+	rowMap, _ := row.(map[string]interface{})
+	object, _ := rowMap[util.K8sClusterResourceObjectKey].(map[string]interface{})
+	metadata, _ := object[util.K8sClusterResourceMetadataKey].(map[string]interface{})
+	name, _ := metadata[util.K8sClusterResourceMetadataNameKey].(string)
+	return name
+}
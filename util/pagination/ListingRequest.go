@@ -0,0 +1,172 @@
+package pagination
+
+import (
+	"fmt"
+	"github.com/go-pg/pg/orm"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type SortOrder string
+
+const (
+	Asc  SortOrder = "ASC"
+	Desc SortOrder = "DESC"
+)
+
+// query params making up the listing contract shared by list endpoints
+const (
+	OffsetParam    = "offset"
+	SizeParam      = "size"
+	SortByParam    = "sortBy"
+	SortOrderParam = "sortOrder"
+	SearchParam    = "searchKey"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ListingContract declares what a list endpoint supports, SortColumns maps the sortBy values accepted from clients to
+// the db columns they order by so that user input is never spliced into sql
+type ListingContract struct {
+	SortColumns      map[string]string
+	DefaultSortBy    string
+	DefaultSortOrder SortOrder
+	DefaultSize      int
+	MaxSize          int
+}
+
+type ListingRequest struct {
+	Offset    int       `json:"offset"`
+	Size      int       `json:"size"`
+	SortBy    string    `json:"sortBy"`
+	SortOrder SortOrder `json:"sortOrder"`
+	SearchKey string    `json:"searchKey"`
+	// sortColumn is the db column resolved from SortBy via the contract
+	sortColumn string
+}
+
+type ListingResponse struct {
+	TotalCount int         `json:"totalCount"`
+	Offset     int         `json:"offset"`
+	Size       int         `json:"size"`
+	Data       interface{} `json:"data"`
+}
+
+// HasListingParams tells whether the client asked for a paginated listing, endpoints which returned plain arrays
+// before adopting the contract use it to keep the old response for old clients
+func HasListingParams(values url.Values) bool {
+	for _, param := range []string{OffsetParam, SizeParam, SortByParam, SortOrderParam, SearchParam} {
+		if _, ok := values[param]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseListingRequest reads the listing query params, size is capped at the contract max instead of being rejected
+func ParseListingRequest(values url.Values, contract ListingContract) (*ListingRequest, error) {
+	maxSize := contract.MaxSize
+	if maxSize <= 0 {
+		maxSize = MaxPageSize
+	}
+	defaultSize := contract.DefaultSize
+	if defaultSize <= 0 || defaultSize > maxSize {
+		defaultSize = DefaultPageSize
+	}
+	request := &ListingRequest{Size: defaultSize, SearchKey: strings.TrimSpace(values.Get(SearchParam))}
+	if offset := values.Get(OffsetParam); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("invalid %s %q, must be a non negative integer", OffsetParam, offset)
+		}
+		request.Offset = value
+	}
+	if size := values.Get(SizeParam); size != "" {
+		value, err := strconv.Atoi(size)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid %s %q, must be a positive integer", SizeParam, size)
+		}
+		request.Size = value
+	}
+	if request.Size > maxSize {
+		request.Size = maxSize
+	}
+
+	request.SortBy = values.Get(SortByParam)
+	if request.SortBy == "" {
+		request.SortBy = contract.DefaultSortBy
+	}
+	if request.SortBy != "" {
+		column, ok := contract.SortColumns[request.SortBy]
+		if !ok {
+			return nil, fmt.Errorf("invalid %s %q, allowed values are %s", SortByParam, request.SortBy, strings.Join(allowedSortKeys(contract), ", "))
+		}
+		request.sortColumn = column
+	}
+
+	request.SortOrder = contract.DefaultSortOrder
+	if sortOrder := values.Get(SortOrderParam); sortOrder != "" {
+		request.SortOrder = SortOrder(strings.ToUpper(sortOrder))
+	}
+	if request.SortOrder == "" {
+		request.SortOrder = Asc
+	}
+	if request.SortOrder != Asc && request.SortOrder != Desc {
+		return nil, fmt.Errorf("invalid %s %q, allowed values are asc, desc", SortOrderParam, request.SortOrder)
+	}
+	return request, nil
+}
+
+func allowedSortKeys(contract ListingContract) []string {
+	keys := make([]string, 0, len(contract.SortColumns))
+	for key := range contract.SortColumns {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SearchPattern returns the search key as an ILIKE pattern with wildcards in it escaped
+func (request *ListingRequest) SearchPattern() string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(request.SearchKey) + "%"
+}
+
+// ApplySort adds the ORDER BY clause resolved from the contract, id is used as tie breaker to keep pages stable
+func (request *ListingRequest) ApplySort(query *orm.Query) *orm.Query {
+	if request.sortColumn != "" {
+		query = query.Order(fmt.Sprintf("%s %s", request.sortColumn, request.SortOrder))
+	}
+	if request.sortColumn == "id" {
+		return query
+	}
+	return query.Order(fmt.Sprintf("id %s", request.SortOrder))
+}
+
+// Apply adds ORDER BY, LIMIT and OFFSET to the query
+func (request *ListingRequest) Apply(query *orm.Query) *orm.Query {
+	return request.ApplySort(query).Limit(request.Size).Offset(request.Offset)
+}
+
+// PageBounds returns the slice bounds of the requested page for lists which can only be paginated in memory, e.g.
+// after rbac filtering
+func (request *ListingRequest) PageBounds(totalCount int) (start int, end int) {
+	start = request.Offset
+	if start > totalCount {
+		start = totalCount
+	}
+	end = start + request.Size
+	if end > totalCount {
+		end = totalCount
+	}
+	return start, end
+}
+
+func NewListingResponse(request *ListingRequest, totalCount int, data interface{}) *ListingResponse {
+	return &ListingResponse{TotalCount: totalCount, Offset: request.Offset, Size: request.Size, Data: data}
+}